@@ -17,13 +17,16 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"github.com/urfave/cli/v2"
 	"os"
+	"strings"
 
 	"github.com/qydata/go-ctereum/accounts"
 	"github.com/qydata/go-ctereum/accounts/keystore"
 	"github.com/qydata/go-ctereum/cmd/utils"
+	"github.com/qydata/go-ctereum/common"
 	"github.com/qydata/go-ctereum/crypto"
 	"github.com/qydata/go-ctereum/log"
 )
@@ -182,12 +185,75 @@ Note:
 As you can directly copy your encrypted accounts to another ethereum instance,
 this import mechanism is not needed when you transfer an account between
 nodes.
+`,
+			},
+			{
+				Name:      "bulk-export",
+				Usage:     "Export accounts into an encrypted key bundle",
+				Action:    accountBulkExport,
+				ArgsUsage: "<bundle> [<address> ...]",
+				Flags: []cli.Flag{
+					utils.DataDirFlag,
+					utils.KeyStoreDirFlag,
+					utils.PasswordFileFlag,
+					utils.LightKDFFlag,
+					newPasswordFileFlag,
+				},
+				Description: `
+    geth account bulk-export <bundle> [<address> ...]
+
+Exports the given accounts, or all of them if none is given, into a JSON bundle
+holding their encrypted key files.
+
+The keys are exported as they are stored, encrypted with their own passwords. With
+--newpassword they are re-encrypted with the password in the given file instead,
+you are prompted for the current password of every account, or they are read in
+order from the --password file.
+`,
+			},
+			{
+				Name:      "bulk-import",
+				Usage:     "Import the accounts of an encrypted key bundle",
+				Action:    accountBulkImport,
+				ArgsUsage: "<bundle>",
+				Flags: []cli.Flag{
+					utils.DataDirFlag,
+					utils.KeyStoreDirFlag,
+					utils.PasswordFileFlag,
+					utils.LightKDFFlag,
+					newPasswordFileFlag,
+				},
+				Description: `
+    geth account bulk-import <bundle>
+
+Imports the accounts of a JSON bundle created by bulk-export into the keystore,
+skipping the accounts already present. Prints the imported addresses.
+
+You are prompted for the password of every key, or they are read in order from
+the --password file. The keys are stored encrypted with the same passwords, or
+re-encrypted with the password in the --newpassword file.
 `,
 			},
 		},
 	}
+
+	newPasswordFileFlag = &cli.PathFlag{
+		Name:      "newpassword",
+		Usage:     "Password file to re-encrypt the keys with",
+		TakesFile: true,
+	}
 )
 
+// keyBundle is the format of the bundles of encrypted key files moved between
+// keystores by the bulk account commands.
+type keyBundle struct {
+	Version int               `json:"version"`
+	Keys    []json.RawMessage `json:"keys"`
+}
+
+// keyBundleVersion is the version of the key bundles written by bulk-export.
+const keyBundleVersion = 1
+
 func accountList(ctx *cli.Context) error {
 	stack, _ := makeConfigNode(ctx)
 	var index int
@@ -355,3 +421,120 @@ func accountImport(ctx *cli.Context) error {
 	fmt.Printf("Address: {%x}\n", acct.Address)
 	return nil
 }
+
+// readNewPassword returns the password of the --newpassword file, or nil if the
+// keys are not re-encrypted.
+func readNewPassword(ctx *cli.Context) *string {
+	path := ctx.Path(newPasswordFileFlag.Name)
+	if path == "" {
+		return nil
+	}
+	text, err := os.ReadFile(path)
+	if err != nil {
+		utils.Fatalf("Failed to read new password file: %v", err)
+	}
+	password := strings.TrimRight(strings.SplitN(string(text), "\n", 2)[0], "\r")
+	return &password
+}
+
+func accountBulkExport(ctx *cli.Context) error {
+	if ctx.Args().Len() < 1 {
+		utils.Fatalf("The bundle file must be given as the first argument")
+	}
+	stack, _ := makeConfigNode(ctx)
+	ks := stack.AccountManager().Backends(keystore.KeyStoreType)[0].(*keystore.KeyStore)
+
+	accs := ks.Accounts()
+	if ctx.Args().Len() > 1 {
+		accs = accs[:0]
+		for _, addr := range ctx.Args().Slice()[1:] {
+			account, err := utils.MakeAddress(ks, addr)
+			if err != nil {
+				utils.Fatalf("Could not find account %s: %v", addr, err)
+			}
+			accs = append(accs, account)
+		}
+	}
+	var (
+		bundle      = keyBundle{Version: keyBundleVersion, Keys: []json.RawMessage{}}
+		newPassword = readNewPassword(ctx)
+		passwords   = utils.MakePasswordList(ctx)
+	)
+	for i, account := range accs {
+		var (
+			keyJSON []byte
+			err     error
+		)
+		if newPassword == nil {
+			keyJSON, err = os.ReadFile(account.URL.Path)
+		} else {
+			prompt := fmt.Sprintf("Unlocking account %s | Export %d/%d", account.Address.Hex(), i+1, len(accs))
+			password := utils.GetPassPhraseWithList(prompt, false, i, passwords)
+			keyJSON, err = ks.Export(account, password, *newPassword)
+		}
+		if err != nil {
+			utils.Fatalf("Could not export account %s: %v", account.Address.Hex(), err)
+		}
+		bundle.Keys = append(bundle.Keys, keyJSON)
+	}
+	blob, err := json.MarshalIndent(bundle, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(ctx.Args().First(), blob, 0600); err != nil {
+		utils.Fatalf("Could not write the bundle: %v", err)
+	}
+	fmt.Printf("Exported %d accounts\n", len(bundle.Keys))
+	return nil
+}
+
+func accountBulkImport(ctx *cli.Context) error {
+	if ctx.Args().Len() != 1 {
+		utils.Fatalf("The bundle file must be given as the only argument")
+	}
+	blob, err := os.ReadFile(ctx.Args().First())
+	if err != nil {
+		utils.Fatalf("Could not read the bundle: %v", err)
+	}
+	var bundle keyBundle
+	if err := json.Unmarshal(blob, &bundle); err != nil {
+		utils.Fatalf("Invalid bundle: %v", err)
+	}
+	if bundle.Version != keyBundleVersion {
+		utils.Fatalf("Unsupported bundle version %d", bundle.Version)
+	}
+	stack, _ := makeConfigNode(ctx)
+	ks := stack.AccountManager().Backends(keystore.KeyStoreType)[0].(*keystore.KeyStore)
+
+	var (
+		newPassword = readNewPassword(ctx)
+		passwords   = utils.MakePasswordList(ctx)
+		imported    int
+	)
+	for i, keyJSON := range bundle.Keys {
+		var key struct {
+			Address string `json:"address"`
+		}
+		if err := json.Unmarshal(keyJSON, &key); err != nil {
+			utils.Fatalf("Invalid key #%d in the bundle: %v", i, err)
+		}
+		if ks.HasAddress(common.HexToAddress(key.Address)) {
+			fmt.Printf("Skipped existing account {%s}\n", strings.ToLower(strings.TrimPrefix(key.Address, "0x")))
+			continue
+		}
+		prompt := fmt.Sprintf("Unlocking key %s | Import %d/%d", key.Address, i+1, len(bundle.Keys))
+		password := utils.GetPassPhraseWithList(prompt, false, i, passwords)
+		storePassword := password
+		if newPassword != nil {
+			storePassword = *newPassword
+		}
+		acct, err := ks.Import(keyJSON, password, storePassword)
+		if err != nil {
+			utils.Fatalf("Could not import key %s: %v", key.Address, err)
+		}
+		fmt.Printf("Address: {%x}\n", acct.Address)
+		imported++
+	}
+	fmt.Printf("Imported %d accounts\n", imported)
+	return nil
+}
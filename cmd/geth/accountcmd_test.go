@@ -360,3 +360,49 @@ Fatal: None of the listed files could be unlocked.
 `)
 	geth.ExpectExit()
 }
+
+func TestAccountBulkExportImport(t *testing.T) {
+	var (
+		datadir  = tmpDatadirWithKeystore(t)
+		dir      = t.TempDir()
+		bundle   = filepath.Join(dir, "bundle.json")
+		password = filepath.Join(dir, "password.txt")
+		newpass  = filepath.Join(dir, "newpassword.txt")
+	)
+	if err := os.WriteFile(password, []byte("foobar\nfoobar"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(newpass, []byte("migrated\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	// Export two accounts re-encrypted with the new password
+	geth := runGeth(t, "account", "bulk-export", "--datadir", datadir, "--lightkdf",
+		"--password", password, "--newpassword", newpass, bundle,
+		"f466859ead1932d743d622cb74fc058882e8648a", "289d485d9771714cce91d3393d764e1311907acc")
+	geth.Expect("Exported 2 accounts\n")
+	geth.ExpectExit()
+
+	// Import them into an empty keystore, which requires the new password
+	target := t.TempDir()
+	geth = runGeth(t, "account", "bulk-import", "--datadir", target, "--lightkdf", bundle)
+	geth.Expect(`
+Unlocking key f466859ead1932d743d622cb74fc058882e8648a | Import 1/2
+!! Unsupported terminal, password will be echoed.
+Password: {{.InputLine "migrated"}}
+Address: {f466859ead1932d743d622cb74fc058882e8648a}
+Unlocking key 289d485d9771714cce91d3393d764e1311907acc | Import 2/2
+Password: {{.InputLine "migrated"}}
+Address: {289d485d9771714cce91d3393d764e1311907acc}
+Imported 2 accounts
+`)
+	geth.ExpectExit()
+
+	// Importing again skips the existing accounts
+	geth = runGeth(t, "account", "bulk-import", "--datadir", target, bundle)
+	geth.Expect(`
+Skipped existing account {f466859ead1932d743d622cb74fc058882e8648a}
+Skipped existing account {289d485d9771714cce91d3393d764e1311907acc}
+Imported 0 accounts
+`)
+	geth.ExpectExit()
+}
@@ -0,0 +1,502 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of go-ctereum.
+//
+// go-ctereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ctereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ctereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/qydata/go-ctereum/accounts/abi/bind"
+	"github.com/qydata/go-ctereum/accounts/keystore"
+	"github.com/qydata/go-ctereum/cmd/utils"
+	"github.com/qydata/go-ctereum/common"
+	"github.com/qydata/go-ctereum/common/hexutil"
+	"github.com/qydata/go-ctereum/contracts/authcontroller"
+	"github.com/qydata/go-ctereum/contracts/authcontroller/contract"
+	"github.com/qydata/go-ctereum/core/types"
+	"github.com/qydata/go-ctereum/ethclient"
+	"github.com/qydata/go-ctereum/internal/flags"
+	"github.com/qydata/go-ctereum/rpc"
+	cli "github.com/urfave/cli/v2"
+)
+
+var (
+	authEndpointFlag = &cli.StringFlag{
+		Name:     "endpoint",
+		Usage:    "RPC endpoint of the running node (default = IPC endpoint inside the datadir)",
+		Category: flags.APICategory,
+	}
+	authFromFlag = &cli.StringFlag{
+		Name:     "from",
+		Usage:    "Keystore account (address or index) to sign the transaction with",
+		Category: flags.AccountCategory,
+	}
+	authRevokeFlag = &cli.BoolFlag{
+		Name:  "revoke",
+		Usage: "Submit a revocation instead of an authentication",
+	}
+	authOrderFlag = &cli.Uint64Flag{
+		Name:  "order",
+		Usage: "Unique order ID of the authentication",
+	}
+	authTimeFlag = &cli.Uint64Flag{
+		Name:  "time",
+		Usage: "Unix time the authentication was performed at",
+	}
+	authExpiryFlag = &cli.Uint64Flag{
+		Name:  "expiry",
+		Usage: "Unix time the authentication expires at (0 = never)",
+	}
+	authLevelFlag = &cli.Uint64Flag{
+		Name:  "level",
+		Usage: "Authentication level",
+	}
+	authSignatureFlag = &cli.StringFlag{
+		Name:  "signature",
+		Usage: "Hex encoded signature of the authentication record",
+	}
+	authExpandFlag = &cli.StringFlag{
+		Name:  "expand",
+		Usage: "Free form data attached to the authentication record",
+	}
+	authFormatFlag = &cli.StringFlag{
+		Name:  "format",
+		Usage: "Format of the snapshot (csv or json)",
+		Value: "csv",
+	}
+	authBlockFlag = &cli.StringFlag{
+		Name:  "block",
+		Usage: "Block the snapshot is taken at (default = latest)",
+	}
+
+	authSignFlags = []cli.Flag{
+		authEndpointFlag,
+		authFromFlag,
+		utils.DataDirFlag,
+		utils.KeyStoreDirFlag,
+		utils.PasswordFileFlag,
+		utils.LightKDFFlag,
+		configFileFlag,
+	}
+
+	authCommand = &cli.Command{
+		Name:  "auth",
+		Usage: "Manage the AuthController of a running node",
+		Description: `
+The auth commands connect to a running node, by default through the IPC endpoint
+inside the datadir, and query or update the state of its AuthController. Updates
+are signed locally with a keystore account and submitted as raw transactions.`,
+		Subcommands: []*cli.Command{
+			{
+				Name:      "status",
+				Usage:     "Print the authentication status of an address",
+				ArgsUsage: "<address>",
+				Action:    authStatus,
+				Flags:     []cli.Flag{authEndpointFlag, utils.DataDirFlag},
+				Description: `
+    geth auth status <address>
+
+Prints whether the address is authenticated and whitelisted as of the latest
+block, along with the remaining validity of its authentication.`,
+			},
+			{
+				Name:  "whitelist",
+				Usage: "Manage the accounts allowed to submit authentications",
+				Subcommands: []*cli.Command{
+					{
+						Name:      "add",
+						Usage:     "Add addresses to the whitelist",
+						ArgsUsage: "<address> [<address>...]",
+						Action:    authWhitelistAdd,
+						Flags:     authSignFlags,
+						Description: `
+    geth auth whitelist add --from <owner> <address> [<address>...]
+
+Adds the addresses to the whitelist. The transaction must be signed by the owner
+of the AuthController.`,
+					},
+					{
+						Name:      "remove",
+						Usage:     "Remove addresses from the whitelist",
+						ArgsUsage: "<address> [<address>...]",
+						Action:    authWhitelistRemove,
+						Flags:     authSignFlags,
+						Description: `
+    geth auth whitelist remove --from <owner> <address> [<address>...]
+
+Removes the addresses from the whitelist. The transaction must be signed by the
+owner of the AuthController.`,
+					},
+				},
+			},
+			{
+				Name:      "submit",
+				Usage:     "Submit an authentication record",
+				ArgsUsage: "<address>",
+				Action:    authSubmit,
+				Flags: flags.Merge(authSignFlags, []cli.Flag{
+					authRevokeFlag,
+					authOrderFlag,
+					authTimeFlag,
+					authExpiryFlag,
+					authLevelFlag,
+					authSignatureFlag,
+					authExpandFlag,
+				}),
+				Description: `
+    geth auth submit --from <signer> --order <id> [--revoke] <address>
+
+Authenticates (or revokes) the address on behalf of the signer, which must be
+whitelisted. Every submission needs a unique order ID. The record is packed with
+the ABI of the contract live at the latest block, the time, expiry, level and
+expand data being dropped before the contract fix.`,
+			},
+			{
+				Name:      "export-index",
+				Usage:     "Export the indexed authentication events of an address",
+				ArgsUsage: "<address> [<from> [<to>]]",
+				Action:    authExportIndex,
+				Flags:     []cli.Flag{authEndpointFlag, utils.DataDirFlag},
+				Description: `
+    geth auth export-index <address> [<from> [<to>]]
+
+Prints the Authentication events of the address indexed by the node between the
+given blocks as JSON, one event per line. The range defaults to the whole chain.`,
+			},
+			{
+				Name:      "export-snapshot",
+				Usage:     "Export a snapshot of all authenticated addresses",
+				ArgsUsage: "<file>",
+				Action:    authExportSnapshot,
+				Flags:     []cli.Flag{authEndpointFlag, utils.DataDirFlag, authFormatFlag, authBlockFlag},
+				Description: `
+    geth auth export-snapshot [--format csv|json] [--block <number>] <file>
+
+Makes the node write all addresses authenticated as of the given block, along
+with their level and expiry, into the file for compliance reporting. The file
+is created by the node, so the path is resolved on its side and must not exist
+yet. It is compressed if its name ends in .gz. The snapshot is derived from the
+indexed auth events, which must cover the chain since the auth fork. The
+export is served by the admin API, exposed over IPC or the operator endpoint.`,
+			},
+		},
+	}
+)
+
+// authStatusResult is the status printed by the status command.
+type authStatusResult struct {
+	Address     common.Address  `json:"address"`
+	Contract    common.Address  `json:"contract"`
+	IsAuth      bool            `json:"isAuth"`
+	Whitelisted bool            `json:"whitelisted"`
+	Validity    json.RawMessage `json:"validity,omitempty"`
+}
+
+func authStatus(ctx *cli.Context) error {
+	if ctx.Args().Len() != 1 {
+		utils.Fatalf("This command requires an address argument.")
+	}
+	addr := parseAuthAddress(ctx.Args().First())
+
+	client := dialAuthNode(ctx)
+	defer client.Close()
+
+	result, err := authStatusOf(ethclient.NewClient(client), authContract(client), addr)
+	if err != nil {
+		utils.Fatalf("Failed to retrieve auth status: %v", err)
+	}
+	// The validity is only tracked by full nodes running the auth manager
+	if err := client.Call(&result.Validity, "ct_getAuthValidity", addr); err != nil {
+		result.Validity = nil
+	}
+	return printAuthJSON(result, true)
+}
+
+// authStatusOf reads the authentication and whitelist status of the address
+// from the auth contract as of the latest block.
+func authStatusOf(backend bind.ContractCaller, contractAddr common.Address, addr common.Address) (*authStatusResult, error) {
+	// Both contract revisions share the ABI of the read-only methods
+	caller, err := contract.NewAuthControllerCaller(contractAddr, backend)
+	if err != nil {
+		return nil, err
+	}
+	result := &authStatusResult{Address: addr, Contract: contractAddr}
+	if result.IsAuth, err = caller.AuthsSingle(nil, addr); err != nil {
+		return nil, err
+	}
+	if result.Whitelisted, err = caller.Whitelisted(nil, addr); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func authWhitelistAdd(ctx *cli.Context) error {
+	return authWhitelist(ctx, (*contract.AuthControllerTransactor).AddToWhitelist)
+}
+
+func authWhitelistRemove(ctx *cli.Context) error {
+	return authWhitelist(ctx, (*contract.AuthControllerTransactor).RemoveFromWhitelist)
+}
+
+// authWhitelist submits a whitelist update of the addresses given as arguments.
+func authWhitelist(ctx *cli.Context, update func(*contract.AuthControllerTransactor, *bind.TransactOpts, []common.Address) (*types.Transaction, error)) error {
+	if ctx.Args().Len() == 0 {
+		utils.Fatalf("This command requires at least one address argument.")
+	}
+	var addrs []common.Address
+	for _, arg := range ctx.Args().Slice() {
+		addrs = append(addrs, parseAuthAddress(arg))
+	}
+	client := dialAuthNode(ctx)
+	defer client.Close()
+
+	// Both contract revisions share the ABI of the whitelist methods
+	backend := ethclient.NewClient(client)
+	transactor, err := contract.NewAuthControllerTransactor(authContract(client), backend)
+	if err != nil {
+		utils.Fatalf("Failed to bind auth contract: %v", err)
+	}
+	tx, err := update(transactor, authSigner(ctx, backend), addrs)
+	if err != nil {
+		utils.Fatalf("Failed to submit whitelist update: %v", err)
+	}
+	return waitAuthTx(backend, tx)
+}
+
+func authSubmit(ctx *cli.Context) error {
+	if ctx.Args().Len() != 1 {
+		utils.Fatalf("This command requires an address argument.")
+	}
+	if !ctx.IsSet(authOrderFlag.Name) {
+		utils.Fatalf("An order ID is required, use --%s", authOrderFlag.Name)
+	}
+	var (
+		addr      = parseAuthAddress(ctx.Args().First())
+		orderId   = new(big.Int).SetUint64(ctx.Uint64(authOrderFlag.Name))
+		signature []byte
+		err       error
+	)
+	if sig := ctx.String(authSignatureFlag.Name); sig != "" {
+		if signature, err = hexutil.Decode(sig); err != nil {
+			utils.Fatalf("Invalid signature: %v", err)
+		}
+	}
+	client := dialAuthNode(ctx)
+	defer client.Close()
+
+	var revision authcontroller.Version
+	if err := client.Call(&revision, "ct_authRevision", nil); err != nil {
+		utils.Fatalf("Failed to retrieve auth contract revision: %v", err)
+	}
+	backend := ethclient.NewClient(client)
+	tx, err := submitAuth(backend, authContract(client), authSigner(ctx, backend), revision, contract.AuthControllerAuthData{
+		Caddress:   addr,
+		Signature:  signature,
+		AuthTime:   new(big.Int).SetUint64(ctx.Uint64(authTimeFlag.Name)),
+		AuthExpiry: new(big.Int).SetUint64(ctx.Uint64(authExpiryFlag.Name)),
+		IsAuth:     !ctx.Bool(authRevokeFlag.Name),
+		AuthLevel:  new(big.Int).SetUint64(ctx.Uint64(authLevelFlag.Name)),
+		ExpandData: ctx.String(authExpandFlag.Name),
+	}, orderId)
+	if err != nil {
+		utils.Fatalf("Failed to submit authentication: %v", err)
+	}
+	return waitAuthTx(backend, tx)
+}
+
+// submitAuth submits the record on behalf of the signer, packed with the ABI of
+// the given contract revision. The fields unknown to v1 contracts are dropped.
+func submitAuth(backend bind.ContractBackend, contractAddr common.Address, opts *bind.TransactOpts, revision authcontroller.Version, auth contract.AuthControllerAuthData, orderId *big.Int) (*types.Transaction, error) {
+	auth.Sender = opts.From
+
+	switch revision {
+	case authcontroller.V1:
+		transactor, err := contract.NewAuthControllerV1Transactor(contractAddr, backend)
+		if err != nil {
+			return nil, err
+		}
+		return transactor.Authentication(opts, contract.AuthControllerV1AuthData{
+			Caddress:  auth.Caddress,
+			Sender:    auth.Sender,
+			Signature: auth.Signature,
+			IsAuth:    auth.IsAuth,
+		}, orderId)
+	case authcontroller.V2:
+		transactor, err := contract.NewAuthControllerTransactor(contractAddr, backend)
+		if err != nil {
+			return nil, err
+		}
+		return transactor.Authentication(opts, auth, orderId)
+	default:
+		return nil, fmt.Errorf("unsupported auth contract revision %d", revision)
+	}
+}
+
+func authExportIndex(ctx *cli.Context) error {
+	if ctx.Args().Len() < 1 || ctx.Args().Len() > 3 {
+		utils.Fatalf("This command requires an address and an optional block range.")
+	}
+	var (
+		addr = parseAuthAddress(ctx.Args().Get(0))
+		from = rpc.EarliestBlockNumber
+		to   = rpc.LatestBlockNumber
+	)
+	for i, number := range []*rpc.BlockNumber{&from, &to} {
+		if arg := ctx.Args().Get(i + 1); arg != "" {
+			if err := number.UnmarshalJSON([]byte(arg)); err != nil {
+				utils.Fatalf("Invalid block number %q: %v", arg, err)
+			}
+		}
+	}
+	client := dialAuthNode(ctx)
+	defer client.Close()
+
+	var events []json.RawMessage
+	if err := client.Call(&events, "ct_getAuthHistory", addr, from, to); err != nil {
+		utils.Fatalf("Failed to retrieve auth history: %v", err)
+	}
+	for _, event := range events {
+		if err := printAuthJSON(event, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func authExportSnapshot(ctx *cli.Context) error {
+	if ctx.Args().Len() != 1 {
+		utils.Fatalf("This command requires a file argument.")
+	}
+	block := rpc.LatestBlockNumber
+	if arg := ctx.String(authBlockFlag.Name); arg != "" {
+		if err := block.UnmarshalJSON([]byte(arg)); err != nil {
+			utils.Fatalf("Invalid block number %q: %v", arg, err)
+		}
+	}
+	client := dialAuthNode(ctx)
+	defer client.Close()
+
+	var count int
+	if err := client.Call(&count, "admin_exportAuthIndex", ctx.Args().First(), ctx.String(authFormatFlag.Name), block); err != nil {
+		utils.Fatalf("Failed to export auth snapshot: %v", err)
+	}
+	fmt.Printf("Exported %d authenticated addresses to %s\n", count, ctx.Args().First())
+	return nil
+}
+
+// dialAuthNode connects to the node given by the endpoint flag, or to the IPC
+// endpoint inside the datadir if none is given.
+func dialAuthNode(ctx *cli.Context) *rpc.Client {
+	endpoint := ctx.String(authEndpointFlag.Name)
+	if endpoint == "" {
+		cfg := defaultNodeConfig()
+		utils.SetDataDir(ctx, &cfg)
+		endpoint = cfg.IPCEndpoint()
+	}
+	client, err := dialRPC(endpoint)
+	if err != nil {
+		utils.Fatalf("Unable to attach to node: %v", err)
+	}
+	return client
+}
+
+// authContract returns the address of the auth contract live at the latest
+// block, as reported along with any auth status.
+func authContract(client *rpc.Client) common.Address {
+	var result authStatusResult
+	if err := client.Call(&result, "ct_getAuth", common.Address{}, nil); err != nil {
+		utils.Fatalf("Failed to retrieve auth contract: %v", err)
+	}
+	return result.Contract
+}
+
+// authSigner unlocks the keystore account given by the from flag and returns
+// a transactor signing with it for the chain of the node.
+func authSigner(ctx *cli.Context, backend *ethclient.Client) *bind.TransactOpts {
+	from := ctx.String(authFromFlag.Name)
+	if from == "" {
+		utils.Fatalf("No signer specified, use --%s", authFromFlag.Name)
+	}
+	cfg := gethConfig{Node: defaultNodeConfig()}
+	if file := ctx.String(configFileFlag.Name); file != "" {
+		if err := loadConfig(file, &cfg); err != nil {
+			utils.Fatalf("%v", err)
+		}
+	}
+	utils.SetNodeConfig(ctx, &cfg.Node)
+	keydir, err := cfg.Node.KeyDirConfig()
+	if err != nil {
+		utils.Fatalf("Failed to read configuration: %v", err)
+	}
+	scryptN, scryptP := keystore.StandardScryptN, keystore.StandardScryptP
+	if cfg.Node.UseLightweightKDF {
+		scryptN, scryptP = keystore.LightScryptN, keystore.LightScryptP
+	}
+	ks := keystore.NewKeyStore(keydir, scryptN, scryptP)
+	account, _ := unlockAccount(ks, from, 0, utils.MakePasswordList(ctx))
+
+	chainID, err := backend.ChainID(context.Background())
+	if err != nil {
+		utils.Fatalf("Failed to retrieve chain ID: %v", err)
+	}
+	opts, err := bind.NewKeyStoreTransactorWithChainID(ks, account, chainID)
+	if err != nil {
+		utils.Fatalf("Failed to create transactor: %v", err)
+	}
+	return opts
+}
+
+// waitAuthTx waits for the transaction to be mined and reports its outcome.
+func waitAuthTx(backend *ethclient.Client, tx *types.Transaction) error {
+	fmt.Printf("Submitted transaction %s, waiting for it to be mined\n", tx.Hash().Hex())
+	receipt, err := bind.WaitMined(context.Background(), backend, tx)
+	if err != nil {
+		utils.Fatalf("Failed to wait for transaction: %v", err)
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		utils.Fatalf("Transaction reverted in block %d", receipt.BlockNumber)
+	}
+	fmt.Printf("Transaction mined in block %d\n", receipt.BlockNumber)
+	return nil
+}
+
+func parseAuthAddress(arg string) common.Address {
+	if !common.IsHexAddress(arg) {
+		utils.Fatalf("Invalid address %q", arg)
+	}
+	return common.HexToAddress(arg)
+}
+
+func printAuthJSON(v interface{}, indent bool) error {
+	var (
+		out []byte
+		err error
+	)
+	if indent {
+		out, err = json.MarshalIndent(v, "", "  ")
+	} else {
+		out, err = json.Marshal(v)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(os.Stdout, string(out))
+	return err
+}
@@ -0,0 +1,113 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of go-ctereum.
+//
+// go-ctereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ctereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ctereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/qydata/go-ctereum/common"
+	"github.com/qydata/go-ctereum/contracts/authcontroller"
+	"github.com/qydata/go-ctereum/contracts/authcontroller/contract"
+	contracttest "github.com/qydata/go-ctereum/contracts/testing"
+)
+
+// Tests that the status command reads the authentication and whitelist status
+// of an address from the auth contract.
+func TestAuthStatus(t *testing.T) {
+	h, err := contracttest.New(1)
+	if err != nil {
+		t.Fatalf("failed to create harness: %v", err)
+	}
+	defer h.Close()
+
+	var (
+		signer = h.Accounts[0]
+		authed = common.HexToAddress("0x01")
+		other  = common.HexToAddress("0x02")
+	)
+	if err := h.Whitelist(signer.From); err != nil {
+		t.Fatalf("failed to whitelist signer: %v", err)
+	}
+	if err := h.Authenticate(signer, true, authed); err != nil {
+		t.Fatalf("failed to authenticate: %v", err)
+	}
+	for i, tt := range []struct {
+		addr                common.Address
+		isAuth, whitelisted bool
+	}{
+		{authed, true, false},
+		{signer.From, false, true},
+		{other, false, false},
+	} {
+		result, err := authStatusOf(h.Backend, h.AuthAddr, tt.addr)
+		if err != nil {
+			t.Fatalf("test %d: failed to retrieve status: %v", i, err)
+		}
+		if result.Address != tt.addr || result.Contract != h.AuthAddr || result.IsAuth != tt.isAuth || result.Whitelisted != tt.whitelisted {
+			t.Errorf("test %d: status mismatch: have %+v, want auth %v, whitelisted %v", i, result, tt.isAuth, tt.whitelisted)
+		}
+	}
+}
+
+// Tests that the submit command packs the record with the ABI of the contract
+// revision, submitting on behalf of the signer.
+func TestAuthSubmit(t *testing.T) {
+	h, err := contracttest.New(1)
+	if err != nil {
+		t.Fatalf("failed to create harness: %v", err)
+	}
+	defer h.Close()
+
+	var (
+		signer = h.Accounts[0]
+		addr   = common.HexToAddress("0x01")
+		record = contract.AuthControllerAuthData{
+			Caddress:   addr,
+			AuthTime:   big.NewInt(1),
+			AuthExpiry: big.NewInt(0),
+			IsAuth:     true,
+			AuthLevel:  big.NewInt(2),
+		}
+	)
+	if err := h.Whitelist(signer.From); err != nil {
+		t.Fatalf("failed to whitelist signer: %v", err)
+	}
+	// A v2 record authenticates the address
+	if _, err := submitAuth(h.Backend, h.AuthAddr, signer, authcontroller.V2, record, big.NewInt(1)); err != nil {
+		t.Fatalf("failed to submit v2 record: %v", err)
+	}
+	h.Backend.Commit()
+	if ok, err := h.Auth.AuthsSingle(nil, addr); err != nil || !ok {
+		t.Errorf("address not authenticated: %v, %v", ok, err)
+	}
+	// A v1 record is packed with the v1 ABI, which the v2 contract doesn't know
+	opts := *signer
+	opts.GasLimit = 1000000
+	tx, err := submitAuth(h.Backend, h.AuthAddr, &opts, authcontroller.V1, record, big.NewInt(2))
+	if err != nil {
+		t.Fatalf("failed to submit v1 record: %v", err)
+	}
+	parsed, _ := contract.AuthControllerV1MetaData.GetAbi()
+	if id := parsed.Methods["authentication"].ID; !bytes.Equal(tx.Data()[:4], id) {
+		t.Errorf("v1 selector mismatch: have %x, want %x", tx.Data()[:4], id)
+	}
+	if _, err := submitAuth(h.Backend, h.AuthAddr, signer, 3, record, big.NewInt(3)); err == nil {
+		t.Errorf("unknown revision accepted")
+	}
+}
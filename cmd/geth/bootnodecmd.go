@@ -0,0 +1,103 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of go-ctereum.
+//
+// go-ctereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ctereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ctereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/qydata/go-ctereum/cmd/utils"
+	cli "github.com/urfave/cli/v2"
+)
+
+var bootnodesCommand = &cli.Command{
+	Name:  "bootnodes",
+	Usage: "Manage the bootnodes of a running node",
+	Description: `
+The bootnodes commands connect to a running node, by default through the IPC
+endpoint inside the datadir, and update the bootstrap nodes of its discovery
+table. The updated list is persisted inside the datadir and used instead of the
+configured bootnodes on the next start.`,
+	Subcommands: []*cli.Command{
+		{
+			Name:   "list",
+			Usage:  "Print the bootnodes of the node",
+			Action: listBootnodes,
+			Flags:  []cli.Flag{authEndpointFlag, utils.DataDirFlag},
+		},
+		{
+			Name:      "add",
+			Usage:     "Add a bootnode to the node",
+			ArgsUsage: "<enode>",
+			Action:    addBootnode,
+			Flags:     []cli.Flag{authEndpointFlag, utils.DataDirFlag},
+		},
+		{
+			Name:      "remove",
+			Usage:     "Remove a bootnode from the node",
+			ArgsUsage: "<enode>",
+			Action:    removeBootnode,
+			Flags:     []cli.Flag{authEndpointFlag, utils.DataDirFlag},
+		},
+	},
+}
+
+func listBootnodes(ctx *cli.Context) error {
+	client := dialAuthNode(ctx)
+	defer client.Close()
+
+	var urls []string
+	if err := client.Call(&urls, "admin_listBootnodes"); err != nil {
+		utils.Fatalf("Failed to retrieve bootnodes: %v", err)
+	}
+	for _, url := range urls {
+		fmt.Println(url)
+	}
+	return nil
+}
+
+func addBootnode(ctx *cli.Context) error {
+	if ctx.Args().Len() != 1 {
+		utils.Fatalf("This command requires an enode argument.")
+	}
+	client := dialAuthNode(ctx)
+	defer client.Close()
+
+	var ok bool
+	if err := client.Call(&ok, "admin_addBootnode", ctx.Args().First()); err != nil {
+		utils.Fatalf("Failed to add bootnode: %v", err)
+	}
+	fmt.Println("Added bootnode", ctx.Args().First())
+	return nil
+}
+
+func removeBootnode(ctx *cli.Context) error {
+	if ctx.Args().Len() != 1 {
+		utils.Fatalf("This command requires an enode argument.")
+	}
+	client := dialAuthNode(ctx)
+	defer client.Close()
+
+	var ok bool
+	if err := client.Call(&ok, "admin_removeBootnode", ctx.Args().First()); err != nil {
+		utils.Fatalf("Failed to remove bootnode: %v", err)
+	}
+	if !ok {
+		utils.Fatalf("Unknown bootnode %s", ctx.Args().First())
+	}
+	fmt.Println("Removed bootnode", ctx.Args().First())
+	return nil
+}
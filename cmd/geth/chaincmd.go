@@ -30,6 +30,7 @@ import (
 	"github.com/qydata/go-ctereum/cmd/utils"
 	"github.com/qydata/go-ctereum/common"
 	"github.com/qydata/go-ctereum/common/hexutil"
+	"github.com/qydata/go-ctereum/consensus/clique"
 	"github.com/qydata/go-ctereum/core"
 	"github.com/qydata/go-ctereum/core/rawdb"
 	"github.com/qydata/go-ctereum/core/state"
@@ -92,13 +93,19 @@ The dumpgenesis command dumps the genesis block configuration in JSON format to
 			utils.MetricsInfluxDBBucketFlag,
 			utils.MetricsInfluxDBOrganizationFlag,
 			utils.TxLookupLimitFlag,
+			utils.ImportNoSealVerifyFlag,
 		}, utils.DatabasePathFlags),
 		Description: `
 The import command imports blocks from an RLP-encoded form. The form can be one file
 with several RLP-encoded blocks, or several files can be used.
 
 If only one file is used, import error will result in failure. If several files are used,
-processing will proceed even if an individual RLP-file import failure occurs.`,
+processing will proceed even if an individual RLP-file import failure occurs.
+
+On clique chains, --import.nosealverify <height> trusts the seals of the blocks
+below the given height, skipping the verification of their signers. The blocks
+are still processed and their state roots verified, so it should only be used
+with chain files from a trusted source.`,
 	}
 	exportCommand = &cli.Command{
 		Action:    exportChain,
@@ -115,6 +122,40 @@ Optional second and third arguments control the first and
 last block to write. In this mode, the file will be appended
 if already existing. If the file ends with .gz, the output will
 be gzipped.`,
+	}
+	importHistoryCommand = &cli.Command{
+		Action:    importHistory,
+		Name:      "import-history",
+		Usage:     "Import blockchain history from Era1 archives",
+		ArgsUsage: "<dir|url>",
+		Flags: flags.Merge([]cli.Flag{
+			utils.CacheFlag,
+			utils.TxLookupLimitFlag,
+		}, utils.DatabasePathFlags),
+		Description: `
+The import-history command imports the headers, bodies, receipts and total
+difficulties stored in the Era1 archives listed in the checksums.txt file of the
+given directory or http(s) location, such as an object storage bucket. Every
+archive is verified against its checksum before being imported.
+
+The blocks are not executed but written directly into the ancient store, so the
+import needs to start right above the local history, typically on a freshly
+initialized node. The state is synced from the network afterwards.`,
+	}
+	exportHistoryCommand = &cli.Command{
+		Action:    exportHistory,
+		Name:      "export-history",
+		Usage:     "Export blockchain history into Era1 archives",
+		ArgsUsage: "<dir> <blockNumFirst> <blockNumLast>",
+		Flags: flags.Merge([]cli.Flag{
+			utils.CacheFlag,
+		}, utils.DatabasePathFlags),
+		Description: `
+The export-history command exports the given range of blocks, along with their
+receipts and total difficulties, into snappy compressed and checksummed Era1
+archives of up to 8192 blocks aligned on epoch boundaries. The archives are named
+after the chain id, the epoch and their checksum, and listed with their sha256
+checksums in the checksums.txt file of the output directory.`,
 	}
 	importPreimagesCommand = &cli.Command{
 		Action:    importPreimages,
@@ -162,6 +203,36 @@ It's deprecated, please use "geth db export" instead.
 This command dumps out the state for a given block (or latest, if none provided).
 `,
 	}
+	inspectBlocksFlag = &cli.Uint64Flag{
+		Name:  "blocks",
+		Usage: "Number of headers to inspect",
+		Value: 1024,
+	}
+	inspectJSONFlag = &cli.BoolFlag{
+		Name:  "json",
+		Usage: "Print the report as JSON",
+	}
+	inspectConsensusCommand = &cli.Command{
+		Action:    inspectConsensus,
+		Name:      "inspect-consensus",
+		Usage:     "Rebuild the clique snapshots of recent headers and report on them",
+		ArgsUsage: "[<blockNum>]",
+		Flags: flags.Merge([]cli.Flag{
+			inspectBlocksFlag,
+			inspectJSONFlag,
+		}, utils.DatabasePathFlags),
+		Description: `
+    geth inspect-consensus [--blocks <count>] [--json] [<blockNum>]
+
+This command opens the datadir without starting the node, rebuilds the clique
+snapshots of the given number of headers up to the block (or the head header, if
+none provided) from the last epoch checkpoint before them, and prints the blocks
+sealed and the in-turn slots missed by each signer, the pending votes and whether
+the epoch checkpoints and the snapshots stored on disk match the rebuilt signers.
+The stored snapshots are not used for the rebuild, so it also works when the
+node fails to start because of them. The rebuild stops at the first header that
+fails to apply, which is reported.`,
+	}
 )
 
 // initGenesis will initialise the given JSON format genesis file and writes it as
@@ -331,6 +402,51 @@ func exportChain(ctx *cli.Context) error {
 	return nil
 }
 
+// importHistory imports the blockchain history from Era1 archives.
+func importHistory(ctx *cli.Context) error {
+	if ctx.Args().Len() != 1 {
+		utils.Fatalf("This command requires an argument.")
+	}
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	chain, db := utils.MakeChain(ctx, stack)
+	defer db.Close()
+
+	start := time.Now()
+	if err := utils.ImportHistory(chain, db, ctx.Args().First()); err != nil {
+		chain.Stop()
+		utils.Fatalf("Import error: %v\n", err)
+	}
+	chain.Stop()
+	fmt.Printf("Import done in %v\n", time.Since(start))
+	return nil
+}
+
+// exportHistory exports the blockchain history into Era1 archives.
+func exportHistory(ctx *cli.Context) error {
+	if ctx.Args().Len() != 3 {
+		utils.Fatalf("This command requires three arguments.")
+	}
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	chain, _ := utils.MakeChain(ctx, stack)
+	start := time.Now()
+
+	first, ferr := strconv.ParseUint(ctx.Args().Get(1), 10, 64)
+	last, lerr := strconv.ParseUint(ctx.Args().Get(2), 10, 64)
+	if ferr != nil || lerr != nil {
+		utils.Fatalf("Export error in parsing parameters: block number not an integer\n")
+	}
+	network := chain.Config().ChainID.String()
+	if err := utils.ExportHistory(chain, ctx.Args().First(), network, first, last); err != nil {
+		utils.Fatalf("Export error: %v\n", err)
+	}
+	fmt.Printf("Export done in %v\n", time.Since(start))
+	return nil
+}
+
 // importPreimages imports preimage data from the specified file.
 func importPreimages(ctx *cli.Context) error {
 	if ctx.Args().Len() < 1 {
@@ -451,6 +567,114 @@ func dump(ctx *cli.Context) error {
 	return nil
 }
 
+func inspectConsensus(ctx *cli.Context) error {
+	if ctx.Args().Len() > 1 {
+		utils.Fatalf("This command accepts at most one block number argument.")
+	}
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	db := utils.MakeChainDatabase(ctx, stack, true)
+	defer db.Close()
+
+	config := rawdb.ReadChainConfig(db, rawdb.ReadCanonicalHash(db, 0))
+	if config == nil || config.Clique == nil {
+		utils.Fatalf("The chain in the datadir does not run clique")
+	}
+	var to uint64
+	if arg := ctx.Args().First(); arg != "" {
+		number, err := strconv.ParseUint(arg, 10, 64)
+		if err != nil {
+			utils.Fatalf("Invalid block number %q: %v", arg, err)
+		}
+		to = number
+	} else {
+		number := rawdb.ReadHeaderNumber(db, rawdb.ReadHeadHeaderHash(db))
+		if number == nil {
+			utils.Fatalf("No head header in the datadir")
+		}
+		to = *number
+	}
+	if to == 0 {
+		utils.Fatalf("No headers to inspect past the genesis")
+	}
+	from := uint64(1)
+	if blocks := ctx.Uint64(inspectBlocksFlag.Name); blocks > 0 && to >= blocks {
+		from = to - blocks + 1
+	}
+	report, err := clique.Inspect(config.Clique, db, from, to)
+	if err != nil {
+		utils.Fatalf("Failed to inspect consensus: %v", err)
+	}
+	if ctx.Bool(inspectJSONFlag.Name) {
+		out, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(out))
+		return nil
+	}
+	printConsensusReport(report)
+	return nil
+}
+
+// printConsensusReport prints a consensus report in a human readable form.
+func printConsensusReport(report *clique.Report) {
+	fmt.Printf("Inspected blocks #%d-#%d, rebuilt from checkpoint #%d\n", report.From, report.To, report.Base)
+
+	fmt.Printf("\nSigners (%d):\n", len(report.Signers))
+	// List the signers dropped within the range after the current ones
+	signers := append([]common.Address{}, report.Signers...)
+	for _, counts := range []map[common.Address]uint64{report.Sealed, report.Missed} {
+		for addr := range counts {
+			if !containsAddress(signers, addr) {
+				signers = append(signers, addr)
+			}
+		}
+	}
+	for _, addr := range signers {
+		status := ""
+		if !containsAddress(report.Signers, addr) {
+			status = " (no longer authorized)"
+		}
+		fmt.Printf("  %s sealed %d (%d in-turn), missed %d in-turn slots%s\n",
+			addr.Hex(), report.Sealed[addr], report.InTurn[addr], report.Missed[addr], status)
+	}
+	fmt.Printf("\nPending votes (%d):\n", len(report.Votes))
+	for _, vote := range report.Votes {
+		action := "drop"
+		if vote.Authorize {
+			action = "add"
+		}
+		fmt.Printf("  #%d %s votes to %s %s (%d votes)\n", vote.Block, vote.Signer.Hex(), action, vote.Address.Hex(), report.Tally[vote.Address].Votes)
+	}
+	for _, list := range []struct {
+		name        string
+		checkpoints []*clique.CheckpointReport
+	}{{"Epoch checkpoints", report.Checkpoints}, {"Stored snapshots", report.Snapshots}} {
+		fmt.Printf("\n%s (%d):\n", list.name, len(list.checkpoints))
+		for _, checkpoint := range list.checkpoints {
+			status := "in sync"
+			if !checkpoint.InSync {
+				status = fmt.Sprintf("MISMATCH, recorded signers %v", checkpoint.Signers)
+			}
+			fmt.Printf("  #%d %s signers %s %s\n", checkpoint.Number, checkpoint.Hash.Hex(), checkpoint.SignersHash.Hex(), status)
+		}
+	}
+	if failure := report.Failure; failure != nil {
+		fmt.Printf("\nRebuild FAILED at block #%d %s: %s\n", failure.Number, failure.Hash.Hex(), failure.Error)
+	}
+}
+
+func containsAddress(addrs []common.Address, addr common.Address) bool {
+	for _, a := range addrs {
+		if a == addr {
+			return true
+		}
+	}
+	return false
+}
+
 // hashish returns true for strings that look like hashes.
 func hashish(x string) bool {
 	_, err := strconv.Atoi(x)
@@ -162,7 +162,6 @@ func makeFullNode(ctx *cli.Context) (*node.Node, ethapi.Backend) {
 		override := ctx.Bool(utils.OverrideTerminalTotalDifficultyPassed.Name)
 		cfg.Eth.OverrideTerminalTotalDifficultyPassed = &override
 	}
-
 	backend, eth := utils.RegisterEthService(stack, &cfg.Eth)
 
 	// Warn users to migrate if they have a legacy freezer format.
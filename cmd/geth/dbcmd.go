@@ -17,7 +17,10 @@
 package main
 
 import (
+	"bufio"
 	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/urfave/cli/v2"
 	"os"
@@ -32,7 +35,9 @@ import (
 	"github.com/qydata/go-ctereum/cmd/utils"
 	"github.com/qydata/go-ctereum/common"
 	"github.com/qydata/go-ctereum/common/hexutil"
+	"github.com/qydata/go-ctereum/consensus/clique"
 	"github.com/qydata/go-ctereum/console/prompt"
+	"github.com/qydata/go-ctereum/core"
 	"github.com/qydata/go-ctereum/core/rawdb"
 	"github.com/qydata/go-ctereum/core/state/snapshot"
 	"github.com/qydata/go-ctereum/core/types"
@@ -44,6 +49,11 @@ import (
 )
 
 var (
+	verifyRepairFlag = &cli.BoolFlag{
+		Name:  "repair",
+		Usage: "Truncate the ancient store to the last intact block if corruption is found",
+	}
+
 	removedbCommand = &cli.Command{
 		Action:    removeDB,
 		Name:      "removedb",
@@ -68,9 +78,15 @@ Remove blockchain and state databases`,
 			dbDumpFreezerIndex,
 			dbImportCmd,
 			dbExportCmd,
+			dbImportPreimagesCmd,
+			dbExportPreimagesCmd,
 			dbMetadataCmd,
 			dbMigrateFreezerCmd,
+			dbVerifyAncientsCmd,
 			dbCheckStateContentCmd,
+			dbMigrateCmd,
+			dbMigrateFromGethCmd,
+			dbInspectContractCmd,
 		},
 	}
 	dbInspectCmd = &cli.Command{
@@ -186,6 +202,34 @@ WARNING: This is a low-level operation which may cause database corruption!`,
 		}, utils.NetworkFlags, utils.DatabasePathFlags),
 		Description: "Exports the specified chain data to an RLP encoded stream, optionally gzip-compressed.",
 	}
+	dbImportPreimagesCmd = &cli.Command{
+		Action:    dbImportPreimages,
+		Name:      "import-preimages",
+		Usage:     "Imports hash preimages from an RLP stream",
+		ArgsUsage: "<dumpfile>",
+		Flags: flags.Merge([]cli.Flag{
+			utils.SyncModeFlag,
+		}, utils.NetworkFlags, utils.DatabasePathFlags),
+		Description: `
+The import-preimages command imports the hash preimages of an RLP stream as
+written by export-preimages. The stream is read from the standard input if the
+<dumpfile> is "-", and gzip-decompressed if its name ends in .gz.`,
+	}
+	dbExportPreimagesCmd = &cli.Command{
+		Action:    dbExportPreimages,
+		Name:      "export-preimages",
+		Usage:     "Exports all recorded hash preimages into an RLP stream",
+		ArgsUsage: "<dumpfile>",
+		Flags: flags.Merge([]cli.Flag{
+			utils.SyncModeFlag,
+		}, utils.NetworkFlags, utils.DatabasePathFlags),
+		Description: `
+The export-preimages command streams the preimages of the trie keys and of the
+recorded keccak256 hashes into an RLP stream, allowing e.g. the hashed storage
+keys of contracts to be mapped back to the original keys. The stream is written
+to the standard output if the <dumpfile> is "-", and gzip-compressed if its name
+ends in .gz.`,
+	}
 	dbMetadataCmd = &cli.Command{
 		Action: showMetaData,
 		Name:   "metadata",
@@ -206,6 +250,59 @@ WARNING: This is a low-level operation which may cause database corruption!`,
 		Description: `The freezer-migrate command checks your database for receipts in a legacy format and updates those.
 WARNING: please back-up the receipt files in your ancients before running this command.`,
 	}
+	dbVerifyAncientsCmd = &cli.Command{
+		Action:    dbVerifyAncients,
+		Name:      "verify-ancients",
+		Usage:     "Check the integrity of the ancient store and optionally repair it",
+		ArgsUsage: "",
+		Flags: flags.Merge([]cli.Flag{
+			utils.SyncModeFlag,
+			verifyRepairFlag,
+		}, utils.NetworkFlags, utils.DatabasePathFlags),
+		Description: `The verify-ancients command reads back every item of all the ancient tables,
+checking that the indices are consistent with the data files, that the items
+can be decoded and that the headers match the canonical hashes. The corrupted
+range of every table is reported.
+
+With --repair, the ancient store is truncated to the last intact block and the
+chain head is rewound to it. The node resyncs the chain above it on startup.`,
+	}
+	dbMigrateCmd = &cli.Command{
+		Action:    dbMigrate,
+		Name:      "migrate",
+		Usage:     "Convert a leveldb chain database to pebble (WARNING: may take a long time)",
+		ArgsUsage: "",
+		Flags: flags.Merge([]cli.Flag{
+			utils.CacheFlag,
+			utils.CacheDatabaseFlag,
+		}, utils.NetworkFlags, utils.DatabasePathFlags),
+		Description: `The migrate command copies all entries of a leveldb chain database into a new
+pebble database and swaps it in place of the original one, which is kept as a
+backup in chaindata.leveldb next to it. The ancient store is shared by both and
+left untouched. Once the node runs fine on pebble, the backup may be deleted.`,
+	}
+	dbMigrateFromGethCmd = &cli.Command{
+		Action:    dbMigrateFromGeth,
+		Name:      "migrate-from-geth",
+		Usage:     "Convert a go-ethereum datadir of a ct network to the go-ctereum format",
+		ArgsUsage: "[<genesis.json>]",
+		Flags: flags.Merge([]cli.Flag{
+			utils.SyncModeFlag,
+		}, utils.NetworkFlags, utils.DatabasePathFlags),
+		Description: `
+    geth db migrate-from-geth [<genesis.json>]
+
+The migrate-from-geth command converts a chain database written by upstream
+go-ethereum, so the node can switch clients without resyncing. The genesis of the
+network is taken from the given file or the network flags, and must be the one of
+the chain in the database. The chain config stored in the database is replaced by
+the ct one of the genesis, and the clique snapshots are converted to the ct
+format, which tracks the activity of the signers.
+
+The blocks, receipts and state share the same layout in both clients and are kept
+as they are. The ct indices, e.g. the auth event index, are built by the node on
+its next start. The command may be run repeatedly.`,
+	}
 )
 
 func removeDB(ctx *cli.Context) error {
@@ -354,14 +451,16 @@ func checkStateContent(ctx *cli.Context) error {
 }
 
 func showLeveldbStats(db ethdb.KeyValueStater) {
-	if stats, err := db.Stat("leveldb.stats"); err != nil {
+	stats, err := db.Stat("leveldb.stats")
+	if err != nil {
 		log.Warn("Failed to read database stats", "error", err)
 	} else {
 		fmt.Println(stats)
 	}
+	// Pebble reports all its stats regardless of the property
 	if ioStats, err := db.Stat("leveldb.iostats"); err != nil {
 		log.Warn("Failed to read database iostats", "error", err)
-	} else {
+	} else if ioStats != stats {
 		fmt.Println(ioStats)
 	}
 }
@@ -696,6 +795,66 @@ func exportChaindata(ctx *cli.Context) error {
 	return utils.ExportChaindata(ctx.Args().Get(1), kind, exporter(db), stop)
 }
 
+// dbImportPreimages imports the hash preimages from a file or the standard
+// input.
+func dbImportPreimages(ctx *cli.Context) error {
+	if ctx.NArg() != 1 {
+		return fmt.Errorf("required arguments: %v", ctx.Command.ArgsUsage)
+	}
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	db := utils.MakeChainDatabase(ctx, stack, false)
+	defer db.Close()
+
+	start := time.Now()
+	if fn := ctx.Args().First(); fn != "-" {
+		if err := utils.ImportPreimages(db, fn); err != nil {
+			return err
+		}
+	} else {
+		count, err := utils.ImportPreimageStream(db, bufio.NewReader(os.Stdin))
+		if err != nil {
+			return err
+		}
+		log.Info("Imported preimages", "count", count)
+	}
+	log.Info("Import done", "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+// dbExportPreimages exports all hash preimages into a file or the standard
+// output.
+func dbExportPreimages(ctx *cli.Context) error {
+	if ctx.NArg() != 1 {
+		return fmt.Errorf("required arguments: %v", ctx.Command.ArgsUsage)
+	}
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	db := utils.MakeChainDatabase(ctx, stack, true)
+	defer db.Close()
+
+	start := time.Now()
+	if fn := ctx.Args().First(); fn != "-" {
+		if err := utils.ExportPreimages(db, fn); err != nil {
+			return err
+		}
+	} else {
+		writer := bufio.NewWriter(os.Stdout)
+		count, err := utils.ExportPreimageStream(db, writer)
+		if err != nil {
+			return err
+		}
+		if err := writer.Flush(); err != nil {
+			return err
+		}
+		log.Info("Exported preimages", "count", count)
+	}
+	log.Info("Export done", "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
 func showMetaData(ctx *cli.Context) error {
 	stack, _ := makeConfigNode(ctx)
 	defer stack.Close()
@@ -785,6 +944,75 @@ func freezerMigrate(ctx *cli.Context) error {
 	return nil
 }
 
+func dbVerifyAncients(ctx *cli.Context) error {
+	repair := ctx.Bool(verifyRepairFlag.Name)
+
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	db := utils.MakeChainDatabase(ctx, stack, !repair)
+	defer db.Close()
+
+	ancient, err := db.AncientDatadir()
+	if err != nil {
+		return err
+	}
+	start := time.Now()
+	report, err := rawdb.VerifyChainFreezer(ancient)
+	if err != nil {
+		return err
+	}
+	var data [][]string
+	for _, t := range report.Tables {
+		status := "intact"
+		if t.Err != nil {
+			status = fmt.Sprintf("corrupted from #%d: %v", t.Good, t.Err)
+		}
+		data = append(data, []string{t.Name, fmt.Sprint(t.Tail), fmt.Sprint(t.Items), status})
+	}
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Table", "Tail", "Items", "Status"})
+	table.AppendBulk(data)
+	table.Render()
+
+	if !report.Corrupted() {
+		log.Info("Ancient store intact", "items", report.Items, "elapsed", common.PrettyDuration(time.Since(start)))
+		return nil
+	}
+	if !repair {
+		return fmt.Errorf("ancient store corrupted from block #%d, rerun with --%s to truncate it", report.Good, verifyRepairFlag.Name)
+	}
+	return truncateAncients(db, report.Good)
+}
+
+// truncateAncients truncates the ancient store to the given number of items,
+// rewinding the chain head markers above it to the last remaining block.
+func truncateAncients(db ethdb.Database, items uint64) error {
+	if items == 0 {
+		return errors.New("no intact block left in the ancient store, resync needed")
+	}
+	hash := rawdb.ReadCanonicalHash(db, items-1)
+	if hash == (common.Hash{}) {
+		return fmt.Errorf("canonical hash of block #%d missing", items-1)
+	}
+	// Rewind the head markers first, so that an interrupted repair leaves them
+	// pointing to a block surviving the truncation
+	rewind := func(read func(ethdb.KeyValueReader) common.Hash, write func(ethdb.KeyValueWriter, common.Hash)) {
+		if number := rawdb.ReadHeaderNumber(db, read(db)); number == nil || *number >= items {
+			write(db, hash)
+		}
+	}
+	rewind(rawdb.ReadHeadHeaderHash, rawdb.WriteHeadHeaderHash)
+	rewind(rawdb.ReadHeadFastBlockHash, rawdb.WriteHeadFastBlockHash)
+	rewind(rawdb.ReadHeadBlockHash, rawdb.WriteHeadBlockHash)
+
+	if err := db.TruncateHead(items); err != nil {
+		return err
+	}
+	log.Info("Truncated ancient store", "items", items, "head", items-1, "hash", hash)
+	return nil
+}
+
 // dbHasLegacyReceipts checks freezer entries for legacy receipts. It stops at the first
 // non-empty receipt and checks its format. The index of this first non-empty element is
 // the second return parameter.
@@ -830,3 +1058,159 @@ func dbHasLegacyReceipts(db ethdb.Database, firstIdx uint64) (bool, uint64, erro
 	legacy, err = types.IsLegacyStoredReceipts(first)
 	return legacy, firstIdx, err
 }
+
+// dbMigrate converts the leveldb chain database into a pebble one, keeping the
+// original as a backup.
+func dbMigrateFromGeth(ctx *cli.Context) error {
+	if ctx.Args().Len() > 1 {
+		return fmt.Errorf("max 1 argument: %v", ctx.Command.ArgsUsage)
+	}
+	genesis := utils.MakeGenesis(ctx)
+	if path := ctx.Args().First(); path != "" {
+		file, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("failed to read genesis file: %v", err)
+		}
+		defer file.Close()
+
+		genesis = new(core.Genesis)
+		if err := json.NewDecoder(file).Decode(genesis); err != nil {
+			return fmt.Errorf("invalid genesis file: %v", err)
+		}
+	}
+	if genesis == nil {
+		return errors.New("the genesis of the network must be given as a file or with a network flag")
+	}
+	if genesis.Config == nil {
+		return errors.New("genesis has no chain config")
+	}
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	db := utils.MakeChainDatabase(ctx, stack, false)
+	defer db.Close()
+
+	stored := rawdb.ReadCanonicalHash(db, 0)
+	if stored == (common.Hash{}) {
+		return errors.New("no chain found in the database")
+	}
+	if hash := genesis.ToBlock().Hash(); hash != stored {
+		return fmt.Errorf("genesis mismatch: database %x, given %x", stored, hash)
+	}
+	if genesis.Config.Clique == nil {
+		return errors.New("the network does not run clique")
+	}
+	// Replace the chain config, which go-ethereum stores without the ct settings
+	if err := genesis.Config.CheckConfigForkOrder(); err != nil {
+		return err
+	}
+	rawdb.WriteChainConfig(db, stored, genesis.Config)
+	log.Info("Replaced chain config", "genesis", stored)
+
+	migrated, err := clique.MigrateSnapshots(db)
+	if err != nil {
+		return fmt.Errorf("failed to migrate clique snapshots: %v", err)
+	}
+	log.Info("Migrated clique snapshots", "count", migrated)
+	return nil
+}
+
+func dbMigrate(ctx *cli.Context) error {
+	if !rawdb.PebbleEnabled {
+		return errors.New("pebble is not supported on this platform")
+	}
+	// The node holds the datadir lock, so the database cannot be in use
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	var (
+		path    = stack.ResolvePath("chaindata")
+		ancient = stack.ResolveAncient("chaindata", ctx.String(utils.AncientFlag.Name))
+		target  = path + ".pebble"
+		backup  = path + ".leveldb"
+
+		cache   = ctx.Int(utils.CacheFlag.Name) * ctx.Int(utils.CacheDatabaseFlag.Name) / 100
+		handles = utils.MakeDatabaseHandles(0) / 2 // Both databases are open at once
+	)
+	switch kind := rawdb.PreexistingDatabase(path); kind {
+	case "":
+		return fmt.Errorf("no chain database found at %s", path)
+	case "leveldb":
+	default:
+		return fmt.Errorf("chain database at %s is already %s", path, kind)
+	}
+	for _, leftover := range []string{target, backup} {
+		if common.FileExist(leftover) {
+			return fmt.Errorf("%s left over from an earlier migration, remove it first", leftover)
+		}
+	}
+	src, err := rawdb.NewLevelDBDatabase(path, cache/2, handles, "", true)
+	if err != nil {
+		return err
+	}
+	dst, err := rawdb.NewPebbleDBDatabase(target, cache/2, handles, "", false)
+	if err != nil {
+		src.Close()
+		return err
+	}
+	var (
+		start  = time.Now()
+		logged = time.Now()
+		count  uint64
+		size   common.StorageSize
+		batch  = dst.NewBatch()
+		it     = src.NewIterator(nil, nil)
+	)
+	for it.Next() {
+		if err = batch.Put(it.Key(), it.Value()); err != nil {
+			break
+		}
+		count++
+		size += common.StorageSize(len(it.Key()) + len(it.Value()))
+
+		if batch.ValueSize() >= ethdb.IdealBatchSize {
+			if err = batch.Write(); err != nil {
+				break
+			}
+			batch.Reset()
+		}
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Migrating chain database", "entries", count, "size", size, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	if err == nil {
+		err = it.Error()
+	}
+	it.Release()
+	if err == nil {
+		err = batch.Write()
+	}
+	if err == nil {
+		log.Info("Compacting migrated database")
+		err = dst.Compact(nil, nil)
+	}
+	src.Close()
+	if cerr := dst.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.RemoveAll(target)
+		return err
+	}
+	// Swap the databases in place, moving the ancients along if kept in the
+	// chain database directory
+	if err := os.Rename(path, backup); err != nil {
+		return err
+	}
+	if err := os.Rename(target, path); err != nil {
+		return err
+	}
+	if ancient == filepath.Join(path, "ancient") && common.FileExist(filepath.Join(backup, "ancient")) {
+		if err := os.Rename(filepath.Join(backup, "ancient"), ancient); err != nil {
+			return err
+		}
+	}
+	log.Info("Migrated chain database to pebble", "entries", count, "size", size, "backup", backup, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
@@ -0,0 +1,373 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of go-ctereum.
+//
+// go-ctereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ctereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ctereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/qydata/go-ctereum/cmd/utils"
+	"github.com/qydata/go-ctereum/common"
+	"github.com/qydata/go-ctereum/common/hexutil"
+	"github.com/qydata/go-ctereum/core/rawdb"
+	"github.com/qydata/go-ctereum/core/state"
+	"github.com/qydata/go-ctereum/core/types"
+	"github.com/qydata/go-ctereum/crypto"
+	"github.com/qydata/go-ctereum/ethdb"
+	"github.com/qydata/go-ctereum/internal/flags"
+	"github.com/qydata/go-ctereum/rlp"
+	"github.com/qydata/go-ctereum/trie"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	contractBlockFlag = &cli.Uint64Flag{
+		Name:  "block",
+		Usage: "Number of the block whose state is inspected (default = head block)",
+	}
+	contractLayoutFlag = &cli.PathFlag{
+		Name:      "layout",
+		Usage:     "Storage layout of the contracts, as output by solc --storage-layout, used to decode their slots",
+		TakesFile: true,
+	}
+	contractLimitFlag = &cli.Uint64Flag{
+		Name:  "limit",
+		Usage: "Maximum number of storage slots dumped per contract (0 = no limit)",
+	}
+	dbInspectContractCmd = &cli.Command{
+		Action:    dbInspectContract,
+		Name:      "inspect-contract",
+		Usage:     "Dump the code and storage of contracts",
+		ArgsUsage: "[<address>...]",
+		Flags: flags.Merge([]cli.Flag{
+			utils.SyncModeFlag,
+			contractBlockFlag,
+			contractLayoutFlag,
+			contractLimitFlag,
+			inspectJSONFlag,
+		}, utils.NetworkFlags, utils.DatabasePathFlags),
+		Description: `This command reads the state of the head block (or of the one given by --block)
+from the datadir without starting the node, and prints the code hash, code size and
+storage slots of the given contracts, defaulting to the validator contract and the
+AuthController of the chain config.
+
+The slots are keyed by their hash in the storage trie, the slot itself is shown if
+its preimage is recorded. With --layout, the state variables of the solc storage
+layout are decoded from the slots they are stored in. Mappings and dynamic arrays
+elements live at hashed slots which can't be attributed to them.`,
+	}
+)
+
+// contractReport is the code and storage of a contract at a given block.
+type contractReport struct {
+	Name        string         `json:"name,omitempty"`
+	Address     common.Address `json:"address"`
+	Exists      bool           `json:"exists"`
+	CodeHash    common.Hash    `json:"codeHash"`
+	CodeSize    int            `json:"codeSize"`
+	StorageRoot common.Hash    `json:"storageRoot"`
+	Slots       []*storageSlot `json:"slots"`
+	Truncated   bool           `json:"truncated,omitempty"` // Whether the dump stopped at the limit
+}
+
+// storageSlot is a non-empty storage slot of a contract.
+type storageSlot struct {
+	Hash      common.Hash     `json:"hash"`           // Key of the slot in the storage trie
+	Slot      *common.Hash    `json:"slot,omitempty"` // Slot number, if known
+	Value     common.Hash     `json:"value"`
+	Variables []*slotVariable `json:"variables,omitempty"` // State variables decoded from the slot
+}
+
+// slotVariable is a state variable decoded from a storage slot.
+type slotVariable struct {
+	Label string `json:"label"`
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// storageLayout is the storage layout of a contract, as output by solc with the
+// --storage-layout option.
+type storageLayout struct {
+	Storage []struct {
+		Label  string `json:"label"`
+		Offset int    `json:"offset"`
+		Slot   string `json:"slot"`
+		Type   string `json:"type"`
+	} `json:"storage"`
+	Types map[string]struct {
+		Encoding      string `json:"encoding"`
+		Label         string `json:"label"`
+		NumberOfBytes string `json:"numberOfBytes"`
+	} `json:"types"`
+}
+
+// layoutVariable is a state variable of a storage layout.
+type layoutVariable struct {
+	label    string
+	typ      string // Solidity type, e.g. uint256
+	encoding string // Encoding of the type: inplace, bytes, dynamic_array or mapping
+	offset   int    // Offset of the variable within the slot, from the lower bytes
+	size     int    // Number of bytes of the variable within the slot
+}
+
+// loadStorageLayout reads a storage layout file, returning the state variables
+// it places at each slot, keyed by the hash of the slot.
+func loadStorageLayout(path string) (map[common.Hash][]*layoutVariable, map[common.Hash]common.Hash, error) {
+	blob, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	var layout storageLayout
+	if err := json.Unmarshal(blob, &layout); err != nil {
+		return nil, nil, fmt.Errorf("invalid storage layout %s: %v", path, err)
+	}
+	var (
+		vars  = make(map[common.Hash][]*layoutVariable)
+		slots = make(map[common.Hash]common.Hash)
+	)
+	for _, entry := range layout.Storage {
+		number, ok := new(big.Int).SetString(entry.Slot, 10)
+		if !ok {
+			return nil, nil, fmt.Errorf("invalid slot %q of %s", entry.Slot, entry.Label)
+		}
+		typ, ok := layout.Types[entry.Type]
+		if !ok {
+			return nil, nil, fmt.Errorf("unknown type %s of %s", entry.Type, entry.Label)
+		}
+		size, err := strconv.Atoi(typ.NumberOfBytes)
+		if err != nil || size <= 0 || size > 32 {
+			// Structs and static arrays span several slots, only their first one
+			// is attributed to them
+			size = 32
+		}
+		slot := common.BigToHash(number)
+		hash := crypto.Keccak256Hash(slot[:])
+		slots[hash] = slot
+		vars[hash] = append(vars[hash], &layoutVariable{
+			label:    entry.Label,
+			typ:      typ.Label,
+			encoding: typ.Encoding,
+			offset:   entry.Offset,
+			size:     size,
+		})
+	}
+	return vars, slots, nil
+}
+
+// decode decodes the value of the variable from its slot.
+func (v *layoutVariable) decode(slot common.Hash) string {
+	switch v.encoding {
+	case "dynamic_array":
+		return fmt.Sprintf("length %d", new(big.Int).SetBytes(slot[:]))
+	case "bytes":
+		// Values up to 31 bytes are stored along with twice their length in the
+		// slot, longer ones in hashed slots with twice their length plus one
+		if slot[31]&1 == 1 {
+			return fmt.Sprintf("length %d", new(big.Int).Rsh(new(big.Int).SetBytes(slot[:]), 1))
+		}
+		size := int(slot[31] / 2)
+		if size >= common.HashLength {
+			return hexutil.Encode(slot[:])
+		}
+		data := slot[:size]
+		if v.typ == "string" {
+			return strconv.Quote(string(data))
+		}
+		return hexutil.Encode(data)
+	case "mapping":
+		return "mapping"
+	}
+	if v.offset < 0 || v.offset+v.size > common.HashLength {
+		return hexutil.Encode(slot[:])
+	}
+	data := slot[common.HashLength-v.offset-v.size : common.HashLength-v.offset]
+	switch {
+	case v.typ == "address" || v.typ == "address payable" || strings.HasPrefix(v.typ, "contract "):
+		return common.BytesToAddress(data).Hex()
+	case v.typ == "bool":
+		return strconv.FormatBool(new(big.Int).SetBytes(data).Sign() != 0)
+	case strings.HasPrefix(v.typ, "uint") || strings.HasPrefix(v.typ, "enum "):
+		return new(big.Int).SetBytes(data).String()
+	case strings.HasPrefix(v.typ, "int"):
+		value := new(big.Int).SetBytes(data)
+		if data[0]&0x80 != 0 {
+			value.Sub(value, new(big.Int).Lsh(common.Big1, uint(8*len(data))))
+		}
+		return value.String()
+	}
+	return hexutil.Encode(data)
+}
+
+func dbInspectContract(ctx *cli.Context) error {
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	db := utils.MakeChainDatabase(ctx, stack, true)
+	defer db.Close()
+
+	var header *types.Header
+	if ctx.IsSet(contractBlockFlag.Name) {
+		number := ctx.Uint64(contractBlockFlag.Name)
+		if hash := rawdb.ReadCanonicalHash(db, number); hash != (common.Hash{}) {
+			header = rawdb.ReadHeader(db, hash, number)
+		}
+		if header == nil {
+			utils.Fatalf("Block #%d not found", number)
+		}
+	} else {
+		block := rawdb.ReadHeadBlock(db)
+		if block == nil {
+			utils.Fatalf("No head block in the datadir")
+		}
+		header = block.Header()
+	}
+	statedb, err := state.New(header.Root, state.NewDatabase(db), nil)
+	if err != nil {
+		utils.Fatalf("State of block #%d is not available: %v", header.Number, err)
+	}
+	// Inspect the given contracts, or the ones of the chain config
+	var reports []*contractReport
+	for _, arg := range ctx.Args().Slice() {
+		if !common.IsHexAddress(arg) {
+			utils.Fatalf("Invalid contract address %q", arg)
+		}
+		reports = append(reports, &contractReport{Address: common.HexToAddress(arg)})
+	}
+	if len(reports) == 0 {
+		config := rawdb.ReadChainConfig(db, rawdb.ReadCanonicalHash(db, 0))
+		if config == nil {
+			utils.Fatalf("No chain config in the datadir")
+		}
+		if config.Clique != nil && common.IsHexAddress(config.Clique.ValidatorContract) {
+			reports = append(reports, &contractReport{Name: "validator contract", Address: common.HexToAddress(config.Clique.ValidatorContract)})
+		}
+		if config.AuthBlock != nil {
+			reports = append(reports, &contractReport{Name: "auth controller", Address: config.AuthContractAt(header.Number)})
+		}
+		if len(reports) == 0 {
+			utils.Fatalf("No validator or auth contract in the chain config, specify the contract address")
+		}
+	}
+	var (
+		vars  map[common.Hash][]*layoutVariable
+		slots map[common.Hash]common.Hash
+	)
+	if ctx.IsSet(contractLayoutFlag.Name) {
+		if vars, slots, err = loadStorageLayout(ctx.Path(contractLayoutFlag.Name)); err != nil {
+			utils.Fatalf("Failed to load storage layout: %v", err)
+		}
+	}
+	limit := ctx.Uint64(contractLimitFlag.Name)
+	for _, report := range reports {
+		if err := report.fill(statedb, db, vars, slots, limit); err != nil {
+			utils.Fatalf("Failed to dump the storage of %s: %v", report.Address.Hex(), err)
+		}
+	}
+	if ctx.Bool(inspectJSONFlag.Name) {
+		out, err := json.MarshalIndent(reports, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(out))
+		return nil
+	}
+	fmt.Printf("State of block #%d (%s)\n", header.Number, header.Hash().Hex())
+	for _, report := range reports {
+		printContractReport(report)
+	}
+	return nil
+}
+
+// fill retrieves the code and storage of the contract from the state, up to the
+// given number of slots.
+func (r *contractReport) fill(statedb *state.StateDB, db ethdb.KeyValueReader, vars map[common.Hash][]*layoutVariable, slots map[common.Hash]common.Hash, limit uint64) error {
+	r.Slots = []*storageSlot{}
+	if r.Exists = statedb.Exist(r.Address); !r.Exists {
+		return nil
+	}
+	r.CodeHash = statedb.GetCodeHash(r.Address)
+	r.CodeSize = statedb.GetCodeSize(r.Address)
+
+	storage := statedb.StorageTrie(r.Address)
+	if storage == nil {
+		return nil
+	}
+	r.StorageRoot = storage.Hash()
+
+	it := trie.NewIterator(storage.NodeIterator(nil))
+	for it.Next() {
+		if limit > 0 && uint64(len(r.Slots)) == limit {
+			r.Truncated = true
+			break
+		}
+		_, content, _, err := rlp.Split(it.Value)
+		if err != nil {
+			return err
+		}
+		entry := &storageSlot{
+			Hash:  common.BytesToHash(it.Key),
+			Value: common.BytesToHash(content),
+		}
+		if slot, ok := slots[entry.Hash]; ok {
+			entry.Slot = &slot
+		} else if preimage := rawdb.ReadPreimage(db, entry.Hash); len(preimage) == common.HashLength {
+			slot := common.BytesToHash(preimage)
+			entry.Slot = &slot
+		}
+		for _, v := range vars[entry.Hash] {
+			entry.Variables = append(entry.Variables, &slotVariable{Label: v.label, Type: v.typ, Value: v.decode(entry.Value)})
+		}
+		r.Slots = append(r.Slots, entry)
+	}
+	return it.Err
+}
+
+// printContractReport prints the code and storage of a contract in a human
+// readable form.
+func printContractReport(r *contractReport) {
+	fmt.Printf("\nContract %s", r.Address.Hex())
+	if r.Name != "" {
+		fmt.Printf(" (%s)", r.Name)
+	}
+	fmt.Println()
+	if !r.Exists {
+		fmt.Println("  Account does not exist")
+		return
+	}
+	fmt.Printf("  Code hash:    %s\n", r.CodeHash.Hex())
+	fmt.Printf("  Code size:    %d bytes\n", r.CodeSize)
+	fmt.Printf("  Storage root: %s\n", r.StorageRoot.Hex())
+	fmt.Printf("  Storage (%d slots", len(r.Slots))
+	if r.Truncated {
+		fmt.Print(", truncated")
+	}
+	fmt.Println("):")
+	for _, entry := range r.Slots {
+		key := "hash " + entry.Hash.Hex()
+		if entry.Slot != nil {
+			key = "slot " + entry.Slot.Big().String()
+		}
+		fmt.Printf("    %s: %s\n", key, entry.Value.Hex())
+		for _, v := range entry.Variables {
+			fmt.Printf("      %s %s = %s\n", v.Type, v.Label, v.Value)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of go-ctereum.
+//
+// go-ctereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ctereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ctereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const inspectContractGenesis = `{
+	"config": {
+		"chainId": 1337,
+		"clique": {"period": 1, "epoch": 30000, "validatorcontract": "0x00000000000000000000000000000000000000b0"}
+	},
+	"difficulty": "1",
+	"gasLimit": "8000000",
+	"extraData": "0x0000000000000000000000000000000000000000000000000000000000000000a0000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000",
+	"alloc": {
+		"00000000000000000000000000000000000000b0": {
+			"balance": "0",
+			"code": "0x6001",
+			"storage": {
+				"0x0000000000000000000000000000000000000000000000000000000000000000": "0x000000000000000000000001000000000000000000000000000000000000abcd",
+				"0x0000000000000000000000000000000000000000000000000000000000000001": "0x6374000000000000000000000000000000000000000000000000000000000004"
+			}
+		}
+	}
+}`
+
+const inspectContractLayout = `{
+	"storage": [
+		{"label": "owner", "offset": 0, "slot": "0", "type": "t_address"},
+		{"label": "active", "offset": 20, "slot": "0", "type": "t_bool"},
+		{"label": "name", "offset": 0, "slot": "1", "type": "t_string_storage"}
+	],
+	"types": {
+		"t_address": {"encoding": "inplace", "label": "address", "numberOfBytes": "20"},
+		"t_bool": {"encoding": "inplace", "label": "bool", "numberOfBytes": "1"},
+		"t_string_storage": {"encoding": "bytes", "label": "string", "numberOfBytes": "32"}
+	}
+}`
+
+// Tests that the storage of the validator contract is dumped from the datadir
+// and decoded with the storage layout.
+func TestInspectContract(t *testing.T) {
+	datadir := t.TempDir()
+
+	genesis := filepath.Join(datadir, "genesis.json")
+	if err := os.WriteFile(genesis, []byte(inspectContractGenesis), 0600); err != nil {
+		t.Fatalf("failed to write genesis file: %v", err)
+	}
+	layout := filepath.Join(datadir, "layout.json")
+	if err := os.WriteFile(layout, []byte(inspectContractLayout), 0600); err != nil {
+		t.Fatalf("failed to write layout file: %v", err)
+	}
+	runGeth(t, "--datadir", datadir, "init", genesis).WaitExit()
+
+	geth := runGeth(t, "--datadir", datadir, "db", "inspect-contract", "--layout", layout)
+	geth.ExpectRegexp(`Contract 0x00000000000000000000000000000000000000B0 \(validator contract\)`)
+	geth.ExpectRegexp(`Code size:\s+2 bytes`)
+	geth.ExpectRegexp(`address owner = 0x000000000000000000000000000000000000ABcD`)
+	geth.ExpectRegexp(`bool active = true`)
+	geth.ExpectRegexp(`string name = "ct"`)
+	geth.ExpectExit()
+}
@@ -0,0 +1,347 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of go-ctereum.
+//
+// go-ctereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ctereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ctereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/qydata/go-ctereum/cmd/utils"
+	"github.com/qydata/go-ctereum/common"
+	"github.com/qydata/go-ctereum/common/hexutil"
+	"github.com/qydata/go-ctereum/common/math"
+	"github.com/qydata/go-ctereum/core"
+	"github.com/qydata/go-ctereum/params"
+	cli "github.com/urfave/cli/v2"
+)
+
+var (
+	genesisChainIDFlag = &cli.Uint64Flag{
+		Name:  "chainid",
+		Usage: "Chain ID of the network",
+	}
+	genesisPeriodFlag = &cli.Uint64Flag{
+		Name:  "period",
+		Usage: "Clique block period in seconds",
+		Value: 5,
+	}
+	genesisEpochFlag = &cli.Uint64Flag{
+		Name:  "epoch",
+		Usage: "Clique epoch length in blocks",
+		Value: 30000,
+	}
+	genesisSignerFlag = &cli.StringSliceFlag{
+		Name:  "signer",
+		Usage: "Address of an initial clique signer (repeatable)",
+	}
+	genesisPremineFlag = &cli.StringSliceFlag{
+		Name:  "premine",
+		Usage: "Premined balance in wei as <address>=<amount> (repeatable)",
+	}
+	genesisForkFlag = &cli.StringSliceFlag{
+		Name:  "fork",
+		Usage: "Fork activation as <name>=<block>, an empty block disabling it (repeatable)",
+	}
+	genesisTimestampFlag = &cli.Uint64Flag{
+		Name:  "timestamp",
+		Usage: "Timestamp of the genesis block",
+	}
+	genesisGasLimitFlag = &cli.Uint64Flag{
+		Name:  "gaslimit",
+		Usage: "Gas limit of the genesis block",
+		Value: params.GenesisGasLimit,
+	}
+	genesisAuthFlag = &cli.StringFlag{
+		Name:  "authcontroller",
+		Usage: "Address to predeploy the AuthController at",
+	}
+	genesisAuthCodeFlag = &cli.StringFlag{
+		Name:  "authcontroller.code",
+		Usage: "File with the hex encoded creation code of the AuthController, including constructor arguments",
+	}
+	genesisAuthOwnerFlag = &cli.StringFlag{
+		Name:  "authcontroller.owner",
+		Usage: "Owner of the AuthController, deploying it",
+	}
+	genesisValidatorsFlag = &cli.StringFlag{
+		Name:  "validators",
+		Usage: "Address to predeploy the validator contract at",
+	}
+	genesisValidatorsCodeFlag = &cli.StringFlag{
+		Name:  "validators.code",
+		Usage: "File with the hex encoded creation code of the validator contract, including constructor arguments",
+	}
+	genesisValidatorsOwnerFlag = &cli.StringFlag{
+		Name:  "validators.owner",
+		Usage: "Owner of the validator contract, deploying it",
+	}
+	genesisValidatorsStakeFlag = &cli.Int64Flag{
+		Name:  "validators.stake",
+		Usage: "Stake required from validators",
+	}
+	genesisOutFlag = &cli.StringFlag{
+		Name:  "out",
+		Usage: "File to write the genesis to (default = stdout)",
+	}
+	exportVanityFlag = &cli.StringFlag{
+		Name:  "vanity",
+		Usage: "Hex encoded vanity prefix of the extra-data, up to 32 bytes",
+	}
+	exportGenesisFlag = &cli.BoolFlag{
+		Name:  "genesis",
+		Usage: "Write a genesis stub carrying the extra-data instead of the extra-data alone",
+	}
+
+	exportValidatorsCommand = &cli.Command{
+		Action:    exportValidators,
+		Name:      "export-validators",
+		Usage:     "Generate the clique genesis extra-data of a signer list",
+		ArgsUsage: "<signers.json>",
+		Flags: []cli.Flag{
+			exportVanityFlag,
+			exportGenesisFlag,
+			genesisChainIDFlag,
+			genesisPeriodFlag,
+			genesisEpochFlag,
+			genesisOutFlag,
+		},
+		Description: `
+    geth export-validators [--vanity <hex>] [--genesis --chainid <id>] <signers.json>
+
+Reads a JSON list of the addresses of the initial clique signers and assembles
+the extraData field of the genesis from it: the vanity padded to 32 bytes, the
+signers in ascending order and an empty 65 byte seal. With --genesis, a genesis
+stub with all forks up to London active from the start is written out instead,
+to be completed with the premines and predeployed contracts of the network.`,
+	}
+
+	genesisCommand = &cli.Command{
+		Name:  "genesis",
+		Usage: "Generate genesis files for ct networks",
+		Subcommands: []*cli.Command{
+			{
+				Name:   "new",
+				Usage:  "Generate the genesis of a new ct network",
+				Action: genesisNew,
+				Flags: []cli.Flag{
+					genesisChainIDFlag,
+					genesisPeriodFlag,
+					genesisEpochFlag,
+					genesisSignerFlag,
+					genesisPremineFlag,
+					genesisForkFlag,
+					genesisTimestampFlag,
+					genesisGasLimitFlag,
+					genesisAuthFlag,
+					genesisAuthCodeFlag,
+					genesisAuthOwnerFlag,
+					genesisValidatorsFlag,
+					genesisValidatorsCodeFlag,
+					genesisValidatorsOwnerFlag,
+					genesisValidatorsStakeFlag,
+					genesisOutFlag,
+				},
+				Description: `
+    geth genesis new --chainid <id> --signer <address> [--signer <address>...]
+
+Generates the genesis of a clique network with all forks up to London active
+from the start. The clique extra-data is assembled from the signers, and the
+AuthController and validator contracts are predeployed by running their creation
+code, so the genesis carries the state set up by their constructors. The same
+parameters always produce the same genesis block.`,
+			},
+		},
+	}
+)
+
+// genesisNew assembles a genesis from the command line parameters and writes
+// it out as JSON.
+func genesisNew(ctx *cli.Context) error {
+	if !ctx.IsSet(genesisChainIDFlag.Name) {
+		utils.Fatalf("Chain ID must be specified with --%s", genesisChainIDFlag.Name)
+	}
+	builder := core.NewGenesisBuilder(new(big.Int).SetUint64(ctx.Uint64(genesisChainIDFlag.Name)))
+	builder.SetPeriod(ctx.Uint64(genesisPeriodFlag.Name))
+	builder.SetEpoch(ctx.Uint64(genesisEpochFlag.Name))
+	builder.SetTimestamp(ctx.Uint64(genesisTimestampFlag.Name))
+	builder.SetGasLimit(ctx.Uint64(genesisGasLimitFlag.Name))
+
+	for _, signer := range ctx.StringSlice(genesisSignerFlag.Name) {
+		builder.AddSigner(parseGenesisAddress(signer))
+	}
+	for _, premine := range ctx.StringSlice(genesisPremineFlag.Name) {
+		addr, amount, ok := strings.Cut(premine, "=")
+		if !ok {
+			utils.Fatalf("Invalid premine %q, expected <address>=<amount>", premine)
+		}
+		balance, ok := math.ParseBig256(amount)
+		if !ok {
+			utils.Fatalf("Invalid premine amount %q", amount)
+		}
+		builder.AddPremine(parseGenesisAddress(addr), balance)
+	}
+	if ctx.IsSet(genesisAuthFlag.Name) {
+		deployment, err := genesisDeployment(ctx, genesisAuthFlag, genesisAuthCodeFlag, genesisAuthOwnerFlag)
+		if err != nil {
+			utils.Fatalf("Invalid AuthController: %v", err)
+		}
+		builder.SetAuthController(deployment)
+	}
+	if ctx.IsSet(genesisValidatorsFlag.Name) {
+		deployment, err := genesisDeployment(ctx, genesisValidatorsFlag, genesisValidatorsCodeFlag, genesisValidatorsOwnerFlag)
+		if err != nil {
+			utils.Fatalf("Invalid validator contract: %v", err)
+		}
+		builder.SetValidatorContract(deployment, ctx.Int64(genesisValidatorsStakeFlag.Name))
+	}
+	// Apply the fork schedule last to allow overriding the contract activations
+	for _, fork := range ctx.StringSlice(genesisForkFlag.Name) {
+		name, number, ok := strings.Cut(fork, "=")
+		if !ok {
+			utils.Fatalf("Invalid fork %q, expected <name>=<block>", fork)
+		}
+		var block *big.Int
+		if number != "" {
+			if block, ok = math.ParseBig256(number); !ok {
+				utils.Fatalf("Invalid fork block %q", number)
+			}
+		}
+		if err := builder.SetFork(name, block); err != nil {
+			utils.Fatalf("Invalid fork: %v", err)
+		}
+	}
+	genesis, err := builder.Build()
+	if err != nil {
+		utils.Fatalf("Failed to build genesis: %v", err)
+	}
+	out, err := json.MarshalIndent(genesis, "", "  ")
+	if err != nil {
+		utils.Fatalf("Failed to encode genesis: %v", err)
+	}
+	out = append(out, '\n')
+	if path := ctx.String(genesisOutFlag.Name); path != "" {
+		if err := os.WriteFile(path, out, 0644); err != nil {
+			utils.Fatalf("Failed to write genesis: %v", err)
+		}
+		return nil
+	}
+	_, err = os.Stdout.Write(out)
+	return err
+}
+
+// exportValidators assembles the clique extra-data of the signers listed in the
+// JSON file, writing it out either alone or within a genesis stub.
+func exportValidators(ctx *cli.Context) error {
+	if ctx.Args().Len() != 1 {
+		utils.Fatalf("This command requires an argument.")
+	}
+	blob, err := os.ReadFile(ctx.Args().First())
+	if err != nil {
+		utils.Fatalf("Failed to read signer list: %v", err)
+	}
+	var list []string
+	if err := json.Unmarshal(blob, &list); err != nil {
+		utils.Fatalf("Invalid signer list, expected a JSON list of addresses: %v", err)
+	}
+	signers := make([]common.Address, len(list))
+	for i, signer := range list {
+		signers[i] = parseGenesisAddress(signer)
+	}
+	var vanity []byte
+	if ctx.IsSet(exportVanityFlag.Name) {
+		if vanity, err = hexutil.Decode(ctx.String(exportVanityFlag.Name)); err != nil {
+			utils.Fatalf("Invalid vanity: %v", err)
+		}
+	}
+	extra, err := core.CliqueExtraData(vanity, signers)
+	if err != nil {
+		utils.Fatalf("Invalid signers: %v", err)
+	}
+	out := []byte(hexutil.Encode(extra) + "\n")
+
+	if ctx.Bool(exportGenesisFlag.Name) {
+		if !ctx.IsSet(genesisChainIDFlag.Name) {
+			utils.Fatalf("Chain ID must be specified with --%s", genesisChainIDFlag.Name)
+		}
+		builder := core.NewGenesisBuilder(new(big.Int).SetUint64(ctx.Uint64(genesisChainIDFlag.Name)))
+		builder.SetPeriod(ctx.Uint64(genesisPeriodFlag.Name))
+		builder.SetEpoch(ctx.Uint64(genesisEpochFlag.Name))
+		builder.SetVanity(vanity)
+		for _, signer := range signers {
+			builder.AddSigner(signer)
+		}
+		genesis, err := builder.Build()
+		if err != nil {
+			utils.Fatalf("Failed to build genesis: %v", err)
+		}
+		if out, err = json.MarshalIndent(genesis, "", "  "); err != nil {
+			utils.Fatalf("Failed to encode genesis: %v", err)
+		}
+		out = append(out, '\n')
+	}
+	if path := ctx.String(genesisOutFlag.Name); path != "" {
+		if err := os.WriteFile(path, out, 0644); err != nil {
+			utils.Fatalf("Failed to write output: %v", err)
+		}
+		return nil
+	}
+	_, err = os.Stdout.Write(out)
+	return err
+}
+
+// genesisDeployment assembles a contract predeployment from its address, code
+// file and owner flags.
+func genesisDeployment(ctx *cli.Context, addrFlag, codeFlag, ownerFlag *cli.StringFlag) (*core.GenesisDeployment, error) {
+	if !ctx.IsSet(codeFlag.Name) {
+		return nil, fmt.Errorf("creation code must be specified with --%s", codeFlag.Name)
+	}
+	if !ctx.IsSet(ownerFlag.Name) {
+		return nil, fmt.Errorf("owner must be specified with --%s", ownerFlag.Name)
+	}
+	blob, err := os.ReadFile(ctx.String(codeFlag.Name))
+	if err != nil {
+		return nil, err
+	}
+	hex := strings.TrimSpace(string(blob))
+	if !strings.HasPrefix(hex, "0x") {
+		hex = "0x" + hex
+	}
+	code, err := hexutil.Decode(hex)
+	if err != nil {
+		return nil, fmt.Errorf("invalid creation code: %v", err)
+	}
+	if len(code) == 0 {
+		return nil, errors.New("empty creation code")
+	}
+	return &core.GenesisDeployment{
+		Address:  parseGenesisAddress(ctx.String(addrFlag.Name)),
+		Deployer: parseGenesisAddress(ctx.String(ownerFlag.Name)),
+		Code:     code,
+	}, nil
+}
+
+// parseGenesisAddress parses a hex address, aborting on invalid input.
+func parseGenesisAddress(s string) common.Address {
+	if !common.IsHexAddress(s) {
+		utils.Fatalf("Invalid address %q", s)
+	}
+	return common.HexToAddress(s)
+}
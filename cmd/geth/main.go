@@ -70,6 +70,11 @@ var (
 		utils.SmartCardDaemonPathFlag,
 		utils.OverrideTerminalTotalDifficulty,
 		utils.OverrideTerminalTotalDifficultyPassed,
+		utils.AuthContractFlag,
+		utils.AuthRegistryFlag,
+		utils.ValidatorContractFlag,
+		utils.CliqueActivityWindowFlag,
+		utils.StrictForksFlag,
 		utils.EthashCacheDirFlag,
 		utils.EthashCachesInMemoryFlag,
 		utils.EthashCachesOnDiskFlag,
@@ -89,6 +94,8 @@ var (
 		utils.TxPoolAccountQueueFlag,
 		utils.TxPoolGlobalQueueFlag,
 		utils.TxPoolLifetimeFlag,
+		utils.TxPoolAuthSendersFlag,
+		utils.TxPoolAuthAllowlistFlag,
 		utils.SyncModeFlag,
 		utils.ExitWhenSyncedFlag,
 		utils.GCModeFlag,
@@ -102,8 +109,11 @@ var (
 		utils.LightKDFFlag,
 		utils.UltraLightServersFlag,
 		utils.UltraLightFractionFlag,
+		utils.UltraLightQuorumFlag,
 		utils.UltraLightOnlyAnnounceFlag,
 		utils.LightNoSyncServeFlag,
+		utils.LightValidatorBalanceFlag,
+		utils.LightCliqueCheckpointFlag,
 		utils.EthRequiredBlocksFlag,
 		utils.LegacyWhitelistFlag,
 		utils.BloomFilterSizeFlag,
@@ -116,6 +126,10 @@ var (
 		utils.CacheSnapshotFlag,
 		utils.CacheNoPrefetchFlag,
 		utils.CachePreimagesFlag,
+		utils.ParallelEVMFlag,
+		utils.StateDiffsFlag,
+		utils.StateWitnessesFlag,
+		utils.LogIndexFlag,
 		utils.CacheLogSizeFlag,
 		utils.FDLimitFlag,
 		utils.ListenPortFlag,
@@ -142,6 +156,10 @@ var (
 		utils.DeveloperFlag,
 		utils.DeveloperPeriodFlag,
 		utils.DeveloperGasLimitFlag,
+		utils.DeveloperCtFlag,
+		utils.DeveloperCtAuthCodeFlag,
+		utils.DeveloperCtValidatorCodeFlag,
+		utils.DeveloperCtStakeFlag,
 		utils.VMEnableDebugFlag,
 		utils.NetworkIdFlag,
 		utils.EthStatsURLFlag,
@@ -151,6 +169,8 @@ var (
 		utils.GpoPercentileFlag,
 		utils.GpoMaxGasPriceFlag,
 		utils.GpoIgnoreGasPriceFlag,
+		utils.GpoMaxEmptyBlocksFlag,
+		utils.GpoMinTipFlag,
 		utils.MinerNotifyFullFlag,
 		utils.IgnoreLegacyReceiptsFlag,
 		configFileFlag,
@@ -165,6 +185,12 @@ var (
 		utils.AuthPortFlag,
 		utils.AuthVirtualHostsFlag,
 		utils.JWTSecretFlag,
+		utils.OperatorEnabledFlag,
+		utils.OperatorListenAddrFlag,
+		utils.OperatorPortFlag,
+		utils.OperatorVirtualHostsFlag,
+		utils.OperatorApiFlag,
+		utils.OperatorJWTSecretFlag,
 		utils.HTTPVirtualHostsFlag,
 		utils.GraphQLEnabledFlag,
 		utils.GraphQLCORSDomainFlag,
@@ -183,6 +209,11 @@ var (
 		utils.RPCGlobalGasCapFlag,
 		utils.RPCGlobalEVMTimeoutFlag,
 		utils.RPCGlobalTxFeeCapFlag,
+		utils.RPCLogsRangeLimitFlag,
+		utils.RPCLogsResultLimitFlag,
+		utils.BatchRequestLimitFlag,
+		utils.BatchResponseMaxSizeFlag,
+		utils.BatchConcurrencyFlag,
 		utils.AllowUnprotectedTxs,
 	}
 
@@ -214,11 +245,14 @@ func init() {
 		initCommand,
 		importCommand,
 		exportCommand,
+		importHistoryCommand,
+		exportHistoryCommand,
 		importPreimagesCommand,
 		exportPreimagesCommand,
 		removedbCommand,
 		dumpCommand,
 		dumpGenesisCommand,
+		inspectConsensusCommand,
 		// See accountcmd.go:
 		accountCommand,
 		walletCommand,
@@ -240,6 +274,12 @@ func init() {
 		utils.ShowDeprecated,
 		// See snapshot.go
 		snapshotCommand,
+		authCommand,
+		validatorCommand,
+		bootnodesCommand,
+		// See genesiscmd.go
+		genesisCommand,
+		exportValidatorsCommand,
 	}
 	sort.Sort(cli.CommandsByName(app.Commands))
 
@@ -20,7 +20,10 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/qydata/go-ctereum/cmd/utils"
@@ -44,6 +47,27 @@ var (
 
 	// emptyCode is the known hash of the empty EVM bytecode.
 	emptyCode = crypto.Keccak256(nil)
+
+	pruneForceFlag = &cli.BoolFlag{
+		Name:  "force",
+		Usage: "Prune the state even if the clique engine can't process the chain on top of the target",
+	}
+	verifyProgressFlag = &cli.StringFlag{
+		Name:  "progress",
+		Usage: "File to persist the progress of a chunked verification to (default = inside the datadir)",
+	}
+	verifyResumeFlag = &cli.BoolFlag{
+		Name:  "resume",
+		Usage: "Resume the chunked verification from the progress file",
+	}
+	verifyChunkFlag = &cli.Uint64Flag{
+		Name:  "chunk",
+		Usage: "Number of accounts to verify between two progress saves (0 = verify the whole state root at once)",
+	}
+	verifyIOLimitFlag = &cli.Float64Flag{
+		Name:  "iolimit",
+		Usage: "Maximum rate of snapshot data read during a chunked verification, in MB/s (0 = no limit)",
+	}
 )
 
 var (
@@ -60,7 +84,8 @@ var (
 				Flags: flags.Merge([]cli.Flag{
 					utils.CacheTrieJournalFlag,
 					utils.BloomFilterSizeFlag,
-				}, utils.NetworkFlags, utils.DatabasePathFlags),
+					pruneForceFlag,
+				}, utils.NetworkFlags, utils.DatabasePathFlags, metricsFlags),
 				Description: `
 geth snapshot prune-state <state-root>
 will prune historical state data with the help of the state snapshot.
@@ -70,6 +95,10 @@ two version states are available: genesis and the specific one.
 
 The default pruning target is the HEAD-127 state.
 
+On clique networks the pruning is refused if the target state is below
+the last checkpoint block or below the Poa2Pos transition block, as the
+chain can't be processed again on top of it. Use --force to prune anyway.
+
 WARNING: It's necessary to delete the trie clean cache after the pruning.
 If you specify another directory for the trie clean cache via "--cache.trie.journal"
 during the use of Geth, please also specify it here for correct deletion. Otherwise
@@ -81,12 +110,23 @@ the trie clean cache with default directory will be deleted.
 				Usage:     "Recalculate state hash based on the snapshot for verification",
 				ArgsUsage: "<root>",
 				Action:    verifyState,
-				Flags:     flags.Merge(utils.NetworkFlags, utils.DatabasePathFlags),
+				Flags: flags.Merge([]cli.Flag{
+					verifyProgressFlag,
+					verifyResumeFlag,
+					verifyChunkFlag,
+					verifyIOLimitFlag,
+				}, utils.NetworkFlags, utils.DatabasePathFlags),
 				Description: `
 geth snapshot verify-state <state-root>
 will traverse the whole accounts and storages set based on the specified
 snapshot and recalculate the root hash of state for verification.
 In other words, this command does the snapshot to trie conversion.
+
+With --chunk, --resume or --iolimit, the snapshot is instead checked account
+by account against the state trie, in chunks of the given number of accounts.
+The progress is saved to the --progress file after every chunk and when the
+command is interrupted, and --resume continues the verification from it. The
+rate of snapshot data read can be limited with --iolimit.
 `,
 			},
 			{
@@ -169,8 +209,15 @@ func pruneState(ctx *cli.Context) error {
 	stack, config := makeConfigNode(ctx)
 	defer stack.Close()
 
+	utils.SetupMetrics(ctx)
+
 	chaindb := utils.MakeChainDatabase(ctx, stack, false)
-	pruner, err := pruner.NewPruner(chaindb, stack.ResolvePath(""), stack.ResolvePath(config.Eth.TrieCleanCacheJournal), ctx.Uint64(utils.BloomFilterSizeFlag.Name))
+	pruner, err := pruner.NewPruner(chaindb, pruner.Config{
+		Datadir:       stack.ResolvePath(""),
+		TrieCachePath: stack.ResolvePath(config.Eth.TrieCleanCacheJournal),
+		BloomSize:     ctx.Uint64(utils.BloomFilterSizeFlag.Name),
+		Force:         ctx.Bool(pruneForceFlag.Name),
+	})
 	if err != nil {
 		log.Error("Failed to open snapshot tree", "err", err)
 		return err
@@ -221,14 +268,107 @@ func verifyState(ctx *cli.Context) error {
 			return err
 		}
 	}
-	if err := snaptree.Verify(root); err != nil {
-		log.Error("Failed to verify state", "root", root, "err", err)
-		return err
+	if ctx.IsSet(verifyChunkFlag.Name) || ctx.IsSet(verifyResumeFlag.Name) || ctx.IsSet(verifyIOLimitFlag.Name) {
+		path := ctx.String(verifyProgressFlag.Name)
+		if path == "" {
+			path = stack.ResolvePath("verify-state.json")
+		}
+		if err := verifyStateChunked(ctx, snaptree, root, path); err != nil {
+			return err
+		}
+	} else {
+		if err := snaptree.Verify(root); err != nil {
+			log.Error("Failed to verify state", "root", root, "err", err)
+			return err
+		}
+		log.Info("Verified the state", "root", root)
 	}
-	log.Info("Verified the state", "root", root)
 	return snapshot.CheckDanglingStorage(chaindb)
 }
 
+// verifyStateChunked checks the snapshot of the state against its trie in chunks,
+// persisting the progress to the given file after every chunk.
+func verifyStateChunked(ctx *cli.Context, snaptree *snapshot.Tree, root common.Hash, path string) error {
+	progress := &snapshot.VerifyProgress{Root: root}
+	if ctx.Bool(verifyResumeFlag.Name) {
+		blob, err := os.ReadFile(path)
+		switch {
+		case os.IsNotExist(err):
+			log.Info("No verification progress to resume, starting over", "path", path)
+		case err != nil:
+			return err
+		default:
+			if err := json.Unmarshal(blob, progress); err != nil {
+				return fmt.Errorf("invalid verification progress %s: %v", path, err)
+			}
+			if progress.Root != root {
+				return fmt.Errorf("verification progress %s is for root %x, not %x", path, progress.Root, root)
+			}
+			log.Info("Resuming state verification", "root", root, "next", progress.Next, "accounts", progress.Accounts)
+		}
+	}
+	var (
+		interrupt = make(chan os.Signal, 1)
+		throttle  = newVerifyThrottle(ctx.Float64(verifyIOLimitFlag.Name))
+		chunk     = ctx.Uint64(verifyChunkFlag.Name)
+		start     = time.Now()
+	)
+	signal.Notify(interrupt, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(interrupt)
+
+	for !progress.Done {
+		select {
+		case <-interrupt:
+			log.Info("Interrupted state verification", "next", progress.Next, "progress", path)
+			return errors.New("verification interrupted")
+		default:
+		}
+		err := snaptree.VerifyChunk(progress, chunk, throttle)
+		if serr := saveVerifyProgress(path, progress); serr != nil {
+			log.Error("Failed to save verification progress", "path", path, "err", serr)
+		}
+		if err != nil {
+			log.Error("Failed to verify state", "root", root, "next", progress.Next, "err", err)
+			return err
+		}
+		log.Info("Verifying state", "accounts", progress.Accounts, "slots", progress.Slots,
+			"size", common.StorageSize(progress.Bytes), "next", progress.Next, "elapsed", common.PrettyDuration(time.Since(start)))
+	}
+	log.Info("Verified the state", "root", root, "accounts", progress.Accounts, "slots", progress.Slots,
+		"size", common.StorageSize(progress.Bytes), "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+// saveVerifyProgress atomically writes the progress of a chunked verification.
+func saveVerifyProgress(path string, progress *snapshot.VerifyProgress) error {
+	blob, err := json.MarshalIndent(progress, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path+".tmp", blob, 0644); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// newVerifyThrottle creates a throttle limiting the rate of data read to the
+// given number of MB/s, or nil if there is no limit.
+func newVerifyThrottle(limit float64) func(int) {
+	if limit <= 0 {
+		return nil
+	}
+	var (
+		start = time.Now()
+		read  float64
+	)
+	return func(size int) {
+		read += float64(size)
+		if wait := time.Duration(read/(limit*1024*1024)*float64(time.Second)) - time.Since(start); wait > 0 {
+			time.Sleep(wait)
+		}
+	}
+}
+
 // checkDanglingStorage iterates the snap storage data, and verifies that all
 // storage also has corresponding account data.
 func checkDanglingStorage(ctx *cli.Context) error {
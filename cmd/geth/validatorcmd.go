@@ -0,0 +1,348 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of go-ctereum.
+//
+// go-ctereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ctereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ctereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/qydata/go-ctereum/accounts"
+	"github.com/qydata/go-ctereum/accounts/abi/bind"
+	"github.com/qydata/go-ctereum/accounts/external"
+	"github.com/qydata/go-ctereum/cmd/utils"
+	"github.com/qydata/go-ctereum/common"
+	"github.com/qydata/go-ctereum/common/hexutil"
+	"github.com/qydata/go-ctereum/common/math"
+	"github.com/qydata/go-ctereum/consensus/clique/contract"
+	"github.com/qydata/go-ctereum/consensus/clique/span"
+	"github.com/qydata/go-ctereum/core/types"
+	"github.com/qydata/go-ctereum/ethclient"
+	"github.com/qydata/go-ctereum/internal/ethapi"
+	"github.com/qydata/go-ctereum/internal/flags"
+	"github.com/qydata/go-ctereum/params"
+	"github.com/qydata/go-ctereum/rpc"
+	cli "github.com/urfave/cli/v2"
+)
+
+var (
+	validatorContractFlag = &cli.StringFlag{
+		Name:  "contract",
+		Usage: "Address of the validator contract (default = the one of the chain config of the node)",
+	}
+	validatorAmountFlag = &cli.StringFlag{
+		Name:  "amount",
+		Usage: "Amount to stake in wei (default = the amount missing to reach the validator threshold)",
+	}
+
+	validatorSignFlags = flags.Merge(authSignFlags, []cli.Flag{
+		utils.ExternalSignerFlag,
+		validatorContractFlag,
+	})
+
+	validatorCommand = &cli.Command{
+		Name:  "validator",
+		Usage: "Manage the stake of a validator on a running node",
+		Description: `
+The validator commands connect to a running node, by default through the IPC
+endpoint inside the datadir, and query or update the state of the validator
+contract. Updates are signed with a keystore account, or with an external signer
+like clef if one is given, and submitted as transactions. The threshold amount
+and the maximum number of validators are checked before submitting stakes.`,
+		Subcommands: []*cli.Command{
+			{
+				Name:      "status",
+				Usage:     "Print the validator status of an address",
+				ArgsUsage: "<address>",
+				Action:    validatorStatus,
+				Flags:     []cli.Flag{authEndpointFlag, utils.DataDirFlag, validatorContractFlag},
+				Description: `
+    geth validator status <address>
+
+Prints whether the address is a validator as of the latest block, along with its
+stake, its sender and the staking limits of the contract.`,
+			},
+			{
+				Name:      "stake",
+				Usage:     "Stake funds for a validator",
+				ArgsUsage: "[<address>]",
+				Action:    validatorStake,
+				Flags:     flags.Merge(validatorSignFlags, []cli.Flag{validatorAmountFlag}),
+				Description: `
+    geth validator stake --from <account> [--amount <wei>] [<address>]
+
+Stakes the amount on behalf of the address, the signing account by default. The
+stake is rejected before submission if it stays below the validator threshold,
+or if it would add a validator while the maximum number of validators is reached.`,
+			},
+			{
+				Name:      "unstake",
+				Usage:     "Withdraw the stake of a validator",
+				ArgsUsage: "[<address>]",
+				Action:    validatorUnstake,
+				Flags:     validatorSignFlags,
+				Description: `
+    geth validator unstake --from <account> [<address>]
+
+Withdraws the stake of the address, the signing account by default.`,
+			},
+			{
+				Name:      "set-sender",
+				Usage:     "Set the account sending the transactions of a validator",
+				ArgsUsage: "<sender>",
+				Action:    validatorSetSender,
+				Flags:     validatorSignFlags,
+				Description: `
+    geth validator set-sender --from <validator> <sender>
+
+Sets the sender of the validator signing the transaction.`,
+			},
+		},
+	}
+)
+
+// validatorStatusResult is the status printed by the status command.
+type validatorStatusResult struct {
+	Address       common.Address `json:"address"`
+	Contract      common.Address `json:"contract"`
+	IsValidator   bool           `json:"isValidator"`
+	Stake         *hexutil.Big   `json:"stake"`
+	Sender        common.Address `json:"sender"`
+	Threshold     *hexutil.Big   `json:"threshold"`
+	Validators    int            `json:"validators"`
+	MaxValidators *hexutil.Big   `json:"maxValidators"`
+}
+
+// rpcCaller executes the calls of the spanner through the RPC API of a node.
+type rpcCaller struct {
+	client *rpc.Client
+}
+
+func (c *rpcCaller) Call(ctx context.Context, args ethapi.TransactionArgs, blockNrOrHash rpc.BlockNumberOrHash, overrides *ethapi.StateOverride) (hexutil.Bytes, error) {
+	var result hexutil.Bytes
+	err := c.client.CallContext(ctx, &result, "eth_call", args, blockNrOrHash, overrides)
+	return result, err
+}
+
+func validatorStatus(ctx *cli.Context) error {
+	if ctx.Args().Len() != 1 {
+		utils.Fatalf("This command requires an address argument.")
+	}
+	addr := parseAuthAddress(ctx.Args().First())
+
+	client := dialAuthNode(ctx)
+	defer client.Close()
+
+	spanner, contractAddr := validatorSpanner(ctx, client)
+	return printAuthJSON(queryValidator(spanner, contractAddr, addr), true)
+}
+
+func validatorStake(ctx *cli.Context) error {
+	if ctx.Args().Len() > 1 {
+		utils.Fatalf("This command accepts at most one address argument.")
+	}
+	client := dialAuthNode(ctx)
+	defer client.Close()
+
+	var (
+		backend               = ethclient.NewClient(client)
+		spanner, contractAddr = validatorSpanner(ctx, client)
+		opts                  = validatorSigner(ctx, backend)
+		addr                  = validatorTarget(ctx, opts)
+		status                = queryValidator(spanner, contractAddr, addr)
+		threshold             = status.Threshold.ToInt()
+		stake                 = status.Stake.ToInt()
+	)
+	// Stake the amount missing to the threshold unless told otherwise
+	amount := new(big.Int).Sub(threshold, stake)
+	if arg := ctx.String(validatorAmountFlag.Name); arg != "" {
+		var ok bool
+		if amount, ok = math.ParseBig256(arg); !ok {
+			utils.Fatalf("Invalid amount %q", arg)
+		}
+	}
+	if amount.Sign() <= 0 {
+		utils.Fatalf("No amount to stake, the threshold of %s wei is reached, use --%s", threshold, validatorAmountFlag.Name)
+	}
+	if total := new(big.Int).Add(stake, amount); total.Cmp(threshold) < 0 {
+		utils.Fatalf("Stake of %s wei is below the threshold of %s wei", total, threshold)
+	}
+	if max := status.MaxValidators.ToInt(); !status.IsValidator && big.NewInt(int64(status.Validators)).Cmp(max) >= 0 {
+		utils.Fatalf("Maximum number of %s validators reached", max)
+	}
+	opts.Value = amount
+	tx, err := bind.NewBoundContract(contractAddr, contract.Staking(), backend, backend, backend).Transact(opts, "stake", addr)
+	if err != nil {
+		utils.Fatalf("Failed to submit stake: %v", err)
+	}
+	return waitAuthTx(backend, tx)
+}
+
+func validatorUnstake(ctx *cli.Context) error {
+	if ctx.Args().Len() > 1 {
+		utils.Fatalf("This command accepts at most one address argument.")
+	}
+	client := dialAuthNode(ctx)
+	defer client.Close()
+
+	var (
+		backend               = ethclient.NewClient(client)
+		spanner, contractAddr = validatorSpanner(ctx, client)
+		opts                  = validatorSigner(ctx, backend)
+		addr                  = validatorTarget(ctx, opts)
+	)
+	if status := queryValidator(spanner, contractAddr, addr); status.Stake.ToInt().Sign() == 0 {
+		utils.Fatalf("No stake of %s to withdraw", addr.Hex())
+	}
+	tx, err := bind.NewBoundContract(contractAddr, contract.Staking(), backend, backend, backend).Transact(opts, "unstake", addr)
+	if err != nil {
+		utils.Fatalf("Failed to submit unstake: %v", err)
+	}
+	return waitAuthTx(backend, tx)
+}
+
+func validatorSetSender(ctx *cli.Context) error {
+	if ctx.Args().Len() != 1 {
+		utils.Fatalf("This command requires a sender address argument.")
+	}
+	sender := parseAuthAddress(ctx.Args().First())
+
+	client := dialAuthNode(ctx)
+	defer client.Close()
+
+	var (
+		backend         = ethclient.NewClient(client)
+		_, contractAddr = validatorSpanner(ctx, client)
+		opts            = validatorSigner(ctx, backend)
+	)
+	tx, err := bind.NewBoundContract(contractAddr, contract.Staking(), backend, backend, backend).Transact(opts, "setSender", opts.From, sender)
+	if err != nil {
+		utils.Fatalf("Failed to submit sender update: %v", err)
+	}
+	return waitAuthTx(backend, tx)
+}
+
+// queryValidator retrieves the status of the validator as of the latest block.
+func queryValidator(spanner *span.ChainSpanner, contractAddr common.Address, addr common.Address) *validatorStatusResult {
+	var (
+		ctx    = context.Background()
+		latest = rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+		result = &validatorStatusResult{Address: addr, Contract: contractAddr}
+		err    error
+	)
+	if result.IsValidator, err = spanner.IsValidator(ctx, latest, addr); err != nil {
+		utils.Fatalf("Failed to retrieve validator status: %v", err)
+	}
+	stake, err := spanner.GetStake(ctx, latest, addr)
+	if err != nil {
+		utils.Fatalf("Failed to retrieve stake: %v", err)
+	}
+	if result.Sender, err = spanner.GetSender(ctx, latest, addr); err != nil {
+		utils.Fatalf("Failed to retrieve sender: %v", err)
+	}
+	threshold, err := spanner.GetValidatorThreshold(ctx, latest)
+	if err != nil {
+		utils.Fatalf("Failed to retrieve validator threshold: %v", err)
+	}
+	validators, err := spanner.GetValidatorAddresses(ctx, latest)
+	if err != nil {
+		utils.Fatalf("Failed to retrieve validators: %v", err)
+	}
+	max, err := spanner.GetMaxValidators(ctx, latest)
+	if err != nil {
+		utils.Fatalf("Failed to retrieve maximum number of validators: %v", err)
+	}
+	result.Stake = (*hexutil.Big)(stake)
+	result.Threshold = (*hexutil.Big)(threshold)
+	result.Validators = len(validators)
+	result.MaxValidators = (*hexutil.Big)(max)
+	return result
+}
+
+// validatorSpanner returns a spanner reading the validator contract given by the
+// contract flag, or the one of the chain config of the node if none is given.
+func validatorSpanner(ctx *cli.Context, client *rpc.Client) (*span.ChainSpanner, common.Address) {
+	var config *params.ChainConfig
+	if arg := ctx.String(validatorContractFlag.Name); arg == "" {
+		var info struct {
+			Protocols struct {
+				Eth struct {
+					Config *params.ChainConfig `json:"config"`
+				} `json:"eth"`
+			} `json:"protocols"`
+		}
+		if err := client.Call(&info, "admin_nodeInfo"); err != nil {
+			utils.Fatalf("Failed to retrieve chain config, use --%s: %v", validatorContractFlag.Name, err)
+		}
+		config = info.Protocols.Eth.Config
+		if config == nil || config.Clique == nil || config.Clique.ValidatorContract == "" {
+			utils.Fatalf("No validator contract configured, use --%s", validatorContractFlag.Name)
+		}
+	}
+	contractAddr := validatorContract(ctx, config)
+	return span.NewChainSpanner(&rpcCaller{client}, contract.Staking(), config, contractAddr), contractAddr
+}
+
+// validatorContract returns the address of the validator contract given by the
+// contract flag, or the one of the chain config otherwise.
+func validatorContract(ctx *cli.Context, config *params.ChainConfig) common.Address {
+	if arg := ctx.String(validatorContractFlag.Name); arg != "" {
+		return parseAuthAddress(arg)
+	}
+	return common.HexToAddress(config.Clique.ValidatorContract)
+}
+
+// validatorTarget returns the validator given as argument, the signing account
+// if none is given.
+func validatorTarget(ctx *cli.Context, opts *bind.TransactOpts) common.Address {
+	if ctx.Args().Len() == 0 {
+		return opts.From
+	}
+	return parseAuthAddress(ctx.Args().First())
+}
+
+// validatorSigner returns a transactor signing with the account given by the
+// from flag, through the external signer if one is given or with the keystore
+// otherwise.
+func validatorSigner(ctx *cli.Context, backend *ethclient.Client) *bind.TransactOpts {
+	endpoint := ctx.String(utils.ExternalSignerFlag.Name)
+	if endpoint == "" {
+		return authSigner(ctx, backend)
+	}
+	from := ctx.String(authFromFlag.Name)
+	if !common.IsHexAddress(from) {
+		utils.Fatalf("An external signer requires the signer address, use --%s", authFromFlag.Name)
+	}
+	signer, err := external.NewExternalSigner(endpoint)
+	if err != nil {
+		utils.Fatalf("Failed to connect to external signer: %v", err)
+	}
+	chainID, err := backend.ChainID(context.Background())
+	if err != nil {
+		utils.Fatalf("Failed to retrieve chain ID: %v", err)
+	}
+	account := accounts.Account{Address: common.HexToAddress(from)}
+	return &bind.TransactOpts{
+		From: account.Address,
+		Signer: func(addr common.Address, tx *types.Transaction) (*types.Transaction, error) {
+			if addr != account.Address {
+				return nil, bind.ErrNotAuthorized
+			}
+			return signer.SignTx(account, tx, chainID)
+		},
+		Context: context.Background(),
+	}
+}
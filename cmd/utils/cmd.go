@@ -305,8 +305,19 @@ func ImportPreimages(db ethdb.Database, fn string) error {
 			return err
 		}
 	}
-	stream := rlp.NewStream(reader, 0)
+	_, err = ImportPreimageStream(db, reader)
+	return err
+}
 
+// ImportPreimageStream imports the hash preimages read from an RLP stream until
+// its end, returning the number of preimages imported.
+func ImportPreimageStream(db ethdb.Database, reader io.Reader) (int, error) {
+	var (
+		stream = rlp.NewStream(reader, 0)
+		count  int
+		start  = time.Now()
+		logged = time.Now()
+	)
 	// Import the preimages in batches to prevent disk thrashing
 	preimages := make(map[common.Hash][]byte)
 
@@ -318,7 +329,7 @@ func ImportPreimages(db ethdb.Database, fn string) error {
 			if err == io.EOF {
 				break
 			}
-			return err
+			return count, err
 		}
 		// Accumulate the preimages and flush when enough ws gathered
 		preimages[crypto.Keccak256Hash(blob)] = common.CopyBytes(blob)
@@ -326,12 +337,17 @@ func ImportPreimages(db ethdb.Database, fn string) error {
 			rawdb.WritePreimages(db, preimages)
 			preimages = make(map[common.Hash][]byte)
 		}
+		count++
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Importing preimages", "count", count, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
 	}
 	// Flush the last batch preimage data
 	if len(preimages) > 0 {
 		rawdb.WritePreimages(db, preimages)
 	}
-	return nil
+	return count, nil
 }
 
 // ExportPreimages exports all known hash preimages into the specified file,
@@ -352,17 +368,39 @@ func ExportPreimages(db ethdb.Database, fn string) error {
 		writer = gzip.NewWriter(writer)
 		defer writer.(*gzip.Writer).Close()
 	}
+	if _, err := ExportPreimageStream(db, writer); err != nil {
+		return err
+	}
+	log.Info("Exported preimages", "file", fn)
+	return nil
+}
+
+// ExportPreimageStream writes all known hash preimages into an RLP stream,
+// returning the number of preimages exported.
+func ExportPreimageStream(db ethdb.Database, writer io.Writer) (int, error) {
+	var (
+		count  int
+		start  = time.Now()
+		logged = time.Now()
+	)
 	// Iterate over the preimages and export them
-	it := db.NewIterator([]byte("secure-key-"), nil)
+	it := db.NewIterator(rawdb.PreimagePrefix, nil)
 	defer it.Release()
 
 	for it.Next() {
+		if len(it.Key()) != len(rawdb.PreimagePrefix)+common.HashLength {
+			continue
+		}
 		if err := rlp.Encode(writer, it.Value()); err != nil {
-			return err
+			return count, err
+		}
+		count++
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Exporting preimages", "count", count, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
 		}
 	}
-	log.Info("Exported preimages", "file", fn)
-	return nil
+	return count, it.Error()
 }
 
 // exportHeader is used in the export/import flow. When we do an export,
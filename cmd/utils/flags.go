@@ -19,6 +19,8 @@ package utils
 
 import (
 	"crypto/ecdsa"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"math"
 	"math/big"
@@ -35,7 +37,10 @@ import (
 	"github.com/qydata/go-ctereum/common"
 	"github.com/qydata/go-ctereum/common/fdlimit"
 	"github.com/qydata/go-ctereum/consensus"
+	"github.com/qydata/go-ctereum/consensus/beacon"
+	"github.com/qydata/go-ctereum/consensus/clique"
 	"github.com/qydata/go-ctereum/consensus/ethash"
+	"github.com/qydata/go-ctereum/contracts/authcontroller"
 	"github.com/qydata/go-ctereum/core"
 	"github.com/qydata/go-ctereum/core/rawdb"
 	"github.com/qydata/go-ctereum/core/vm"
@@ -171,6 +176,29 @@ var (
 		Value:    11500000,
 		Category: flags.DevCategory,
 	}
+	DeveloperCtFlag = &cli.BoolFlag{
+		Name:     "dev.ct",
+		Usage:    "Run the developer network with the ct forks and contracts active from genesis",
+		Category: flags.DevCategory,
+	}
+	DeveloperCtAuthCodeFlag = &cli.PathFlag{
+		Name:      "dev.ct.authcode",
+		Usage:     "File holding the hex encoded creation code of the AuthController predeployed in ct developer mode",
+		TakesFile: true,
+		Category:  flags.DevCategory,
+	}
+	DeveloperCtValidatorCodeFlag = &cli.PathFlag{
+		Name:      "dev.ct.validatorcode",
+		Usage:     "File holding the hex encoded creation code of the staking contract predeployed in ct developer mode",
+		TakesFile: true,
+		Category:  flags.DevCategory,
+	}
+	DeveloperCtStakeFlag = &flags.BigFlag{
+		Name:     "dev.ct.stake",
+		Usage:    "Stake of the developer account in the staking contract in ct developer mode, in wei",
+		Value:    new(big.Int).Mul(big.NewInt(2_000_000), big.NewInt(params.Ether)),
+		Category: flags.DevCategory,
+	}
 
 	IdentityFlag = &cli.StringFlag{
 		Name:     "identity",
@@ -274,6 +302,33 @@ var (
 		Usage:    "Manually specify TerminalTotalDifficultyPassed, overriding the bundled setting",
 		Category: flags.EthCategory,
 	}
+	AuthContractFlag = &cli.StringFlag{
+		Name:     "auth.contract",
+		Usage:    "Address of the AuthController queried for sender authentication before the fix fork, overriding the bundled setting",
+		Category: flags.EthCategory,
+	}
+	AuthRegistryFlag = &cli.PathFlag{
+		Name:      "auth.registry",
+		Usage:     "JSON file mapping labels to the addresses of additional per-dApp AuthControllers",
+		TakesFile: true,
+		Category:  flags.EthCategory,
+	}
+	ValidatorContractFlag = &cli.StringFlag{
+		Name:     "validator.contract",
+		Usage:    "Address of the staking contract holding the clique validators, overriding the bundled setting",
+		Category: flags.EthCategory,
+	}
+	CliqueActivityWindowFlag = &cli.Uint64Flag{
+		Name:     "clique.activitywindow",
+		Usage:    "Number of recent blocks the signer activity of clique_status is computed over",
+		Value:    ethconfig.Defaults.CliqueActivityWindow,
+		Category: flags.EthCategory,
+	}
+	StrictForksFlag = &cli.BoolFlag{
+		Name:     "strict",
+		Usage:    "Refuse to start if the ct forks of the chain config diverge from the canonical ones of the network",
+		Category: flags.EthCategory,
+	}
 	// Light server and client settings
 	LightServeFlag = &cli.IntFlag{
 		Name:     "light.serve",
@@ -311,6 +366,11 @@ var (
 		Value:    ethconfig.Defaults.UltraLightFraction,
 		Category: flags.LightCategory,
 	}
+	UltraLightQuorumFlag = &cli.IntFlag{
+		Name:     "ulc.quorum",
+		Usage:    "Number of trusted ultra-light servers required to announce a new head, overriding --ulc.fraction (0 = disabled)",
+		Category: flags.LightCategory,
+	}
 	UltraLightOnlyAnnounceFlag = &cli.BoolFlag{
 		Name:     "ulc.onlyannounce",
 		Usage:    "Ultra light server sends announcements only",
@@ -326,6 +386,16 @@ var (
 		Usage:    "Enables serving light clients before syncing",
 		Category: flags.LightCategory,
 	}
+	LightValidatorBalanceFlag = &cli.Uint64Flag{
+		Name:     "light.validatorbalance",
+		Usage:    "Positive balance granted to clients proving control of a staked validator (0 = disabled)",
+		Category: flags.LightCategory,
+	}
+	LightCliqueCheckpointFlag = &cli.StringFlag{
+		Name:     "light.cliquecheckpoint",
+		Usage:    "Clique epoch block to start light syncing from, as <number>:<hash>:<signer set hash>",
+		Category: flags.LightCategory,
+	}
 
 	// Ethash settings
 	EthashCacheDirFlag = &flags.DirectoryFlag{
@@ -439,6 +509,16 @@ var (
 		Value:    ethconfig.Defaults.TxPool.Lifetime,
 		Category: flags.TxPoolCategory,
 	}
+	TxPoolAuthSendersFlag = &cli.BoolFlag{
+		Name:     "txpool.authsenders",
+		Usage:    "Rejects transactions from senders not authenticated in the auth contract",
+		Category: flags.TxPoolCategory,
+	}
+	TxPoolAuthAllowlistFlag = &cli.StringFlag{
+		Name:     "txpool.authallowlist",
+		Usage:    "Comma separated accounts exempt from sender authentication",
+		Category: flags.TxPoolCategory,
+	}
 
 	// Performance tuning settings
 	CacheFlag = &cli.IntFlag{
@@ -483,6 +563,11 @@ var (
 		Value:    10,
 		Category: flags.PerfCategory,
 	}
+	DBEngineFlag = &cli.StringFlag{
+		Name:     "db.engine",
+		Usage:    "Backing database implementation to use ('leveldb' or 'pebble'), defaults to the one of an existing database",
+		Category: flags.PerfCategory,
+	}
 	CacheNoPrefetchFlag = &cli.BoolFlag{
 		Name:     "cache.noprefetch",
 		Usage:    "Disable heuristic state prefetch during block import (less CPU and disk IO, more time waiting for data)",
@@ -493,6 +578,26 @@ var (
 		Usage:    "Enable recording the SHA3/keccak preimages of trie keys",
 		Category: flags.PerfCategory,
 	}
+	ParallelEVMFlag = &cli.BoolFlag{
+		Name:     "parallel-evm",
+		Usage:    "Execute the transactions of imported blocks speculatively in parallel, re-executing conflicting ones serially",
+		Category: flags.PerfCategory,
+	}
+	StateDiffsFlag = &cli.BoolFlag{
+		Name:     "state.diffs",
+		Usage:    "Record the account and storage changes made by every block, served by debug_getStateDiff",
+		Category: flags.EthCategory,
+	}
+	StateWitnessesFlag = &cli.BoolFlag{
+		Name:     "state.witnesses",
+		Usage:    "Record and store the execution witness (accessed trie nodes and codes) of every block, served by debug_executionWitness",
+		Category: flags.EthCategory,
+	}
+	LogIndexFlag = &cli.BoolFlag{
+		Name:     "logindex",
+		Usage:    "Index the blocks containing Authentication and Staked events in the background, accelerating eth_getLogs over wide ranges",
+		Category: flags.EthCategory,
+	}
 	CacheLogSizeFlag = &cli.IntFlag{
 		Name:     "cache.blocklogs",
 		Usage:    "Size (in number of blocks) of the log cache for filtering",
@@ -613,6 +718,36 @@ var (
 		Value:    ethconfig.Defaults.RPCTxFeeCap,
 		Category: flags.APICategory,
 	}
+	RPCLogsRangeLimitFlag = &cli.Uint64Flag{
+		Name:     "rpc.logs-range-limit",
+		Usage:    "Maximum number of blocks spanned by an eth_getLogs query (0 = no limit)",
+		Value:    ethconfig.Defaults.RPCLogsRangeLimit,
+		Category: flags.APICategory,
+	}
+	RPCLogsResultLimitFlag = &cli.IntFlag{
+		Name:     "rpc.logs-result-limit",
+		Usage:    "Maximum number of logs returned by an eth_getLogs query (0 = no limit)",
+		Value:    ethconfig.Defaults.RPCLogsResultLimit,
+		Category: flags.APICategory,
+	}
+	BatchRequestLimitFlag = &cli.IntFlag{
+		Name:     "rpc.batch-request-limit",
+		Usage:    "Maximum number of requests in a batch (0 = no limit)",
+		Value:    node.DefaultConfig.BatchRequestLimit,
+		Category: flags.APICategory,
+	}
+	BatchResponseMaxSizeFlag = &cli.IntFlag{
+		Name:     "rpc.batch-response-max-size",
+		Usage:    "Maximum number of bytes returned from a batched call (0 = no limit)",
+		Value:    node.DefaultConfig.BatchResponseMaxSize,
+		Category: flags.APICategory,
+	}
+	BatchConcurrencyFlag = &cli.IntFlag{
+		Name:     "rpc.batch-concurrency",
+		Usage:    "Number of requests of a batch executed concurrently",
+		Value:    node.DefaultConfig.BatchConcurrency,
+		Category: flags.APICategory,
+	}
 	// Authenticated RPC HTTP settings
 	AuthListenFlag = &cli.StringFlag{
 		Name:     "authrpc.addr",
@@ -637,6 +772,41 @@ var (
 		Usage:    "Path to a JWT secret to use for authenticated RPC endpoints",
 		Category: flags.APICategory,
 	}
+	// Operator RPC settings
+	OperatorEnabledFlag = &cli.BoolFlag{
+		Name:     "operatorrpc",
+		Usage:    "Enable the JWT authenticated operator RPC endpoint, limiting the HTTP-RPC and WS-RPC servers to the eth, net and web3 APIs",
+		Category: flags.APICategory,
+	}
+	OperatorListenAddrFlag = &cli.StringFlag{
+		Name:     "operatorrpc.addr",
+		Usage:    "Listening address for the operator APIs",
+		Value:    node.DefaultOperatorHost,
+		Category: flags.APICategory,
+	}
+	OperatorPortFlag = &cli.IntFlag{
+		Name:     "operatorrpc.port",
+		Usage:    "Listening port for the operator APIs",
+		Value:    node.DefaultConfig.OperatorPort,
+		Category: flags.APICategory,
+	}
+	OperatorVirtualHostsFlag = &cli.StringFlag{
+		Name:     "operatorrpc.vhosts",
+		Usage:    "Comma separated list of virtual hostnames from which to accept operator requests (server enforced). Accepts '*' wildcard.",
+		Value:    strings.Join(node.DefaultConfig.OperatorVirtualHosts, ","),
+		Category: flags.APICategory,
+	}
+	OperatorApiFlag = &cli.StringFlag{
+		Name:     "operatorrpc.api",
+		Usage:    "API's offered over the operator RPC interface",
+		Value:    strings.Join(node.DefaultConfig.OperatorModules, ","),
+		Category: flags.APICategory,
+	}
+	OperatorJWTSecretFlag = &cli.StringFlag{
+		Name:     "operatorrpc.jwtsecret",
+		Usage:    "Path to a JWT secret to use for the operator RPC endpoint",
+		Category: flags.APICategory,
+	}
 
 	// Logging and debug settings
 	EthStatsURLFlag = &cli.StringFlag{
@@ -654,6 +824,11 @@ var (
 		Usage:    "Disables db compaction after import",
 		Category: flags.LoggingCategory,
 	}
+	ImportNoSealVerifyFlag = &cli.Uint64Flag{
+		Name:     "import.nosealverify",
+		Usage:    "Trust the clique seals of the imported blocks below this height, still verifying their state roots",
+		Category: flags.LoggingCategory,
+	}
 
 	IgnoreLegacyReceiptsFlag = &cli.BoolFlag{
 		Name:     "ignore-legacy-receipts",
@@ -882,6 +1057,17 @@ var (
 		Value:    ethconfig.Defaults.GPO.IgnorePrice.Int64(),
 		Category: flags.GasPriceCategory,
 	}
+	GpoMaxEmptyBlocksFlag = &cli.IntFlag{
+		Name:     "gpo.maxemptyblocks",
+		Usage:    "Number of empty blocks looked past on clique chains to find blocks with transactions to check",
+		Value:    ethconfig.Defaults.GPO.MaxEmptyBlocks,
+		Category: flags.GasPriceCategory,
+	}
+	GpoMinTipFlag = &cli.Int64Flag{
+		Name:     "gpo.mintip",
+		Usage:    "Minimum transaction priority fee to be recommended by gpo on clique chains (defaults to the miner gas price)",
+		Category: flags.GasPriceCategory,
+	}
 
 	// Metrics flags
 	MetricsEnabledFlag = &cli.BoolFlag{
@@ -998,6 +1184,7 @@ var (
 		DataDirFlag,
 		AncientFlag,
 		RemoteDBFlag,
+		DBEngineFlag,
 	}
 )
 
@@ -1240,6 +1427,29 @@ func setWS(ctx *cli.Context, cfg *node.Config) {
 	}
 }
 
+// setOperator configures the operator RPC endpoint from the set command line
+// flags, leaving it disabled unless explicitly enabled.
+func setOperator(ctx *cli.Context, cfg *node.Config) {
+	if ctx.Bool(OperatorEnabledFlag.Name) && cfg.OperatorHost == "" {
+		cfg.OperatorHost = ctx.String(OperatorListenAddrFlag.Name)
+	}
+	if ctx.IsSet(OperatorPortFlag.Name) {
+		cfg.OperatorPort = ctx.Int(OperatorPortFlag.Name)
+	}
+
+	if ctx.IsSet(OperatorVirtualHostsFlag.Name) {
+		cfg.OperatorVirtualHosts = SplitAndTrim(ctx.String(OperatorVirtualHostsFlag.Name))
+	}
+
+	if ctx.IsSet(OperatorApiFlag.Name) {
+		cfg.OperatorModules = SplitAndTrim(ctx.String(OperatorApiFlag.Name))
+	}
+
+	if ctx.IsSet(OperatorJWTSecretFlag.Name) {
+		cfg.OperatorJWTSecret = ctx.String(OperatorJWTSecretFlag.Name)
+	}
+}
+
 // setIPC creates an IPC path configuration from the set command line flags,
 // returning an empty string if IPC was explicitly disabled, or the set path.
 func setIPC(ctx *cli.Context, cfg *node.Config) {
@@ -1276,6 +1486,13 @@ func setLes(ctx *cli.Context, cfg *ethconfig.Config) {
 		log.Error("Ultra light fraction is invalid", "had", cfg.UltraLightFraction, "updated", ethconfig.Defaults.UltraLightFraction)
 		cfg.UltraLightFraction = ethconfig.Defaults.UltraLightFraction
 	}
+	if ctx.IsSet(UltraLightQuorumFlag.Name) {
+		cfg.UltraLightQuorum = ctx.Int(UltraLightQuorumFlag.Name)
+	}
+	if cfg.UltraLightQuorum < 0 {
+		log.Error("Ultra light quorum is invalid", "had", cfg.UltraLightQuorum, "updated", 0)
+		cfg.UltraLightQuorum = 0
+	}
 	if ctx.IsSet(UltraLightOnlyAnnounceFlag.Name) {
 		cfg.UltraLightOnlyAnnounce = ctx.Bool(UltraLightOnlyAnnounceFlag.Name)
 	}
@@ -1285,6 +1502,28 @@ func setLes(ctx *cli.Context, cfg *ethconfig.Config) {
 	if ctx.IsSet(LightNoSyncServeFlag.Name) {
 		cfg.LightNoSyncServe = ctx.Bool(LightNoSyncServeFlag.Name)
 	}
+	if ctx.IsSet(LightValidatorBalanceFlag.Name) {
+		cfg.LightValidatorBalance = ctx.Uint64(LightValidatorBalanceFlag.Name)
+	}
+	if ctx.IsSet(LightCliqueCheckpointFlag.Name) {
+		value := ctx.String(LightCliqueCheckpointFlag.Name)
+		parts := strings.Split(value, ":")
+		if len(parts) != 3 {
+			Fatalf("Invalid clique checkpoint %q, expected <number>:<hash>:<signer set hash>", value)
+		}
+		n, err := strconv.ParseUint(parts[0], 10, 64)
+		if err != nil {
+			Fatalf("Invalid clique checkpoint number %q: %v", parts[0], err)
+		}
+		var hash, signersHash common.Hash
+		if err := hash.UnmarshalText([]byte(parts[1])); err != nil {
+			Fatalf("Invalid clique checkpoint hash %q: %v", parts[1], err)
+		}
+		if err := signersHash.UnmarshalText([]byte(parts[2])); err != nil {
+			Fatalf("Invalid clique checkpoint signer set hash %q: %v", parts[2], err)
+		}
+		cfg.CliqueCheckpoint = &params.CliqueCheckpoint{Number: n, Hash: hash, SignersHash: signersHash}
+	}
 }
 
 // MakeDatabaseHandles raises out the number of allowed file handles per process
@@ -1459,6 +1698,7 @@ func SetNodeConfig(ctx *cli.Context, cfg *node.Config) {
 	setHTTP(ctx, cfg)
 	setGraphQL(ctx, cfg)
 	setWS(ctx, cfg)
+	setOperator(ctx, cfg)
 	setNodeUserIdent(ctx, cfg)
 	SetDataDir(ctx, cfg)
 	setSmartCard(ctx, cfg)
@@ -1467,6 +1707,18 @@ func SetNodeConfig(ctx *cli.Context, cfg *node.Config) {
 		cfg.JWTSecret = ctx.String(JWTSecretFlag.Name)
 	}
 
+	if ctx.IsSet(BatchRequestLimitFlag.Name) {
+		cfg.BatchRequestLimit = ctx.Int(BatchRequestLimitFlag.Name)
+	}
+
+	if ctx.IsSet(BatchResponseMaxSizeFlag.Name) {
+		cfg.BatchResponseMaxSize = ctx.Int(BatchResponseMaxSizeFlag.Name)
+	}
+
+	if ctx.IsSet(BatchConcurrencyFlag.Name) {
+		cfg.BatchConcurrency = ctx.Int(BatchConcurrencyFlag.Name)
+	}
+
 	if ctx.IsSet(ExternalSignerFlag.Name) {
 		cfg.ExternalSigner = ctx.String(ExternalSignerFlag.Name)
 	}
@@ -1474,6 +1726,13 @@ func SetNodeConfig(ctx *cli.Context, cfg *node.Config) {
 	if ctx.IsSet(KeyStoreDirFlag.Name) {
 		cfg.KeyStoreDir = ctx.String(KeyStoreDirFlag.Name)
 	}
+	if ctx.IsSet(DBEngineFlag.Name) {
+		dbEngine := ctx.String(DBEngineFlag.Name)
+		if dbEngine != "leveldb" && dbEngine != "pebble" {
+			Fatalf("Invalid choice for db.engine '%s', allowed 'leveldb' or 'pebble'", dbEngine)
+		}
+		cfg.DBEngine = dbEngine
+	}
 	if ctx.IsSet(DeveloperFlag.Name) {
 		cfg.UseLightweightKDF = true
 	}
@@ -1558,6 +1817,12 @@ func setGPO(ctx *cli.Context, cfg *gasprice.Config, light bool) {
 	if ctx.IsSet(GpoIgnoreGasPriceFlag.Name) {
 		cfg.IgnorePrice = big.NewInt(ctx.Int64(GpoIgnoreGasPriceFlag.Name))
 	}
+	if ctx.IsSet(GpoMaxEmptyBlocksFlag.Name) {
+		cfg.MaxEmptyBlocks = ctx.Int(GpoMaxEmptyBlocksFlag.Name)
+	}
+	if ctx.IsSet(GpoMinTipFlag.Name) {
+		cfg.MinTip = big.NewInt(ctx.Int64(GpoMinTipFlag.Name))
+	}
 }
 
 func setTxPool(ctx *cli.Context, cfg *core.TxPoolConfig) {
@@ -1601,6 +1866,19 @@ func setTxPool(ctx *cli.Context, cfg *core.TxPoolConfig) {
 	if ctx.IsSet(TxPoolLifetimeFlag.Name) {
 		cfg.Lifetime = ctx.Duration(TxPoolLifetimeFlag.Name)
 	}
+	if ctx.IsSet(TxPoolAuthSendersFlag.Name) {
+		cfg.AuthSenders = ctx.Bool(TxPoolAuthSendersFlag.Name)
+	}
+	if ctx.IsSet(TxPoolAuthAllowlistFlag.Name) {
+		allowlist := strings.Split(ctx.String(TxPoolAuthAllowlistFlag.Name), ",")
+		for _, account := range allowlist {
+			if trimmed := strings.TrimSpace(account); !common.IsHexAddress(trimmed) {
+				Fatalf("Invalid account in --txpool.authallowlist: %s", trimmed)
+			} else {
+				cfg.AuthAllowlist = append(cfg.AuthAllowlist, common.HexToAddress(trimmed))
+			}
+		}
+	}
 }
 
 func setEthash(ctx *cli.Context, cfg *ethconfig.Config) {
@@ -1683,6 +1961,86 @@ func setRequiredBlocks(ctx *cli.Context, cfg *ethconfig.Config) {
 	}
 }
 
+// makeDeveloperCtGenesis creates the genesis of a ct developer network, with the
+// contracts predeployed from the given creation code and the accounts of the
+// keystore funded and whitelisted next to the developer one.
+func makeDeveloperCtGenesis(ctx *cli.Context, ks *keystore.KeyStore, developer common.Address) *core.Genesis {
+	if !ctx.IsSet(DeveloperCtAuthCodeFlag.Name) || !ctx.IsSet(DeveloperCtValidatorCodeFlag.Name) {
+		Fatalf("The ct developer mode requires the creation code of the AuthController (--%s) and of the staking contract (--%s)",
+			DeveloperCtAuthCodeFlag.Name, DeveloperCtValidatorCodeFlag.Name)
+	}
+	authCode, err := readCreationCode(ctx.Path(DeveloperCtAuthCodeFlag.Name))
+	if err != nil {
+		Fatalf("Invalid AuthController code: %v", err)
+	}
+	validatorCode, err := readCreationCode(ctx.Path(DeveloperCtValidatorCodeFlag.Name))
+	if err != nil {
+		Fatalf("Invalid staking contract code: %v", err)
+	}
+	var accounts []common.Address
+	for _, account := range ks.Accounts() {
+		accounts = append(accounts, account.Address)
+	}
+	genesis, err := core.DeveloperCtGenesisBlock(uint64(ctx.Int(DeveloperPeriodFlag.Name)), ctx.Uint64(DeveloperGasLimitFlag.Name),
+		developer, accounts, authCode, validatorCode, flags.GlobalBig(ctx, DeveloperCtStakeFlag.Name))
+	if err != nil {
+		Fatalf("Failed to create ct developer genesis: %v", err)
+	}
+	log.Info("Predeployed ct developer contracts", "authcontroller", core.DeveloperAuthContract, "validators", core.DeveloperValidatorContract, "stake", genesis.Config.Clique.StakeAmount)
+	return genesis
+}
+
+// readCreationCode reads the hex encoded creation code of a contract from a file.
+func readCreationCode(path string) ([]byte, error) {
+	blob, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	// Accept code without the 0x prefix, as output by solc --bin
+	code, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(string(blob)), "0x"))
+	if err != nil {
+		return nil, err
+	}
+	if len(code) == 0 {
+		return nil, errors.New("empty creation code")
+	}
+	return code, nil
+}
+
+// setCtOverrides applies the ct specific flags, overriding the bundled auth and
+// staking contracts of the chain config, into the config.
+func setCtOverrides(ctx *cli.Context, cfg *ethconfig.Config) {
+	if ctx.IsSet(AuthContractFlag.Name) {
+		addr := ctx.String(AuthContractFlag.Name)
+		if !common.IsHexAddress(addr) {
+			Fatalf("Invalid auth contract address %q", addr)
+		}
+		override := common.HexToAddress(addr)
+		cfg.OverrideAuthContract = &override
+	}
+	if ctx.IsSet(AuthRegistryFlag.Name) {
+		registry, err := authcontroller.LoadRegistry(ctx.Path(AuthRegistryFlag.Name))
+		if err != nil {
+			Fatalf("Failed to load auth registry: %v", err)
+		}
+		cfg.AuthRegistry = registry
+	}
+	if ctx.IsSet(ValidatorContractFlag.Name) {
+		addr := ctx.String(ValidatorContractFlag.Name)
+		if !common.IsHexAddress(addr) {
+			Fatalf("Invalid validator contract address %q", addr)
+		}
+		override := common.HexToAddress(addr)
+		cfg.OverrideValidatorContract = &override
+	}
+	if ctx.IsSet(CliqueActivityWindowFlag.Name) {
+		cfg.CliqueActivityWindow = ctx.Uint64(CliqueActivityWindowFlag.Name)
+	}
+	if ctx.IsSet(StrictForksFlag.Name) {
+		cfg.StrictForks = ctx.Bool(StrictForksFlag.Name)
+	}
+}
+
 // CheckExclusive verifies that only a single instance of the provided flags was
 // set by the user. Each flag might optionally be followed by a string type to
 // specialize it further.
@@ -1748,6 +2106,7 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 	setMiner(ctx, &cfg.Miner)
 	setRequiredBlocks(ctx, cfg)
 	setLes(ctx, cfg)
+	setCtOverrides(ctx, cfg)
 
 	// Cap the cache allowance and tune the garbage collector
 	mem, err := gopsutil.VirtualMemory()
@@ -1792,6 +2151,18 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 	if ctx.IsSet(CacheNoPrefetchFlag.Name) {
 		cfg.NoPrefetch = ctx.Bool(CacheNoPrefetchFlag.Name)
 	}
+	if ctx.IsSet(ParallelEVMFlag.Name) {
+		cfg.ParallelEVM = ctx.Bool(ParallelEVMFlag.Name)
+	}
+	if ctx.IsSet(StateDiffsFlag.Name) {
+		cfg.StateDiffs = ctx.Bool(StateDiffsFlag.Name)
+	}
+	if ctx.IsSet(StateWitnessesFlag.Name) {
+		cfg.StateWitnesses = ctx.Bool(StateWitnessesFlag.Name)
+	}
+	if ctx.IsSet(LogIndexFlag.Name) {
+		cfg.LogIndex = ctx.Bool(LogIndexFlag.Name)
+	}
 	// Read the value from the flag no matter if it's set or not.
 	cfg.Preimages = ctx.Bool(CachePreimagesFlag.Name)
 	if cfg.NoPruning && !cfg.Preimages {
@@ -1850,6 +2221,12 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 	if ctx.IsSet(RPCGlobalTxFeeCapFlag.Name) {
 		cfg.RPCTxFeeCap = ctx.Float64(RPCGlobalTxFeeCapFlag.Name)
 	}
+	if ctx.IsSet(RPCLogsRangeLimitFlag.Name) {
+		cfg.RPCLogsRangeLimit = ctx.Uint64(RPCLogsRangeLimitFlag.Name)
+	}
+	if ctx.IsSet(RPCLogsResultLimitFlag.Name) {
+		cfg.RPCLogsResultLimit = ctx.Int(RPCLogsResultLimitFlag.Name)
+	}
 	if ctx.IsSet(NoDiscoverFlag.Name) {
 		cfg.EthDiscoveryURLs, cfg.SnapDiscoveryURLs = []string{}, []string{}
 	} else if ctx.IsSet(DNSDiscoveryFlag.Name) {
@@ -1942,7 +2319,11 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 		log.Info("Using developer account", "address", developer.Address)
 
 		// Create a new developer genesis block or reuse existing one
-		cfg.Genesis = core.DeveloperGenesisBlock(uint64(ctx.Int(DeveloperPeriodFlag.Name)), ctx.Uint64(DeveloperGasLimitFlag.Name), developer.Address)
+		if ctx.Bool(DeveloperCtFlag.Name) {
+			cfg.Genesis = makeDeveloperCtGenesis(ctx, ks, developer.Address)
+		} else {
+			cfg.Genesis = core.DeveloperGenesisBlock(uint64(ctx.Int(DeveloperPeriodFlag.Name)), ctx.Uint64(DeveloperGasLimitFlag.Name), developer.Address)
+		}
 		if ctx.IsSet(DataDirFlag.Name) {
 			// If datadir doesn't exist we need to open db in write-mode
 			// so leveldb can create files.
@@ -2036,6 +2417,8 @@ func RegisterFilterAPI(stack *node.Node, backend ethapi.Backend, ethcfg *ethconf
 	isLightClient := ethcfg.SyncMode == downloader.LightSync
 	filterSystem := filters.NewFilterSystem(backend, filters.Config{
 		LogCacheSize: ethcfg.FilterLogCacheSize,
+		RangeLimit:   ethcfg.RPCLogsRangeLimit,
+		ResultLimit:  ethcfg.RPCLogsResultLimit,
 	})
 	stack.RegisterAPIs([]rpc.API{{
 		Namespace: "eth",
@@ -2180,6 +2563,18 @@ func MakeChain(ctx *cli.Context, stack *node.Node) (chain *core.BlockChain, chai
 		ethashConf.PowMode = ethash.ModeFake
 	}
 	engine = ethconfig.CreateConsensusEngine(stack, config, &ethashConf, nil, false, chainDb, nil)
+	if ctx.IsSet(ImportNoSealVerifyFlag.Name) {
+		height, inner := ctx.Uint64(ImportNoSealVerifyFlag.Name), engine
+		if b, ok := engine.(*beacon.Beacon); ok {
+			inner = b.InnerEngine()
+		}
+		c, ok := inner.(*clique.Clique)
+		if !ok {
+			Fatalf("--%s requires a clique chain", ImportNoSealVerifyFlag.Name)
+		}
+		c.SetTrustedHeight(height)
+		log.Warn("Trusting the seals of the imported blocks", "below", height)
+	}
 	if gcmode := ctx.String(GCModeFlag.Name); gcmode != "full" && gcmode != "archive" {
 		Fatalf("--%s must be either 'full' or 'archive'", GCModeFlag.Name)
 	}
@@ -2191,6 +2586,9 @@ func MakeChain(ctx *cli.Context, stack *node.Node) (chain *core.BlockChain, chai
 		TrieTimeLimit:       ethconfig.Defaults.TrieTimeout,
 		SnapshotLimit:       ethconfig.Defaults.SnapshotCache,
 		Preimages:           ctx.Bool(CachePreimagesFlag.Name),
+		ParallelEVM:         ctx.Bool(ParallelEVMFlag.Name),
+		StateDiffs:          ctx.Bool(StateDiffsFlag.Name),
+		Witnesses:           ctx.Bool(StateWitnessesFlag.Name),
 	}
 	if cache.TrieDirtyDisabled && !cache.Preimages {
 		cache.Preimages = true
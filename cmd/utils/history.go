@@ -0,0 +1,340 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/qydata/go-ctereum/common"
+	"github.com/qydata/go-ctereum/core"
+	"github.com/qydata/go-ctereum/core/types"
+	"github.com/qydata/go-ctereum/ethdb"
+	"github.com/qydata/go-ctereum/internal/era"
+	"github.com/qydata/go-ctereum/log"
+)
+
+// historyChecksumsFile is the file listing the sha256 checksums of the history
+// archives in an export directory, in block order.
+const historyChecksumsFile = "checksums.txt"
+
+// ExportHistory exports the given range of blocks into Era1 archives of at most
+// era.MaxEra1Size blocks each, aligned on epoch boundaries, writing them into
+// the given directory along with a list of their sha256 checksums.
+func ExportHistory(bc *core.BlockChain, dir string, network string, first, last uint64) error {
+	log.Info("Exporting blockchain history", "dir", dir, "first", first, "last", last)
+	if first > last {
+		return fmt.Errorf("export failed: first (%d) is greater than last (%d)", first, last)
+	}
+	if head := bc.CurrentFastBlock().NumberU64(); last > head {
+		return fmt.Errorf("export failed: last (%d) is greater than head block %d", last, head)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	var (
+		checksums []string
+		start     = time.Now()
+		reported  = time.Now()
+	)
+	for from := first; from <= last; {
+		epoch := from / era.MaxEra1Size
+		to := (epoch+1)*era.MaxEra1Size - 1
+		if to > last {
+			to = last
+		}
+		name, sum, err := exportArchive(bc, dir, network, int(epoch), from, to)
+		if err != nil {
+			return err
+		}
+		checksums = append(checksums, fmt.Sprintf("%x %s", sum, name))
+
+		if time.Since(reported) >= 8*time.Second {
+			log.Info("Exporting blockchain history", "exported", to-first+1, "elapsed", common.PrettyDuration(time.Since(start)))
+			reported = time.Now()
+		}
+		from = to + 1
+	}
+	list := strings.Join(checksums, "\n") + "\n"
+	if err := os.WriteFile(filepath.Join(dir, historyChecksumsFile), []byte(list), 0644); err != nil {
+		return err
+	}
+	log.Info("Exported blockchain history", "dir", dir, "archives", len(checksums), "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+// exportArchive writes the given range of blocks into a single archive,
+// returning its file name and sha256 checksum.
+func exportArchive(bc *core.BlockChain, dir string, network string, epoch int, from, to uint64) (string, []byte, error) {
+	f, err := os.CreateTemp(dir, "history-*.tmp")
+	if err != nil {
+		return "", nil, err
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	var (
+		hasher  = sha256.New()
+		buff    = bufio.NewWriter(io.MultiWriter(f, hasher))
+		builder = era.NewBuilder(buff)
+	)
+	for number := from; number <= to; number++ {
+		block := bc.GetBlockByNumber(number)
+		if block == nil {
+			return "", nil, fmt.Errorf("export failed on #%d: not found", number)
+		}
+		receipts := bc.GetReceiptsByHash(block.Hash())
+		if receipts == nil && len(block.Transactions()) > 0 {
+			return "", nil, fmt.Errorf("export failed on #%d: receipts not found", number)
+		}
+		td := bc.GetTd(block.Hash(), number)
+		if td == nil {
+			return "", nil, fmt.Errorf("export failed on #%d: total difficulty not found", number)
+		}
+		if err := builder.Add(block, receipts, td); err != nil {
+			return "", nil, fmt.Errorf("export failed on #%d: %v", number, err)
+		}
+	}
+	checksum, err := builder.Finalize()
+	if err != nil {
+		return "", nil, err
+	}
+	if err := buff.Flush(); err != nil {
+		return "", nil, err
+	}
+	if err := f.Close(); err != nil {
+		return "", nil, err
+	}
+	name := era.Filename(network, epoch, checksum)
+	if err := os.Rename(f.Name(), filepath.Join(dir, name)); err != nil {
+		return "", nil, err
+	}
+	return name, hasher.Sum(nil), nil
+}
+
+// ImportHistory imports the Era1 archives listed in the checksums file of the
+// given source into the chain, extending the history held by the node without
+// executing the blocks. The source is either a local directory or the URL of a
+// http(s) location, such as an object storage bucket, holding an export.
+//
+// The imported blocks are written into the ancient store, so the history needs
+// to be imported on top of the blocks already frozen, typically on a freshly
+// initialized node. State is not reconstructed, but synced afterwards.
+func ImportHistory(chain *core.BlockChain, db ethdb.Database, src string) error {
+	frozen, err := db.Ancients()
+	if err != nil {
+		return err
+	}
+	head := chain.CurrentFastBlock().NumberU64()
+	if frozen != head+1 && !(frozen == 0 && head == 0) {
+		return fmt.Errorf("history import needs the chain to end at the ancient store: head %d, ancients %d", head, frozen)
+	}
+	log.Info("Importing blockchain history", "source", src, "head", head)
+
+	list, err := readHistorySource(src, historyChecksumsFile)
+	if err != nil {
+		return err
+	}
+	var (
+		start    = time.Now()
+		imported uint64
+	)
+	scanner := bufio.NewScanner(bytes.NewReader(list))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return fmt.Errorf("invalid checksum entry %q", line)
+		}
+		sum, err := hex.DecodeString(fields[0])
+		if err != nil {
+			return fmt.Errorf("invalid checksum entry %q: %v", line, err)
+		}
+		n, err := importArchive(chain, src, fields[1], sum)
+		if err != nil {
+			return fmt.Errorf("failed to import %s: %v", fields[1], err)
+		}
+		imported += n
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	log.Info("Imported blockchain history", "blocks", imported, "head", chain.CurrentFastBlock().NumberU64(), "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+// importArchive verifies the given archive against its sha256 checksum and
+// inserts the blocks above the local history into the chain, returning the
+// number of blocks imported.
+func importArchive(chain *core.BlockChain, src string, name string, sum []byte) (uint64, error) {
+	path, cleanup, err := fetchHistoryArchive(src, name, sum)
+	if err != nil {
+		return 0, err
+	}
+	defer cleanup()
+
+	archive, err := era.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer archive.Close()
+
+	if err := archive.Verify(); err != nil {
+		return 0, err
+	}
+	var (
+		head = chain.CurrentFastBlock().NumberU64()
+		from = archive.Start()
+		last = archive.Start() + archive.Count() - 1
+	)
+	if last <= head {
+		log.Info("Skipping history archive as all blocks present", "name", name, "first", from, "last", last)
+		return 0, nil
+	}
+	if from > head+1 {
+		return 0, fmt.Errorf("gap in history: archive starts at %d, local head is %d", from, head)
+	}
+	from = head + 1
+
+	var imported uint64
+	for batchStart := from; batchStart <= last; batchStart += importBatchSize {
+		batchEnd := batchStart + importBatchSize - 1
+		if batchEnd > last {
+			batchEnd = last
+		}
+		var (
+			headers  = make([]*types.Header, 0, batchEnd-batchStart+1)
+			blocks   = make(types.Blocks, 0, batchEnd-batchStart+1)
+			receipts = make([]types.Receipts, 0, batchEnd-batchStart+1)
+			td       *big.Int
+		)
+		for number := batchStart; number <= batchEnd; number++ {
+			block, blockReceipts, blockTd, err := archive.GetByNumber(number)
+			if err != nil {
+				return imported, err
+			}
+			headers = append(headers, block.Header())
+			blocks = append(blocks, block)
+			receipts = append(receipts, blockReceipts)
+			td = blockTd
+		}
+		if n, err := chain.InsertHeaderChain(headers, 1); err != nil {
+			return imported, fmt.Errorf("invalid header #%d: %v", headers[n].Number, err)
+		}
+		lastBlock := blocks[len(blocks)-1]
+		if local := chain.GetTd(lastBlock.Hash(), lastBlock.NumberU64()); local == nil || local.Cmp(td) != 0 {
+			return imported, fmt.Errorf("total difficulty mismatch at #%d: have %v, want %v", lastBlock.NumberU64(), local, td)
+		}
+		if n, err := chain.InsertReceiptChain(blocks, receipts, math.MaxUint64); err != nil {
+			return imported, fmt.Errorf("invalid block #%d: %v", blocks[n].NumberU64(), err)
+		}
+		imported += uint64(len(blocks))
+		log.Info("Imported history batch", "name", name, "first", batchStart, "last", batchEnd)
+	}
+	return imported, nil
+}
+
+// isRemoteHistory reports whether the history source is a http(s) location.
+func isRemoteHistory(src string) bool {
+	return strings.HasPrefix(src, "http://") || strings.HasPrefix(src, "https://")
+}
+
+// readHistorySource reads a small file from the history source.
+func readHistorySource(src string, name string) ([]byte, error) {
+	if !isRemoteHistory(src) {
+		return os.ReadFile(filepath.Join(src, name))
+	}
+	res, err := http.Get(strings.TrimSuffix(src, "/") + "/" + name)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch %s: %s", name, res.Status)
+	}
+	return io.ReadAll(res.Body)
+}
+
+// fetchHistoryArchive returns the local path of the given archive, downloading
+// it from a remote source into a temporary file, and verifies its sha256
+// checksum. The returned cleanup function removes any temporary file.
+func fetchHistoryArchive(src string, name string, sum []byte) (string, func(), error) {
+	if filepath.Base(name) != name {
+		return "", nil, fmt.Errorf("invalid archive name %q", name)
+	}
+	var (
+		path    = filepath.Join(src, name)
+		cleanup = func() {}
+		hasher  = sha256.New()
+	)
+	if !isRemoteHistory(src) {
+		f, err := os.Open(path)
+		if err != nil {
+			return "", nil, err
+		}
+		_, err = io.Copy(hasher, f)
+		f.Close()
+		if err != nil {
+			return "", nil, err
+		}
+	} else {
+		log.Info("Downloading history archive", "name", name)
+		res, err := http.Get(strings.TrimSuffix(src, "/") + "/" + name)
+		if err != nil {
+			return "", nil, err
+		}
+		defer res.Body.Close()
+
+		if res.StatusCode != http.StatusOK {
+			return "", nil, fmt.Errorf("failed to fetch %s: %s", name, res.Status)
+		}
+		f, err := os.CreateTemp("", "history-*.era1")
+		if err != nil {
+			return "", nil, err
+		}
+		path, cleanup = f.Name(), func() { os.Remove(f.Name()) }
+
+		_, err = io.Copy(io.MultiWriter(f, hasher), res.Body)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			cleanup()
+			return "", nil, err
+		}
+	}
+	if have := hasher.Sum(nil); !bytes.Equal(have, sum) {
+		cleanup()
+		return "", nil, fmt.Errorf("checksum mismatch: have %x, want %x", have, sum)
+	}
+	return path, cleanup, nil
+}
@@ -0,0 +1,127 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package utils
+
+import (
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/qydata/go-ctereum/common"
+	"github.com/qydata/go-ctereum/consensus/ethash"
+	"github.com/qydata/go-ctereum/core"
+	"github.com/qydata/go-ctereum/core/rawdb"
+	"github.com/qydata/go-ctereum/core/types"
+	"github.com/qydata/go-ctereum/core/vm"
+	"github.com/qydata/go-ctereum/crypto"
+	"github.com/qydata/go-ctereum/ethdb"
+	"github.com/qydata/go-ctereum/internal/era"
+	"github.com/qydata/go-ctereum/params"
+)
+
+// newHistoryChain creates a chain backed by a database with a freezer, holding
+// only the genesis of the given spec.
+func newHistoryChain(t *testing.T, gspec *core.Genesis) (*core.BlockChain, ethdb.Database) {
+	db, err := rawdb.NewDatabaseWithFreezer(rawdb.NewMemoryDatabase(), t.TempDir(), "", false)
+	if err != nil {
+		t.Fatalf("failed to create database: %v", err)
+	}
+	gspec.MustCommit(db)
+
+	chain, err := core.NewBlockChain(db, nil, gspec.Config, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	return chain, db
+}
+
+// Tests that the history exported into Era1 archives can be imported into a
+// fresh node, both from a directory and over http.
+func TestHistoryExportImport(t *testing.T) {
+	var (
+		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		address = crypto.PubkeyToAddress(key.PublicKey)
+		gspec   = &core.Genesis{
+			Config:  params.TestChainConfig,
+			Alloc:   core.GenesisAlloc{address: {Balance: big.NewInt(params.Ether)}},
+			BaseFee: big.NewInt(params.InitialBaseFee),
+		}
+		count = era.MaxEra1Size + 10
+	)
+	chain, db := newHistoryChain(t, gspec)
+	defer chain.Stop()
+
+	blocks, _ := core.GenerateChain(gspec.Config, chain.Genesis(), ethash.NewFaker(), db, count, func(i int, b *core.BlockGen) {
+		if i%1000 != 0 {
+			return
+		}
+		tx, err := types.SignTx(types.NewTransaction(b.TxNonce(address), common.Address{0x01}, big.NewInt(1), params.TxGas, b.BaseFee(), nil), types.LatestSigner(gspec.Config), key)
+		if err != nil {
+			t.Fatalf("failed to sign tx: %v", err)
+		}
+		b.AddTx(tx)
+	})
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	dir := t.TempDir()
+	if err := ExportHistory(chain, dir, "test", 0, uint64(count)); err != nil {
+		t.Fatalf("failed to export history: %v", err)
+	}
+	list, err := os.ReadFile(filepath.Join(dir, historyChecksumsFile))
+	if err != nil {
+		t.Fatalf("failed to read checksums: %v", err)
+	}
+	if lines := strings.Split(strings.TrimSpace(string(list)), "\n"); len(lines) != 2 {
+		t.Fatalf("archive count mismatch: have %d, want 2", len(lines))
+	}
+	server := httptest.NewServer(http.FileServer(http.Dir(dir)))
+	defer server.Close()
+
+	for _, src := range []string{dir, server.URL} {
+		imported, importedDb := newHistoryChain(t, gspec)
+		if err := ImportHistory(imported, importedDb, src); err != nil {
+			t.Fatalf("failed to import history from %s: %v", src, err)
+		}
+		if head := imported.CurrentFastBlock(); head.Hash() != blocks[count-1].Hash() {
+			t.Errorf("head mismatch: have #%d, want #%d", head.NumberU64(), count)
+		}
+		for _, block := range []*types.Block{blocks[0], blocks[1000], blocks[count-1]} {
+			if !imported.HasBlock(block.Hash(), block.NumberU64()) {
+				t.Errorf("block #%d missing", block.NumberU64())
+			}
+			want, have := chain.GetReceiptsByHash(block.Hash()), imported.GetReceiptsByHash(block.Hash())
+			if len(have) != len(want) {
+				t.Fatalf("block #%d receipt count mismatch: have %d, want %d", block.NumberU64(), len(have), len(want))
+			}
+			for i := range want {
+				if have[i].TxHash != want[i].TxHash || have[i].CumulativeGasUsed != want[i].CumulativeGasUsed {
+					t.Errorf("block #%d receipt %d mismatch", block.NumberU64(), i)
+				}
+			}
+		}
+		// Importing again is a noop
+		if err := ImportHistory(imported, importedDb, src); err != nil {
+			t.Errorf("failed to reimport history: %v", err)
+		}
+		imported.Stop()
+	}
+}
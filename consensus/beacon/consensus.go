@@ -26,6 +26,7 @@ import (
 	"github.com/qydata/go-ctereum/consensus/misc"
 	"github.com/qydata/go-ctereum/core/state"
 	"github.com/qydata/go-ctereum/core/types"
+	"github.com/qydata/go-ctereum/core/vm"
 	"github.com/qydata/go-ctereum/params"
 	"github.com/qydata/go-ctereum/rpc"
 	"github.com/qydata/go-ctereum/trie"
@@ -263,6 +264,9 @@ func (beacon *Beacon) verifyHeader(chain consensus.ChainHeaderReader, header, pa
 	if header.GasUsed > header.GasLimit {
 		return fmt.Errorf("invalid gasUsed: have %d, gasLimit %d", header.GasUsed, header.GasLimit)
 	}
+	if err := misc.VerifyGasLimitBounds(chain.Config(), parent, header); err != nil {
+		return err
+	}
 	// Verify that the block number is parent's +1
 	if diff := new(big.Int).Sub(header.Number, parent.Number); diff.Cmp(common.Big1) != 0 {
 		return consensus.ErrInvalidNumber
@@ -339,6 +343,26 @@ func (beacon *Beacon) Finalize(chain consensus.ChainHeaderReader, header *types.
 	header.Root = state.IntermediateRoot(true)
 }
 
+// FinalizeWithTracer implements consensus.TracingFinalizer, running the system
+// calls of the eth1 engine through the given tracer.
+func (beacon *Beacon) FinalizeWithTracer(chain consensus.ChainHeaderReader, header *types.Header, state *state.StateDB, txs []*types.Transaction, uncles []*types.Header, tracer vm.EVMLogger) {
+	if engine, ok := beacon.ethone.(consensus.TracingFinalizer); ok && !beacon.IsPoSHeader(header) {
+		engine.FinalizeWithTracer(chain, header, state, txs, uncles, tracer)
+		return
+	}
+	beacon.Finalize(chain, header, state, txs, uncles)
+}
+
+// FinalizeDetached implements consensus.DetachedFinalizer, finalizing the
+// pre-merge blocks through the eth1 engine.
+func (beacon *Beacon) FinalizeDetached(chain consensus.ChainHeaderReader, header *types.Header, state *state.StateDB) {
+	if engine, ok := beacon.ethone.(consensus.DetachedFinalizer); ok && !beacon.IsPoSHeader(header) {
+		engine.FinalizeDetached(chain, header, state)
+		return
+	}
+	beacon.Finalize(chain, header, state, nil, nil)
+}
+
 // FinalizeAndAssemble implements consensus.Engine, setting the final state and
 // assembling the block.
 func (beacon *Beacon) FinalizeAndAssemble(chain consensus.ChainHeaderReader, header *types.Header, state *state.StateDB, txs []*types.Transaction, uncles []*types.Header, receipts []*types.Receipt) (*types.Block, error) {
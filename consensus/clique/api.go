@@ -17,17 +17,25 @@
 package clique
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
 
 	"github.com/qydata/go-ctereum/common"
 	"github.com/qydata/go-ctereum/common/hexutil"
 	"github.com/qydata/go-ctereum/consensus"
+	"github.com/qydata/go-ctereum/consensus/clique/statefull"
+	"github.com/qydata/go-ctereum/consensus/clique/valset"
 	"github.com/qydata/go-ctereum/core/types"
 	"github.com/qydata/go-ctereum/rlp"
 	"github.com/qydata/go-ctereum/rpc"
 )
 
+// maxRotationSlots is the maximum number of upcoming blocks the signer rotation
+// can be requested for in one call.
+const maxRotationSlots = 1024
+
 // API is a user facing RPC API to allow controlling the signer and voting
 // mechanisms of the proof-of-authority scheme.
 type API struct {
@@ -93,6 +101,160 @@ func (api *API) GetSignersAtHash(hash common.Hash) ([]common.Address, error) {
 	return snap.signers(), nil
 }
 
+// ProposerSlot is the expected proposer of an upcoming block.
+type ProposerSlot struct {
+	Number   uint64         `json:"number"`   // Block number of the slot
+	InTurn   common.Address `json:"inturn"`   // Signer expected to seal the block in-turn
+	Recently bool           `json:"recently"` // Whether the in-turn signer signed too recently to seal
+}
+
+// ProducerSet is the block producer view at a given block: the staking
+// contract validators (once Poa2Pos is active) and the signer rotation order
+// derived from the voting snapshot.
+type ProducerSet struct {
+	Number     uint64              `json:"number"`
+	Hash       common.Hash         `json:"hash"`
+	Validators []*valset.Validator `json:"validators"`
+	Signers    []common.Address    `json:"signers"`
+}
+
+// GetProducers retrieves the producer set at the specified block.
+func (api *API) GetProducers(number *rpc.BlockNumber) (*ProducerSet, error) {
+	var header *types.Header
+	if number == nil || *number == rpc.LatestBlockNumber {
+		header = api.chain.CurrentHeader()
+	} else {
+		header = api.chain.GetHeaderByNumber(uint64(number.Int64()))
+	}
+	if header == nil {
+		return nil, errUnknownBlock
+	}
+	return api.producers(header)
+}
+
+// GetProducersAtHash retrieves the producer set at the specified block.
+func (api *API) GetProducersAtHash(hash common.Hash) (*ProducerSet, error) {
+	header := api.chain.GetHeaderByHash(hash)
+	if header == nil {
+		return nil, errUnknownBlock
+	}
+	return api.producers(header)
+}
+
+func (api *API) producers(header *types.Header) (*ProducerSet, error) {
+	snap, err := api.clique.snapshot(api.chain, header.Number.Uint64(), header.Hash(), nil)
+	if err != nil {
+		return nil, err
+	}
+	set := &ProducerSet{
+		Number:     header.Number.Uint64(),
+		Hash:       header.Hash(),
+		Validators: []*valset.Validator{},
+		Signers:    snap.signers(),
+	}
+	if api.chain.Config().IsPoa2Pos(header.Number) {
+		validators, err := api.clique.spanner.GetCurrentValidators(context.Background(), header.Hash(), header.Number.Uint64()+1)
+		if err != nil {
+			return nil, err
+		}
+		set.Validators = validators
+	}
+	return set, nil
+}
+
+// GetSignerRotation returns the expected in-turn signer of the count blocks
+// following the specified block, based on the signer rotation of its snapshot.
+func (api *API) GetSignerRotation(number *rpc.BlockNumber, count *hexutil.Uint64) ([]*ProposerSlot, error) {
+	var header *types.Header
+	if number == nil || *number == rpc.LatestBlockNumber {
+		header = api.chain.CurrentHeader()
+	} else {
+		header = api.chain.GetHeaderByNumber(uint64(number.Int64()))
+	}
+	if header == nil {
+		return nil, errUnknownBlock
+	}
+	slots := uint64(1)
+	if count != nil {
+		slots = uint64(*count)
+	}
+	if slots == 0 || slots > maxRotationSlots {
+		return nil, fmt.Errorf("invalid slot count %d, must be within [1, %d]", slots, maxRotationSlots)
+	}
+	snap, err := api.clique.snapshot(api.chain, header.Number.Uint64(), header.Hash(), nil)
+	if err != nil {
+		return nil, err
+	}
+	return snap.rotation(header.Number.Uint64()+1, slots), nil
+}
+
+// Reconciliation lists the divergences between the signers of a snapshot and
+// the validators registered in the staking contract at the same block.
+type Reconciliation struct {
+	Number     uint64              `json:"number"`
+	Hash       common.Hash         `json:"hash"`
+	InSync     bool                `json:"inSync"`
+	Unstaked   []common.Address    `json:"unstaked"`   // Signers without an eligible stake
+	Unsigned   []common.Address    `json:"unsigned"`   // Eligible validators that are not signers
+	Ineligible []*valset.Validator `json:"ineligible"` // Validators whose stake differs from the required amount
+}
+
+// ReconcileValidators compares the snapshot signers at the specified block with
+// the validators reported by the staking contract and returns the divergences.
+func (api *API) ReconcileValidators(number *rpc.BlockNumber) (*Reconciliation, error) {
+	var header *types.Header
+	if number == nil || *number == rpc.LatestBlockNumber {
+		header = api.chain.CurrentHeader()
+	} else {
+		header = api.chain.GetHeaderByNumber(uint64(number.Int64()))
+	}
+	if header == nil {
+		return nil, errUnknownBlock
+	}
+	if !api.chain.Config().IsPoa2Pos(header.Number) {
+		return nil, fmt.Errorf("staking not active at block %d", header.Number)
+	}
+	snap, err := api.clique.snapshot(api.chain, header.Number.Uint64(), header.Hash(), nil)
+	if err != nil {
+		return nil, err
+	}
+	validators, err := api.clique.spanner.GetCurrentValidators(context.Background(), header.Hash(), header.Number.Uint64()+1)
+	if err != nil {
+		return nil, err
+	}
+	return reconcile(snap, validators, api.clique.config.StakeAmount), nil
+}
+
+// reconcile computes the divergences between the snapshot signers and the
+// staking contract validators.
+func reconcile(snap *Snapshot, validators []*valset.Validator, stakeAmount int64) *Reconciliation {
+	res := &Reconciliation{
+		Number:     snap.Number,
+		Hash:       snap.Hash,
+		Unstaked:   []common.Address{},
+		Unsigned:   []common.Address{},
+		Ineligible: []*valset.Validator{},
+	}
+	eligible := eligibleValidators(validators, stakeAmount)
+	for _, signer := range snap.signers() {
+		if _, ok := eligible[signer]; !ok {
+			res.Unstaked = append(res.Unstaked, signer)
+		}
+	}
+	for _, validator := range validators {
+		if _, ok := eligible[validator.Address]; !ok {
+			res.Ineligible = append(res.Ineligible, validator)
+			continue
+		}
+		if _, ok := snap.Signers[validator.Address]; !ok {
+			res.Unsigned = append(res.Unsigned, validator.Address)
+		}
+	}
+	sort.Sort(signersAscending(res.Unsigned))
+	res.InSync = len(res.Unstaked) == 0 && len(res.Unsigned) == 0
+	return res
+}
+
 // Proposals returns the current proposals the node tries to uphold and vote on.
 func (api *API) Proposals() map[common.Address]bool {
 	api.clique.lock.RLock()
@@ -135,7 +297,7 @@ type status struct {
 // - the percentage of in-turn blocks
 func (api *API) Status() (*status, error) {
 	var (
-		numBlocks = uint64(64)
+		numBlocks = api.clique.statusWindow()
 		header    = api.chain.CurrentHeader()
 		diff      = uint64(0)
 		optimals  = 0
@@ -233,3 +395,13 @@ func (api *API) GetSigner(rlpOrBlockNr *blockNumberOrHashOrRLP) (common.Address,
 	}
 	return api.clique.Author(header)
 }
+
+// DebugAPI exposes the internals of the proof-of-stake system calls.
+type DebugAPI struct{}
+
+// SystemCallStats returns the gas used, call and failure counters and the last
+// decoded revert reason of the system calls issued since startup, keyed by the
+// validator contract method.
+func (api *DebugAPI) SystemCallStats() map[string]statefull.CallStats {
+	return statefull.Stats()
+}
@@ -37,6 +37,7 @@ import (
 	"github.com/qydata/go-ctereum/consensus/misc"
 	"github.com/qydata/go-ctereum/core/state"
 	"github.com/qydata/go-ctereum/core/types"
+	"github.com/qydata/go-ctereum/core/vm"
 	"github.com/qydata/go-ctereum/crypto"
 	"github.com/qydata/go-ctereum/ethdb"
 	"github.com/qydata/go-ctereum/log"
@@ -80,68 +81,75 @@ var (
 var (
 	// errUnknownBlock is returned when the list of signers is requested for a block
 	// that is not part of the local blockchain.
-	errUnknownBlock = errors.New("unknown block")
+	errUnknownBlock = consensus.NewCodedError(1001, "UNKNOWN_BLOCK", "unknown block")
 
 	// errInvalidCheckpointBeneficiary is returned if a checkpoint/epoch transition
 	// block has a beneficiary set to non-zeroes.
-	errInvalidCheckpointBeneficiary = errors.New("beneficiary in checkpoint block non-zero")
+	errInvalidCheckpointBeneficiary = consensus.NewCodedError(1002, "INVALID_CHECKPOINT_BENEFICIARY", "beneficiary in checkpoint block non-zero")
 
 	// errInvalidVote is returned if a nonce value is something else that the two
 	// allowed constants of 0x00..0 or 0xff..f.
-	errInvalidVote = errors.New("vote nonce not 0x00..0 or 0xff..f")
+	errInvalidVote = consensus.NewCodedError(1003, "INVALID_VOTE", "vote nonce not 0x00..0 or 0xff..f")
 
 	// errInvalidCheckpointVote is returned if a checkpoint/epoch transition block
 	// has a vote nonce set to non-zeroes.
-	errInvalidCheckpointVote = errors.New("vote nonce in checkpoint block non-zero")
+	errInvalidCheckpointVote = consensus.NewCodedError(1004, "INVALID_CHECKPOINT_VOTE", "vote nonce in checkpoint block non-zero")
 
 	// errMissingVanity is returned if a block's extra-data section is shorter than
 	// 32 bytes, which is required to store the signer vanity.
-	errMissingVanity = errors.New("extra-data 32 byte vanity prefix missing")
+	errMissingVanity = consensus.NewCodedError(1005, "MISSING_VANITY", "extra-data 32 byte vanity prefix missing")
 
 	// errMissingSignature is returned if a block's extra-data section doesn't seem
 	// to contain a 65 byte secp256k1 signature.
-	errMissingSignature = errors.New("extra-data 65 byte signature suffix missing")
+	errMissingSignature = consensus.NewCodedError(1006, "MISSING_SIGNATURE", "extra-data 65 byte signature suffix missing")
 
 	// errExtraSigners is returned if non-checkpoint block contain signer data in
 	// their extra-data fields.
-	errExtraSigners = errors.New("non-checkpoint block contains extra signer list")
+	errExtraSigners = consensus.NewCodedError(1007, "EXTRA_SIGNERS", "non-checkpoint block contains extra signer list")
 
 	// errInvalidCheckpointSigners is returned if a checkpoint block contains an
 	// invalid list of signers (i.e. non divisible by 20 bytes).
-	errInvalidCheckpointSigners = errors.New("invalid signer list on checkpoint block")
+	errInvalidCheckpointSigners = consensus.NewCodedError(1008, "INVALID_CHECKPOINT_SIGNERS", "invalid signer list on checkpoint block")
 
 	// errMismatchingCheckpointSigners is returned if a checkpoint block contains a
 	// list of signers different than the one the local node calculated.
-	errMismatchingCheckpointSigners = errors.New("mismatching signer list on checkpoint block")
+	errMismatchingCheckpointSigners = consensus.NewCodedError(1009, "MISMATCHING_CHECKPOINT_SIGNERS", "mismatching signer list on checkpoint block")
 
 	// errInvalidMixDigest is returned if a block's mix digest is non-zero.
-	errInvalidMixDigest = errors.New("non-zero mix digest")
+	errInvalidMixDigest = consensus.NewCodedError(1010, "INVALID_MIX_DIGEST", "non-zero mix digest")
 
 	// errInvalidUncleHash is returned if a block contains an non-empty uncle list.
-	errInvalidUncleHash = errors.New("non empty uncle hash")
+	errInvalidUncleHash = consensus.NewCodedError(1011, "INVALID_UNCLE_HASH", "non empty uncle hash")
 
 	// errInvalidDifficulty is returned if the difficulty of a block neither 1 or 2.
-	errInvalidDifficulty = errors.New("invalid difficulty")
+	errInvalidDifficulty = consensus.NewCodedError(1012, "INVALID_DIFFICULTY", "invalid difficulty")
 
 	// errWrongDifficulty is returned if the difficulty of a block doesn't match the
 	// turn of the signer.
-	errWrongDifficulty = errors.New("wrong difficulty")
+	errWrongDifficulty = consensus.NewCodedError(1013, "WRONG_DIFFICULTY", "wrong difficulty")
 
 	// errInvalidTimestamp is returned if the timestamp of a block is lower than
 	// the previous block's timestamp + the minimum block period.
-	errInvalidTimestamp = errors.New("invalid timestamp")
+	errInvalidTimestamp = consensus.NewCodedError(1014, "INVALID_TIMESTAMP", "invalid timestamp")
 
 	// errInvalidVotingChain is returned if an authorization list is attempted to
 	// be modified via out-of-range or non-contiguous headers.
-	errInvalidVotingChain = errors.New("invalid voting chain")
+	errInvalidVotingChain = consensus.NewCodedError(1015, "INVALID_VOTING_CHAIN", "invalid voting chain")
 
 	// errUnauthorizedSigner is returned if a header is signed by a non-authorized entity.
-	errUnauthorizedSigner = errors.New("unauthorized signer")
+	errUnauthorizedSigner = consensus.NewCodedError(1016, "UNAUTHORIZED_SIGNER", "unauthorized signer")
 
 	// errRecentlySigned is returned if a header is signed by an authorized entity
 	// that already signed a header recently, thus is temporarily not allowed to.
-	errRecentlySigned    = errors.New("recently signed")
-	errUnknownValidators = errors.New("unknown validators")
+	errRecentlySigned = consensus.NewCodedError(1017, "RECENTLY_SIGNED", "recently signed")
+
+	// errUnknownValidators is returned if the validator set of a header could not
+	// be retrieved from the validator contract.
+	errUnknownValidators = consensus.NewCodedError(1018, "UNKNOWN_VALIDATORS", "unknown validators")
+
+	// errSealingStopped is returned if a block is requested to be sealed after the
+	// sealing was stopped for shutdown.
+	errSealingStopped = errors.New("sealing stopped for shutdown")
 )
 
 // SignerFn hashes and signs the data to be signed by a backing account.
@@ -191,6 +199,19 @@ type Clique struct {
 	fakeDiff bool // Skip difficulty verifications
 
 	spanner Spanner
+
+	activityWindow uint64 // Number of recent blocks the status API reports on
+	trustedHeight  uint64 // Height below which the seals are trusted without verification
+
+	sealing  sync.WaitGroup // In-flight seals waiting for their slot
+	draining bool           // Whether new sealing work is refused, protected by lock
+
+	head     *Snapshot   // Most recent snapshot, flushed to disk on close
+	headLock sync.Mutex  // Protects the head snapshot
+	flushed  common.Hash // Hash of the snapshot flushed on the previous close
+
+	unpaidLogged time.Time  // Time of the last failed reimbursement warning
+	unpaidLock   sync.Mutex // Protects the unpaidLogged field
 }
 
 // New creates a Clique proof-of-authority consensus engine with the initial
@@ -212,15 +233,149 @@ func New(config *params.CliqueConfig, db ethdb.Database, spanner Spanner) *Cliqu
 		signatures: signatures,
 		proposals:  make(map[common.Address]bool),
 		spanner:    spanner,
+		flushed:    readFlushedSnapshot(db),
 	}
 }
 
+// SetActivityWindow sets the number of recent blocks the signer activity of the
+// status API is computed over. It doesn't affect the activity check done during
+// block finalization, which is part of consensus.
+func (c *Clique) SetActivityWindow(blocks uint64) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.activityWindow = blocks
+}
+
+// SetTrustedHeight makes the engine trust the seals of the headers below the
+// given height, skipping the verification of their signer and checkpoint signer
+// list. It is meant for offline imports of chains from a trusted source: the
+// sealers are still recovered while processing the blocks, as the block reward
+// and the coinbase of the EVM depend on them, so the state roots are verified.
+func (c *Clique) SetTrustedHeight(number uint64) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.trustedHeight = number
+}
+
+// trustedSeal reports whether the seal of the header with the given number is
+// trusted without verification.
+func (c *Clique) trustedSeal(number uint64) bool {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return number < c.trustedHeight
+}
+
+// statusWindow returns the number of recent blocks the status is computed over.
+func (c *Clique) statusWindow() uint64 {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	if c.activityWindow == 0 {
+		return 64
+	}
+	return c.activityWindow
+}
+
 // Author implements consensus.Engine, returning the Ethereum address recovered
 // from the signature in the header's extra-data section.
 func (c *Clique) Author(header *types.Header) (common.Address, error) {
 	return ecrecover(header, c.signatures)
 }
 
+// SealInfo is the seal metadata of a block, as seen by the signers authorized
+// by its parent.
+type SealInfo struct {
+	Sealer          common.Address  `json:"sealer"`          // Signer recovered from the extra-data
+	InTurn          bool            `json:"inTurn"`          // Whether the sealer was in-turn
+	Validators      int             `json:"validators"`      // Number of signers authorized to seal the block
+	RewardRecipient *common.Address `json:"rewardRecipient"` // Recipient of the block reward, nil if none is paid
+}
+
+// SealInfo recovers the sealer of the given block and checks it against the
+// signer set of its parent.
+func (c *Clique) SealInfo(chain consensus.ChainHeaderReader, header *types.Header) (*SealInfo, error) {
+	number := header.Number.Uint64()
+	if number == 0 {
+		return nil, errUnknownBlock
+	}
+	sealer, err := c.Author(header)
+	if err != nil {
+		return nil, err
+	}
+	snap, err := c.snapshot(chain, number-1, header.ParentHash, nil)
+	if err != nil {
+		return nil, err
+	}
+	info := &SealInfo{
+		Sealer:     sealer,
+		InTurn:     snap.inturn(number, sealer),
+		Validators: len(snap.Signers),
+	}
+	// The block reward goes to the sealer of the parent block, see finalize
+	if number != 1 && !chain.Config().IsImplAuth(header.Number) {
+		recipient := snap.Recents[number-1]
+		info.RewardRecipient = &recipient
+	}
+	return info, nil
+}
+
+// ValidatorStatus is the state of the validator set at a block.
+type ValidatorStatus struct {
+	Signers    int          `json:"signers"`    // Number of signers authorized to seal the next block
+	Validators int          `json:"validators"` // Number of eligible staking validators, zero before Poa2Pos
+	Staked     *hexutil.Big `json:"staked"`     // Total stake of the eligible validators, nil before Poa2Pos
+	Checkpoint uint64       `json:"checkpoint"` // Last epoch checkpoint block
+}
+
+// ValidatorStatus returns the signers authorized by the given block and, once
+// Poa2Pos is active, the eligible validators of the staking contract.
+func (c *Clique) ValidatorStatus(chain consensus.ChainHeaderReader, header *types.Header) (*ValidatorStatus, error) {
+	number := header.Number.Uint64()
+	snap, err := c.snapshot(chain, number, header.Hash(), nil)
+	if err != nil {
+		return nil, err
+	}
+	status := &ValidatorStatus{
+		Signers:    len(snap.Signers),
+		Checkpoint: number - number%c.config.Epoch,
+	}
+	if c.spanner != nil && chain.Config().IsPoa2Pos(header.Number) {
+		validators, err := c.spanner.GetCurrentValidators(context.Background(), header.Hash(), number+1)
+		if err != nil {
+			return nil, err
+		}
+		eligible, staked := eligibleValidators(validators, c.config.StakeAmount), new(big.Int)
+		for _, validator := range eligible {
+			staked.Add(staked, big.NewInt(validator.ProposerPriority))
+		}
+		status.Validators, status.Staked = len(eligible), (*hexutil.Big)(staked)
+	}
+	return status, nil
+}
+
+// IsValidator reports whether the address is an eligible staking validator of
+// the block following the given one once Poa2Pos is active, or an authorized
+// signer before.
+func (c *Clique) IsValidator(chain consensus.ChainHeaderReader, header *types.Header, addr common.Address) (bool, error) {
+	if c.spanner != nil && chain.Config().IsPoa2Pos(header.Number) {
+		validators, err := c.Validators(header)
+		if err != nil {
+			return false, err
+		}
+		_, ok := eligibleValidators(validators, c.config.StakeAmount)[addr]
+		return ok, nil
+	}
+	snap, err := c.snapshot(chain, header.Number.Uint64(), header.Hash(), nil)
+	if err != nil {
+		return false, err
+	}
+	_, ok := snap.Signers[addr]
+	return ok, nil
+}
+
 // VerifyHeader checks whether a header conforms to the consensus rules.
 func (c *Clique) VerifyHeader(chain consensus.ChainHeaderReader, header *types.Header, seal bool) error {
 	return c.verifyHeader(chain, header, nil)
@@ -341,6 +496,9 @@ func (c *Clique) verifyCascadingFields(chain consensus.ChainHeaderReader, header
 	if header.GasUsed > header.GasLimit {
 		return fmt.Errorf("invalid gasUsed: have %d, gasLimit %d", header.GasUsed, header.GasLimit)
 	}
+	if err := misc.VerifyGasLimitBounds(chain.Config(), parent, header); err != nil {
+		return err
+	}
 	if !chain.Config().IsLondon(header.Number) {
 		// Verify BaseFee not present before EIP-1559 fork.
 		if header.BaseFee != nil {
@@ -353,6 +511,10 @@ func (c *Clique) verifyCascadingFields(chain consensus.ChainHeaderReader, header
 		// Verify the header's EIP-1559 attributes.
 		return err
 	}
+	// Trusted seals need neither the snapshot nor the signature of the header
+	if c.trustedSeal(number) {
+		return nil
+	}
 	// Retrieve the snapshot needed to verify this header and cache it
 	snap, err := c.snapshot(chain, number-1, header.ParentHash, parents)
 	if err != nil {
@@ -387,8 +549,9 @@ func (c *Clique) snapshot(chain consensus.ChainHeaderReader, number uint64, hash
 			snap = s.(*Snapshot)
 			break
 		}
-		// If an on-disk checkpoint snapshot can be found, use that
-		if number%checkpointInterval == 0 {
+		// If an on-disk checkpoint snapshot, or the one flushed on the previous
+		// shutdown, can be found, use that
+		if number%checkpointInterval == 0 || hash == c.flushed {
 			if s, err := loadSnapshot(c.config, c.signatures, c.db, hash); err == nil {
 				log.Trace("Loaded voting snapshot from disk", "number", number, "hash", hash)
 				snap = s
@@ -404,11 +567,7 @@ func (c *Clique) snapshot(chain consensus.ChainHeaderReader, number uint64, hash
 			if checkpoint != nil {
 				hash := checkpoint.Hash()
 
-				signers := make([]common.Address, (len(checkpoint.Extra)-extraVanity-extraSeal)/common.AddressLength)
-				for i := 0; i < len(signers); i++ {
-					copy(signers[i][:], checkpoint.Extra[extraVanity+i*common.AddressLength:])
-				}
-				snap = newSnapshot(c.config, c.signatures, number, hash, signers)
+				snap = newSnapshot(c.config, c.signatures, number, hash, checkpointSigners(checkpoint))
 				if err := snap.store(c.db); err != nil {
 					return nil, err
 				}
@@ -446,6 +605,12 @@ func (c *Clique) snapshot(chain consensus.ChainHeaderReader, number uint64, hash
 	}
 	c.recents.Add(snap.Hash, snap)
 
+	c.headLock.Lock()
+	if c.head == nil || snap.Number > c.head.Number {
+		c.head = snap
+	}
+	c.headLock.Unlock()
+
 	// If we've generated a new checkpoint snapshot, save to disk
 	if snap.Number%checkpointInterval == 0 && len(headers) > 0 {
 		if err = snap.store(c.db); err != nil {
@@ -525,11 +690,11 @@ func (c *Clique) Prepare(chain consensus.ChainHeaderReader, header *types.Header
 
 			newValidators, err := c.spanner.GetCurrentValidators(context.Background(), header.ParentHash, number+1)
 			if err1 := snap.updateSigners(newValidators, c); err1 != nil {
-				log.Info("updateSigners", "Err:", err1)
+				log.Warn("Failed to update signers", "number", number, "err", err1)
 				//}
 			}
 			if err != nil {
-				log.Info("Prepare", "err:", err)
+				log.Warn("Failed to retrieve validators", "number", number, "err", err)
 				return errUnknownValidators
 			}
 
@@ -590,6 +755,18 @@ func (c *Clique) Prepare(chain consensus.ChainHeaderReader, header *types.Header
 // Finalize implements consensus.Engine, ensuring no uncles are set, nor block
 // rewards given.
 func (c *Clique) Finalize(chain consensus.ChainHeaderReader, header *types.Header, state *state.StateDB, txs []*types.Transaction, uncles []*types.Header) {
+	c.finalize(context.Background(), chain, header, state)
+}
+
+// FinalizeWithTracer implements consensus.TracingFinalizer, running the system
+// calls through the given tracer.
+func (c *Clique) FinalizeWithTracer(chain consensus.ChainHeaderReader, header *types.Header, state *state.StateDB, txs []*types.Transaction, uncles []*types.Header, tracer vm.EVMLogger) {
+	c.finalize(statefull.WithTracer(context.Background(), tracer), chain, header, state)
+}
+
+// finalize applies the block rewards and the system calls of the block, the
+// latter being applied with the given context.
+func (c *Clique) finalize(ctx context.Context, chain consensus.ChainHeaderReader, header *types.Header, state *state.StateDB) {
 	//iozhaq  加入矿工奖励
 	blockReward := BlockReward
 	reward := new(big.Int).Set(blockReward)
@@ -597,7 +774,7 @@ func (c *Clique) Finalize(chain consensus.ChainHeaderReader, header *types.Heade
 	//log.Info("区块奖励签名地址打印number:", number)
 	snap, err := c.snapshot(chain, number-1, header.ParentHash, nil)
 	if err != nil {
-		log.Info("Finalize", "err", err)
+		log.Warn("Failed to retrieve snapshot", "number", number-1, "err", err)
 	}
 
 	rewardAddress := snap.Recents[number-1]
@@ -608,24 +785,13 @@ func (c *Clique) Finalize(chain consensus.ChainHeaderReader, header *types.Heade
 			state.AddBalance(rewardAddress, reward)
 		}
 	}
-
-	//if chain.Config().Poa2PosBlock == big.NewInt(0).SetUint64(number) {
-	if (header.Number.Int64() + 1) == c.config.Poa2PosBlock {
-		state.SetCode(
-			common.HexToAddress(c.config.ValidatorContract),
-			common.FromHex(string("0x6080604052600436106101145760003560e01c80638563e8c9116100a0578063d1bc0ee711610064578063d1bc0ee714610331578063e804fbf61461035e578063f2888dbb14610373578063f9fc17f514610393578063facd743b146103b357600080fd5b80638563e8c914610275578063b7ab4db5146102ab578063b9f8e7dc146102cf578063c5a222e4146102ef578063ca1e78191461030f57600080fd5b80633434735f116100e75780633434735f146101b7578063373d6132146101ea5780633fd3eb1f146101ff578063714ff425146102295780637a6eea371461023e57600080fd5b806302b75199146101195780630fbf5d92146101595780632367f6b51461016e57806326476204146101a4575b600080fd5b34801561012557600080fd5b506101466101343660046115e3565b60056020526000908152604090205481565b6040519081526020015b60405180910390f35b61016c610167366004611697565b6103ec565b005b34801561017a57600080fd5b506101466101893660046115e3565b6001600160a01b031660009081526002602052604090205490565b61016c6101b23660046115e3565b6104d1565b3480156101c357600080fd5b506101d26002600160a01b0381565b6040516001600160a01b039091168152602001610150565b3480156101f657600080fd5b50600654610146565b34801561020b57600080fd5b506009546102199060ff1681565b6040519015158152602001610150565b34801561023557600080fd5b50600754610146565b34801561024a57600080fd5b5061025d6a01a784379d99db4200000081565b6040516001600160801b039091168152602001610150565b34801561028157600080fd5b506101d26102903660046115e3565b6003602052600090815260409020546001600160a01b031681565b3480156102b757600080fd5b506102c061052c565b6040516101509392919061176e565b3480156102db57600080fd5b5061016c6102ea366004611675565b61086f565b3480156102fb57600080fd5b5061016c61030a366004611605565b6109c4565b34801561031b57600080fd5b50610324610b3d565b604051610150919061175b565b34801561033d57600080fd5b5061014661034c3660046115e3565b60046020526000908152604090205481565b34801561036a57600080fd5b50600854610146565b34801561037f57600080fd5b5061016c61038e3660046115e3565b610b9f565b34801561039f57600080fd5b5061016c6103ae366004611638565b610cce565b3480156103bf57600080fd5b506102196103ce3660046115e3565b6001600160a01b031660009081526001602052604090205460ff1690565b60095460ff161561043b5760405162461bcd60e51b8152602060048201526014602482015273416c726561647920696e697469616c697a65642160601b60448201526064015b60405180910390fd5b6007839055600882905560408051848152602081018490527f8288f503736de9545ced743c85bd6747df04791f503746e7e444d0015b7a7f77910160405180910390a160005b81518110156104be576104ac82828151811061049f5761049f611896565b6020026020010151610f1e565b806104b681611839565b915050610481565b50506009805460ff191660011790555050565b333b156105205760405162461bcd60e51b815260206004820152601b60248201527f4f6e6c7920454f412063616e2063616c6c2066756e6374696f6e2100000000006044820152606401610432565b61052981610f1e565b50565b6009546060908190819060ff1661063e57604080516001808252818301909252600091602080830190803683375050604080516001808252818301909252929350600092915060208083019080368337505060408051600180825281830190925292935060009291506020808301908036833701905050905073cebcbf16494edbad87d7feab0260ade82c571e5d836000815181106105cd576105cd611896565b60200260200101906001600160a01b031690816001600160a01b031681525050621e84808260008151811061060457610604611896565b602002602001018181525050621e84808160008151811061062757610627611896565b602090810291909101015291959094509092509050565b6000805467ffffffffffffffff81111561065a5761065a6118ac565b604051908082528060200260200182016040528015610683578160200160208202803683370190505b50600080549192509067ffffffffffffffff8111156106a4576106a46118ac565b6040519080825280602002602001820160405280156106cd578160200160208202803683370190505b50600080549192509067ffffffffffffffff8111156106ee576106ee6118ac565b604051908082528060200260200182016040528015610717578160200160208202803683370190505b50905060005b600054811015610862576000818154811061073a5761073a611896565b9060005260206000200160009054906101000a90046001600160a01b031684828151811061076a5761076a611896565b60200260200101906001600160a01b031690816001600160a01b031681525050670de0b6b3a7640000600260008084815481106107a9576107a9611896565b60009182526020808320909101546001600160a01b031683528201929092526040019020546107d891906117ef565b8382815181106107ea576107ea611896565b6020026020010181815250506004600080838154811061080c5761080c611896565b60009182526020808320909101546001600160a01b03168352820192909252604001902054825183908390811061084557610845611896565b60209081029190910101528061085a81611839565b91505061071d565b5091959094509092509050565b336002600160a01b03146108ba5760405162461bcd60e51b81526020600482015260126024820152714e6f742053797374656d204164646573732160701b6044820152606401610432565b81806108fc5760405162461bcd60e51b815260206004820152601160248201527076616c2063616e206e6f7420626520302160781b6044820152606401610432565b8183111561097c5760405162461bcd60e51b815260206004820152604160248201527f4d696e2076616c696461746f7273206e756d2063616e206e6f7420626520677260448201527f6561746572207468616e206d6178206e756d206f662076616c696461746f72736064820152602160f81b608482015260a401610432565b6007839055600882905560408051848152602081018490527f8288f503736de9545ced743c85bd6747df04791f503746e7e444d0015b7a7f77910160405180910390a1505050565b6001600160a01b038083166000908152600360205260409020548391163314610a2f5760405162461bcd60e51b815260206004820152601e60248201527f4f6e6c792073656e6465722063616e2063616c6c2066756e6374696f6e2100006044820152606401610432565b826001600160a01b038116610a7f5760405162461bcd60e51b8152602060048201526016602482015275616464722076616c2063616e206e6f7420626520302160501b6044820152606401610432565b826001600160a01b038116610acf5760405162461bcd60e51b8152602060048201526016602482015275616464722076616c2063616e206e6f7420626520302160501b6044820152606401610432565b6001600160a01b0385811660008181526003602090815260409182902080546001600160a01b031916948916948517905581519283528201929092527f831c28b544f77160ca9d466425fadde5c2e38b2370bf8079c4b67861d480536d910160405180910390a15050505050565b60606000805480602002602001604051908101604052809291908181526020018280548015610b9557602002820191906000526020600020905b81546001600160a01b03168152600190910190602001808311610b77575b5050505050905090565b333b15610bee5760405162461bcd60e51b815260206004820152601b60248201527f4f6e6c7920454f412063616e2063616c6c2066756e6374696f6e2100000000006044820152606401610432565b6001600160a01b0381166000908152600260205260409020548190610c555760405162461bcd60e51b815260206004820152601e60248201527f4f6e6c79207374616b65722063616e2063616c6c2066756e6374696f6e2100006044820152606401610432565b6001600160a01b038083166000908152600360205260409020548391163314610cc05760405162461bcd60e51b815260206004820152601e60248201527f4f6e6c792073656e6465722063616e2063616c6c2066756e6374696f6e2100006044820152606401610432565b610cc9836110ed565b505050565b336002600160a01b0314610d195760405162461bcd60e51b81526020600482015260126024820152714e6f742053797374656d204164646573732160701b6044820152606401610432565b60005b8151811015610f1a57670de0b6b3a764000060026000808481548110610d4457610d44611896565b60009182526020808320909101546001600160a01b03168352820192909252604001902054610d7391906117ef565b60046000848481518110610d8957610d89611896565b60200260200101516001600160a01b03166001600160a01b03168152602001908152602001600020541415610f085761271060046000848481518110610dd157610dd1611896565b60200260200101516001600160a01b03166001600160a01b031681526020019081526020016000206000828254610e089190611822565b9250508190555069021e19e0c9bab240000060066000828254610e2b9190611822565b9091555050604051339060009069021e19e0c9bab24000009082818181858883f19350505050158015610e62573d6000803e3d6000fd5b507f5c3feea8eff3540b84cbb449042c19315e2d8db6cce02c68ab8592d8a914ebcb828281518110610e9657610e96611896565b602002602001015160046000858581518110610eb457610eb4611896565b60200260200101516001600160a01b03166001600160a01b0316815260200190815260200160002054604051610eff9291906001600160a01b03929092168252602082015260400190565b60405180910390a15b80610f1281611839565b915050610d1c565b5050565b34610f625760405162461bcd60e51b81526020600482015260146024820152735374616b652076616c7565206973207a65726f2160601b6044820152606401610432565b3460066000828254610f7491906117b1565b90915550506001600160a01b03811660009081526002602052604081208054349290610fa19084906117b1565b90915550610fb99050670de0b6b3a7640000346117ef565b6001600160a01b03821660009081526004602052604081208054909190610fe19084906117b1565b90915550506001600160a01b038116600090815260036020526040902080546001600160a01b0319163317905561102b670de0b6b3a76400006a01a784379d99db420000006117c9565b6001600160a01b0382166000908152600460205260409020546001600160801b0391909116146110905760405162461bcd60e51b815260206004820152601060248201526f20b1b1bab69031b0b6319032b93937b960811b6044820152606401610432565b61109981611209565b156110a7576110a78161125b565b806001600160a01b03167f9e71bc8eea02a63969f509818f2dafb9254532904319f9dbda79b67bd34a5f3d346040516110e291815260200190565b60405180910390a250565b6001600160a01b0381166000908152600260205260408120805490829055600680549192839261111e908490611822565b90915550506001600160a01b03821660009081526001602052604090205460ff161561114d5761114d8261132c565b6001600160a01b03821660009081526004602052604090205461117890670de0b6b3a7640000611803565b6001600160a01b03831660008181526004602052604080822082905551929350909183156108fc0291849190818181858888f193505050501580156111c1573d6000803e3d6000fd5b50816001600160a01b03167f0f5bb82176feb1b5e747e28471aa92156a04d9f3ab9f45f28e2d704232b93f75826040516111fd91815260200190565b60405180910390a25050565b6001600160a01b03811660009081526001602052604081205460ff1615801561125557506001600160a01b0382166000908152600260205260409020546a01a784379d99db4200000011155b92915050565b600854600054106112bf5760405162461bcd60e51b815260206004820152602860248201527f56616c696461746f72207365742068617320726561636865642066756c6c2063604482015267617061636974792160c01b6064820152608401610432565b6001600160a01b03166000818152600160208181526040808420805460ff19168417905583546005909252832081905590810182559080527f290decd9548b62a8d60345a988386fc84ba6bc95484008f6362f93160ef3e5630180546001600160a01b0319169091179055565b600754600054116113af5760405162461bcd60e51b815260206004820152604160248201527f56616c696461746f72732063616e2774206265206c657373207468616e20746860448201527f65206d696e696d756d2072657175697265642076616c696461746f72206e756d6064820152602160f81b608482015260a401610432565b600080546001600160a01b038316825260056020526040909120541061140d5760405162461bcd60e51b8152602060048201526013602482015272696e646578206f7574206f662072616e67652160681b6044820152606401610432565b6001600160a01b038116600090815260056020526040812054815490919061143790600190611822565b90508082146114bc57600080828154811061145457611454611896565b600091825260208220015481546001600160a01b0390911692508291908590811061148157611481611896565b600091825260208083209190910180546001600160a01b0319166001600160a01b039485161790559290911681526005909152604090208290555b6001600160a01b0383166000908152600160209081526040808320805460ff19169055600590915281208190558054806114f8576114f8611880565b600082815260209020810160001990810180546001600160a01b0319169055019055505050565b80356001600160a01b038116811461153657600080fd5b919050565b600082601f83011261154c57600080fd5b8135602067ffffffffffffffff80831115611569576115696118ac565b8260051b604051601f19603f8301168101818110848211171561158e5761158e6118ac565b604052848152838101925086840182880185018910156115ad57600080fd5b600092505b858310156115d7576115c38161151f565b8452928401926001929092019184016115b2565b50979650505050505050565b6000602082840312156115f557600080fd5b6115fe8261151f565b9392505050565b6000806040838503121561161857600080fd5b6116218361151f565b915061162f6020840161151f565b90509250929050565b60006020828403121561164a57600080fd5b813567ffffffffffffffff81111561166157600080fd5b61166d8482850161153b565b949350505050565b6000806040838503121561168857600080fd5b50508035926020909101359150565b6000806000606084860312156116ac57600080fd5b8335925060208401359150604084013567ffffffffffffffff8111156116d157600080fd5b6116dd8682870161153b565b9150509250925092565b600081518084526020808501945080840160005b838110156117205781516001600160a01b0316875295820195908201906001016116fb565b509495945050505050565b600081518084526020808501945080840160005b838110156117205781518752958201959082019060010161173f565b6020815260006115fe60208301846116e7565b60608152600061178160608301866116e7565b8281036020840152611793818661172b565b905082810360408401526117a7818561172b565b9695505050505050565b600082198211156117c4576117c4611854565b500190565b60006001600160801b03808416806117e3576117e361186a565b92169190910492915050565b6000826117fe576117fe61186a565b500490565b600081600019048311821515161561181d5761181d611854565b500290565b60008282101561183457611834611854565b500390565b600060001982141561184d5761184d611854565b5060010190565b634e487b7160e01b600052601160045260246000fd5b634e487b7160e01b600052601260045260246000fd5b634e487b7160e01b600052603160045260246000fd5b634e487b7160e01b600052603260045260246000fd5b634e487b7160e01b600052604160045260246000fdfea264697066735822122038a908c2c4bc79ece6d2485297ba5769f998623c52c2fbb896c50f12d642a04a64736f6c63430008070033")),
-		)
-		// 一百亿发行
-		rewardY, _ := big.NewInt(0).SetString("8974832090000000000000000000", 10)
-		state.AddBalance(common.HexToAddress("0xEa8943f4c47Ab8602eCCD3ed5087512f75C14E60"), rewardY)
-	}
-	if header.Number.Int64() == 5185000 {
-		state.SetCode(
-			common.HexToAddress(c.config.ValidatorContract),
-			common.FromHex(string("0x6080604052600436106101145760003560e01c80638563e8c9116100a0578063d1bc0ee711610064578063d1bc0ee714610331578063e804fbf61461035e578063f2888dbb14610373578063f9fc17f514610393578063facd743b146103b357600080fd5b80638563e8c914610275578063b7ab4db5146102ab578063b9f8e7dc146102cf578063c5a222e4146102ef578063ca1e78191461030f57600080fd5b80633434735f116100e75780633434735f146101b7578063373d6132146101ea5780633fd3eb1f146101ff578063714ff425146102295780637a6eea371461023e57600080fd5b806302b75199146101195780630fbf5d92146101595780632367f6b51461016e57806326476204146101a4575b600080fd5b34801561012557600080fd5b50610146610134366004611603565b60056020526000908152604090205481565b6040519081526020015b60405180910390f35b61016c6101673660046116b7565b6103ec565b005b34801561017a57600080fd5b50610146610189366004611603565b6001600160a01b031660009081526002602052604090205490565b61016c6101b2366004611603565b6104d1565b3480156101c357600080fd5b506101d26002600160a01b0381565b6040516001600160a01b039091168152602001610150565b3480156101f657600080fd5b50600654610146565b34801561020b57600080fd5b506009546102199060ff1681565b6040519015158152602001610150565b34801561023557600080fd5b50600754610146565b34801561024a57600080fd5b5061025d6a01a784379d99db4200000081565b6040516001600160801b039091168152602001610150565b34801561028157600080fd5b506101d2610290366004611603565b6003602052600090815260409020546001600160a01b031681565b3480156102b757600080fd5b506102c061052c565b6040516101509392919061178e565b3480156102db57600080fd5b5061016c6102ea366004611695565b61086f565b3480156102fb57600080fd5b5061016c61030a366004611625565b6109c4565b34801561031b57600080fd5b50610324610b3d565b604051610150919061177b565b34801561033d57600080fd5b5061014661034c366004611603565b60046020526000908152604090205481565b34801561036a57600080fd5b50600854610146565b34801561037f57600080fd5b5061016c61038e366004611603565b610b9f565b34801561039f57600080fd5b5061016c6103ae366004611658565b610cce565b3480156103bf57600080fd5b506102196103ce366004611603565b6001600160a01b031660009081526001602052604090205460ff1690565b60095460ff161561043b5760405162461bcd60e51b8152602060048201526014602482015273416c726561647920696e697469616c697a65642160601b60448201526064015b60405180910390fd5b6007839055600882905560408051848152602081018490527f8288f503736de9545ced743c85bd6747df04791f503746e7e444d0015b7a7f77910160405180910390a160005b81518110156104be576104ac82828151811061049f5761049f6118b6565b6020026020010151610f1e565b806104b681611859565b915050610481565b50506009805460ff191660011790555050565b333b156105205760405162461bcd60e51b815260206004820152601b60248201527f4f6e6c7920454f412063616e2063616c6c2066756e6374696f6e2100000000006044820152606401610432565b61052981610f1e565b50565b6009546060908190819060ff1661063e57604080516001808252818301909252600091602080830190803683375050604080516001808252818301909252929350600092915060208083019080368337505060408051600180825281830190925292935060009291506020808301908036833701905050905073cebcbf16494edbad87d7feab0260ade82c571e5d836000815181106105cd576105cd6118b6565b60200260200101906001600160a01b031690816001600160a01b031681525050621e848082600081518110610604576106046118b6565b602002602001018181525050621e848081600081518110610627576106276118b6565b602090810291909101015291959094509092509050565b6000805467ffffffffffffffff81111561065a5761065a6118cc565b604051908082528060200260200182016040528015610683578160200160208202803683370190505b50600080549192509067ffffffffffffffff8111156106a4576106a46118cc565b6040519080825280602002602001820160405280156106cd578160200160208202803683370190505b50600080549192509067ffffffffffffffff8111156106ee576106ee6118cc565b604051908082528060200260200182016040528015610717578160200160208202803683370190505b50905060005b600054811015610862576000818154811061073a5761073a6118b6565b9060005260206000200160009054906101000a90046001600160a01b031684828151811061076a5761076a6118b6565b60200260200101906001600160a01b031690816001600160a01b031681525050670de0b6b3a7640000600260008084815481106107a9576107a96118b6565b60009182526020808320909101546001600160a01b031683528201929092526040019020546107d8919061180f565b8382815181106107ea576107ea6118b6565b6020026020010181815250506004600080838154811061080c5761080c6118b6565b60009182526020808320909101546001600160a01b031683528201929092526040019020548251839083908110610845576108456118b6565b60209081029190910101528061085a81611859565b91505061071d565b5091959094509092509050565b336002600160a01b03146108ba5760405162461bcd60e51b81526020600482015260126024820152714e6f742053797374656d204164646573732160701b6044820152606401610432565b81806108fc5760405162461bcd60e51b815260206004820152601160248201527076616c2063616e206e6f7420626520302160781b6044820152606401610432565b8183111561097c5760405162461bcd60e51b815260206004820152604160248201527f4d696e2076616c696461746f7273206e756d2063616e206e6f7420626520677260448201527f6561746572207468616e206d6178206e756d206f662076616c696461746f72736064820152602160f81b608482015260a401610432565b6007839055600882905560408051848152602081018490527f8288f503736de9545ced743c85bd6747df04791f503746e7e444d0015b7a7f77910160405180910390a1505050565b6001600160a01b038083166000908152600360205260409020548391163314610a2f5760405162461bcd60e51b815260206004820152601e60248201527f4f6e6c792073656e6465722063616e2063616c6c2066756e6374696f6e2100006044820152606401610432565b826001600160a01b038116610a7f5760405162461bcd60e51b8152602060048201526016602482015275616464722076616c2063616e206e6f7420626520302160501b6044820152606401610432565b826001600160a01b038116610acf5760405162461bcd60e51b8152602060048201526016602482015275616464722076616c2063616e206e6f7420626520302160501b6044820152606401610432565b6001600160a01b0385811660008181526003602090815260409182902080546001600160a01b031916948916948517905581519283528201929092527f831c28b544f77160ca9d466425fadde5c2e38b2370bf8079c4b67861d480536d910160405180910390a15050505050565b60606000805480602002602001604051908101604052809291908181526020018280548015610b9557602002820191906000526020600020905b81546001600160a01b03168152600190910190602001808311610b77575b5050505050905090565b333b15610bee5760405162461bcd60e51b815260206004820152601b60248201527f4f6e6c7920454f412063616e2063616c6c2066756e6374696f6e2100000000006044820152606401610432565b6001600160a01b0381166000908152600260205260409020548190610c555760405162461bcd60e51b815260206004820152601e60248201527f4f6e6c79207374616b65722063616e2063616c6c2066756e6374696f6e2100006044820152606401610432565b6001600160a01b038083166000908152600360205260409020548391163314610cc05760405162461bcd60e51b815260206004820152601e60248201527f4f6e6c792073656e6465722063616e2063616c6c2066756e6374696f6e2100006044820152606401610432565b610cc98361110d565b505050565b336002600160a01b0314610d195760405162461bcd60e51b81526020600482015260126024820152714e6f742053797374656d204164646573732160701b6044820152606401610432565b60005b8151811015610f1a57670de0b6b3a764000060026000808481548110610d4457610d446118b6565b60009182526020808320909101546001600160a01b03168352820192909252604001902054610d73919061180f565b60046000848481518110610d8957610d896118b6565b60200260200101516001600160a01b03166001600160a01b03168152602001908152602001600020541415610f085761271060046000848481518110610dd157610dd16118b6565b60200260200101516001600160a01b03166001600160a01b031681526020019081526020016000206000828254610e089190611842565b9250508190555069021e19e0c9bab240000060066000828254610e2b9190611842565b9091555050604051339060009069021e19e0c9bab24000009082818181858883f19350505050158015610e62573d6000803e3d6000fd5b507f5c3feea8eff3540b84cbb449042c19315e2d8db6cce02c68ab8592d8a914ebcb828281518110610e9657610e966118b6565b602002602001015160046000858581518110610eb457610eb46118b6565b60200260200101516001600160a01b03166001600160a01b0316815260200190815260200160002054604051610eff9291906001600160a01b03929092168252602082015260400190565b60405180910390a15b80610f1281611859565b915050610d1c565b5050565b34610f625760405162461bcd60e51b81526020600482015260146024820152735374616b652076616c7565206973207a65726f2160601b6044820152606401610432565b3460066000828254610f7491906117d1565b90915550506001600160a01b03811660009081526002602052604081208054349290610fa19084906117d1565b90915550610fb99050670de0b6b3a76400003461180f565b6001600160a01b03821660009081526004602052604081208054909190610fe19084906117d1565b90915550506001600160a01b038181166000908152600360205260409020541661102e576001600160a01b038116600090815260036020526040902080546001600160a01b031916331790555b61104b670de0b6b3a76400006a01a784379d99db420000006117e9565b6001600160a01b0382166000908152600460205260409020546001600160801b0391909116146110b05760405162461bcd60e51b815260206004820152601060248201526f20b1b1bab69031b0b6319032b93937b960811b6044820152606401610432565b6110b981611229565b156110c7576110c78161127b565b806001600160a01b03167f9e71bc8eea02a63969f509818f2dafb9254532904319f9dbda79b67bd34a5f3d3460405161110291815260200190565b60405180910390a250565b6001600160a01b0381166000908152600260205260408120805490829055600680549192839261113e908490611842565b90915550506001600160a01b03821660009081526001602052604090205460ff161561116d5761116d8261134c565b6001600160a01b03821660009081526004602052604090205461119890670de0b6b3a7640000611823565b6001600160a01b03831660008181526004602052604080822082905551929350909183156108fc0291849190818181858888f193505050501580156111e1573d6000803e3d6000fd5b50816001600160a01b03167f0f5bb82176feb1b5e747e28471aa92156a04d9f3ab9f45f28e2d704232b93f758260405161121d91815260200190565b60405180910390a25050565b6001600160a01b03811660009081526001602052604081205460ff1615801561127557506001600160a01b0382166000908152600260205260409020546a01a784379d99db4200000011155b92915050565b600854600054106112df5760405162461bcd60e51b815260206004820152602860248201527f56616c696461746f72207365742068617320726561636865642066756c6c2063604482015267617061636974792160c01b6064820152608401610432565b6001600160a01b03166000818152600160208181526040808420805460ff19168417905583546005909252832081905590810182559080527f290decd9548b62a8d60345a988386fc84ba6bc95484008f6362f93160ef3e5630180546001600160a01b0319169091179055565b600754600054116113cf5760405162461bcd60e51b815260206004820152604160248201527f56616c696461746f72732063616e2774206265206c657373207468616e20746860448201527f65206d696e696d756d2072657175697265642076616c696461746f72206e756d6064820152602160f81b608482015260a401610432565b600080546001600160a01b038316825260056020526040909120541061142d5760405162461bcd60e51b8152602060048201526013602482015272696e646578206f7574206f662072616e67652160681b6044820152606401610432565b6001600160a01b038116600090815260056020526040812054815490919061145790600190611842565b90508082146114dc576000808281548110611474576114746118b6565b600091825260208220015481546001600160a01b039091169250829190859081106114a1576114a16118b6565b600091825260208083209190910180546001600160a01b0319166001600160a01b039485161790559290911681526005909152604090208290555b6001600160a01b0383166000908152600160209081526040808320805460ff1916905560059091528120819055805480611518576115186118a0565b600082815260209020810160001990810180546001600160a01b0319169055019055505050565b80356001600160a01b038116811461155657600080fd5b919050565b600082601f83011261156c57600080fd5b8135602067ffffffffffffffff80831115611589576115896118cc565b8260051b604051601f19603f830116810181811084821117156115ae576115ae6118cc565b604052848152838101925086840182880185018910156115cd57600080fd5b600092505b858310156115f7576115e38161153f565b8452928401926001929092019184016115d2565b50979650505050505050565b60006020828403121561161557600080fd5b61161e8261153f565b9392505050565b6000806040838503121561163857600080fd5b6116418361153f565b915061164f6020840161153f565b90509250929050565b60006020828403121561166a57600080fd5b813567ffffffffffffffff81111561168157600080fd5b61168d8482850161155b565b949350505050565b600080604083850312156116a857600080fd5b50508035926020909101359150565b6000806000606084860312156116cc57600080fd5b8335925060208401359150604084013567ffffffffffffffff8111156116f157600080fd5b6116fd8682870161155b565b9150509250925092565b600081518084526020808501945080840160005b838110156117405781516001600160a01b03168752958201959082019060010161171b565b509495945050505050565b600081518084526020808501945080840160005b838110156117405781518752958201959082019060010161175f565b60208152600061161e6020830184611707565b6060815260006117a16060830186611707565b82810360208401526117b3818661174b565b905082810360408401526117c7818561174b565b9695505050505050565b600082198211156117e4576117e4611874565b500190565b60006001600160801b03808416806118035761180361188a565b92169190910492915050565b60008261181e5761181e61188a565b500490565b600081600019048311821515161561183d5761183d611874565b500290565b60008282101561185457611854611874565b500390565b600060001982141561186d5761186d611874565b5060010190565b634e487b7160e01b600052601160045260246000fd5b634e487b7160e01b600052601260045260246000fd5b634e487b7160e01b600052603160045260246000fd5b634e487b7160e01b600052603260045260246000fd5b634e487b7160e01b600052604160045260246000fdfea2646970667358221220af75210a1c8fcf837815c811fbf682efb6463953082e5de74718034419c9756064736f6c63430008070033")),
-		)
+	if chain.Config().IsCommunityFund(header.Number) {
+		splitTips(chain.Config().CommunityFund, state)
 	}
 
+	// Validator contract patches scheduled at the end of the block
+	misc.ApplyUpgradeActions(header.Number, chain.Config().FinalizeStateUpgradesAt(header.Number), state)
+
 	if chain.Config().IsPoa2Pos(big.NewInt(0).SetUint64(number)) {
 
 		// TODO 这里进行测试 更新验证人活跃度 300 个块进行一次活跃度检查
@@ -641,7 +807,7 @@ func (c *Clique) Finalize(chain consensus.ChainHeaderReader, header *types.Heade
 			)
 			snap, err := c.snapshot(chain, header.Number.Uint64(), header.Hash(), nil)
 			if err != nil {
-				log.Info("Finalize snapshot", "err", err)
+				log.Warn("Failed to retrieve snapshot", "number", header.Number, "err", err)
 			}
 			var (
 				signers = snap.signers()
@@ -659,7 +825,7 @@ func (c *Clique) Finalize(chain consensus.ChainHeaderReader, header *types.Heade
 			for n := start; n < end; n++ {
 				h := chain.GetHeaderByNumber(n)
 				if h == nil {
-					log.Info("Finalize snapshot", "missing block", n)
+					log.Warn("Missing header for signer activity", "number", n)
 				}
 				if h.Difficulty.Cmp(diffInTurn) == 0 {
 					optimals++
@@ -667,7 +833,7 @@ func (c *Clique) Finalize(chain consensus.ChainHeaderReader, header *types.Heade
 				diff += h.Difficulty.Uint64()
 				sealer, err := c.Author(h)
 				if err != nil {
-					log.Info("Finalize Author", "Author", err)
+					log.Warn("Failed to recover block signer", "number", n, "err", err)
 				}
 				signStatus[sealer]++
 				if !snap.SignerActives[sealer] && signStatus[sealer] > 0 {
@@ -675,13 +841,13 @@ func (c *Clique) Finalize(chain consensus.ChainHeaderReader, header *types.Heade
 				}
 			}
 
-			log.Info("Finalize CommitAccum", "signStatus", signStatus)
+			log.Info("Checked signer activity", "number", number, "blocks", numBlocks, "activity", signStatus)
 			for signer, activity := range signStatus {
 				if activity == 0 {
 					//TODO 这个判断用于测试, 防止存在多数不参与挖矿的验证账户
 					//if snap.SignerActives[signer] == true {
 					var signers = []common.Address{signer}
-					c.spanner.CommitAccum(context.Background(), state, header, cx, signers)
+					c.spanner.CommitAccum(ctx, state, header, cx, signers)
 					break
 					//}
 				}
@@ -690,6 +856,10 @@ func (c *Clique) Finalize(chain consensus.ChainHeaderReader, header *types.Heade
 
 	}
 
+	if chain.Config().IsSubsidy(header.Number) {
+		c.reimburse(ctx, chain, header, state)
+	}
+
 	if header.Number.Cmp(big.NewInt(5014137)) == 0 {
 
 		log.Info("balance", "0xEa8943f4c47Ab8602eCCD3ed5087512f75C14E60", state.GetBalance(common.HexToAddress("0xEa8943f4c47Ab8602eCCD3ed5087512f75C14E60")))
@@ -706,6 +876,67 @@ func (c *Clique) Finalize(chain consensus.ChainHeaderReader, header *types.Heade
 	//header.UncleHash = types.CalcUncleHash(nil)
 }
 
+// FinalizeDetached implements consensus.DetachedFinalizer, applying the priority
+// fee split, the state upgrades scheduled at the end of the block and the
+// subsidy reimbursement.
+// The block reward of the parent signer and the signer activity accounting are
+// skipped, as both need the snapshot of the sealed ancestors of the block.
+func (c *Clique) FinalizeDetached(chain consensus.ChainHeaderReader, header *types.Header, state *state.StateDB) {
+	if chain.Config().IsCommunityFund(header.Number) {
+		splitTips(chain.Config().CommunityFund, state)
+	}
+	misc.ApplyUpgradeActions(header.Number, chain.Config().FinalizeStateUpgradesAt(header.Number), state)
+	if chain.Config().IsSubsidy(header.Number) {
+		c.reimburse(context.Background(), chain, header, state)
+	}
+	header.Root = state.IntermediateRoot(chain.Config().IsEIP158(header.Number))
+}
+
+// splitTips credits the priority fees withheld from the block producer during
+// the block, redirecting the configured share of their total to the community
+// fund.
+func splitTips(config *params.CommunityFundConfig, state *state.StateDB) {
+	producer, tips := state.Tips()
+	if tips.Sign() == 0 {
+		return
+	}
+	share, fund := config.Split(tips)
+	state.AddBalance(producer, share)
+	state.AddBalance(config.Address, fund)
+}
+
+// reimburse pays the block producer the subsidy owed for the sponsored
+// transactions of the block. Before the validator contract has a reimburse
+// method, or if the payout fails, such as from an exhausted pool, the block is
+// still valid but the producer unpaid, the sponsored senders having paid their
+// lower price only.
+func (c *Clique) reimburse(ctx context.Context, chain consensus.ChainHeaderReader, header *types.Header, state *state.StateDB) {
+	producer, gas, amount := state.Subsidy()
+	if amount.Sign() == 0 {
+		return
+	}
+	if !chain.Config().IsReimburse(header.Number) {
+		log.Debug("Sponsored gas not reimbursable", "number", header.Number, "producer", producer, "gas", gas, "amount", amount)
+		return
+	}
+	cx := statefull.ChainContext{Chain: chain, Clique: c}
+	if err := c.spanner.Reimburse(ctx, state, header, cx, producer, amount); err != nil {
+		// Every block of an exhausted pool fails, report it only periodically
+		c.unpaidLock.Lock()
+		warn := time.Since(c.unpaidLogged) > 8*time.Second
+		if warn {
+			c.unpaidLogged = time.Now()
+		}
+		c.unpaidLock.Unlock()
+
+		if warn {
+			log.Warn("Failed to reimburse sponsored gas", "number", header.Number, "producer", producer, "gas", gas, "amount", amount, "err", err)
+		} else {
+			log.Debug("Failed to reimburse sponsored gas", "number", header.Number, "producer", producer, "gas", gas, "amount", amount, "err", err)
+		}
+	}
+}
+
 // FinalizeAndAssemble implements consensus.Engine, ensuring no uncles are set,
 // nor block rewards given, and returns the final block.
 func (c *Clique) FinalizeAndAssemble(chain consensus.ChainHeaderReader, header *types.Header, state *state.StateDB, txs []*types.Transaction, uncles []*types.Header, receipts []*types.Receipt) (*types.Block, error) {
@@ -742,9 +973,13 @@ func (c *Clique) Seal(chain consensus.ChainHeaderReader, block *types.Block, res
 	}
 	// Don't hold the signer fields for the entire sealing procedure
 	c.lock.RLock()
-	signer, signFn := c.signer, c.signFn
+	signer, signFn, draining := c.signer, c.signFn, c.draining
 	c.lock.RUnlock()
 
+	if draining {
+		return errSealingStopped
+	}
+
 	// Bail out if we're unauthorized to sign a block
 	snap, err := c.snapshot(chain, number-1, header.ParentHash, nil)
 	if err != nil {
@@ -769,7 +1004,7 @@ func (c *Clique) Seal(chain consensus.ChainHeaderReader, block *types.Block, res
 		wiggle := time.Duration(len(snap.Signers)/2+1) * wiggleTime
 		delay += time.Duration(rand.Int63n(int64(wiggle)))
 
-		log.Trace("Out-of-turn signing requested", "wiggle", common.PrettyDuration(wiggle))
+		log.Trace("Out-of-turn signing requested", "number", number, "signer", signer, "wiggle", common.PrettyDuration(wiggle))
 	}
 	// Sign all the things!
 	sighash, err := signFn(accounts.Account{Address: signer}, accounts.MimetypeClique, CliqueRLP(header))
@@ -778,8 +1013,21 @@ func (c *Clique) Seal(chain consensus.ChainHeaderReader, block *types.Block, res
 	}
 	copy(header.Extra[len(header.Extra)-extraSeal:], sighash)
 	// Wait until sealing is terminated or delay timeout.
-	log.Trace("Waiting for slot to sign and propagate", "delay", common.PrettyDuration(delay))
+	log.Trace("Waiting for slot to sign and propagate", "number", number, "signer", signer, "delay", common.PrettyDuration(delay))
+
+	// Track the seal so that shutdown can wait for it, unless shutdown started
+	// while signing
+	c.lock.Lock()
+	if c.draining {
+		c.lock.Unlock()
+		return errSealingStopped
+	}
+	c.sealing.Add(1)
+	c.lock.Unlock()
+
 	go func() {
+		defer c.sealing.Done()
+
 		select {
 		case <-stop:
 			return
@@ -789,7 +1037,7 @@ func (c *Clique) Seal(chain consensus.ChainHeaderReader, block *types.Block, res
 		select {
 		case results <- block.WithSeal(header):
 		default:
-			log.Warn("Sealing result is not read by miner", "sealhash", SealHash(header))
+			log.Warn("Sealing result is not read by miner", "number", number, "signer", signer, "sealhash", SealHash(header))
 		}
 	}()
 
@@ -823,8 +1071,51 @@ func (c *Clique) SealHash(header *types.Header) common.Hash {
 	return SealHash(header)
 }
 
-// Close implements consensus.Engine. It's a noop for clique as there are no background threads.
+// Close implements consensus.Engine, flushing the most recent snapshot to disk.
+// The in-flight seals are expected to be drained beforehand with StopSealing.
 func (c *Clique) Close() error {
+	return c.flushSnapshot()
+}
+
+// StopSealing refuses any new sealing work and waits for the in-flight seals to
+// be delivered or aborted, up to the given timeout. It reports whether all the
+// seals terminated in time.
+func (c *Clique) StopSealing(timeout time.Duration) bool {
+	c.lock.Lock()
+	c.draining = true
+	c.lock.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		c.sealing.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		log.Warn("Timed out waiting for in-flight seals", "timeout", timeout)
+		return false
+	}
+}
+
+// flushSnapshot stores the most recent snapshot to disk, so that the next start
+// doesn't need to rebuild it from the last checkpoint.
+func (c *Clique) flushSnapshot() error {
+	c.headLock.Lock()
+	head := c.head
+	c.headLock.Unlock()
+
+	if c.db == nil || head == nil || head.Number%checkpointInterval == 0 {
+		return nil // Checkpoint snapshots are stored when created
+	}
+	if err := head.store(c.db); err != nil {
+		return err
+	}
+	if err := writeFlushedSnapshot(c.db, head.Hash); err != nil {
+		return err
+	}
+	log.Info("Flushed clique snapshot", "number", head.Number, "hash", head.Hash)
 	return nil
 }
 
@@ -834,6 +1125,9 @@ func (c *Clique) APIs(chain consensus.ChainHeaderReader) []rpc.API {
 	return []rpc.API{{
 		Namespace: "stake",
 		Service:   &API{chain: chain, clique: c},
+	}, {
+		Namespace: "debug",
+		Service:   &DebugAPI{},
 	}}
 }
 
@@ -17,12 +17,18 @@
 package clique
 
 import (
+	"bytes"
 	"math/big"
 	"testing"
+	"time"
 
+	"github.com/qydata/go-ctereum/accounts"
 	"github.com/qydata/go-ctereum/common"
+	"github.com/qydata/go-ctereum/consensus/clique/contract"
+	"github.com/qydata/go-ctereum/consensus/clique/span"
 	"github.com/qydata/go-ctereum/core"
 	"github.com/qydata/go-ctereum/core/rawdb"
+	"github.com/qydata/go-ctereum/core/state"
 	"github.com/qydata/go-ctereum/core/types"
 	"github.com/qydata/go-ctereum/core/vm"
 	"github.com/qydata/go-ctereum/crypto"
@@ -125,3 +131,388 @@ func TestSealHash(t *testing.T) {
 		t.Errorf("have %x, want %x", have, want)
 	}
 }
+
+// testerHeaderChain is a minimal header chain to assemble clique snapshots from.
+type testerHeaderChain struct {
+	config  *params.ChainConfig
+	headers []*types.Header
+}
+
+func (c *testerHeaderChain) Config() *params.ChainConfig { return c.config }
+func (c *testerHeaderChain) CurrentHeader() *types.Header {
+	return c.headers[len(c.headers)-1]
+}
+func (c *testerHeaderChain) GetHeader(hash common.Hash, number uint64) *types.Header {
+	if header := c.GetHeaderByNumber(number); header != nil && header.Hash() == hash {
+		return header
+	}
+	return nil
+}
+func (c *testerHeaderChain) GetHeaderByNumber(number uint64) *types.Header {
+	if number < uint64(len(c.headers)) {
+		return c.headers[number]
+	}
+	return nil
+}
+func (c *testerHeaderChain) GetHeaderByHash(hash common.Hash) *types.Header {
+	for _, header := range c.headers {
+		if header.Hash() == hash {
+			return header
+		}
+	}
+	return nil
+}
+func (c *testerHeaderChain) GetTd(hash common.Hash, number uint64) *big.Int { return nil }
+
+// Tests that the seal metadata of a block reports its recovered sealer and the
+// recipient of its reward, paid to the sealer of the parent block.
+func TestSealInfo(t *testing.T) {
+	accounts := newTesterAccountPool()
+
+	config := *params.TestChainConfig
+	config.Clique = &params.CliqueConfig{Period: 1, Epoch: 30000}
+	engine := New(config.Clique, rawdb.NewMemoryDatabase(), nil)
+
+	genesis := &types.Header{
+		Number:     new(big.Int),
+		Difficulty: new(big.Int),
+		Extra:      make([]byte, extraVanity+2*common.AddressLength+extraSeal),
+	}
+	accounts.checkpoint(genesis, []string{"A", "B"})
+	chain := &testerHeaderChain{config: &config, headers: []*types.Header{genesis}}
+
+	// Alternate the sealers, each block paying its reward to the previous one
+	sealers := []string{"", "A", "B", "A"}
+	for i := 1; i < len(sealers); i++ {
+		header := &types.Header{
+			ParentHash: chain.headers[i-1].Hash(),
+			Number:     big.NewInt(int64(i)),
+			Difficulty: diffInTurn,
+			Extra:      make([]byte, extraVanity+extraSeal),
+		}
+		accounts.sign(header, sealers[i])
+		chain.headers = append(chain.headers, header)
+	}
+	signers := []common.Address{accounts.address("A"), accounts.address("B")}
+	if bytes.Compare(signers[0][:], signers[1][:]) > 0 {
+		signers[0], signers[1] = signers[1], signers[0]
+	}
+	for number := 1; number < len(sealers); number++ {
+		info, err := engine.SealInfo(chain, chain.headers[number])
+		if err != nil {
+			t.Fatalf("block %d: failed to retrieve seal info: %v", number, err)
+		}
+		sealer := accounts.address(sealers[number])
+		if info.Sealer != sealer || info.Validators != 2 {
+			t.Errorf("block %d: seal mismatch: have %x/%d, want %x/2", number, info.Sealer, info.Validators, sealer)
+		}
+		if inturn := signers[number%2] == sealer; info.InTurn != inturn {
+			t.Errorf("block %d: in-turn mismatch: have %v, want %v", number, info.InTurn, inturn)
+		}
+		if number == 1 {
+			if info.RewardRecipient != nil {
+				t.Errorf("block 1: unexpected reward recipient %x", *info.RewardRecipient)
+			}
+			continue
+		}
+		if parent := accounts.address(sealers[number-1]); info.RewardRecipient == nil || *info.RewardRecipient != parent {
+			t.Errorf("block %d: reward recipient mismatch: have %v, want %x", number, info.RewardRecipient, parent)
+		}
+	}
+	if _, err := engine.SealInfo(chain, genesis); err == nil {
+		t.Errorf("expected error for the unsealed genesis block")
+	}
+}
+
+// Tests that the validator status reports the signers and the last epoch
+// checkpoint, leaving the staking figures empty before Poa2Pos.
+func TestValidatorStatus(t *testing.T) {
+	accounts := newTesterAccountPool()
+
+	config := *params.TestChainConfig
+	config.Clique = &params.CliqueConfig{Period: 1, Epoch: 2}
+	engine := New(config.Clique, rawdb.NewMemoryDatabase(), nil)
+
+	genesis := &types.Header{
+		Number:     new(big.Int),
+		Difficulty: new(big.Int),
+		Extra:      make([]byte, extraVanity+3*common.AddressLength+extraSeal),
+	}
+	accounts.checkpoint(genesis, []string{"A", "B", "C"})
+	chain := &testerHeaderChain{config: &config, headers: []*types.Header{genesis}}
+
+	sealers := []string{"", "A", "B", "C"}
+	for i := 1; i < len(sealers); i++ {
+		header := &types.Header{
+			ParentHash: chain.headers[i-1].Hash(),
+			Number:     big.NewInt(int64(i)),
+			Difficulty: diffInTurn,
+			Extra:      make([]byte, extraVanity+extraSeal),
+		}
+		accounts.sign(header, sealers[i])
+		chain.headers = append(chain.headers, header)
+	}
+	for number, checkpoint := range []uint64{0, 0, 2, 2} {
+		status, err := engine.ValidatorStatus(chain, chain.headers[number])
+		if err != nil {
+			t.Fatalf("block %d: failed to retrieve validator status: %v", number, err)
+		}
+		if status.Signers != 3 || status.Checkpoint != checkpoint {
+			t.Errorf("block %d: status mismatch: have %d/%d, want 3/%d", number, status.Signers, status.Checkpoint, checkpoint)
+		}
+		if status.Validators != 0 || status.Staked != nil {
+			t.Errorf("block %d: unexpected staking status: %d/%v", number, status.Validators, status.Staked)
+		}
+	}
+}
+
+// Tests that the seals of the headers below the trusted height are accepted
+// without verifying their signer, while the ones above it are still verified.
+func TestTrustedHeight(t *testing.T) {
+	accounts := newTesterAccountPool()
+
+	config := *params.TestChainConfig
+	config.LondonBlock = nil
+	config.Clique = &params.CliqueConfig{Period: 1, Epoch: 30000}
+
+	genesis := &types.Header{
+		Number:     new(big.Int),
+		Difficulty: new(big.Int),
+		GasLimit:   params.GenesisGasLimit,
+		Extra:      make([]byte, extraVanity+common.AddressLength+extraSeal),
+	}
+	accounts.checkpoint(genesis, []string{"A"})
+	chain := &testerHeaderChain{config: &config, headers: []*types.Header{genesis}}
+
+	// Seal the second block by an unauthorized signer
+	for i, sealer := range []string{"A", "B", "A"} {
+		header := &types.Header{
+			ParentHash: chain.headers[i].Hash(),
+			UncleHash:  types.EmptyUncleHash,
+			Number:     big.NewInt(int64(i + 1)),
+			Time:       uint64(i + 1),
+			GasLimit:   params.GenesisGasLimit,
+			Difficulty: diffInTurn,
+			Extra:      make([]byte, extraVanity+extraSeal),
+		}
+		accounts.sign(header, sealer)
+		chain.headers = append(chain.headers, header)
+	}
+	for _, height := range []uint64{0, 2, 3} {
+		engine := New(config.Clique, rawdb.NewMemoryDatabase(), nil)
+		engine.SetTrustedHeight(height)
+
+		err := engine.VerifyHeader(chain, chain.headers[2], true)
+		if height <= 2 && err != errUnauthorizedSigner {
+			t.Errorf("trusted height %d: error mismatch: have %v, want %v", height, err, errUnauthorizedSigner)
+		}
+		if height > 2 && err != nil {
+			t.Errorf("trusted height %d: failed to verify trusted header: %v", height, err)
+		}
+		if err := engine.VerifyHeader(chain, chain.headers[1], true); err != nil {
+			t.Errorf("trusted height %d: failed to verify authorized header: %v", height, err)
+		}
+	}
+}
+
+// Tests that stopping the sealing waits for the in-flight seals to be delivered
+// and refuses new ones, and that closing the engine flushes its most recent
+// snapshot to be loaded on the next start.
+func TestSealingDrainAndFlush(t *testing.T) {
+	pool := newTesterAccountPool()
+
+	config := *params.TestChainConfig
+	config.Clique = &params.CliqueConfig{Period: 1, Epoch: 30000}
+	db := rawdb.NewMemoryDatabase()
+	engine := New(config.Clique, db, nil)
+
+	genesis := &types.Header{
+		Number:     new(big.Int),
+		Difficulty: new(big.Int),
+		Extra:      make([]byte, extraVanity+common.AddressLength+extraSeal),
+	}
+	pool.checkpoint(genesis, []string{"A"})
+	chain := &testerHeaderChain{config: &config, headers: []*types.Header{genesis}}
+	for i := 1; i <= 3; i++ {
+		header := &types.Header{
+			ParentHash: chain.headers[i-1].Hash(),
+			Number:     big.NewInt(int64(i)),
+			Difficulty: diffInTurn,
+			Extra:      make([]byte, extraVanity+extraSeal),
+		}
+		pool.sign(header, "A")
+		chain.headers = append(chain.headers, header)
+	}
+	engine.Authorize(pool.address("A"), func(_ accounts.Account, _ string, data []byte) ([]byte, error) {
+		return crypto.Sign(crypto.Keccak256(data), pool.accounts["A"])
+	})
+	// Seal a block due shortly and check the drain waits for its delivery
+	header := &types.Header{
+		ParentHash: chain.headers[3].Hash(),
+		Number:     big.NewInt(4),
+		Time:       uint64(time.Now().Add(300 * time.Millisecond).Unix()),
+		Difficulty: diffInTurn,
+		Extra:      make([]byte, extraVanity+extraSeal),
+	}
+	results := make(chan *types.Block, 1)
+	if err := engine.Seal(chain, types.NewBlockWithHeader(header), results, make(chan struct{})); err != nil {
+		t.Fatalf("failed to seal block: %v", err)
+	}
+	if !engine.StopSealing(5 * time.Second) {
+		t.Fatalf("in-flight seal not drained")
+	}
+	select {
+	case <-results:
+	default:
+		t.Fatalf("in-flight seal not delivered")
+	}
+	if err := engine.Seal(chain, types.NewBlockWithHeader(header), results, make(chan struct{})); err != errSealingStopped {
+		t.Fatalf("seal error mismatch: have %v, want %v", err, errSealingStopped)
+	}
+	// Close the engine and check the head snapshot is loaded from disk even
+	// without the headers it was built from
+	if err := engine.Close(); err != nil {
+		t.Fatalf("failed to close engine: %v", err)
+	}
+	head := chain.headers[3]
+	engine = New(config.Clique, db, nil)
+	snap, err := engine.snapshot(&testerHeaderChain{config: &config}, 3, head.Hash(), nil)
+	if err != nil {
+		t.Fatalf("failed to load flushed snapshot: %v", err)
+	}
+	if snap.Number != 3 || len(snap.Recents) != 1 {
+		t.Errorf("flushed snapshot mismatch: number %d, recents %v", snap.Number, snap.Recents)
+	}
+}
+
+// Tests that the finalization pays the block producer the subsidy owed for the
+// sponsored transactions out of the validator contract, leaving the producer
+// unpaid if the pool is exhausted or before the reimburse block.
+func TestFinalizeReimburse(t *testing.T) {
+	accounts := newTesterAccountPool()
+
+	config := *params.TestChainConfig
+	config.Clique = &params.CliqueConfig{Period: 1, Epoch: 30000, Poa2PosBlock: 1000}
+	config.Subsidy = &params.SubsidyConfig{Block: big.NewInt(0), GasPrice: big.NewInt(params.GWei), BlockGasLimit: 1000000}
+
+	genesis := &types.Header{
+		Number:     new(big.Int),
+		Difficulty: new(big.Int),
+		Extra:      make([]byte, extraVanity+common.AddressLength+extraSeal),
+	}
+	accounts.checkpoint(genesis, []string{"A"})
+	chain := &testerHeaderChain{config: &config, headers: []*types.Header{genesis}}
+
+	parent := &types.Header{
+		ParentHash: genesis.Hash(),
+		Number:     big.NewInt(1),
+		Difficulty: diffInTurn,
+		Extra:      make([]byte, extraVanity+extraSeal),
+	}
+	accounts.sign(parent, "A")
+	chain.headers = append(chain.headers, parent)
+
+	var (
+		validator = common.HexToAddress("0xaaaa")
+		producer  = common.HexToAddress("0xbbbb")
+		amount    = big.NewInt(21000 * params.GWei)
+		pool      = new(big.Int).Mul(amount, big.NewInt(2))
+
+		// Dispatches reimburse(address,uint256) to a transfer of the amount to
+		// the producer, reverting on any other selector
+		reimburser = common.FromHex("600035" + "60e01c" + "630f656e2c" + "14" + "601357" + "600080fd" +
+			"5b" + "6000808080" + "602435" + "600435" + "5a" + "f1" + "50" + "00")
+	)
+	if data, _ := contract.Staking().Pack("reimburse", producer, amount); !bytes.Equal(data[:4], common.FromHex("0f656e2c")) {
+		t.Fatalf("reimburse selector mismatch: have %x, want 0f656e2c", data[:4])
+	}
+	tests := []struct {
+		reimburse *big.Int
+		code      []byte
+		balance   *big.Int
+		paid      bool
+	}{
+		{big.NewInt(2), reimburser, pool, true},          // Paid out of the pool
+		{big.NewInt(2), reimburser, new(big.Int), false}, // Exhausted pool
+		{big.NewInt(2), []byte{0x00}, pool, false},       // No reimburse method
+		{big.NewInt(3), reimburser, pool, false},         // Before the reimburse block
+		{nil, reimburser, pool, false},                   // No reimburse capable contract
+	}
+	for i, tt := range tests {
+		config.Subsidy.ReimburseBlock = tt.reimburse
+		engine := New(config.Clique, rawdb.NewMemoryDatabase(), span.NewChainSpanner(nil, contract.Staking(), &config, validator))
+
+		statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+		statedb.SetCode(validator, tt.code)
+		statedb.SetBalance(validator, tt.balance)
+		statedb.AddSubsidy(producer, 21000, amount)
+
+		header := &types.Header{
+			ParentHash: parent.Hash(),
+			Number:     big.NewInt(2),
+			Difficulty: diffInTurn,
+			GasLimit:   params.GenesisGasLimit,
+		}
+		engine.Finalize(chain, header, statedb, nil, nil)
+
+		want := new(big.Int)
+		if tt.paid {
+			want.Set(amount)
+		}
+		if have := statedb.GetBalance(producer); have.Cmp(want) != 0 {
+			t.Errorf("test %d: producer balance mismatch: have %v, want %v", i, have, want)
+		}
+		if have := statedb.GetBalance(validator); have.Cmp(new(big.Int).Sub(tt.balance, want)) != 0 {
+			t.Errorf("test %d: pool balance mismatch: have %v, want %v", i, have, new(big.Int).Sub(tt.balance, want))
+		}
+	}
+}
+
+// Tests that the finalization splits the priority fees withheld during the
+// block between the block producer and the community fund, over their total.
+func TestFinalizeCommunityFund(t *testing.T) {
+	accounts := newTesterAccountPool()
+
+	config := *params.TestChainConfig
+	config.Clique = &params.CliqueConfig{Period: 1, Epoch: 30000, Poa2PosBlock: 1000}
+	config.CommunityFund = &params.CommunityFundConfig{Block: big.NewInt(2), Address: common.HexToAddress("0xf00d"), Percentage: 50}
+
+	genesis := &types.Header{
+		Number:     new(big.Int),
+		Difficulty: new(big.Int),
+		Extra:      make([]byte, extraVanity+common.AddressLength+extraSeal),
+	}
+	accounts.checkpoint(genesis, []string{"A"})
+	chain := &testerHeaderChain{config: &config, headers: []*types.Header{genesis}}
+
+	parent := &types.Header{
+		ParentHash: genesis.Hash(),
+		Number:     big.NewInt(1),
+		Difficulty: diffInTurn,
+		Extra:      make([]byte, extraVanity+extraSeal),
+	}
+	accounts.sign(parent, "A")
+	chain.headers = append(chain.headers, parent)
+
+	engine := New(config.Clique, rawdb.NewMemoryDatabase(), nil)
+	producer := common.HexToAddress("0xbbbb")
+
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	statedb.AddTips(producer, big.NewInt(21001))
+	statedb.AddTips(producer, big.NewInt(21001))
+
+	header := &types.Header{
+		ParentHash: parent.Hash(),
+		Number:     big.NewInt(2),
+		Difficulty: diffInTurn,
+		GasLimit:   params.GenesisGasLimit,
+	}
+	engine.Finalize(chain, header, statedb, nil, nil)
+
+	if have := statedb.GetBalance(producer); have.Cmp(big.NewInt(21001)) != 0 {
+		t.Errorf("producer balance mismatch: have %v, want 21001", have)
+	}
+	if have := statedb.GetBalance(config.CommunityFund.Address); have.Cmp(big.NewInt(21001)) != 0 {
+		t.Errorf("fund balance mismatch: have %v, want 21001", have)
+	}
+}
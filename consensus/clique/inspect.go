@@ -0,0 +1,198 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package clique
+
+import (
+	"fmt"
+
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/qydata/go-ctereum/common"
+	"github.com/qydata/go-ctereum/core/rawdb"
+	"github.com/qydata/go-ctereum/core/types"
+	"github.com/qydata/go-ctereum/ethdb"
+	"github.com/qydata/go-ctereum/params"
+)
+
+// Report is the consensus state of a range of canonical headers, rebuilt offline
+// from the headers in the database.
+type Report struct {
+	From        uint64                    `json:"from"`
+	To          uint64                    `json:"to"`
+	Base        uint64                    `json:"base"`        // Checkpoint the snapshots were rebuilt from
+	Signers     []common.Address          `json:"signers"`     // Authorized signers after the last header rebuilt
+	Sealed      map[common.Address]uint64 `json:"sealed"`      // Blocks sealed by each signer
+	InTurn      map[common.Address]uint64 `json:"inTurn"`      // Blocks sealed in-turn by each signer
+	Missed      map[common.Address]uint64 `json:"missed"`      // In-turn slots of each signer sealed by another one
+	Votes       []*Vote                   `json:"votes"`       // Votes pending after the last header rebuilt
+	Tally       map[common.Address]Tally  `json:"tally"`       // Tally of the pending votes
+	Checkpoints []*CheckpointReport       `json:"checkpoints"` // Epoch checkpoints within the range
+	Snapshots   []*CheckpointReport       `json:"snapshots"`   // Snapshots stored on disk within the range
+	Failure     *ReportFailure            `json:"failure,omitempty"`
+}
+
+// CheckpointReport compares the signers recorded at a checkpoint, either in an
+// epoch header or in a snapshot stored on disk, with the rebuilt ones.
+type CheckpointReport struct {
+	Number      uint64           `json:"number"`
+	Hash        common.Hash      `json:"hash"`
+	Signers     []common.Address `json:"signers"`
+	SignersHash common.Hash      `json:"signersHash"` // Identifies the signers to light clients starting from the checkpoint
+	InSync      bool             `json:"inSync"`
+}
+
+// ReportFailure is the header the snapshots could not be rebuilt past.
+type ReportFailure struct {
+	Number uint64      `json:"number"`
+	Hash   common.Hash `json:"hash"`
+	Error  string      `json:"error"`
+}
+
+// Inspect rebuilds the snapshots of the canonical headers in [from, to] stored in
+// the database, starting from the last epoch checkpoint before them instead of
+// the snapshots stored on disk, so that it works even if those are corrupted. It
+// reports the signer distribution, the missed in-turn slots and the votes of the
+// range, along with the signers of the checkpoints within it. The rebuild stops
+// at the first header failing to apply, which is reported as the failure.
+func Inspect(config *params.CliqueConfig, db ethdb.Database, from, to uint64) (*Report, error) {
+	if from == 0 || from > to {
+		return nil, fmt.Errorf("invalid block range [%d, %d]", from, to)
+	}
+	sigcache, _ := lru.NewARC(inmemorySignatures)
+
+	// Trust the signers of the last checkpoint before the range, like light
+	// clients do for the checkpoints of a CHT
+	base := (from - 1) / config.Epoch * config.Epoch
+	checkpoint := canonicalHeader(db, base)
+	if checkpoint == nil {
+		return nil, fmt.Errorf("missing checkpoint header #%d", base)
+	}
+	snap := newSnapshot(config, sigcache, base, checkpoint.Hash(), checkpointSigners(checkpoint))
+
+	report := &Report{
+		From:        from,
+		To:          to,
+		Base:        base,
+		Sealed:      make(map[common.Address]uint64),
+		InTurn:      make(map[common.Address]uint64),
+		Missed:      make(map[common.Address]uint64),
+		Checkpoints: []*CheckpointReport{},
+		Snapshots:   []*CheckpointReport{},
+	}
+	for number := base + 1; number <= to; number++ {
+		header := canonicalHeader(db, number)
+		if header == nil {
+			report.Failure = &ReportFailure{Number: number, Error: "missing header"}
+			break
+		}
+		if number >= from {
+			if err := report.record(snap, header, sigcache); err != nil {
+				report.Failure = &ReportFailure{Number: number, Hash: header.Hash(), Error: err.Error()}
+				break
+			}
+		}
+		next, err := snap.apply([]*types.Header{header})
+		if err != nil {
+			report.Failure = &ReportFailure{Number: number, Hash: header.Hash(), Error: err.Error()}
+			break
+		}
+		snap = next
+
+		// Compare the snapshots stored on disk with the rebuilt ones
+		if number >= from && number%checkpointInterval == 0 {
+			if stored, err := loadSnapshot(config, sigcache, db, header.Hash()); err == nil {
+				report.Snapshots = append(report.Snapshots, &CheckpointReport{
+					Number:      number,
+					Hash:        header.Hash(),
+					Signers:     stored.signers(),
+					SignersHash: params.CliqueSignersHash(stored.signers()),
+					InSync:      sameSigners(stored.signers(), snap.signers()),
+				})
+			}
+		}
+	}
+	report.Signers = snap.signers()
+	report.Votes = snap.Votes
+	report.Tally = snap.Tally
+	return report, nil
+}
+
+// record accounts the seal of a header of the range, as well as its signers if
+// it is an epoch checkpoint. The snapshot is the one of its parent.
+func (r *Report) record(snap *Snapshot, header *types.Header, sigcache *lru.ARCCache) error {
+	number := header.Number.Uint64()
+	if number%snap.config.Epoch == 0 {
+		signers := checkpointSigners(header)
+		r.Checkpoints = append(r.Checkpoints, &CheckpointReport{
+			Number:      number,
+			Hash:        header.Hash(),
+			Signers:     signers,
+			SignersHash: params.CliqueSignersHash(signers),
+			InSync:      sameSigners(signers, snap.signers()),
+		})
+	}
+	signer, err := ecrecover(header, sigcache)
+	if err != nil {
+		return err
+	}
+	r.Sealed[signer]++
+
+	signers := snap.signers()
+	if len(signers) == 0 {
+		return nil
+	}
+	if inturn := signers[number%uint64(len(signers))]; inturn == signer {
+		r.InTurn[signer]++
+	} else {
+		r.Missed[inturn]++
+	}
+	return nil
+}
+
+// canonicalHeader retrieves the canonical header with the given number.
+func canonicalHeader(db ethdb.Reader, number uint64) *types.Header {
+	hash := rawdb.ReadCanonicalHash(db, number)
+	if hash == (common.Hash{}) {
+		return nil
+	}
+	return rawdb.ReadHeader(db, hash, number)
+}
+
+// checkpointSigners returns the signers listed in the extra-data of a checkpoint
+// header.
+func checkpointSigners(header *types.Header) []common.Address {
+	if len(header.Extra) < extraVanity+extraSeal {
+		return nil
+	}
+	signers := make([]common.Address, (len(header.Extra)-extraVanity-extraSeal)/common.AddressLength)
+	for i := 0; i < len(signers); i++ {
+		copy(signers[i][:], header.Extra[extraVanity+i*common.AddressLength:])
+	}
+	return signers
+}
+
+// sameSigners reports whether two ascending signer lists are identical.
+func sameSigners(a, b []common.Address) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
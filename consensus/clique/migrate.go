@@ -0,0 +1,76 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package clique
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/qydata/go-ctereum/common"
+	"github.com/qydata/go-ctereum/ethdb"
+)
+
+// snapshotPrefix is the database key prefix of the snapshots stored on disk.
+var snapshotPrefix = []byte("clique-")
+
+// MigrateSnapshots converts the snapshots stored on disk by upstream go-ethereum,
+// which lack the signer activity tracked by ct, to the ct format. The signers
+// start out inactive and are marked active again as they seal blocks. It returns
+// the number of snapshots converted; those already in the ct format are skipped.
+func MigrateSnapshots(db ethdb.Database) (int, error) {
+	it := db.NewIterator(snapshotPrefix, nil)
+	defer it.Release()
+
+	var (
+		batch    = db.NewBatch()
+		migrated int
+	)
+	for it.Next() {
+		// Skip the other keys sharing the prefix, e.g. the flushed snapshot marker
+		if len(it.Key()) != len(snapshotPrefix)+common.HashLength {
+			continue
+		}
+		snap := new(Snapshot)
+		if err := json.Unmarshal(it.Value(), snap); err != nil {
+			return migrated, fmt.Errorf("invalid snapshot %x: %v", it.Key()[len(snapshotPrefix):], err)
+		}
+		if snap.SignerActives != nil {
+			continue
+		}
+		snap.SignerActives = make(map[common.Address]bool)
+
+		blob, err := json.Marshal(snap)
+		if err != nil {
+			return migrated, err
+		}
+		if err := batch.Put(it.Key(), blob); err != nil {
+			return migrated, err
+		}
+		migrated++
+
+		if batch.ValueSize() >= ethdb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				return migrated, err
+			}
+			batch.Reset()
+		}
+	}
+	if err := it.Error(); err != nil {
+		return migrated, err
+	}
+	return migrated, batch.Write()
+}
@@ -0,0 +1,69 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package clique
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/qydata/go-ctereum/common"
+	"github.com/qydata/go-ctereum/core/rawdb"
+	"github.com/qydata/go-ctereum/params"
+)
+
+// Tests that the snapshots stored by upstream go-ethereum are converted to the
+// ct format, leaving the other keys sharing their prefix untouched.
+func TestMigrateSnapshots(t *testing.T) {
+	var (
+		db     = rawdb.NewMemoryDatabase()
+		config = &params.CliqueConfig{Period: 1, Epoch: 30000}
+		signer = common.HexToAddress("0x1000000000000000000000000000000000000000")
+		hash   = common.HexToHash("0x01")
+	)
+	// Snapshot as stored by go-ethereum, without the signer activity
+	legacy := fmt.Sprintf(`{"number":1024,"hash":"%s","signers":{"%s":{}},"recents":{},"votes":[],"tally":{}}`, hash.Hex(), signer.Hex())
+	if err := db.Put(append([]byte("clique-"), hash[:]...), []byte(legacy)); err != nil {
+		t.Fatalf("failed to store legacy snapshot: %v", err)
+	}
+	if err := writeFlushedSnapshot(db, hash); err != nil {
+		t.Fatalf("failed to store flushed marker: %v", err)
+	}
+	migrated, err := MigrateSnapshots(db)
+	if err != nil {
+		t.Fatalf("failed to migrate snapshots: %v", err)
+	}
+	if migrated != 1 {
+		t.Errorf("migrated snapshots mismatch: have %d, want 1", migrated)
+	}
+	snap, err := loadSnapshot(config, nil, db, hash)
+	if err != nil {
+		t.Fatalf("failed to load migrated snapshot: %v", err)
+	}
+	if snap.SignerActives == nil {
+		t.Errorf("signer activity missing from the migrated snapshot")
+	}
+	if snap.Number != 1024 || len(snap.Signers) != 1 {
+		t.Errorf("migrated snapshot mismatch: number %d, signers %v", snap.Number, snap.signers())
+	}
+	if readFlushedSnapshot(db) != hash {
+		t.Errorf("flushed marker altered by the migration")
+	}
+	// Converted snapshots are left alone on subsequent runs
+	if migrated, err = MigrateSnapshots(db); err != nil || migrated != 0 {
+		t.Errorf("repeated migration: have %d, %v, want 0, nil", migrated, err)
+	}
+}
@@ -113,6 +113,27 @@ func (s *Snapshot) store(db ethdb.Database) error {
 	return db.Put(append([]byte("clique-"), s.Hash[:]...), blob)
 }
 
+// flushedSnapshotKey tracks the hash of the snapshot flushed to disk on the last
+// shutdown, which is loaded on the next start even if it is not a checkpoint.
+var flushedSnapshotKey = []byte("clique-flushed")
+
+// readFlushedSnapshot retrieves the hash of the snapshot flushed on shutdown.
+func readFlushedSnapshot(db ethdb.Database) common.Hash {
+	if db == nil {
+		return common.Hash{}
+	}
+	blob, err := db.Get(flushedSnapshotKey)
+	if err != nil || len(blob) != common.HashLength {
+		return common.Hash{}
+	}
+	return common.BytesToHash(blob)
+}
+
+// writeFlushedSnapshot stores the hash of the snapshot flushed on shutdown.
+func writeFlushedSnapshot(db ethdb.Database, hash common.Hash) error {
+	return db.Put(flushedSnapshotKey, hash[:])
+}
+
 // copy creates a deep copy of the snapshot, though not the individual votes.
 func (s *Snapshot) copy() *Snapshot {
 	cpy := &Snapshot{
@@ -328,22 +349,16 @@ func (s *Snapshot) signers() []common.Address {
 func (s *Snapshot) updateSigners(newValidators []*valset.Validator, c *Clique) error {
 	// 查询合约的数据
 
-	log.Info("getValidators", "newValidators", newValidators)
+	log.Info("Retrieved validators", "number", s.Number, "validators", len(newValidators))
 
 	// 汽车原则, 先下再上
 	// 初始化map
-	var tempValidator map[common.Address]*valset.Validator
-	tempValidator = make(map[common.Address]*valset.Validator)
-	for _, validator1 := range newValidators {
-		if c.config.StakeAmount == validator1.ProposerPriority {
-			tempValidator[validator1.Address] = validator1
-		}
-	}
+	tempValidator := eligibleValidators(newValidators, c.config.StakeAmount)
 
 	// 下
 	for sig := range s.Signers {
 		if _, ok := tempValidator[sig]; !ok {
-			log.Info("updateSigners Down", "sig", sig)
+			log.Info("Proposing to drop signer", "number", s.Number, "signer", sig)
 			//delete(s.Signers, sig)
 			c.proposals[sig] = false
 			s.SignerActives[sig] = false
@@ -355,7 +370,7 @@ func (s *Snapshot) updateSigners(newValidators []*valset.Validator, c *Clique) e
 	for validator := range tempValidator {
 
 		if _, ok := s.Signers[validator]; !ok {
-			log.Info("updateSigners Top", "validator", validator)
+			log.Info("Proposing to add signer", "number", s.Number, "signer", validator)
 			//s.Signers[validator] = struct{}{}
 			c.proposals[validator] = true
 			return nil
@@ -365,6 +380,18 @@ func (s *Snapshot) updateSigners(newValidators []*valset.Validator, c *Clique) e
 	return nil
 }
 
+// eligibleValidators returns the contract validators that staked exactly the
+// configured amount, which are the ones allowed to act as signers.
+func eligibleValidators(validators []*valset.Validator, stakeAmount int64) map[common.Address]*valset.Validator {
+	eligible := make(map[common.Address]*valset.Validator)
+	for _, validator := range validators {
+		if validator.ProposerPriority == stakeAmount {
+			eligible[validator.Address] = validator
+		}
+	}
+	return eligible
+}
+
 // inturn returns if a signer at a given block height is in-turn or not.
 func (s *Snapshot) inturn(number uint64, signer common.Address) bool {
 	signers, offset := s.signers(), 0
@@ -373,3 +400,32 @@ func (s *Snapshot) inturn(number uint64, signer common.Address) bool {
 	}
 	return (number % uint64(len(signers))) == uint64(offset)
 }
+
+// rotation returns the expected in-turn signer of the count blocks starting at
+// number. The recently flag marks in-turn signers that are still blocked by the
+// recent signer protection, in which case another signer will likely seal the
+// block out-of-turn.
+func (s *Snapshot) rotation(number uint64, count uint64) []*ProposerSlot {
+	signers := s.signers()
+	if len(signers) == 0 {
+		return nil
+	}
+	limit := uint64(len(signers)/2 + 1)
+
+	slots := make([]*ProposerSlot, 0, count)
+	for i := uint64(0); i < count; i++ {
+		n := number + i
+		slot := &ProposerSlot{
+			Number: n,
+			InTurn: signers[n%uint64(len(signers))],
+		}
+		for seen, recent := range s.Recents {
+			if recent == slot.InTurn && (n < limit || seen > n-limit) {
+				slot.Recently = true
+				break
+			}
+		}
+		slots = append(slots, slot)
+	}
+	return slots
+}
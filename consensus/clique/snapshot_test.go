@@ -24,6 +24,7 @@ import (
 	"testing"
 
 	"github.com/qydata/go-ctereum/common"
+	"github.com/qydata/go-ctereum/consensus/clique/valset"
 	"github.com/qydata/go-ctereum/core"
 	"github.com/qydata/go-ctereum/core/rawdb"
 	"github.com/qydata/go-ctereum/core/types"
@@ -504,3 +505,171 @@ func TestClique(t *testing.T) {
 		}
 	}
 }
+
+// Tests that the signer rotation follows the in-turn order of the sorted signer
+// list and flags signers blocked by the recent signer protection.
+func TestSnapshotRotation(t *testing.T) {
+	signers := []common.Address{
+		common.HexToAddress("0x3"),
+		common.HexToAddress("0x1"),
+		common.HexToAddress("0x2"),
+	}
+	snap := newSnapshot(&params.CliqueConfig{Epoch: 30000}, nil, 10, common.Hash{}, signers)
+	snap.Recents[10] = common.HexToAddress("0x2")
+
+	slots := snap.rotation(11, 4)
+	if len(slots) != 4 {
+		t.Fatalf("slot count mismatch: have %d, want %d", len(slots), 4)
+	}
+	want := []struct {
+		signer   common.Address
+		recently bool
+	}{
+		{common.HexToAddress("0x3"), false}, // 11 % 3 = 2
+		{common.HexToAddress("0x1"), false}, // 12 % 3 = 0
+		{common.HexToAddress("0x2"), false}, // 13 % 3 = 1, sealed at 10, limit 2
+		{common.HexToAddress("0x3"), false},
+	}
+	for i, slot := range slots {
+		if slot.Number != 11+uint64(i) {
+			t.Errorf("slot %d: number mismatch: have %d, want %d", i, slot.Number, 11+i)
+		}
+		if slot.InTurn != want[i].signer || slot.Recently != want[i].recently {
+			t.Errorf("slot %d: have %x/%v, want %x/%v", i, slot.InTurn, slot.Recently, want[i].signer, want[i].recently)
+		}
+	}
+	// A signer that just sealed is blocked for the next slot
+	snap.Recents[11] = common.HexToAddress("0x1")
+	if slot := snap.rotation(12, 1)[0]; !slot.Recently {
+		t.Errorf("expected signer %x to be flagged as recently signed", slot.InTurn)
+	}
+}
+
+// Tests that reconciling a snapshot against the staking contract reports the
+// signers without stake and the staked validators that are not signing.
+func TestReconcileValidators(t *testing.T) {
+	var (
+		a = common.HexToAddress("0x1")
+		b = common.HexToAddress("0x2")
+		c = common.HexToAddress("0x3")
+		d = common.HexToAddress("0x4")
+	)
+	snap := newSnapshot(&params.CliqueConfig{Epoch: 30000}, nil, 10, common.Hash{}, []common.Address{a, b})
+
+	validators := []*valset.Validator{
+		{Address: a, ProposerPriority: 100},
+		{Address: c, ProposerPriority: 100},
+		{Address: d, ProposerPriority: 50},
+	}
+	res := reconcile(snap, validators, 100)
+	if res.InSync {
+		t.Fatalf("expected divergence to be reported")
+	}
+	if len(res.Unstaked) != 1 || res.Unstaked[0] != b {
+		t.Errorf("unstaked mismatch: have %x, want [%x]", res.Unstaked, b)
+	}
+	if len(res.Unsigned) != 1 || res.Unsigned[0] != c {
+		t.Errorf("unsigned mismatch: have %x, want [%x]", res.Unsigned, c)
+	}
+	if len(res.Ineligible) != 1 || res.Ineligible[0].Address != d {
+		t.Errorf("ineligible mismatch: have %v, want [%x]", res.Ineligible, d)
+	}
+	// Matching sets are in sync
+	if res := reconcile(snap, validators[:1], 100); res.InSync {
+		t.Errorf("expected missing signer %x to be reported", b)
+	}
+	if res := reconcile(snap, []*valset.Validator{{Address: a, ProposerPriority: 100}, {Address: b, ProposerPriority: 100}}, 100); !res.InSync {
+		t.Errorf("expected snapshot to be in sync: %+v", res)
+	}
+}
+
+// Tests that inspecting a range of headers rebuilds their snapshots from the
+// last checkpoint and reports the seals, missed slots, votes and checkpoints.
+func TestInspect(t *testing.T) {
+	var (
+		accounts = newTesterAccountPool()
+		config   = &params.CliqueConfig{Period: 1, Epoch: 3}
+		db       = rawdb.NewMemoryDatabase()
+		signers  = []common.Address{accounts.address("A"), accounts.address("B"), accounts.address("C")}
+	)
+	sort.Sort(signersAscending(signers))
+	names := make(map[common.Address]string)
+	for _, name := range []string{"A", "B", "C"} {
+		names[accounts.address(name)] = name
+	}
+	s0, s1, s2 := names[signers[0]], names[signers[1]], names[signers[2]]
+
+	// Blocks 3 to 5 are sealed out-of-turn, block 5 votes to add D and block 6
+	// is sealed by the unauthorized D
+	blocks := []struct {
+		signer string
+		voted  string
+	}{{"", ""}, {s1, ""}, {s2, ""}, {s1, ""}, {s2, ""}, {s0, "D"}, {"D", ""}}
+
+	var parent common.Hash
+	for number, block := range blocks {
+		header := &types.Header{
+			ParentHash: parent,
+			Number:     big.NewInt(int64(number)),
+			Difficulty: diffNoTurn,
+			Coinbase:   accounts.address(block.voted),
+			Extra:      make([]byte, extraVanity+extraSeal),
+		}
+		if block.voted != "" {
+			copy(header.Nonce[:], nonceAuthVote)
+		}
+		if number%int(config.Epoch) == 0 {
+			header.Extra = make([]byte, extraVanity+len(signers)*common.AddressLength+extraSeal)
+			accounts.checkpoint(header, []string{"A", "B", "C"})
+		}
+		if block.signer != "" {
+			accounts.sign(header, block.signer)
+		}
+		rawdb.WriteHeader(db, header)
+		rawdb.WriteCanonicalHash(db, header.Hash(), header.Number.Uint64())
+		parent = header.Hash()
+	}
+	report, err := Inspect(config, db, 2, 5)
+	if err != nil {
+		t.Fatalf("failed to inspect headers: %v", err)
+	}
+	if report.Base != 0 || report.Failure != nil {
+		t.Fatalf("unexpected rebuild: base %d, failure %+v", report.Base, report.Failure)
+	}
+	counts := []struct {
+		name string
+		have map[common.Address]uint64
+		want map[string]uint64
+	}{
+		{"sealed", report.Sealed, map[string]uint64{s0: 1, s1: 1, s2: 2}},
+		{"in-turn", report.InTurn, map[string]uint64{s2: 1}},
+		{"missed", report.Missed, map[string]uint64{s0: 1, s1: 1, s2: 1}},
+	}
+	for _, c := range counts {
+		if len(c.have) != len(c.want) {
+			t.Errorf("%s count mismatch: have %v, want %v", c.name, c.have, c.want)
+		}
+		for name, want := range c.want {
+			if have := c.have[accounts.address(name)]; have != want {
+				t.Errorf("%s blocks of %s mismatch: have %d, want %d", c.name, name, have, want)
+			}
+		}
+	}
+	if len(report.Checkpoints) != 1 || report.Checkpoints[0].Number != 3 || !report.Checkpoints[0].InSync {
+		t.Errorf("checkpoints mismatch: %+v", report.Checkpoints)
+	}
+	if len(report.Votes) != 1 || report.Votes[0].Address != accounts.address("D") || report.Tally[accounts.address("D")].Votes != 1 {
+		t.Errorf("votes mismatch: %+v, tally %+v", report.Votes, report.Tally)
+	}
+	// The unauthorized seal stops the rebuild
+	report, err = Inspect(config, db, 4, 6)
+	if err != nil {
+		t.Fatalf("failed to inspect headers: %v", err)
+	}
+	if report.Base != 3 || report.Failure == nil || report.Failure.Number != 6 || report.Failure.Error != errUnauthorizedSigner.Error() {
+		t.Fatalf("failure mismatch: base %d, failure %+v", report.Base, report.Failure)
+	}
+	if len(report.Signers) != 3 {
+		t.Errorf("signers mismatch: %v", report.Signers)
+	}
+}
@@ -2,6 +2,7 @@ package clique
 
 import (
 	"context"
+	"math/big"
 
 	"github.com/qydata/go-ctereum/common"
 	"github.com/qydata/go-ctereum/consensus/clique/valset"
@@ -14,4 +15,11 @@ import (
 type Spanner interface {
 	GetCurrentValidators(ctx context.Context, headerHash common.Hash, blockNumber uint64) ([]*valset.Validator, error)
 	CommitAccum(ctx context.Context, state *state.StateDB, header *types.Header, chainContext core.ChainContext, validators []common.Address) error
+	Reimburse(ctx context.Context, state *state.StateDB, header *types.Header, chainContext core.ChainContext, producer common.Address, amount *big.Int) error
+}
+
+// Validators retrieves the validators registered in the staking contract for
+// the block following the given one.
+func (c *Clique) Validators(header *types.Header) ([]*valset.Validator, error) {
+	return c.spanner.GetCurrentValidators(context.Background(), header.Hash(), header.Number.Uint64()+1)
 }
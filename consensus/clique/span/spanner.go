@@ -117,7 +117,103 @@ func (c *ChainSpanner) CommitAccum(ctx context.Context, state *state.StateDB, he
 	msg := statefull.GetSystemMessage(c.validatorContractAddress, data)
 
 	// apply message
-	_, err = statefull.ApplyMessage(ctx, msg, state, header, c.chainConfig, chainContext)
+	_, err = statefull.ApplyMessage(ctx, method, msg, state, header, c.chainConfig, chainContext)
 
 	return err
 }
+
+// Reimburse pays the block producer the subsidy owed for the sponsored
+// transactions of the block out of the subsidy pool of the validator contract.
+// The caller makes sure the contract deployed has a reimburse method, see the
+// ReimburseBlock of the subsidy config.
+func (c *ChainSpanner) Reimburse(ctx context.Context, state *state.StateDB, header *types.Header, chainContext core.ChainContext, producer common.Address, amount *big.Int) error {
+	const method = "reimburse"
+
+	data, err := c.staking.Pack(method, producer, amount)
+	if err != nil {
+		log.Error("Unable to pack tx for reimburse", "error", err)
+		return err
+	}
+	msg := statefull.GetSystemMessage(c.validatorContractAddress, data)
+	_, err = statefull.ApplyMessage(ctx, method, msg, state, header, c.chainConfig, chainContext)
+	return err
+}
+
+// GetValidatorThreshold returns the minimum stake of a validator.
+func (c *ChainSpanner) GetValidatorThreshold(ctx context.Context, blockNr rpc.BlockNumberOrHash) (*big.Int, error) {
+	threshold := new(*big.Int)
+	if err := c.callStaking(ctx, blockNr, threshold, "VALIDATOR_THRESHOLD"); err != nil {
+		return nil, err
+	}
+	return *threshold, nil
+}
+
+// GetMaxValidators returns the maximum number of validators.
+func (c *ChainSpanner) GetMaxValidators(ctx context.Context, blockNr rpc.BlockNumberOrHash) (*big.Int, error) {
+	max := new(*big.Int)
+	if err := c.callStaking(ctx, blockNr, max, "maximumNumValidators"); err != nil {
+		return nil, err
+	}
+	return *max, nil
+}
+
+// GetValidatorAddresses returns the addresses of the current validators.
+func (c *ChainSpanner) GetValidatorAddresses(ctx context.Context, blockNr rpc.BlockNumberOrHash) ([]common.Address, error) {
+	validators := new([]common.Address)
+	if err := c.callStaking(ctx, blockNr, validators, "validators"); err != nil {
+		return nil, err
+	}
+	return *validators, nil
+}
+
+// GetStake returns the amount staked by an account.
+func (c *ChainSpanner) GetStake(ctx context.Context, blockNr rpc.BlockNumberOrHash, addr common.Address) (*big.Int, error) {
+	stake := new(*big.Int)
+	if err := c.callStaking(ctx, blockNr, stake, "accountStake", addr); err != nil {
+		return nil, err
+	}
+	return *stake, nil
+}
+
+// IsValidator returns whether an account is a current validator.
+func (c *ChainSpanner) IsValidator(ctx context.Context, blockNr rpc.BlockNumberOrHash, addr common.Address) (bool, error) {
+	var ok bool
+	if err := c.callStaking(ctx, blockNr, &ok, "isValidator", addr); err != nil {
+		return false, err
+	}
+	return ok, nil
+}
+
+// GetSender returns the account sending the transactions of a validator, the
+// zero address if none is set.
+func (c *ChainSpanner) GetSender(ctx context.Context, blockNr rpc.BlockNumberOrHash, addr common.Address) (common.Address, error) {
+	var sender common.Address
+	if err := c.callStaking(ctx, blockNr, &sender, "_addressToSender", addr); err != nil {
+		return common.Address{}, err
+	}
+	return sender, nil
+}
+
+// callStaking calls a view method of the validator contract at the given block,
+// unpacking its single result into out.
+func (c *ChainSpanner) callStaking(ctx context.Context, blockNr rpc.BlockNumberOrHash, out interface{}, method string, args ...interface{}) error {
+	data, err := c.staking.Pack(method, args...)
+	if err != nil {
+		log.Error("Unable to pack tx for "+method, "error", err)
+		return err
+	}
+	var (
+		msgData   = (hexutil.Bytes)(data)
+		toAddress = c.validatorContractAddress
+		gas       = (hexutil.Uint64)(uint64(math.MaxUint64 / 2))
+	)
+	result, err := c.ethAPI.Call(ctx, ethapi.TransactionArgs{
+		Gas:  &gas,
+		To:   &toAddress,
+		Data: &msgData,
+	}, blockNr, nil)
+	if err != nil {
+		return err
+	}
+	return c.staking.UnpackIntoInterface(out, method, result)
+}
@@ -15,7 +15,7 @@ import (
 	"github.com/qydata/go-ctereum/params"
 )
 
-var systemAddress = common.HexToAddress("0xffffFFFfFFffffffffffffffFfFFFfffFFFfFFfE")
+var systemAddress = params.SystemAddress
 
 type ChainContext struct {
 	Chain  consensus.ChainHeaderReader
@@ -58,9 +58,29 @@ func GetSystemMessage(toAddress common.Address, data []byte) Callmsg {
 	}
 }
 
-// apply message
+// tracerKey is the context key of the tracer of the system calls.
+type tracerKey struct{}
+
+// WithTracer returns a copy of the context running the system calls applied
+// with it through the given tracer.
+func WithTracer(ctx context.Context, tracer vm.EVMLogger) context.Context {
+	return context.WithValue(ctx, tracerKey{}, tracer)
+}
+
+// vmConfig returns the EVM configuration of the system calls applied with the
+// given context, enabling the tracer it carries, if any.
+func vmConfig(ctx context.Context) vm.Config {
+	if tracer, ok := ctx.Value(tracerKey{}).(vm.EVMLogger); ok && tracer != nil {
+		return vm.Config{Debug: true, Tracer: tracer}
+	}
+	return vm.Config{}
+}
+
+// apply message, accounting its outcome under the given contract method and
+// returning the error of a failed call
 func ApplyMessage(
-	_ context.Context,
+	ctx context.Context,
+	method string,
 	msg Callmsg,
 	state *state.StateDB,
 	header *types.Header,
@@ -75,10 +95,14 @@ func ApplyMessage(
 
 	// Create a new environment which holds all relevant information
 	// about the transaction and calling mechanisms.
-	vmenv := vm.NewEVM(blockContext, vm.TxContext{}, state, chainConfig, vm.Config{})
+	config := vmConfig(ctx)
+	vmenv := vm.NewEVM(blockContext, vm.TxContext{}, state, chainConfig, config)
+	if config.Debug {
+		config.Tracer.CaptureTxStart(initialGas)
+	}
 
 	// Apply the transaction to the current state (included in the env)
-	_, gasLeft, err := vmenv.Call(
+	ret, gasLeft, err := vmenv.Call(
 		vm.AccountRef(msg.From()),
 		*msg.To(),
 		msg.Data(),
@@ -90,7 +114,11 @@ func ApplyMessage(
 		state.Finalise(true)
 	}
 
+	if config.Debug {
+		config.Tracer.CaptureTxEnd(gasLeft)
+	}
 	gasUsed := initialGas - gasLeft
+	recordCall(method, gasUsed, ret, err)
 
-	return gasUsed, nil
+	return gasUsed, err
 }
@@ -0,0 +1,46 @@
+package statefull
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/qydata/go-ctereum/common"
+	"github.com/qydata/go-ctereum/consensus/ethash"
+	"github.com/qydata/go-ctereum/core/rawdb"
+	"github.com/qydata/go-ctereum/core/state"
+	"github.com/qydata/go-ctereum/core/types"
+	"github.com/qydata/go-ctereum/eth/tracers/logger"
+	"github.com/qydata/go-ctereum/params"
+)
+
+// Tests that system calls applied with a tracer in their context run through it.
+func TestApplyMessageTracer(t *testing.T) {
+	contract := common.HexToAddress("0xaaaa")
+	db, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	db.SetCode(contract, common.FromHex("6001600155")) // sstore(1, 1)
+
+	header := &types.Header{Number: big.NewInt(1), Difficulty: big.NewInt(1), GasLimit: 8000000}
+	msg := GetSystemMessage(contract, nil)
+	chain := ChainContext{Clique: ethash.NewFaker()}
+	db.PrepareAccessList(msg.From(), msg.To(), nil, nil)
+
+	// Without a tracer nothing is captured
+	tracer := logger.NewStructLogger(nil)
+	if _, err := ApplyMessage(context.Background(), "testTrace", msg, db, header, params.TestChainConfig, chain); err != nil {
+		t.Fatalf("failed to apply message: %v", err)
+	}
+	if len(tracer.StructLogs()) != 0 {
+		t.Fatalf("untraced call captured")
+	}
+	ctx := WithTracer(context.Background(), tracer)
+	if _, err := ApplyMessage(ctx, "testTrace", msg, db, header, params.TestChainConfig, chain); err != nil {
+		t.Fatalf("failed to apply message: %v", err)
+	}
+	if have := len(tracer.StructLogs()); have != 4 {
+		t.Errorf("captured opcode count mismatch: have %d, want 4", have)
+	}
+	if db.GetState(contract, common.BigToHash(common.Big1)) != common.BigToHash(common.Big1) {
+		t.Errorf("traced call not applied")
+	}
+}
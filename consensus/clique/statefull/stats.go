@@ -0,0 +1,71 @@
+package statefull
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/qydata/go-ctereum/accounts/abi"
+	"github.com/qydata/go-ctereum/core/vm"
+	"github.com/qydata/go-ctereum/metrics"
+)
+
+// systemCallMetricsPrefix is the prefix of the per-method system call metrics.
+const systemCallMetricsPrefix = "clique/systemcall"
+
+// CallStats is the accumulated outcome of the system calls issued for a single
+// contract method.
+type CallStats struct {
+	Calls      uint64 `json:"calls"`
+	Failures   uint64 `json:"failures"`
+	GasUsed    uint64 `json:"gasUsed"`
+	LastError  string `json:"lastError,omitempty"`
+	LastRevert string `json:"lastRevert,omitempty"`
+}
+
+var (
+	statsLock sync.RWMutex
+	stats     = make(map[string]*CallStats)
+)
+
+// recordCall accounts a finished system call both in the metrics registry and
+// in the in-memory statistics served over RPC.
+func recordCall(method string, gasUsed uint64, ret []byte, err error) {
+	metrics.GetOrRegisterCounter(fmt.Sprintf("%s/%s/calls", systemCallMetricsPrefix, method), nil).Inc(1)
+	metrics.GetOrRegisterCounter(fmt.Sprintf("%s/%s/gas", systemCallMetricsPrefix, method), nil).Inc(int64(gasUsed))
+	if err != nil {
+		metrics.GetOrRegisterCounter(fmt.Sprintf("%s/%s/failures", systemCallMetricsPrefix, method), nil).Inc(1)
+	}
+	statsLock.Lock()
+	defer statsLock.Unlock()
+
+	s, ok := stats[method]
+	if !ok {
+		s = new(CallStats)
+		stats[method] = s
+	}
+	s.Calls++
+	s.GasUsed += gasUsed
+	if err != nil {
+		s.Failures++
+		s.LastError = err.Error()
+		if errors.Is(err, vm.ErrExecutionReverted) {
+			if reason, errUnpack := abi.UnpackRevert(ret); errUnpack == nil {
+				s.LastRevert = reason
+			}
+		}
+	}
+}
+
+// Stats returns a copy of the system call statistics gathered since startup,
+// keyed by contract method name.
+func Stats() map[string]CallStats {
+	statsLock.RLock()
+	defer statsLock.RUnlock()
+
+	res := make(map[string]CallStats, len(stats))
+	for method, s := range stats {
+		res[method] = *s
+	}
+	return res
+}
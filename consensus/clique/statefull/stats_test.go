@@ -0,0 +1,38 @@
+package statefull
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/qydata/go-ctereum/common"
+	"github.com/qydata/go-ctereum/core/vm"
+	"github.com/qydata/go-ctereum/crypto"
+)
+
+func TestRecordCall(t *testing.T) {
+	// Error(string) revert payload with the reason "not allowed"
+	revert := append(crypto.Keccak256([]byte("Error(string)"))[:4],
+		common.FromHex("0x"+
+			"0000000000000000000000000000000000000000000000000000000000000020"+
+			"000000000000000000000000000000000000000000000000000000000000000b"+
+			"6e6f7420616c6c6f776564000000000000000000000000000000000000000000")...)
+
+	recordCall("testMethod", 100, nil, nil)
+	recordCall("testMethod", 50, revert, vm.ErrExecutionReverted)
+	recordCall("testMethod", 25, nil, errors.New("out of gas"))
+
+	have, ok := Stats()["testMethod"]
+	if !ok {
+		t.Fatalf("missing stats for method")
+	}
+	want := CallStats{
+		Calls:      3,
+		Failures:   2,
+		GasUsed:    175,
+		LastError:  "out of gas",
+		LastRevert: "not allowed",
+	}
+	if have != want {
+		t.Errorf("stats mismatch: have %+v, want %+v", have, want)
+	}
+}
@@ -0,0 +1,293 @@
+package valset
+
+import (
+	"math"
+	"testing"
+
+	"github.com/qydata/go-ctereum/common"
+)
+
+func newValidator(addr string, power int64) *Validator {
+	return &Validator{Address: common.HexToAddress(addr), VotingPower: power}
+}
+
+func TestValidatorSetBasic(t *testing.T) {
+	// empty or nil validator lists are allowed
+	for _, vset := range []*ValidatorSet{NewValidatorSet([]*Validator{}), NewValidatorSet(nil)} {
+		if !vset.IsNilOrEmpty() {
+			t.Errorf("expected empty set")
+		}
+		if vset.Size() != 0 {
+			t.Errorf("size mismatch: have %d, want 0", vset.Size())
+		}
+		if vset.GetProposer() != nil {
+			t.Errorf("expected no proposer in empty set")
+		}
+		if idx, val := vset.GetByAddress(common.HexToAddress("0x1")); idx != -1 || val != nil {
+			t.Errorf("unexpected validator in empty set: %d %v", idx, val)
+		}
+		if addr, val := vset.GetByIndex(0); addr != nil || val != nil {
+			t.Errorf("unexpected validator at index 0: %x %v", addr, val)
+		}
+	}
+	vset := NewValidatorSet([]*Validator{newValidator("0x2", 10), newValidator("0x1", 10)})
+	if vset.Size() != 2 {
+		t.Fatalf("size mismatch: have %d, want 2", vset.Size())
+	}
+	if vset.TotalVotingPower() != 20 {
+		t.Errorf("total power mismatch: have %d, want 20", vset.TotalVotingPower())
+	}
+	// validators are kept sorted by address
+	if addr, _ := vset.GetByIndex(0); common.BytesToAddress(addr) != common.HexToAddress("0x1") {
+		t.Errorf("validators not sorted: first is %x", addr)
+	}
+	if !vset.HasAddress(common.HexToAddress("0x2").Bytes()) {
+		t.Errorf("expected address to be in the set")
+	}
+	if vset.HasAddress(common.HexToAddress("0x3").Bytes()) {
+		t.Errorf("unexpected address in the set")
+	}
+	if vset.GetProposer() == nil {
+		t.Errorf("expected a proposer")
+	}
+}
+
+func TestNewValidatorSetDuplicatePanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected panic on duplicate validators")
+		}
+	}()
+	NewValidatorSet([]*Validator{newValidator("0x1", 10), newValidator("0x1", 20)})
+}
+
+func TestValidatorSetCopy(t *testing.T) {
+	vset := NewValidatorSet([]*Validator{newValidator("0x1", 10), newValidator("0x2", 20)})
+	vsetCopy := vset.Copy()
+
+	vsetCopy.IncrementProposerPriority(3)
+	for i := range vset.Validators {
+		if vset.Validators[i] == vsetCopy.Validators[i] {
+			t.Fatalf("validator %d shared between copies", i)
+		}
+	}
+	orig := NewValidatorSet([]*Validator{newValidator("0x1", 10), newValidator("0x2", 20)})
+	for i := range vset.Validators {
+		if vset.Validators[i].ProposerPriority != orig.Validators[i].ProposerPriority {
+			t.Errorf("validator %d: original mutated through copy", i)
+		}
+	}
+	inc := vset.CopyIncrementProposerPriority(3)
+	for i := range inc.Validators {
+		if inc.Validators[i].ProposerPriority != vsetCopy.Validators[i].ProposerPriority {
+			t.Errorf("validator %d: priority mismatch: have %d, want %d", i, inc.Validators[i].ProposerPriority, vsetCopy.Validators[i].ProposerPriority)
+		}
+	}
+}
+
+// Tests that the proposers are selected in proportion to their voting power.
+func TestProposerSelectionWeighted(t *testing.T) {
+	vset := NewValidatorSet([]*Validator{
+		newValidator("0x1", 1),
+		newValidator("0x2", 2),
+		newValidator("0x3", 7),
+	})
+	counts := make(map[common.Address]int)
+	for i := 0; i < 1000; i++ {
+		counts[vset.GetProposer().Address]++
+		vset.IncrementProposerPriority(1)
+	}
+	for addr, want := range map[string]int{"0x1": 100, "0x2": 200, "0x3": 700} {
+		if have := counts[common.HexToAddress(addr)]; have != want {
+			t.Errorf("validator %s: proposer count mismatch: have %d, want %d", addr, have, want)
+		}
+	}
+}
+
+// Tests that equal powers rotate the proposer in address order.
+func TestProposerSelectionRoundRobin(t *testing.T) {
+	vset := NewValidatorSet([]*Validator{
+		newValidator("0x3", 5),
+		newValidator("0x1", 5),
+		newValidator("0x2", 5),
+	})
+	var proposers []common.Address
+	for i := 0; i < 6; i++ {
+		proposers = append(proposers, vset.GetProposer().Address)
+		vset.IncrementProposerPriority(1)
+	}
+	for i := 0; i < 3; i++ {
+		if proposers[i] == proposers[(i+1)%3] {
+			t.Errorf("proposer %x repeated in round %d", proposers[i], i)
+		}
+		if proposers[i] != proposers[i+3] {
+			t.Errorf("rotation not periodic: %x != %x", proposers[i], proposers[i+3])
+		}
+	}
+	if proposers[0] != common.HexToAddress("0x1") {
+		t.Errorf("first proposer mismatch: have %x, want %x", proposers[0], common.HexToAddress("0x1"))
+	}
+}
+
+// Tests that incrementing multiple times at once is equivalent to incrementing
+// one by one.
+func TestIncrementProposerPriorityTimes(t *testing.T) {
+	vals := []*Validator{newValidator("0x1", 3), newValidator("0x2", 4), newValidator("0x3", 5)}
+
+	one := NewValidatorSet(validatorListCopy(vals))
+	many := NewValidatorSet(validatorListCopy(vals))
+	for i := 0; i < 5; i++ {
+		one.IncrementProposerPriority(1)
+	}
+	many.IncrementProposerPriority(5)
+	if one.GetProposer().Address != many.GetProposer().Address {
+		t.Errorf("proposer mismatch: have %x, want %x", many.GetProposer().Address, one.GetProposer().Address)
+	}
+}
+
+func TestIncrementProposerPriorityPanics(t *testing.T) {
+	for i, fn := range []func(){
+		func() { NewValidatorSet(nil).IncrementProposerPriority(1) },
+		func() { NewValidatorSet([]*Validator{newValidator("0x1", 1)}).IncrementProposerPriority(0) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("test %d: expected panic", i)
+				}
+			}()
+			fn()
+		}()
+	}
+}
+
+// Tests that the priorities are centred around zero and the distance between
+// them stays bounded by the priority window.
+func TestPrioritiesCentredAndBounded(t *testing.T) {
+	vset := NewValidatorSet([]*Validator{
+		newValidator("0x1", 1),
+		newValidator("0x2", 10),
+		newValidator("0x3", 100),
+	})
+	window := PriorityWindowSizeFactor * vset.TotalVotingPower()
+	for i := 0; i < 500; i++ {
+		vset.IncrementProposerPriority(1)
+		if diff := computeMaxMinPriorityDiff(vset); diff > window {
+			t.Fatalf("round %d: priority spread %d exceeds window %d", i, diff, window)
+		}
+	}
+	// Sum of priorities after centering is within the rounding error
+	vset.shiftByAvgProposerPriority()
+	var sum int64
+	for _, val := range vset.Validators {
+		sum += val.ProposerPriority
+	}
+	if sum < -int64(vset.Size()) || sum > int64(vset.Size()) {
+		t.Errorf("priorities not centred: sum %d", sum)
+	}
+}
+
+func TestRescalePriorities(t *testing.T) {
+	vset := &ValidatorSet{Validators: []*Validator{
+		{Address: common.HexToAddress("0x1"), VotingPower: 10, ProposerPriority: -1000},
+		{Address: common.HexToAddress("0x2"), VotingPower: 10, ProposerPriority: 1000},
+	}}
+	vset.RescalePriorities(400)
+	// diff 2000, ratio ceil(2000/400) = 5
+	if have := vset.Validators[0].ProposerPriority; have != -200 {
+		t.Errorf("priority mismatch: have %d, want %d", have, -200)
+	}
+	if have := vset.Validators[1].ProposerPriority; have != 200 {
+		t.Errorf("priority mismatch: have %d, want %d", have, 200)
+	}
+	// Spreads already inside the window are left alone
+	vset.RescalePriorities(1000)
+	if have := vset.Validators[1].ProposerPriority; have != 200 {
+		t.Errorf("priority changed inside window: have %d", have)
+	}
+}
+
+func TestUpdateWithChangeSet(t *testing.T) {
+	vset := NewValidatorSet([]*Validator{newValidator("0x1", 10), newValidator("0x2", 10)})
+
+	// add a new validator and update an existing one
+	if err := vset.UpdateWithChangeSet([]*Validator{newValidator("0x3", 30), newValidator("0x1", 20)}); err != nil {
+		t.Fatalf("failed to apply changes: %v", err)
+	}
+	if vset.Size() != 3 || vset.TotalVotingPower() != 60 {
+		t.Fatalf("set mismatch: size %d, power %d", vset.Size(), vset.TotalVotingPower())
+	}
+	if _, val := vset.GetByAddress(common.HexToAddress("0x1")); val.VotingPower != 20 {
+		t.Errorf("power not updated: have %d, want 20", val.VotingPower)
+	}
+	// new validators start with a penalty so they can't reset their priority by re-bonding
+	if _, val := vset.GetByAddress(common.HexToAddress("0x3")); val.ProposerPriority >= 0 {
+		t.Errorf("new validator priority not penalised: %d", val.ProposerPriority)
+	}
+	// remove a validator
+	if err := vset.UpdateWithChangeSet([]*Validator{newValidator("0x2", 0)}); err != nil {
+		t.Fatalf("failed to remove validator: %v", err)
+	}
+	if vset.HasAddress(common.HexToAddress("0x2").Bytes()) {
+		t.Errorf("validator not removed")
+	}
+	if vset.TotalVotingPower() != 50 {
+		t.Errorf("total power mismatch: have %d, want 50", vset.TotalVotingPower())
+	}
+}
+
+func TestUpdateWithChangeSetErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		changes []*Validator
+	}{
+		{"duplicate", []*Validator{newValidator("0x3", 1), newValidator("0x3", 2)}},
+		{"negative power", []*Validator{newValidator("0x3", -1)}},
+		{"power too high", []*Validator{newValidator("0x3", MaxTotalVotingPower+1)}},
+		{"total power too high", []*Validator{newValidator("0x3", MaxTotalVotingPower)}},
+		{"unknown removal", []*Validator{newValidator("0x3", 0)}},
+		{"empty result", []*Validator{newValidator("0x1", 0), newValidator("0x2", 0)}},
+	}
+	for _, tt := range tests {
+		vset := NewValidatorSet([]*Validator{newValidator("0x1", 10), newValidator("0x2", 10)})
+		orig := vset.Copy()
+		if err := vset.UpdateWithChangeSet(tt.changes); err == nil {
+			t.Errorf("%s: expected error", tt.name)
+		}
+		// failed updates leave the set untouched
+		if vset.Size() != orig.Size() || vset.TotalVotingPower() != orig.TotalVotingPower() {
+			t.Errorf("%s: set modified on error", tt.name)
+		}
+		for i := range vset.Validators {
+			if *vset.Validators[i] != *orig.Validators[i] {
+				t.Errorf("%s: validator %d modified on error", tt.name, i)
+			}
+		}
+	}
+	// Deletes are rejected at construction time
+	vset := &ValidatorSet{}
+	if err := vset.updateWithChangeSet([]*Validator{newValidator("0x1", 0)}, false); err == nil {
+		t.Errorf("expected error for zero power validator")
+	}
+}
+
+func TestSafeAddSubClip(t *testing.T) {
+	tests := []struct {
+		a, b     int64
+		add, sub int64
+	}{
+		{1, 2, 3, -1},
+		{math.MaxInt64, 1, math.MaxInt64, math.MaxInt64 - 1},
+		{math.MinInt64, -1, math.MinInt64, math.MinInt64 + 1},
+		{math.MinInt64, 1, math.MinInt64 + 1, math.MinInt64},
+		{math.MaxInt64, -1, math.MaxInt64 - 1, math.MaxInt64},
+	}
+	for i, tt := range tests {
+		if have := safeAddClip(tt.a, tt.b); have != tt.add {
+			t.Errorf("test %d: add mismatch: have %d, want %d", i, have, tt.add)
+		}
+		if have := safeSubClip(tt.a, tt.b); have != tt.sub {
+			t.Errorf("test %d: sub mismatch: have %d, want %d", i, have, tt.sub)
+		}
+	}
+}
@@ -23,6 +23,7 @@ import (
 	"github.com/qydata/go-ctereum/common"
 	"github.com/qydata/go-ctereum/core/state"
 	"github.com/qydata/go-ctereum/core/types"
+	"github.com/qydata/go-ctereum/core/vm"
 	"github.com/qydata/go-ctereum/params"
 	"github.com/qydata/go-ctereum/rpc"
 )
@@ -121,6 +122,24 @@ type Engine interface {
 	Close() error
 }
 
+// TracingFinalizer is implemented by the consensus engines executing system
+// calls while finalizing a block, which can run them through an EVM tracer like
+// the transactions of the block.
+type TracingFinalizer interface {
+	// FinalizeWithTracer is similar to Finalize, but runs the system calls
+	// executed by the engine through the given tracer.
+	FinalizeWithTracer(chain ChainHeaderReader, header *types.Header, state *state.StateDB, txs []*types.Transaction,
+		uncles []*types.Header, tracer vm.EVMLogger)
+}
+
+// DetachedFinalizer is a consensus engine able to finalize blocks detached from
+// the local chain, such as simulated ones, whose ancestors may be unknown.
+type DetachedFinalizer interface {
+	// FinalizeDetached is similar to Finalize, but skips the state changes
+	// depending on the sealing history of the chain.
+	FinalizeDetached(chain ChainHeaderReader, header *types.Header, state *state.StateDB)
+}
+
 // PoW is a consensus engine based on proof-of-work.
 type PoW interface {
 	Engine
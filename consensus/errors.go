@@ -39,3 +39,38 @@ var (
 	// total difficulty.
 	ErrInvalidTerminalBlock = errors.New("invalid terminal block")
 )
+
+// CodedError is a consensus error carrying a stable, machine-readable code and
+// name, allowing operator tooling to act upon block import failures without
+// matching on error messages. The code and name are surfaced over RPC too.
+type CodedError struct {
+	code int    // Numeric code of the failure
+	name string // Symbolic name of the failure
+	msg  string // Human readable description
+}
+
+// NewCodedError creates a consensus error with the given code and name.
+func NewCodedError(code int, name string, msg string) *CodedError {
+	return &CodedError{code: code, name: name, msg: msg}
+}
+
+// Error implements error.
+func (e *CodedError) Error() string { return e.msg }
+
+// ErrorCode returns the machine-readable code of the failure.
+func (e *CodedError) ErrorCode() int { return e.code }
+
+// ErrorData returns the symbolic name of the failure.
+func (e *CodedError) ErrorData() interface{} { return e.name }
+
+// Name returns the symbolic name of the failure.
+func (e *CodedError) Name() string { return e.name }
+
+// CodeOf returns the coded consensus error wrapped by err, if any.
+func CodeOf(err error) (*CodedError, bool) {
+	var coded *CodedError
+	if errors.As(err, &coded) {
+		return coded, true
+	}
+	return nil, false
+}
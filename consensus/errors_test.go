@@ -0,0 +1,43 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package consensus
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// Tests that coded errors are found through wrapping and keep their identity.
+func TestCodedError(t *testing.T) {
+	errTest := NewCodedError(1, "TEST", "test failure")
+
+	wrapped := fmt.Errorf("block #1: %w", errTest)
+	if !errors.Is(wrapped, errTest) {
+		t.Fatalf("wrapped error lost its identity")
+	}
+	coded, ok := CodeOf(wrapped)
+	if !ok || coded != errTest {
+		t.Fatalf("coded error not found: %v", wrapped)
+	}
+	if coded.ErrorCode() != 1 || coded.ErrorData() != "TEST" || coded.Error() != "test failure" {
+		t.Errorf("coded error mismatch: code %d, data %v, message %q", coded.ErrorCode(), coded.ErrorData(), coded.Error())
+	}
+	if _, ok := CodeOf(ErrUnknownAncestor); ok {
+		t.Errorf("plain error reported as coded")
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/qydata/go-ctereum/core/types"
 	"github.com/qydata/go-ctereum/params"
 )
 
@@ -40,3 +41,27 @@ func VerifyGaslimit(parentGasLimit, headerGasLimit uint64) error {
 	}
 	return nil
 }
+
+// VerifyGasLimitBounds verifies the header gas limit against the network-wide
+// bounds of the chain config. As the limit may only move gradually, a limit out
+// of the bounds is accepted while strictly converging towards them from a parent
+// out of the bounds too, e.g. right after the bounds activate. Staying flat out
+// of the bounds is rejected, so that the limit does reach them eventually.
+func VerifyGasLimitBounds(config *params.ChainConfig, parent, header *types.Header) error {
+	if !config.IsGasLimitBounds(header.Number) {
+		return nil
+	}
+	min, max := config.GasLimitBoundsAt(header.Number)
+
+	parentGasLimit := parent.GasLimit
+	if config.IsLondon(header.Number) && !config.IsLondon(parent.Number) {
+		parentGasLimit = parent.GasLimit * params.ElasticityMultiplier
+	}
+	if header.GasLimit < min && (parentGasLimit >= min || header.GasLimit <= parentGasLimit) {
+		return fmt.Errorf("invalid gas limit: have %d, minimum %d", header.GasLimit, min)
+	}
+	if header.GasLimit > max && (parentGasLimit <= max || header.GasLimit >= parentGasLimit) {
+		return fmt.Errorf("invalid gas limit: have %d, maximum %d", header.GasLimit, max)
+	}
+	return nil
+}
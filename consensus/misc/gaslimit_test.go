@@ -0,0 +1,76 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package misc
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/qydata/go-ctereum/core/types"
+	"github.com/qydata/go-ctereum/params"
+)
+
+// TestGasLimitBounds tests the network-wide gas limit bounds, including the
+// convergence of out of bounds limits after the activation.
+func TestGasLimitBounds(t *testing.T) {
+	config := config()
+	config.GasLimitBounds = &params.GasLimitBoundsConfig{
+		Block: big.NewInt(10),
+		Min:   8000000,
+		Max:   30000000,
+	}
+	for i, tc := range []struct {
+		pGasLimit uint64
+		pNum      int64
+		gasLimit  uint64
+		ok        bool
+	}{
+		// Before the activation any limit goes
+		{7000000, 8, 6999000, true},
+		{40000000, 8, 40001000, true},
+		// Within the bounds
+		{8000000, 10, 8000000, true},
+		{30000000, 10, 30000000, true},
+		// Leaving the bounds
+		{8000000, 10, 7999999, false},
+		{30000000, 10, 30000001, false},
+		// Converging towards the bounds
+		{7000000, 9, 7006000, true},
+		{7000000, 9, 6999999, false},
+		{40000000, 9, 39990000, true},
+		{40000000, 9, 40000001, false},
+		// Staying flat out of the bounds
+		{7000000, 9, 7000000, false},
+		{40000000, 9, 40000000, false},
+	} {
+		parent := &types.Header{
+			GasLimit: tc.pGasLimit,
+			Number:   big.NewInt(tc.pNum),
+		}
+		header := &types.Header{
+			GasLimit: tc.gasLimit,
+			Number:   big.NewInt(tc.pNum + 1),
+		}
+		err := VerifyGasLimitBounds(config, parent, header)
+		if tc.ok && err != nil {
+			t.Errorf("test %d: Expected valid header: %s", i, err)
+		}
+		if !tc.ok && err == nil {
+			t.Errorf("test %d: Expected invalid header", i)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package misc
+
+import (
+	"math/big"
+
+	"github.com/qydata/go-ctereum/core/state"
+	"github.com/qydata/go-ctereum/log"
+	"github.com/qydata/go-ctereum/params"
+)
+
+// ApplyUpgradeActions patches the state database according to the given state
+// upgrade actions of a block.
+func ApplyUpgradeActions(number *big.Int, actions []params.UpgradeAction, statedb *state.StateDB) {
+	for _, action := range actions {
+		if action.Code != nil {
+			statedb.SetCode(action.Address, action.Code)
+		}
+		for key, value := range action.Storage {
+			statedb.SetState(action.Address, key, value)
+		}
+		if action.AddBalance != nil {
+			statedb.AddBalance(action.Address, action.AddBalance)
+		}
+		log.Debug("Applied state upgrade", "number", number, "address", action.Address, "finalize", action.Finalize)
+	}
+}
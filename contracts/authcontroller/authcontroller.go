@@ -0,0 +1,234 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package authcontroller is a Go wrapper around the on-chain AuthController
+// keeping the real-name authentication of accounts.
+package authcontroller
+
+//go:generate go run ../../cmd/abigen --abi contract/authcontroller.abi --pkg contract --type AuthController --out contract/authcontroller.go
+//go:generate go run ../../cmd/abigen --abi contract/authcontroller_v1.abi --pkg contract --type AuthControllerV1 --out contract/authcontroller_v1.go
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/qydata/go-ctereum/accounts/abi/bind"
+	"github.com/qydata/go-ctereum/common"
+	"github.com/qydata/go-ctereum/contracts/authcontroller/contract"
+	"github.com/qydata/go-ctereum/core/types"
+)
+
+// Version is the revision of the AuthController ABI a contract implements.
+type Version int
+
+const (
+	// V1 records only carry the authenticated address, its sender, the
+	// signature and the auth flag.
+	V1 Version = 1
+
+	// V2 records additionally carry the auth time, expiry, level and free form
+	// expansion data.
+	V2 Version = 2
+)
+
+// AuthData is an authentication record, independent of the contract revision
+// it was read from. Fields unknown to v1 contracts are left zero.
+type AuthData struct {
+	Caddress   common.Address
+	Sender     common.Address
+	Signature  []byte
+	AuthTime   *big.Int
+	AuthExpiry *big.Int
+	IsAuth     bool
+	AuthLevel  *big.Int
+	ExpandData string
+}
+
+// Expired reports whether the authentication has expired at the given unix
+// time. Records without an expiry never expire.
+func (a *AuthData) Expired(now uint64) bool {
+	if a.AuthExpiry == nil || a.AuthExpiry.Sign() == 0 {
+		return false
+	}
+	return a.AuthExpiry.Cmp(new(big.Int).SetUint64(now)) <= 0
+}
+
+// Level returns the authentication level of the record, zero if unknown.
+func (a *AuthData) Level() uint64 {
+	if a.AuthLevel == nil {
+		return 0
+	}
+	return a.AuthLevel.Uint64()
+}
+
+// Binding is the revision independent view of an AuthController contract.
+type Binding interface {
+	// AuthsSingle reports whether the address is authenticated.
+	AuthsSingle(opts *bind.CallOpts, addr common.Address) (bool, error)
+
+	// Whitelisted reports whether the address is on the whitelist.
+	Whitelisted(opts *bind.CallOpts, addr common.Address) (bool, error)
+
+	// GetWhitelist returns all whitelisted addresses.
+	GetWhitelist(opts *bind.CallOpts) ([]common.Address, error)
+
+	// ParentAuth returns the record the parent address issued for addr.
+	ParentAuth(opts *bind.CallOpts, parent common.Address, addr common.Address) (*AuthData, error)
+
+	// ParseAuthentication decodes an Authentication event log.
+	ParseAuthentication(log types.Log) (*AuthData, error)
+
+	// AUTHTYPEHASH returns the EIP-712 type hash the contract verifies
+	// signatures against.
+	AUTHTYPEHASH(opts *bind.CallOpts) ([32]byte, error)
+}
+
+// AuthController is a Go wrapper around an on-chain AuthController contract.
+type AuthController struct {
+	Binding
+
+	address common.Address
+	version Version
+}
+
+// AuthBackend is the chain access needed to use an AuthController. It is
+// satisfied by the simulated backend and by remote clients, as well as by
+// ChainBackend for the node's local chain.
+type AuthBackend interface {
+	bind.ContractCaller
+}
+
+// NewAuthController binds the AuthController of the given revision at the
+// contract address. Backends not able to transact and filter logs are bound
+// read-only.
+func NewAuthController(contractAddr common.Address, backend AuthBackend, version Version) (*AuthController, error) {
+	contractBackend, ok := backend.(bind.ContractBackend)
+	if !ok {
+		contractBackend = &readOnlyBackend{backend}
+	}
+	var (
+		binding Binding
+		err     error
+	)
+	switch version {
+	case V1:
+		binding, err = newV1Binding(contractAddr, contractBackend)
+	case V2:
+		binding, err = newV2Binding(contractAddr, contractBackend)
+	default:
+		return nil, fmt.Errorf("unsupported auth controller version %d", version)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &AuthController{Binding: binding, address: contractAddr, version: version}, nil
+}
+
+// ContractAddr returns the address of contract.
+func (ac *AuthController) ContractAddr() common.Address {
+	return ac.address
+}
+
+// Version returns the ABI revision the contract is bound with.
+func (ac *AuthController) Version() Version {
+	return ac.version
+}
+
+// v1Binding adapts the v1 generated binding to the Binding interface.
+type v1Binding struct {
+	*contract.AuthControllerV1
+}
+
+func newV1Binding(contractAddr common.Address, backend bind.ContractBackend) (*v1Binding, error) {
+	c, err := contract.NewAuthControllerV1(contractAddr, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &v1Binding{c}, nil
+}
+
+func (b *v1Binding) ParentAuth(opts *bind.CallOpts, parent common.Address, addr common.Address) (*AuthData, error) {
+	res, err := b.Parentauths(opts, parent, addr)
+	if err != nil {
+		return nil, err
+	}
+	return &AuthData{
+		Caddress:  res.Caddress,
+		Sender:    res.Sender,
+		Signature: res.Signature,
+		IsAuth:    res.IsAuth,
+	}, nil
+}
+
+func (b *v1Binding) ParseAuthentication(log types.Log) (*AuthData, error) {
+	event, err := b.AuthControllerV1.ParseAuthentication(log)
+	if err != nil {
+		return nil, err
+	}
+	return &AuthData{
+		Caddress:  event.Arg0.Caddress,
+		Sender:    event.Arg0.Sender,
+		Signature: event.Arg0.Signature,
+		IsAuth:    event.Arg0.IsAuth,
+	}, nil
+}
+
+// v2Binding adapts the v2 generated binding to the Binding interface.
+type v2Binding struct {
+	*contract.AuthController
+}
+
+func newV2Binding(contractAddr common.Address, backend bind.ContractBackend) (*v2Binding, error) {
+	c, err := contract.NewAuthController(contractAddr, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &v2Binding{c}, nil
+}
+
+func (b *v2Binding) ParentAuth(opts *bind.CallOpts, parent common.Address, addr common.Address) (*AuthData, error) {
+	res, err := b.Parentauths(opts, parent, addr)
+	if err != nil {
+		return nil, err
+	}
+	return &AuthData{
+		Caddress:   res.Caddress,
+		Sender:     res.Sender,
+		Signature:  res.Signature,
+		AuthTime:   res.AuthTime,
+		AuthExpiry: res.AuthExpiry,
+		IsAuth:     res.IsAuth,
+		AuthLevel:  res.AuthLevel,
+		ExpandData: res.ExpandData,
+	}, nil
+}
+
+func (b *v2Binding) ParseAuthentication(log types.Log) (*AuthData, error) {
+	event, err := b.AuthController.ParseAuthentication(log)
+	if err != nil {
+		return nil, err
+	}
+	return &AuthData{
+		Caddress:   event.Arg0.Caddress,
+		Sender:     event.Arg0.Sender,
+		Signature:  event.Arg0.Signature,
+		AuthTime:   event.Arg0.AuthTime,
+		AuthExpiry: event.Arg0.AuthExpiry,
+		IsAuth:     event.Arg0.IsAuth,
+		AuthLevel:  event.Arg0.AuthLevel,
+		ExpandData: event.Arg0.ExpandData,
+	}, nil
+}
@@ -0,0 +1,137 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package authcontroller
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/qydata/go-ctereum/accounts/abi/bind"
+	"github.com/qydata/go-ctereum/accounts/abi/bind/backends"
+	"github.com/qydata/go-ctereum/common"
+	"github.com/qydata/go-ctereum/contracts/authcontroller/contract"
+	"github.com/qydata/go-ctereum/core"
+	"github.com/qydata/go-ctereum/core/types"
+)
+
+// authenticationLog packs an Authentication event of the contract described by
+// the metadata.
+func authenticationLog(t *testing.T, meta *bind.MetaData, data interface{}, caddress common.Address) types.Log {
+	parsed, err := meta.GetAbi()
+	if err != nil {
+		t.Fatalf("failed to parse abi: %v", err)
+	}
+	event := parsed.Events["Authentication"]
+	packed, err := event.Inputs.NonIndexed().Pack(data)
+	if err != nil {
+		t.Fatalf("failed to pack event: %v", err)
+	}
+	return types.Log{
+		Topics: []common.Hash{event.ID, common.BytesToHash(caddress.Bytes())},
+		Data:   packed,
+	}
+}
+
+func TestParseAuthentication(t *testing.T) {
+	backend := backends.NewSimulatedBackend(core.GenesisAlloc{}, 10000000)
+	defer backend.Close()
+
+	var (
+		caddress = common.HexToAddress("0x01")
+		sender   = common.HexToAddress("0x02")
+	)
+	v1, err := NewAuthController(common.Address{}, backend, V1)
+	if err != nil {
+		t.Fatalf("failed to bind v1 contract: %v", err)
+	}
+	log := authenticationLog(t, contract.AuthControllerV1MetaData, contract.AuthControllerV1AuthData{
+		Caddress:  caddress,
+		Sender:    sender,
+		Signature: []byte{0x01, 0x02},
+		IsAuth:    true,
+	}, caddress)
+	have, err := v1.ParseAuthentication(log)
+	if err != nil {
+		t.Fatalf("failed to parse v1 event: %v", err)
+	}
+	want := &AuthData{Caddress: caddress, Sender: sender, Signature: []byte{0x01, 0x02}, IsAuth: true}
+	if !reflect.DeepEqual(have, want) {
+		t.Errorf("v1 record mismatch: have %+v, want %+v", have, want)
+	}
+	if have.Expired(0) || have.Level() != 0 {
+		t.Errorf("v1 record must not expire and has no level")
+	}
+
+	v2, err := NewAuthController(common.Address{}, backend, V2)
+	if err != nil {
+		t.Fatalf("failed to bind v2 contract: %v", err)
+	}
+	log = authenticationLog(t, contract.AuthControllerMetaData, contract.AuthControllerAuthData{
+		Caddress:   caddress,
+		Sender:     sender,
+		Signature:  []byte{0x03},
+		AuthTime:   big.NewInt(100),
+		AuthExpiry: big.NewInt(200),
+		IsAuth:     true,
+		AuthLevel:  big.NewInt(2),
+		ExpandData: "extra",
+	}, caddress)
+	have, err = v2.ParseAuthentication(log)
+	if err != nil {
+		t.Fatalf("failed to parse v2 event: %v", err)
+	}
+	want = &AuthData{
+		Caddress:   caddress,
+		Sender:     sender,
+		Signature:  []byte{0x03},
+		AuthTime:   big.NewInt(100),
+		AuthExpiry: big.NewInt(200),
+		IsAuth:     true,
+		AuthLevel:  big.NewInt(2),
+		ExpandData: "extra",
+	}
+	if !reflect.DeepEqual(have, want) {
+		t.Errorf("v2 record mismatch: have %+v, want %+v", have, want)
+	}
+	if have.Level() != 2 {
+		t.Errorf("level mismatch: have %d, want 2", have.Level())
+	}
+	if have.Expired(199) || !have.Expired(200) {
+		t.Errorf("expiry mismatch around %v", have.AuthExpiry)
+	}
+	// A v2 event does not decode with the v1 layout
+	if _, err := v1.ParseAuthentication(log); err == nil {
+		t.Errorf("expected v1 binding to reject a v2 event")
+	}
+}
+
+func TestNewAuthControllerVersion(t *testing.T) {
+	backend := backends.NewSimulatedBackend(core.GenesisAlloc{}, 10000000)
+	defer backend.Close()
+
+	if _, err := NewAuthController(common.Address{}, backend, Version(3)); err == nil {
+		t.Errorf("expected error for unknown version")
+	}
+	ac, err := NewAuthController(common.HexToAddress("0x10"), backend, V2)
+	if err != nil {
+		t.Fatalf("failed to bind contract: %v", err)
+	}
+	if ac.ContractAddr() != common.HexToAddress("0x10") || ac.Version() != V2 {
+		t.Errorf("binding mismatch: %x v%d", ac.ContractAddr(), ac.Version())
+	}
+}
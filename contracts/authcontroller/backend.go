@@ -0,0 +1,170 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package authcontroller
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/big"
+
+	ethereum "github.com/qydata/go-ctereum"
+	"github.com/qydata/go-ctereum/common"
+	"github.com/qydata/go-ctereum/core"
+	"github.com/qydata/go-ctereum/core/state"
+	"github.com/qydata/go-ctereum/core/types"
+	"github.com/qydata/go-ctereum/core/vm"
+	"github.com/qydata/go-ctereum/params"
+)
+
+var (
+	// ErrReadOnly is returned if a read-only binding is used to transact or to
+	// filter logs.
+	ErrReadOnly = errors.New("auth controller bound read-only")
+
+	// errUnknownBlock is returned if the block a call is executed at is not
+	// known to the local chain.
+	errUnknownBlock = errors.New("unknown block")
+)
+
+// readOnlyBackend completes an AuthBackend into a bind.ContractBackend that
+// refuses to transact and to filter logs.
+type readOnlyBackend struct {
+	AuthBackend
+}
+
+func (b *readOnlyBackend) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return nil, ErrReadOnly
+}
+
+func (b *readOnlyBackend) PendingCodeAt(ctx context.Context, account common.Address) ([]byte, error) {
+	return nil, ErrReadOnly
+}
+
+func (b *readOnlyBackend) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	return 0, ErrReadOnly
+}
+
+func (b *readOnlyBackend) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	return nil, ErrReadOnly
+}
+
+func (b *readOnlyBackend) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	return nil, ErrReadOnly
+}
+
+func (b *readOnlyBackend) EstimateGas(ctx context.Context, call ethereum.CallMsg) (uint64, error) {
+	return 0, ErrReadOnly
+}
+
+func (b *readOnlyBackend) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	return ErrReadOnly
+}
+
+func (b *readOnlyBackend) FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	return nil, ErrReadOnly
+}
+
+func (b *readOnlyBackend) SubscribeFilterLogs(ctx context.Context, query ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
+	return nil, ErrReadOnly
+}
+
+// LocalChain is the view of the local chain ChainBackend executes calls on,
+// such as a core.BlockChain.
+type LocalChain interface {
+	core.ChainContext
+
+	// Config retrieves the chain's fork configuration.
+	Config() *params.ChainConfig
+
+	// CurrentHeader retrieves the head header of the chain.
+	CurrentHeader() *types.Header
+
+	// GetHeaderByNumber retrieves a canonical header by number.
+	GetHeaderByNumber(number uint64) *types.Header
+
+	// StateAt retrieves the state with the given root.
+	StateAt(root common.Hash) (*state.StateDB, error)
+}
+
+// ChainBackend is an AuthBackend executing contract calls directly against the
+// state of the local chain.
+type ChainBackend struct {
+	chain LocalChain
+}
+
+// NewChainBackend creates an AuthBackend on top of the local chain.
+func NewChainBackend(chain LocalChain) *ChainBackend {
+	return &ChainBackend{chain: chain}
+}
+
+// CodeAt returns the code of the given account at the given block, or the
+// head if nil.
+func (b *ChainBackend) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	statedb, _, err := b.stateAt(blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	return statedb.GetCode(contract), nil
+}
+
+// CallContract executes a read-only call against the state at the given
+// block, or the head if nil.
+func (b *ChainBackend) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	statedb, header, err := b.stateAt(blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	gas := call.Gas
+	if gas == 0 {
+		gas = header.GasLimit
+	}
+	value := call.Value
+	if value == nil {
+		value = new(big.Int)
+	}
+	msg := types.NewMessage(call.From, call.To, 0, value, gas, new(big.Int), new(big.Int), new(big.Int), call.Data, call.AccessList, true)
+
+	evm := vm.NewEVM(core.NewEVMBlockContext(header, b.chain, nil), core.NewEVMTxContext(msg), statedb, b.chain.Config(), vm.Config{NoBaseFee: true})
+	result, err := core.ApplyMessage(evm, msg, new(core.GasPool).AddGas(math.MaxUint64))
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Revert()) > 0 {
+		return nil, result.Err
+	}
+	return result.Return(), result.Err
+}
+
+// stateAt returns the state and the header of the given block, or the head if
+// nil.
+func (b *ChainBackend) stateAt(blockNumber *big.Int) (*state.StateDB, *types.Header, error) {
+	var header *types.Header
+	if blockNumber == nil {
+		header = b.chain.CurrentHeader()
+	} else if blockNumber.IsUint64() {
+		header = b.chain.GetHeaderByNumber(blockNumber.Uint64())
+	}
+	if header == nil {
+		return nil, nil, errUnknownBlock
+	}
+	statedb, err := b.chain.StateAt(header.Root)
+	if err != nil {
+		return nil, nil, err
+	}
+	return statedb, header, nil
+}
@@ -0,0 +1,84 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package authcontroller
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/qydata/go-ctereum/accounts/abi/bind"
+	"github.com/qydata/go-ctereum/accounts/abi/bind/backends"
+	"github.com/qydata/go-ctereum/common"
+	"github.com/qydata/go-ctereum/consensus/ethash"
+	"github.com/qydata/go-ctereum/core"
+	"github.com/qydata/go-ctereum/core/rawdb"
+	"github.com/qydata/go-ctereum/core/vm"
+	"github.com/qydata/go-ctereum/params"
+)
+
+var (
+	_ LocalChain  = (*core.BlockChain)(nil)
+	_ AuthBackend = (*ChainBackend)(nil)
+	_ AuthBackend = (*backends.SimulatedBackend)(nil)
+)
+
+func TestChainBackend(t *testing.T) {
+	var (
+		contractAddr = common.HexToAddress("0xa0")
+		authed       = common.HexToAddress("0x01")
+		other        = common.HexToAddress("0x02")
+	)
+	db := rawdb.NewMemoryDatabase()
+	genesis := &core.Genesis{
+		Config: params.AllEthashProtocolChanges,
+		Alloc: core.GenesisAlloc{
+			contractAddr: {
+				Code:    lookupCode,
+				Balance: new(big.Int),
+				Storage: map[common.Hash]common.Hash{common.BytesToHash(authed.Bytes()): common.BigToHash(common.Big1)},
+			},
+		},
+		BaseFee: big.NewInt(params.InitialBaseFee),
+	}
+	genesis.MustCommit(db)
+
+	chain, err := core.NewBlockChain(db, nil, genesis.Config, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	defer chain.Stop()
+
+	ac, err := NewAuthController(contractAddr, NewChainBackend(chain), V2)
+	if err != nil {
+		t.Fatalf("failed to bind contract: %v", err)
+	}
+	for _, opts := range []*bind.CallOpts{nil, {BlockNumber: big.NewInt(0)}} {
+		if ok, err := ac.AuthsSingle(opts, authed); err != nil || !ok {
+			t.Errorf("authenticated address mismatch: have %v, %v", ok, err)
+		}
+		if ok, err := ac.AuthsSingle(opts, other); err != nil || ok {
+			t.Errorf("unauthenticated address mismatch: have %v, %v", ok, err)
+		}
+	}
+	if _, err := ac.AuthsSingle(&bind.CallOpts{BlockNumber: big.NewInt(1)}, authed); err != errUnknownBlock {
+		t.Errorf("unknown block error mismatch: have %v, want %v", err, errUnknownBlock)
+	}
+	// The local chain backend can only be used to read the contract
+	if _, err := ac.Binding.(*v2Binding).AddToWhitelist(&bind.TransactOpts{}, []common.Address{other}); err == nil {
+		t.Errorf("expected error transacting through a read-only binding")
+	}
+}
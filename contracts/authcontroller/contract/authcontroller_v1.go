@@ -0,0 +1,1183 @@
+// Code generated - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package contract
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+
+	ethereum "github.com/qydata/go-ctereum"
+	"github.com/qydata/go-ctereum/accounts/abi"
+	"github.com/qydata/go-ctereum/accounts/abi/bind"
+	"github.com/qydata/go-ctereum/common"
+	"github.com/qydata/go-ctereum/core/types"
+	"github.com/qydata/go-ctereum/event"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = errors.New
+	_ = big.NewInt
+	_ = strings.NewReader
+	_ = ethereum.NotFound
+	_ = bind.Bind
+	_ = common.Big1
+	_ = types.BloomLookup
+	_ = event.NewSubscription
+)
+
+// AuthControllerV1AuthData is an auto generated low-level Go binding around an user-defined struct.
+type AuthControllerV1AuthData struct {
+	Caddress  common.Address
+	Sender    common.Address
+	Signature []byte
+	IsAuth    bool
+}
+
+// AuthControllerV1MetaData contains all meta data concerning the AuthControllerV1 contract.
+var AuthControllerV1MetaData = &bind.MetaData{
+	ABI: "[{\"inputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"constructor\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":false,\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"}],\"name\":\"AddedToWhiteList\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"components\":[{\"internalType\":\"address\",\"name\":\"caddress\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"sender\",\"type\":\"address\"},{\"internalType\":\"bytes\",\"name\":\"signature\",\"type\":\"bytes\"},{\"internalType\":\"bool\",\"name\":\"isAuth\",\"type\":\"bool\"}],\"indexed\":false,\"internalType\":\"structAuthControllerV1.AuthData\",\"name\":\"\",\"type\":\"tuple\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"caddress\",\"type\":\"address\"}],\"name\":\"Authentication\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"address\",\"name\":\"previousOwner\",\"type\":\"address\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"newOwner\",\"type\":\"address\"}],\"name\":\"OwnershipTransferred\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":false,\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"}],\"name\":\"RemovedFromWhiteList\",\"type\":\"event\"},{\"inputs\":[],\"name\":\"AUTH_TYPEHASH\",\"outputs\":[{\"internalType\":\"bytes32\",\"name\":\"\",\"type\":\"bytes32\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address[]\",\"name\":\"_addresses\",\"type\":\"address[]\"}],\"name\":\"addToWhitelist\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"components\":[{\"internalType\":\"address\",\"name\":\"caddress\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"sender\",\"type\":\"address\"},{\"internalType\":\"bytes\",\"name\":\"signature\",\"type\":\"bytes\"},{\"internalType\":\"bool\",\"name\":\"isAuth\",\"type\":\"bool\"}],\"internalType\":\"structAuthControllerV1.AuthData\",\"name\":\"auth\",\"type\":\"tuple\"},{\"internalType\":\"uint256\",\"name\":\"orderId\",\"type\":\"uint256\"}],\"name\":\"authentication\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"components\":[{\"internalType\":\"address\",\"name\":\"caddress\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"sender\",\"type\":\"address\"},{\"internalType\":\"bytes\",\"name\":\"signature\",\"type\":\"bytes\"},{\"internalType\":\"bool\",\"name\":\"isAuth\",\"type\":\"bool\"}],\"internalType\":\"structAuthControllerV1.AuthData[]\",\"name\":\"auths\",\"type\":\"tuple[]\"},{\"internalType\":\"uint256[]\",\"name\":\"orderIds\",\"type\":\"uint256[]\"}],\"name\":\"authenticationBetch\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"}],\"name\":\"auths\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"addr\",\"type\":\"address\"}],\"name\":\"authsSingle\",\"outputs\":[{\"internalType\":\"bool\",\"name\":\"isAuth\",\"type\":\"bool\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"getWhitelist\",\"outputs\":[{\"internalType\":\"address[]\",\"name\":\"list\",\"type\":\"address[]\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"name\":\"orders\",\"outputs\":[{\"internalType\":\"bool\",\"name\":\"\",\"type\":\"bool\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"owner\",\"outputs\":[{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"}],\"name\":\"parentauths\",\"outputs\":[{\"internalType\":\"address\",\"name\":\"caddress\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"sender\",\"type\":\"address\"},{\"internalType\":\"bytes\",\"name\":\"signature\",\"type\":\"bytes\"},{\"internalType\":\"bool\",\"name\":\"isAuth\",\"type\":\"bool\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"name\":\"parentauthsa\",\"outputs\":[{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address[]\",\"name\":\"_addresses\",\"type\":\"address[]\"}],\"name\":\"removeFromWhitelist\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"renounceOwnership\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"newOwner\",\"type\":\"address\"}],\"name\":\"transferOwnership\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"_address\",\"type\":\"address\"}],\"name\":\"whitelisted\",\"outputs\":[{\"internalType\":\"bool\",\"name\":\"\",\"type\":\"bool\"}],\"stateMutability\":\"view\",\"type\":\"function\"}]",
+}
+
+// AuthControllerV1ABI is the input ABI used to generate the binding from.
+// Deprecated: Use AuthControllerV1MetaData.ABI instead.
+var AuthControllerV1ABI = AuthControllerV1MetaData.ABI
+
+// AuthControllerV1 is an auto generated Go binding around an Ethereum contract.
+type AuthControllerV1 struct {
+	AuthControllerV1Caller     // Read-only binding to the contract
+	AuthControllerV1Transactor // Write-only binding to the contract
+	AuthControllerV1Filterer   // Log filterer for contract events
+}
+
+// AuthControllerV1Caller is an auto generated read-only Go binding around an Ethereum contract.
+type AuthControllerV1Caller struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// AuthControllerV1Transactor is an auto generated write-only Go binding around an Ethereum contract.
+type AuthControllerV1Transactor struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// AuthControllerV1Filterer is an auto generated log filtering Go binding around an Ethereum contract events.
+type AuthControllerV1Filterer struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// AuthControllerV1Session is an auto generated Go binding around an Ethereum contract,
+// with pre-set call and transact options.
+type AuthControllerV1Session struct {
+	Contract     *AuthControllerV1 // Generic contract binding to set the session for
+	CallOpts     bind.CallOpts     // Call options to use throughout this session
+	TransactOpts bind.TransactOpts // Transaction auth options to use throughout this session
+}
+
+// AuthControllerV1CallerSession is an auto generated read-only Go binding around an Ethereum contract,
+// with pre-set call options.
+type AuthControllerV1CallerSession struct {
+	Contract *AuthControllerV1Caller // Generic contract caller binding to set the session for
+	CallOpts bind.CallOpts           // Call options to use throughout this session
+}
+
+// AuthControllerV1TransactorSession is an auto generated write-only Go binding around an Ethereum contract,
+// with pre-set transact options.
+type AuthControllerV1TransactorSession struct {
+	Contract     *AuthControllerV1Transactor // Generic contract transactor binding to set the session for
+	TransactOpts bind.TransactOpts           // Transaction auth options to use throughout this session
+}
+
+// AuthControllerV1Raw is an auto generated low-level Go binding around an Ethereum contract.
+type AuthControllerV1Raw struct {
+	Contract *AuthControllerV1 // Generic contract binding to access the raw methods on
+}
+
+// AuthControllerV1CallerRaw is an auto generated low-level read-only Go binding around an Ethereum contract.
+type AuthControllerV1CallerRaw struct {
+	Contract *AuthControllerV1Caller // Generic read-only contract binding to access the raw methods on
+}
+
+// AuthControllerV1TransactorRaw is an auto generated low-level write-only Go binding around an Ethereum contract.
+type AuthControllerV1TransactorRaw struct {
+	Contract *AuthControllerV1Transactor // Generic write-only contract binding to access the raw methods on
+}
+
+// NewAuthControllerV1 creates a new instance of AuthControllerV1, bound to a specific deployed contract.
+func NewAuthControllerV1(address common.Address, backend bind.ContractBackend) (*AuthControllerV1, error) {
+	contract, err := bindAuthControllerV1(address, backend, backend, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &AuthControllerV1{AuthControllerV1Caller: AuthControllerV1Caller{contract: contract}, AuthControllerV1Transactor: AuthControllerV1Transactor{contract: contract}, AuthControllerV1Filterer: AuthControllerV1Filterer{contract: contract}}, nil
+}
+
+// NewAuthControllerV1Caller creates a new read-only instance of AuthControllerV1, bound to a specific deployed contract.
+func NewAuthControllerV1Caller(address common.Address, caller bind.ContractCaller) (*AuthControllerV1Caller, error) {
+	contract, err := bindAuthControllerV1(address, caller, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &AuthControllerV1Caller{contract: contract}, nil
+}
+
+// NewAuthControllerV1Transactor creates a new write-only instance of AuthControllerV1, bound to a specific deployed contract.
+func NewAuthControllerV1Transactor(address common.Address, transactor bind.ContractTransactor) (*AuthControllerV1Transactor, error) {
+	contract, err := bindAuthControllerV1(address, nil, transactor, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &AuthControllerV1Transactor{contract: contract}, nil
+}
+
+// NewAuthControllerV1Filterer creates a new log filterer instance of AuthControllerV1, bound to a specific deployed contract.
+func NewAuthControllerV1Filterer(address common.Address, filterer bind.ContractFilterer) (*AuthControllerV1Filterer, error) {
+	contract, err := bindAuthControllerV1(address, nil, nil, filterer)
+	if err != nil {
+		return nil, err
+	}
+	return &AuthControllerV1Filterer{contract: contract}, nil
+}
+
+// bindAuthControllerV1 binds a generic wrapper to an already deployed contract.
+func bindAuthControllerV1(address common.Address, caller bind.ContractCaller, transactor bind.ContractTransactor, filterer bind.ContractFilterer) (*bind.BoundContract, error) {
+	parsed, err := abi.JSON(strings.NewReader(AuthControllerV1ABI))
+	if err != nil {
+		return nil, err
+	}
+	return bind.NewBoundContract(address, parsed, caller, transactor, filterer), nil
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_AuthControllerV1 *AuthControllerV1Raw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _AuthControllerV1.Contract.AuthControllerV1Caller.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_AuthControllerV1 *AuthControllerV1Raw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _AuthControllerV1.Contract.AuthControllerV1Transactor.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_AuthControllerV1 *AuthControllerV1Raw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _AuthControllerV1.Contract.AuthControllerV1Transactor.contract.Transact(opts, method, params...)
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_AuthControllerV1 *AuthControllerV1CallerRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _AuthControllerV1.Contract.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_AuthControllerV1 *AuthControllerV1TransactorRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _AuthControllerV1.Contract.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_AuthControllerV1 *AuthControllerV1TransactorRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _AuthControllerV1.Contract.contract.Transact(opts, method, params...)
+}
+
+// AUTHTYPEHASH is a free data retrieval call binding the contract method 0x5110ee86.
+//
+// Solidity: function AUTH_TYPEHASH() view returns(bytes32)
+func (_AuthControllerV1 *AuthControllerV1Caller) AUTHTYPEHASH(opts *bind.CallOpts) ([32]byte, error) {
+	var out []interface{}
+	err := _AuthControllerV1.contract.Call(opts, &out, "AUTH_TYPEHASH")
+
+	if err != nil {
+		return *new([32]byte), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new([32]byte)).(*[32]byte)
+
+	return out0, err
+
+}
+
+// AUTHTYPEHASH is a free data retrieval call binding the contract method 0x5110ee86.
+//
+// Solidity: function AUTH_TYPEHASH() view returns(bytes32)
+func (_AuthControllerV1 *AuthControllerV1Session) AUTHTYPEHASH() ([32]byte, error) {
+	return _AuthControllerV1.Contract.AUTHTYPEHASH(&_AuthControllerV1.CallOpts)
+}
+
+// AUTHTYPEHASH is a free data retrieval call binding the contract method 0x5110ee86.
+//
+// Solidity: function AUTH_TYPEHASH() view returns(bytes32)
+func (_AuthControllerV1 *AuthControllerV1CallerSession) AUTHTYPEHASH() ([32]byte, error) {
+	return _AuthControllerV1.Contract.AUTHTYPEHASH(&_AuthControllerV1.CallOpts)
+}
+
+// Auths is a free data retrieval call binding the contract method 0x64e72dbd.
+//
+// Solidity: function auths(address ) view returns(uint256)
+func (_AuthControllerV1 *AuthControllerV1Caller) Auths(opts *bind.CallOpts, arg0 common.Address) (*big.Int, error) {
+	var out []interface{}
+	err := _AuthControllerV1.contract.Call(opts, &out, "auths", arg0)
+
+	if err != nil {
+		return *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	return out0, err
+
+}
+
+// Auths is a free data retrieval call binding the contract method 0x64e72dbd.
+//
+// Solidity: function auths(address ) view returns(uint256)
+func (_AuthControllerV1 *AuthControllerV1Session) Auths(arg0 common.Address) (*big.Int, error) {
+	return _AuthControllerV1.Contract.Auths(&_AuthControllerV1.CallOpts, arg0)
+}
+
+// Auths is a free data retrieval call binding the contract method 0x64e72dbd.
+//
+// Solidity: function auths(address ) view returns(uint256)
+func (_AuthControllerV1 *AuthControllerV1CallerSession) Auths(arg0 common.Address) (*big.Int, error) {
+	return _AuthControllerV1.Contract.Auths(&_AuthControllerV1.CallOpts, arg0)
+}
+
+// AuthsSingle is a free data retrieval call binding the contract method 0x5caf8667.
+//
+// Solidity: function authsSingle(address addr) view returns(bool isAuth)
+func (_AuthControllerV1 *AuthControllerV1Caller) AuthsSingle(opts *bind.CallOpts, addr common.Address) (bool, error) {
+	var out []interface{}
+	err := _AuthControllerV1.contract.Call(opts, &out, "authsSingle", addr)
+
+	if err != nil {
+		return *new(bool), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(bool)).(*bool)
+
+	return out0, err
+
+}
+
+// AuthsSingle is a free data retrieval call binding the contract method 0x5caf8667.
+//
+// Solidity: function authsSingle(address addr) view returns(bool isAuth)
+func (_AuthControllerV1 *AuthControllerV1Session) AuthsSingle(addr common.Address) (bool, error) {
+	return _AuthControllerV1.Contract.AuthsSingle(&_AuthControllerV1.CallOpts, addr)
+}
+
+// AuthsSingle is a free data retrieval call binding the contract method 0x5caf8667.
+//
+// Solidity: function authsSingle(address addr) view returns(bool isAuth)
+func (_AuthControllerV1 *AuthControllerV1CallerSession) AuthsSingle(addr common.Address) (bool, error) {
+	return _AuthControllerV1.Contract.AuthsSingle(&_AuthControllerV1.CallOpts, addr)
+}
+
+// GetWhitelist is a free data retrieval call binding the contract method 0xd01f63f5.
+//
+// Solidity: function getWhitelist() view returns(address[] list)
+func (_AuthControllerV1 *AuthControllerV1Caller) GetWhitelist(opts *bind.CallOpts) ([]common.Address, error) {
+	var out []interface{}
+	err := _AuthControllerV1.contract.Call(opts, &out, "getWhitelist")
+
+	if err != nil {
+		return *new([]common.Address), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new([]common.Address)).(*[]common.Address)
+
+	return out0, err
+
+}
+
+// GetWhitelist is a free data retrieval call binding the contract method 0xd01f63f5.
+//
+// Solidity: function getWhitelist() view returns(address[] list)
+func (_AuthControllerV1 *AuthControllerV1Session) GetWhitelist() ([]common.Address, error) {
+	return _AuthControllerV1.Contract.GetWhitelist(&_AuthControllerV1.CallOpts)
+}
+
+// GetWhitelist is a free data retrieval call binding the contract method 0xd01f63f5.
+//
+// Solidity: function getWhitelist() view returns(address[] list)
+func (_AuthControllerV1 *AuthControllerV1CallerSession) GetWhitelist() ([]common.Address, error) {
+	return _AuthControllerV1.Contract.GetWhitelist(&_AuthControllerV1.CallOpts)
+}
+
+// Orders is a free data retrieval call binding the contract method 0xa85c38ef.
+//
+// Solidity: function orders(uint256 ) view returns(bool)
+func (_AuthControllerV1 *AuthControllerV1Caller) Orders(opts *bind.CallOpts, arg0 *big.Int) (bool, error) {
+	var out []interface{}
+	err := _AuthControllerV1.contract.Call(opts, &out, "orders", arg0)
+
+	if err != nil {
+		return *new(bool), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(bool)).(*bool)
+
+	return out0, err
+
+}
+
+// Orders is a free data retrieval call binding the contract method 0xa85c38ef.
+//
+// Solidity: function orders(uint256 ) view returns(bool)
+func (_AuthControllerV1 *AuthControllerV1Session) Orders(arg0 *big.Int) (bool, error) {
+	return _AuthControllerV1.Contract.Orders(&_AuthControllerV1.CallOpts, arg0)
+}
+
+// Orders is a free data retrieval call binding the contract method 0xa85c38ef.
+//
+// Solidity: function orders(uint256 ) view returns(bool)
+func (_AuthControllerV1 *AuthControllerV1CallerSession) Orders(arg0 *big.Int) (bool, error) {
+	return _AuthControllerV1.Contract.Orders(&_AuthControllerV1.CallOpts, arg0)
+}
+
+// Owner is a free data retrieval call binding the contract method 0x8da5cb5b.
+//
+// Solidity: function owner() view returns(address)
+func (_AuthControllerV1 *AuthControllerV1Caller) Owner(opts *bind.CallOpts) (common.Address, error) {
+	var out []interface{}
+	err := _AuthControllerV1.contract.Call(opts, &out, "owner")
+
+	if err != nil {
+		return *new(common.Address), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(common.Address)).(*common.Address)
+
+	return out0, err
+
+}
+
+// Owner is a free data retrieval call binding the contract method 0x8da5cb5b.
+//
+// Solidity: function owner() view returns(address)
+func (_AuthControllerV1 *AuthControllerV1Session) Owner() (common.Address, error) {
+	return _AuthControllerV1.Contract.Owner(&_AuthControllerV1.CallOpts)
+}
+
+// Owner is a free data retrieval call binding the contract method 0x8da5cb5b.
+//
+// Solidity: function owner() view returns(address)
+func (_AuthControllerV1 *AuthControllerV1CallerSession) Owner() (common.Address, error) {
+	return _AuthControllerV1.Contract.Owner(&_AuthControllerV1.CallOpts)
+}
+
+// Parentauths is a free data retrieval call binding the contract method 0x59030c74.
+//
+// Solidity: function parentauths(address , address ) view returns(address caddress, address sender, bytes signature, bool isAuth)
+func (_AuthControllerV1 *AuthControllerV1Caller) Parentauths(opts *bind.CallOpts, arg0 common.Address, arg1 common.Address) (struct {
+	Caddress  common.Address
+	Sender    common.Address
+	Signature []byte
+	IsAuth    bool
+}, error) {
+	var out []interface{}
+	err := _AuthControllerV1.contract.Call(opts, &out, "parentauths", arg0, arg1)
+
+	outstruct := new(struct {
+		Caddress  common.Address
+		Sender    common.Address
+		Signature []byte
+		IsAuth    bool
+	})
+	if err != nil {
+		return *outstruct, err
+	}
+
+	outstruct.Caddress = *abi.ConvertType(out[0], new(common.Address)).(*common.Address)
+	outstruct.Sender = *abi.ConvertType(out[1], new(common.Address)).(*common.Address)
+	outstruct.Signature = *abi.ConvertType(out[2], new([]byte)).(*[]byte)
+	outstruct.IsAuth = *abi.ConvertType(out[3], new(bool)).(*bool)
+
+	return *outstruct, err
+
+}
+
+// Parentauths is a free data retrieval call binding the contract method 0x59030c74.
+//
+// Solidity: function parentauths(address , address ) view returns(address caddress, address sender, bytes signature, bool isAuth)
+func (_AuthControllerV1 *AuthControllerV1Session) Parentauths(arg0 common.Address, arg1 common.Address) (struct {
+	Caddress  common.Address
+	Sender    common.Address
+	Signature []byte
+	IsAuth    bool
+}, error) {
+	return _AuthControllerV1.Contract.Parentauths(&_AuthControllerV1.CallOpts, arg0, arg1)
+}
+
+// Parentauths is a free data retrieval call binding the contract method 0x59030c74.
+//
+// Solidity: function parentauths(address , address ) view returns(address caddress, address sender, bytes signature, bool isAuth)
+func (_AuthControllerV1 *AuthControllerV1CallerSession) Parentauths(arg0 common.Address, arg1 common.Address) (struct {
+	Caddress  common.Address
+	Sender    common.Address
+	Signature []byte
+	IsAuth    bool
+}, error) {
+	return _AuthControllerV1.Contract.Parentauths(&_AuthControllerV1.CallOpts, arg0, arg1)
+}
+
+// Parentauthsa is a free data retrieval call binding the contract method 0x62f6c8b5.
+//
+// Solidity: function parentauthsa(address , uint256 ) view returns(address)
+func (_AuthControllerV1 *AuthControllerV1Caller) Parentauthsa(opts *bind.CallOpts, arg0 common.Address, arg1 *big.Int) (common.Address, error) {
+	var out []interface{}
+	err := _AuthControllerV1.contract.Call(opts, &out, "parentauthsa", arg0, arg1)
+
+	if err != nil {
+		return *new(common.Address), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(common.Address)).(*common.Address)
+
+	return out0, err
+
+}
+
+// Parentauthsa is a free data retrieval call binding the contract method 0x62f6c8b5.
+//
+// Solidity: function parentauthsa(address , uint256 ) view returns(address)
+func (_AuthControllerV1 *AuthControllerV1Session) Parentauthsa(arg0 common.Address, arg1 *big.Int) (common.Address, error) {
+	return _AuthControllerV1.Contract.Parentauthsa(&_AuthControllerV1.CallOpts, arg0, arg1)
+}
+
+// Parentauthsa is a free data retrieval call binding the contract method 0x62f6c8b5.
+//
+// Solidity: function parentauthsa(address , uint256 ) view returns(address)
+func (_AuthControllerV1 *AuthControllerV1CallerSession) Parentauthsa(arg0 common.Address, arg1 *big.Int) (common.Address, error) {
+	return _AuthControllerV1.Contract.Parentauthsa(&_AuthControllerV1.CallOpts, arg0, arg1)
+}
+
+// Whitelisted is a free data retrieval call binding the contract method 0xd936547e.
+//
+// Solidity: function whitelisted(address _address) view returns(bool)
+func (_AuthControllerV1 *AuthControllerV1Caller) Whitelisted(opts *bind.CallOpts, _address common.Address) (bool, error) {
+	var out []interface{}
+	err := _AuthControllerV1.contract.Call(opts, &out, "whitelisted", _address)
+
+	if err != nil {
+		return *new(bool), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(bool)).(*bool)
+
+	return out0, err
+
+}
+
+// Whitelisted is a free data retrieval call binding the contract method 0xd936547e.
+//
+// Solidity: function whitelisted(address _address) view returns(bool)
+func (_AuthControllerV1 *AuthControllerV1Session) Whitelisted(_address common.Address) (bool, error) {
+	return _AuthControllerV1.Contract.Whitelisted(&_AuthControllerV1.CallOpts, _address)
+}
+
+// Whitelisted is a free data retrieval call binding the contract method 0xd936547e.
+//
+// Solidity: function whitelisted(address _address) view returns(bool)
+func (_AuthControllerV1 *AuthControllerV1CallerSession) Whitelisted(_address common.Address) (bool, error) {
+	return _AuthControllerV1.Contract.Whitelisted(&_AuthControllerV1.CallOpts, _address)
+}
+
+// AddToWhitelist is a paid mutator transaction binding the contract method 0x7f649783.
+//
+// Solidity: function addToWhitelist(address[] _addresses) returns()
+func (_AuthControllerV1 *AuthControllerV1Transactor) AddToWhitelist(opts *bind.TransactOpts, _addresses []common.Address) (*types.Transaction, error) {
+	return _AuthControllerV1.contract.Transact(opts, "addToWhitelist", _addresses)
+}
+
+// AddToWhitelist is a paid mutator transaction binding the contract method 0x7f649783.
+//
+// Solidity: function addToWhitelist(address[] _addresses) returns()
+func (_AuthControllerV1 *AuthControllerV1Session) AddToWhitelist(_addresses []common.Address) (*types.Transaction, error) {
+	return _AuthControllerV1.Contract.AddToWhitelist(&_AuthControllerV1.TransactOpts, _addresses)
+}
+
+// AddToWhitelist is a paid mutator transaction binding the contract method 0x7f649783.
+//
+// Solidity: function addToWhitelist(address[] _addresses) returns()
+func (_AuthControllerV1 *AuthControllerV1TransactorSession) AddToWhitelist(_addresses []common.Address) (*types.Transaction, error) {
+	return _AuthControllerV1.Contract.AddToWhitelist(&_AuthControllerV1.TransactOpts, _addresses)
+}
+
+// Authentication is a paid mutator transaction binding the contract method 0x1272eb1a.
+//
+// Solidity: function authentication((address,address,bytes,bool) auth, uint256 orderId) returns()
+func (_AuthControllerV1 *AuthControllerV1Transactor) Authentication(opts *bind.TransactOpts, auth AuthControllerV1AuthData, orderId *big.Int) (*types.Transaction, error) {
+	return _AuthControllerV1.contract.Transact(opts, "authentication", auth, orderId)
+}
+
+// Authentication is a paid mutator transaction binding the contract method 0x1272eb1a.
+//
+// Solidity: function authentication((address,address,bytes,bool) auth, uint256 orderId) returns()
+func (_AuthControllerV1 *AuthControllerV1Session) Authentication(auth AuthControllerV1AuthData, orderId *big.Int) (*types.Transaction, error) {
+	return _AuthControllerV1.Contract.Authentication(&_AuthControllerV1.TransactOpts, auth, orderId)
+}
+
+// Authentication is a paid mutator transaction binding the contract method 0x1272eb1a.
+//
+// Solidity: function authentication((address,address,bytes,bool) auth, uint256 orderId) returns()
+func (_AuthControllerV1 *AuthControllerV1TransactorSession) Authentication(auth AuthControllerV1AuthData, orderId *big.Int) (*types.Transaction, error) {
+	return _AuthControllerV1.Contract.Authentication(&_AuthControllerV1.TransactOpts, auth, orderId)
+}
+
+// AuthenticationBetch is a paid mutator transaction binding the contract method 0xd7e6a1b8.
+//
+// Solidity: function authenticationBetch((address,address,bytes,bool)[] auths, uint256[] orderIds) returns()
+func (_AuthControllerV1 *AuthControllerV1Transactor) AuthenticationBetch(opts *bind.TransactOpts, auths []AuthControllerV1AuthData, orderIds []*big.Int) (*types.Transaction, error) {
+	return _AuthControllerV1.contract.Transact(opts, "authenticationBetch", auths, orderIds)
+}
+
+// AuthenticationBetch is a paid mutator transaction binding the contract method 0xd7e6a1b8.
+//
+// Solidity: function authenticationBetch((address,address,bytes,bool)[] auths, uint256[] orderIds) returns()
+func (_AuthControllerV1 *AuthControllerV1Session) AuthenticationBetch(auths []AuthControllerV1AuthData, orderIds []*big.Int) (*types.Transaction, error) {
+	return _AuthControllerV1.Contract.AuthenticationBetch(&_AuthControllerV1.TransactOpts, auths, orderIds)
+}
+
+// AuthenticationBetch is a paid mutator transaction binding the contract method 0xd7e6a1b8.
+//
+// Solidity: function authenticationBetch((address,address,bytes,bool)[] auths, uint256[] orderIds) returns()
+func (_AuthControllerV1 *AuthControllerV1TransactorSession) AuthenticationBetch(auths []AuthControllerV1AuthData, orderIds []*big.Int) (*types.Transaction, error) {
+	return _AuthControllerV1.Contract.AuthenticationBetch(&_AuthControllerV1.TransactOpts, auths, orderIds)
+}
+
+// RemoveFromWhitelist is a paid mutator transaction binding the contract method 0x548db174.
+//
+// Solidity: function removeFromWhitelist(address[] _addresses) returns()
+func (_AuthControllerV1 *AuthControllerV1Transactor) RemoveFromWhitelist(opts *bind.TransactOpts, _addresses []common.Address) (*types.Transaction, error) {
+	return _AuthControllerV1.contract.Transact(opts, "removeFromWhitelist", _addresses)
+}
+
+// RemoveFromWhitelist is a paid mutator transaction binding the contract method 0x548db174.
+//
+// Solidity: function removeFromWhitelist(address[] _addresses) returns()
+func (_AuthControllerV1 *AuthControllerV1Session) RemoveFromWhitelist(_addresses []common.Address) (*types.Transaction, error) {
+	return _AuthControllerV1.Contract.RemoveFromWhitelist(&_AuthControllerV1.TransactOpts, _addresses)
+}
+
+// RemoveFromWhitelist is a paid mutator transaction binding the contract method 0x548db174.
+//
+// Solidity: function removeFromWhitelist(address[] _addresses) returns()
+func (_AuthControllerV1 *AuthControllerV1TransactorSession) RemoveFromWhitelist(_addresses []common.Address) (*types.Transaction, error) {
+	return _AuthControllerV1.Contract.RemoveFromWhitelist(&_AuthControllerV1.TransactOpts, _addresses)
+}
+
+// RenounceOwnership is a paid mutator transaction binding the contract method 0x715018a6.
+//
+// Solidity: function renounceOwnership() returns()
+func (_AuthControllerV1 *AuthControllerV1Transactor) RenounceOwnership(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _AuthControllerV1.contract.Transact(opts, "renounceOwnership")
+}
+
+// RenounceOwnership is a paid mutator transaction binding the contract method 0x715018a6.
+//
+// Solidity: function renounceOwnership() returns()
+func (_AuthControllerV1 *AuthControllerV1Session) RenounceOwnership() (*types.Transaction, error) {
+	return _AuthControllerV1.Contract.RenounceOwnership(&_AuthControllerV1.TransactOpts)
+}
+
+// RenounceOwnership is a paid mutator transaction binding the contract method 0x715018a6.
+//
+// Solidity: function renounceOwnership() returns()
+func (_AuthControllerV1 *AuthControllerV1TransactorSession) RenounceOwnership() (*types.Transaction, error) {
+	return _AuthControllerV1.Contract.RenounceOwnership(&_AuthControllerV1.TransactOpts)
+}
+
+// TransferOwnership is a paid mutator transaction binding the contract method 0xf2fde38b.
+//
+// Solidity: function transferOwnership(address newOwner) returns()
+func (_AuthControllerV1 *AuthControllerV1Transactor) TransferOwnership(opts *bind.TransactOpts, newOwner common.Address) (*types.Transaction, error) {
+	return _AuthControllerV1.contract.Transact(opts, "transferOwnership", newOwner)
+}
+
+// TransferOwnership is a paid mutator transaction binding the contract method 0xf2fde38b.
+//
+// Solidity: function transferOwnership(address newOwner) returns()
+func (_AuthControllerV1 *AuthControllerV1Session) TransferOwnership(newOwner common.Address) (*types.Transaction, error) {
+	return _AuthControllerV1.Contract.TransferOwnership(&_AuthControllerV1.TransactOpts, newOwner)
+}
+
+// TransferOwnership is a paid mutator transaction binding the contract method 0xf2fde38b.
+//
+// Solidity: function transferOwnership(address newOwner) returns()
+func (_AuthControllerV1 *AuthControllerV1TransactorSession) TransferOwnership(newOwner common.Address) (*types.Transaction, error) {
+	return _AuthControllerV1.Contract.TransferOwnership(&_AuthControllerV1.TransactOpts, newOwner)
+}
+
+// AuthControllerV1AddedToWhiteListIterator is returned from FilterAddedToWhiteList and is used to iterate over the raw logs and unpacked data for AddedToWhiteList events raised by the AuthControllerV1 contract.
+type AuthControllerV1AddedToWhiteListIterator struct {
+	Event *AuthControllerV1AddedToWhiteList // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *AuthControllerV1AddedToWhiteListIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(AuthControllerV1AddedToWhiteList)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(AuthControllerV1AddedToWhiteList)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *AuthControllerV1AddedToWhiteListIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *AuthControllerV1AddedToWhiteListIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// AuthControllerV1AddedToWhiteList represents a AddedToWhiteList event raised by the AuthControllerV1 contract.
+type AuthControllerV1AddedToWhiteList struct {
+	Arg0 common.Address
+	Raw  types.Log // Blockchain specific contextual infos
+}
+
+// FilterAddedToWhiteList is a free log retrieval operation binding the contract event 0x8a3be376fdc726be3f3cee8e59ba5698a268a9b59f69cdabcf06d2ec2c90658f.
+//
+// Solidity: event AddedToWhiteList(address arg0)
+func (_AuthControllerV1 *AuthControllerV1Filterer) FilterAddedToWhiteList(opts *bind.FilterOpts) (*AuthControllerV1AddedToWhiteListIterator, error) {
+
+	logs, sub, err := _AuthControllerV1.contract.FilterLogs(opts, "AddedToWhiteList")
+	if err != nil {
+		return nil, err
+	}
+	return &AuthControllerV1AddedToWhiteListIterator{contract: _AuthControllerV1.contract, event: "AddedToWhiteList", logs: logs, sub: sub}, nil
+}
+
+// WatchAddedToWhiteList is a free log subscription operation binding the contract event 0x8a3be376fdc726be3f3cee8e59ba5698a268a9b59f69cdabcf06d2ec2c90658f.
+//
+// Solidity: event AddedToWhiteList(address arg0)
+func (_AuthControllerV1 *AuthControllerV1Filterer) WatchAddedToWhiteList(opts *bind.WatchOpts, sink chan<- *AuthControllerV1AddedToWhiteList) (event.Subscription, error) {
+
+	logs, sub, err := _AuthControllerV1.contract.WatchLogs(opts, "AddedToWhiteList")
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(AuthControllerV1AddedToWhiteList)
+				if err := _AuthControllerV1.contract.UnpackLog(event, "AddedToWhiteList", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseAddedToWhiteList is a log parse operation binding the contract event 0x8a3be376fdc726be3f3cee8e59ba5698a268a9b59f69cdabcf06d2ec2c90658f.
+//
+// Solidity: event AddedToWhiteList(address arg0)
+func (_AuthControllerV1 *AuthControllerV1Filterer) ParseAddedToWhiteList(log types.Log) (*AuthControllerV1AddedToWhiteList, error) {
+	event := new(AuthControllerV1AddedToWhiteList)
+	if err := _AuthControllerV1.contract.UnpackLog(event, "AddedToWhiteList", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
+
+// AuthControllerV1AuthenticationIterator is returned from FilterAuthentication and is used to iterate over the raw logs and unpacked data for Authentication events raised by the AuthControllerV1 contract.
+type AuthControllerV1AuthenticationIterator struct {
+	Event *AuthControllerV1Authentication // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *AuthControllerV1AuthenticationIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(AuthControllerV1Authentication)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(AuthControllerV1Authentication)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *AuthControllerV1AuthenticationIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *AuthControllerV1AuthenticationIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// AuthControllerV1Authentication represents a Authentication event raised by the AuthControllerV1 contract.
+type AuthControllerV1Authentication struct {
+	Arg0     AuthControllerV1AuthData
+	Caddress common.Address
+	Raw      types.Log // Blockchain specific contextual infos
+}
+
+// FilterAuthentication is a free log retrieval operation binding the contract event 0x9e3ca33193c0871844181f99d50f1628d2db9194b5d381f11fa7ac693b6f36f9.
+//
+// Solidity: event Authentication((address,address,bytes,bool) arg0, address indexed caddress)
+func (_AuthControllerV1 *AuthControllerV1Filterer) FilterAuthentication(opts *bind.FilterOpts, caddress []common.Address) (*AuthControllerV1AuthenticationIterator, error) {
+
+	var caddressRule []interface{}
+	for _, caddressItem := range caddress {
+		caddressRule = append(caddressRule, caddressItem)
+	}
+
+	logs, sub, err := _AuthControllerV1.contract.FilterLogs(opts, "Authentication", caddressRule)
+	if err != nil {
+		return nil, err
+	}
+	return &AuthControllerV1AuthenticationIterator{contract: _AuthControllerV1.contract, event: "Authentication", logs: logs, sub: sub}, nil
+}
+
+// WatchAuthentication is a free log subscription operation binding the contract event 0x9e3ca33193c0871844181f99d50f1628d2db9194b5d381f11fa7ac693b6f36f9.
+//
+// Solidity: event Authentication((address,address,bytes,bool) arg0, address indexed caddress)
+func (_AuthControllerV1 *AuthControllerV1Filterer) WatchAuthentication(opts *bind.WatchOpts, sink chan<- *AuthControllerV1Authentication, caddress []common.Address) (event.Subscription, error) {
+
+	var caddressRule []interface{}
+	for _, caddressItem := range caddress {
+		caddressRule = append(caddressRule, caddressItem)
+	}
+
+	logs, sub, err := _AuthControllerV1.contract.WatchLogs(opts, "Authentication", caddressRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(AuthControllerV1Authentication)
+				if err := _AuthControllerV1.contract.UnpackLog(event, "Authentication", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseAuthentication is a log parse operation binding the contract event 0x9e3ca33193c0871844181f99d50f1628d2db9194b5d381f11fa7ac693b6f36f9.
+//
+// Solidity: event Authentication((address,address,bytes,bool) arg0, address indexed caddress)
+func (_AuthControllerV1 *AuthControllerV1Filterer) ParseAuthentication(log types.Log) (*AuthControllerV1Authentication, error) {
+	event := new(AuthControllerV1Authentication)
+	if err := _AuthControllerV1.contract.UnpackLog(event, "Authentication", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
+
+// AuthControllerV1OwnershipTransferredIterator is returned from FilterOwnershipTransferred and is used to iterate over the raw logs and unpacked data for OwnershipTransferred events raised by the AuthControllerV1 contract.
+type AuthControllerV1OwnershipTransferredIterator struct {
+	Event *AuthControllerV1OwnershipTransferred // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *AuthControllerV1OwnershipTransferredIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(AuthControllerV1OwnershipTransferred)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(AuthControllerV1OwnershipTransferred)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *AuthControllerV1OwnershipTransferredIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *AuthControllerV1OwnershipTransferredIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// AuthControllerV1OwnershipTransferred represents a OwnershipTransferred event raised by the AuthControllerV1 contract.
+type AuthControllerV1OwnershipTransferred struct {
+	PreviousOwner common.Address
+	NewOwner      common.Address
+	Raw           types.Log // Blockchain specific contextual infos
+}
+
+// FilterOwnershipTransferred is a free log retrieval operation binding the contract event 0x8be0079c531659141344cd1fd0a4f28419497f9722a3daafe3b4186f6b6457e0.
+//
+// Solidity: event OwnershipTransferred(address indexed previousOwner, address indexed newOwner)
+func (_AuthControllerV1 *AuthControllerV1Filterer) FilterOwnershipTransferred(opts *bind.FilterOpts, previousOwner []common.Address, newOwner []common.Address) (*AuthControllerV1OwnershipTransferredIterator, error) {
+
+	var previousOwnerRule []interface{}
+	for _, previousOwnerItem := range previousOwner {
+		previousOwnerRule = append(previousOwnerRule, previousOwnerItem)
+	}
+	var newOwnerRule []interface{}
+	for _, newOwnerItem := range newOwner {
+		newOwnerRule = append(newOwnerRule, newOwnerItem)
+	}
+
+	logs, sub, err := _AuthControllerV1.contract.FilterLogs(opts, "OwnershipTransferred", previousOwnerRule, newOwnerRule)
+	if err != nil {
+		return nil, err
+	}
+	return &AuthControllerV1OwnershipTransferredIterator{contract: _AuthControllerV1.contract, event: "OwnershipTransferred", logs: logs, sub: sub}, nil
+}
+
+// WatchOwnershipTransferred is a free log subscription operation binding the contract event 0x8be0079c531659141344cd1fd0a4f28419497f9722a3daafe3b4186f6b6457e0.
+//
+// Solidity: event OwnershipTransferred(address indexed previousOwner, address indexed newOwner)
+func (_AuthControllerV1 *AuthControllerV1Filterer) WatchOwnershipTransferred(opts *bind.WatchOpts, sink chan<- *AuthControllerV1OwnershipTransferred, previousOwner []common.Address, newOwner []common.Address) (event.Subscription, error) {
+
+	var previousOwnerRule []interface{}
+	for _, previousOwnerItem := range previousOwner {
+		previousOwnerRule = append(previousOwnerRule, previousOwnerItem)
+	}
+	var newOwnerRule []interface{}
+	for _, newOwnerItem := range newOwner {
+		newOwnerRule = append(newOwnerRule, newOwnerItem)
+	}
+
+	logs, sub, err := _AuthControllerV1.contract.WatchLogs(opts, "OwnershipTransferred", previousOwnerRule, newOwnerRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(AuthControllerV1OwnershipTransferred)
+				if err := _AuthControllerV1.contract.UnpackLog(event, "OwnershipTransferred", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseOwnershipTransferred is a log parse operation binding the contract event 0x8be0079c531659141344cd1fd0a4f28419497f9722a3daafe3b4186f6b6457e0.
+//
+// Solidity: event OwnershipTransferred(address indexed previousOwner, address indexed newOwner)
+func (_AuthControllerV1 *AuthControllerV1Filterer) ParseOwnershipTransferred(log types.Log) (*AuthControllerV1OwnershipTransferred, error) {
+	event := new(AuthControllerV1OwnershipTransferred)
+	if err := _AuthControllerV1.contract.UnpackLog(event, "OwnershipTransferred", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
+
+// AuthControllerV1RemovedFromWhiteListIterator is returned from FilterRemovedFromWhiteList and is used to iterate over the raw logs and unpacked data for RemovedFromWhiteList events raised by the AuthControllerV1 contract.
+type AuthControllerV1RemovedFromWhiteListIterator struct {
+	Event *AuthControllerV1RemovedFromWhiteList // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *AuthControllerV1RemovedFromWhiteListIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(AuthControllerV1RemovedFromWhiteList)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(AuthControllerV1RemovedFromWhiteList)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *AuthControllerV1RemovedFromWhiteListIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *AuthControllerV1RemovedFromWhiteListIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// AuthControllerV1RemovedFromWhiteList represents a RemovedFromWhiteList event raised by the AuthControllerV1 contract.
+type AuthControllerV1RemovedFromWhiteList struct {
+	Arg0 common.Address
+	Raw  types.Log // Blockchain specific contextual infos
+}
+
+// FilterRemovedFromWhiteList is a free log retrieval operation binding the contract event 0x9354cd337eebad48c93d70f7321b188732c3061fa5c48fe32b8e6f9480c52fcc.
+//
+// Solidity: event RemovedFromWhiteList(address arg0)
+func (_AuthControllerV1 *AuthControllerV1Filterer) FilterRemovedFromWhiteList(opts *bind.FilterOpts) (*AuthControllerV1RemovedFromWhiteListIterator, error) {
+
+	logs, sub, err := _AuthControllerV1.contract.FilterLogs(opts, "RemovedFromWhiteList")
+	if err != nil {
+		return nil, err
+	}
+	return &AuthControllerV1RemovedFromWhiteListIterator{contract: _AuthControllerV1.contract, event: "RemovedFromWhiteList", logs: logs, sub: sub}, nil
+}
+
+// WatchRemovedFromWhiteList is a free log subscription operation binding the contract event 0x9354cd337eebad48c93d70f7321b188732c3061fa5c48fe32b8e6f9480c52fcc.
+//
+// Solidity: event RemovedFromWhiteList(address arg0)
+func (_AuthControllerV1 *AuthControllerV1Filterer) WatchRemovedFromWhiteList(opts *bind.WatchOpts, sink chan<- *AuthControllerV1RemovedFromWhiteList) (event.Subscription, error) {
+
+	logs, sub, err := _AuthControllerV1.contract.WatchLogs(opts, "RemovedFromWhiteList")
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(AuthControllerV1RemovedFromWhiteList)
+				if err := _AuthControllerV1.contract.UnpackLog(event, "RemovedFromWhiteList", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseRemovedFromWhiteList is a log parse operation binding the contract event 0x9354cd337eebad48c93d70f7321b188732c3061fa5c48fe32b8e6f9480c52fcc.
+//
+// Solidity: event RemovedFromWhiteList(address arg0)
+func (_AuthControllerV1 *AuthControllerV1Filterer) ParseRemovedFromWhiteList(log types.Log) (*AuthControllerV1RemovedFromWhiteList, error) {
+	event := new(AuthControllerV1RemovedFromWhiteList)
+	if err := _AuthControllerV1.contract.UnpackLog(event, "RemovedFromWhiteList", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
@@ -0,0 +1,241 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package eip712 constructs, signs and verifies the EIP-712 signatures carried
+// in AuthController authentication records.
+package eip712
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/qydata/go-ctereum/accounts"
+	"github.com/qydata/go-ctereum/accounts/abi/bind"
+	"github.com/qydata/go-ctereum/common"
+	"github.com/qydata/go-ctereum/common/math"
+	"github.com/qydata/go-ctereum/contracts/authcontroller"
+	"github.com/qydata/go-ctereum/crypto"
+	"github.com/qydata/go-ctereum/signer/core/apitypes"
+)
+
+const (
+	// DomainName is the EIP-712 domain name of the AuthController.
+	DomainName = "AuthController"
+
+	// DomainVersion is the EIP-712 domain version of the AuthController.
+	DomainVersion = "1"
+
+	// primaryType is the name of the signed struct.
+	primaryType = "AuthData"
+)
+
+var (
+	// ErrInvalidSignature is returned if a signature is malformed.
+	ErrInvalidSignature = errors.New("invalid signature")
+
+	// ErrSignerMismatch is returned if a signature was not made by the
+	// expected signer.
+	ErrSignerMismatch = errors.New("signer mismatch")
+
+	// ErrTypeHashMismatch is returned if the type hash of a contract differs
+	// from the locally derived one.
+	ErrTypeHashMismatch = errors.New("type hash mismatch")
+)
+
+var domainType = []apitypes.Type{
+	{Name: "name", Type: "string"},
+	{Name: "version", Type: "string"},
+	{Name: "chainId", Type: "uint256"},
+	{Name: "verifyingContract", Type: "address"},
+}
+
+// authTypes are the signed fields of the AuthData struct per contract revision.
+// The signature itself is never part of the signed data.
+var authTypes = map[authcontroller.Version][]apitypes.Type{
+	authcontroller.V1: {
+		{Name: "caddress", Type: "address"},
+		{Name: "sender", Type: "address"},
+		{Name: "isAuth", Type: "bool"},
+	},
+	authcontroller.V2: {
+		{Name: "caddress", Type: "address"},
+		{Name: "sender", Type: "address"},
+		{Name: "authTime", Type: "uint256"},
+		{Name: "authExpiry", Type: "uint256"},
+		{Name: "isAuth", Type: "bool"},
+		{Name: "authLevel", Type: "uint256"},
+		{Name: "expandData", Type: "string"},
+	},
+}
+
+// Domain returns the EIP-712 domain of the AuthController deployed at the
+// contract address on the given chain.
+func Domain(chainID *big.Int, contractAddr common.Address) apitypes.TypedDataDomain {
+	return apitypes.TypedDataDomain{
+		Name:              DomainName,
+		Version:           DomainVersion,
+		ChainId:           (*math.HexOrDecimal256)(chainID),
+		VerifyingContract: contractAddr.Hex(),
+	}
+}
+
+// DomainSeparator returns the EIP-712 domain separator of the AuthController
+// deployed at the contract address on the given chain.
+func DomainSeparator(chainID *big.Int, contractAddr common.Address) (common.Hash, error) {
+	typedData := apitypes.TypedData{
+		Types:  apitypes.Types{"EIP712Domain": domainType},
+		Domain: Domain(chainID, contractAddr),
+	}
+	hash, err := typedData.HashStruct("EIP712Domain", typedData.Domain.Map())
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return common.BytesToHash(hash), nil
+}
+
+// TypeHash returns the AUTH_TYPEHASH of the given contract revision.
+func TypeHash(version authcontroller.Version) (common.Hash, error) {
+	fields, ok := authTypes[version]
+	if !ok {
+		return common.Hash{}, fmt.Errorf("unsupported auth controller version %d", version)
+	}
+	typedData := apitypes.TypedData{Types: apitypes.Types{primaryType: fields}}
+	return common.BytesToHash(typedData.TypeHash(primaryType)), nil
+}
+
+// TypedData assembles the EIP-712 typed data of an authentication record.
+func TypedData(version authcontroller.Version, chainID *big.Int, contractAddr common.Address, auth *authcontroller.AuthData) (apitypes.TypedData, error) {
+	fields, ok := authTypes[version]
+	if !ok {
+		return apitypes.TypedData{}, fmt.Errorf("unsupported auth controller version %d", version)
+	}
+	message := apitypes.TypedDataMessage{
+		"caddress": auth.Caddress.Hex(),
+		"sender":   auth.Sender.Hex(),
+		"isAuth":   auth.IsAuth,
+	}
+	if version == authcontroller.V2 {
+		message["authTime"] = hexOrDecimal(auth.AuthTime)
+		message["authExpiry"] = hexOrDecimal(auth.AuthExpiry)
+		message["authLevel"] = hexOrDecimal(auth.AuthLevel)
+		message["expandData"] = auth.ExpandData
+	}
+	return apitypes.TypedData{
+		Types: apitypes.Types{
+			"EIP712Domain": domainType,
+			primaryType:    fields,
+		},
+		PrimaryType: primaryType,
+		Domain:      Domain(chainID, contractAddr),
+		Message:     message,
+	}, nil
+}
+
+// Hash returns the EIP-712 digest of an authentication record along with the
+// raw data it was derived from.
+func Hash(version authcontroller.Version, chainID *big.Int, contractAddr common.Address, auth *authcontroller.AuthData) (common.Hash, []byte, error) {
+	typedData, err := TypedData(version, chainID, contractAddr, auth)
+	if err != nil {
+		return common.Hash{}, nil, err
+	}
+	hash, raw, err := apitypes.TypedDataAndHash(typedData)
+	if err != nil {
+		return common.Hash{}, nil, err
+	}
+	return common.BytesToHash(hash), []byte(raw), nil
+}
+
+// Sign signs an authentication record with the account of the given wallet.
+// The returned signature is in the [R || S || V] format expected by ecrecover,
+// with V being 27 or 28.
+func Sign(wallet accounts.Wallet, account accounts.Account, version authcontroller.Version, chainID *big.Int, contractAddr common.Address, auth *authcontroller.AuthData) ([]byte, error) {
+	_, raw, err := Hash(version, chainID, contractAddr, auth)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := wallet.SignData(account, accounts.MimetypeTypedData, raw)
+	if err != nil {
+		return nil, err
+	}
+	if len(sig) != crypto.SignatureLength {
+		return nil, ErrInvalidSignature
+	}
+	sig[crypto.RecoveryIDOffset] += 27
+	return sig, nil
+}
+
+// Recover returns the address that signed the authentication record.
+func Recover(version authcontroller.Version, chainID *big.Int, contractAddr common.Address, auth *authcontroller.AuthData) (common.Address, error) {
+	if len(auth.Signature) != crypto.SignatureLength {
+		return common.Address{}, ErrInvalidSignature
+	}
+	sig := common.CopyBytes(auth.Signature)
+	if sig[crypto.RecoveryIDOffset] >= 27 {
+		sig[crypto.RecoveryIDOffset] -= 27
+	}
+	if sig[crypto.RecoveryIDOffset] > 1 {
+		return common.Address{}, ErrInvalidSignature
+	}
+	hash, _, err := Hash(version, chainID, contractAddr, auth)
+	if err != nil {
+		return common.Address{}, err
+	}
+	pubkey, err := crypto.SigToPub(hash.Bytes(), sig)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return crypto.PubkeyToAddress(*pubkey), nil
+}
+
+// Verify checks that the authentication record was signed by the signer.
+func Verify(version authcontroller.Version, chainID *big.Int, contractAddr common.Address, auth *authcontroller.AuthData, signer common.Address) error {
+	recovered, err := Recover(version, chainID, contractAddr, auth)
+	if err != nil {
+		return err
+	}
+	if recovered != signer {
+		return fmt.Errorf("%w: have %x, want %x", ErrSignerMismatch, recovered, signer)
+	}
+	return nil
+}
+
+// CheckTypeHash cross-checks the AUTH_TYPEHASH of the contract against the one
+// derived for its revision, guarding against signing records the contract
+// would reject.
+func CheckTypeHash(opts *bind.CallOpts, ac *authcontroller.AuthController) error {
+	want, err := TypeHash(ac.Version())
+	if err != nil {
+		return err
+	}
+	have, err := ac.AUTHTYPEHASH(opts)
+	if err != nil {
+		return err
+	}
+	if common.Hash(have) != want {
+		return fmt.Errorf("%w: contract %x, have %x, want %x", ErrTypeHashMismatch, ac.ContractAddr(), common.Hash(have), want)
+	}
+	return nil
+}
+
+// hexOrDecimal converts an optional big integer into the typed data encoding,
+// treating nil as zero.
+func hexOrDecimal(n *big.Int) *math.HexOrDecimal256 {
+	if n == nil {
+		return (*math.HexOrDecimal256)(new(big.Int))
+	}
+	return (*math.HexOrDecimal256)(n)
+}
@@ -0,0 +1,211 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eip712
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/qydata/go-ctereum/accounts/abi/bind/backends"
+	"github.com/qydata/go-ctereum/accounts/keystore"
+	"github.com/qydata/go-ctereum/common"
+	"github.com/qydata/go-ctereum/contracts/authcontroller"
+	"github.com/qydata/go-ctereum/core"
+	"github.com/qydata/go-ctereum/core/types"
+	"github.com/qydata/go-ctereum/crypto"
+)
+
+var (
+	testChainID  = big.NewInt(1337)
+	testContract = common.HexToAddress("0xa0")
+)
+
+func TestTypeHash(t *testing.T) {
+	tests := map[authcontroller.Version]string{
+		authcontroller.V1: "AuthData(address caddress,address sender,bool isAuth)",
+		authcontroller.V2: "AuthData(address caddress,address sender,uint256 authTime,uint256 authExpiry,bool isAuth,uint256 authLevel,string expandData)",
+	}
+	for version, typ := range tests {
+		have, err := TypeHash(version)
+		if err != nil {
+			t.Fatalf("v%d: failed to derive type hash: %v", version, err)
+		}
+		if want := crypto.Keccak256Hash([]byte(typ)); have != want {
+			t.Errorf("v%d: type hash mismatch: have %x, want %x", version, have, want)
+		}
+	}
+	if _, err := TypeHash(authcontroller.Version(3)); err == nil {
+		t.Errorf("expected error for unknown version")
+	}
+}
+
+func TestDomainSeparator(t *testing.T) {
+	have, err := DomainSeparator(testChainID, testContract)
+	if err != nil {
+		t.Fatalf("failed to derive domain separator: %v", err)
+	}
+	typeHash := crypto.Keccak256([]byte("EIP712Domain(string name,string version,uint256 chainId,address verifyingContract)"))
+	want := crypto.Keccak256Hash(
+		typeHash,
+		crypto.Keccak256([]byte(DomainName)),
+		crypto.Keccak256([]byte(DomainVersion)),
+		common.LeftPadBytes(testChainID.Bytes(), 32),
+		common.LeftPadBytes(testContract.Bytes(), 32),
+	)
+	if have != want {
+		t.Errorf("domain separator mismatch: have %x, want %x", have, want)
+	}
+	other, _ := DomainSeparator(big.NewInt(1), testContract)
+	if other == have {
+		t.Errorf("domain separator does not depend on the chain id")
+	}
+}
+
+func TestSignVerify(t *testing.T) {
+	ks := keystore.NewKeyStore(t.TempDir(), keystore.LightScryptN, keystore.LightScryptP)
+	account, err := ks.NewAccount("")
+	if err != nil {
+		t.Fatalf("failed to create account: %v", err)
+	}
+	if err := ks.Unlock(account, ""); err != nil {
+		t.Fatalf("failed to unlock account: %v", err)
+	}
+	wallet := ks.Wallets()[0]
+
+	for _, version := range []authcontroller.Version{authcontroller.V1, authcontroller.V2} {
+		auth := &authcontroller.AuthData{
+			Caddress:   common.HexToAddress("0x01"),
+			Sender:     account.Address,
+			AuthTime:   big.NewInt(1000),
+			AuthExpiry: big.NewInt(2000),
+			IsAuth:     true,
+			AuthLevel:  big.NewInt(2),
+			ExpandData: "kyc",
+		}
+		sig, err := Sign(wallet, account, version, testChainID, testContract, auth)
+		if err != nil {
+			t.Fatalf("v%d: failed to sign: %v", version, err)
+		}
+		if v := sig[crypto.RecoveryIDOffset]; v != 27 && v != 28 {
+			t.Errorf("v%d: recovery id not in ecrecover format: %d", version, v)
+		}
+		auth.Signature = sig
+		if err := Verify(version, testChainID, testContract, auth, account.Address); err != nil {
+			t.Errorf("v%d: failed to verify: %v", version, err)
+		}
+		// Signatures are bound to the chain and the contract
+		if err := Verify(version, big.NewInt(1), testContract, auth, account.Address); !errors.Is(err, ErrSignerMismatch) {
+			t.Errorf("v%d: chain id mismatch not detected: %v", version, err)
+		}
+		if err := Verify(version, testChainID, common.HexToAddress("0xa1"), auth, account.Address); !errors.Is(err, ErrSignerMismatch) {
+			t.Errorf("v%d: contract mismatch not detected: %v", version, err)
+		}
+		auth.IsAuth = false
+		if err := Verify(version, testChainID, testContract, auth, account.Address); !errors.Is(err, ErrSignerMismatch) {
+			t.Errorf("v%d: modified record not detected: %v", version, err)
+		}
+	}
+	// Fields unknown to v1 contracts are not signed
+	auth := &authcontroller.AuthData{Caddress: common.HexToAddress("0x01"), Sender: account.Address, IsAuth: true}
+	sig, err := Sign(wallet, account, authcontroller.V1, testChainID, testContract, auth)
+	if err != nil {
+		t.Fatalf("failed to sign: %v", err)
+	}
+	auth.Signature, auth.AuthLevel = sig, big.NewInt(5)
+	if err := Verify(authcontroller.V1, testChainID, testContract, auth, account.Address); err != nil {
+		t.Errorf("v1 signature depends on v2 fields: %v", err)
+	}
+	// Malformed signatures are rejected
+	auth.Signature = sig[:64]
+	if err := Verify(authcontroller.V1, testChainID, testContract, auth, account.Address); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("short signature error mismatch: have %v, want %v", err, ErrInvalidSignature)
+	}
+	// Locked accounts can't sign
+	ks.Lock(account.Address)
+	if _, err := Sign(wallet, account, authcontroller.V1, testChainID, testContract, auth); !errors.Is(err, keystore.ErrLocked) {
+		t.Errorf("locked account error mismatch: have %v, want %v", err, keystore.ErrLocked)
+	}
+}
+
+// Tests that the auth proofs of authenticated transactions are hashed like the
+// v2 authentication records of the contract.
+func TestAuthProofHash(t *testing.T) {
+	sender := common.HexToAddress("0x01")
+	proof := &types.AuthProof{
+		AuthTime:   big.NewInt(1000),
+		AuthExpiry: big.NewInt(2000),
+		AuthLevel:  big.NewInt(2),
+		ExpandData: "kyc",
+	}
+	want, _, err := Hash(authcontroller.V2, testChainID, testContract, &authcontroller.AuthData{
+		Caddress:   testContract,
+		Sender:     sender,
+		AuthTime:   proof.AuthTime,
+		AuthExpiry: proof.AuthExpiry,
+		IsAuth:     true,
+		AuthLevel:  proof.AuthLevel,
+		ExpandData: proof.ExpandData,
+	})
+	if err != nil {
+		t.Fatalf("failed to hash record: %v", err)
+	}
+	if have := proof.Hash(testChainID, testContract, sender); have != want {
+		t.Errorf("hash mismatch: have %x, want %x", have, want)
+	}
+}
+
+// typeHashCode is a contract returning the given hash for any call.
+func typeHashCode(hash common.Hash) []byte {
+	code := append([]byte{0x7f}, hash.Bytes()...)              // PUSH32 hash
+	return append(code, common.FromHex("60005260206000f3")...) // mstore(0, hash) return(0, 32)
+}
+
+func TestCheckTypeHash(t *testing.T) {
+	v2Hash, _ := TypeHash(authcontroller.V2)
+	var (
+		good = common.HexToAddress("0xa0")
+		bad  = common.HexToAddress("0xa1")
+	)
+	backend := backends.NewSimulatedBackend(core.GenesisAlloc{
+		good: {Code: typeHashCode(v2Hash), Balance: new(big.Int)},
+		bad:  {Code: typeHashCode(common.Hash{0x01}), Balance: new(big.Int)},
+	}, 10000000)
+	defer backend.Close()
+
+	ac, err := authcontroller.NewAuthController(good, backend, authcontroller.V2)
+	if err != nil {
+		t.Fatalf("failed to bind contract: %v", err)
+	}
+	if err := CheckTypeHash(nil, ac); err != nil {
+		t.Errorf("failed to check matching type hash: %v", err)
+	}
+	ac, err = authcontroller.NewAuthController(good, backend, authcontroller.V1)
+	if err != nil {
+		t.Fatalf("failed to bind contract: %v", err)
+	}
+	if err := CheckTypeHash(nil, ac); !errors.Is(err, ErrTypeHashMismatch) {
+		t.Errorf("revision mismatch error mismatch: have %v, want %v", err, ErrTypeHashMismatch)
+	}
+	ac, err = authcontroller.NewAuthController(bad, backend, authcontroller.V2)
+	if err != nil {
+		t.Fatalf("failed to bind contract: %v", err)
+	}
+	if err := CheckTypeHash(nil, ac); !errors.Is(err, ErrTypeHashMismatch) {
+		t.Errorf("type hash mismatch error mismatch: have %v, want %v", err, ErrTypeHashMismatch)
+	}
+}
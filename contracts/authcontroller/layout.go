@@ -0,0 +1,97 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package authcontroller
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/qydata/go-ctereum/accounts/abi/bind"
+	"github.com/qydata/go-ctereum/common"
+	"github.com/qydata/go-ctereum/contracts/authcontroller/contract"
+	"github.com/qydata/go-ctereum/core/vm"
+	"github.com/qydata/go-ctereum/crypto"
+)
+
+// ErrLayoutMismatch is returned if the storage of an auth contract disagrees
+// with its public getters, the contract not following the expected layout.
+var ErrLayoutMismatch = errors.New("auth contract storage layout mismatch")
+
+// Storage slots of the AuthController state variables, in declaration order
+// after the owner inherited from Ownable. The contract source is not part of
+// the tree, so the slots are checked against the public getters of the deployed
+// contract by CheckLayout before being relied upon.
+const (
+	OwnerSlot     = 0 // address owner
+	AuthsSlot     = 1 // mapping(address => uint256) auths
+	WhitelistSlot = 2 // mapping(address => bool) whitelist
+)
+
+// MappingSlot returns the storage slot holding the value of the key in the
+// Solidity mapping declared at the given slot, keccak256(key . slot).
+func MappingSlot(key common.Hash, slot uint64) common.Hash {
+	return crypto.Keccak256Hash(key.Bytes(), common.BigToHash(new(big.Int).SetUint64(slot)).Bytes())
+}
+
+// AuthSlot returns the storage slot holding the auth status of the address.
+func AuthSlot(addr common.Address) common.Hash {
+	return MappingSlot(common.BytesToHash(addr.Bytes()), AuthsSlot)
+}
+
+// WhitelistedSlot returns the storage slot holding the whitelist flag of the
+// address.
+func WhitelistedSlot(addr common.Address) common.Hash {
+	return MappingSlot(common.BytesToHash(addr.Bytes()), WhitelistSlot)
+}
+
+// CheckLayout verifies the slots derived for the owner of the contract and for
+// the auth status and whitelist flag of the address, comparing their storage
+// with the public getters of the contract executed on the state of the EVM.
+func CheckLayout(evm *vm.EVM, contractAddr common.Address, addr common.Address) error {
+	if evm.StateDB.GetCodeSize(contractAddr) == 0 {
+		return bind.ErrNoCode
+	}
+	parsed, err := contract.AuthControllerMetaData.GetAbi()
+	if err != nil {
+		return err
+	}
+	checks := []struct {
+		method string
+		args   []interface{}
+		slot   common.Hash
+	}{
+		{"owner", nil, common.BigToHash(big.NewInt(OwnerSlot))},
+		{"auths", []interface{}{addr}, AuthSlot(addr)},
+		{"whitelisted", []interface{}{addr}, WhitelistedSlot(addr)},
+	}
+	for _, check := range checks {
+		input, err := parsed.Pack(check.method, check.args...)
+		if err != nil {
+			return err
+		}
+		output, _, err := evm.StaticCall(vm.AccountRef(common.Address{}), contractAddr, input, authsSingleGas)
+		if err != nil {
+			return fmt.Errorf("%s lookup failed: %w", check.method, err)
+		}
+		// The getters all return a single word, padded the same as in storage
+		if len(output) != common.HashLength || common.BytesToHash(output) != evm.StateDB.GetState(contractAddr, check.slot) {
+			return fmt.Errorf("%w: %s not stored at slot %x", ErrLayoutMismatch, check.method, check.slot)
+		}
+	}
+	return nil
+}
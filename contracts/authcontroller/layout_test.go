@@ -0,0 +1,126 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package authcontroller
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/qydata/go-ctereum/common"
+	"github.com/qydata/go-ctereum/core/rawdb"
+	"github.com/qydata/go-ctereum/core/state"
+	"github.com/qydata/go-ctereum/crypto"
+	"github.com/qydata/go-ctereum/ethdb/memorydb"
+	"github.com/qydata/go-ctereum/rlp"
+	"github.com/qydata/go-ctereum/trie"
+)
+
+func TestMappingSlot(t *testing.T) {
+	// keccak256 of 64 zero bytes, the slot of key 0 in a mapping at slot 0
+	want := common.HexToHash("0xad3228b676f7d3cd4284a5443f17f1962b36e491b30a40b2405849e597ba5fb5")
+	if have := MappingSlot(common.Hash{}, 0); have != want {
+		t.Errorf("slot mismatch: have %x, want %x", have, want)
+	}
+	addr := common.HexToAddress("0x01")
+	if AuthSlot(addr) == WhitelistedSlot(addr) {
+		t.Errorf("auth and whitelist slots collide")
+	}
+}
+
+// Tests that the derived slots can be proven against the storage root.
+func TestAuthSlotProof(t *testing.T) {
+	var (
+		contractAddr = common.HexToAddress("0xa0")
+		addr         = common.HexToAddress("0x01")
+	)
+	db := state.NewDatabase(rawdb.NewMemoryDatabase())
+	statedb, _ := state.New(common.Hash{}, db, nil)
+	statedb.SetCode(contractAddr, lookupCode)
+	statedb.SetState(contractAddr, AuthSlot(addr), common.BigToHash(common.Big1))
+	root, _ := statedb.Commit(true)
+	statedb, _ = state.New(root, db, nil)
+
+	for _, slot := range []common.Hash{AuthSlot(addr), WhitelistedSlot(addr)} {
+		proof, err := statedb.GetStorageProof(contractAddr, slot)
+		if err != nil {
+			t.Fatalf("failed to prove slot %x: %v", slot, err)
+		}
+		proofDb := memorydb.New()
+		for _, node := range proof {
+			proofDb.Put(crypto.Keccak256(node), node)
+		}
+		value, err := trie.VerifyProof(statedb.StorageTrie(contractAddr).Hash(), crypto.Keccak256(slot.Bytes()), proofDb)
+		if err != nil {
+			t.Fatalf("failed to verify slot %x: %v", slot, err)
+		}
+		var want []byte
+		if slot == AuthSlot(addr) {
+			want, _ = rlp.EncodeToBytes(common.Big1)
+		}
+		if string(value) != string(want) {
+			t.Errorf("slot %x: value mismatch: have %x, want %x", slot, value, want)
+		}
+	}
+}
+
+// layoutCode is a stand-in for the owner, auths and whitelisted getters of the
+// AuthController, reading the owner and the two mappings at the given slots.
+func layoutCode(ownerSlot, authsSlot, whitelistSlot byte) []byte {
+	return common.FromHex(fmt.Sprintf("600035"+"60e01c"+
+		"80638da5cb5b14602757"+ // owner()
+		"806364e72dbd14602e57"+ // auths(address)
+		"63d936547e14603457"+ // whitelisted(address)
+		"600080fd"+
+		"5b60%02x54604a56"+ // sload(ownerSlot)
+		"5b60%02x603a56"+ // mapping at authsSlot
+		"5b60%02x603a56"+ // mapping at whitelistSlot
+		"5b602052600435600052604060002054"+ // sload(keccak256(key . slot))
+		"5b60005260206000f3",
+		ownerSlot, authsSlot, whitelistSlot))
+}
+
+// Tests that the derived slots are checked against the getters of the contract,
+// detecting contracts laid out differently.
+func TestCheckLayout(t *testing.T) {
+	var (
+		contractAddr = common.HexToAddress("0xa0")
+		owner        = common.HexToAddress("0x0a")
+		addr         = common.HexToAddress("0x01")
+	)
+	tests := []struct {
+		code []byte
+		err  error
+	}{
+		{layoutCode(OwnerSlot, AuthsSlot, WhitelistSlot), nil},
+		{layoutCode(OwnerSlot, 5, WhitelistSlot), ErrLayoutMismatch},
+		{layoutCode(OwnerSlot, AuthsSlot, 5), ErrLayoutMismatch},
+		{layoutCode(5, AuthsSlot, WhitelistSlot), ErrLayoutMismatch},
+	}
+	for i, tt := range tests {
+		evm := newTestEVM(t, contractAddr)
+		evm.StateDB.SetCode(contractAddr, tt.code)
+		evm.StateDB.SetState(contractAddr, common.BigToHash(big.NewInt(OwnerSlot)), common.BytesToHash(owner.Bytes()))
+		evm.StateDB.SetState(contractAddr, AuthSlot(addr), common.BigToHash(big.NewInt(1700000000)))
+		evm.StateDB.SetState(contractAddr, WhitelistedSlot(addr), common.BigToHash(common.Big1))
+
+		if err := CheckLayout(evm, contractAddr, addr); !errors.Is(err, tt.err) {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, tt.err)
+		}
+	}
+}
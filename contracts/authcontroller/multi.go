@@ -0,0 +1,124 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package authcontroller
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/qydata/go-ctereum/accounts/abi"
+	"github.com/qydata/go-ctereum/accounts/abi/bind"
+	"github.com/qydata/go-ctereum/common"
+	"github.com/qydata/go-ctereum/common/hexutil"
+	"github.com/qydata/go-ctereum/contracts/authcontroller/contract"
+	"github.com/qydata/go-ctereum/core/vm"
+	"github.com/qydata/go-ctereum/rpc"
+)
+
+// authsSingleGas is the gas allowance of a single authsSingle lookup.
+const authsSingleGas = 1000000
+
+// BatchCaller is a remote node able to execute a batch of RPC calls in a single
+// round trip, such as an rpc.Client.
+type BatchCaller interface {
+	BatchCallContext(ctx context.Context, b []rpc.BatchElem) error
+}
+
+// AuthsMulti reports for every address whether it is authenticated in the
+// contract, executing all the lookups against the state of the given EVM.
+func AuthsMulti(evm *vm.EVM, contractAddr common.Address, addrs []common.Address) ([]bool, error) {
+	if evm.StateDB.GetCodeSize(contractAddr) == 0 {
+		return nil, bind.ErrNoCode
+	}
+	parsed, err := contract.AuthControllerMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	results := make([]bool, len(addrs))
+	for i, addr := range addrs {
+		input, err := parsed.Pack("authsSingle", addr)
+		if err != nil {
+			return nil, err
+		}
+		output, _, err := evm.StaticCall(vm.AccountRef(common.Address{}), contractAddr, input, authsSingleGas)
+		if err != nil {
+			return nil, fmt.Errorf("auth lookup of %x failed: %w", addr, err)
+		}
+		if results[i], err = unpackAuthsSingle(parsed, output); err != nil {
+			return nil, fmt.Errorf("auth lookup of %x failed: %w", addr, err)
+		}
+	}
+	return results, nil
+}
+
+// AuthsMultiCall reports for every address whether it is authenticated in the
+// contract as of the given block, or the latest one if nil, batching all the
+// lookups into a single round trip to the remote node.
+func AuthsMultiCall(ctx context.Context, caller BatchCaller, contractAddr common.Address, addrs []common.Address, blockNumber *big.Int) ([]bool, error) {
+	parsed, err := contract.AuthControllerMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	block := "latest"
+	if blockNumber != nil {
+		block = hexutil.EncodeBig(blockNumber)
+	}
+	var (
+		outputs = make([]hexutil.Bytes, len(addrs))
+		batch   = make([]rpc.BatchElem, len(addrs))
+	)
+	for i, addr := range addrs {
+		input, err := parsed.Pack("authsSingle", addr)
+		if err != nil {
+			return nil, err
+		}
+		batch[i] = rpc.BatchElem{
+			Method: "eth_call",
+			Args: []interface{}{map[string]interface{}{
+				"to":   contractAddr,
+				"data": hexutil.Bytes(input),
+			}, block},
+			Result: &outputs[i],
+		}
+	}
+	if err := caller.BatchCallContext(ctx, batch); err != nil {
+		return nil, err
+	}
+	results := make([]bool, len(addrs))
+	for i, elem := range batch {
+		if elem.Error != nil {
+			return nil, fmt.Errorf("auth lookup of %x failed: %w", addrs[i], elem.Error)
+		}
+		if len(outputs[i]) == 0 {
+			return nil, bind.ErrNoCode
+		}
+		if results[i], err = unpackAuthsSingle(parsed, outputs[i]); err != nil {
+			return nil, fmt.Errorf("auth lookup of %x failed: %w", addrs[i], err)
+		}
+	}
+	return results, nil
+}
+
+// unpackAuthsSingle decodes the output of an authsSingle call.
+func unpackAuthsSingle(parsed *abi.ABI, output []byte) (bool, error) {
+	res, err := parsed.Unpack("authsSingle", output)
+	if err != nil {
+		return false, err
+	}
+	return *abi.ConvertType(res[0], new(bool)).(*bool), nil
+}
@@ -0,0 +1,129 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package authcontroller
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/qydata/go-ctereum/accounts/abi/bind"
+	"github.com/qydata/go-ctereum/common"
+	"github.com/qydata/go-ctereum/common/hexutil"
+	"github.com/qydata/go-ctereum/core"
+	"github.com/qydata/go-ctereum/core/rawdb"
+	"github.com/qydata/go-ctereum/core/state"
+	"github.com/qydata/go-ctereum/core/vm"
+	"github.com/qydata/go-ctereum/params"
+	"github.com/qydata/go-ctereum/rpc"
+)
+
+// lookupCode is a stand-in for the authsSingle method, returning the storage
+// slot keyed by the address argument.
+var lookupCode = common.FromHex("600435" + "54" + "600052" + "60206000f3")
+
+func newTestEVM(t *testing.T, contractAddr common.Address, authed ...common.Address) *vm.EVM {
+	statedb, err := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	if err != nil {
+		t.Fatalf("failed to create state: %v", err)
+	}
+	statedb.SetCode(contractAddr, lookupCode)
+	for _, addr := range authed {
+		statedb.SetState(contractAddr, common.BytesToHash(addr.Bytes()), common.BigToHash(common.Big1))
+	}
+	blockCtx := vm.BlockContext{
+		CanTransfer: core.CanTransfer,
+		Transfer:    core.Transfer,
+		BlockNumber: new(big.Int),
+		Time:        new(big.Int),
+		Difficulty:  new(big.Int),
+		GasLimit:    params.GenesisGasLimit,
+	}
+	return vm.NewEVM(blockCtx, vm.TxContext{}, statedb, params.TestChainConfig, vm.Config{})
+}
+
+func TestAuthsMulti(t *testing.T) {
+	var (
+		contractAddr = common.HexToAddress("0xa0")
+		addrs        = []common.Address{common.HexToAddress("0x01"), common.HexToAddress("0x02"), common.HexToAddress("0x03")}
+	)
+	evm := newTestEVM(t, contractAddr, addrs[0], addrs[2])
+
+	have, err := AuthsMulti(evm, contractAddr, addrs)
+	if err != nil {
+		t.Fatalf("failed to look up auths: %v", err)
+	}
+	if want := []bool{true, false, true}; !reflect.DeepEqual(have, want) {
+		t.Errorf("auths mismatch: have %v, want %v", have, want)
+	}
+	if _, err := AuthsMulti(evm, common.HexToAddress("0xa1"), addrs); err != bind.ErrNoCode {
+		t.Errorf("missing contract error mismatch: have %v, want %v", err, bind.ErrNoCode)
+	}
+}
+
+// testBatchCaller answers eth_call batches against a local EVM.
+type testBatchCaller struct {
+	evm     *vm.EVM
+	batches int
+	fail    error
+}
+
+func (c *testBatchCaller) BatchCallContext(ctx context.Context, b []rpc.BatchElem) error {
+	c.batches++
+	for i := range b {
+		if c.fail != nil {
+			b[i].Error = c.fail
+			continue
+		}
+		args := b[i].Args[0].(map[string]interface{})
+		output, _, err := c.evm.StaticCall(vm.AccountRef(common.Address{}), args["to"].(common.Address), args["data"].(hexutil.Bytes), authsSingleGas)
+		if err != nil {
+			b[i].Error = err
+			continue
+		}
+		*b[i].Result.(*hexutil.Bytes) = output
+	}
+	return nil
+}
+
+func TestAuthsMultiCall(t *testing.T) {
+	var (
+		contractAddr = common.HexToAddress("0xa0")
+		addrs        = []common.Address{common.HexToAddress("0x01"), common.HexToAddress("0x02"), common.HexToAddress("0x03")}
+	)
+	caller := &testBatchCaller{evm: newTestEVM(t, contractAddr, addrs[1])}
+
+	have, err := AuthsMultiCall(context.Background(), caller, contractAddr, addrs, nil)
+	if err != nil {
+		t.Fatalf("failed to look up auths: %v", err)
+	}
+	if want := []bool{false, true, false}; !reflect.DeepEqual(have, want) {
+		t.Errorf("auths mismatch: have %v, want %v", have, want)
+	}
+	if caller.batches != 1 {
+		t.Errorf("round trip count mismatch: have %d, want 1", caller.batches)
+	}
+	if _, err := AuthsMultiCall(context.Background(), caller, common.HexToAddress("0xa1"), addrs, big.NewInt(1)); err != bind.ErrNoCode {
+		t.Errorf("missing contract error mismatch: have %v, want %v", err, bind.ErrNoCode)
+	}
+	caller.fail = errors.New("call failed")
+	if _, err := AuthsMultiCall(context.Background(), caller, contractAddr, addrs, nil); !errors.Is(err, caller.fail) {
+		t.Errorf("call error mismatch: have %v, want %v", err, caller.fail)
+	}
+}
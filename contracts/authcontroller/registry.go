@@ -0,0 +1,97 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package authcontroller
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/qydata/go-ctereum/common"
+)
+
+// Registry maps labels to the addresses of AuthController instances deployed
+// for individual dApps, next to the one configured for the chain. Registered
+// controllers are expected to implement the current (v2) contract revision.
+type Registry map[string]common.Address
+
+// LoadRegistry reads a registry from a JSON file holding an object mapping the
+// labels to the contract addresses.
+func LoadRegistry(path string) (Registry, error) {
+	blob, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var registry Registry
+	if err := json.Unmarshal(blob, &registry); err != nil {
+		return nil, fmt.Errorf("invalid auth registry %s: %v", path, err)
+	}
+	if err := registry.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid auth registry %s: %v", path, err)
+	}
+	return registry, nil
+}
+
+// Validate checks that every label is set and maps to a distinct, non-zero
+// contract address.
+func (r Registry) Validate() error {
+	seen := make(map[common.Address]string)
+	for _, label := range r.Labels() {
+		addr := r[label]
+		if label == "" {
+			return fmt.Errorf("empty label for %x", addr)
+		}
+		if addr == (common.Address{}) {
+			return fmt.Errorf("no address for %q", label)
+		}
+		if other, ok := seen[addr]; ok {
+			return fmt.Errorf("%x registered as both %q and %q", addr, other, label)
+		}
+		seen[addr] = label
+	}
+	return nil
+}
+
+// Lookup returns the address of the controller registered under the label.
+func (r Registry) Lookup(label string) (common.Address, error) {
+	addr, ok := r[label]
+	if !ok {
+		return common.Address{}, fmt.Errorf("unknown auth controller %q", label)
+	}
+	return addr, nil
+}
+
+// Labels returns the registered labels in sorted order.
+func (r Registry) Labels() []string {
+	labels := make([]string, 0, len(r))
+	for label := range r {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+	return labels
+}
+
+// Addresses returns the addresses of the registered controllers, in the order
+// of their labels.
+func (r Registry) Addresses() []common.Address {
+	addrs := make([]common.Address, 0, len(r))
+	for _, label := range r.Labels() {
+		addrs = append(addrs, r[label])
+	}
+	return addrs
+}
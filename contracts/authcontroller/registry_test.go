@@ -0,0 +1,71 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package authcontroller
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/qydata/go-ctereum/common"
+)
+
+func TestLoadRegistry(t *testing.T) {
+	tests := []struct {
+		json string
+		want Registry
+		fail bool
+	}{
+		{json: `{}`, want: Registry{}},
+		{
+			json: `{"shop": "0x00000000000000000000000000000000000000a1", "bank": "0x00000000000000000000000000000000000000a2"}`,
+			want: Registry{"shop": common.HexToAddress("0xa1"), "bank": common.HexToAddress("0xa2")},
+		},
+		{json: `{"shop": "0x00000000000000000000000000000000000000a1", "bank": "0x00000000000000000000000000000000000000a1"}`, fail: true},
+		{json: `{"shop": "0x0000000000000000000000000000000000000000"}`, fail: true},
+		{json: `{"": "0x00000000000000000000000000000000000000a1"}`, fail: true},
+		{json: `["0x00000000000000000000000000000000000000a1"]`, fail: true},
+	}
+	for i, tt := range tests {
+		path := filepath.Join(t.TempDir(), "registry.json")
+		if err := os.WriteFile(path, []byte(tt.json), 0600); err != nil {
+			t.Fatalf("failed to write registry: %v", err)
+		}
+		registry, err := LoadRegistry(path)
+		if tt.fail {
+			if err == nil {
+				t.Errorf("test %d: invalid registry accepted", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("test %d: failed to load registry: %v", i, err)
+			continue
+		}
+		if !reflect.DeepEqual(registry, tt.want) {
+			t.Errorf("test %d: registry mismatch: have %v, want %v", i, registry, tt.want)
+		}
+	}
+	registry := Registry{"shop": common.HexToAddress("0xa1"), "bank": common.HexToAddress("0xa2")}
+	if addrs := registry.Addresses(); !reflect.DeepEqual(addrs, []common.Address{common.HexToAddress("0xa2"), common.HexToAddress("0xa1")}) {
+		t.Errorf("addresses mismatch: %x", addrs)
+	}
+	if _, err := registry.Lookup("unknown"); err == nil {
+		t.Errorf("unknown label resolved")
+	}
+}
@@ -0,0 +1,470 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package testing
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/qydata/go-ctereum/accounts/abi"
+	"github.com/qydata/go-ctereum/common"
+	"github.com/qydata/go-ctereum/common/hexutil"
+	"github.com/qydata/go-ctereum/consensus/clique/contract"
+	"github.com/qydata/go-ctereum/core/asm"
+	"github.com/qydata/go-ctereum/core/vm"
+)
+
+// The compiled AuthController and staking contracts are not part of the
+// repository, so the harness deploys stand-ins implementing the subset of the
+// ABIs the node relies on. Selectors and event topics are taken from the real
+// ABIs, keeping the stand-ins interchangeable with the Go bindings.
+
+// authControllerSource is the runtime code of the AuthController stand-in. It
+// implements authsSingle, whitelisted, owner, addToWhitelist, removeFromWhitelist
+// and the v2 authentication method. The owner is stored under $ownerSlot, the
+// auth flag of an address under the address itself and its whitelist flag under
+// the address or'ed with $whitelist.
+const authControllerSource = `
+	PUSH 0
+	CALLDATALOAD
+	PUSH 0xe0
+	SHR
+	DUP1
+	PUSH $authsSingle
+	EQ
+	JUMPI @authsSingle
+	DUP1
+	PUSH $whitelisted
+	EQ
+	JUMPI @whitelisted
+	DUP1
+	PUSH $owner
+	EQ
+	JUMPI @owner
+	DUP1
+	PUSH $addToWhitelist
+	EQ
+	JUMPI @addToWhitelist
+	DUP1
+	PUSH $removeFromWhitelist
+	EQ
+	JUMPI @removeFromWhitelist
+	DUP1
+	PUSH $authentication
+	EQ
+	JUMPI @authentication
+revert:
+	PUSH 0
+	DUP1
+	REVERT
+
+returnWord:
+	PUSH 0
+	MSTORE
+	PUSH 32
+	PUSH 0
+	RETURN
+
+authsSingle:
+	PUSH 4
+	CALLDATALOAD
+	SLOAD
+	JUMP @returnWord
+
+whitelisted:
+	PUSH 4
+	CALLDATALOAD
+	PUSH $whitelist
+	OR
+	SLOAD
+	JUMP @returnWord
+
+owner:
+	PUSH $ownerSlot
+	SLOAD
+	JUMP @returnWord
+
+addToWhitelist:
+	PUSH $ownerSlot
+	SLOAD
+	CALLER
+	EQ
+	ISZERO
+	JUMPI @revert
+	PUSH 1
+	PUSH $AddedToWhiteList
+	JUMP @updateWhitelist
+
+removeFromWhitelist:
+	PUSH $ownerSlot
+	SLOAD
+	CALLER
+	EQ
+	ISZERO
+	JUMPI @revert
+	PUSH 0
+	PUSH $RemovedFromWhiteList
+	JUMP @updateWhitelist
+
+;; stack: flag, topic
+updateWhitelist:
+	PUSH 4
+	CALLDATALOAD
+	PUSH 4
+	ADD
+	DUP1
+	CALLDATALOAD
+	SWAP1
+	PUSH 32
+	ADD
+	SWAP1
+	PUSH 32
+	MUL
+	DUP2
+	ADD
+;; stack: flag, topic, ptr, end
+updateLoop:
+	DUP1
+	DUP3
+	LT
+	ISZERO
+	JUMPI @stop
+	DUP2
+	CALLDATALOAD
+	DUP5
+	DUP2
+	PUSH $whitelist
+	OR
+	SSTORE
+	PUSH 0
+	MSTORE
+	DUP3
+	PUSH 32
+	PUSH 0
+	LOG1
+	SWAP1
+	PUSH 32
+	ADD
+	SWAP1
+	JUMP @updateLoop
+
+authentication:
+	CALLER
+	PUSH $whitelist
+	OR
+	SLOAD
+	ISZERO
+	JUMPI @revert
+	PUSH 4
+	CALLDATALOAD
+	PUSH 4
+	ADD
+;; stack: tuple offset
+	DUP1
+	PUSH 160
+	ADD
+	CALLDATALOAD
+	DUP2
+	CALLDATALOAD
+	SSTORE
+;; emit Authentication(auth, auth.caddress), the tuple being the tail of the call
+	PUSH 32
+	PUSH 0
+	MSTORE
+	DUP1
+	CALLDATASIZE
+	SUB
+	DUP1
+	DUP3
+	PUSH 32
+	CALLDATACOPY
+	DUP2
+	CALLDATALOAD
+	PUSH $Authentication
+	DUP3
+	PUSH 32
+	ADD
+	PUSH 0
+	LOG2
+stop:
+	STOP
+`
+
+// stakingSource is the runtime code of the staking contract stand-in. It
+// implements stake, accountStake, isValidator, getValidators and a no-op
+// commitAccum. The stake of an address is stored under the address itself,
+// the number of validators under $count and the validator list after it.
+// Voting powers are the raw stakes and proposer priorities are always zero.
+const stakingSource = `
+	PUSH 0
+	CALLDATALOAD
+	PUSH 0xe0
+	SHR
+	DUP1
+	PUSH $stake
+	EQ
+	JUMPI @stake
+	DUP1
+	PUSH $accountStake
+	EQ
+	JUMPI @accountStake
+	DUP1
+	PUSH $isValidator
+	EQ
+	JUMPI @isValidator
+	DUP1
+	PUSH $getValidators
+	EQ
+	JUMPI @getValidators
+	DUP1
+	PUSH $commitAccum
+	EQ
+	JUMPI @stop
+	PUSH 0
+	DUP1
+	REVERT
+
+returnWord:
+	PUSH 0
+	MSTORE
+	PUSH 32
+	PUSH 0
+	RETURN
+
+accountStake:
+	PUSH 4
+	CALLDATALOAD
+	SLOAD
+	JUMP @returnWord
+
+isValidator:
+	PUSH 4
+	CALLDATALOAD
+	SLOAD
+	ISZERO
+	ISZERO
+	JUMP @returnWord
+
+stake:
+	PUSH 4
+	CALLDATALOAD
+	DUP1
+	SLOAD
+	DUP1
+	JUMPI @staked
+	PUSH $count
+	SLOAD
+	DUP3
+	DUP2
+	PUSH $list
+	ADD
+	SSTORE
+	PUSH 1
+	ADD
+	PUSH $count
+	SSTORE
+;; stack: user, old stake
+staked:
+	CALLVALUE
+	ADD
+	DUP2
+	SSTORE
+	PUSH 0
+	MSTORE
+	CALLVALUE
+	PUSH 32
+	MSTORE
+	PUSH $Staked
+	PUSH 64
+	PUSH 0
+	LOG1
+stop:
+	STOP
+
+;; returns three arrays of n words, each encoded in w = 32 * (n + 1) bytes
+getValidators:
+	PUSH $count
+	SLOAD
+	PUSH 0x60
+	PUSH 0
+	MSTORE
+	DUP1
+	PUSH 1
+	ADD
+	PUSH 32
+	MUL
+	DUP1
+	PUSH 0x60
+	ADD
+	PUSH 32
+	MSTORE
+	DUP1
+	DUP1
+	ADD
+	PUSH 0x60
+	ADD
+	PUSH 64
+	MSTORE
+	DUP2
+	PUSH 0x60
+	MSTORE
+	DUP2
+	DUP2
+	PUSH 0x60
+	ADD
+	MSTORE
+	DUP2
+	DUP2
+	DUP1
+	ADD
+	PUSH 0x60
+	ADD
+	MSTORE
+	PUSH 0
+;; stack: n, w, i
+validatorsLoop:
+	DUP3
+	DUP2
+	LT
+	ISZERO
+	JUMPI @validatorsDone
+	DUP1
+	PUSH $list
+	ADD
+	SLOAD
+	DUP1
+	DUP3
+	PUSH 32
+	MUL
+	PUSH 0x80
+	ADD
+	MSTORE
+	SLOAD
+	DUP2
+	PUSH 32
+	MUL
+	PUSH 0x80
+	ADD
+	DUP4
+	ADD
+	MSTORE
+	PUSH 1
+	ADD
+	JUMP @validatorsLoop
+validatorsDone:
+	POP
+	PUSH 3
+	MUL
+	PUSH 0x60
+	ADD
+	PUSH 0
+	RETURN
+`
+
+var (
+	// whitelistFlag and ownerSlot keep the whitelist flags and the owner of
+	// the AuthController stand-in clear of the auth flags keyed by address.
+	whitelistFlag = "0x010000000000000000000000000000000000000000"
+	ownerSlot     = "0x020000000000000000000000000000000000000000"
+
+	// validatorCount keeps the validator list of the staking stand-in clear
+	// of the stakes keyed by address.
+	validatorCount = "0x010000000000000000000000000000000000000000"
+	validatorList  = "0x010000000000000000000000000000000000000001"
+)
+
+// authControllerCode returns the deployment code of the AuthController
+// stand-in, making the deployer the owner.
+func authControllerCode(parsed *abi.ABI) []byte {
+	runtime := assemble(authControllerSource, parsed, map[string]string{
+		"whitelist": whitelistFlag,
+		"ownerSlot": ownerSlot,
+	})
+
+	// sstore(ownerSlot, caller)
+	prefix := []byte{byte(vm.CALLER), byte(vm.PUSH21)}
+	prefix = append(prefix, hexutil.MustDecode(ownerSlot)...)
+	prefix = append(prefix, byte(vm.SSTORE))
+	return deployCode(prefix, runtime)
+}
+
+// stakingCode returns the deployment code of the staking stand-in.
+func stakingCode() []byte {
+	parsed := contract.Staking()
+	return deployCode(nil, assemble(stakingSource, &parsed, map[string]string{
+		"count": validatorCount,
+		"list":  validatorList,
+	}))
+}
+
+// assemble compiles the source of a stand-in contract, substituting the
+// method selectors and event topics of the ABI as well as the given constants.
+func assemble(source string, parsed *abi.ABI, constants map[string]string) []byte {
+	values := make(map[string]string)
+	for name, value := range constants {
+		values["$"+name] = value
+	}
+	for name, method := range parsed.Methods {
+		values["$"+name] = hexutil.Encode(method.ID)
+	}
+	for name, event := range parsed.Events {
+		values["$"+name] = event.ID.Hex()
+	}
+	// Replace the longest names first, so that no name clobbers the ones it
+	// is a prefix of.
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return len(names[i]) > len(names[j]) })
+
+	replacements := make([]string, 0, 2*len(names))
+	for _, name := range names {
+		replacements = append(replacements, name, values[name])
+	}
+	source = strings.NewReplacer(replacements...).Replace(source)
+
+	compiler := asm.NewCompiler(false)
+	compiler.Feed(asm.Lex([]byte(source), false))
+	code, errs := compiler.Compile()
+	if len(errs) != 0 {
+		panic(fmt.Sprintf("failed to assemble contract: %v", errs))
+	}
+	return common.FromHex(code)
+}
+
+// deployCode wraps the runtime code into deployment code, running the prefix
+// as the constructor.
+func deployCode(prefix []byte, runtime []byte) []byte {
+	// codecopy(0, len(deploy code), len(runtime)), return(0, len(runtime))
+	const copierLen = 13
+	var (
+		size   = len(runtime)
+		offset = len(prefix) + copierLen
+	)
+	code := append(prefix, []byte{
+		byte(vm.PUSH2), byte(size >> 8), byte(size),
+		byte(vm.DUP1),
+		byte(vm.PUSH2), byte(offset >> 8), byte(offset),
+		byte(vm.PUSH1), 0,
+		byte(vm.CODECOPY),
+		byte(vm.PUSH1), 0,
+		byte(vm.RETURN),
+	}...)
+	return append(code, runtime...)
+}
@@ -0,0 +1,183 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package testing deploys the AuthController and staking validator contracts
+// onto a simulated backend, so that the auth enforcement and validator logic
+// of the node can be tested hermetically.
+package testing
+
+import (
+	"context"
+	"errors"
+	"math/big"
+
+	"github.com/qydata/go-ctereum/accounts/abi/bind"
+	"github.com/qydata/go-ctereum/accounts/abi/bind/backends"
+	"github.com/qydata/go-ctereum/common"
+	"github.com/qydata/go-ctereum/consensus/clique/contract"
+	"github.com/qydata/go-ctereum/contracts/authcontroller"
+	authcontract "github.com/qydata/go-ctereum/contracts/authcontroller/contract"
+	"github.com/qydata/go-ctereum/core"
+	"github.com/qydata/go-ctereum/core/types"
+	"github.com/qydata/go-ctereum/crypto"
+	"github.com/qydata/go-ctereum/params"
+)
+
+// ErrReverted is returned if a transaction sent by the harness failed.
+var ErrReverted = errors.New("transaction reverted")
+
+// Harness is a simulated chain with the AuthController and staking contracts
+// deployed. The AuthController is owned by the Owner account.
+type Harness struct {
+	Backend  *backends.SimulatedBackend
+	Owner    *bind.TransactOpts   // Deployer of the contracts
+	Accounts []*bind.TransactOpts // Funded accounts for the test to use
+
+	AuthAddr    common.Address                 // Address of the AuthController
+	Auth        *authcontroller.AuthController // Binding of the AuthController
+	StakingAddr common.Address                 // Address of the staking contract
+
+	authTransactor *authcontract.AuthControllerTransactor
+	staking        *bind.BoundContract
+}
+
+// New creates a simulated chain with the given number of funded accounts and
+// deploys the contracts onto it.
+func New(accounts int) (*Harness, error) {
+	var (
+		alloc = make(core.GenesisAlloc)
+		funds = new(big.Int).Mul(big.NewInt(1000), big.NewInt(params.Ether))
+		keys  = make([]*bind.TransactOpts, accounts+1)
+	)
+	for i := range keys {
+		key, err := crypto.GenerateKey()
+		if err != nil {
+			return nil, err
+		}
+		if keys[i], err = bind.NewKeyedTransactorWithChainID(key, big.NewInt(1337)); err != nil {
+			return nil, err
+		}
+		alloc[keys[i].From] = core.GenesisAccount{Balance: funds}
+	}
+	h := &Harness{
+		Backend:  backends.NewSimulatedBackend(alloc, 10000000),
+		Owner:    keys[0],
+		Accounts: keys[1:],
+	}
+	if err := h.deploy(); err != nil {
+		h.Backend.Close()
+		return nil, err
+	}
+	return h, nil
+}
+
+// deploy deploys the contracts and creates their bindings.
+func (h *Harness) deploy() error {
+	parsed, err := authcontract.AuthControllerMetaData.GetAbi()
+	if err != nil {
+		return err
+	}
+	authAddr, tx, _, err := bind.DeployContract(h.Owner, *parsed, authControllerCode(parsed), h.Backend)
+	if err := h.commit(tx, err); err != nil {
+		return err
+	}
+	staking := contract.Staking()
+	stakingAddr, tx, bound, err := bind.DeployContract(h.Owner, staking, stakingCode(), h.Backend)
+	if err := h.commit(tx, err); err != nil {
+		return err
+	}
+	h.AuthAddr, h.StakingAddr, h.staking = authAddr, stakingAddr, bound
+
+	if h.Auth, err = authcontroller.NewAuthController(authAddr, h.Backend, authcontroller.V2); err != nil {
+		return err
+	}
+	h.authTransactor, err = authcontract.NewAuthControllerTransactor(authAddr, h.Backend)
+	return err
+}
+
+// Close shuts down the simulated chain.
+func (h *Harness) Close() error {
+	return h.Backend.Close()
+}
+
+// commit mines the transaction into a new block and checks that it succeeded.
+func (h *Harness) commit(tx *types.Transaction, err error) error {
+	if err != nil {
+		return err
+	}
+	h.Backend.Commit()
+
+	receipt, err := h.Backend.TransactionReceipt(context.Background(), tx.Hash())
+	if err != nil {
+		return err
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return ErrReverted
+	}
+	return nil
+}
+
+// Whitelist adds the signers to the whitelist of the AuthController, allowing
+// them to submit authentications.
+func (h *Harness) Whitelist(signers ...common.Address) error {
+	return h.commit(h.authTransactor.AddToWhitelist(h.Owner, signers))
+}
+
+// Unwhitelist removes the signers from the whitelist of the AuthController.
+func (h *Harness) Unwhitelist(signers ...common.Address) error {
+	return h.commit(h.authTransactor.RemoveFromWhitelist(h.Owner, signers))
+}
+
+// SubmitAuth submits the authentication from the whitelisted signer. Numeric
+// fields left nil are submitted as zero.
+func (h *Harness) SubmitAuth(signer *bind.TransactOpts, auth authcontract.AuthControllerAuthData, orderId *big.Int) error {
+	for _, field := range []**big.Int{&auth.AuthTime, &auth.AuthExpiry, &auth.AuthLevel, &orderId} {
+		if *field == nil {
+			*field = new(big.Int)
+		}
+	}
+	return h.commit(h.authTransactor.Authentication(signer, auth, orderId))
+}
+
+// Authenticate sets the auth flag of the addresses, submitting a minimal
+// authentication for each from the whitelisted signer.
+func (h *Harness) Authenticate(signer *bind.TransactOpts, isAuth bool, addrs ...common.Address) error {
+	for _, addr := range addrs {
+		auth := authcontract.AuthControllerAuthData{Caddress: addr, Sender: signer.From, IsAuth: isAuth}
+		if err := h.SubmitAuth(signer, auth, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Stake stakes the amount for the validator from the given account, adding
+// the validator to the validator set on its first stake.
+func (h *Harness) Stake(from *bind.TransactOpts, validator common.Address, amount *big.Int) error {
+	opts := *from
+	opts.Value = amount
+	return h.commit(h.staking.Transact(&opts, "stake", validator))
+}
+
+// Validators returns the validator set along with the voting powers, as
+// reported by the getValidators method of the staking contract.
+func (h *Harness) Validators() ([]common.Address, []*big.Int, error) {
+	var out []interface{}
+	if err := h.staking.Call(nil, &out, "getValidators"); err != nil {
+		return nil, nil, err
+	}
+	return out[0].([]common.Address), out[1].([]*big.Int), nil
+}
@@ -0,0 +1,130 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package testing
+
+import (
+	"context"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/qydata/go-ctereum"
+	"github.com/qydata/go-ctereum/common"
+	authcontract "github.com/qydata/go-ctereum/contracts/authcontroller/contract"
+)
+
+func TestAuthController(t *testing.T) {
+	h, err := New(2)
+	if err != nil {
+		t.Fatalf("failed to create harness: %v", err)
+	}
+	defer h.Close()
+
+	var (
+		signer = h.Accounts[0]
+		user   = h.Accounts[1].From
+	)
+	caller, _ := authcontract.NewAuthControllerCaller(h.AuthAddr, h.Backend)
+	if owner, err := caller.Owner(nil); err != nil || owner != h.Owner.From {
+		t.Errorf("owner mismatch: have %x, %v, want %x", owner, err, h.Owner.From)
+	}
+	// Only the owner may whitelist and only whitelisted signers may authenticate
+	if err := h.Authenticate(signer, true, user); err == nil {
+		t.Fatalf("authenticated from a signer not on the whitelist")
+	}
+	if err := h.commit(h.authTransactor.AddToWhitelist(signer, []common.Address{signer.From})); err == nil {
+		t.Fatalf("whitelisted from a non-owner account")
+	}
+	if err := h.Whitelist(signer.From); err != nil {
+		t.Fatalf("failed to whitelist signer: %v", err)
+	}
+	if ok, err := h.Auth.Whitelisted(nil, signer.From); err != nil || !ok {
+		t.Fatalf("signer not whitelisted: %v, %v", ok, err)
+	}
+	auth := authcontract.AuthControllerAuthData{
+		Caddress:   user,
+		Sender:     signer.From,
+		Signature:  []byte{0x01, 0x02},
+		AuthExpiry: big.NewInt(1000),
+		IsAuth:     true,
+		AuthLevel:  big.NewInt(2),
+		ExpandData: "data",
+	}
+	if err := h.SubmitAuth(signer, auth, big.NewInt(1)); err != nil {
+		t.Fatalf("failed to submit auth: %v", err)
+	}
+	if ok, err := h.Auth.AuthsSingle(nil, user); err != nil || !ok {
+		t.Fatalf("user not authenticated: %v, %v", ok, err)
+	}
+	// The Authentication event carries the submitted record
+	logs, err := h.Backend.FilterLogs(context.Background(), ethereum.FilterQuery{Addresses: []common.Address{h.AuthAddr}})
+	if err != nil {
+		t.Fatalf("failed to filter logs: %v", err)
+	}
+	event, err := h.Auth.ParseAuthentication(logs[len(logs)-1])
+	if err != nil {
+		t.Fatalf("failed to parse event: %v", err)
+	}
+	if event.Caddress != user || event.Level() != 2 || event.AuthExpiry.Uint64() != 1000 || event.ExpandData != "data" {
+		t.Errorf("event mismatch: %+v", event)
+	}
+	// Revoking the auth and the signer
+	if err := h.Authenticate(signer, false, user); err != nil {
+		t.Fatalf("failed to revoke auth: %v", err)
+	}
+	if ok, _ := h.Auth.AuthsSingle(nil, user); ok {
+		t.Errorf("user still authenticated")
+	}
+	if err := h.Unwhitelist(signer.From); err != nil {
+		t.Fatalf("failed to remove signer: %v", err)
+	}
+	if err := h.Authenticate(signer, true, user); err == nil {
+		t.Errorf("authenticated from a removed signer")
+	}
+}
+
+func TestStaking(t *testing.T) {
+	h, err := New(2)
+	if err != nil {
+		t.Fatalf("failed to create harness: %v", err)
+	}
+	defer h.Close()
+
+	validators := []common.Address{common.HexToAddress("0x01"), common.HexToAddress("0x02")}
+	if err := h.Stake(h.Accounts[0], validators[0], big.NewInt(10)); err != nil {
+		t.Fatalf("failed to stake: %v", err)
+	}
+	if err := h.Stake(h.Accounts[1], validators[1], big.NewInt(20)); err != nil {
+		t.Fatalf("failed to stake: %v", err)
+	}
+	if err := h.Stake(h.Accounts[1], validators[0], big.NewInt(5)); err != nil {
+		t.Fatalf("failed to stake: %v", err)
+	}
+	addrs, powers, err := h.Validators()
+	if err != nil {
+		t.Fatalf("failed to retrieve validators: %v", err)
+	}
+	if !reflect.DeepEqual(addrs, validators) {
+		t.Errorf("validators mismatch: have %x, want %x", addrs, validators)
+	}
+	if want := []*big.Int{big.NewInt(15), big.NewInt(20)}; !reflect.DeepEqual(powers, want) {
+		t.Errorf("powers mismatch: have %v, want %v", powers, want)
+	}
+	if balance, _ := h.Backend.BalanceAt(context.Background(), h.StakingAddr, nil); balance.Cmp(big.NewInt(35)) != 0 {
+		t.Errorf("staked balance mismatch: have %v, want 35", balance)
+	}
+}
@@ -0,0 +1,57 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/qydata/go-ctereum/common"
+	"github.com/qydata/go-ctereum/core/types"
+	"github.com/qydata/go-ctereum/core/vm"
+	"github.com/qydata/go-ctereum/params"
+)
+
+// authOwnerSlot is the storage slot of the owner of the AuthController,
+// inherited from Ownable.
+var authOwnerSlot = common.Hash{}
+
+// VerifyAuthProof checks the auth proof of an authenticated transaction against
+// the AuthController live at the given block: the proof must authenticate the
+// sender, be signed by the owner of the controller and expire within at most
+// MaxAuthProofLifetime.
+//
+// The proof does not stand in for the authentication of the sender in the
+// controller, which is checked on inclusion as well so that revoking a sender
+// voids its outstanding proofs.
+func VerifyAuthProof(config *params.ChainConfig, statedb vm.StateDB, number *big.Int, time uint64, sender common.Address, proof *types.AuthProof) error {
+	if proof.AuthExpiry == nil || proof.AuthExpiry.Sign() == 0 || proof.AuthExpiry.Cmp(new(big.Int).SetUint64(time+params.MaxAuthProofLifetime)) > 0 {
+		return fmt.Errorf("%w: address %v, expiry %v", ErrAuthProofLifetime, sender.Hex(), proof.AuthExpiry)
+	}
+	if proof.Expired(time) {
+		return fmt.Errorf("%w: address %v, expiry %v", ErrAuthProofExpired, sender.Hex(), proof.AuthExpiry)
+	}
+	controller := config.AuthContractAt(number)
+	signer, err := proof.Signer(config.ChainID, controller, sender)
+	if err != nil {
+		return fmt.Errorf("%w: address %v", err, sender.Hex())
+	}
+	if owner := common.BytesToAddress(statedb.GetState(controller, authOwnerSlot).Bytes()); signer != owner {
+		return fmt.Errorf("%w: address %v, signer %v, owner %v", ErrAuthProofSigner, sender.Hex(), signer.Hex(), owner.Hex())
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/qydata/go-ctereum/common"
+	"github.com/qydata/go-ctereum/consensus/ethash"
+	"github.com/qydata/go-ctereum/core/rawdb"
+	"github.com/qydata/go-ctereum/core/state"
+	"github.com/qydata/go-ctereum/core/types"
+	"github.com/qydata/go-ctereum/core/vm"
+	"github.com/qydata/go-ctereum/crypto"
+	"github.com/qydata/go-ctereum/params"
+	"github.com/qydata/go-ctereum/rlp"
+)
+
+// Tests that authenticated transactions are included if their proof is signed
+// by the owner of the auth contract, bounded in time and its sender still
+// authenticated, and rejected otherwise.
+func TestAuthTxProcessing(t *testing.T) {
+	var (
+		controller = common.HexToAddress("0x000000000000000000000000000000000000a0a0")
+		key, _     = crypto.GenerateKey()
+		owner, _   = crypto.GenerateKey()
+		other, _   = crypto.GenerateKey()
+		sender     = crypto.PubkeyToAddress(key.PublicKey)
+		engine     = ethash.NewFaker()
+		db         = rawdb.NewMemoryDatabase()
+		config     = *params.TestChainConfig
+	)
+	config.AuthBlock = common.Big0
+	config.AuthContract = controller
+	config.AuthTxBlock = common.Big0
+
+	gspec := &Genesis{
+		Config: &config,
+		Alloc: GenesisAlloc{
+			sender: {Balance: big.NewInt(params.Ether)},
+			controller: {
+				Balance: new(big.Int),
+				// authsSingle(addr) returning the storage slot keyed by addr
+				Code: []byte{
+					byte(vm.PUSH1), 0x04, byte(vm.CALLDATALOAD), byte(vm.SLOAD),
+					byte(vm.PUSH1), 0x00, byte(vm.MSTORE),
+					byte(vm.PUSH1), 0x20, byte(vm.PUSH1), 0x00, byte(vm.RETURN),
+				},
+				Storage: map[common.Hash]common.Hash{
+					{}:                                 common.BytesToHash(crypto.PubkeyToAddress(owner.PublicKey).Bytes()),
+					common.BytesToHash(sender.Bytes()): common.BigToHash(common.Big1),
+				},
+			},
+		},
+	}
+	genesis := gspec.MustCommit(db)
+	signer := types.LatestSigner(gspec.Config)
+
+	authTx := func(nonce uint64, baseFee *big.Int, signee *ecdsa.PrivateKey, expiry int64) *types.Transaction {
+		proof := &types.AuthProof{AuthTime: big.NewInt(1), AuthExpiry: big.NewInt(expiry), AuthLevel: big.NewInt(1)}
+		hash := proof.Hash(config.ChainID, controller, sender)
+		proof.Signature, _ = crypto.Sign(hash[:], signee)
+		blob, _ := rlp.EncodeToBytes(proof)
+
+		return types.MustSignNewTx(key, signer, &types.AuthTx{
+			ChainID:   config.ChainID,
+			Nonce:     nonce,
+			GasTipCap: big.NewInt(1),
+			GasFeeCap: baseFee,
+			Gas:       params.TxGas,
+			To:        &common.Address{0x01},
+			Value:     new(big.Int),
+			AuthProof: blob,
+		})
+	}
+	blocks, _ := GenerateChain(gspec.Config, genesis, engine, db, 1, func(i int, b *BlockGen) {
+		b.AddTx(authTx(0, b.header.BaseFee, owner, int64(b.header.Time)+100))
+	})
+	chain, err := NewBlockChain(db, nil, gspec.Config, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if n, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("block %d: failed to insert into chain: %v", n, err)
+	}
+	receipts := chain.GetReceiptsByHash(blocks[0].Hash())
+	if len(receipts) != 1 || receipts[0].Type != types.AuthTxType || receipts[0].Status != types.ReceiptStatusSuccessful {
+		t.Fatalf("receipt mismatch: %+v", receipts)
+	}
+	// Proofs not signed by the owner, unbounded or expired ones and proofs of
+	// revoked senders are invalid
+	head := chain.CurrentBlock()
+	for i, tt := range []struct {
+		signee  *ecdsa.PrivateKey
+		expiry  int64
+		revoked bool
+		err     error
+	}{
+		{other, int64(head.Time()) + 100, false, ErrAuthProofSigner},
+		{owner, 0, false, ErrAuthProofLifetime},
+		{owner, int64(head.Time()+params.MaxAuthProofLifetime) + 100, false, ErrAuthProofLifetime},
+		{owner, 1, false, ErrAuthProofExpired},
+		{owner, int64(head.Time()) + 100, true, ErrAuthProofRevoked},
+		{owner, int64(head.Time()) + 100, false, nil},
+	} {
+		statedb, _ := state.New(head.Root(), state.NewDatabase(db), nil)
+		if tt.revoked {
+			statedb.SetState(controller, common.BytesToHash(sender.Bytes()), common.Hash{})
+		}
+		header := &types.Header{
+			ParentHash: head.Hash(),
+			Number:     new(big.Int).Add(head.Number(), common.Big1),
+			GasLimit:   head.GasLimit(),
+			Time:       head.Time() + 10,
+			Difficulty: head.Difficulty(),
+			BaseFee:    head.BaseFee(),
+		}
+		usedGas := new(uint64)
+		_, err := ApplyTransaction(gspec.Config, chain, nil, new(GasPool).AddGas(header.GasLimit), statedb, header, authTx(1, header.BaseFee, tt.signee, tt.expiry), usedGas, vm.Config{})
+		if !errors.Is(err, tt.err) {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, tt.err)
+		}
+	}
+}
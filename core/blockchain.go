@@ -43,6 +43,7 @@ import (
 	"github.com/qydata/go-ctereum/log"
 	"github.com/qydata/go-ctereum/metrics"
 	"github.com/qydata/go-ctereum/params"
+	"github.com/qydata/go-ctereum/rlp"
 	"github.com/qydata/go-ctereum/trie"
 )
 
@@ -131,6 +132,9 @@ type CacheConfig struct {
 	TrieTimeLimit       time.Duration // Time limit after which to flush the current in-memory trie to disk
 	SnapshotLimit       int           // Memory allowance (MB) to use for caching snapshot entries in memory
 	Preimages           bool          // Whether to store preimage of trie key to the disk
+	ParallelEVM         bool          // Whether to execute the transactions of imported blocks speculatively in parallel
+	StateDiffs          bool          // Whether to record and store the state changes made by every block
+	Witnesses           bool          // Whether to record and store the execution witness of every block
 
 	SnapshotWait bool // Wait for snapshot construction on startup. TODO(karalabe): This is a dirty hack for testing, nuke it
 }
@@ -145,6 +149,14 @@ var defaultCacheConfig = &CacheConfig{
 	SnapshotWait:   true,
 }
 
+// ImportFailure describes a block rejected during import.
+type ImportFailure struct {
+	Number uint64      // Number of the rejected block
+	Hash   common.Hash // Hash of the rejected block
+	Time   time.Time   // Time of the rejection
+	Err    error       // Reason of the rejection
+}
+
 // BlockChain represents the canonical chain given a database with a genesis
 // block. The Blockchain manages chain imports, reverts, chain reorganisations.
 //
@@ -173,13 +185,20 @@ type BlockChain struct {
 	//  * 0:   means no limit and regenerate any missing indexes
 	//  * N:   means N block limit [HEAD-N+1, HEAD] and delete extra indexes
 	//  * nil: disable tx reindexer/deleter, but still index new blocks
+	//
+	// It is accessed atomically, as it may be changed while the indexer runs.
 	txLookupLimit uint64
 
+	txIndexing    bool          // Whether the tx reindexer/deleter is running
+	txIndexPaused int32         // 1 if the tx reindexer/deleter is paused
+	txIndexUpdate chan struct{} // Notification channel for changes of the tx index window
+
 	hc            *HeaderChain
 	rmLogsFeed    event.Feed
 	chainFeed     event.Feed
 	chainSideFeed event.Feed
 	chainHeadFeed event.Feed
+	chain2Feed    event.Feed
 	logsFeed      event.Feed
 	blockProcFeed event.Feed
 	scope         event.SubscriptionScope
@@ -193,6 +212,7 @@ type BlockChain struct {
 	currentFastBlock      atomic.Value // Current head of the fast-sync chain (may be above the block chain!)
 	currentFinalizedBlock atomic.Value // Current finalized head
 	currentSafeBlock      atomic.Value // Current safe head
+	lastImportFailure     atomic.Value // Last block rejected during import (*ImportFailure)
 
 	stateCache    state.Database // State database to reuse between imports (contains state cache)
 	bodyCache     *lru.Cache     // Cache for the most recent block bodies
@@ -207,6 +227,8 @@ type BlockChain struct {
 	running       int32          // 0 if chain is running, 1 when stopped
 	procInterrupt int32          // interrupt signaler for block processing
 
+	recordPreimages uint32 // Whether the EVM records keccak256 preimages, toggled at runtime (atomic)
+
 	engine     consensus.Engine
 	validator  Validator // Block and state validator interface
 	prefetcher Prefetcher
@@ -240,6 +262,7 @@ func NewBlockChain(db ethdb.Database, cacheConfig *CacheConfig, chainConfig *par
 			Preimages: cacheConfig.Preimages,
 		}),
 		quit:          make(chan struct{}),
+		txIndexUpdate: make(chan struct{}, 1),
 		chainmu:       syncx.NewClosableMutex(),
 		bodyCache:     bodyCache,
 		bodyRLPCache:  bodyRLPCache,
@@ -391,6 +414,7 @@ func NewBlockChain(db ethdb.Database, cacheConfig *CacheConfig, chainConfig *par
 	// Start tx indexer/unindexer.
 	if txLookupLimit != nil {
 		bc.txLookupLimit = *txLookupLimit
+		bc.txIndexing = true
 
 		bc.wg.Add(1)
 		go bc.maintainTxIndex(txIndexBlock)
@@ -663,6 +687,9 @@ func (bc *BlockChain) setHeadBeyondRoot(head uint64, root common.Hash, repair bo
 			rawdb.DeleteBody(db, hash, num)
 			rawdb.DeleteReceipts(db, hash, num)
 		}
+		// State diffs and witnesses are never frozen, drop them from the active store
+		rawdb.DeleteStateDiff(db, hash, num)
+		rawdb.DeleteWitness(db, hash, num)
 		// Todo(rjl493456442) txlookup, bloombits, etc
 	}
 	// If SetHead was only called as a chain reparation method, try to skip
@@ -1054,9 +1081,12 @@ func (bc *BlockChain) InsertReceiptChain(blockChain types.Blocks, receiptChain [
 		// a background routine to re-indexed all indices in [ancients - txlookupLimit, ancients)
 		// range. In this case, all tx indices of newly imported blocks should be
 		// generated.
-		var batch = bc.db.NewBatch()
+		var (
+			batch = bc.db.NewBatch()
+			limit = bc.TxLookupLimit()
+		)
 		for i, block := range blockChain {
-			if bc.txLookupLimit == 0 || ancientLimit <= bc.txLookupLimit || block.NumberU64() >= ancientLimit-bc.txLookupLimit {
+			if limit == 0 || ancientLimit <= limit || block.NumberU64() >= ancientLimit-limit {
 				rawdb.WriteTxLookupEntriesByBlock(batch, block)
 			} else if rawdb.ReadTxIndexTail(bc.db) != nil {
 				rawdb.WriteTxLookupEntriesByBlock(batch, block)
@@ -1184,10 +1214,10 @@ func (bc *BlockChain) InsertReceiptChain(blockChain types.Blocks, receiptChain [
 		// * 0: all ancient blocks have been indexed
 		// * ancient-limit: the indices of blocks before ancient-limit are ignored
 		if tail := rawdb.ReadTxIndexTail(bc.db); tail == nil {
-			if bc.txLookupLimit == 0 || ancientLimit <= bc.txLookupLimit {
+			if limit := bc.TxLookupLimit(); limit == 0 || ancientLimit <= limit {
 				rawdb.WriteTxIndexTail(bc.db, 0)
 			} else {
-				rawdb.WriteTxIndexTail(bc.db, ancientLimit-bc.txLookupLimit)
+				rawdb.WriteTxIndexTail(bc.db, ancientLimit-limit)
 			}
 		}
 	}
@@ -1266,6 +1296,28 @@ func (bc *BlockChain) writeBlockWithState(block *types.Block, receipts []*types.
 	rawdb.WriteBlock(blockBatch, block)
 	rawdb.WriteReceipts(blockBatch, block.Hash(), block.NumberU64(), receipts)
 	rawdb.WritePreimages(blockBatch, state.Preimages())
+	diff, err := state.StateDiff()
+	if err != nil {
+		return err
+	}
+	if diff != nil {
+		blob, err := rlp.EncodeToBytes(diff)
+		if err != nil {
+			return err
+		}
+		rawdb.WriteStateDiffRLP(blockBatch, block.Hash(), block.NumberU64(), blob)
+	}
+	witness, err := state.Witness()
+	if err != nil {
+		return err
+	}
+	if witness != nil {
+		blob, err := rlp.EncodeToBytes(witness)
+		if err != nil {
+			return err
+		}
+		rawdb.WriteWitnessRLP(blockBatch, block.Hash(), block.NumberU64(), blob)
+	}
 	if err := blockBatch.Write(); err != nil {
 		log.Crit("Failed to write block into disk", "err", err)
 	}
@@ -1340,6 +1392,25 @@ func (bc *BlockChain) WriteBlockAndSetHead(block *types.Block, receipts []*types
 	return bc.writeBlockAndSetHead(block, receipts, logs, state, emitHeadEvent)
 }
 
+// sendChain2HeadEvent persists a chain head event into the reorg journal and
+// fires it to the subscribers. This function expects the chain mutex to be held.
+func (bc *BlockChain) sendChain2HeadEvent(ev Chain2HeadEvent) {
+	entry := &rawdb.ReorgJournalEntry{
+		Type:    ev.Type,
+		Time:    uint64(time.Now().Unix()),
+		Dropped: make([]rawdb.ReorgJournalBlock, len(ev.OldChain)),
+		Added:   make([]rawdb.ReorgJournalBlock, len(ev.NewChain)),
+	}
+	for i, block := range ev.OldChain {
+		entry.Dropped[i] = rawdb.ReorgJournalBlock{Number: block.NumberU64(), Hash: block.Hash()}
+	}
+	for i, block := range ev.NewChain {
+		entry.Added[i] = rawdb.ReorgJournalBlock{Number: block.NumberU64(), Hash: block.Hash()}
+	}
+	rawdb.WriteReorgJournalEntry(bc.db, entry)
+	bc.chain2Feed.Send(ev)
+}
+
 // writeBlockAndSetHead is the internal implementation of WriteBlockAndSetHead.
 // This function expects the chain mutex to be held.
 func (bc *BlockChain) writeBlockAndSetHead(block *types.Block, receipts []*types.Receipt, logs []*types.Log, state *state.StateDB, emitHeadEvent bool) (status WriteStatus, err error) {
@@ -1381,8 +1452,16 @@ func (bc *BlockChain) writeBlockAndSetHead(block *types.Block, receipts []*types
 		if emitHeadEvent {
 			bc.chainHeadFeed.Send(ChainHeadEvent{Block: block})
 		}
+		bc.sendChain2HeadEvent(Chain2HeadEvent{
+			Type:     Chain2HeadCanonicalEvent,
+			NewChain: []*types.Block{block},
+		})
 	} else {
 		bc.chainSideFeed.Send(ChainSideEvent{Block: block})
+		bc.sendChain2HeadEvent(Chain2HeadEvent{
+			Type:     Chain2HeadForkEvent,
+			NewChain: []*types.Block{block},
+		})
 	}
 	return status, nil
 }
@@ -1643,6 +1722,12 @@ func (bc *BlockChain) insertChain(chain types.Blocks, verifySeals, setHead bool)
 		if err != nil {
 			return it.index, err
 		}
+		if bc.cacheConfig.StateDiffs {
+			statedb.RecordDiffs()
+		}
+		if bc.cacheConfig.Witnesses {
+			statedb.RecordWitness()
+		}
 
 		// Enable prefetching to pull in trie node paths while processing transactions
 		statedb.StartPrefetcher("chain")
@@ -1668,7 +1753,11 @@ func (bc *BlockChain) insertChain(chain types.Blocks, verifySeals, setHead bool)
 
 		// Process block using the parent state as reference point
 		substart := time.Now()
-		receipts, logs, usedGas, err := bc.processor.Process(block, statedb, bc.vmConfig)
+		vmConfig := bc.vmConfig
+		if atomic.LoadUint32(&bc.recordPreimages) == 1 {
+			vmConfig.EnablePreimageRecording = true
+		}
+		receipts, logs, usedGas, err := bc.processor.Process(block, statedb, vmConfig)
 		if err != nil {
 			bc.reportBlock(block, receipts, err)
 			atomic.StoreUint32(&followupInterrupt, 1)
@@ -2154,6 +2243,13 @@ func (bc *BlockChain) reorg(oldBlock, newBlock *types.Block) error {
 			bc.chainSideFeed.Send(ChainSideEvent{Block: oldChain[i]})
 		}
 	}
+	if len(oldChain) > 0 && len(newChain) > 0 {
+		bc.sendChain2HeadEvent(Chain2HeadEvent{
+			Type:     Chain2HeadReorgEvent,
+			NewChain: newChain,
+			OldChain: oldChain,
+		})
+	}
 	return nil
 }
 
@@ -2204,6 +2300,10 @@ func (bc *BlockChain) SetCanonical(head *types.Block) (common.Hash, error) {
 		bc.logsFeed.Send(logs)
 	}
 	bc.chainHeadFeed.Send(ChainHeadEvent{Block: head})
+	bc.sendChain2HeadEvent(Chain2HeadEvent{
+		Type:     Chain2HeadCanonicalEvent,
+		NewChain: []*types.Block{head},
+	})
 
 	context := []interface{}{
 		"number", head.Number(),
@@ -2292,30 +2392,31 @@ func (bc *BlockChain) maintainTxIndex(ancients uint64) {
 	// pruning requests.
 	if ancients > 0 {
 		var from = uint64(0)
-		if bc.txLookupLimit != 0 && ancients > bc.txLookupLimit {
-			from = ancients - bc.txLookupLimit
+		if limit := bc.TxLookupLimit(); limit != 0 && ancients > limit {
+			from = ancients - limit
 		}
 		rawdb.IndexTransactions(bc.db, from, ancients, bc.quit)
 	}
 
 	// indexBlocks reindexes or unindexes transactions depending on user configuration
-	indexBlocks := func(tail *uint64, head uint64, done chan struct{}) {
+	indexBlocks := func(tail *uint64, head uint64, interrupt chan struct{}, done chan struct{}) {
 		defer func() { done <- struct{}{} }()
 
 		// If the user just upgraded Geth to a new version which supports transaction
 		// index pruning, write the new tail and remove anything older.
+		limit := bc.TxLookupLimit()
 		if tail == nil {
-			if bc.txLookupLimit == 0 || head < bc.txLookupLimit {
+			if limit == 0 || head < limit {
 				// Nothing to delete, write the tail and return
 				rawdb.WriteTxIndexTail(bc.db, 0)
 			} else {
 				// Prune all stale tx indices and record the tx index tail
-				rawdb.UnindexTransactions(bc.db, 0, head-bc.txLookupLimit+1, bc.quit)
+				rawdb.UnindexTransactions(bc.db, 0, head-limit+1, interrupt)
 			}
 			return
 		}
 		// If a previous indexing existed, make sure that we fill in any missing entries
-		if bc.txLookupLimit == 0 || head < bc.txLookupLimit {
+		if limit == 0 || head < limit {
 			if *tail > 0 {
 				// It can happen when chain is rewound to a historical point which
 				// is even lower than the indexes tail, recap the indexing target
@@ -2324,24 +2425,27 @@ func (bc *BlockChain) maintainTxIndex(ancients uint64) {
 				if end > head+1 {
 					end = head + 1
 				}
-				rawdb.IndexTransactions(bc.db, 0, end, bc.quit)
+				rawdb.IndexTransactions(bc.db, 0, end, interrupt)
 			}
 			return
 		}
 		// Update the transaction index to the new chain state
-		if head-bc.txLookupLimit+1 < *tail {
+		if head-limit+1 < *tail {
 			// Reindex a part of missing indices and rewind index tail to HEAD-limit
-			rawdb.IndexTransactions(bc.db, head-bc.txLookupLimit+1, *tail, bc.quit)
+			rawdb.IndexTransactions(bc.db, head-limit+1, *tail, interrupt)
 		} else {
 			// Unindex a part of stale indices and forward index tail to HEAD-limit
-			rawdb.UnindexTransactions(bc.db, *tail, head-bc.txLookupLimit+1, bc.quit)
+			rawdb.UnindexTransactions(bc.db, *tail, head-limit+1, interrupt)
 		}
 	}
 
 	// Any reindexing done, start listening to chain events and moving the index window
 	var (
-		done   chan struct{}                  // Non-nil if background unindexing or reindexing routine is active.
-		headCh = make(chan ChainHeadEvent, 1) // Buffered to avoid locking up the event feed
+		done      chan struct{}                  // Non-nil if background unindexing or reindexing routine is active.
+		interrupt chan struct{}                  // Closed to abort the active routine, on pause or shutdown
+		pending   bool                           // Whether the index window moved while a routine was active
+		head      uint64                         // Latest chain head the index window is anchored to
+		headCh    = make(chan ChainHeadEvent, 1) // Buffered to avoid locking up the event feed
 	)
 	sub := bc.SubscribeChainHeadEvent(headCh)
 	if sub == nil {
@@ -2349,18 +2453,44 @@ func (bc *BlockChain) maintainTxIndex(ancients uint64) {
 	}
 	defer sub.Unsubscribe()
 
+	run := func() {
+		if done != nil {
+			pending = true
+			return
+		}
+		if atomic.LoadInt32(&bc.txIndexPaused) == 1 {
+			return
+		}
+		done, interrupt = make(chan struct{}), make(chan struct{})
+		go indexBlocks(rawdb.ReadTxIndexTail(bc.db), head, interrupt, done)
+	}
 	for {
 		select {
-		case head := <-headCh:
-			if done == nil {
-				done = make(chan struct{})
-				go indexBlocks(rawdb.ReadTxIndexTail(bc.db), head.Block.NumberU64(), done)
+		case ev := <-headCh:
+			head = ev.Block.NumberU64()
+			run()
+		case <-bc.txIndexUpdate:
+			if atomic.LoadInt32(&bc.txIndexPaused) == 1 {
+				if interrupt != nil {
+					close(interrupt)
+					interrupt = nil
+				}
+				continue
 			}
+			head = bc.CurrentBlock().NumberU64()
+			run()
 		case <-done:
-			done = nil
+			done, interrupt = nil, nil
+			if pending {
+				pending = false
+				run()
+			}
 		case <-bc.quit:
 			if done != nil {
 				log.Info("Waiting background transaction indexer to exit")
+				if interrupt != nil {
+					close(interrupt)
+				}
 				<-done
 			}
 			return
@@ -2368,9 +2498,108 @@ func (bc *BlockChain) maintainTxIndex(ancients uint64) {
 	}
 }
 
+// notifyTxIndexer wakes the tx indexer up to act on a change of its settings.
+func (bc *BlockChain) notifyTxIndexer() {
+	select {
+	case bc.txIndexUpdate <- struct{}{}:
+	default:
+	}
+}
+
+// TxIndexProgress is the status of the transaction indexer.
+type TxIndexProgress struct {
+	Limit     uint64  // Number of recent blocks whose transactions are indexed, 0 for all
+	Tail      *uint64 // Oldest block with indexed transactions, nil if unknown
+	Head      uint64  // Current head of the chain
+	Remaining uint64  // Number of blocks left to index or unindex to reach the limit
+	Paused    bool    // Whether the indexer is paused
+}
+
+// Done reports whether the transaction index matches the configured limit.
+func (prog TxIndexProgress) Done() bool {
+	return prog.Tail != nil && prog.Remaining == 0
+}
+
+// TxIndexProgress returns the status of the transaction indexer. It returns an
+// error if the indexer is not running, i.e. no lookup limit was configured.
+func (bc *BlockChain) TxIndexProgress() (TxIndexProgress, error) {
+	if !bc.txIndexing {
+		return TxIndexProgress{}, errors.New("transaction indexer not running")
+	}
+	prog := TxIndexProgress{
+		Limit:  bc.TxLookupLimit(),
+		Tail:   rawdb.ReadTxIndexTail(bc.db),
+		Head:   bc.CurrentBlock().NumberU64(),
+		Paused: atomic.LoadInt32(&bc.txIndexPaused) == 1,
+	}
+	if prog.Tail == nil {
+		return prog, nil
+	}
+	var target uint64
+	if prog.Limit != 0 && prog.Head >= prog.Limit {
+		target = prog.Head - prog.Limit + 1
+	}
+	if *prog.Tail > target {
+		prog.Remaining = *prog.Tail - target
+	} else {
+		prog.Remaining = target - *prog.Tail
+	}
+	return prog, nil
+}
+
+// PauseTxIndexing stops the transaction indexer, aborting the reindexing or
+// unindexing in progress. Its progress is kept and continued on resumption.
+func (bc *BlockChain) PauseTxIndexing() error {
+	if !bc.txIndexing {
+		return errors.New("transaction indexer not running")
+	}
+	atomic.StoreInt32(&bc.txIndexPaused, 1)
+	bc.notifyTxIndexer()
+	return nil
+}
+
+// ResumeTxIndexing restarts a paused transaction indexer.
+func (bc *BlockChain) ResumeTxIndexing() error {
+	if !bc.txIndexing {
+		return errors.New("transaction indexer not running")
+	}
+	atomic.StoreInt32(&bc.txIndexPaused, 0)
+	bc.notifyTxIndexer()
+	return nil
+}
+
+// SetTxIndexTail moves the oldest block with indexed transactions to the given
+// one, reindexing or unindexing blocks as needed. The tail keeps following the
+// chain head at the same distance afterwards, as the lookup limit is adjusted
+// accordingly.
+func (bc *BlockChain) SetTxIndexTail(tail uint64) error {
+	if !bc.txIndexing {
+		return errors.New("transaction indexer not running")
+	}
+	head := bc.CurrentBlock().NumberU64()
+	if tail > head {
+		return fmt.Errorf("tail %d beyond chain head %d", tail, head)
+	}
+	limit := head - tail + 1
+	if tail == 0 {
+		limit = 0
+	}
+	bc.SetTxLookupLimit(limit)
+	return nil
+}
+
 // reportBlock logs a bad block error.
 func (bc *BlockChain) reportBlock(block *types.Block, receipts types.Receipts, err error) {
 	rawdb.WriteBadBlock(bc.db, block)
+	bc.lastImportFailure.Store(&ImportFailure{
+		Number: block.NumberU64(),
+		Hash:   block.Hash(),
+		Time:   time.Now(),
+		Err:    err,
+	})
+	if coded, ok := consensus.CodeOf(err); ok {
+		log.Error("Block rejected by consensus engine", "number", block.Number(), "hash", block.Hash(), "code", coded.ErrorCode(), "name", coded.Name(), "err", err)
+	}
 
 	var receiptString string
 	for i, receipt := range receipts {
@@ -2391,6 +2620,13 @@ Error: %v
 `, bc.chainConfig, block.Number(), block.Hash(), receiptString, err))
 }
 
+// LastImportFailure returns the last block rejected during import, or nil if
+// no block was rejected since startup.
+func (bc *BlockChain) LastImportFailure() *ImportFailure {
+	failure, _ := bc.lastImportFailure.Load().(*ImportFailure)
+	return failure
+}
+
 // InsertHeaderChain attempts to insert the given header chain in to the local
 // chain, possibly creating a reorg. If an error is returned, it will return the
 // index number of the failing header as well an error describing what went wrong.
@@ -2416,6 +2652,20 @@ func (bc *BlockChain) InsertHeaderChain(chain []*types.Header, checkFreq int) (i
 	return 0, err
 }
 
+// SetPreimageRecording enables or disables recording the preimages of the trie
+// keys and of the keccak256 hashes computed by the EVM while importing blocks,
+// overriding the configured settings.
+func (bc *BlockChain) SetPreimageRecording(enabled bool) {
+	bc.stateCache.TrieDB().SetPreimageRecording(enabled)
+
+	var flag uint32
+	if enabled {
+		flag = 1
+	}
+	atomic.StoreUint32(&bc.recordPreimages, flag)
+	log.Info("Updated preimage recording", "enabled", enabled)
+}
+
 // SetBlockValidatorAndProcessorForTesting sets the current validator and processor.
 // This method can be used to force an invalid blockchain to be verified for tests.
 // This method is unsafe and should only be used before block import starts.
@@ -18,6 +18,7 @@ package core
 
 import (
 	"math/big"
+	"sync/atomic"
 
 	"github.com/qydata/go-ctereum/common"
 	"github.com/qydata/go-ctereum/consensus"
@@ -225,6 +226,74 @@ func (bc *BlockChain) GetReceiptsByHash(hash common.Hash) types.Receipts {
 	return receipts
 }
 
+// ReorgHistory retrieves up to count of the most recent chain head events from
+// the reorg journal, newest first.
+func (bc *BlockChain) ReorgHistory(count int) []*rawdb.ReorgJournalEntry {
+	return rawdb.ReadReorgJournal(bc.db, count)
+}
+
+// GetStateDiff retrieves the state changes made by a block, or nil if they were
+// not recorded.
+func (bc *BlockChain) GetStateDiff(hash common.Hash) (*state.StateDiff, error) {
+	number := rawdb.ReadHeaderNumber(bc.db, hash)
+	if number == nil {
+		return nil, nil
+	}
+	blob := rawdb.ReadStateDiffRLP(bc.db, hash, *number)
+	if blob == nil {
+		return nil, nil
+	}
+	diff := new(state.StateDiff)
+	if err := rlp.DecodeBytes(blob, diff); err != nil {
+		return nil, err
+	}
+	return diff, nil
+}
+
+// StateDiffsEnabled reports whether the state changes made by blocks are
+// recorded, in which case states blocks are built on should record them too.
+func (bc *BlockChain) StateDiffsEnabled() bool {
+	return bc.cacheConfig.StateDiffs
+}
+
+// GetWitness retrieves the execution witness of a block. Witnesses not stored
+// on import are generated by executing the block again, which requires the
+// state of its parent to be available.
+func (bc *BlockChain) GetWitness(block *types.Block) (*state.Witness, error) {
+	if blob := rawdb.ReadWitnessRLP(bc.db, block.Hash(), block.NumberU64()); blob != nil {
+		witness := new(state.Witness)
+		if err := rlp.DecodeBytes(blob, witness); err != nil {
+			return nil, err
+		}
+		return witness, nil
+	}
+	parent := bc.GetHeader(block.ParentHash(), block.NumberU64()-1)
+	if parent == nil {
+		return nil, consensus.ErrUnknownAncestor
+	}
+	statedb, err := state.New(parent.Root, bc.stateCache, bc.snaps)
+	if err != nil {
+		return nil, err
+	}
+	statedb.RecordWitness()
+	if _, _, _, err := bc.processor.Process(block, statedb, bc.vmConfig); err != nil {
+		return nil, err
+	}
+	return statedb.Witness()
+}
+
+// WitnessesEnabled reports whether the execution witnesses of blocks are
+// recorded, in which case states blocks are built on should record them too.
+func (bc *BlockChain) WitnessesEnabled() bool {
+	return bc.cacheConfig.Witnesses
+}
+
+// PreimageRecording reports whether the preimages of the trie keys are recorded
+// while importing blocks.
+func (bc *BlockChain) PreimageRecording() bool {
+	return bc.stateCache.TrieDB().PreimageRecording()
+}
+
 // GetUnclesInChain retrieves all the uncles from a given block backwards until
 // a specific distance is reached.
 func (bc *BlockChain) GetUnclesInChain(block *types.Block, length int) []*types.Header {
@@ -367,13 +436,14 @@ func (bc *BlockChain) GetVMConfig() *vm.Config {
 // SetTxLookupLimit is responsible for updating the txlookup limit to the
 // original one stored in db if the new mismatches with the old one.
 func (bc *BlockChain) SetTxLookupLimit(limit uint64) {
-	bc.txLookupLimit = limit
+	atomic.StoreUint64(&bc.txLookupLimit, limit)
+	bc.notifyTxIndexer()
 }
 
 // TxLookupLimit retrieves the txlookup limit used by blockchain to prune
 // stale transaction indices.
 func (bc *BlockChain) TxLookupLimit() uint64 {
-	return bc.txLookupLimit
+	return atomic.LoadUint64(&bc.txLookupLimit)
 }
 
 // SubscribeRemovedLogsEvent registers a subscription of RemovedLogsEvent.
@@ -391,6 +461,11 @@ func (bc *BlockChain) SubscribeChainHeadEvent(ch chan<- ChainHeadEvent) event.Su
 	return bc.scope.Track(bc.chainHeadFeed.Subscribe(ch))
 }
 
+// SubscribeChain2HeadEvent registers a subscription of Chain2HeadEvent.
+func (bc *BlockChain) SubscribeChain2HeadEvent(ch chan<- Chain2HeadEvent) event.Subscription {
+	return bc.scope.Track(bc.chain2Feed.Subscribe(ch))
+}
+
 // SubscribeChainSideEvent registers a subscription of ChainSideEvent.
 func (bc *BlockChain) SubscribeChainSideEvent(ch chan<- ChainSideEvent) event.Subscription {
 	return bc.scope.Track(bc.chainSideFeed.Subscribe(ch))
@@ -0,0 +1,134 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/qydata/go-ctereum/common"
+	"github.com/qydata/go-ctereum/consensus/ethash"
+	"github.com/qydata/go-ctereum/core/rawdb"
+	"github.com/qydata/go-ctereum/core/state"
+	"github.com/qydata/go-ctereum/core/types"
+	"github.com/qydata/go-ctereum/core/vm"
+	"github.com/qydata/go-ctereum/crypto"
+	"github.com/qydata/go-ctereum/params"
+)
+
+// Tests that the state changes made by imported blocks are recorded and stored
+// when enabled, and that they are dropped along with side chain blocks.
+func TestStateDiffRecording(t *testing.T) {
+	var (
+		// The counter contract increments slot 0 on every call
+		counter = common.HexToAddress("0x000000000000000000000000000000000000c0c0")
+		code    = []byte{
+			byte(vm.PUSH1), 0x00, byte(vm.SLOAD),
+			byte(vm.PUSH1), 0x01, byte(vm.ADD),
+			byte(vm.PUSH1), 0x00, byte(vm.SSTORE),
+			byte(vm.STOP),
+		}
+		key, _    = crypto.GenerateKey()
+		sender    = crypto.PubkeyToAddress(key.PublicKey)
+		recipient = common.Address{0x10}
+		coinbase  = common.Address{0x01}
+		engine    = ethash.NewFaker()
+		db        = rawdb.NewMemoryDatabase()
+		funds     = big.NewInt(params.Ether)
+		gspec     = &Genesis{
+			Config: params.TestChainConfig,
+			Alloc: GenesisAlloc{
+				sender:  {Balance: funds},
+				counter: {Code: code, Balance: new(big.Int)},
+			},
+		}
+		genesis = gspec.MustCommit(db)
+		signer  = types.LatestSigner(gspec.Config)
+	)
+	blocks, _ := GenerateChain(gspec.Config, genesis, engine, db, 1, func(i int, b *BlockGen) {
+		b.SetCoinbase(coinbase)
+		tx, _ := types.SignTx(types.NewTransaction(0, recipient, big.NewInt(1000), params.TxGas, b.header.BaseFee, nil), signer, key)
+		b.AddTx(tx)
+		tx, _ = types.SignTx(types.NewTransaction(1, counter, new(big.Int), 50000, b.header.BaseFee, nil), signer, key)
+		b.AddTx(tx)
+	})
+	diskdb := rawdb.NewMemoryDatabase()
+	gspec.MustCommit(diskdb)
+
+	cacheConfig := *defaultCacheConfig
+	cacheConfig.StateDiffs = true
+	chain, err := NewBlockChain(diskdb, &cacheConfig, gspec.Config, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if n, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("block %d: failed to insert into chain: %v", n, err)
+	}
+	diff, err := chain.GetStateDiff(blocks[0].Hash())
+	if err != nil {
+		t.Fatalf("failed to retrieve state diff: %v", err)
+	}
+	if diff == nil {
+		t.Fatalf("state diff not recorded")
+	}
+	accounts := make(map[common.Address]*state.AccountDiff)
+	for _, account := range diff.Accounts {
+		accounts[account.Address] = account
+	}
+	if len(accounts) != 4 {
+		t.Fatalf("changed account count mismatch: have %d, want %d", len(accounts), 4)
+	}
+	// The sender paid the transfer and the fees for both transactions
+	statedb, _ := chain.State()
+	if account := accounts[sender]; account == nil {
+		t.Errorf("sender change missing")
+	} else {
+		want := statedb.GetBalance(sender)
+		if account.Prev.Balance.Cmp(funds) != 0 || account.Post.Balance.Cmp(want) != 0 {
+			t.Errorf("sender balance mismatch: have %v -> %v, want %v -> %v", account.Prev.Balance, account.Post.Balance, funds, want)
+		}
+		if account.Prev.Nonce != 0 || account.Post.Nonce != 2 {
+			t.Errorf("sender nonce mismatch: have %d -> %d, want 0 -> 2", account.Prev.Nonce, account.Post.Nonce)
+		}
+	}
+	// The recipient was created by the block
+	if account := accounts[recipient]; account == nil {
+		t.Errorf("recipient change missing")
+	} else if account.Prev != nil || account.Post == nil || account.Post.Balance.Cmp(big.NewInt(1000)) != 0 {
+		t.Errorf("recipient change mismatch: have %+v -> %+v", account.Prev, account.Post)
+	}
+	// The counter only changed in storage
+	if account := accounts[counter]; account == nil {
+		t.Errorf("counter change missing")
+	} else {
+		if !(len(account.Storage) == 1 && account.Storage[0].Post == common.BigToHash(common.Big1) && account.Storage[0].Prev == (common.Hash{})) {
+			t.Errorf("counter storage mismatch: have %+v", account.Storage)
+		}
+	}
+	if accounts[coinbase] == nil {
+		t.Errorf("coinbase change missing")
+	}
+	// Rewinding the chain drops the diffs of the removed blocks
+	if err := chain.SetHead(0); err != nil {
+		t.Fatalf("failed to rewind chain: %v", err)
+	}
+	if blob := rawdb.ReadStateDiffRLP(diskdb, blocks[0].Hash(), 1); blob != nil {
+		t.Errorf("state diff retained after rewind")
+	}
+}
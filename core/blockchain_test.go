@@ -604,6 +604,11 @@ func testBadHashes(t *testing.T, full bool) {
 		defer func() { delete(BadHashes, blocks[2].Header().Hash()) }()
 
 		_, err = blockchain.InsertChain(blocks)
+
+		failure := blockchain.LastImportFailure()
+		if failure == nil || failure.Hash != blocks[2].Hash() || !errors.Is(failure.Err, ErrBannedHash) {
+			t.Errorf("import failure mismatch: have %+v, want block %x", failure, blocks[2].Hash())
+		}
 	} else {
 		headers := makeHeaderChain(blockchain.CurrentHeader(), 3, ethash.NewFaker(), db, 10)
 
@@ -1365,6 +1370,99 @@ done:
 	}
 }
 
+// Tests that a reorg emits a Chain2HeadEvent carrying both the dropped and the
+// added chain segments, surrounded by the expected fork and head events.
+func TestChain2HeadEvent(t *testing.T) {
+	var (
+		db      = rawdb.NewMemoryDatabase()
+		key1, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr1   = crypto.PubkeyToAddress(key1.PublicKey)
+		gspec   = &Genesis{
+			Config: params.TestChainConfig,
+			Alloc:  GenesisAlloc{addr1: {Balance: big.NewInt(10000000000000000)}},
+		}
+		genesis = gspec.MustCommit(db)
+		signer  = types.LatestSigner(gspec.Config)
+	)
+
+	blockchain, _ := NewBlockChain(db, nil, gspec.Config, ethash.NewFaker(), vm.Config{}, nil, nil)
+	defer blockchain.Stop()
+
+	chain2HeadCh := make(chan Chain2HeadEvent, 64)
+	blockchain.SubscribeChain2HeadEvent(chain2HeadCh)
+
+	chain, _ := GenerateChain(gspec.Config, genesis, ethash.NewFaker(), db, 3, func(i int, gen *BlockGen) {})
+	if _, err := blockchain.InsertChain(chain); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+
+	replacementBlocks, _ := GenerateChain(gspec.Config, genesis, ethash.NewFaker(), db, 4, func(i int, gen *BlockGen) {
+		tx, err := types.SignTx(types.NewContractCreation(gen.TxNonce(addr1), new(big.Int), 1000000, gen.header.BaseFee, nil), signer, key1)
+		if i == 2 {
+			gen.OffsetTime(-9)
+		}
+		if err != nil {
+			t.Fatalf("failed to create tx: %v", err)
+		}
+		gen.AddTx(tx)
+	})
+	if _, err := blockchain.InsertChain(replacementBlocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+
+	type eventResult struct {
+		hash common.Hash
+		typ  string
+	}
+	expected := []eventResult{
+		{chain[0].Hash(), Chain2HeadCanonicalEvent},
+		{chain[1].Hash(), Chain2HeadCanonicalEvent},
+		{chain[2].Hash(), Chain2HeadCanonicalEvent},
+		{replacementBlocks[0].Hash(), Chain2HeadForkEvent},
+		{replacementBlocks[1].Hash(), Chain2HeadForkEvent},
+		{replacementBlocks[2].Hash(), Chain2HeadReorgEvent},
+		{replacementBlocks[2].Hash(), Chain2HeadCanonicalEvent},
+		{replacementBlocks[3].Hash(), Chain2HeadCanonicalEvent},
+	}
+	for i, want := range expected {
+		select {
+		case ev := <-chain2HeadCh:
+			if ev.Type != want.typ {
+				t.Fatalf("event %d: type mismatch: have %s, want %s", i, ev.Type, want.typ)
+			}
+			if have := ev.NewChain[0].Hash(); have != want.hash {
+				t.Fatalf("event %d: head mismatch: have %x, want %x", i, have, want.hash)
+			}
+			if ev.Type != Chain2HeadReorgEvent {
+				if len(ev.NewChain) != 1 || len(ev.OldChain) != 0 {
+					t.Fatalf("event %d: unexpected segments: new %d, old %d", i, len(ev.NewChain), len(ev.OldChain))
+				}
+				continue
+			}
+			// Reorg segments are ordered from the tip down to the common ancestor
+			if len(ev.OldChain) != 3 || len(ev.NewChain) != 3 {
+				t.Fatalf("event %d: reorg segment length mismatch: new %d, old %d", i, len(ev.NewChain), len(ev.OldChain))
+			}
+			for j := 0; j < 3; j++ {
+				if have, want := ev.OldChain[j].Hash(), chain[2-j].Hash(); have != want {
+					t.Errorf("event %d: old chain %d mismatch: have %x, want %x", i, j, have, want)
+				}
+				if have, want := ev.NewChain[j].Hash(), replacementBlocks[2-j].Hash(); have != want {
+					t.Errorf("event %d: new chain %d mismatch: have %x, want %x", i, j, have, want)
+				}
+			}
+		case <-time.After(10 * time.Second):
+			t.Fatalf("event %d: timeout waiting for %s event", i, want.typ)
+		}
+	}
+	// make sure no more events are fired
+	select {
+	case e := <-chain2HeadCh:
+		t.Errorf("unexpected event fired: %s %x", e.Type, e.NewChain[0].Hash())
+	case <-time.After(250 * time.Millisecond):
+	}
+}
+
 // Tests if the canonical block can be fetched from the database during chain insertion.
 func TestCanonicalBlockRetrieval(t *testing.T) {
 	_, blockchain, err := newCanonical(ethash.NewFaker(), 0, true)
@@ -2613,6 +2711,91 @@ func TestTransactionIndices(t *testing.T) {
 	}
 }
 
+// Tests that the transaction indexer can be paused, resumed and steered to a
+// new tail while running.
+func TestTxIndexControl(t *testing.T) {
+	var (
+		db      = rawdb.NewMemoryDatabase()
+		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		address = crypto.PubkeyToAddress(key.PublicKey)
+		funds   = big.NewInt(100000000000000000)
+		gspec   = &Genesis{
+			Config:  params.TestChainConfig,
+			Alloc:   GenesisAlloc{address: {Balance: funds}},
+			BaseFee: big.NewInt(params.InitialBaseFee),
+		}
+		genesis = gspec.MustCommit(db)
+		signer  = types.LatestSigner(gspec.Config)
+	)
+	blocks, _ := GenerateChain(gspec.Config, genesis, ethash.NewFaker(), db, 64, func(i int, block *BlockGen) {
+		tx, err := types.SignTx(types.NewTransaction(block.TxNonce(address), common.Address{0x00}, big.NewInt(1000), params.TxGas, block.header.BaseFee, nil), signer, key)
+		if err != nil {
+			panic(err)
+		}
+		block.AddTx(tx)
+	})
+	limit := uint64(0)
+	chain, err := NewBlockChain(db, nil, params.TestChainConfig, ethash.NewFaker(), vm.Config{}, nil, &limit)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if n, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("block %d: failed to insert into chain: %v", n, err)
+	}
+	wait := func(tail uint64) {
+		t.Helper()
+		for i := 0; i < 500; i++ {
+			if prog, _ := chain.TxIndexProgress(); prog.Done() && *prog.Tail == tail {
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		prog, _ := chain.TxIndexProgress()
+		t.Fatalf("indexer stuck: %+v", prog)
+	}
+	indexed := func(number uint64) bool {
+		tx := blocks[number-1].Transactions()[0]
+		return rawdb.ReadTxLookupEntry(db, tx.Hash()) != nil
+	}
+	wait(0)
+
+	// Move the tail forward, unindexing the old blocks
+	if err := chain.SetTxIndexTail(40); err != nil {
+		t.Fatalf("failed to set tail: %v", err)
+	}
+	wait(40)
+	if indexed(39) || !indexed(40) {
+		t.Fatalf("unindexing mismatch: block 39 indexed %v, block 40 indexed %v", indexed(39), indexed(40))
+	}
+	if limit := chain.TxLookupLimit(); limit != 25 {
+		t.Fatalf("lookup limit mismatch: have %d, want %d", limit, 25)
+	}
+	// Pause the indexer and move the tail back, nothing should happen
+	if err := chain.PauseTxIndexing(); err != nil {
+		t.Fatalf("failed to pause indexer: %v", err)
+	}
+	if err := chain.SetTxIndexTail(10); err != nil {
+		t.Fatalf("failed to set tail: %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if prog, _ := chain.TxIndexProgress(); !prog.Paused || *prog.Tail != 40 || prog.Remaining != 30 {
+		t.Fatalf("paused progress mismatch: %+v", prog)
+	}
+	// Resume the indexer, reindexing the missing blocks
+	if err := chain.ResumeTxIndexing(); err != nil {
+		t.Fatalf("failed to resume indexer: %v", err)
+	}
+	wait(10)
+	if indexed(9) || !indexed(10) {
+		t.Fatalf("reindexing mismatch: block 9 indexed %v, block 10 indexed %v", indexed(9), indexed(10))
+	}
+	if err := chain.SetTxIndexTail(65); err == nil {
+		t.Fatalf("tail beyond head accepted")
+	}
+}
+
 func TestSkipStaleTxIndicesInSnapSync(t *testing.T) {
 	// Configure and generate a sample block chain
 	var (
@@ -3865,3 +4048,135 @@ func TestCanonicalHashMarker(t *testing.T) {
 		}
 	}
 }
+
+// Tests that the priority fees are withheld from the block producer once the
+// community fund split is active, left for the engine to split when finalizing.
+func TestCommunityFundTips(t *testing.T) {
+	var (
+		aa   = common.HexToAddress("0x000000000000000000000000000000000000aaaa")
+		fund = common.HexToAddress("0x000000000000000000000000000000000000f00d")
+
+		engine = ethash.NewFaker()
+		db     = rawdb.NewMemoryDatabase()
+
+		key1, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr1   = crypto.PubkeyToAddress(key1.PublicKey)
+		funds   = new(big.Int).Mul(common.Big1, big.NewInt(params.Ether))
+		config  = *params.AllEthashProtocolChanges
+	)
+	config.BerlinBlock = common.Big0
+	config.LondonBlock = common.Big0
+	// Only the state transition is exercised, the engine splitting the tips
+	config.Clique = &params.CliqueConfig{Period: 1, Epoch: 30000}
+	config.CommunityFund = &params.CommunityFundConfig{
+		Block:      common.Big2,
+		Address:    fund,
+		Percentage: 30,
+	}
+	gspec := &Genesis{
+		Config:    &config,
+		ExtraData: append(append(make([]byte, 32), addr1[:]...), make([]byte, crypto.SignatureLength)...),
+		Alloc:     GenesisAlloc{addr1: {Balance: funds}},
+	}
+	genesis := gspec.MustCommit(db)
+	signer := types.LatestSigner(gspec.Config)
+
+	GenerateChain(gspec.Config, genesis, engine, db, 2, func(i int, b *BlockGen) {
+		coinbase := common.Address{byte(i + 1)}
+		b.SetCoinbase(coinbase)
+
+		tx, _ := types.SignTx(types.NewTx(&types.DynamicFeeTx{
+			ChainID:   gspec.Config.ChainID,
+			Nonce:     uint64(i),
+			To:        &aa,
+			Gas:       30000,
+			GasFeeCap: newGwei(5),
+			GasTipCap: big.NewInt(1000),
+		}), signer, key1)
+		b.AddTx(tx)
+
+		tips := new(big.Int).SetUint64(b.receipts[0].GasUsed * 1000)
+		producer, withheld := b.statedb.Tips()
+		switch i {
+		case 0:
+			// Before the split the producer is paid as the block goes
+			if withheld.Sign() != 0 {
+				t.Errorf("block %d: tips withheld before the split: %v", i+1, withheld)
+			}
+			if have := b.statedb.GetBalance(coinbase); have.Cmp(tips) != 0 {
+				t.Errorf("block %d: producer balance mismatch: have %v, want %v", i+1, have, tips)
+			}
+		case 1:
+			if producer != coinbase || withheld.Cmp(tips) != 0 {
+				t.Errorf("block %d: withheld tips mismatch: have %v from %x, want %v from %x", i+1, withheld, producer, tips, coinbase)
+			}
+			if have := b.statedb.GetBalance(coinbase); have.Sign() != 0 {
+				t.Errorf("block %d: producer paid before finalization: %v", i+1, have)
+			}
+			if have := b.statedb.GetBalance(fund); have.Sign() != 0 {
+				t.Errorf("block %d: fund paid before finalization: %v", i+1, have)
+			}
+		}
+	})
+}
+
+// Tests that chain head events are persisted into the reorg journal.
+func TestReorgJournal(t *testing.T) {
+	db, chain, err := newCanonical(ethash.NewFaker(), 0, true)
+	if err != nil {
+		t.Fatalf("failed to create pristine chain: %v", err)
+	}
+	defer chain.Stop()
+
+	easyBlocks, _ := GenerateChain(params.TestChainConfig, chain.CurrentBlock(), ethash.NewFaker(), db, 3, func(i int, b *BlockGen) {
+		b.SetCoinbase(common.Address{0x01})
+	})
+	diffBlocks, _ := GenerateChain(params.TestChainConfig, chain.CurrentBlock(), ethash.NewFaker(), db, 5, func(i int, b *BlockGen) {
+		b.SetCoinbase(common.Address{0x02})
+	})
+	if _, err := chain.InsertChain(easyBlocks); err != nil {
+		t.Fatalf("failed to insert easy chain: %v", err)
+	}
+	if _, err := chain.InsertChain(diffBlocks); err != nil {
+		t.Fatalf("failed to insert difficult chain: %v", err)
+	}
+	history := chain.ReorgHistory(100)
+	if len(history) == 0 {
+		t.Fatal("no chain head events recorded")
+	}
+	for i := 1; i < len(history); i++ {
+		if history[i].Seq != history[i-1].Seq-1 {
+			t.Fatalf("entry %d: sequence mismatch: have %d, want %d", i, history[i].Seq, history[i-1].Seq-1)
+		}
+	}
+	if head := history[0]; head.Type != Chain2HeadCanonicalEvent || head.Added[0].Hash != diffBlocks[4].Hash() {
+		t.Fatalf("head entry mismatch: have %s %x, want %s %x", head.Type, head.Added[0].Hash, Chain2HeadCanonicalEvent, diffBlocks[4].Hash())
+	}
+	var reorg *rawdb.ReorgJournalEntry
+	for _, entry := range history {
+		if entry.Type == Chain2HeadReorgEvent {
+			reorg = entry
+			break
+		}
+	}
+	if reorg == nil {
+		t.Fatal("reorg not recorded")
+	}
+	if len(reorg.Dropped) != len(easyBlocks) {
+		t.Fatalf("dropped blocks mismatch: have %d, want %d", len(reorg.Dropped), len(easyBlocks))
+	}
+	for _, dropped := range reorg.Dropped {
+		if want := easyBlocks[dropped.Number-1].Hash(); dropped.Hash != want {
+			t.Errorf("dropped block %d mismatch: have %x, want %x", dropped.Number, dropped.Hash, want)
+		}
+	}
+	for _, added := range reorg.Added {
+		if want := diffBlocks[added.Number-1].Hash(); added.Hash != want {
+			t.Errorf("added block %d mismatch: have %x, want %x", added.Number, added.Hash, want)
+		}
+	}
+	// The journal must survive a restart
+	if reloaded := rawdb.ReadReorgJournal(db, 100); len(reloaded) != len(history) {
+		t.Fatalf("persisted entries mismatch: have %d, want %d", len(reloaded), len(history))
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/qydata/go-ctereum/common"
+	"github.com/qydata/go-ctereum/consensus/ethash"
+	"github.com/qydata/go-ctereum/core/rawdb"
+	"github.com/qydata/go-ctereum/core/state"
+	"github.com/qydata/go-ctereum/core/types"
+	"github.com/qydata/go-ctereum/core/vm"
+	"github.com/qydata/go-ctereum/crypto"
+	"github.com/qydata/go-ctereum/params"
+)
+
+// Tests that the execution witness of a block is recorded on import, matches
+// the one generated on demand, and suffices to execute the block statelessly.
+func TestWitnessRecording(t *testing.T) {
+	var (
+		// The counter contract increments slot 0 on every call
+		counter = common.HexToAddress("0x000000000000000000000000000000000000c0c0")
+		code    = []byte{
+			byte(vm.PUSH1), 0x00, byte(vm.SLOAD),
+			byte(vm.PUSH1), 0x01, byte(vm.ADD),
+			byte(vm.PUSH1), 0x00, byte(vm.SSTORE),
+			byte(vm.STOP),
+		}
+		key, _ = crypto.GenerateKey()
+		sender = crypto.PubkeyToAddress(key.PublicKey)
+		engine = ethash.NewFaker()
+		db     = rawdb.NewMemoryDatabase()
+		gspec  = &Genesis{
+			Config: params.TestChainConfig,
+			Alloc: GenesisAlloc{
+				sender: {Balance: big.NewInt(params.Ether)},
+				counter: {
+					Code:    code,
+					Balance: new(big.Int),
+					Storage: map[common.Hash]common.Hash{{}: common.BigToHash(big.NewInt(5))},
+				},
+			},
+		}
+		genesis = gspec.MustCommit(db)
+		signer  = types.LatestSigner(gspec.Config)
+	)
+	blocks, _ := GenerateChain(gspec.Config, genesis, engine, db, 2, func(i int, b *BlockGen) {
+		b.SetCoinbase(common.Address{0x01})
+		tx, _ := types.SignTx(types.NewTransaction(uint64(2*i), common.Address{0x10}, big.NewInt(1000), params.TxGas, b.header.BaseFee, nil), signer, key)
+		b.AddTx(tx)
+		tx, _ = types.SignTx(types.NewTransaction(uint64(2*i+1), counter, new(big.Int), 50000, b.header.BaseFee, nil), signer, key)
+		b.AddTx(tx)
+	})
+	newChain := func(witnesses bool) *BlockChain {
+		diskdb := rawdb.NewMemoryDatabase()
+		gspec.MustCommit(diskdb)
+
+		cacheConfig := *defaultCacheConfig
+		cacheConfig.Witnesses = witnesses
+		chain, err := NewBlockChain(diskdb, &cacheConfig, gspec.Config, engine, vm.Config{}, nil, nil)
+		if err != nil {
+			t.Fatalf("failed to create tester chain: %v", err)
+		}
+		if n, err := chain.InsertChain(blocks); err != nil {
+			t.Fatalf("block %d: failed to insert into chain: %v", n, err)
+		}
+		return chain
+	}
+	recording := newChain(true)
+	defer recording.Stop()
+
+	if blob := rawdb.ReadWitnessRLP(recording.db, blocks[1].Hash(), 2); blob == nil {
+		t.Fatalf("witness not stored on import")
+	}
+	witness, err := recording.GetWitness(blocks[1])
+	if err != nil {
+		t.Fatalf("failed to retrieve witness: %v", err)
+	}
+	if witness.Root != blocks[0].Root() {
+		t.Fatalf("witness root mismatch: have %x, want %x", witness.Root, blocks[0].Root())
+	}
+	if len(witness.Codes) != 1 || !reflect.DeepEqual(witness.Codes[0], code) {
+		t.Fatalf("witness codes mismatch: have %x", witness.Codes)
+	}
+	// Generating the witness on demand yields the same one
+	plain := newChain(false)
+	defer plain.Stop()
+
+	if blob := rawdb.ReadWitnessRLP(plain.db, blocks[1].Hash(), 2); blob != nil {
+		t.Fatalf("witness stored while disabled")
+	}
+	generated, err := plain.GetWitness(blocks[1])
+	if err != nil {
+		t.Fatalf("failed to generate witness: %v", err)
+	}
+	if !reflect.DeepEqual(generated, witness) {
+		t.Fatalf("generated witness mismatch")
+	}
+	// Execute the block on top of the witness alone
+	statelessdb := rawdb.NewMemoryDatabase()
+	for _, node := range witness.Nodes {
+		statelessdb.Put(crypto.Keccak256(node), node)
+	}
+	for _, code := range witness.Codes {
+		rawdb.WriteCode(statelessdb, crypto.Keccak256Hash(code), code)
+	}
+	statedb, err := state.New(witness.Root, state.NewDatabase(statelessdb), nil)
+	if err != nil {
+		t.Fatalf("failed to open witness state: %v", err)
+	}
+	if _, _, _, err := plain.Processor().Process(blocks[1], statedb, vm.Config{}); err != nil {
+		t.Fatalf("failed to execute block statelessly: %v", err)
+	}
+	if root := statedb.IntermediateRoot(true); root != blocks[1].Root() {
+		t.Fatalf("stateless root mismatch: have %x, want %x", root, blocks[1].Root())
+	}
+	if err := statedb.Error(); err != nil {
+		t.Fatalf("stateless execution error: %v", err)
+	}
+}
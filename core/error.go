@@ -97,4 +97,25 @@ var (
 
 	// ErrSenderNoEOA is returned if the sender of a transaction is a contract.
 	ErrSenderNoEOA = errors.New("sender not an eoa")
+
+	// ErrDeployNotAuthorized is returned if a contract creation is sent by an
+	// address neither authenticated nor whitelisted in the auth contract while
+	// the deployment restriction is active.
+	ErrDeployNotAuthorized = errors.New("contract deployment not authorized")
+
+	// ErrAuthProofExpired is returned if the auth proof of an authenticated
+	// transaction expired before the block including it.
+	ErrAuthProofExpired = errors.New("auth proof expired")
+
+	// ErrAuthProofSigner is returned if the auth proof of an authenticated
+	// transaction is not signed by the owner of the auth contract.
+	ErrAuthProofSigner = errors.New("auth proof not signed by auth contract owner")
+
+	// ErrAuthProofLifetime is returned if the auth proof of an authenticated
+	// transaction never expires or expires too far past the block including it.
+	ErrAuthProofLifetime = errors.New("auth proof lifetime out of bounds")
+
+	// ErrAuthProofRevoked is returned if the sender of an authenticated
+	// transaction is no longer authenticated in the auth contract.
+	ErrAuthProofRevoked = errors.New("auth proof revoked")
 )
@@ -41,3 +41,19 @@ type ChainSideEvent struct {
 }
 
 type ChainHeadEvent struct{ Block *types.Block }
+
+// Chain2HeadEvent is posted whenever the canonical head moves. Unlike
+// ChainHeadEvent it carries both the dropped and the added chain segments,
+// which lets subscribers tell a plain head extension apart from a reorg.
+type Chain2HeadEvent struct {
+	NewChain []*types.Block
+	OldChain []*types.Block
+	Type     string
+}
+
+// Chain2HeadEvent types.
+const (
+	Chain2HeadReorgEvent     = "reorg"
+	Chain2HeadForkEvent      = "fork"
+	Chain2HeadCanonicalEvent = "head"
+)
@@ -249,10 +249,10 @@ func (e *GenesisMismatchError) Error() string {
 //
 // The returned chain configuration is never nil.
 func SetupGenesisBlock(db ethdb.Database, genesis *Genesis) (*params.ChainConfig, common.Hash, error) {
-	return SetupGenesisBlockWithOverride(db, genesis, nil, nil)
+	return SetupGenesisBlockWithOverride(db, genesis, nil, nil, nil, nil)
 }
 
-func SetupGenesisBlockWithOverride(db ethdb.Database, genesis *Genesis, overrideTerminalTotalDifficulty *big.Int, overrideTerminalTotalDifficultyPassed *bool) (*params.ChainConfig, common.Hash, error) {
+func SetupGenesisBlockWithOverride(db ethdb.Database, genesis *Genesis, overrideTerminalTotalDifficulty *big.Int, overrideTerminalTotalDifficultyPassed *bool, overrideAuthContract *common.Address, overrideValidatorContract *common.Address) (*params.ChainConfig, common.Hash, error) {
 	if genesis != nil && genesis.Config == nil {
 		return params.AllEthashProtocolChanges, common.Hash{}, errGenesisNoConfig
 	}
@@ -265,6 +265,14 @@ func SetupGenesisBlockWithOverride(db ethdb.Database, genesis *Genesis, override
 			if overrideTerminalTotalDifficultyPassed != nil {
 				config.TerminalTotalDifficultyPassed = *overrideTerminalTotalDifficultyPassed
 			}
+			if overrideAuthContract != nil {
+				config.AuthContract = *overrideAuthContract
+			}
+			if overrideValidatorContract != nil && config.Clique != nil {
+				clique := *config.Clique
+				clique.ValidatorContract = overrideValidatorContract.Hex()
+				config.Clique = &clique
+			}
 		}
 	}
 
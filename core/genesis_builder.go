@@ -0,0 +1,525 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/qydata/go-ctereum/common"
+	"github.com/qydata/go-ctereum/consensus/clique/contract"
+	"github.com/qydata/go-ctereum/core/rawdb"
+	"github.com/qydata/go-ctereum/core/state"
+	"github.com/qydata/go-ctereum/core/vm"
+	"github.com/qydata/go-ctereum/crypto"
+	"github.com/qydata/go-ctereum/params"
+)
+
+// genesisDeployGas is the gas available to the constructor of a contract
+// predeployed in the genesis.
+const genesisDeployGas = 100_000_000
+
+// extraVanity is the length of the vanity prefix of the clique extra-data.
+const extraVanity = 32
+
+// GenesisDeployment is a contract predeployed in the genesis by running its
+// creation code, so the genesis carries the state set up by its constructor.
+type GenesisDeployment struct {
+	Address  common.Address // Address the contract is deployed at
+	Deployer common.Address // Sender of the deployment, e.g. the owner of an Ownable contract
+	Code     []byte         // Creation code, followed by the ABI encoded constructor arguments
+}
+
+// GenesisCall is a call made in the genesis after the contracts are predeployed,
+// e.g. to register the initial validators, leaving its state changes in it.
+type GenesisCall struct {
+	From  common.Address // Sender of the call, paying the value from its premine
+	To    common.Address
+	Data  []byte
+	Value *big.Int
+}
+
+// GenesisBuilder assembles the genesis of a clique based ct network from its
+// parameters: the signer set, the premined accounts, the predeployed contracts
+// and the fork schedule. The result only depends on the inputs, so the same
+// parameters always yield the same genesis block.
+type GenesisBuilder struct {
+	config      *params.ChainConfig
+	signers     []common.Address
+	vanity      []byte
+	alloc       GenesisAlloc
+	deployments []*GenesisDeployment
+	calls       []*GenesisCall
+	timestamp   uint64
+	gasLimit    uint64
+}
+
+// NewGenesisBuilder creates a builder for a network with the given chain ID,
+// with all forks up to London active from genesis and a clique block period of
+// 5 seconds.
+func NewGenesisBuilder(chainID *big.Int) *GenesisBuilder {
+	return &GenesisBuilder{
+		config: &params.ChainConfig{
+			ChainID:             new(big.Int).Set(chainID),
+			HomesteadBlock:      big.NewInt(0),
+			EIP150Block:         big.NewInt(0),
+			EIP155Block:         big.NewInt(0),
+			EIP158Block:         big.NewInt(0),
+			ByzantiumBlock:      big.NewInt(0),
+			ConstantinopleBlock: big.NewInt(0),
+			PetersburgBlock:     big.NewInt(0),
+			IstanbulBlock:       big.NewInt(0),
+			BerlinBlock:         big.NewInt(0),
+			LondonBlock:         big.NewInt(0),
+			Clique: &params.CliqueConfig{
+				Period: 5,
+				Epoch:  30000,
+			},
+		},
+		alloc:    make(GenesisAlloc),
+		gasLimit: params.GenesisGasLimit,
+	}
+}
+
+// Config returns the chain configuration being built, allowing the settings
+// not covered by the builder methods to be adjusted directly.
+func (b *GenesisBuilder) Config() *params.ChainConfig {
+	return b.config
+}
+
+// SetPeriod sets the clique block period in seconds.
+func (b *GenesisBuilder) SetPeriod(period uint64) {
+	b.config.Clique.Period = period
+}
+
+// SetEpoch sets the clique epoch length in blocks.
+func (b *GenesisBuilder) SetEpoch(epoch uint64) {
+	b.config.Clique.Epoch = epoch
+}
+
+// SetTimestamp sets the timestamp of the genesis block.
+func (b *GenesisBuilder) SetTimestamp(timestamp uint64) {
+	b.timestamp = timestamp
+}
+
+// SetGasLimit sets the gas limit of the genesis block.
+func (b *GenesisBuilder) SetGasLimit(gasLimit uint64) {
+	b.gasLimit = gasLimit
+}
+
+// AddSigner adds an initial clique signer.
+func (b *GenesisBuilder) AddSigner(signer common.Address) {
+	b.signers = append(b.signers, signer)
+}
+
+// SetVanity sets the vanity prefix of the clique extra-data of the genesis.
+func (b *GenesisBuilder) SetVanity(vanity []byte) {
+	b.vanity = common.CopyBytes(vanity)
+}
+
+// AddPremine credits the balance to the account in the genesis.
+func (b *GenesisBuilder) AddPremine(addr common.Address, balance *big.Int) {
+	account := b.alloc[addr]
+	if account.Balance == nil {
+		account.Balance = new(big.Int)
+	}
+	account.Balance = new(big.Int).Add(account.Balance, balance)
+	b.alloc[addr] = account
+}
+
+// AddAccount places the account into the genesis as is, replacing any premine
+// of it.
+func (b *GenesisBuilder) AddAccount(addr common.Address, account GenesisAccount) {
+	b.alloc[addr] = account
+}
+
+// AddDeployment predeploys a contract in the genesis. Contracts are deployed
+// in the order added, after all the accounts were placed.
+func (b *GenesisBuilder) AddDeployment(deployment *GenesisDeployment) {
+	b.deployments = append(b.deployments, deployment)
+}
+
+// AddCall makes a call in the genesis. Calls are made in the order added, after
+// all the contracts were deployed.
+func (b *GenesisBuilder) AddCall(call *GenesisCall) {
+	b.calls = append(b.calls, call)
+}
+
+// SetAuthController predeploys the AuthController and activates it from the
+// genesis on. The deployer becomes the owner of the contract.
+func (b *GenesisBuilder) SetAuthController(deployment *GenesisDeployment) {
+	b.AddDeployment(deployment)
+	b.config.AuthBlock = big.NewInt(0)
+	b.config.AuthContract = deployment.Address
+}
+
+// SetValidatorContract predeploys the staking contract clique reads the
+// validator set from, along with the stake validators need to be eligible.
+func (b *GenesisBuilder) SetValidatorContract(deployment *GenesisDeployment, stake int64) {
+	b.AddDeployment(deployment)
+	b.config.Clique.ValidatorContract = deployment.Address.Hex()
+	b.config.Clique.StakeAmount = stake
+}
+
+// SetFork schedules the named fork at the given block, nil disabling it. The
+// names are the ones of the chain configuration fields without the "Block"
+// suffix, e.g. "london", "auth" or "authTx", and are case insensitive.
+func (b *GenesisBuilder) SetFork(name string, block *big.Int) error {
+	if strings.EqualFold(name, "poa2pos") {
+		if block == nil || !block.IsInt64() {
+			return fmt.Errorf("invalid poa2pos block %v", block)
+		}
+		b.config.Clique.Poa2PosBlock = block.Int64()
+		return nil
+	}
+	forks := map[string]**big.Int{
+		"homestead":      &b.config.HomesteadBlock,
+		"eip150":         &b.config.EIP150Block,
+		"eip155":         &b.config.EIP155Block,
+		"eip158":         &b.config.EIP158Block,
+		"byzantium":      &b.config.ByzantiumBlock,
+		"constantinople": &b.config.ConstantinopleBlock,
+		"petersburg":     &b.config.PetersburgBlock,
+		"istanbul":       &b.config.IstanbulBlock,
+		"muirglacier":    &b.config.MuirGlacierBlock,
+		"berlin":         &b.config.BerlinBlock,
+		"london":         &b.config.LondonBlock,
+		"arrowglacier":   &b.config.ArrowGlacierBlock,
+		"grayglacier":    &b.config.GrayGlacierBlock,
+		"shanghai":       &b.config.ShanghaiBlock,
+		"cancun":         &b.config.CancunBlock,
+		"auth":           &b.config.AuthBlock,
+		"authtx":         &b.config.AuthTxBlock,
+	}
+	field, ok := forks[strings.ToLower(name)]
+	if !ok {
+		return fmt.Errorf("unknown fork %q", name)
+	}
+	if block != nil {
+		block = new(big.Int).Set(block)
+	}
+	*field = block
+	return nil
+}
+
+// Build validates the parameters and assembles the genesis, running the
+// constructors of the predeployed contracts.
+func (b *GenesisBuilder) Build() (*Genesis, error) {
+	if len(b.signers) == 0 {
+		return nil, errors.New("no clique signers")
+	}
+	if err := b.config.CheckConfigForkOrder(); err != nil {
+		return nil, err
+	}
+	alloc, err := b.deploy()
+	if err != nil {
+		return nil, err
+	}
+	extra, err := CliqueExtraData(b.vanity, b.signers)
+	if err != nil {
+		return nil, err
+	}
+
+	config := *b.config
+	clique := *b.config.Clique
+	config.Clique = &clique
+
+	genesis := &Genesis{
+		Config:     &config,
+		Timestamp:  b.timestamp,
+		ExtraData:  extra,
+		GasLimit:   b.gasLimit,
+		Difficulty: big.NewInt(1),
+		Alloc:      alloc,
+	}
+	if config.IsLondon(common.Big0) {
+		genesis.BaseFee = big.NewInt(params.InitialBaseFee)
+	}
+	return genesis, nil
+}
+
+// CliqueExtraData assembles the clique extra-data of a genesis block, made of the
+// vanity padded to 32 bytes, the initial signers in ascending order and an empty
+// seal.
+func CliqueExtraData(vanity []byte, signers []common.Address) ([]byte, error) {
+	if len(vanity) > extraVanity {
+		return nil, fmt.Errorf("vanity too long: %d bytes, max %d", len(vanity), extraVanity)
+	}
+	if len(signers) == 0 {
+		return nil, errors.New("no clique signers")
+	}
+	// Clique expects the signers in ascending order between the vanity and the
+	// seal of the extra-data
+	sorted := make([]common.Address, len(signers))
+	copy(sorted, signers)
+	sort.Slice(sorted, func(i, j int) bool { return bytes.Compare(sorted[i][:], sorted[j][:]) < 0 })
+
+	extra := make([]byte, extraVanity, extraVanity+len(sorted)*common.AddressLength+crypto.SignatureLength)
+	copy(extra, vanity)
+	for i, signer := range sorted {
+		if i > 0 && signer == sorted[i-1] {
+			return nil, fmt.Errorf("duplicate signer %x", signer)
+		}
+		extra = append(extra, signer[:]...)
+	}
+	return append(extra, make([]byte, crypto.SignatureLength)...), nil
+}
+
+// deploy places the accounts into a scratch state, runs the constructors of
+// the predeployed contracts on top and returns the resulting allocation.
+func (b *GenesisBuilder) deploy() (GenesisAlloc, error) {
+	statedb, err := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	if err != nil {
+		return nil, err
+	}
+	alloc := make(GenesisAlloc, len(b.alloc))
+	for addr, account := range b.alloc {
+		if account.Balance == nil {
+			account.Balance = new(big.Int)
+		}
+		alloc[addr] = account
+
+		statedb.AddBalance(addr, account.Balance)
+		statedb.SetCode(addr, account.Code)
+		statedb.SetNonce(addr, account.Nonce)
+		for key, value := range account.Storage {
+			statedb.SetState(addr, key, value)
+		}
+	}
+	if len(b.deployments) == 0 && len(b.calls) == 0 {
+		return alloc, nil
+	}
+	// The genesis is set up without the auth restrictions, which only apply to
+	// the transactions of the chain
+	config := *b.config
+	config.AuthBlock, config.DeployAuth = nil, nil
+
+	var (
+		record  = state.NewAccessRecord()
+		context = vm.BlockContext{
+			CanTransfer: CanTransfer,
+			Transfer:    Transfer,
+			GetHash:     func(uint64) common.Hash { return common.Hash{} },
+			GasLimit:    b.gasLimit,
+			BlockNumber: new(big.Int),
+			Time:        new(big.Int).SetUint64(b.timestamp),
+			Difficulty:  big.NewInt(1),
+			BaseFee:     new(big.Int),
+		}
+	)
+	statedb.RecordAccess(record)
+	for _, deployment := range b.deployments {
+		if statedb.GetCodeSize(deployment.Address) > 0 {
+			return nil, fmt.Errorf("contract %x deployed twice", deployment.Address)
+		}
+		// Run the creation code in place of the runtime code, leaving the state
+		// set up by the constructor at the target address
+		evm := vm.NewEVM(context, vm.TxContext{Origin: deployment.Deployer, GasPrice: new(big.Int)}, statedb, &config, vm.Config{})
+
+		rules := config.Rules(context.BlockNumber, false)
+		if rules.IsBerlin {
+			statedb.PrepareAccessList(deployment.Deployer, &deployment.Address, vm.ActivePrecompiles(rules), nil)
+		}
+		statedb.SetNonce(deployment.Address, 1)
+		statedb.SetCode(deployment.Address, deployment.Code)
+		code, _, err := evm.Call(vm.AccountRef(deployment.Deployer), deployment.Address, nil, genesisDeployGas, new(big.Int))
+		if err != nil {
+			return nil, fmt.Errorf("failed to deploy contract %x: %v", deployment.Address, err)
+		}
+		if len(code) == 0 {
+			return nil, fmt.Errorf("contract %x deployed without code", deployment.Address)
+		}
+		statedb.SetCode(deployment.Address, code)
+		statedb.Finalise(true)
+	}
+	for _, call := range b.calls {
+		evm := vm.NewEVM(context, vm.TxContext{Origin: call.From, GasPrice: new(big.Int)}, statedb, &config, vm.Config{})
+
+		rules := config.Rules(context.BlockNumber, false)
+		if rules.IsBerlin {
+			statedb.PrepareAccessList(call.From, &call.To, vm.ActivePrecompiles(rules), nil)
+		}
+		value := call.Value
+		if value == nil {
+			value = new(big.Int)
+		}
+		if !CanTransfer(statedb, call.From, value) {
+			return nil, fmt.Errorf("insufficient premine of %x for a call to %x", call.From, call.To)
+		}
+		if _, _, err := evm.Call(vm.AccountRef(call.From), call.To, call.Data, genesisDeployGas, value); err != nil {
+			return nil, fmt.Errorf("failed to call contract %x: %v", call.To, err)
+		}
+		statedb.Finalise(true)
+	}
+	statedb.RecordAccess(nil)
+
+	// Export every account touched by the constructors
+	touched := make(map[common.Address]struct{})
+	for _, set := range []map[common.Address]struct{}{record.AccountWrites, record.Resets} {
+		for addr := range set {
+			touched[addr] = struct{}{}
+		}
+	}
+	for addr := range record.SlotWrites {
+		touched[addr] = struct{}{}
+	}
+	for addr := range touched {
+		if !statedb.Exist(addr) {
+			delete(alloc, addr)
+			continue
+		}
+		account := GenesisAccount{
+			Code:    statedb.GetCode(addr),
+			Balance: statedb.GetBalance(addr),
+			Nonce:   statedb.GetNonce(addr),
+			Storage: make(map[common.Hash]common.Hash),
+		}
+		for key, value := range alloc[addr].Storage {
+			account.Storage[key] = value
+		}
+		for key := range record.SlotWrites[addr] {
+			if value := statedb.GetState(addr, key); value != (common.Hash{}) {
+				account.Storage[key] = value
+			} else {
+				delete(account.Storage, key)
+			}
+		}
+		if len(account.Storage) == 0 {
+			account.Storage = nil
+		}
+		alloc[addr] = account
+	}
+	return alloc, nil
+}
+
+// Addresses of the contracts predeployed in the ct developer genesis.
+var (
+	DeveloperAuthContract      = common.HexToAddress("0x0000000000000000000000000000000000001001")
+	DeveloperValidatorContract = common.HexToAddress("0x0000000000000000000000000000000000001000")
+)
+
+// developerAccountBalance is the premine of the accounts of the ct developer
+// genesis other than the developer one, 1 billion ether.
+var developerAccountBalance = new(big.Int).Mul(big.NewInt(1_000_000_000), big.NewInt(params.Ether))
+
+// DeveloperCtGenesisBlock returns the genesis of a single node ct developer
+// network. On top of the developer genesis, the AuthController and the staking
+// contract are predeployed from their creation code with the developer as their
+// owner, and ImplAuth and Poa2Pos are active from the genesis on. The staking
+// contract is initialized with the developer as its only validator, backed by
+// the stake, and the accounts are funded and whitelisted.
+func DeveloperCtGenesisBlock(period uint64, gasLimit uint64, developer common.Address, accounts []common.Address, authCode []byte, validatorCode []byte, stake *big.Int) (*Genesis, error) {
+	builder := NewGenesisBuilder(params.AllCliqueProtocolChanges.ChainID)
+	builder.SetPeriod(period)
+	builder.SetGasLimit(gasLimit)
+	builder.AddSigner(developer)
+
+	// Fund the precompiles like the developer genesis, the developer with the
+	// faucet balance and the other accounts with a lower one, so that transfers
+	// can't overflow their balances
+	for i := byte(1); i <= 9; i++ {
+		builder.AddPremine(common.BytesToAddress([]byte{i}), big.NewInt(1))
+	}
+	builder.AddPremine(developer, new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(9)))
+	for _, account := range accounts {
+		if account != developer {
+			builder.AddPremine(account, developerAccountBalance)
+		}
+	}
+	builder.SetAuthController(&GenesisDeployment{Address: DeveloperAuthContract, Deployer: developer, Code: authCode})
+	builder.SetValidatorContract(&GenesisDeployment{Address: DeveloperValidatorContract, Deployer: developer, Code: validatorCode}, 0)
+	if err := builder.SetFork("poa2pos", common.Big0); err != nil {
+		return nil, err
+	}
+	whitelist := append([]common.Address{developer}, accounts...)
+	data, err := contract.AuthController().Pack("addToWhitelist", whitelist)
+	if err != nil {
+		return nil, err
+	}
+	builder.AddCall(&GenesisCall{From: developer, To: DeveloperAuthContract, Data: data})
+
+	data, err = contract.Staking().Pack("init", common.Big1, big.NewInt(int64(len(whitelist))), []common.Address{developer})
+	if err != nil {
+		return nil, err
+	}
+	builder.AddCall(&GenesisCall{From: developer, To: DeveloperValidatorContract, Data: data, Value: stake})
+
+	genesis, err := builder.Build()
+	if err != nil {
+		return nil, err
+	}
+	// Clique only accepts the validators whose stake reported by the contract
+	// matches the configured one, so configure the developer one
+	amount, err := developerStake(genesis, developer)
+	if err != nil {
+		return nil, err
+	}
+	genesis.Config.Clique.StakeAmount = amount
+	return genesis, nil
+}
+
+// developerStake returns the stake the staking contract of the ct developer
+// genesis reports for the developer.
+func developerStake(genesis *Genesis, developer common.Address) (int64, error) {
+	db := rawdb.NewMemoryDatabase()
+	block, err := genesis.Commit(db)
+	if err != nil {
+		return 0, err
+	}
+	statedb, err := state.New(block.Root(), state.NewDatabase(db), nil)
+	if err != nil {
+		return 0, err
+	}
+	context := vm.BlockContext{
+		CanTransfer: CanTransfer,
+		Transfer:    Transfer,
+		GetHash:     func(uint64) common.Hash { return common.Hash{} },
+		GasLimit:    genesis.GasLimit,
+		BlockNumber: new(big.Int),
+		Time:        new(big.Int).SetUint64(genesis.Timestamp),
+		Difficulty:  big.NewInt(1),
+		BaseFee:     new(big.Int),
+	}
+	evm := vm.NewEVM(context, vm.TxContext{Origin: developer, GasPrice: new(big.Int)}, statedb, genesis.Config, vm.Config{})
+
+	data, err := contract.Staking().Pack("getValidators")
+	if err != nil {
+		return 0, err
+	}
+	ret, _, err := evm.StaticCall(vm.AccountRef(developer), DeveloperValidatorContract, data, genesisDeployGas)
+	if err != nil {
+		return 0, fmt.Errorf("failed to retrieve validators: %v", err)
+	}
+	out, err := contract.Staking().Unpack("getValidators", ret)
+	if err != nil {
+		return 0, fmt.Errorf("failed to retrieve validators: %v", err)
+	}
+	validators, stakes := out[0].([]common.Address), out[2].([]*big.Int)
+	for i, validator := range validators {
+		if validator == developer && i < len(stakes) {
+			if !stakes[i].IsInt64() {
+				return 0, fmt.Errorf("developer stake %v out of range", stakes[i])
+			}
+			return stakes[i].Int64(), nil
+		}
+	}
+	return 0, errors.New("developer not registered as validator")
+}
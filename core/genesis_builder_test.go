@@ -0,0 +1,251 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/qydata/go-ctereum/common"
+	"github.com/qydata/go-ctereum/consensus/clique/contract"
+	"github.com/qydata/go-ctereum/core/rawdb"
+)
+
+// Tests that the genesis builder assembles the clique extra-data, the premines
+// and the state set up by the constructors of the predeployed contracts.
+func TestGenesisBuilder(t *testing.T) {
+	var (
+		signer1  = common.HexToAddress("0x2000000000000000000000000000000000000000")
+		signer2  = common.HexToAddress("0x1000000000000000000000000000000000000000")
+		funded   = common.HexToAddress("0x3000000000000000000000000000000000000000")
+		owner    = common.HexToAddress("0x4000000000000000000000000000000000000000")
+		contract = common.HexToAddress("0x5000000000000000000000000000000000000000")
+		arg      = common.HexToHash("0x2a")
+	)
+	// Constructor storing the caller in slot 0 and its argument in slot 1, then
+	// returning a single STOP as the runtime code
+	code := common.FromHex("0x33600055602060" + "1e" + "6000396000516001556001601d600039" + "60016000f3" + "00")
+	code = append(code, arg[:]...)
+
+	build := func() *Genesis {
+		builder := NewGenesisBuilder(big.NewInt(1337))
+		builder.AddSigner(signer1)
+		builder.AddSigner(signer2)
+		builder.AddPremine(funded, big.NewInt(1000))
+		builder.AddPremine(funded, big.NewInt(337))
+		builder.SetAuthController(&GenesisDeployment{Address: contract, Deployer: owner, Code: code})
+
+		genesis, err := builder.Build()
+		if err != nil {
+			t.Fatalf("failed to build genesis: %v", err)
+		}
+		return genesis
+	}
+	genesis := build()
+
+	// Check the signers are sorted into the extra-data
+	want := append(make([]byte, 32), signer2[:]...)
+	want = append(want, signer1[:]...)
+	want = append(want, make([]byte, 65)...)
+	if !bytes.Equal(genesis.ExtraData, want) {
+		t.Errorf("extra-data mismatch: have %x, want %x", genesis.ExtraData, want)
+	}
+	if genesis.Config.AuthBlock == nil || genesis.Config.AuthBlock.Sign() != 0 || genesis.Config.AuthContract != contract {
+		t.Errorf("auth fork mismatch: have %v at %x", genesis.Config.AuthBlock, genesis.Config.AuthContract)
+	}
+	if balance := genesis.Alloc[funded].Balance; balance == nil || balance.Int64() != 1337 {
+		t.Errorf("premine mismatch: have %v, want 1337", balance)
+	}
+	// Check the state set up by the constructor
+	account, ok := genesis.Alloc[contract]
+	if !ok {
+		t.Fatalf("contract missing from genesis")
+	}
+	if !bytes.Equal(account.Code, []byte{0x00}) {
+		t.Errorf("runtime code mismatch: have %x", account.Code)
+	}
+	if account.Nonce != 1 {
+		t.Errorf("contract nonce mismatch: have %d, want 1", account.Nonce)
+	}
+	if have := account.Storage[common.Hash{}]; have != common.BytesToHash(owner[:]) {
+		t.Errorf("slot 0 mismatch: have %x, want %x", have, owner)
+	}
+	if have := account.Storage[common.BigToHash(common.Big1)]; have != arg {
+		t.Errorf("slot 1 mismatch: have %x, want %x", have, arg)
+	}
+	if len(account.Storage) != 2 {
+		t.Errorf("storage size mismatch: have %d, want 2", len(account.Storage))
+	}
+	// Check the genesis is deterministic and can be committed
+	if a, b := genesis.ToBlock().Hash(), build().ToBlock().Hash(); a != b {
+		t.Errorf("genesis not deterministic: %x != %x", a, b)
+	}
+	if _, err := genesis.Commit(rawdb.NewMemoryDatabase()); err != nil {
+		t.Fatalf("failed to commit genesis: %v", err)
+	}
+}
+
+// Tests that the genesis builder rejects invalid parameters.
+func TestGenesisBuilderInvalid(t *testing.T) {
+	signer := common.HexToAddress("0x1000000000000000000000000000000000000000")
+
+	builder := NewGenesisBuilder(big.NewInt(1337))
+	if _, err := builder.Build(); err == nil {
+		t.Errorf("genesis without signers built")
+	}
+	builder.AddSigner(signer)
+	builder.AddSigner(signer)
+	if _, err := builder.Build(); err == nil {
+		t.Errorf("genesis with duplicate signers built")
+	}
+	builder = NewGenesisBuilder(big.NewInt(1337))
+	builder.AddSigner(signer)
+	if err := builder.SetFork("unknown", common.Big1); err == nil {
+		t.Errorf("unknown fork accepted")
+	}
+	if err := builder.SetFork("berlin", big.NewInt(10)); err != nil {
+		t.Fatalf("failed to set fork: %v", err)
+	}
+	if _, err := builder.Build(); err == nil {
+		t.Errorf("genesis with invalid fork order built")
+	}
+	builder = NewGenesisBuilder(big.NewInt(1337))
+	builder.AddSigner(signer)
+	builder.AddDeployment(&GenesisDeployment{Address: common.HexToAddress("0x01ff"), Code: []byte{0xfe}})
+	if _, err := builder.Build(); err == nil {
+		t.Errorf("genesis with failing constructor built")
+	}
+}
+
+// Tests that the clique extra-data is assembled from the padded vanity, the
+// sorted signers and an empty seal, and that invalid inputs are rejected.
+func TestCliqueExtraData(t *testing.T) {
+	var (
+		signer1 = common.HexToAddress("0x2000000000000000000000000000000000000000")
+		signer2 = common.HexToAddress("0x1000000000000000000000000000000000000000")
+	)
+	extra, err := CliqueExtraData([]byte("ct"), []common.Address{signer1, signer2})
+	if err != nil {
+		t.Fatalf("failed to assemble extra-data: %v", err)
+	}
+	want := make([]byte, 32)
+	copy(want, "ct")
+	want = append(want, signer2[:]...)
+	want = append(want, signer1[:]...)
+	want = append(want, make([]byte, 65)...)
+	if !bytes.Equal(extra, want) {
+		t.Errorf("extra-data mismatch: have %x, want %x", extra, want)
+	}
+	if _, err := CliqueExtraData(make([]byte, 33), []common.Address{signer1}); err == nil {
+		t.Errorf("overlong vanity accepted")
+	}
+	if _, err := CliqueExtraData(nil, nil); err == nil {
+		t.Errorf("empty signer set accepted")
+	}
+	if _, err := CliqueExtraData(nil, []common.Address{signer1, signer1}); err == nil {
+		t.Errorf("duplicate signers accepted")
+	}
+}
+
+// Tests that the calls made in the genesis leave their state changes in it, even
+// if the auth fork is active from the genesis on.
+func TestGenesisBuilderCalls(t *testing.T) {
+	var (
+		signer   = common.HexToAddress("0x1000000000000000000000000000000000000000")
+		funded   = common.HexToAddress("0x3000000000000000000000000000000000000000")
+		contract = common.HexToAddress("0x5000000000000000000000000000000000000000")
+	)
+	// Constructor returning a runtime code storing the call value in slot 0 and
+	// the caller in slot 1
+	code := common.FromHex("0x6009600c60003960096000f3" + "346000553360015500")
+
+	builder := NewGenesisBuilder(big.NewInt(1337))
+	builder.AddSigner(signer)
+	builder.AddPremine(funded, big.NewInt(1337))
+	builder.SetAuthController(&GenesisDeployment{Address: contract, Deployer: funded, Code: code})
+	builder.AddCall(&GenesisCall{From: funded, To: contract, Value: big.NewInt(1000)})
+
+	genesis, err := builder.Build()
+	if err != nil {
+		t.Fatalf("failed to build genesis: %v", err)
+	}
+	if balance := genesis.Alloc[funded].Balance; balance == nil || balance.Int64() != 337 {
+		t.Errorf("caller balance mismatch: have %v, want 337", balance)
+	}
+	account := genesis.Alloc[contract]
+	if account.Balance == nil || account.Balance.Int64() != 1000 {
+		t.Errorf("contract balance mismatch: have %v, want 1000", account.Balance)
+	}
+	if have := account.Storage[common.Hash{}]; have != common.BigToHash(big.NewInt(1000)) {
+		t.Errorf("slot 0 mismatch: have %x, want value 1000", have)
+	}
+	if have := account.Storage[common.BigToHash(common.Big1)]; have != common.BytesToHash(funded[:]) {
+		t.Errorf("slot 1 mismatch: have %x, want %x", have, funded)
+	}
+	// Calls beyond the premine of the caller are rejected
+	builder.AddCall(&GenesisCall{From: funded, To: contract, Value: big.NewInt(1000)})
+	if _, err := builder.Build(); err == nil {
+		t.Errorf("genesis with underfunded call built")
+	}
+}
+
+// Tests that the ct developer genesis predeploys the contracts, activates the ct
+// forks and configures the stake the staking contract reports for the developer.
+func TestDeveloperCtGenesisBlock(t *testing.T) {
+	var (
+		developer = common.HexToAddress("0x1000000000000000000000000000000000000000")
+		account   = common.HexToAddress("0x2000000000000000000000000000000000000000")
+		stake     = big.NewInt(1000)
+	)
+	// Stand-in AuthController accepting any call
+	authCode := common.FromHex("0x6001600c60003960016000f3" + "00")
+
+	// Stand-in staking contract answering any call with the developer as the
+	// only validator, with a stake of 7
+	validators, err := contract.Staking().Methods["getValidators"].Outputs.Pack(
+		[]common.Address{developer}, []*big.Int{big.NewInt(1)}, []*big.Int{big.NewInt(7)})
+	if err != nil {
+		t.Fatalf("failed to encode validators: %v", err)
+	}
+	runtime := append(common.FromHex("0x61012080600c6000396000f3"), validators...)
+	validatorCode := append(common.FromHex("0x61012c80600c6000396000f3"), runtime...)
+
+	genesis, err := DeveloperCtGenesisBlock(0, 11_500_000, developer, []common.Address{account}, authCode, validatorCode, stake)
+	if err != nil {
+		t.Fatalf("failed to create genesis: %v", err)
+	}
+	config := genesis.Config
+	if !config.IsImplAuth(common.Big0) || config.AuthContract != DeveloperAuthContract {
+		t.Errorf("auth fork mismatch: have %v at %x", config.AuthBlock, config.AuthContract)
+	}
+	if !config.IsPoa2Pos(common.Big0) || config.Clique.ValidatorContract != DeveloperValidatorContract.Hex() {
+		t.Errorf("poa2pos fork mismatch: have %d at %s", config.Clique.Poa2PosBlock, config.Clique.ValidatorContract)
+	}
+	if config.Clique.StakeAmount != 7 {
+		t.Errorf("stake amount mismatch: have %d, want 7", config.Clique.StakeAmount)
+	}
+	if balance := genesis.Alloc[DeveloperValidatorContract].Balance; balance == nil || balance.Cmp(stake) != 0 {
+		t.Errorf("staked balance mismatch: have %v, want %v", balance, stake)
+	}
+	if balance := genesis.Alloc[account].Balance; balance == nil || balance.Cmp(developerAccountBalance) != 0 {
+		t.Errorf("account balance mismatch: have %v, want %v", balance, developerAccountBalance)
+	}
+	if _, err := genesis.Commit(rawdb.NewMemoryDatabase()); err != nil {
+		t.Fatalf("failed to commit genesis: %v", err)
+	}
+}
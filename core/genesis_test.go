@@ -210,6 +210,28 @@ func TestGenesis_Commit(t *testing.T) {
 	}
 }
 
+// Tests that the auth contract override replaces the AuthController queried
+// before the fix fork only.
+func TestSetupGenesisAuthContractOverride(t *testing.T) {
+	config := *params.TestChainConfig
+	config.AuthContract = common.Address{0x01}
+	config.FixAuthContract = common.Address{0x02}
+
+	override := common.Address{0x03}
+	genesis := &Genesis{Config: &config, BaseFee: big.NewInt(params.InitialBaseFee)}
+	db := rawdb.NewMemoryDatabase()
+	have, _, err := SetupGenesisBlockWithOverride(db, genesis, nil, nil, &override, nil)
+	if err != nil {
+		t.Fatalf("failed to setup genesis: %v", err)
+	}
+	if have.AuthContract != override {
+		t.Errorf("auth contract mismatch: have %x, want %x", have.AuthContract, override)
+	}
+	if have.FixAuthContract != config.FixAuthContract {
+		t.Errorf("fix auth contract mismatch: have %x, want %x", have.FixAuthContract, config.FixAuthContract)
+	}
+}
+
 func TestReadWriteGenesisAlloc(t *testing.T) {
 	var (
 		db    = rawdb.NewMemoryDatabase()
@@ -0,0 +1,260 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"encoding/binary"
+
+	"github.com/qydata/go-ctereum/common"
+	"github.com/qydata/go-ctereum/ethdb"
+	"github.com/qydata/go-ctereum/log"
+)
+
+// ReadAuthStatus retrieves the serialized auth status history of an address.
+func ReadAuthStatus(db ethdb.KeyValueReader, addr common.Address) []byte {
+	data, _ := db.Get(authStatusKey(addr))
+	return data
+}
+
+// WriteAuthStatus stores the serialized auth status history of an address.
+func WriteAuthStatus(db ethdb.KeyValueWriter, addr common.Address, status []byte) {
+	if err := db.Put(authStatusKey(addr), status); err != nil {
+		log.Crit("Failed to store auth status", "err", err)
+	}
+}
+
+// DeleteAuthStatus deletes the auth status history of an address.
+func DeleteAuthStatus(db ethdb.KeyValueWriter, addr common.Address) {
+	if err := db.Delete(authStatusKey(addr)); err != nil {
+		log.Crit("Failed to remove auth status", "err", err)
+	}
+}
+
+// ReadAuthControllerStatus retrieves the serialized auth status history of an
+// address in a registered auth contract.
+func ReadAuthControllerStatus(db ethdb.KeyValueReader, contract common.Address, addr common.Address) []byte {
+	data, _ := db.Get(authControllerStatusKey(contract, addr))
+	return data
+}
+
+// WriteAuthControllerStatus stores the serialized auth status history of an
+// address in a registered auth contract.
+func WriteAuthControllerStatus(db ethdb.KeyValueWriter, contract common.Address, addr common.Address, status []byte) {
+	if err := db.Put(authControllerStatusKey(contract, addr), status); err != nil {
+		log.Crit("Failed to store auth status", "err", err)
+	}
+}
+
+// DeleteAuthControllerStatus deletes the auth status history of an address in
+// a registered auth contract.
+func DeleteAuthControllerStatus(db ethdb.KeyValueWriter, contract common.Address, addr common.Address) {
+	if err := db.Delete(authControllerStatusKey(contract, addr)); err != nil {
+		log.Crit("Failed to remove auth status", "err", err)
+	}
+}
+
+// DeleteAuthControllerStatuses deletes the auth status histories of all
+// addresses in a registered auth contract.
+func DeleteAuthControllerStatuses(db ethdb.KeyValueStore, contract common.Address) {
+	deleteAuthStatuses(db, append(common.CopyBytes(authStatusPrefix), contract.Bytes()...), len(authStatusPrefix)+2*common.AddressLength)
+}
+
+// DeleteAuthStatuses deletes the auth status histories of all addresses, both
+// in the chain's and in the registered auth contracts.
+func DeleteAuthStatuses(db ethdb.KeyValueStore) {
+	deleteAuthStatuses(db, authStatusPrefix, len(authStatusPrefix)+common.AddressLength, len(authStatusPrefix)+2*common.AddressLength)
+}
+
+// deleteAuthStatuses deletes the entries with the given prefix and any of the
+// given key lengths.
+func deleteAuthStatuses(db ethdb.KeyValueStore, prefix []byte, lengths ...int) {
+	it := db.NewIterator(prefix, nil)
+	defer it.Release()
+
+	batch := db.NewBatch()
+	for it.Next() {
+		var match bool
+		for _, length := range lengths {
+			match = match || len(it.Key()) == length
+		}
+		if !match {
+			continue
+		}
+		batch.Delete(it.Key())
+		if batch.ValueSize() > ethdb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				log.Crit("Failed to remove auth statuses", "err", err)
+			}
+			batch.Reset()
+		}
+	}
+	if err := batch.Write(); err != nil {
+		log.Crit("Failed to remove auth statuses", "err", err)
+	}
+}
+
+// ReadAuthProgress retrieves the range of blocks scanned for auth contract
+// events, returning false if no scan has been recorded.
+func ReadAuthProgress(db ethdb.KeyValueReader) (uint64, uint64, bool) {
+	data, _ := db.Get(authProgressKey)
+	if len(data) != 16 {
+		return 0, 0, false
+	}
+	return binary.BigEndian.Uint64(data[:8]), binary.BigEndian.Uint64(data[8:]), true
+}
+
+// WriteAuthProgress stores the range of blocks scanned for auth contract events.
+func WriteAuthProgress(db ethdb.KeyValueWriter, from uint64, to uint64) {
+	if err := db.Put(authProgressKey, append(encodeBlockNumber(from), encodeBlockNumber(to)...)); err != nil {
+		log.Crit("Failed to store auth progress", "err", err)
+	}
+}
+
+// ReadAuthRegistryProgress retrieves the serialized first blocks scanned for
+// the events of the registered auth contracts.
+func ReadAuthRegistryProgress(db ethdb.KeyValueReader) []byte {
+	data, _ := db.Get(authRegistryProgressKey)
+	return data
+}
+
+// WriteAuthRegistryProgress stores the serialized first blocks scanned for the
+// events of the registered auth contracts.
+func WriteAuthRegistryProgress(db ethdb.KeyValueWriter, progress []byte) {
+	if err := db.Put(authRegistryProgressKey, progress); err != nil {
+		log.Crit("Failed to store auth registry progress", "err", err)
+	}
+}
+
+// ReadAuthOrderCounter retrieves the next order ID to hand out for auth contract
+// submissions, zero if none has been handed out yet.
+func ReadAuthOrderCounter(db ethdb.KeyValueReader) uint64 {
+	data, _ := db.Get(authOrderKey)
+	if len(data) != 8 {
+		return 0
+	}
+	return binary.BigEndian.Uint64(data)
+}
+
+// WriteAuthOrderCounter stores the next order ID to hand out for auth contract
+// submissions.
+func WriteAuthOrderCounter(db ethdb.KeyValueWriter, next uint64) {
+	if err := db.Put(authOrderKey, encodeBlockNumber(next)); err != nil {
+		log.Crit("Failed to store auth order counter", "err", err)
+	}
+}
+
+// WriteAuthEvent stores a serialized auth event of an address, emitted by the
+// log with the given index of the given block.
+func WriteAuthEvent(db ethdb.KeyValueWriter, addr common.Address, number uint64, index uint32, event []byte) {
+	if err := db.Put(authEventKey(addr, number, index), event); err != nil {
+		log.Crit("Failed to store auth event", "err", err)
+	}
+}
+
+// DeleteAuthEvent deletes an auth event of an address.
+func DeleteAuthEvent(db ethdb.KeyValueWriter, addr common.Address, number uint64, index uint32) {
+	if err := db.Delete(authEventKey(addr, number, index)); err != nil {
+		log.Crit("Failed to remove auth event", "err", err)
+	}
+}
+
+// ReadAuthEvents retrieves the serialized auth events of an address emitted
+// between the given blocks (inclusive), in block and log order. At most limit
+// events are returned if limit is positive.
+func ReadAuthEvents(db ethdb.Iteratee, addr common.Address, from uint64, to uint64, limit int) [][]byte {
+	prefix := append(common.CopyBytes(authEventPrefix), addr.Bytes()...)
+	it := db.NewIterator(prefix, encodeBlockNumber(from))
+	defer it.Release()
+
+	var events [][]byte
+	for it.Next() {
+		key := it.Key()
+		if len(key) != len(prefix)+12 {
+			continue
+		}
+		if binary.BigEndian.Uint64(key[len(prefix):]) > to {
+			break
+		}
+		if limit > 0 && len(events) >= limit {
+			break
+		}
+		events = append(events, common.CopyBytes(it.Value()))
+	}
+	return events
+}
+
+// IterateAuthEvents invokes the callback for every stored auth event, ordered
+// by address and then in block and log order, until the callback fails.
+func IterateAuthEvents(db ethdb.Iteratee, fn func(addr common.Address, number uint64, event []byte) error) error {
+	it := db.NewIterator(authEventPrefix, nil)
+	defer it.Release()
+
+	for it.Next() {
+		key := it.Key()
+		if len(key) != len(authEventPrefix)+common.AddressLength+12 {
+			continue
+		}
+		addr := common.BytesToAddress(key[len(authEventPrefix) : len(authEventPrefix)+common.AddressLength])
+		number := binary.BigEndian.Uint64(key[len(authEventPrefix)+common.AddressLength:])
+		if err := fn(addr, number, it.Value()); err != nil {
+			return err
+		}
+	}
+	return it.Error()
+}
+
+// ReadAuthIndexProgress retrieves the range of blocks indexed for auth events,
+// returning false if nothing has been indexed yet.
+func ReadAuthIndexProgress(db ethdb.KeyValueReader) (uint64, uint64, bool) {
+	data, _ := db.Get(authIndexProgressKey)
+	if len(data) != 16 {
+		return 0, 0, false
+	}
+	return binary.BigEndian.Uint64(data[:8]), binary.BigEndian.Uint64(data[8:]), true
+}
+
+// WriteAuthIndexProgress stores the range of blocks indexed for auth events.
+func WriteAuthIndexProgress(db ethdb.KeyValueWriter, tail uint64, head uint64) {
+	if err := db.Put(authIndexProgressKey, append(encodeBlockNumber(tail), encodeBlockNumber(head)...)); err != nil {
+		log.Crit("Failed to store auth index progress", "err", err)
+	}
+}
+
+// ReadAuthBackfill retrieves the first block the running auth event backfill
+// indexes, returning false if no backfill is running.
+func ReadAuthBackfill(db ethdb.KeyValueReader) (uint64, bool) {
+	data, _ := db.Get(authBackfillKey)
+	if len(data) != 8 {
+		return 0, false
+	}
+	return binary.BigEndian.Uint64(data), true
+}
+
+// WriteAuthBackfill stores the first block the running auth event backfill
+// indexes.
+func WriteAuthBackfill(db ethdb.KeyValueWriter, target uint64) {
+	if err := db.Put(authBackfillKey, encodeBlockNumber(target)); err != nil {
+		log.Crit("Failed to store auth backfill target", "err", err)
+	}
+}
+
+// DeleteAuthBackfill deletes the target of the auth event backfill.
+func DeleteAuthBackfill(db ethdb.KeyValueWriter) {
+	if err := db.Delete(authBackfillKey); err != nil {
+		log.Crit("Failed to remove auth backfill target", "err", err)
+	}
+}
@@ -840,6 +840,8 @@ func writeAncientBlock(op ethdb.AncientWriteOp, block *types.Block, header *type
 // DeleteBlock removes all block data associated with a hash.
 func DeleteBlock(db ethdb.KeyValueWriter, hash common.Hash, number uint64) {
 	DeleteReceipts(db, hash, number)
+	DeleteStateDiff(db, hash, number)
+	DeleteWitness(db, hash, number)
 	DeleteHeader(db, hash, number)
 	DeleteBody(db, hash, number)
 	DeleteTd(db, hash, number)
@@ -0,0 +1,140 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"encoding/binary"
+
+	"github.com/qydata/go-ctereum/common"
+	"github.com/qydata/go-ctereum/ethdb"
+	"github.com/qydata/go-ctereum/log"
+	"github.com/qydata/go-ctereum/rlp"
+)
+
+// LogIndexProgress is the range of blocks covered by the log index, along with
+// the event signatures (first log topics) indexed. The range is empty if the
+// tail is above the head.
+type LogIndexProgress struct {
+	Tail   uint64        // First block indexed
+	Head   uint64        // Last block indexed
+	Topics []common.Hash // Event signatures indexed
+}
+
+// Covers reports whether the blocks containing logs with the given event
+// signature are indexed.
+func (p *LogIndexProgress) Covers(topic common.Hash) bool {
+	for _, t := range p.Topics {
+		if t == topic {
+			return true
+		}
+	}
+	return false
+}
+
+// ReadLogIndexProgress retrieves the progress of the log index, returning nil
+// if nothing has been indexed yet.
+func ReadLogIndexProgress(db ethdb.KeyValueReader) *LogIndexProgress {
+	data, _ := db.Get(logIndexProgressKey)
+	if len(data) == 0 {
+		return nil
+	}
+	progress := new(LogIndexProgress)
+	if err := rlp.DecodeBytes(data, progress); err != nil {
+		log.Error("Invalid log index progress", "err", err)
+		return nil
+	}
+	return progress
+}
+
+// WriteLogIndexProgress stores the progress of the log index.
+func WriteLogIndexProgress(db ethdb.KeyValueWriter, progress *LogIndexProgress) {
+	data, err := rlp.EncodeToBytes(progress)
+	if err != nil {
+		log.Crit("Failed to RLP encode log index progress", "err", err)
+	}
+	if err := db.Put(logIndexProgressKey, data); err != nil {
+		log.Crit("Failed to store log index progress", "err", err)
+	}
+}
+
+// WriteLogIndexEntry marks the block as containing logs with the given event
+// signature.
+func WriteLogIndexEntry(db ethdb.KeyValueWriter, topic common.Hash, number uint64) {
+	if err := db.Put(logIndexKey(topic, number), []byte{}); err != nil {
+		log.Crit("Failed to store log index entry", "err", err)
+	}
+}
+
+// DeleteLogIndexEntry removes the mark of the block containing logs with the
+// given event signature.
+func DeleteLogIndexEntry(db ethdb.KeyValueWriter, topic common.Hash, number uint64) {
+	if err := db.Delete(logIndexKey(topic, number)); err != nil {
+		log.Crit("Failed to remove log index entry", "err", err)
+	}
+}
+
+// ReadLogIndexBlocks retrieves the numbers of the blocks between the given ones
+// (inclusive) marked as containing logs with the given event signature, in
+// ascending order.
+func ReadLogIndexBlocks(db ethdb.Iteratee, topic common.Hash, from, to uint64) []uint64 {
+	prefix := append(common.CopyBytes(logIndexPrefix), topic.Bytes()...)
+
+	it := db.NewIterator(prefix, encodeBlockNumber(from))
+	defer it.Release()
+
+	var numbers []uint64
+	for it.Next() {
+		key := it.Key()
+		if len(key) != len(prefix)+8 {
+			continue
+		}
+		number := binary.BigEndian.Uint64(key[len(prefix):])
+		if number > to {
+			break
+		}
+		numbers = append(numbers, number)
+	}
+	return numbers
+}
+
+// DeleteLogIndex deletes the whole log index along with its progress.
+func DeleteLogIndex(db ethdb.KeyValueStore) {
+	batch := db.NewBatch()
+	it := db.NewIterator(logIndexPrefix, nil)
+	defer it.Release()
+
+	for it.Next() {
+		if len(it.Key()) != len(logIndexPrefix)+common.HashLength+8 {
+			continue
+		}
+		if err := batch.Delete(it.Key()); err != nil {
+			log.Crit("Failed to remove log index entry", "err", err)
+		}
+		if batch.ValueSize() > ethdb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				log.Crit("Failed to remove log index", "err", err)
+			}
+			batch.Reset()
+		}
+	}
+	if err := batch.Delete(logIndexProgressKey); err != nil {
+		log.Crit("Failed to remove log index progress", "err", err)
+	}
+	if err := batch.Write(); err != nil {
+		log.Crit("Failed to remove log index", "err", err)
+	}
+}
@@ -0,0 +1,98 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"encoding/binary"
+
+	"github.com/qydata/go-ctereum/common"
+	"github.com/qydata/go-ctereum/ethdb"
+	"github.com/qydata/go-ctereum/log"
+	"github.com/qydata/go-ctereum/rlp"
+)
+
+// ReorgJournalCapacity is the number of chain head events retained in the
+// reorg journal. Older entries are overwritten in a ring buffer fashion.
+const ReorgJournalCapacity = 4096
+
+// ReorgJournalBlock references a block dropped or added by a chain head event.
+type ReorgJournalBlock struct {
+	Number uint64
+	Hash   common.Hash
+}
+
+// ReorgJournalEntry is a persisted chain head event.
+type ReorgJournalEntry struct {
+	Seq     uint64              // Sequence number of the entry, assigned on write
+	Type    string              // Type of the event (head, fork or reorg)
+	Time    uint64              // Local unix time the event was recorded
+	Dropped []ReorgJournalBlock // Blocks removed from the canonical chain
+	Added   []ReorgJournalBlock // Blocks added to the chain
+}
+
+// ReadReorgJournalHead retrieves the sequence number of the next reorg journal
+// entry, which is also the total number of entries ever written.
+func ReadReorgJournalHead(db ethdb.KeyValueReader) uint64 {
+	data, _ := db.Get(reorgJournalHeadKey)
+	if len(data) != 8 {
+		return 0
+	}
+	return binary.BigEndian.Uint64(data)
+}
+
+// WriteReorgJournalEntry appends an entry to the reorg journal, overwriting the
+// oldest one if the journal is full. The sequence number of the entry is set
+// to its position in the journal.
+func WriteReorgJournalEntry(db ethdb.KeyValueStore, entry *ReorgJournalEntry) {
+	entry.Seq = ReadReorgJournalHead(db)
+	data, err := rlp.EncodeToBytes(entry)
+	if err != nil {
+		log.Crit("Failed to RLP encode reorg journal entry", "err", err)
+	}
+	batch := db.NewBatch()
+	if err := batch.Put(reorgJournalKey(entry.Seq%ReorgJournalCapacity), data); err != nil {
+		log.Crit("Failed to store reorg journal entry", "err", err)
+	}
+	if err := batch.Put(reorgJournalHeadKey, encodeBlockNumber(entry.Seq+1)); err != nil {
+		log.Crit("Failed to store reorg journal head", "err", err)
+	}
+	if err := batch.Write(); err != nil {
+		log.Crit("Failed to write reorg journal entry", "err", err)
+	}
+}
+
+// ReadReorgJournal retrieves up to count of the most recent reorg journal
+// entries, newest first.
+func ReadReorgJournal(db ethdb.KeyValueReader, count int) []*ReorgJournalEntry {
+	var (
+		head    = ReadReorgJournalHead(db)
+		entries []*ReorgJournalEntry
+	)
+	for seq := head; seq > 0 && head-seq < ReorgJournalCapacity && len(entries) < count; seq-- {
+		data, _ := db.Get(reorgJournalKey((seq - 1) % ReorgJournalCapacity))
+		if len(data) == 0 {
+			break
+		}
+		entry := new(ReorgJournalEntry)
+		if err := rlp.DecodeBytes(data, entry); err != nil {
+			log.Error("Invalid reorg journal entry", "seq", seq-1, "err", err)
+			break
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
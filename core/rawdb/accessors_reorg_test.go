@@ -0,0 +1,58 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"testing"
+
+	"github.com/qydata/go-ctereum/common"
+)
+
+// Tests that the reorg journal retains the most recent entries once wrapped.
+func TestReorgJournalWrap(t *testing.T) {
+	db := NewMemoryDatabase()
+
+	if entries := ReadReorgJournal(db, 10); len(entries) != 0 {
+		t.Fatalf("empty journal returned %d entries", len(entries))
+	}
+	total := ReorgJournalCapacity + 10
+	for i := 0; i < total; i++ {
+		WriteReorgJournalEntry(db, &ReorgJournalEntry{
+			Type:  "head",
+			Added: []ReorgJournalBlock{{Number: uint64(i), Hash: common.Hash{byte(i)}}},
+		})
+	}
+	if head := ReadReorgJournalHead(db); head != uint64(total) {
+		t.Fatalf("journal head mismatch: have %d, want %d", head, total)
+	}
+	entries := ReadReorgJournal(db, 3)
+	if len(entries) != 3 {
+		t.Fatalf("entry count mismatch: have %d, want %d", len(entries), 3)
+	}
+	for i, entry := range entries {
+		if want := uint64(total - 1 - i); entry.Seq != want || entry.Added[0].Number != want {
+			t.Errorf("entry %d mismatch: have seq %d block %d, want %d", i, entry.Seq, entry.Added[0].Number, want)
+		}
+	}
+	entries = ReadReorgJournal(db, total)
+	if len(entries) != ReorgJournalCapacity {
+		t.Fatalf("entry count mismatch: have %d, want %d", len(entries), ReorgJournalCapacity)
+	}
+	if oldest := entries[len(entries)-1]; oldest.Seq != uint64(total-ReorgJournalCapacity) {
+		t.Fatalf("oldest entry mismatch: have %d, want %d", oldest.Seq, total-ReorgJournalCapacity)
+	}
+}
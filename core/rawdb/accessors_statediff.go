@@ -0,0 +1,53 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"github.com/golang/snappy"
+	"github.com/qydata/go-ctereum/common"
+	"github.com/qydata/go-ctereum/ethdb"
+	"github.com/qydata/go-ctereum/log"
+)
+
+// ReadStateDiffRLP retrieves the RLP encoded state diff of a block, or nil if
+// none was recorded.
+func ReadStateDiffRLP(db ethdb.KeyValueReader, hash common.Hash, number uint64) []byte {
+	data, _ := db.Get(stateDiffKey(number, hash))
+	if len(data) == 0 {
+		return nil
+	}
+	blob, err := snappy.Decode(nil, data)
+	if err != nil {
+		log.Error("Invalid state diff", "number", number, "hash", hash, "err", err)
+		return nil
+	}
+	return blob
+}
+
+// WriteStateDiffRLP stores the RLP encoded state diff of a block, compressed.
+func WriteStateDiffRLP(db ethdb.KeyValueWriter, hash common.Hash, number uint64, blob []byte) {
+	if err := db.Put(stateDiffKey(number, hash), snappy.Encode(nil, blob)); err != nil {
+		log.Crit("Failed to store state diff", "err", err)
+	}
+}
+
+// DeleteStateDiff removes the state diff of a block.
+func DeleteStateDiff(db ethdb.KeyValueWriter, hash common.Hash, number uint64) {
+	if err := db.Delete(stateDiffKey(number, hash)); err != nil {
+		log.Crit("Failed to delete state diff", "err", err)
+	}
+}
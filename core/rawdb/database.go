@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"os"
 	"path"
+	"path/filepath"
 	"sync/atomic"
 	"time"
 
@@ -321,6 +322,85 @@ func NewLevelDBDatabaseWithFreezer(file string, cache int, handles int, ancient
 	return frdb, nil
 }
 
+const (
+	dbPebble  = "pebble"
+	dbLeveldb = "leveldb"
+)
+
+// PreexistingDatabase checks the given data directory whether a database is
+// already instantiated at that location, and if so, returns the type of the
+// database (or the empty string).
+func PreexistingDatabase(path string) string {
+	if _, err := os.Stat(filepath.Join(path, "CURRENT")); err != nil {
+		return "" // No pre-existing db
+	}
+	if matches, err := filepath.Glob(filepath.Join(path, "OPTIONS*")); len(matches) > 0 || err != nil {
+		if err != nil {
+			panic(err) // only possible if the pattern is malformed
+		}
+		return dbPebble
+	}
+	return dbLeveldb
+}
+
+// OpenOptions contains the options to apply when opening a database.
+// If AncientsDirectory is empty, it indicates that no freezer is to be used.
+type OpenOptions struct {
+	Type              string // "leveldb" | "pebble", empty to detect from the directory
+	Directory         string // the datadir
+	AncientsDirectory string // the ancients-dir
+	Namespace         string // the namespace for database relevant metrics
+	Cache             int    // the capacity(in megabytes) of the data caching
+	Handles           int    // number of files to be open simultaneously
+	ReadOnly          bool
+}
+
+// openKeyValueDatabase opens a disk-based key-value database, e.g. leveldb or pebble.
+//
+//	                      type == null          type != null
+//	                   +----------------------------------------
+//	db is non-existent |  leveldb default  |  specified type
+//	db is existent     |  from db          |  specified type (if compatible)
+func openKeyValueDatabase(o OpenOptions) (ethdb.Database, error) {
+	existingDb := PreexistingDatabase(o.Directory)
+	if len(existingDb) != 0 && len(o.Type) != 0 && o.Type != existingDb {
+		return nil, fmt.Errorf("db.engine choice was %v but found pre-existing %v database in specified data directory", o.Type, existingDb)
+	}
+	if o.Type == dbPebble || existingDb == dbPebble {
+		if !PebbleEnabled {
+			return nil, errors.New("db.engine 'pebble' not supported on this platform")
+		}
+		log.Info("Using pebble as the backing database")
+		return NewPebbleDBDatabase(o.Directory, o.Cache, o.Handles, o.Namespace, o.ReadOnly)
+	}
+	if len(o.Type) != 0 && o.Type != dbLeveldb {
+		return nil, fmt.Errorf("unknown db.engine %v", o.Type)
+	}
+	// Use leveldb, either as default (no explicit choice), or pre-existing, or chosen explicitly
+	log.Info("Using leveldb as the backing database")
+	return NewLevelDBDatabase(o.Directory, o.Cache, o.Handles, o.Namespace, o.ReadOnly)
+}
+
+// Open opens both a disk-based key-value database such as leveldb or pebble, but
+// also integrates it with a freezer database if the AncientsDirectory option has
+// been set on the provided OpenOptions. The passed AncientsDirectory indicates
+// the path of root ancient directory where the chain freezer can be opened.
+func Open(o OpenOptions) (ethdb.Database, error) {
+	kvdb, err := openKeyValueDatabase(o)
+	if err != nil {
+		return nil, err
+	}
+	if len(o.AncientsDirectory) == 0 {
+		return kvdb, nil
+	}
+	frdb, err := NewDatabaseWithFreezer(kvdb, o.AncientsDirectory, o.Namespace, o.ReadOnly)
+	if err != nil {
+		kvdb.Close()
+		return nil, err
+	}
+	return frdb, nil
+}
+
 type counter uint64
 
 func (c counter) String() string {
@@ -378,6 +458,12 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 		bloomBits       stat
 		beaconHeaders   stat
 		cliqueSnaps     stat
+		authStatuses    stat
+		authEvents      stat
+		stateDiffs      stat
+		witnesses       stat
+		reorgJournal    stat
+		logIndex        stat
 
 		// Ancient store statistics
 		ancientHeadersSize  common.StorageSize
@@ -441,6 +527,18 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 			beaconHeaders.Add(size)
 		case bytes.HasPrefix(key, []byte("clique-")) && len(key) == 7+common.HashLength:
 			cliqueSnaps.Add(size)
+		case bytes.HasPrefix(key, authStatusPrefix) && len(key) == (len(authStatusPrefix)+common.AddressLength):
+			authStatuses.Add(size)
+		case bytes.HasPrefix(key, authEventPrefix) && len(key) == (len(authEventPrefix)+common.AddressLength+12):
+			authEvents.Add(size)
+		case bytes.HasPrefix(key, stateDiffPrefix) && len(key) == (len(stateDiffPrefix)+8+common.HashLength):
+			stateDiffs.Add(size)
+		case bytes.HasPrefix(key, witnessPrefix) && len(key) == (len(witnessPrefix)+8+common.HashLength):
+			witnesses.Add(size)
+		case bytes.HasPrefix(key, reorgJournalPrefix) && len(key) == (len(reorgJournalPrefix)+8):
+			reorgJournal.Add(size)
+		case bytes.HasPrefix(key, logIndexPrefix) && len(key) == (len(logIndexPrefix)+common.HashLength+8):
+			logIndex.Add(size)
 		case bytes.HasPrefix(key, []byte("cht-")) ||
 			bytes.HasPrefix(key, []byte("chtIndexV2-")) ||
 			bytes.HasPrefix(key, []byte("chtRootV2-")): // Canonical hash trie
@@ -456,6 +554,7 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 				lastPivotKey, fastTrieProgressKey, snapshotDisabledKey, SnapshotRootKey, snapshotJournalKey,
 				snapshotGeneratorKey, snapshotRecoveryKey, txIndexTailKey, fastTxLookupLimitKey,
 				uncleanShutdownKey, badBlockKey, transitionStatusKey, skeletonSyncStatusKey,
+				authProgressKey, authIndexProgressKey, authBackfillKey, reorgJournalHeadKey, logIndexProgressKey,
 			} {
 				if bytes.Equal(key, meta) {
 					metadata.Add(size)
@@ -503,6 +602,12 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 		{"Key-Value store", "Storage snapshot", storageSnaps.Size(), storageSnaps.Count()},
 		{"Key-Value store", "Beacon sync headers", beaconHeaders.Size(), beaconHeaders.Count()},
 		{"Key-Value store", "Clique snapshots", cliqueSnaps.Size(), cliqueSnaps.Count()},
+		{"Key-Value store", "Auth statuses", authStatuses.Size(), authStatuses.Count()},
+		{"Key-Value store", "Auth events", authEvents.Size(), authEvents.Count()},
+		{"Key-Value store", "State diffs", stateDiffs.Size(), stateDiffs.Count()},
+		{"Key-Value store", "Execution witnesses", witnesses.Size(), witnesses.Count()},
+		{"Key-Value store", "Reorg journal", reorgJournal.Size(), reorgJournal.Count()},
+		{"Key-Value store", "Log index", logIndex.Size(), logIndex.Count()},
 		{"Key-Value store", "Singleton metadata", metadata.Size(), metadata.Count()},
 		{"Ancient store", "Headers", ancientHeadersSize.String(), ancients.String()},
 		{"Ancient store", "Bodies", ancientBodiesSize.String(), ancients.String()},
@@ -0,0 +1,75 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+//go:build arm64 || amd64
+// +build arm64 amd64
+
+package rawdb
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+)
+
+// Tests that the engine of an existing database is detected when opening it,
+// and that opening it with another engine is refused.
+func TestOpenDetectsEngine(t *testing.T) {
+	for _, engine := range []string{dbLeveldb, dbPebble} {
+		var (
+			dir  = filepath.Join(t.TempDir(), "chaindata")
+			opts = OpenOptions{
+				Type:              engine,
+				Directory:         dir,
+				AncientsDirectory: filepath.Join(dir, "ancient"),
+			}
+		)
+		if kind := PreexistingDatabase(dir); kind != "" {
+			t.Fatalf("%s: database detected before creation: %s", engine, kind)
+		}
+		db, err := Open(opts)
+		if err != nil {
+			t.Fatalf("%s: failed to create database: %v", engine, err)
+		}
+		if err := db.Put([]byte("key"), []byte("value")); err != nil {
+			t.Fatalf("%s: failed to write: %v", engine, err)
+		}
+		db.Close()
+
+		if kind := PreexistingDatabase(dir); kind != engine {
+			t.Fatalf("%s: detected engine mismatch: have %q", engine, kind)
+		}
+		// Reopen without an explicit engine
+		opts.Type = ""
+		if db, err = Open(opts); err != nil {
+			t.Fatalf("%s: failed to reopen database: %v", engine, err)
+		}
+		if value, err := db.Get([]byte("key")); err != nil || !bytes.Equal(value, []byte("value")) {
+			t.Errorf("%s: value mismatch: have %q, %v", engine, value, err)
+		}
+		db.Close()
+
+		// Reopen with the other engine
+		opts.Type = dbPebble
+		if engine == dbPebble {
+			opts.Type = dbLeveldb
+		}
+		if db, err = Open(opts); err == nil {
+			db.Close()
+			t.Errorf("%s: opened as %s", engine, opts.Type)
+		}
+	}
+}
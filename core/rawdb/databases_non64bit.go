@@ -0,0 +1,35 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+//go:build !(arm64 || amd64)
+// +build !arm64,!amd64
+
+package rawdb
+
+import (
+	"errors"
+
+	"github.com/qydata/go-ctereum/ethdb"
+)
+
+// PebbleEnabled reports whether pebble is available on this platform, it's only
+// supported on 64bit architectures.
+const PebbleEnabled = false
+
+// NewPebbleDBDatabase is a placeholder on the platforms pebble does not support.
+func NewPebbleDBDatabase(file string, cache int, handles int, namespace string, readonly bool) (ethdb.Database, error) {
+	return nil, errors.New("pebble is not supported on this platform")
+}
@@ -0,0 +1,278 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/golang/snappy"
+	"github.com/qydata/go-ctereum/common"
+	"github.com/qydata/go-ctereum/crypto"
+	"github.com/qydata/go-ctereum/log"
+)
+
+// FreezerTableReport is the result of verifying a single freezer table.
+type FreezerTableReport struct {
+	Name  string // Name of the table
+	Tail  uint64 // Number of items removed from the tail of the table
+	Items uint64 // Number of items indexed by the table, including the removed ones
+	Good  uint64 // Number of leading items found intact
+	Err   error  // First inconsistency found in the table, nil if intact
+}
+
+// FreezerReport is the result of verifying all the tables of a freezer.
+type FreezerReport struct {
+	Tables []*FreezerTableReport // Reports of the individual tables, sorted by name
+	Tail   uint64                // First item held by all the tables
+	Items  uint64                // Number of items held by all the tables
+	Good   uint64                // Number of leading items found intact in all the tables
+}
+
+// Corrupted reports whether an inconsistency was found in any of the tables.
+func (r *FreezerReport) Corrupted() bool {
+	for _, table := range r.Tables {
+		if table.Err != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// freezerTableVerifier sequentially reads the items of a freezer table straight
+// from its files, cross-checking the index against the data files. Contrary to
+// opening the table, it never modifies any of the files.
+type freezerTableVerifier struct {
+	report   *FreezerTableReport
+	path     string
+	noSnappy bool
+
+	index *os.File
+	buf   *bufio.Reader
+	files map[uint32]*os.File
+	sizes map[uint32]int64
+	prev  indexEntry // Index entry of the last item read
+	next  uint64     // Number of the next item to read
+}
+
+// newFreezerTableVerifier opens the index of the given table for verification.
+func newFreezerTableVerifier(path, name string, noSnappy bool) (*freezerTableVerifier, error) {
+	idxName := fmt.Sprintf("%s.cidx", name)
+	if noSnappy {
+		idxName = fmt.Sprintf("%s.ridx", name)
+	}
+	index, err := openFreezerFileForReadOnly(filepath.Join(path, idxName))
+	if err != nil {
+		return nil, err
+	}
+	stat, err := index.Stat()
+	if err != nil {
+		index.Close()
+		return nil, err
+	}
+	v := &freezerTableVerifier{
+		report:   &FreezerTableReport{Name: name},
+		path:     path,
+		noSnappy: noSnappy,
+		index:    index,
+		buf:      bufio.NewReaderSize(index, 1024*indexEntrySize),
+		files:    make(map[uint32]*os.File),
+		sizes:    make(map[uint32]int64),
+	}
+	if stat.Size() < indexEntrySize {
+		return v, nil
+	}
+	// The first index entry carries the number of the first data file and the
+	// number of items removed from the tail instead of an offset.
+	buffer := make([]byte, indexEntrySize)
+	if _, err := io.ReadFull(v.buf, buffer); err != nil {
+		v.close()
+		return nil, err
+	}
+	var first indexEntry
+	first.unmarshalBinary(buffer)
+
+	v.report.Tail = uint64(first.offset)
+	v.report.Items = v.report.Tail + uint64(stat.Size()/indexEntrySize-1)
+	v.report.Good = v.report.Tail
+	v.prev = indexEntry{filenum: first.filenum}
+	v.next = v.report.Tail
+	return v, nil
+}
+
+// dataFile opens the given data file of the table, returning it along with its
+// size.
+func (v *freezerTableVerifier) dataFile(num uint32) (*os.File, int64, error) {
+	if f, ok := v.files[num]; ok {
+		return f, v.sizes[num], nil
+	}
+	name := fmt.Sprintf("%s.%04d.cdat", v.report.Name, num)
+	if v.noSnappy {
+		name = fmt.Sprintf("%s.%04d.rdat", v.report.Name, num)
+	}
+	f, err := openFreezerFileForReadOnly(filepath.Join(v.path, name))
+	if err != nil {
+		return nil, 0, err
+	}
+	stat, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, 0, err
+	}
+	v.files[num], v.sizes[num] = f, stat.Size()
+	return f, stat.Size(), nil
+}
+
+// read retrieves the next item of the table, verifying that its index entry is
+// consistent with the previous one and with the data files. The data files no
+// longer referenced are closed along the way.
+func (v *freezerTableVerifier) read() ([]byte, error) {
+	buffer := make([]byte, indexEntrySize)
+	if _, err := io.ReadFull(v.buf, buffer); err != nil {
+		return nil, fmt.Errorf("item %d: index unreadable: %v", v.next, err)
+	}
+	var entry indexEntry
+	entry.unmarshalBinary(buffer)
+
+	switch {
+	case entry.filenum < v.prev.filenum || entry.filenum > v.prev.filenum+1:
+		return nil, fmt.Errorf("item %d: data file %d out of sequence after %d", v.next, entry.filenum, v.prev.filenum)
+	case entry.filenum == v.prev.filenum && entry.offset < v.prev.offset:
+		return nil, fmt.Errorf("item %d: offset %d below previous offset %d", v.next, entry.offset, v.prev.offset)
+	}
+	start, end, num := v.prev.bounds(&entry)
+	if num != v.prev.filenum {
+		if f, ok := v.files[v.prev.filenum]; ok {
+			f.Close()
+			delete(v.files, v.prev.filenum)
+		}
+	}
+	f, size, err := v.dataFile(num)
+	if err != nil {
+		return nil, fmt.Errorf("item %d: %v", v.next, err)
+	}
+	if int64(end) > size {
+		return nil, fmt.Errorf("item %d: data file %d too short, have %d bytes, want %d", v.next, num, size, end)
+	}
+	blob := make([]byte, end-start)
+	if _, err := f.ReadAt(blob, int64(start)); err != nil {
+		return nil, fmt.Errorf("item %d: data unreadable: %v", v.next, err)
+	}
+	if !v.noSnappy {
+		if blob, err = snappy.Decode(nil, blob); err != nil {
+			return nil, fmt.Errorf("item %d: data not decodable: %v", v.next, err)
+		}
+	}
+	v.prev = entry
+	v.next++
+	return blob, nil
+}
+
+// close releases all the files opened by the verifier.
+func (v *freezerTableVerifier) close() {
+	v.index.Close()
+	for _, f := range v.files {
+		f.Close()
+	}
+}
+
+// VerifyChainFreezer checks the integrity of all the tables of the chain freezer
+// located in the given ancient directory, without modifying any of them. Every
+// item held by all the tables is read back and cross-checked: the indices must
+// point within the data files, compressed items must be decodable and every
+// header must hash to the canonical hash stored next to it.
+//
+// The returned report holds the number of leading items found intact, which is
+// the height the ancient store can be truncated to in order to repair it.
+func VerifyChainFreezer(ancient string) (*FreezerReport, error) {
+	var (
+		path      = resolveChainFreezerDir(ancient)
+		names     []string
+		verifiers = make(map[string]*freezerTableVerifier)
+		report    = new(FreezerReport)
+	)
+	for name := range chainFreezerNoSnappy {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for i, name := range names {
+		v, err := newFreezerTableVerifier(path, name, chainFreezerNoSnappy[name])
+		if err != nil {
+			return nil, err
+		}
+		defer v.close()
+		verifiers[name] = v
+
+		report.Tables = append(report.Tables, v.report)
+		if i == 0 || v.report.Tail > report.Tail {
+			report.Tail = v.report.Tail
+		}
+		if i == 0 || v.report.Items < report.Items {
+			report.Items = v.report.Items
+		}
+	}
+	// Skip the items removed from the tail of any of the tables
+	for _, name := range names {
+		v := verifiers[name]
+		for v.next < report.Tail {
+			if _, err := v.read(); err != nil {
+				v.report.Err = err
+				report.Good = report.Tail
+				return report, nil
+			}
+			v.report.Good = v.next
+		}
+	}
+	var (
+		start    = time.Now()
+		reported = time.Now()
+		blobs    = make(map[string][]byte)
+	)
+	for number := report.Tail; number < report.Items; number++ {
+		for _, name := range names {
+			v := verifiers[name]
+			blob, err := v.read()
+			if err != nil {
+				v.report.Err = err
+				report.Good = number
+				return report, nil
+			}
+			blobs[name] = blob
+		}
+		hash := common.BytesToHash(blobs[chainFreezerHashTable])
+		if have := crypto.Keccak256Hash(blobs[chainFreezerHeaderTable]); have != hash {
+			verifiers[chainFreezerHeaderTable].report.Err = fmt.Errorf("item %d: header hash %x mismatches canonical hash %x", number, have, hash)
+			report.Good = number
+			return report, nil
+		}
+		for _, name := range names {
+			verifiers[name].report.Good = number + 1
+		}
+		if time.Since(reported) >= 8*time.Second {
+			log.Info("Verifying ancient store", "verified", number-report.Tail+1, "total", report.Items-report.Tail, "elapsed", common.PrettyDuration(time.Since(start)))
+			reported = time.Now()
+		}
+	}
+	report.Good = report.Items
+	return report, nil
+}
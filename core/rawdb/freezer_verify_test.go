@@ -0,0 +1,85 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Tests that corruptions of the chain freezer are detected and located.
+func TestVerifyChainFreezer(t *testing.T) {
+	dir := t.TempDir()
+	db, err := NewDatabaseWithFreezer(NewMemoryDatabase(), dir, "", false)
+	if err != nil {
+		t.Fatalf("failed to create database with ancient backend: %v", err)
+	}
+	if _, err := WriteAncientBlocks(db, makeTestBlocks(10, 1), makeTestReceipts(10, 1), big.NewInt(100)); err != nil {
+		t.Fatalf("failed to write ancient blocks: %v", err)
+	}
+	db.Close()
+
+	report, err := VerifyChainFreezer(dir)
+	if err != nil {
+		t.Fatalf("failed to verify freezer: %v", err)
+	}
+	if report.Corrupted() || report.Items != 10 || report.Good != 10 {
+		t.Fatalf("intact freezer mismatch: corrupted %v, items %d, good %d", report.Corrupted(), report.Items, report.Good)
+	}
+	// Flip a byte of the fifth canonical hash
+	path := resolveChainFreezerDir(dir)
+	hashes, err := os.OpenFile(filepath.Join(path, "hashes.0000.rdat"), os.O_RDWR, 0644)
+	if err != nil {
+		t.Fatalf("failed to open hashes: %v", err)
+	}
+	defer hashes.Close()
+
+	original := make([]byte, 1)
+	hashes.ReadAt(original, 4*32)
+	hashes.WriteAt([]byte{^original[0]}, 4*32)
+
+	if report, err = VerifyChainFreezer(dir); err != nil {
+		t.Fatalf("failed to verify freezer: %v", err)
+	}
+	if !report.Corrupted() || report.Good != 4 {
+		t.Errorf("hash corruption mismatch: corrupted %v, good %d, want 4", report.Corrupted(), report.Good)
+	}
+	hashes.WriteAt(original, 4*32)
+
+	// Cut the bodies data file in half
+	bodies := filepath.Join(path, "bodies.0000.cdat")
+	stat, err := os.Stat(bodies)
+	if err != nil {
+		t.Fatalf("failed to stat bodies: %v", err)
+	}
+	if err := os.Truncate(bodies, stat.Size()/2); err != nil {
+		t.Fatalf("failed to truncate bodies: %v", err)
+	}
+	if report, err = VerifyChainFreezer(dir); err != nil {
+		t.Fatalf("failed to verify freezer: %v", err)
+	}
+	if report.Good != 5 {
+		t.Errorf("truncation mismatch: good %d, want 5", report.Good)
+	}
+	for _, table := range report.Tables {
+		if (table.Name == chainFreezerBodiesTable) != (table.Err != nil) {
+			t.Errorf("table %s error mismatch: %v", table.Name, table.Err)
+		}
+	}
+}
@@ -84,6 +84,30 @@ var (
 	// transitionStatusKey tracks the eth2 transition status.
 	transitionStatusKey = []byte("eth2-transition")
 
+	// authProgressKey tracks the last block scanned for auth contract events.
+	authProgressKey = []byte("AuthManagerProgress")
+
+	// authIndexProgressKey tracks the range of blocks indexed for auth events.
+	authIndexProgressKey = []byte("AuthIndexProgress")
+
+	// authBackfillKey tracks the target of the running auth event backfill.
+	authBackfillKey = []byte("AuthIndexBackfill")
+
+	// authOrderKey tracks the next order ID handed out by the auth manager.
+	authOrderKey = []byte("AuthOrderCounter")
+
+	// authRegistryProgressKey tracks the first block scanned for the events of
+	// each registered auth contract.
+	authRegistryProgressKey = []byte("AuthManagerRegistry")
+
+	// reorgJournalHeadKey tracks the sequence number of the next chain head
+	// journal entry.
+	reorgJournalHeadKey = []byte("ReorgJournalHead")
+
+	// logIndexProgressKey tracks the range of blocks and the event signatures
+	// covered by the log index.
+	logIndexProgressKey = []byte("LogIndexProgress")
+
 	// Data item prefixes (use single byte to avoid mixing data types, avoid `i`, used for indexes).
 	headerPrefix       = []byte("h") // headerPrefix + num (uint64 big endian) + hash -> header
 	headerTDSuffix     = []byte("t") // headerPrefix + num (uint64 big endian) + hash + headerTDSuffix -> td
@@ -100,9 +124,15 @@ var (
 	CodePrefix            = []byte("c") // CodePrefix + code hash -> account code
 	skeletonHeaderPrefix  = []byte("S") // skeletonHeaderPrefix + num (uint64 big endian) -> header
 
-	PreimagePrefix = []byte("secure-key-")       // PreimagePrefix + hash -> preimage
-	configPrefix   = []byte("ethereum-config-")  // config prefix for the db
-	genesisPrefix  = []byte("ethereum-genesis-") // genesis state prefix for the db
+	PreimagePrefix     = []byte("secure-key-")       // PreimagePrefix + hash -> preimage
+	configPrefix       = []byte("ethereum-config-")  // config prefix for the db
+	genesisPrefix      = []byte("ethereum-genesis-") // genesis state prefix for the db
+	authStatusPrefix   = []byte("auth-status-")      // authStatusPrefix + [contract] + address -> auth status history
+	authEventPrefix    = []byte("auth-event-")       // authEventPrefix + address + num (uint64 big endian) + log index (uint32 big endian) -> auth event
+	stateDiffPrefix    = []byte("state-diff-")       // stateDiffPrefix + num (uint64 big endian) + hash -> compressed state diff
+	witnessPrefix      = []byte("witness-")          // witnessPrefix + num (uint64 big endian) + hash -> compressed execution witness
+	reorgJournalPrefix = []byte("reorg-journal-")    // reorgJournalPrefix + slot (uint64 big endian) -> chain head journal entry
+	logIndexPrefix     = []byte("log-index-")        // logIndexPrefix + topic + num (uint64 big endian) -> empty
 
 	// Chain index prefixes (use `i` + single byte to avoid mixing data types).
 	BloomBitsIndexPrefix = []byte("iB") // BloomBitsIndexPrefix is the data table of a chain indexer to track its progress
@@ -196,6 +226,30 @@ func skeletonHeaderKey(number uint64) []byte {
 	return append(skeletonHeaderPrefix, encodeBlockNumber(number)...)
 }
 
+// authStatusKey = authStatusPrefix + address
+func authStatusKey(addr common.Address) []byte {
+	return append(authStatusPrefix, addr.Bytes()...)
+}
+
+// authControllerStatusKey = authStatusPrefix + contract + address
+func authControllerStatusKey(contract common.Address, addr common.Address) []byte {
+	key := make([]byte, len(authStatusPrefix)+2*common.AddressLength)
+	copy(key, authStatusPrefix)
+	copy(key[len(authStatusPrefix):], contract.Bytes())
+	copy(key[len(authStatusPrefix)+common.AddressLength:], addr.Bytes())
+	return key
+}
+
+// authEventKey = authEventPrefix + address + num (uint64 big endian) + log index (uint32 big endian)
+func authEventKey(addr common.Address, number uint64, index uint32) []byte {
+	key := make([]byte, len(authEventPrefix)+common.AddressLength+12)
+	copy(key, authEventPrefix)
+	copy(key[len(authEventPrefix):], addr.Bytes())
+	binary.BigEndian.PutUint64(key[len(authEventPrefix)+common.AddressLength:], number)
+	binary.BigEndian.PutUint32(key[len(authEventPrefix)+common.AddressLength+8:], index)
+	return key
+}
+
 // preimageKey = PreimagePrefix + hash
 func preimageKey(hash common.Hash) []byte {
 	return append(PreimagePrefix, hash.Bytes()...)
@@ -224,3 +278,23 @@ func configKey(hash common.Hash) []byte {
 func genesisStateSpecKey(hash common.Hash) []byte {
 	return append(genesisPrefix, hash.Bytes()...)
 }
+
+// witnessKey = witnessPrefix + num (uint64 big endian) + hash
+func witnessKey(number uint64, hash common.Hash) []byte {
+	return append(append(witnessPrefix, encodeBlockNumber(number)...), hash.Bytes()...)
+}
+
+// reorgJournalKey = reorgJournalPrefix + slot (uint64 big endian)
+func reorgJournalKey(slot uint64) []byte {
+	return append(reorgJournalPrefix, encodeBlockNumber(slot)...)
+}
+
+// logIndexKey = logIndexPrefix + topic + num (uint64 big endian)
+func logIndexKey(topic common.Hash, number uint64) []byte {
+	return append(append(logIndexPrefix, topic.Bytes()...), encodeBlockNumber(number)...)
+}
+
+// stateDiffKey = stateDiffPrefix + num (uint64 big endian) + hash
+func stateDiffKey(number uint64, hash common.Hash) []byte {
+	return append(append(stateDiffPrefix, encodeBlockNumber(number)...), hash.Bytes()...)
+}
@@ -0,0 +1,146 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"github.com/qydata/go-ctereum/common"
+)
+
+// AccessRecord collects the accounts and storage slots read and written through
+// a StateDB while recording. Writes of reverted calls are kept, so the write
+// sets may be larger than the actual changes.
+type AccessRecord struct {
+	AccountReads  map[common.Address]struct{}                 // Accounts whose balance, nonce, code or existence was read
+	SlotReads     map[common.Address]map[common.Hash]struct{} // Storage slots read
+	AccountWrites map[common.Address]struct{}                 // Accounts whose balance or nonce was changed
+	SlotWrites    map[common.Address]map[common.Hash]struct{} // Storage slots written
+	Resets        map[common.Address]struct{}                 // Accounts created, destroyed or given code
+
+	// Replayable reports whether the changes are limited to balances, nonces
+	// and storage slots, so they can be carried over to another state.
+	Replayable bool
+}
+
+// NewAccessRecord creates an empty access record.
+func NewAccessRecord() *AccessRecord {
+	return &AccessRecord{
+		AccountReads:  make(map[common.Address]struct{}),
+		SlotReads:     make(map[common.Address]map[common.Hash]struct{}),
+		AccountWrites: make(map[common.Address]struct{}),
+		SlotWrites:    make(map[common.Address]map[common.Hash]struct{}),
+		Resets:        make(map[common.Address]struct{}),
+		Replayable:    true,
+	}
+}
+
+// Conflicts reports whether any of the reads or slot writes of the record
+// overlap the writes of the other one. Balance writes alone do not conflict, as
+// balance changes are carried over as deltas.
+func (r *AccessRecord) Conflicts(other *AccessRecord) bool {
+	for addr := range r.AccountReads {
+		if _, ok := other.AccountWrites[addr]; ok {
+			return true
+		}
+		if _, ok := other.Resets[addr]; ok {
+			return true
+		}
+	}
+	for _, slots := range []map[common.Address]map[common.Hash]struct{}{r.SlotReads, r.SlotWrites} {
+		for addr, keys := range slots {
+			if _, ok := other.Resets[addr]; ok {
+				return true
+			}
+			for key := range keys {
+				if _, ok := other.SlotWrites[addr][key]; ok {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// MergeWrites adds the writes of the other record to this one.
+func (r *AccessRecord) MergeWrites(other *AccessRecord) {
+	for addr := range other.AccountWrites {
+		r.AccountWrites[addr] = struct{}{}
+	}
+	for addr, keys := range other.SlotWrites {
+		for key := range keys {
+			addSlot(r.SlotWrites, addr, key)
+		}
+	}
+	for addr := range other.Resets {
+		r.Resets[addr] = struct{}{}
+	}
+}
+
+func addSlot(slots map[common.Address]map[common.Hash]struct{}, addr common.Address, key common.Hash) {
+	keys, ok := slots[addr]
+	if !ok {
+		keys = make(map[common.Hash]struct{})
+		slots[addr] = keys
+	}
+	keys[key] = struct{}{}
+}
+
+// RecordAccess starts collecting the accesses into the record, or stops if it
+// is nil.
+func (s *StateDB) RecordAccess(record *AccessRecord) {
+	s.access = record
+}
+
+func (s *StateDB) recordAccountRead(addr common.Address) {
+	if s.access != nil {
+		s.access.AccountReads[addr] = struct{}{}
+	}
+}
+
+func (s *StateDB) recordSlotRead(addr common.Address, key common.Hash) {
+	if s.access != nil {
+		addSlot(s.access.SlotReads, addr, key)
+	}
+}
+
+func (s *StateDB) recordAccountWrite(addr common.Address) {
+	if s.access != nil {
+		s.access.AccountWrites[addr] = struct{}{}
+	}
+}
+
+func (s *StateDB) recordSlotWrite(addr common.Address, key common.Hash) {
+	if s.access != nil {
+		addSlot(s.access.SlotWrites, addr, key)
+	}
+}
+
+// recordReset records an account whose code or storage changed as a whole,
+// which cannot be replayed.
+func (s *StateDB) recordReset(addr common.Address) {
+	if s.access != nil {
+		s.access.Resets[addr] = struct{}{}
+		s.access.Replayable = false
+	}
+}
+
+// recordUnreplayable records a change outside the accounts, which cannot be
+// replayed.
+func (s *StateDB) recordUnreplayable() {
+	if s.access != nil {
+		s.access.Replayable = false
+	}
+}
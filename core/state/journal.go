@@ -121,6 +121,15 @@ type (
 	refundChange struct {
 		prev uint64
 	}
+	subsidyChange struct {
+		prevPayee  common.Address
+		prevGas    uint64
+		prevAmount *big.Int
+	}
+	tipsChange struct {
+		prevPayee  common.Address
+		prevAmount *big.Int
+	}
 	addLogChange struct {
 		txhash common.Hash
 	}
@@ -221,6 +230,22 @@ func (ch refundChange) dirtied() *common.Address {
 	return nil
 }
 
+func (ch subsidyChange) revert(s *StateDB) {
+	s.subsidyPayee, s.subsidyGas, s.subsidyAmount = ch.prevPayee, ch.prevGas, ch.prevAmount
+}
+
+func (ch subsidyChange) dirtied() *common.Address {
+	return nil
+}
+
+func (ch tipsChange) revert(s *StateDB) {
+	s.tipsPayee, s.tipsAmount = ch.prevPayee, ch.prevAmount
+}
+
+func (ch tipsChange) dirtied() *common.Address {
+	return nil
+}
+
 func (ch addLogChange) revert(s *StateDB) {
 	logs := s.logs[ch.txhash]
 	if len(logs) == 1 {
@@ -0,0 +1,78 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package pruner
+
+import (
+	"fmt"
+
+	"github.com/qydata/go-ctereum/common"
+	"github.com/qydata/go-ctereum/core/rawdb"
+	"github.com/qydata/go-ctereum/core/types"
+	"github.com/qydata/go-ctereum/ethdb"
+	"github.com/qydata/go-ctereum/params"
+)
+
+// maxTargetSearchDepth is the number of blocks below the head searched for the
+// block belonging to the pruning target.
+const maxTargetSearchDepth = params.FullImmutabilityThreshold
+
+// checkCliqueTarget ensures that dropping all states apart from the target one
+// doesn't prevent the clique engine from processing the chain on top of it. As
+// the chain is rewound to the target state after the pruning and the blocks
+// above it are processed again, the target may not be below:
+//
+//   - the last checkpoint block, the base of the signer snapshots reconstructed
+//     for the blocks above it
+//   - the Poa2Pos transition block, whose state replaces the validator contract
+//     queried by the engine from then on
+func checkCliqueTarget(db ethdb.Reader, config *params.ChainConfig, head *types.Header, root common.Hash) error {
+	if config == nil || config.Clique == nil {
+		return nil
+	}
+	target, ok := findStateBlock(db, head, root)
+	if !ok {
+		return fmt.Errorf("pruning target %x not within the last %d blocks", root, maxTargetSearchDepth)
+	}
+	number := head.Number.Uint64()
+	if epoch := config.Clique.Epoch; epoch > 0 {
+		if checkpoint := number - number%epoch; target < checkpoint {
+			return fmt.Errorf("pruning target #%d is below the last clique checkpoint #%d", target, checkpoint)
+		}
+	}
+	// The validator contract is replaced while finalizing the block before the
+	// fork block.
+	if fork := config.Clique.Poa2PosBlock; fork > 0 {
+		if transition := uint64(fork) - 1; target < transition && transition <= number {
+			return fmt.Errorf("pruning target #%d is below the Poa2Pos transition block #%d", target, transition)
+		}
+	}
+	return nil
+}
+
+// findStateBlock returns the number of the most recent canonical block with the
+// given state root, searching back from the head.
+func findStateBlock(db ethdb.Reader, head *types.Header, root common.Hash) (uint64, bool) {
+	for header := head; header != nil; header = rawdb.ReadHeader(db, header.ParentHash, header.Number.Uint64()-1) {
+		if header.Root == root {
+			return header.Number.Uint64(), true
+		}
+		if header.Number.Uint64() == 0 || head.Number.Uint64()-header.Number.Uint64() >= maxTargetSearchDepth {
+			break
+		}
+	}
+	return 0, false
+}
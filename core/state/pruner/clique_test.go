@@ -0,0 +1,76 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package pruner
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/qydata/go-ctereum/common"
+	"github.com/qydata/go-ctereum/core/rawdb"
+	"github.com/qydata/go-ctereum/core/types"
+	"github.com/qydata/go-ctereum/params"
+)
+
+// Tests that pruning targets the clique engine can't process the chain on top
+// of are rejected.
+func TestCheckCliqueTarget(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+
+	// Block n has the state root n
+	var head *types.Header
+	for n := uint64(0); n <= 250; n++ {
+		header := &types.Header{Number: new(big.Int).SetUint64(n), Root: common.BigToHash(new(big.Int).SetUint64(n))}
+		if head != nil {
+			header.ParentHash = head.Hash()
+		}
+		rawdb.WriteHeader(db, header)
+		head = header
+	}
+	root := func(n int64) common.Hash { return common.BigToHash(big.NewInt(n)) }
+
+	for i, tc := range []struct {
+		config *params.CliqueConfig
+		target common.Hash
+		ok     bool
+	}{
+		// Non-clique networks are not checked
+		{nil, root(10), true},
+		// Targets above the last checkpoint are accepted
+		{&params.CliqueConfig{Epoch: 100}, root(200), true},
+		{&params.CliqueConfig{Epoch: 100}, root(240), true},
+		{&params.CliqueConfig{Epoch: 100}, root(199), false},
+		// Targets below the Poa2Pos transition are rejected once it passed
+		{&params.CliqueConfig{Epoch: 1000, Poa2PosBlock: 230}, root(229), true},
+		{&params.CliqueConfig{Epoch: 1000, Poa2PosBlock: 230}, root(228), false},
+		{&params.CliqueConfig{Epoch: 1000, Poa2PosBlock: 300}, root(10), true},
+		// Unknown targets are rejected
+		{&params.CliqueConfig{Epoch: 1000}, common.HexToHash("0xdeadbeef"), false},
+	} {
+		config := &params.ChainConfig{Clique: tc.config}
+		if tc.config == nil {
+			config = params.TestChainConfig
+		}
+		err := checkCliqueTarget(db, config, head, tc.target)
+		if tc.ok && err != nil {
+			t.Errorf("test %d: unexpected error: %v", i, err)
+		}
+		if !tc.ok && err == nil {
+			t.Errorf("test %d: expected error", i)
+		}
+	}
+}
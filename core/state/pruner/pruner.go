@@ -34,6 +34,8 @@ import (
 	"github.com/qydata/go-ctereum/crypto"
 	"github.com/qydata/go-ctereum/ethdb"
 	"github.com/qydata/go-ctereum/log"
+	"github.com/qydata/go-ctereum/metrics"
+	"github.com/qydata/go-ctereum/params"
 	"github.com/qydata/go-ctereum/rlp"
 	"github.com/qydata/go-ctereum/trie"
 )
@@ -61,8 +63,22 @@ var (
 
 	// emptyCode is the known hash of the empty EVM bytecode.
 	emptyCode = crypto.Keccak256(nil)
+
+	// Progress of the stale state deletion, reporting the number and size of the
+	// deleted entries and the percentage of the database iterated.
+	pruneNodesGauge    = metrics.NewRegisteredGauge("state/prune/nodes", nil)
+	pruneSizeGauge     = metrics.NewRegisteredGauge("state/prune/size", nil)
+	pruneProgressGauge = metrics.NewRegisteredGauge("state/prune/progress", nil)
 )
 
+// Config includes all the configurations for pruning.
+type Config struct {
+	Datadir       string // The directory of the state database
+	TrieCachePath string // The directory of the clean trie cache journal
+	BloomSize     uint64 // The Megabytes of memory allocated to bloom-filter
+	Force         bool   // Whether to prune regardless of the clique safety checks
+}
+
 // Pruner is an offline tool to prune the stale state with the
 // help of the snapshot. The workflow of pruner is very simple:
 //
@@ -75,16 +91,16 @@ var (
 // periodically in order to release the disk usage and improve the
 // disk read performance to some extent.
 type Pruner struct {
-	db            ethdb.Database
-	stateBloom    *stateBloom
-	datadir       string
-	trieCachePath string
-	headHeader    *types.Header
-	snaptree      *snapshot.Tree
+	config      Config
+	chainConfig *params.ChainConfig
+	db          ethdb.Database
+	stateBloom  *stateBloom
+	headHeader  *types.Header
+	snaptree    *snapshot.Tree
 }
 
 // NewPruner creates the pruner instance.
-func NewPruner(db ethdb.Database, datadir, trieCachePath string, bloomSize uint64) (*Pruner, error) {
+func NewPruner(db ethdb.Database, config Config) (*Pruner, error) {
 	headBlock := rawdb.ReadHeadBlock(db)
 	if headBlock == nil {
 		return nil, errors.New("Failed to load head block")
@@ -94,21 +110,21 @@ func NewPruner(db ethdb.Database, datadir, trieCachePath string, bloomSize uint6
 		return nil, err // The relevant snapshot(s) might not exist
 	}
 	// Sanitize the bloom filter size if it's too small.
-	if bloomSize < 256 {
-		log.Warn("Sanitizing bloomfilter size", "provided(MB)", bloomSize, "updated(MB)", 256)
-		bloomSize = 256
+	if config.BloomSize < 256 {
+		log.Warn("Sanitizing bloomfilter size", "provided(MB)", config.BloomSize, "updated(MB)", 256)
+		config.BloomSize = 256
 	}
-	stateBloom, err := newStateBloomWithSize(bloomSize)
+	stateBloom, err := newStateBloomWithSize(config.BloomSize)
 	if err != nil {
 		return nil, err
 	}
 	return &Pruner{
-		db:            db,
-		stateBloom:    stateBloom,
-		datadir:       datadir,
-		trieCachePath: trieCachePath,
-		headHeader:    headBlock.Header(),
-		snaptree:      snaptree,
+		config:      config,
+		chainConfig: rawdb.ReadChainConfig(db, rawdb.ReadCanonicalHash(db, 0)),
+		db:          db,
+		stateBloom:  stateBloom,
+		headHeader:  headBlock.Header(),
+		snaptree:    snaptree,
 	}, nil
 }
 
@@ -154,6 +170,10 @@ func prune(snaptree *snapshot.Tree, root common.Hash, maindb ethdb.Database, sta
 			size += common.StorageSize(len(key) + len(iter.Value()))
 			batch.Delete(key)
 
+			pruneNodesGauge.Update(int64(count))
+			pruneSizeGauge.Update(int64(size))
+			pruneProgressGauge.Update(int64(binary.BigEndian.Uint64(key[:8]) / (math.MaxUint64 / 100)))
+
 			var eta time.Duration // Realistically will never remain uninited
 			if done := binary.BigEndian.Uint64(key[:8]); done > 0 {
 				var (
@@ -183,6 +203,7 @@ func prune(snaptree *snapshot.Tree, root common.Hash, maindb ethdb.Database, sta
 		batch.Reset()
 	}
 	iter.Release()
+	pruneProgressGauge.Update(100)
 	log.Info("Pruned state data", "nodes", count, "size", size, "elapsed", common.PrettyDuration(time.Since(pstart)))
 
 	// Pruning is done, now drop the "useless" layers from the snapshot.
@@ -236,12 +257,12 @@ func (p *Pruner) Prune(root common.Hash) error {
 	// reuse it for pruning instead of generating a new one. It's
 	// mandatory because a part of state may already be deleted,
 	// the recovery procedure is necessary.
-	_, stateBloomRoot, err := findBloomFilter(p.datadir)
+	_, stateBloomRoot, err := findBloomFilter(p.config.Datadir)
 	if err != nil {
 		return err
 	}
 	if stateBloomRoot != (common.Hash{}) {
-		return RecoverPruning(p.datadir, p.db, p.trieCachePath)
+		return RecoverPruning(p.config.Datadir, p.db, p.config.TrieCachePath)
 	}
 	// If the target state root is not specified, use the HEAD-127 as the
 	// target. The reason for picking it is:
@@ -299,11 +320,19 @@ func (p *Pruner) Prune(root common.Hash) error {
 			log.Info("Selecting user-specified state as the pruning target", "root", root)
 		}
 	}
+	// Ensure the clique engine can still process the chain on top of the
+	// pruning target, unless explicitly overridden.
+	if err := checkCliqueTarget(p.db, p.chainConfig, p.headHeader, root); err != nil {
+		if !p.config.Force {
+			return err
+		}
+		log.Warn("Forcing state pruning", "reason", err)
+	}
 	// Before start the pruning, delete the clean trie cache first.
 	// It's necessary otherwise in the next restart we will hit the
 	// deleted state root in the "clean cache" so that the incomplete
 	// state is picked for usage.
-	deleteCleanTrieCache(p.trieCachePath)
+	deleteCleanTrieCache(p.config.TrieCachePath)
 
 	// All the state roots of the middle layer should be forcibly pruned,
 	// otherwise the dangling state will be left.
@@ -325,7 +354,7 @@ func (p *Pruner) Prune(root common.Hash) error {
 	if err := extractGenesis(p.db, p.stateBloom); err != nil {
 		return err
 	}
-	filterName := bloomFilterName(p.datadir, root)
+	filterName := bloomFilterName(p.config.Datadir, root)
 
 	log.Info("Writing state bloom to disk", "name", filterName)
 	if err := p.stateBloom.Commit(filterName, filterName+stateBloomFileTempSuffix); err != nil {
@@ -0,0 +1,230 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package snapshot
+
+import (
+	"encoding/binary"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/qydata/go-ctereum/common"
+)
+
+// minThrottleDelay is the shortest pause the throttled generator sleeps for,
+// avoiding a timer for every single account or storage slot.
+const minThrottleDelay = 10 * time.Millisecond
+
+// GeneratorStatus is the progress of the snapshot generation.
+type GeneratorStatus struct {
+	Root     common.Hash        // Root of the disk layer
+	Done     bool               // Whether the snapshot is fully generated
+	Marker   []byte             // Position of the generator in the state, nil if done
+	Accounts uint64             // Number of accounts indexed (generated or recovered)
+	Slots    uint64             // Number of storage slots indexed (generated or recovered)
+	Dangling uint64             // Number of dangling storage slots
+	Storage  common.StorageSize // Total account and storage slot size
+	Elapsed  time.Duration      // Time since the generator was (re)started
+	Progress float64            // Estimated fraction of the account space processed
+	ETA      time.Duration      // Estimated time left, zero if unknown
+	Paused   bool               // Whether the generation is paused
+	Rate     uint64             // Maximum snapshot data written per second, 0 if unlimited
+}
+
+// generatorControl tracks the progress of the snapshot generator and allows
+// pausing and throttling it from the outside. A single control is shared by all
+// the disk layers of a tree, so the settings survive the generator restarts
+// caused by flattening diff layers into the disk layer.
+type generatorControl struct {
+	paused bool          // Whether the generation is suspended
+	rate   uint64        // Maximum snapshot data written per second, 0 if unlimited
+	wake   chan struct{} // Closed on every settings change to wake up the generator
+
+	stats  generatorStats // Statistics of the generator at the last report
+	marker []byte         // Position of the generator at the last report
+
+	debt common.StorageSize // Data written beyond the allowed rate
+	seen common.StorageSize // Total data written at the last report
+	last time.Time          // Time of the last report
+
+	lock sync.Mutex
+}
+
+// newGeneratorControl creates a control for an unpaused, unthrottled generator.
+func newGeneratorControl() *generatorControl {
+	return &generatorControl{wake: make(chan struct{})}
+}
+
+// setPaused suspends or resumes the generator.
+func (c *generatorControl) setPaused(paused bool) {
+	if c == nil {
+		return
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.paused = paused
+	c.notify()
+}
+
+// setRate limits the snapshot data written by the generator per second, 0
+// meaning no limit.
+func (c *generatorControl) setRate(rate uint64) {
+	if c == nil {
+		return
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.rate, c.debt = rate, 0
+	c.notify()
+}
+
+// notify wakes up a waiting generator to pick up the changed settings. The
+// lock is assumed to be held.
+func (c *generatorControl) notify() {
+	close(c.wake)
+	c.wake = make(chan struct{})
+}
+
+// report records the progress of the generator.
+func (c *generatorControl) report(stats *generatorStats, marker []byte) {
+	if c == nil {
+		return
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.record(stats, marker)
+}
+
+// record stores the progress of the generator and charges the data written
+// since the last report against the allowed rate. The lock is assumed to be
+// held.
+func (c *generatorControl) record(stats *generatorStats, marker []byte) {
+	c.stats = *stats
+	c.marker = append(c.marker[:0], marker...)
+
+	now := time.Now()
+	if stats.storage > c.seen {
+		c.debt += stats.storage - c.seen
+	}
+	c.seen = stats.storage
+	if !c.last.IsZero() && c.rate > 0 {
+		c.debt -= common.StorageSize(float64(c.rate) * now.Sub(c.last).Seconds())
+	}
+	if c.debt < 0 || c.rate == 0 {
+		c.debt = 0
+	}
+	c.last = now
+}
+
+// wait reports the progress of the generator and blocks it while the generation
+// is paused or runs ahead of the allowed rate. An abort request received in the
+// meantime is returned for the generator to act upon.
+func (c *generatorControl) wait(stats *generatorStats, marker []byte, abort chan chan *generatorStats) chan *generatorStats {
+	if c == nil {
+		return nil
+	}
+	for {
+		c.lock.Lock()
+		c.record(stats, marker)
+
+		var (
+			paused = c.paused
+			wake   = c.wake
+			delay  time.Duration
+		)
+		if c.rate > 0 {
+			delay = time.Duration(float64(c.debt) / float64(c.rate) * float64(time.Second))
+		}
+		c.lock.Unlock()
+
+		if !paused && delay < minThrottleDelay {
+			return nil
+		}
+		var (
+			timer   *time.Timer
+			timeout <-chan time.Time // Never fires while paused
+		)
+		if !paused {
+			timer = time.NewTimer(delay)
+			timeout = timer.C
+		}
+		select {
+		case req := <-abort:
+			if timer != nil {
+				timer.Stop()
+			}
+			return req
+		case <-wake:
+			if timer != nil {
+				timer.Stop()
+			}
+		case <-timeout:
+		}
+		// Don't credit the time spent paused against the rate
+		if paused {
+			c.lock.Lock()
+			c.last = time.Now()
+			c.lock.Unlock()
+		}
+	}
+}
+
+// status returns the progress of the generator running on the given disk
+// layer, or the completion if it's done.
+func (c *generatorControl) status(root common.Hash, marker []byte) *GeneratorStatus {
+	status := &GeneratorStatus{
+		Root: root,
+		Done: marker == nil,
+	}
+	if c == nil {
+		return status
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	status.Paused = c.paused
+	status.Rate = c.rate
+	status.Accounts = c.stats.accounts
+	status.Slots = c.stats.slots
+	status.Dangling = c.stats.dangling
+	status.Storage = c.stats.storage
+	if !c.stats.start.IsZero() {
+		status.Elapsed = time.Since(c.stats.start)
+	}
+	if status.Done {
+		status.Progress = 1
+		return status
+	}
+	// Prefer the live position of the generator over the flushed one
+	if len(c.marker) > 0 {
+		marker = c.marker
+	}
+	status.Marker = common.CopyBytes(marker)
+	if len(marker) >= 8 {
+		pos := binary.BigEndian.Uint64(marker[:8])
+		status.Progress = float64(pos) / math.MaxUint64
+
+		if done := pos - c.stats.origin; pos > c.stats.origin && status.Elapsed > 0 {
+			left := math.MaxUint64 - pos
+			status.ETA = time.Duration(float64(left) / float64(done) * float64(status.Elapsed))
+		}
+	}
+	return status
+}
@@ -62,7 +62,7 @@ var (
 // generateSnapshot regenerates a brand new snapshot based on an existing state
 // database and head block asynchronously. The snapshot is returned immediately
 // and generation is continued in the background until done.
-func generateSnapshot(diskdb ethdb.KeyValueStore, triedb *trie.Database, cache int, root common.Hash) *diskLayer {
+func generateSnapshot(diskdb ethdb.KeyValueStore, triedb *trie.Database, cache int, root common.Hash, ctl *generatorControl) *diskLayer {
 	// Create a new disk layer with an initialized state marker at zero
 	var (
 		stats     = &generatorStats{start: time.Now()}
@@ -82,6 +82,7 @@ func generateSnapshot(diskdb ethdb.KeyValueStore, triedb *trie.Database, cache i
 		genMarker:  genMarker,
 		genPending: make(chan struct{}),
 		genAbort:   make(chan chan *generatorStats),
+		genCtl:     ctl,
 	}
 	go base.generate(stats)
 	log.Debug("Start snapshot generation", "root", root)
@@ -475,6 +476,7 @@ func (dl *diskLayer) checkAndFlush(ctx *generatorContext, current []byte) error
 	select {
 	case abort = <-dl.genAbort:
 	default:
+		abort = dl.genCtl.wait(ctx.stats, current, dl.genAbort)
 	}
 	if ctx.batch.ValueSize() > ethdb.IdealBatchSize || abort != nil {
 		if bytes.Compare(current, dl.genMarker) < 0 {
@@ -711,6 +713,8 @@ func (dl *diskLayer) generate(stats *generatorStats) {
 	log.Info("Generated state snapshot", "accounts", stats.accounts, "slots", stats.slots,
 		"storage", stats.storage, "dangling", stats.dangling, "elapsed", common.PrettyDuration(time.Since(stats.start)))
 
+	dl.genCtl.report(stats, nil)
+
 	dl.lock.Lock()
 	dl.genMarker = nil
 	close(dl.genPending)
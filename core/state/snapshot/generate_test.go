@@ -208,7 +208,7 @@ func (t *testHelper) Commit() common.Hash {
 
 func (t *testHelper) CommitAndGenerate() (common.Hash, *diskLayer) {
 	root := t.Commit()
-	snap := generateSnapshot(t.diskdb, t.triedb, 16, root)
+	snap := generateSnapshot(t.diskdb, t.triedb, 16, root, nil)
 	return root, snap
 }
 
@@ -393,7 +393,7 @@ func TestGenerateCorruptAccountTrie(t *testing.T) {
 	helper.triedb.Commit(root, false, nil)
 	helper.diskdb.Delete(common.HexToHash("0x65145f923027566669a1ae5ccac66f945b55ff6eaeb17d2ea8e048b7d381f2d7").Bytes())
 
-	snap := generateSnapshot(helper.diskdb, helper.triedb, 16, root)
+	snap := generateSnapshot(helper.diskdb, helper.triedb, 16, root, nil)
 	select {
 	case <-snap.genPending:
 		// Snapshot generation succeeded
@@ -428,7 +428,7 @@ func TestGenerateMissingStorageTrie(t *testing.T) {
 	// Delete a storage trie root and ensure the generator chokes
 	helper.diskdb.Delete(stRoot)
 
-	snap := generateSnapshot(helper.diskdb, helper.triedb, 16, root)
+	snap := generateSnapshot(helper.diskdb, helper.triedb, 16, root, nil)
 	select {
 	case <-snap.genPending:
 		// Snapshot generation succeeded
@@ -462,7 +462,7 @@ func TestGenerateCorruptStorageTrie(t *testing.T) {
 	// Delete a storage trie leaf and ensure the generator chokes
 	helper.diskdb.Delete(common.HexToHash("0x18a0f4d79cff4459642dd7604f303886ad9d77c30cf3d7d7cedb3a693ab6d371").Bytes())
 
-	snap := generateSnapshot(helper.diskdb, helper.triedb, 16, root)
+	snap := generateSnapshot(helper.diskdb, helper.triedb, 16, root, nil)
 	select {
 	case <-snap.genPending:
 		// Snapshot generation succeeded
@@ -521,7 +521,7 @@ func TestGenerateWithExtraAccounts(t *testing.T) {
 	if data := rawdb.ReadStorageSnapshot(helper.triedb.DiskDB(), hashData([]byte("acc-2")), hashData([]byte("b-key-1"))); data == nil {
 		t.Fatalf("expected snap storage to exist")
 	}
-	snap := generateSnapshot(helper.diskdb, helper.triedb, 16, root)
+	snap := generateSnapshot(helper.diskdb, helper.triedb, 16, root, nil)
 	select {
 	case <-snap.genPending:
 		// Snapshot generation succeeded
@@ -873,3 +873,51 @@ func TestGenerateBrokenSnapshotWithDanglingStorage(t *testing.T) {
 	snap.genAbort <- stop
 	<-stop
 }
+
+// Tests that a paused snapshot generator makes no progress, can still be
+// aborted, and completes the generation once resumed.
+func TestGeneratePauseResume(t *testing.T) {
+	helper := newHelper()
+	helper.addTrieAccount("acc-1", &Account{Balance: big.NewInt(1), Root: emptyRoot.Bytes(), CodeHash: emptyCode.Bytes()})
+	helper.addTrieAccount("acc-2", &Account{Balance: big.NewInt(2), Root: emptyRoot.Bytes(), CodeHash: emptyCode.Bytes()})
+	helper.addTrieAccount("acc-3", &Account{Balance: big.NewInt(3), Root: emptyRoot.Bytes(), CodeHash: emptyCode.Bytes()})
+	root := helper.Commit()
+
+	ctl := newGeneratorControl()
+	ctl.setPaused(true)
+
+	// Start a paused generator and ensure it can be torn down
+	snap := generateSnapshot(helper.diskdb, helper.triedb, 16, root, ctl)
+	select {
+	case <-snap.genPending:
+		t.Fatalf("Snapshot generated while paused")
+	case <-time.After(200 * time.Millisecond):
+	}
+	if status := ctl.status(snap.root, snap.genMarker); status.Done || !status.Paused {
+		t.Fatalf("Status mismatch: done %v, paused %v", status.Done, status.Paused)
+	}
+	stop := make(chan *generatorStats)
+	snap.genAbort <- stop
+	<-stop
+
+	// Start another paused generator and resume it
+	snap = generateSnapshot(helper.diskdb, helper.triedb, 16, root, ctl)
+	time.Sleep(50 * time.Millisecond)
+	ctl.setPaused(false)
+	select {
+	case <-snap.genPending:
+	case <-time.After(3 * time.Second):
+		t.Fatalf("Snapshot generation failed after resuming")
+	}
+	checkSnapRoot(t, snap, root)
+
+	snap.lock.RLock()
+	status := ctl.status(snap.root, snap.genMarker)
+	snap.lock.RUnlock()
+	if !status.Done || status.Accounts != 3 {
+		t.Fatalf("Status mismatch: done %v, accounts %d", status.Done, status.Accounts)
+	}
+	stop = make(chan *generatorStats)
+	snap.genAbort <- stop
+	<-stop
+}
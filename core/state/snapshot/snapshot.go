@@ -162,6 +162,7 @@ type Tree struct {
 	triedb *trie.Database           // In-memory cache to access the trie through
 	cache  int                      // Megabytes permitted to use for read caches
 	layers map[common.Hash]snapshot // Collection of all known layers
+	genCtl *generatorControl        // Pause and throttle control of the generator
 	lock   sync.RWMutex
 
 	// Test hooks
@@ -190,12 +191,13 @@ func New(diskdb ethdb.KeyValueStore, triedb *trie.Database, cache int, root comm
 		triedb: triedb,
 		cache:  cache,
 		layers: make(map[common.Hash]snapshot),
+		genCtl: newGeneratorControl(),
 	}
 	if !async {
 		defer snap.waitBuild()
 	}
 	// Attempt to load a previously persisted snapshot and rebuild one if failed
-	head, disabled, err := loadSnapshot(diskdb, triedb, cache, root, recovery)
+	head, disabled, err := loadSnapshot(diskdb, triedb, cache, root, recovery, snap.genCtl)
 	if disabled {
 		log.Warn("Snapshot maintenance disabled (syncing)")
 		return snap, nil
@@ -632,6 +634,7 @@ func diffToDisk(bottom *diffLayer) *diskLayer {
 		triedb:     base.triedb,
 		genMarker:  base.genMarker,
 		genPending: base.genPending,
+		genCtl:     base.genCtl,
 	}
 	// If snapshot generation hasn't finished yet, port over all the starts and
 	// continue where the previous round left off.
@@ -727,7 +730,7 @@ func (t *Tree) Rebuild(root common.Hash) {
 	// generator will run a wiper first if there's not one running right now.
 	log.Info("Rebuilding state snapshot")
 	t.layers = map[common.Hash]snapshot{
-		root: generateSnapshot(t.diskdb, t.triedb, t.cache, root),
+		root: generateSnapshot(t.diskdb, t.triedb, t.cache, root, t.genCtl),
 	}
 }
 
@@ -835,6 +838,37 @@ func (t *Tree) generating() (bool, error) {
 	return layer.genMarker != nil, nil
 }
 
+// GeneratorStatus returns the progress of the snapshot generation.
+func (t *Tree) GeneratorStatus() (*GeneratorStatus, error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	layer := t.disklayer()
+	if layer == nil {
+		return nil, errors.New("disk layer is missing")
+	}
+	layer.lock.RLock()
+	defer layer.lock.RUnlock()
+	return t.genCtl.status(layer.root, layer.genMarker), nil
+}
+
+// PauseGeneration suspends the snapshot generation until resumed. The setting
+// applies to any generation started afterwards too.
+func (t *Tree) PauseGeneration() {
+	t.genCtl.setPaused(true)
+}
+
+// ResumeGeneration continues a suspended snapshot generation.
+func (t *Tree) ResumeGeneration() {
+	t.genCtl.setPaused(false)
+}
+
+// SetGenerationRate limits the snapshot data written by the generator per
+// second, trading generation time for disk IO. Zero removes the limit.
+func (t *Tree) SetGenerationRate(rate uint64) {
+	t.genCtl.setRate(rate)
+}
+
 // diskRoot is a external helper function to return the disk layer root.
 func (t *Tree) DiskRoot() common.Hash {
 	t.lock.Lock()
@@ -0,0 +1,148 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package snapshot
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/qydata/go-ctereum/common"
+	"github.com/qydata/go-ctereum/trie"
+)
+
+// VerifyProgress is the position of a chunked verification of the snapshot of a
+// state against its trie, from which the verification can be resumed.
+type VerifyProgress struct {
+	Root     common.Hash `json:"root"`
+	Next     common.Hash `json:"next"` // Hash of the next account to verify
+	Done     bool        `json:"done"`
+	Accounts uint64      `json:"accounts"` // Number of accounts verified
+	Slots    uint64      `json:"slots"`    // Number of storage slots verified
+	Bytes    uint64      `json:"bytes"`    // Size of the snapshot data verified
+}
+
+// VerifyChunk checks the snapshot of a state against its trie, resuming from the
+// progress. Every account of the snapshot is compared with the one of the account
+// trie, and the storage root recomputed from its storage snapshot with the one of
+// the account. The verification stops after the given number of accounts, zero
+// meaning no limit, with the progress updated to resume it later, or at the first
+// inconsistency, which is returned. The throttle, if set, is invoked with the size
+// of the data read after every account, allowing to limit the IO rate.
+func (t *Tree) VerifyChunk(progress *VerifyProgress, limit uint64, throttle func(size int)) error {
+	if progress.Done {
+		return nil
+	}
+	accTrie, err := trie.New(common.Hash{}, progress.Root, t.triedb)
+	if err != nil {
+		return err
+	}
+	trieIt := trie.NewIterator(accTrie.NodeIterator(progress.Next[:]))
+
+	snapIt, err := t.AccountIterator(progress.Root, progress.Next)
+	if err != nil {
+		return err
+	}
+	defer snapIt.Release()
+
+	for verified := uint64(0); limit == 0 || verified < limit; verified++ {
+		inTrie, inSnap := trieIt.Next(), snapIt.Next()
+		if trieIt.Err != nil {
+			return trieIt.Err
+		}
+		if err := snapIt.Error(); err != nil {
+			return err
+		}
+		if !inTrie && !inSnap {
+			progress.Done = true
+			return nil
+		}
+		// Both iterators are sorted by account hash, so the lower one of a
+		// mismatching pair is missing from the other side
+		var (
+			trieHash = common.BytesToHash(trieIt.Key)
+			snapHash = snapIt.Hash()
+		)
+		switch {
+		case !inSnap || (inTrie && bytes.Compare(trieHash[:], snapHash[:]) < 0):
+			return fmt.Errorf("account %x missing from the snapshot", trieHash)
+		case !inTrie || trieHash != snapHash:
+			return fmt.Errorf("account %x missing from the trie", snapHash)
+		}
+		data := snapIt.Account()
+		full, err := FullAccountRLP(data)
+		if err != nil {
+			return fmt.Errorf("invalid snapshot account %x: %v", snapHash, err)
+		}
+		if !bytes.Equal(full, trieIt.Value) {
+			return fmt.Errorf("account %x mismatch: snapshot %x, trie %x", snapHash, full, trieIt.Value)
+		}
+		account, err := FullAccount(data)
+		if err != nil {
+			return fmt.Errorf("invalid snapshot account %x: %v", snapHash, err)
+		}
+		root, slots, size, err := t.storageRoot(progress.Root, snapHash)
+		if err != nil {
+			return err
+		}
+		if want := common.BytesToHash(account.Root); root != want {
+			return fmt.Errorf("storage of account %x mismatch: snapshot root %x, trie root %x", snapHash, root, want)
+		}
+		size += len(data)
+
+		progress.Accounts++
+		progress.Slots += slots
+		progress.Bytes += uint64(size)
+		if throttle != nil {
+			throttle(size)
+		}
+		next := increaseKey(common.CopyBytes(snapHash[:]))
+		if next == nil {
+			progress.Done = true
+			return nil
+		}
+		progress.Next = common.BytesToHash(next)
+	}
+	return nil
+}
+
+// storageRoot recomputes the storage root of the account from the snapshot of
+// the state, returning it along with the number of slots and their size.
+func (t *Tree) storageRoot(root common.Hash, account common.Hash) (common.Hash, uint64, int, error) {
+	it, err := t.StorageIterator(root, account, common.Hash{})
+	if err != nil {
+		return common.Hash{}, 0, 0, err
+	}
+	defer it.Release()
+
+	var (
+		stack = trie.NewStackTrieWithOwner(nil, account)
+		slots uint64
+		size  int
+	)
+	for it.Next() {
+		hash, slot := it.Hash(), it.Slot()
+		if err := stack.TryUpdate(hash[:], slot); err != nil {
+			return common.Hash{}, 0, 0, err
+		}
+		slots++
+		size += common.HashLength + len(slot)
+	}
+	if err := it.Error(); err != nil {
+		return common.Hash{}, 0, 0, err
+	}
+	return stack.Hash(), slots, size, nil
+}
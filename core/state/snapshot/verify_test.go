@@ -0,0 +1,150 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package snapshot
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/VictoriaMetrics/fastcache"
+	"github.com/qydata/go-ctereum/common"
+)
+
+// newVerifyHelper creates a state of a few accounts, with storage for some of
+// them, along with its snapshot. The modifier may alter the snapshot of the
+// account with the given index.
+func newVerifyHelper(modify func(i int, helper *testHelper, key string, acc *Account)) (*Tree, common.Hash) {
+	helper := newHelper()
+	for i := 0; i < 8; i++ {
+		key := fmt.Sprintf("acc-%d", i)
+		acc := &Account{Balance: big.NewInt(int64(i + 1)), Root: emptyRoot.Bytes(), CodeHash: emptyCode.Bytes()}
+		if i%2 == 0 {
+			acc.Root = helper.makeStorageTrie(common.Hash{}, hashData([]byte(key)), []string{"key-1", "key-2"}, []string{"val-1", "val-2"}, true)
+		}
+		helper.addTrieAccount(key, acc)
+		if modify != nil {
+			modify(i, helper, key, acc)
+			continue
+		}
+		helper.addSnapAccount(key, acc)
+		if i%2 == 0 {
+			helper.addSnapStorage(key, []string{"key-1", "key-2"}, []string{"val-1", "val-2"})
+		}
+	}
+	root := helper.Commit()
+	return &Tree{
+		diskdb: helper.diskdb,
+		triedb: helper.triedb,
+		layers: map[common.Hash]snapshot{
+			root: &diskLayer{
+				diskdb: helper.diskdb,
+				triedb: helper.triedb,
+				cache:  fastcache.New(500 * 1024),
+				root:   root,
+			},
+		},
+	}, root
+}
+
+// Tests that a consistent snapshot is verified in chunks, resuming from the
+// progress of the previous chunk.
+func TestVerifyChunk(t *testing.T) {
+	snaps, root := newVerifyHelper(nil)
+
+	var (
+		progress  = &VerifyProgress{Root: root}
+		throttled int
+		chunks    int
+	)
+	for !progress.Done {
+		if err := snaps.VerifyChunk(progress, 3, func(size int) { throttled += size }); err != nil {
+			t.Fatalf("chunk %d: verification failed: %v", chunks, err)
+		}
+		chunks++
+	}
+	if chunks != 3 {
+		t.Errorf("chunk count mismatch: have %d, want 3", chunks)
+	}
+	if progress.Accounts != 8 || progress.Slots != 8 {
+		t.Errorf("verified items mismatch: have %d accounts and %d slots, want 8 and 8", progress.Accounts, progress.Slots)
+	}
+	if progress.Bytes == 0 || uint64(throttled) != progress.Bytes {
+		t.Errorf("throttled size mismatch: throttled %d, verified %d", throttled, progress.Bytes)
+	}
+}
+
+// Tests that the inconsistencies between the snapshot and the trie are detected.
+func TestVerifyChunkInconsistent(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(i int, helper *testHelper, key string, acc *Account)
+		err    string
+	}{
+		{
+			name:   "missing account",
+			modify: snapExcept(3, func(*testHelper, string, *Account) {}),
+			err:    "missing from the snapshot",
+		},
+		{
+			name: "extra account",
+			modify: snapExcept(3, func(helper *testHelper, key string, acc *Account) {
+				helper.addSnapAccount(key, acc)
+				helper.addSnapAccount("acc-extra", acc)
+			}),
+			err: "missing from the trie",
+		},
+		{
+			name: "wrong balance",
+			modify: snapExcept(3, func(helper *testHelper, key string, acc *Account) {
+				helper.addSnapAccount(key, &Account{Balance: big.NewInt(100), Root: acc.Root, CodeHash: acc.CodeHash})
+			}),
+			err: "mismatch: snapshot",
+		},
+		{
+			name: "wrong storage",
+			modify: snapExcept(4, func(helper *testHelper, key string, acc *Account) {
+				helper.addSnapAccount(key, acc)
+				helper.addSnapStorage(key, []string{"key-1", "key-2"}, []string{"val-1", "val-3"})
+			}),
+			err: "storage of account",
+		},
+	}
+	for _, tt := range tests {
+		snaps, root := newVerifyHelper(tt.modify)
+		err := snaps.VerifyChunk(&VerifyProgress{Root: root}, 0, nil)
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%s: error mismatch: have %v, want %q", tt.name, err, tt.err)
+		}
+	}
+}
+
+// snapExcept creates a snapshot modifier writing the consistent snapshot of all
+// accounts but the given one, which is written by the callback instead.
+func snapExcept(index int, write func(helper *testHelper, key string, acc *Account)) func(int, *testHelper, string, *Account) {
+	return func(i int, helper *testHelper, key string, acc *Account) {
+		if i == index {
+			write(helper, key, acc)
+			return
+		}
+		helper.addSnapAccount(key, acc)
+		if i%2 == 0 {
+			helper.addSnapStorage(key, []string{"key-1", "key-2"}, []string{"val-1", "val-2"})
+		}
+	}
+}
@@ -197,6 +197,9 @@ func (s *stateObject) GetCommittedState(db Database, key common.Hash) common.Has
 	if value, cached := s.originStorage[key]; cached {
 		return value
 	}
+	if s.db.witness != nil {
+		s.db.witness.recordSlot(s.address, key)
+	}
 	// If no live objects are available, attempt to use snapshots
 	var (
 		enc []byte
@@ -462,6 +465,9 @@ func (s *stateObject) Code(db Database) []byte {
 	if bytes.Equal(s.CodeHash(), emptyCodeHash) {
 		return nil
 	}
+	if s.db.witness != nil {
+		s.db.witness.recordCode(common.BytesToHash(s.CodeHash()))
+	}
 	code, err := db.ContractCode(s.addrHash, common.BytesToHash(s.CodeHash()))
 	if err != nil {
 		s.setError(fmt.Errorf("can't load code hash %x: %v", s.CodeHash(), err))
@@ -480,6 +486,9 @@ func (s *stateObject) CodeSize(db Database) int {
 	if bytes.Equal(s.CodeHash(), emptyCodeHash) {
 		return 0
 	}
+	if s.db.witness != nil {
+		s.db.witness.recordCode(common.BytesToHash(s.CodeHash()))
+	}
 	size, err := db.ContractCodeSize(s.addrHash, common.BytesToHash(s.CodeHash()))
 	if err != nil {
 		s.setError(fmt.Errorf("can't load code size %x: %v", s.CodeHash(), err))
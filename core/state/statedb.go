@@ -92,6 +92,17 @@ type StateDB struct {
 	// The refund counter, also used by state transitioning.
 	refund uint64
 
+	// The gas of the sponsored transactions and the subsidy owed for them to
+	// the block producer, also used by state transitioning.
+	subsidyPayee  common.Address
+	subsidyGas    uint64
+	subsidyAmount *big.Int
+
+	// The priority fees withheld from the block producer for the engine to
+	// split with the community fund, also used by state transitioning.
+	tipsPayee  common.Address
+	tipsAmount *big.Int
+
 	thash   common.Hash
 	txIndex int
 	logs    map[common.Hash][]*types.Log
@@ -102,6 +113,16 @@ type StateDB struct {
 	// Per-transaction access list
 	accessList *accessList
 
+	// Accounts and storage slots accessed while recording, nil if not recording
+	access *AccessRecord
+
+	// Accounts and storage slots modified while recording diffs, nil if not recording
+	diffs *diffRecorder
+
+	// Accounts, storage slots and codes loaded while recording the execution
+	// witness, nil if not recording
+	witness *witnessRecorder
+
 	// Journal of state modifications. This is the backbone of
 	// Snapshot and RevertToSnapshot.
 	journal        *journal
@@ -218,6 +239,7 @@ func (s *StateDB) Logs() []*types.Log {
 
 // AddPreimage records a SHA3 preimage seen by the VM.
 func (s *StateDB) AddPreimage(hash common.Hash, preimage []byte) {
+	s.recordUnreplayable()
 	if _, ok := s.preimages[hash]; !ok {
 		s.journal.append(addPreimageChange{hash: hash})
 		pi := make([]byte, len(preimage))
@@ -247,21 +269,87 @@ func (s *StateDB) SubRefund(gas uint64) {
 	s.refund -= gas
 }
 
+// AddSubsidy records the gas of a sponsored transaction and the amount owed for
+// it to the block producer.
+func (s *StateDB) AddSubsidy(producer common.Address, gas uint64, amount *big.Int) {
+	s.recordUnreplayable()
+	s.journal.append(subsidyChange{prevPayee: s.subsidyPayee, prevGas: s.subsidyGas, prevAmount: s.subsidyAmount})
+	s.subsidyPayee = producer
+	s.subsidyGas += gas
+
+	// Amounts are replaced rather than updated, so copies can share them
+	total := new(big.Int).Set(amount)
+	if s.subsidyAmount != nil {
+		total.Add(total, s.subsidyAmount)
+	}
+	s.subsidyAmount = total
+}
+
+// Subsidy returns the block producer owed the subsidy of the sponsored
+// transactions applied so far, their gas and the amount owed.
+func (s *StateDB) Subsidy() (common.Address, uint64, *big.Int) {
+	s.recordUnreplayable()
+	if s.subsidyAmount == nil {
+		return s.subsidyPayee, s.subsidyGas, new(big.Int)
+	}
+	return s.subsidyPayee, s.subsidyGas, new(big.Int).Set(s.subsidyAmount)
+}
+
+// ResetSubsidy forgets the subsidy recorded so far, for the state databases
+// carried over from one block to the next.
+func (s *StateDB) ResetSubsidy() {
+	s.subsidyPayee, s.subsidyGas, s.subsidyAmount = common.Address{}, 0, nil
+}
+
+// AddTips records the priority fees of a transaction withheld from the block
+// producer.
+func (s *StateDB) AddTips(producer common.Address, amount *big.Int) {
+	s.recordUnreplayable()
+	s.journal.append(tipsChange{prevPayee: s.tipsPayee, prevAmount: s.tipsAmount})
+	s.tipsPayee = producer
+
+	// Amounts are replaced rather than updated, so copies can share them
+	total := new(big.Int).Set(amount)
+	if s.tipsAmount != nil {
+		total.Add(total, s.tipsAmount)
+	}
+	s.tipsAmount = total
+}
+
+// Tips returns the block producer the priority fees applied so far were
+// withheld from and their amount.
+func (s *StateDB) Tips() (common.Address, *big.Int) {
+	s.recordUnreplayable()
+	if s.tipsAmount == nil {
+		return s.tipsPayee, new(big.Int)
+	}
+	return s.tipsPayee, new(big.Int).Set(s.tipsAmount)
+}
+
+// ResetTips forgets the priority fees withheld so far, for the state databases
+// carried over from one block to the next.
+func (s *StateDB) ResetTips() {
+	s.tipsPayee, s.tipsAmount = common.Address{}, nil
+}
+
 // Exist reports whether the given account address exists in the state.
 // Notably this also returns true for suicided accounts.
 func (s *StateDB) Exist(addr common.Address) bool {
+	s.recordAccountRead(addr)
 	return s.getStateObject(addr) != nil
 }
 
 // Empty returns whether the state object is either non-existent
 // or empty according to the EIP161 specification (balance = nonce = code = 0)
 func (s *StateDB) Empty(addr common.Address) bool {
+	s.recordAccountRead(addr)
 	so := s.getStateObject(addr)
 	return so == nil || so.empty()
 }
 
 // GetBalance retrieves the balance from the given address or 0 if object not found
 func (s *StateDB) GetBalance(addr common.Address) *big.Int {
+	s.recordAccountRead(addr)
 	stateObject := s.getStateObject(addr)
 	if stateObject != nil {
 		return stateObject.Balance()
@@ -270,6 +358,7 @@ func (s *StateDB) GetBalance(addr common.Address) *big.Int {
 }
 
 func (s *StateDB) GetNonce(addr common.Address) uint64 {
+	s.recordAccountRead(addr)
 	stateObject := s.getStateObject(addr)
 	if stateObject != nil {
 		return stateObject.Nonce()
@@ -284,6 +373,7 @@ func (s *StateDB) TxIndex() int {
 }
 
 func (s *StateDB) GetCode(addr common.Address) []byte {
+	s.recordAccountRead(addr)
 	stateObject := s.getStateObject(addr)
 	if stateObject != nil {
 		return stateObject.Code(s.db)
@@ -292,6 +382,7 @@ func (s *StateDB) GetCode(addr common.Address) []byte {
 }
 
 func (s *StateDB) GetCodeSize(addr common.Address) int {
+	s.recordAccountRead(addr)
 	stateObject := s.getStateObject(addr)
 	if stateObject != nil {
 		return stateObject.CodeSize(s.db)
@@ -300,6 +391,7 @@ func (s *StateDB) GetCodeSize(addr common.Address) int {
 }
 
 func (s *StateDB) GetCodeHash(addr common.Address) common.Hash {
+	s.recordAccountRead(addr)
 	stateObject := s.getStateObject(addr)
 	if stateObject == nil {
 		return common.Hash{}
@@ -309,6 +401,7 @@ func (s *StateDB) GetCodeHash(addr common.Address) common.Hash {
 
 // GetState retrieves a value from the given account's storage trie.
 func (s *StateDB) GetState(addr common.Address, hash common.Hash) common.Hash {
+	s.recordSlotRead(addr, hash)
 	stateObject := s.getStateObject(addr)
 	if stateObject != nil {
 		return stateObject.GetState(s.db, hash)
@@ -341,6 +434,7 @@ func (s *StateDB) GetStorageProof(a common.Address, key common.Hash) ([][]byte,
 
 // GetCommittedState retrieves a value from the given account's committed storage trie.
 func (s *StateDB) GetCommittedState(addr common.Address, hash common.Hash) common.Hash {
+	s.recordSlotRead(addr, hash)
 	stateObject := s.getStateObject(addr)
 	if stateObject != nil {
 		return stateObject.GetCommittedState(s.db, hash)
@@ -366,6 +460,7 @@ func (s *StateDB) StorageTrie(addr common.Address) Trie {
 }
 
 func (s *StateDB) HasSuicided(addr common.Address) bool {
+	s.recordAccountRead(addr)
 	stateObject := s.getStateObject(addr)
 	if stateObject != nil {
 		return stateObject.suicided
@@ -379,6 +474,7 @@ func (s *StateDB) HasSuicided(addr common.Address) bool {
 
 // AddBalance adds amount to the account associated with addr.
 func (s *StateDB) AddBalance(addr common.Address, amount *big.Int) {
+	s.recordAccountWrite(addr)
 	stateObject := s.GetOrNewStateObject(addr)
 	if stateObject != nil {
 		stateObject.AddBalance(amount)
@@ -387,6 +483,7 @@ func (s *StateDB) AddBalance(addr common.Address, amount *big.Int) {
 
 // SubBalance subtracts amount from the account associated with addr.
 func (s *StateDB) SubBalance(addr common.Address, amount *big.Int) {
+	s.recordAccountWrite(addr)
 	stateObject := s.GetOrNewStateObject(addr)
 	if stateObject != nil {
 		stateObject.SubBalance(amount)
@@ -394,6 +491,7 @@ func (s *StateDB) SubBalance(addr common.Address, amount *big.Int) {
 }
 
 func (s *StateDB) SetBalance(addr common.Address, amount *big.Int) {
+	s.recordAccountWrite(addr)
 	stateObject := s.GetOrNewStateObject(addr)
 	if stateObject != nil {
 		stateObject.SetBalance(amount)
@@ -401,6 +499,7 @@ func (s *StateDB) SetBalance(addr common.Address, amount *big.Int) {
 }
 
 func (s *StateDB) SetNonce(addr common.Address, nonce uint64) {
+	s.recordAccountWrite(addr)
 	stateObject := s.GetOrNewStateObject(addr)
 	if stateObject != nil {
 		stateObject.SetNonce(nonce)
@@ -408,6 +507,7 @@ func (s *StateDB) SetNonce(addr common.Address, nonce uint64) {
 }
 
 func (s *StateDB) SetCode(addr common.Address, code []byte) {
+	s.recordReset(addr)
 	stateObject := s.GetOrNewStateObject(addr)
 	if stateObject != nil {
 		stateObject.SetCode(crypto.Keccak256Hash(code), code)
@@ -415,6 +515,7 @@ func (s *StateDB) SetCode(addr common.Address, code []byte) {
 }
 
 func (s *StateDB) SetState(addr common.Address, key, value common.Hash) {
+	s.recordSlotWrite(addr, key)
 	stateObject := s.GetOrNewStateObject(addr)
 	if stateObject != nil {
 		stateObject.SetState(s.db, key, value)
@@ -424,6 +525,7 @@ func (s *StateDB) SetState(addr common.Address, key, value common.Hash) {
 // SetStorage replaces the entire storage for the specified account with given
 // storage. This function should only be used for debugging.
 func (s *StateDB) SetStorage(addr common.Address, storage map[common.Hash]common.Hash) {
+	s.recordReset(addr)
 	stateObject := s.GetOrNewStateObject(addr)
 	if stateObject != nil {
 		stateObject.SetStorage(storage)
@@ -436,6 +538,7 @@ func (s *StateDB) SetStorage(addr common.Address, storage map[common.Hash]common
 // The account's state object is still available until the state is committed,
 // getStateObject will return a non-nil account after Suicide.
 func (s *StateDB) Suicide(addr common.Address) bool {
+	s.recordReset(addr)
 	stateObject := s.getStateObject(addr)
 	if stateObject == nil {
 		return false
@@ -508,6 +611,9 @@ func (s *StateDB) getDeletedStateObject(addr common.Address) *stateObject {
 	if obj := s.stateObjects[addr]; obj != nil {
 		return obj
 	}
+	if s.witness != nil {
+		s.witness.recordAccount(addr)
+	}
 	// If no live objects are available, attempt to use snapshots
 	var data *types.StateAccount
 	if s.snap != nil {
@@ -605,6 +711,13 @@ func (s *StateDB) createObject(addr common.Address) (newobj, prev *stateObject)
 //
 // Carrying over the balance ensures that Ether doesn't disappear.
 func (s *StateDB) CreateAccount(addr common.Address) {
+	// Creating a missing account is replayed like any other balance change
+	if s.getStateObject(addr) == nil {
+		s.recordAccountRead(addr)
+		s.recordAccountWrite(addr)
+	} else {
+		s.recordReset(addr)
+	}
 	newObj, prev := s.createObject(addr)
 	if prev != nil {
 		newObj.setBalance(prev.data.Balance)
@@ -652,6 +765,11 @@ func (s *StateDB) Copy() *StateDB {
 		stateObjectsPending: make(map[common.Address]struct{}, len(s.stateObjectsPending)),
 		stateObjectsDirty:   make(map[common.Address]struct{}, len(s.journal.dirties)),
 		refund:              s.refund,
+		subsidyPayee:        s.subsidyPayee,
+		subsidyGas:          s.subsidyGas,
+		subsidyAmount:       s.subsidyAmount,
+		tipsPayee:           s.tipsPayee,
+		tipsAmount:          s.tipsAmount,
 		logs:                make(map[common.Hash][]*types.Log, len(s.logs)),
 		logSize:             s.logSize,
 		preimages:           make(map[common.Hash][]byte, len(s.preimages)),
@@ -707,6 +825,13 @@ func (s *StateDB) Copy() *StateDB {
 	// to not blow up if we ever decide copy it in the middle of a transaction
 	state.accessList = s.accessList.Copy()
 
+	if s.diffs != nil {
+		state.diffs = s.diffs.copy()
+	}
+	if s.witness != nil {
+		state.witness = s.witness.copy()
+	}
+
 	// If there's a prefetcher running, make an inactive copy of it that can
 	// only access data but does not actively preload (since the user will not
 	// know that they need to explicitly terminate an active copy).
@@ -786,6 +911,12 @@ func (s *StateDB) Finalise(deleteEmptyObjects bool) {
 			// Thus, we can safely ignore it here
 			continue
 		}
+		if s.diffs != nil {
+			s.diffs.record(addr, obj.dirtyStorage)
+			if obj.suicided {
+				s.diffs.destructed[addr] = struct{}{}
+			}
+		}
 		if obj.suicided || (deleteEmptyObjects && obj.empty()) {
 			obj.deleted = true
 
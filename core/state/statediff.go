@@ -0,0 +1,162 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"bytes"
+	"math/big"
+	"sort"
+
+	"github.com/qydata/go-ctereum/common"
+)
+
+// AccountState is the state of an account before or after a block.
+type AccountState struct {
+	Nonce    uint64
+	Balance  *big.Int
+	CodeHash common.Hash
+}
+
+// SlotDiff is the change of a storage slot made by a block.
+type SlotDiff struct {
+	Key  common.Hash
+	Prev common.Hash
+	Post common.Hash
+}
+
+// AccountDiff is the change of an account made by a block. The states are nil
+// if the account does not exist before or after the block respectively.
+type AccountDiff struct {
+	Address    common.Address
+	Prev       *AccountState `rlp:"nil"`
+	Post       *AccountState `rlp:"nil"`
+	Destructed bool          // Whether the storage was wiped by a destruction during the block
+	Storage    []SlotDiff    // Changed slots, sorted by key
+}
+
+// StateDiff is the set of changes made to the state by a block, sorted by
+// address.
+type StateDiff struct {
+	Accounts []*AccountDiff
+}
+
+// diffRecorder collects the accounts and slots modified while applying a block.
+type diffRecorder struct {
+	accounts   map[common.Address]map[common.Hash]struct{}
+	destructed map[common.Address]struct{}
+}
+
+func newDiffRecorder() *diffRecorder {
+	return &diffRecorder{
+		accounts:   make(map[common.Address]map[common.Hash]struct{}),
+		destructed: make(map[common.Address]struct{}),
+	}
+}
+
+// record marks the account and the given slots of it as modified.
+func (r *diffRecorder) record(addr common.Address, slots Storage) {
+	keys, ok := r.accounts[addr]
+	if !ok {
+		keys = make(map[common.Hash]struct{})
+		r.accounts[addr] = keys
+	}
+	for key := range slots {
+		keys[key] = struct{}{}
+	}
+}
+
+func (r *diffRecorder) copy() *diffRecorder {
+	cpy := newDiffRecorder()
+	for addr, keys := range r.accounts {
+		cpy.record(addr, nil)
+		for key := range keys {
+			cpy.accounts[addr][key] = struct{}{}
+		}
+	}
+	for addr := range r.destructed {
+		cpy.destructed[addr] = struct{}{}
+	}
+	return cpy
+}
+
+// RecordDiffs starts collecting the accounts and slots modified from now on, to
+// be retrieved by StateDiff once the block is applied.
+func (s *StateDB) RecordDiffs() {
+	s.diffs = newDiffRecorder()
+}
+
+// StateDiff returns the changes made to the state since the recording started,
+// comparing the current values of the modified accounts and slots against the
+// ones in the original state. Unchanged values are left out. It returns nil if
+// the changes are not recorded. The state must be finalised beforehand.
+func (s *StateDB) StateDiff() (*StateDiff, error) {
+	if s.diffs == nil {
+		return nil, nil
+	}
+	prev, err := New(s.originalRoot, s.db, s.snaps)
+	if err != nil {
+		return nil, err
+	}
+	diff := new(StateDiff)
+	for addr, keys := range s.diffs.accounts {
+		_, destructed := s.diffs.destructed[addr]
+		account := &AccountDiff{
+			Address:    addr,
+			Prev:       accountState(prev, addr),
+			Post:       accountState(s, addr),
+			Destructed: destructed,
+		}
+		for key := range keys {
+			slot := SlotDiff{Key: key, Prev: prev.GetState(addr, key), Post: s.GetState(addr, key)}
+			if slot.Prev != slot.Post {
+				account.Storage = append(account.Storage, slot)
+			}
+		}
+		if !destructed && len(account.Storage) == 0 && account.Prev.equal(account.Post) {
+			continue
+		}
+		sort.Slice(account.Storage, func(i, j int) bool {
+			return bytes.Compare(account.Storage[i].Key[:], account.Storage[j].Key[:]) < 0
+		})
+		diff.Accounts = append(diff.Accounts, account)
+	}
+	sort.Slice(diff.Accounts, func(i, j int) bool {
+		return bytes.Compare(diff.Accounts[i].Address[:], diff.Accounts[j].Address[:]) < 0
+	})
+	return diff, nil
+}
+
+// accountState returns the state of the account, or nil if it does not exist.
+func accountState(s *StateDB, addr common.Address) *AccountState {
+	obj := s.getStateObject(addr)
+	if obj == nil {
+		return nil
+	}
+	return &AccountState{
+		Nonce:    obj.Nonce(),
+		Balance:  new(big.Int).Set(obj.Balance()),
+		CodeHash: common.BytesToHash(obj.CodeHash()),
+	}
+}
+
+// equal reports whether both account states are the same, nil ones included.
+func (a *AccountState) equal(b *AccountState) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Nonce == b.Nonce && a.Balance.Cmp(b.Balance) == 0 && a.CodeHash == b.CodeHash
+}
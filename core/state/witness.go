@@ -0,0 +1,158 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"github.com/qydata/go-ctereum/common"
+	"github.com/qydata/go-ctereum/crypto"
+	"github.com/qydata/go-ctereum/ethdb/memorydb"
+)
+
+// Witness is the execution witness of a block: the trie nodes and contract
+// codes of the pre-state needed to execute the block without access to the
+// full state.
+//
+// The trie nodes are the Merkle proofs of every account and storage slot loaded
+// during the execution, which cover the reads and the paths of the writes. A
+// deletion collapsing a branch node additionally needs the sibling of the
+// deleted node to compute the post-state root, which is not included.
+type Witness struct {
+	Root  common.Hash // Root of the pre-state
+	Nodes [][]byte    // RLP encoded trie nodes, sorted by hash
+	Codes [][]byte    // Contract codes, sorted by hash
+}
+
+// witnessRecorder collects the accounts, storage slots and codes loaded from
+// the pre-state while applying a block.
+type witnessRecorder struct {
+	accounts map[common.Address]map[common.Hash]struct{}
+	codes    map[common.Hash]struct{}
+}
+
+func newWitnessRecorder() *witnessRecorder {
+	return &witnessRecorder{
+		accounts: make(map[common.Address]map[common.Hash]struct{}),
+		codes:    make(map[common.Hash]struct{}),
+	}
+}
+
+// recordAccount marks the account as loaded.
+func (r *witnessRecorder) recordAccount(addr common.Address) {
+	if _, ok := r.accounts[addr]; !ok {
+		r.accounts[addr] = make(map[common.Hash]struct{})
+	}
+}
+
+// recordSlot marks the storage slot of the account as loaded.
+func (r *witnessRecorder) recordSlot(addr common.Address, key common.Hash) {
+	r.recordAccount(addr)
+	r.accounts[addr][key] = struct{}{}
+}
+
+// recordCode marks the contract code as loaded.
+func (r *witnessRecorder) recordCode(codeHash common.Hash) {
+	r.codes[codeHash] = struct{}{}
+}
+
+func (r *witnessRecorder) copy() *witnessRecorder {
+	cpy := newWitnessRecorder()
+	for addr, keys := range r.accounts {
+		cpy.recordAccount(addr)
+		for key := range keys {
+			cpy.accounts[addr][key] = struct{}{}
+		}
+	}
+	for hash := range r.codes {
+		cpy.codes[hash] = struct{}{}
+	}
+	return cpy
+}
+
+// RecordWitness starts collecting the accounts, storage slots and codes loaded
+// from now on, to be turned into the execution witness by Witness once the
+// block is applied.
+func (s *StateDB) RecordWitness() {
+	s.witness = newWitnessRecorder()
+}
+
+// RecordingWitness reports whether the state collects an execution witness.
+func (s *StateDB) RecordingWitness() bool {
+	return s.witness != nil
+}
+
+// Witness returns the execution witness of the changes made to the state since
+// the recording started, proving every loaded account and slot against the
+// original state. It returns nil if no witness is recorded.
+func (s *StateDB) Witness() (*Witness, error) {
+	if s.witness == nil {
+		return nil, nil
+	}
+	tr, err := s.db.OpenTrie(s.originalRoot)
+	if err != nil {
+		return nil, err
+	}
+	proofs := memorydb.New()
+	for addr, keys := range s.witness.accounts {
+		if err := tr.Prove(crypto.Keccak256(addr.Bytes()), 0, proofs); err != nil {
+			return nil, fmt.Errorf("failed to prove account %x: %v", addr, err)
+		}
+		if len(keys) == 0 {
+			continue
+		}
+		account, err := tr.TryGetAccount(addr.Bytes())
+		if err != nil {
+			return nil, err
+		}
+		if account == nil || account.Root == emptyRoot {
+			continue
+		}
+		st, err := s.db.OpenStorageTrie(crypto.Keccak256Hash(addr.Bytes()), account.Root)
+		if err != nil {
+			return nil, err
+		}
+		for key := range keys {
+			if err := st.Prove(crypto.Keccak256(key.Bytes()), 0, proofs); err != nil {
+				return nil, fmt.Errorf("failed to prove slot %x of %x: %v", key, addr, err)
+			}
+		}
+	}
+	witness := &Witness{Root: s.originalRoot}
+
+	it := proofs.NewIterator(nil, nil)
+	for it.Next() {
+		witness.Nodes = append(witness.Nodes, common.CopyBytes(it.Value()))
+	}
+	it.Release()
+
+	hashes := make([]common.Hash, 0, len(s.witness.codes))
+	for hash := range s.witness.codes {
+		hashes = append(hashes, hash)
+	}
+	sort.Slice(hashes, func(i, j int) bool { return bytes.Compare(hashes[i][:], hashes[j][:]) < 0 })
+	for _, hash := range hashes {
+		code, err := s.db.ContractCode(common.Hash{}, hash)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load code %x: %v", hash, err)
+		}
+		witness.Codes = append(witness.Codes, code)
+	}
+	return witness, nil
+}
@@ -70,8 +70,15 @@ func (p *StateProcessor) Process(block *types.Block, statedb *state.StateDB, cfg
 	if p.config.DAOForkSupport && p.config.DAOForkBlock != nil && p.config.DAOForkBlock.Cmp(block.Number()) == 0 {
 		misc.ApplyDAOHardFork(statedb)
 	}
+	ApplyStateUpgrades(p.config, blockNumber, statedb)
 	blockContext := NewEVMBlockContext(header, p.bc, nil)
 	vmenv := vm.NewEVM(blockContext, vm.TxContext{}, statedb, p.config, cfg)
+	// Execute the transactions speculatively in parallel if enabled, only the
+	// results not depending on earlier transactions are kept
+	var parallel *parallelExecution
+	if p.parallelizable(block, statedb, cfg) {
+		parallel = p.speculate(block, statedb, cfg)
+	}
 	// Iterate over and process the individual transactions
 	for i, tx := range block.Transactions() {
 		msg, err := tx.AsMessage(types.MakeSigner(p.config, header.Number), header.BaseFee)
@@ -79,7 +86,12 @@ func (p *StateProcessor) Process(block *types.Block, statedb *state.StateDB, cfg
 			return nil, nil, 0, fmt.Errorf("could not apply tx %d [%v]: %w", i, tx.Hash().Hex(), err)
 		}
 		statedb.Prepare(tx.Hash(), i)
-		receipt, err := applyTransaction(msg, p.config, nil, gp, statedb, blockNumber, blockHash, tx, usedGas, vmenv)
+		var receipt *types.Receipt
+		if parallel != nil {
+			receipt, err = parallel.apply(i, msg, p.config, gp, statedb, blockNumber, blockHash, tx, usedGas, vmenv)
+		} else {
+			receipt, err = applyTransaction(msg, p.config, nil, gp, statedb, blockNumber, blockHash, tx, usedGas, vmenv)
+		}
 		if err != nil {
 			return nil, nil, 0, fmt.Errorf("could not apply tx %d [%v]: %w", i, tx.Hash().Hex(), err)
 		}
@@ -87,7 +99,11 @@ func (p *StateProcessor) Process(block *types.Block, statedb *state.StateDB, cfg
 		allLogs = append(allLogs, receipt.Logs...)
 	}
 	// Finalize the block, applying any consensus engine specific extras (e.g. block rewards)
-	p.engine.Finalize(p.bc, header, statedb, block.Transactions(), block.Uncles())
+	if engine, ok := p.engine.(consensus.TracingFinalizer); ok && cfg.Debug && cfg.Tracer != nil {
+		engine.FinalizeWithTracer(p.bc, header, statedb, block.Transactions(), block.Uncles(), cfg.Tracer)
+	} else {
+		p.engine.Finalize(p.bc, header, statedb, block.Transactions(), block.Uncles())
+	}
 
 	return receipts, allLogs, *usedGas, nil
 }
@@ -111,10 +127,13 @@ func applyTransaction(msg types.Message, config *params.ChainConfig, author *com
 		root = statedb.IntermediateRoot(config.IsEIP158(blockNumber)).Bytes()
 	}
 	*usedGas += result.UsedGas
+	return newReceipt(msg, result, root, statedb, blockNumber, blockHash, tx, *usedGas, evm.TxContext.Origin), nil
+}
 
-	// Create a new receipt for the transaction, storing the intermediate root and gas used
-	// by the tx.
-	receipt := &types.Receipt{Type: tx.Type(), PostState: root, CumulativeGasUsed: *usedGas}
+// newReceipt creates the receipt of an applied transaction, storing the
+// intermediate root and gas used by the tx.
+func newReceipt(msg types.Message, result *ExecutionResult, root []byte, statedb *state.StateDB, blockNumber *big.Int, blockHash common.Hash, tx *types.Transaction, usedGas uint64, origin common.Address) *types.Receipt {
+	receipt := &types.Receipt{Type: tx.Type(), PostState: root, CumulativeGasUsed: usedGas}
 	if result.Failed() {
 		receipt.Status = types.ReceiptStatusFailed
 	} else {
@@ -125,7 +144,7 @@ func applyTransaction(msg types.Message, config *params.ChainConfig, author *com
 
 	// If the transaction created a contract, store the creation address in the receipt.
 	if msg.To() == nil {
-		receipt.ContractAddress = crypto.CreateAddress(evm.TxContext.Origin, tx.Nonce())
+		receipt.ContractAddress = crypto.CreateAddress(origin, tx.Nonce())
 	}
 
 	// Set the receipt logs and create the bloom filter.
@@ -134,7 +153,7 @@ func applyTransaction(msg types.Message, config *params.ChainConfig, author *com
 	receipt.BlockHash = blockHash
 	receipt.BlockNumber = blockNumber
 	receipt.TransactionIndex = uint(statedb.TxIndex())
-	return receipt, err
+	return receipt
 }
 
 // ApplyTransaction attempts to apply a transaction to the given state database
@@ -0,0 +1,192 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math/big"
+	"runtime"
+	"sync"
+
+	"github.com/qydata/go-ctereum/common"
+	"github.com/qydata/go-ctereum/core/state"
+	"github.com/qydata/go-ctereum/core/types"
+	"github.com/qydata/go-ctereum/core/vm"
+	"github.com/qydata/go-ctereum/metrics"
+	"github.com/qydata/go-ctereum/params"
+)
+
+var (
+	parallelMergedMeter   = metrics.NewRegisteredMeter("chain/parallel/merged", nil)
+	parallelFallbackMeter = metrics.NewRegisteredMeter("chain/parallel/fallback", nil)
+)
+
+// speculation is the outcome of executing a transaction on top of the state
+// before the block, ignoring the transactions preceding it.
+type speculation struct {
+	state  *state.StateDB      // Pre-block state with the transaction applied
+	access *state.AccessRecord // Accounts and slots accessed by the transaction
+	result *ExecutionResult
+	err    error
+}
+
+// parallelExecution applies the transactions of a block from their speculative
+// executions where possible. A speculative result is kept if the transaction
+// did not access anything written by the transactions preceding it, otherwise
+// the transaction is re-executed serially.
+type parallelExecution struct {
+	pre    *state.StateDB      // State before the transactions of the block
+	specs  []*speculation      // Speculative executions, by transaction index
+	writes *state.AccessRecord // Writes of the transactions applied so far
+}
+
+// parallelizable reports whether the transactions of the block may be executed
+// speculatively. Blocks needing per-transaction intermediate roots or block
+// level accounting of the sponsored gas, as well as traced executions and
+// executions recording a witness, are always processed serially.
+func (p *StateProcessor) parallelizable(block *types.Block, statedb *state.StateDB, cfg vm.Config) bool {
+	if p.bc == nil || !p.bc.cacheConfig.ParallelEVM || len(block.Transactions()) < 2 {
+		return false
+	}
+	if statedb.RecordingWitness() {
+		return false
+	}
+	if cfg.Debug || cfg.EnablePreimageRecording {
+		return false
+	}
+	return p.config.IsByzantium(block.Number()) && !p.config.IsSubsidy(block.Number())
+}
+
+// speculate executes every transaction of the block concurrently, each on its
+// own copy of the given state.
+func (p *StateProcessor) speculate(block *types.Block, statedb *state.StateDB, cfg vm.Config) *parallelExecution {
+	var (
+		header = block.Header()
+		txs    = block.Transactions()
+		signer = types.MakeSigner(p.config, header.Number)
+		pe     = &parallelExecution{
+			pre:    statedb.Copy(),
+			specs:  make([]*speculation, len(txs)),
+			writes: state.NewAccessRecord(),
+		}
+	)
+	// Copying is not safe against concurrent use of the source, do it upfront
+	for i := range txs {
+		spec := &speculation{state: statedb.Copy(), access: state.NewAccessRecord()}
+		spec.state.StopPrefetcher()
+		pe.specs[i] = spec
+	}
+	workers := runtime.NumCPU()
+	if workers > len(txs) {
+		workers = len(txs)
+	}
+	var (
+		tasks = make(chan int, len(txs))
+		wg    sync.WaitGroup
+	)
+	for i := range txs {
+		tasks <- i
+	}
+	close(tasks)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range tasks {
+				spec := pe.specs[i]
+				msg, err := txs[i].AsMessage(signer, header.BaseFee)
+				if err != nil {
+					spec.err = err
+					continue
+				}
+				spec.state.RecordAccess(spec.access)
+				spec.state.Prepare(txs[i].Hash(), i)
+
+				evm := vm.NewEVM(NewEVMBlockContext(header, p.bc, nil), NewEVMTxContext(msg), spec.state, p.config, cfg)
+				spec.result, spec.err = ApplyMessage(evm, msg, new(GasPool).AddGas(header.GasLimit))
+				spec.state.RecordAccess(nil)
+				if spec.err == nil {
+					spec.state.Finalise(true)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	return pe
+}
+
+// apply applies the i-th transaction of the block to the state, carrying over
+// its speculative result if valid or executing it serially otherwise.
+func (pe *parallelExecution) apply(i int, msg types.Message, config *params.ChainConfig, gp *GasPool, statedb *state.StateDB, blockNumber *big.Int, blockHash common.Hash, tx *types.Transaction, usedGas *uint64, evm *vm.EVM) (*types.Receipt, error) {
+	spec := pe.specs[i]
+	pe.specs[i] = nil // Release the state copy once applied
+
+	if spec.err != nil || !spec.access.Replayable || spec.access.Conflicts(pe.writes) || gp.Gas() < msg.Gas() {
+		parallelFallbackMeter.Mark(1)
+
+		record := state.NewAccessRecord()
+		statedb.RecordAccess(record)
+		receipt, err := applyTransaction(msg, config, nil, gp, statedb, blockNumber, blockHash, tx, usedGas, evm)
+		statedb.RecordAccess(nil)
+
+		pe.writes.MergeWrites(record)
+		return receipt, err
+	}
+	parallelMergedMeter.Mark(1)
+
+	// Balances are carried over as deltas, as transactions may credit accounts
+	// like the coinbase without reading them. Everything else read before is
+	// known to be unchanged, so the speculative values are final.
+	//
+	// Writes of reverted calls are recorded too, so unchanged balances are only
+	// replayed to touch the empty accounts the transaction deleted. Touching any
+	// other empty account would delete it unlike the serial execution.
+	for addr := range spec.access.AccountWrites {
+		delta := new(big.Int).Sub(spec.state.GetBalance(addr), pe.pre.GetBalance(addr))
+		switch {
+		case delta.Sign() > 0:
+			statedb.AddBalance(addr, delta)
+		case delta.Sign() < 0:
+			statedb.SubBalance(addr, delta.Neg(delta))
+		case pe.pre.Exist(addr) && !spec.state.Exist(addr):
+			statedb.AddBalance(addr, delta)
+		}
+		if nonce := spec.state.GetNonce(addr); nonce != pe.pre.GetNonce(addr) {
+			statedb.SetNonce(addr, nonce)
+		}
+	}
+	for addr, keys := range spec.access.SlotWrites {
+		for key := range keys {
+			statedb.SetState(addr, key, spec.state.GetState(addr, key))
+		}
+	}
+	for _, l := range spec.state.GetLogs(tx.Hash(), blockHash) {
+		statedb.AddLog(&types.Log{
+			Address:     l.Address,
+			Topics:      l.Topics,
+			Data:        l.Data,
+			BlockNumber: l.BlockNumber,
+		})
+	}
+	if err := gp.SubGas(spec.result.UsedGas); err != nil {
+		return nil, err
+	}
+	statedb.Finalise(true)
+	pe.writes.MergeWrites(spec.access)
+
+	*usedGas += spec.result.UsedGas
+	return newReceipt(msg, spec.result, nil, statedb, blockNumber, blockHash, tx, *usedGas, msg.From()), nil
+}
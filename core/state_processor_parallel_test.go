@@ -0,0 +1,157 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/qydata/go-ctereum/common"
+	"github.com/qydata/go-ctereum/consensus/ethash"
+	"github.com/qydata/go-ctereum/core/rawdb"
+	"github.com/qydata/go-ctereum/core/types"
+	"github.com/qydata/go-ctereum/core/vm"
+	"github.com/qydata/go-ctereum/crypto"
+	"github.com/qydata/go-ctereum/params"
+)
+
+// Tests that blocks processed with speculative parallel execution end up in the
+// same state and with the same receipts as when processed serially, for both
+// independent and conflicting transactions. The state roots are checked by the
+// import of the serially generated blocks.
+func TestParallelProcessing(t *testing.T) {
+	var (
+		// The counter contract increments slot 0 on every call
+		counter = common.HexToAddress("0x000000000000000000000000000000000000c0c0")
+		code    = []byte{
+			byte(vm.PUSH1), 0x00, byte(vm.SLOAD),
+			byte(vm.PUSH1), 0x01, byte(vm.ADD),
+			byte(vm.PUSH1), 0x00, byte(vm.SSTORE),
+			byte(vm.STOP),
+		}
+		// Empty accounts existing before the block, credited by a reverted call
+		// and by a zero value transfer respectively
+		reverted = common.HexToAddress("0x000000000000000000000000000000000000e0e0")
+		touched  = common.HexToAddress("0x000000000000000000000000000000000000e1e1")
+
+		// The reverter contract credits the first empty account and reverts
+		reverter = common.HexToAddress("0x000000000000000000000000000000000000c1c1")
+		revert   = append(append([]byte{
+			byte(vm.PUSH1), 0x00, byte(vm.PUSH1), 0x00, byte(vm.PUSH1), 0x00, byte(vm.PUSH1), 0x00,
+			byte(vm.PUSH1), 0x01, byte(vm.PUSH20)}, reverted.Bytes()...),
+			byte(vm.GAS), byte(vm.CALL),
+			byte(vm.PUSH1), 0x00, byte(vm.PUSH1), 0x00, byte(vm.REVERT),
+		)
+		engine = ethash.NewFaker()
+		db     = rawdb.NewMemoryDatabase()
+		funds  = big.NewInt(params.Ether)
+		keys   = make([]*ecdsa.PrivateKey, 4)
+		alloc  = GenesisAlloc{
+			counter:  {Code: code, Balance: new(big.Int)},
+			reverter: {Code: revert, Balance: big.NewInt(1)},
+			reverted: {Balance: new(big.Int)},
+			touched:  {Balance: new(big.Int)},
+		}
+	)
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey()
+		alloc[crypto.PubkeyToAddress(keys[i].PublicKey)] = GenesisAccount{Balance: funds}
+	}
+	gspec := &Genesis{Config: params.TestChainConfig, Alloc: alloc}
+	genesis := gspec.MustCommit(db)
+
+	var (
+		signer = types.LatestSigner(gspec.Config)
+		nonces = make([]uint64, len(keys))
+	)
+	transfer := func(b *BlockGen, from int, to common.Address, value int64, gas uint64) {
+		tx, _ := types.SignTx(types.NewTransaction(nonces[from], to, big.NewInt(value), gas, b.header.BaseFee, nil), signer, keys[from])
+		nonces[from]++
+		b.AddTx(tx)
+	}
+	send := func(b *BlockGen, from int, to common.Address, gas uint64) {
+		transfer(b, from, to, 1000, gas)
+	}
+	blocks, _ := GenerateChain(gspec.Config, genesis, engine, db, 4, func(i int, b *BlockGen) {
+		b.SetCoinbase(common.Address{0x01})
+		switch i {
+		case 0:
+			// Independent transfers to fresh accounts
+			for j := range keys {
+				send(b, j, common.Address{0x10, byte(j)}, params.TxGas)
+			}
+		case 1:
+			// Transfers from the same sender and to accounts sending later
+			send(b, 0, common.Address{0x20}, params.TxGas)
+			send(b, 0, common.Address{0x21}, params.TxGas)
+			send(b, 1, crypto.PubkeyToAddress(keys[2].PublicKey), params.TxGas)
+			send(b, 2, common.Address{0x22}, params.TxGas)
+		case 2:
+			// Calls conflicting on the storage of the counter
+			for j := range keys {
+				send(b, j, counter, 50000)
+			}
+		case 3:
+			// Reverted call crediting an empty account and a zero value
+			// transfer touching another one
+			transfer(b, 0, reverter, 0, 100000)
+			transfer(b, 1, touched, 0, params.TxGas)
+		}
+	})
+	process := func(parallel bool) *BlockChain {
+		diskdb := rawdb.NewMemoryDatabase()
+		gspec.MustCommit(diskdb)
+
+		cacheConfig := *defaultCacheConfig
+		cacheConfig.ParallelEVM = parallel
+		chain, err := NewBlockChain(diskdb, &cacheConfig, gspec.Config, engine, vm.Config{}, nil, nil)
+		if err != nil {
+			t.Fatalf("failed to create tester chain: %v", err)
+		}
+		if n, err := chain.InsertChain(blocks); err != nil {
+			t.Fatalf("parallel %v, block %d: failed to insert into chain: %v", parallel, n, err)
+		}
+		return chain
+	}
+	serial, parallel := process(false), process(true)
+	defer serial.Stop()
+	defer parallel.Stop()
+
+	for _, block := range blocks {
+		want := serial.GetReceiptsByHash(block.Hash())
+		have := parallel.GetReceiptsByHash(block.Hash())
+		if len(have) != len(want) {
+			t.Fatalf("block %d: receipt count mismatch: have %d, want %d", block.NumberU64(), len(have), len(want))
+		}
+		for i := range want {
+			if have[i].Status != want[i].Status || have[i].CumulativeGasUsed != want[i].CumulativeGasUsed || have[i].Bloom != want[i].Bloom {
+				t.Errorf("block %d: receipt %d mismatch: have %+v, want %+v", block.NumberU64(), i, have[i], want[i])
+			}
+		}
+	}
+	state, _ := parallel.State()
+	if have := state.GetState(counter, common.Hash{}); have != common.BigToHash(big.NewInt(int64(len(keys)))) {
+		t.Errorf("counter mismatch: have %x, want %d", have, len(keys))
+	}
+	if !state.Exist(reverted) {
+		t.Errorf("empty account credited by a reverted call deleted")
+	}
+	if state.Exist(touched) {
+		t.Errorf("empty account touched by a transfer not deleted")
+	}
+}
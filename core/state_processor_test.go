@@ -27,6 +27,7 @@ import (
 	"github.com/qydata/go-ctereum/consensus/ethash"
 	"github.com/qydata/go-ctereum/consensus/misc"
 	"github.com/qydata/go-ctereum/core/rawdb"
+	"github.com/qydata/go-ctereum/core/state"
 	"github.com/qydata/go-ctereum/core/types"
 	"github.com/qydata/go-ctereum/core/vm"
 	"github.com/qydata/go-ctereum/crypto"
@@ -299,6 +300,85 @@ func TestStateProcessorErrors(t *testing.T) {
 	}
 }
 
+// Tests that the transactions of authenticated senders priced below the subsidy
+// threshold are charged their own price, paid to the coinbase, and record the
+// difference up to the threshold as owed to the coinbase, within the sponsored
+// gas limit of the block.
+func TestSubsidySponsorship(t *testing.T) {
+	var (
+		controller  = common.HexToAddress("0x000000000000000000000000000000000000a0a0")
+		coinbase    = common.HexToAddress("0x000000000000000000000000000000000000c0c0")
+		authKey, _  = crypto.GenerateKey()
+		otherKey, _ = crypto.GenerateKey()
+		threshold   = big.NewInt(10 * params.GWei)
+		config      = *params.TestChainConfig
+	)
+	config.LondonBlock = nil
+	config.AuthContract = controller
+	config.Subsidy = &params.SubsidyConfig{Block: common.Big0, GasPrice: threshold, BlockGasLimit: 2 * params.TxGas}
+
+	for i, tt := range []struct {
+		key       *ecdsa.PrivateKey
+		price     *big.Int
+		sponsored uint64 // Gas sponsored earlier in the block
+		owed      bool
+	}{
+		{authKey, big.NewInt(params.GWei), 0, true},
+		{authKey, big.NewInt(params.GWei), params.TxGas, true},
+		{authKey, big.NewInt(params.GWei), params.TxGas + 1, false},
+		{authKey, threshold, 0, false},
+		{otherKey, big.NewInt(params.GWei), 0, false},
+	} {
+		sender := crypto.PubkeyToAddress(tt.key.PublicKey)
+		statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+		statedb.SetBalance(sender, big.NewInt(params.Ether))
+		// authsSingle(addr) returning the storage slot keyed by addr
+		statedb.SetCode(controller, []byte{
+			byte(vm.PUSH1), 0x04, byte(vm.CALLDATALOAD), byte(vm.SLOAD),
+			byte(vm.PUSH1), 0x00, byte(vm.MSTORE),
+			byte(vm.PUSH1), 0x20, byte(vm.PUSH1), 0x00, byte(vm.RETURN),
+		})
+		statedb.SetState(controller, common.BytesToHash(crypto.PubkeyToAddress(authKey.PublicKey).Bytes()), common.BigToHash(common.Big1))
+		if tt.sponsored > 0 {
+			statedb.AddSubsidy(coinbase, tt.sponsored, new(big.Int))
+		}
+		header := &types.Header{
+			Number:     big.NewInt(1),
+			GasLimit:   params.GenesisGasLimit,
+			Difficulty: common.Big1,
+			Coinbase:   coinbase,
+		}
+		tx := types.MustSignNewTx(tt.key, types.LatestSigner(&config), &types.LegacyTx{
+			Gas:      params.TxGas,
+			GasPrice: tt.price,
+			To:       &common.Address{0x01},
+			Value:    new(big.Int),
+		})
+		if _, err := ApplyTransaction(&config, nil, &coinbase, new(GasPool).AddGas(header.GasLimit), statedb, header, tx, new(uint64), vm.Config{}); err != nil {
+			t.Fatalf("test %d: failed to apply transaction: %v", i, err)
+		}
+		fee := new(big.Int).Mul(tt.price, new(big.Int).SetUint64(params.TxGas))
+		if have, want := statedb.GetBalance(sender), new(big.Int).Sub(big.NewInt(params.Ether), fee); have.Cmp(want) != 0 {
+			t.Errorf("test %d: sender balance mismatch: have %v, want %v", i, have, want)
+		}
+		if have := statedb.GetBalance(coinbase); have.Cmp(fee) != 0 {
+			t.Errorf("test %d: coinbase balance mismatch: have %v, want %v", i, have, fee)
+		}
+		producer, gas, amount := statedb.Subsidy()
+		wantGas, wantAmount := tt.sponsored, new(big.Int)
+		if tt.owed {
+			wantGas += params.TxGas
+			wantAmount = config.Subsidy.Reimbursement(params.TxGas, tt.price)
+		}
+		if gas != wantGas || amount.Cmp(wantAmount) != 0 {
+			t.Errorf("test %d: subsidy mismatch: have %d/%v, want %d/%v", i, gas, amount, wantGas, wantAmount)
+		}
+		if gas > 0 && producer != coinbase {
+			t.Errorf("test %d: subsidy payee mismatch: have %x, want %x", i, producer, coinbase)
+		}
+	}
+}
+
 // GenerateBadBlock constructs a "block" which contains the transactions. The transactions are not expected to be
 // valid, and no proper post-state can be made. But from the perspective of the blockchain, the block is sufficiently
 // valid to be considered for import:
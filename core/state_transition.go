@@ -79,6 +79,7 @@ type Message interface {
 	IsFake() bool
 	Data() []byte
 	AccessList() types.AccessList
+	AuthProof() *types.AuthProof
 }
 
 // ExecutionResult includes all output after executing given evm
@@ -234,6 +235,19 @@ func (st *StateTransition) preCheck() error {
 				st.msg.From().Hex(), codeHash)
 		}
 	}
+	// Make sure the auth proof of authenticated transactions holds
+	if proof := st.msg.AuthProof(); proof != nil {
+		if err := VerifyAuthProof(st.evm.ChainConfig(), st.state, st.evm.Context.BlockNumber, st.evm.Context.Time.Uint64(), st.msg.From(), proof); err != nil {
+			return err
+		}
+		if _, isAuth := st.evm.IsAuth(st.msg.From()); !isAuth {
+			return fmt.Errorf("%w: address %v", ErrAuthProofRevoked, st.msg.From().Hex())
+		}
+	}
+	// Make sure the sender is allowed to deploy contracts
+	if st.msg.To() == nil && st.evm.ChainConfig().IsDeployAuth(st.evm.Context.BlockNumber) && !st.evm.CanDeploy(st.msg.From()) {
+		return fmt.Errorf("%w: address %v", ErrDeployNotAuthorized, st.msg.From().Hex())
+	}
 	// Make sure that transaction gasFeeCap is greater than the baseFee (post london)
 	if st.evm.ChainConfig().IsLondon(st.evm.Context.BlockNumber) {
 		// Skip the checks if gas fields are zero and baseFee was explicitly disabled (eth_call)
@@ -367,7 +381,15 @@ func (st *StateTransition) TransitionDb() (*ExecutionResult, error) {
 	} else {
 		fee := new(big.Int).SetUint64(st.gasUsed())
 		fee.Mul(fee, effectiveTip)
-		st.state.AddBalance(st.evm.Context.Coinbase, fee)
+		if st.evm.ChainConfig().IsCommunityFund(st.evm.Context.BlockNumber) {
+			// Withheld for the engine to split with the community fund
+			st.state.AddTips(st.evm.Context.Coinbase, fee)
+		} else {
+			st.state.AddBalance(st.evm.Context.Coinbase, fee)
+		}
+	}
+	if config := st.evm.ChainConfig(); config.IsSubsidy(st.evm.Context.BlockNumber) && !msg.IsFake() {
+		st.sponsor(config.Subsidy)
 	}
 
 	return &ExecutionResult{
@@ -377,6 +399,23 @@ func (st *StateTransition) TransitionDb() (*ExecutionResult, error) {
 	}, nil
 }
 
+// sponsor records the subsidy owed to the block producer if the transaction is
+// priced below the subsidy threshold and sent by an authenticated address, as
+// long as the sponsored gas of the block stays within the limit.
+func (st *StateTransition) sponsor(config *params.SubsidyConfig) {
+	if st.gasPrice.Cmp(config.GasPrice) >= 0 {
+		return
+	}
+	_, sponsored, _ := st.state.Subsidy()
+	if sponsored+st.gasUsed() > config.BlockGasLimit {
+		return
+	}
+	if _, authenticated := st.evm.IsAuth(st.msg.From()); !authenticated {
+		return
+	}
+	st.state.AddSubsidy(st.evm.Context.Coinbase, st.gasUsed(), config.Reimbursement(st.gasUsed(), st.gasPrice))
+}
+
 func (st *StateTransition) refundGas(refundQuotient uint64) {
 	// Apply refund counter, capped to a refund quotient
 	refund := st.gasUsed() / refundQuotient
@@ -0,0 +1,34 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math/big"
+
+	"github.com/qydata/go-ctereum/consensus/misc"
+	"github.com/qydata/go-ctereum/core/state"
+	"github.com/qydata/go-ctereum/params"
+)
+
+// ApplyStateUpgrades patches the state according to the state upgrades the
+// chain config schedules for the given block. It is applied on top of the
+// parent state, before any transaction of the block, the same way by every
+// consensus engine. The upgrades scheduled at the end of the block are left to
+// the engine finalization.
+func ApplyStateUpgrades(config *params.ChainConfig, number *big.Int, statedb *state.StateDB) {
+	misc.ApplyUpgradeActions(number, config.StateUpgradesAt(number), statedb)
+}
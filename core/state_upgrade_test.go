@@ -0,0 +1,114 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/qydata/go-ctereum/common"
+	"github.com/qydata/go-ctereum/consensus/ethash"
+	"github.com/qydata/go-ctereum/consensus/misc"
+	"github.com/qydata/go-ctereum/core/rawdb"
+	"github.com/qydata/go-ctereum/core/state"
+	"github.com/qydata/go-ctereum/core/types"
+	"github.com/qydata/go-ctereum/core/vm"
+	"github.com/qydata/go-ctereum/params"
+)
+
+// Tests that the scheduled state upgrades are applied by both the chain maker
+// and the block processor, so that the generated blocks are accepted.
+func TestStateUpgrades(t *testing.T) {
+	var (
+		target = common.HexToAddress("0x000000000000000000000000000000000000c0de")
+		code   = []byte{0x60, 0x00, 0x60, 0x00, 0xf3}
+		slot   = common.HexToHash("0x01")
+		value  = common.HexToHash("0x02")
+
+		engine = ethash.NewFaker()
+		db     = rawdb.NewMemoryDatabase()
+		config = *params.AllEthashProtocolChanges
+	)
+	config.StateUpgrades = map[uint64][]params.UpgradeAction{
+		2: {{
+			Address:    target,
+			Code:       code,
+			Storage:    map[common.Hash]common.Hash{slot: value},
+			AddBalance: big.NewInt(params.Ether),
+		}},
+	}
+	gspec := &Genesis{Config: &config}
+	genesis := gspec.MustCommit(db)
+	blocks, _ := GenerateChain(gspec.Config, genesis, engine, db, 3, nil)
+
+	diskdb := rawdb.NewMemoryDatabase()
+	gspec.MustCommit(diskdb)
+	chain, err := NewBlockChain(diskdb, nil, gspec.Config, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if n, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("block %d: failed to insert into chain: %v", n, err)
+	}
+	before, _ := chain.StateAt(chain.GetBlockByNumber(1).Root())
+	if before.GetCodeSize(target) != 0 {
+		t.Fatalf("upgrade applied before its block")
+	}
+	state, _ := chain.State()
+	if have := state.GetCode(target); !bytes.Equal(have, code) {
+		t.Errorf("code mismatch: have %x, want %x", have, code)
+	}
+	if have := state.GetState(target, slot); have != value {
+		t.Errorf("storage mismatch: have %x, want %x", have, value)
+	}
+	if have := state.GetBalance(target); have.Cmp(big.NewInt(params.Ether)) != 0 {
+		t.Errorf("balance mismatch: have %v, want %v", have, params.Ether)
+	}
+}
+
+// Tests that the main network validator contract patches, moved from the clique
+// engine into the config, produce the same state as the engine did.
+func TestMainnetStateUpgrades(t *testing.T) {
+	tests := []struct {
+		number uint64
+		code   common.Hash // Hash of the validator contract code set by the engine
+		root   common.Hash // Root of an empty state patched by the engine
+	}{
+		{5033499, common.HexToHash("0xb91b7308171945b3d45e1d48b0d480109881cd49d9469c002b8678a4cf3f526d"), common.HexToHash("0x147b5c0c93b365907052639d5e9f2b1978502f7faf09653c7e57656d057b7905")},
+		{5185000, common.HexToHash("0x6652298f02858120b4e02ee08ac6eff1f6232f4d805149f7ec9c65aafd3725ea"), common.HexToHash("0x294c051e8fe5600abb1789ddd91870a50a13a0da764ede67ff77f8bf4cc118ee")},
+	}
+	validator := common.HexToAddress(params.MainnetChainConfig.Clique.ValidatorContract)
+	for _, tt := range tests {
+		number := new(big.Int).SetUint64(tt.number)
+		statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+
+		ApplyStateUpgrades(params.MainnetChainConfig, number, statedb)
+		if root := statedb.IntermediateRoot(true); root != types.EmptyRootHash {
+			t.Errorf("block %d: patched at the start of the block", tt.number)
+		}
+		misc.ApplyUpgradeActions(number, params.MainnetChainConfig.FinalizeStateUpgradesAt(number), statedb)
+		if have := statedb.GetCodeHash(validator); have != tt.code {
+			t.Errorf("block %d: code hash mismatch: have %x, want %x", tt.number, have, tt.code)
+		}
+		if have := statedb.IntermediateRoot(true); have != tt.root {
+			t.Errorf("block %d: root mismatch: have %x, want %x", tt.number, have, tt.root)
+		}
+	}
+}
@@ -85,6 +85,14 @@ var (
 	// than some meaningful limit a user might use. This is not a consensus error
 	// making the transaction invalid, rather a DOS protection.
 	ErrOversizedData = errors.New("oversized data")
+
+	// ErrSenderNotAuthenticated is returned if sender authentication is enforced
+	// and the sender of a transaction is not authenticated in the auth contract.
+	ErrSenderNotAuthenticated = errors.New("sender not authenticated")
+
+	// ErrAuthCheckerUnavailable is returned if the authentication status of the
+	// senders is requested from a pool without sender authentication lookup.
+	ErrAuthCheckerUnavailable = errors.New("sender authentication lookup unavailable")
 )
 
 var (
@@ -112,6 +120,12 @@ var (
 	invalidTxMeter     = metrics.NewRegisteredMeter("txpool/invalid", nil)
 	underpricedTxMeter = metrics.NewRegisteredMeter("txpool/underpriced", nil)
 	overflowedTxMeter  = metrics.NewRegisteredMeter("txpool/overflowed", nil)
+	// unauthenticatedTxMeter counts how many transactions are rejected due to
+	// their sender not being authenticated.
+	unauthenticatedTxMeter = metrics.NewRegisteredMeter("txpool/unauthenticated", nil)
+	// unauthorizedDeployMeter counts how many contract creations are rejected
+	// due to their sender not being allowed to deploy.
+	unauthorizedDeployMeter = metrics.NewRegisteredMeter("txpool/undeployable", nil)
 	// throttleTxMeter counts how many transactions are rejected due to too-many-changes between
 	// txpool reorgs.
 	throttleTxMeter = metrics.NewRegisteredMeter("txpool/throttle", nil)
@@ -165,6 +179,16 @@ type TxPoolConfig struct {
 	GlobalQueue  uint64 // Maximum number of non-executable transaction slots for all accounts
 
 	Lifetime time.Duration // Maximum amount of time non-executable transaction are queued
+
+	AuthSenders   bool             // Whether to reject transactions from senders not authenticated in the auth contract
+	AuthAllowlist []common.Address // Senders exempt from the authentication check
+}
+
+// TxAuthChecker reports whether an address is authenticated in the auth
+// contract, or allowed to deploy contracts, as of a given block.
+type TxAuthChecker interface {
+	IsAuthenticated(addr common.Address, number uint64) (bool, error)
+	CanDeploy(addr common.Address, number uint64) (bool, error)
 }
 
 // DefaultTxPoolConfig contains the default configurations for the transaction
@@ -243,6 +267,7 @@ type TxPool struct {
 	istanbul bool // Fork indicator whether we are in the istanbul stage.
 	eip2718  bool // Fork indicator whether we are using EIP-2718 type transactions.
 	eip1559  bool // Fork indicator whether we are using EIP-1559 type transactions.
+	authTx   bool // Fork indicator whether we are using authenticated transactions.
 
 	currentState  *state.StateDB // Current state in the blockchain head
 	pendingNonces *txNoncer      // Pending state tracking virtual nonces
@@ -251,6 +276,9 @@ type TxPool struct {
 	locals  *accountSet // Set of local transaction to exempt from eviction rules
 	journal *txJournal  // Journal of local transaction to back up to disk
 
+	authChecker   TxAuthChecker               // Sender authentication lookup, nil if unavailable
+	authAllowlist map[common.Address]struct{} // Senders exempt from the authentication check
+
 	pending map[common.Address]*txList   // All currently processable transactions
 	queue   map[common.Address]*txList   // Queued but non-processable transactions
 	beats   map[common.Address]time.Time // Last heartbeat from each known account
@@ -304,6 +332,10 @@ func NewTxPool(config TxPoolConfig, chainconfig *params.ChainConfig, chain block
 		log.Info("Setting new local account", "address", addr)
 		pool.locals.add(addr)
 	}
+	pool.authAllowlist = map[common.Address]struct{}{params.SystemAddress: {}}
+	for _, addr := range config.AuthAllowlist {
+		pool.authAllowlist[addr] = struct{}{}
+	}
 	pool.priced = newTxPricedList(pool.all)
 	pool.reset(nil, chain.CurrentBlock().Header())
 
@@ -420,6 +452,13 @@ func (pool *TxPool) Stop() {
 	pool.wg.Wait()
 
 	if pool.journal != nil {
+		// Regenerate the journal, so that it doesn't depend on any append
+		// interrupted by the shutdown
+		pool.mu.Lock()
+		if err := pool.journal.rotate(pool.local()); err != nil {
+			log.Warn("Failed to flush local tx journal", "err", err)
+		}
+		pool.mu.Unlock()
 		pool.journal.close()
 	}
 	log.Info("Transaction pool stopped")
@@ -535,17 +574,22 @@ func (pool *TxPool) ContentFrom(addr common.Address) (types.Transactions, types.
 // the next pending execution environment.
 func (pool *TxPool) Pending(enforceTips bool) map[common.Address]types.Transactions {
 	pool.mu.Lock()
-	defer pool.mu.Unlock()
-
-	pending := make(map[common.Address]types.Transactions)
+	var (
+		pending = make(map[common.Address]types.Transactions)
+		capped  []common.Address
+		unpaid  []*types.Transaction
+	)
+	gasPrice, baseFee, checker := pool.gasPrice, pool.priced.urgent.baseFee, pool.authChecker
 	for addr, list := range pool.pending {
 		txs := list.Flatten()
 
-		// If the miner requests tip enforcement, cap the lists now
+		// If the miner requests tip enforcement, collect the transactions paying
+		// too little, which may still be sponsored by the gas subsidy
 		if enforceTips && !pool.locals.contains(addr) {
 			for i, tx := range txs {
-				if tx.EffectiveGasTipIntCmp(pool.gasPrice, pool.priced.urgent.baseFee) < 0 {
-					txs = txs[:i]
+				if tx.EffectiveGasTipIntCmp(gasPrice, baseFee) < 0 {
+					capped = append(capped, addr)
+					unpaid = append(unpaid, txs[i:]...)
 					break
 				}
 			}
@@ -554,6 +598,27 @@ func (pool *TxPool) Pending(enforceTips bool) map[common.Address]types.Transacti
 			pending[addr] = txs
 		}
 	}
+	pool.mu.Unlock()
+
+	// Cap the lists at the first unsponsored transaction, looking the senders up
+	// without holding the pool lock
+	if len(capped) > 0 {
+		auths := pool.lookupAuth(checker, unpaid)
+		for _, addr := range capped {
+			txs := pending[addr]
+			for i, tx := range txs {
+				if tx.EffectiveGasTipIntCmp(gasPrice, baseFee) < 0 && !pool.sponsored(tx, addr, auths) {
+					txs = txs[:i]
+					break
+				}
+			}
+			if len(txs) > 0 {
+				pending[addr] = txs
+			} else {
+				delete(pending, addr)
+			}
+		}
+	}
 	return pending
 }
 
@@ -583,7 +648,10 @@ func (pool *TxPool) local() map[common.Address]types.Transactions {
 
 // validateTx checks whether a transaction is valid according to the consensus
 // rules and adheres to some heuristic limits of the local node (price and size).
-func (pool *TxPool) validateTx(tx *types.Transaction, local bool) error {
+//
+// The authentication of the sender is checked against the statuses resolved in
+// auths, which is nil if the senders were not looked up.
+func (pool *TxPool) validateTx(tx *types.Transaction, local bool, auths *txAuthLookup) error {
 	// Accept only legacy transactions until EIP-2718/2930 activates.
 	if !pool.eip2718 && tx.Type() != types.LegacyTxType {
 		return ErrTxTypeNotSupported
@@ -592,6 +660,10 @@ func (pool *TxPool) validateTx(tx *types.Transaction, local bool) error {
 	if !pool.eip1559 && tx.Type() == types.DynamicFeeTxType {
 		return ErrTxTypeNotSupported
 	}
+	// Reject authenticated transactions until their fork activates.
+	if !pool.authTx && tx.Type() == types.AuthTxType {
+		return ErrTxTypeNotSupported
+	}
 	// Reject transactions over defined size to prevent DOS attacks
 	if uint64(tx.Size()) > txMaxSize {
 		return ErrOversizedData
@@ -621,8 +693,9 @@ func (pool *TxPool) validateTx(tx *types.Transaction, local bool) error {
 	if err != nil {
 		return ErrInvalidSender
 	}
-	// Drop non-local transactions under our own minimal accepted gas price or tip
-	if !local && tx.GasTipCapIntCmp(pool.gasPrice) < 0 {
+	// Drop non-local transactions under our own minimal accepted gas price or tip,
+	// unless sponsored by the gas subsidy
+	if !local && tx.GasTipCapIntCmp(pool.gasPrice) < 0 && !pool.sponsored(tx, from, auths) {
 		return ErrUnderpriced
 	}
 	// Ensure the transaction adheres to nonce ordering
@@ -646,6 +719,20 @@ func (pool *TxPool) validateTx(tx *types.Transaction, local bool) error {
 		}
 	}
 
+	// Ensure the sender is authenticated if the pool enforces it, authenticated
+	// transactions carrying the proof themselves
+	if tx.Type() == types.AuthTxType {
+		if err := pool.validateAuthProof(tx, from, auths); err != nil {
+			return err
+		}
+	} else if err := pool.validateSenderAuth(from, auths); err != nil {
+		return err
+	}
+	if tx.To() == nil {
+		if err := pool.validateDeployAuth(from, auths); err != nil {
+			return err
+		}
+	}
 	// Ensure the transaction has more gas than the basic tx fee.
 	intrGas, err := IntrinsicGas(tx.Data(), tx.AccessList(), tx.To() == nil, true, pool.istanbul)
 	if err != nil {
@@ -657,6 +744,245 @@ func (pool *TxPool) validateTx(tx *types.Transaction, local bool) error {
 	return nil
 }
 
+// txAuthLookup holds the authentication statuses of the senders of a batch of
+// transactions. They are looked up before obtaining the pool lock, so that the
+// auth contract isn't queried with the pool stalled.
+type txAuthLookup struct {
+	checker       TxAuthChecker           // Lookup the statuses were resolved with
+	head          *types.Block            // Block the statuses were looked up at
+	authenticated map[common.Address]bool // Senders authenticated in the auth contract
+	deployers     map[common.Address]bool // Senders allowed to deploy contracts
+}
+
+// active reports whether the statuses were looked up, the authentication checks
+// being skipped otherwise.
+func (auths *txAuthLookup) active() bool {
+	return auths != nil && auths.checker != nil
+}
+
+// lookupAuth resolves the authentication statuses of the senders of the given
+// transactions needed by their validation at the current head. The senders are
+// expected to be cached in the transactions already.
+//
+// The pool lock must not be held, as the checker may be slow or obtain it.
+func (pool *TxPool) lookupAuth(checker TxAuthChecker, txs []*types.Transaction) *txAuthLookup {
+	if checker == nil {
+		return nil
+	}
+	auths := &txAuthLookup{
+		checker:       checker,
+		head:          pool.chain.CurrentBlock(),
+		authenticated: make(map[common.Address]bool),
+		deployers:     make(map[common.Address]bool),
+	}
+	var (
+		number = auths.head.NumberU64()
+		next   = new(big.Int).Add(auths.head.Number(), common.Big1)
+	)
+	for _, tx := range txs {
+		from, err := types.Sender(pool.signer, tx)
+		if err != nil {
+			continue
+		}
+		if _, ok := auths.authenticated[from]; !ok && pool.needsAuth(tx, from, auths.head.Number(), next) {
+			authenticated, err := checker.IsAuthenticated(from, number)
+			if err != nil {
+				log.Debug("Failed to check sender authentication", "sender", from, "err", err)
+			}
+			auths.authenticated[from] = authenticated
+		}
+		if _, ok := auths.deployers[from]; !ok && tx.To() == nil && pool.chainconfig.IsDeployAuth(next) && !pool.chainconfig.DeployAuth.Allowed(from) {
+			allowed, err := checker.CanDeploy(from, number)
+			if err != nil {
+				log.Debug("Failed to check deployment authorization", "sender", from, "err", err)
+			}
+			auths.deployers[from] = allowed
+		}
+	}
+	return auths
+}
+
+// needsAuth reports whether the validation of the transaction may depend on the
+// authentication of its sender: carrying an auth proof, sent while the pool
+// enforces sender authentication or priced for the gas subsidy.
+func (pool *TxPool) needsAuth(tx *types.Transaction, from common.Address, number, next *big.Int) bool {
+	if tx.Type() == types.AuthTxType {
+		return true
+	}
+	if pool.config.AuthSenders && pool.chainconfig.IsImplAuth(number) {
+		if _, ok := pool.authAllowlist[from]; !ok {
+			return true
+		}
+	}
+	return pool.chainconfig.IsSubsidy(next) && tx.GasPrice().Cmp(pool.chainconfig.Subsidy.GasPrice) < 0
+}
+
+// validateAuthProof checks the auth proof of an authenticated transaction
+// against the auth contract as of the pending block.
+func (pool *TxPool) validateAuthProof(tx *types.Transaction, from common.Address, auths *txAuthLookup) error {
+	proof, err := types.DecodeAuthProof(tx.AuthProof())
+	if err != nil {
+		return err
+	}
+	head := pool.chain.CurrentBlock()
+	if auths.active() {
+		head = auths.head
+	}
+	next := new(big.Int).Add(head.Number(), big.NewInt(1))
+	if err := VerifyAuthProof(pool.chainconfig, pool.currentState, next, uint64(time.Now().Unix()), from, proof); err != nil {
+		unauthenticatedTxMeter.Mark(1)
+		return err
+	}
+	if !auths.active() {
+		return nil
+	}
+	if !auths.authenticated[from] {
+		unauthenticatedTxMeter.Mark(1)
+		return ErrAuthProofRevoked
+	}
+	return nil
+}
+
+// validateSenderAuth checks whether the sender is authenticated in the auth
+// contract, if the pool is configured to enforce it and sender authentication
+// is active on the chain.
+func (pool *TxPool) validateSenderAuth(from common.Address, auths *txAuthLookup) error {
+	if !pool.config.AuthSenders || !auths.active() {
+		return nil
+	}
+	if !pool.chainconfig.IsImplAuth(auths.head.Number()) {
+		return nil
+	}
+	if _, ok := pool.authAllowlist[from]; ok {
+		return nil
+	}
+	if !auths.authenticated[from] {
+		unauthenticatedTxMeter.Mark(1)
+		return ErrSenderNotAuthenticated
+	}
+	return nil
+}
+
+// validateDeployAuth checks whether the sender may deploy contracts, if the
+// deployment restriction is active for the next block.
+func (pool *TxPool) validateDeployAuth(from common.Address, auths *txAuthLookup) error {
+	if !auths.active() {
+		return nil
+	}
+	next := new(big.Int).Add(auths.head.Number(), common.Big1)
+	if !pool.chainconfig.IsDeployAuth(next) {
+		return nil
+	}
+	if pool.chainconfig.DeployAuth.Allowed(from) {
+		return nil
+	}
+	if !auths.deployers[from] {
+		unauthorizedDeployMeter.Mark(1)
+		return ErrDeployNotAuthorized
+	}
+	return nil
+}
+
+// sponsored reports whether the transaction is priced below the gas subsidy
+// threshold and sent by an authenticated address, exempting it from the minimum
+// gas price of the pool.
+func (pool *TxPool) sponsored(tx *types.Transaction, from common.Address, auths *txAuthLookup) bool {
+	if !auths.active() {
+		return false
+	}
+	next := new(big.Int).Add(auths.head.Number(), common.Big1)
+	if !pool.chainconfig.IsSubsidy(next) {
+		return false
+	}
+	if tx.GasPrice().Cmp(pool.chainconfig.Subsidy.GasPrice) >= 0 {
+		return false
+	}
+	return auths.authenticated[from]
+}
+
+// SetAuthChecker sets the sender authentication lookup used to enforce the
+// AuthSenders option.
+func (pool *TxPool) SetAuthChecker(checker TxAuthChecker) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	pool.authChecker = checker
+}
+
+// TxPoolAuthBucket counts the senders of the pool sharing an authentication
+// status, along with their transactions.
+type TxPoolAuthBucket struct {
+	Senders int // Number of senders in the bucket
+	Pending int // Number of executable transactions of the senders
+	Queued  int // Number of non-executable transactions of the senders
+}
+
+// TxPoolAuthSummary buckets the content of the pool by the authentication status
+// of the senders, as of the current head.
+type TxPoolAuthSummary struct {
+	Number          uint64           // Block the authentication status was looked up at
+	Enforced        bool             // Whether unauthenticated senders are currently rejected
+	Exempt          TxPoolAuthBucket // Senders exempt from the authentication check
+	Authenticated   TxPoolAuthBucket // Senders authenticated in the auth contract
+	Unauthenticated TxPoolAuthBucket // Senders that would be rejected by the check
+}
+
+// AuthSummary buckets the pending and queued transactions by whether their
+// sender is authenticated, showing how much of the traffic would be rejected
+// once sender authentication is enforced. Senders whose status cannot be looked
+// up are reported as unauthenticated, as the check would reject them.
+//
+// The senders are collected under the pool lock, their status looked up after
+// releasing it so as not to stall the pool.
+func (pool *TxPool) AuthSummary() (*TxPoolAuthSummary, error) {
+	type senderCount struct {
+		pending, queued int
+	}
+	pool.mu.RLock()
+	checker := pool.authChecker
+	if checker == nil {
+		pool.mu.RUnlock()
+		return nil, ErrAuthCheckerUnavailable
+	}
+	head := pool.chain.CurrentBlock()
+	senders := make(map[common.Address]*senderCount, len(pool.pending)+len(pool.queue))
+	for addr, list := range pool.pending {
+		senders[addr] = &senderCount{pending: list.Len()}
+	}
+	for addr, list := range pool.queue {
+		count, ok := senders[addr]
+		if !ok {
+			count = new(senderCount)
+			senders[addr] = count
+		}
+		count.queued = list.Len()
+	}
+	pool.mu.RUnlock()
+
+	summary := &TxPoolAuthSummary{
+		Number:   head.NumberU64(),
+		Enforced: pool.config.AuthSenders && pool.chainconfig.IsImplAuth(head.Number()),
+	}
+	for addr, count := range senders {
+		bucket := &summary.Unauthenticated
+		if _, ok := pool.authAllowlist[addr]; ok {
+			bucket = &summary.Exempt
+		} else {
+			authenticated, err := checker.IsAuthenticated(addr, summary.Number)
+			if err != nil {
+				log.Debug("Failed to check sender authentication", "sender", addr, "err", err)
+			}
+			if authenticated {
+				bucket = &summary.Authenticated
+			}
+		}
+		bucket.Senders++
+		bucket.Pending += count.pending
+		bucket.Queued += count.queued
+	}
+	return summary, nil
+}
+
 // add validates a transaction and inserts it into the non-executable queue for later
 // pending promotion and execution. If the transaction is a replacement for an already
 // pending or queued one, it overwrites the previous transaction if its price is higher.
@@ -664,7 +990,9 @@ func (pool *TxPool) validateTx(tx *types.Transaction, local bool) error {
 // If a newly added transaction is marked as local, its sending account will be
 // be added to the allowlist, preventing any associated transaction from being dropped
 // out of the pool due to pricing constraints.
-func (pool *TxPool) add(tx *types.Transaction, local bool) (replaced bool, err error) {
+//
+// The authentication of the sender is checked against the statuses in auths.
+func (pool *TxPool) add(tx *types.Transaction, local bool, auths *txAuthLookup) (replaced bool, err error) {
 	// If the transaction is already known, discard it
 	hash := tx.Hash()
 	if pool.all.Get(hash) != nil {
@@ -677,7 +1005,7 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (replaced bool, err e
 	isLocal := local || pool.locals.containsTx(tx)
 
 	// If the transaction fails basic validation, discard it
-	if err := pool.validateTx(tx, isLocal); err != nil {
+	if err := pool.validateTx(tx, isLocal, auths); err != nil {
 		log.Trace("Discarding invalid transaction", "hash", hash, "err", err)
 		invalidTxMeter.Mark(1)
 		return false, err
@@ -927,9 +1255,15 @@ func (pool *TxPool) addTxs(txs []*types.Transaction, local, sync bool) []error {
 		return errs
 	}
 
+	// Look the senders up before obtaining the lock, the auth contract being slow
+	pool.mu.RLock()
+	checker := pool.authChecker
+	pool.mu.RUnlock()
+	auths := pool.lookupAuth(checker, news)
+
 	// Process all the new transaction and merge any errors into the original slice
 	pool.mu.Lock()
-	newErrs, dirtyAddrs := pool.addTxsLocked(news, local)
+	newErrs, dirtyAddrs := pool.addTxsLocked(news, local, auths)
 	pool.mu.Unlock()
 
 	var nilSlot = 0
@@ -948,13 +1282,14 @@ func (pool *TxPool) addTxs(txs []*types.Transaction, local, sync bool) []error {
 	return errs
 }
 
-// addTxsLocked attempts to queue a batch of transactions if they are valid.
+// addTxsLocked attempts to queue a batch of transactions if they are valid,
+// checking the authentication of the senders against the statuses in auths.
 // The transaction pool lock must be held.
-func (pool *TxPool) addTxsLocked(txs []*types.Transaction, local bool) ([]error, *accountSet) {
+func (pool *TxPool) addTxsLocked(txs []*types.Transaction, local bool, auths *txAuthLookup) ([]error, *accountSet) {
 	dirty := newAccountSet(pool.signer)
 	errs := make([]error, len(txs))
 	for i, tx := range txs {
-		replaced, err := pool.add(tx, local)
+		replaced, err := pool.add(tx, local, auths)
 		errs[i] = err
 		if err == nil && !replaced {
 			dirty.addTx(tx)
@@ -1164,10 +1499,29 @@ func (pool *TxPool) runReorg(done chan struct{}, reset *txpoolResetRequest, dirt
 		// the flatten operation can be avoided.
 		promoteAddrs = dirtyAccounts.flatten()
 	}
+	var (
+		reinject types.Transactions
+		reorged  bool
+		auths    *txAuthLookup
+	)
+	if reset != nil {
+		// Collect the reorged transactions and look their senders up before
+		// obtaining the lock, the auth contract being slow
+		if reinject, reorged = pool.reorgedTxs(reset.oldHead, reset.newHead); reorged {
+			senderCacher.recover(pool.signer, reinject)
+
+			pool.mu.RLock()
+			checker := pool.authChecker
+			pool.mu.RUnlock()
+			auths = pool.lookupAuth(checker, reinject)
+		}
+	}
 	pool.mu.Lock()
 	if reset != nil {
 		// Reset from the old head to the new, rescheduling any reorged transactions
-		pool.reset(reset.oldHead, reset.newHead)
+		if reorged {
+			pool.resetState(reset.newHead, reinject, auths)
+		}
 
 		// Nonces were reset, discard any events that became stale
 		for addr := range events {
@@ -1229,7 +1583,22 @@ func (pool *TxPool) runReorg(done chan struct{}, reset *txpoolResetRequest, dirt
 
 // reset retrieves the current state of the blockchain and ensures the content
 // of the transaction pool is valid with regard to the chain state.
+//
+// The senders of the reorged transactions are looked up with the pool lock held,
+// the reorgs are thus expected to go through runReorg instead.
 func (pool *TxPool) reset(oldHead, newHead *types.Header) {
+	reinject, ok := pool.reorgedTxs(oldHead, newHead)
+	if !ok {
+		return
+	}
+	senderCacher.recover(pool.signer, reinject)
+	pool.resetState(newHead, reinject, pool.lookupAuth(pool.authChecker, reinject))
+}
+
+// reorgedTxs retrieves the transactions dropped by reorging from the old head to
+// the new, to be reinjected into the pool. It reports false if the pool is not
+// to be reset at all.
+func (pool *TxPool) reorgedTxs(oldHead, newHead *types.Header) (types.Transactions, bool) {
 	// If we're reorging an old state, reinject all dropped transactions
 	var reinject types.Transactions
 
@@ -1256,7 +1625,7 @@ func (pool *TxPool) reset(oldHead, newHead *types.Header) {
 					// If we reorged to a same or higher number, then it's not a case of setHead
 					log.Warn("Transaction pool reset with missing oldhead",
 						"old", oldHead.Hash(), "oldnum", oldNum, "new", newHead.Hash(), "newnum", newNum)
-					return
+					return nil, false
 				}
 				// If the reorg ended up on a lower number, it's indicative of setHead being the cause
 				log.Debug("Skipping transaction reset caused by setHead",
@@ -1267,32 +1636,39 @@ func (pool *TxPool) reset(oldHead, newHead *types.Header) {
 					discarded = append(discarded, rem.Transactions()...)
 					if rem = pool.chain.GetBlock(rem.ParentHash(), rem.NumberU64()-1); rem == nil {
 						log.Error("Unrooted old chain seen by tx pool", "block", oldHead.Number, "hash", oldHead.Hash())
-						return
+						return nil, false
 					}
 				}
 				for add.NumberU64() > rem.NumberU64() {
 					included = append(included, add.Transactions()...)
 					if add = pool.chain.GetBlock(add.ParentHash(), add.NumberU64()-1); add == nil {
 						log.Error("Unrooted new chain seen by tx pool", "block", newHead.Number, "hash", newHead.Hash())
-						return
+						return nil, false
 					}
 				}
 				for rem.Hash() != add.Hash() {
 					discarded = append(discarded, rem.Transactions()...)
 					if rem = pool.chain.GetBlock(rem.ParentHash(), rem.NumberU64()-1); rem == nil {
 						log.Error("Unrooted old chain seen by tx pool", "block", oldHead.Number, "hash", oldHead.Hash())
-						return
+						return nil, false
 					}
 					included = append(included, add.Transactions()...)
 					if add = pool.chain.GetBlock(add.ParentHash(), add.NumberU64()-1); add == nil {
 						log.Error("Unrooted new chain seen by tx pool", "block", newHead.Number, "hash", newHead.Hash())
-						return
+						return nil, false
 					}
 				}
 				reinject = types.TxDifference(discarded, included)
 			}
 		}
 	}
+	return reinject, true
+}
+
+// resetState resets the pool to the state of the new head, reinjecting the given
+// reorged transactions checked against the authentication statuses in auths.
+// The transaction pool lock must be held.
+func (pool *TxPool) resetState(newHead *types.Header, reinject types.Transactions, auths *txAuthLookup) {
 	// Initialize the internal state to the current head
 	if newHead == nil {
 		newHead = pool.chain.CurrentBlock().Header() // Special case during testing
@@ -1308,14 +1684,14 @@ func (pool *TxPool) reset(oldHead, newHead *types.Header) {
 
 	// Inject any transactions discarded due to reorgs
 	log.Debug("Reinjecting stale transactions", "count", len(reinject))
-	senderCacher.recover(pool.signer, reinject)
-	pool.addTxsLocked(reinject, false)
+	pool.addTxsLocked(reinject, false, auths)
 
 	// Update all fork indicator by next pending block number.
 	next := new(big.Int).Add(newHead.Number, big.NewInt(1))
 	pool.istanbul = pool.chainconfig.IsIstanbul(next)
 	pool.eip2718 = pool.chainconfig.IsBerlin(next)
 	pool.eip1559 = pool.chainconfig.IsLondon(next)
+	pool.authTx = pool.chainconfig.IsAuthTx(next)
 }
 
 // promoteExecutables moves transactions that have become processable from the
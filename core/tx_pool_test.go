@@ -309,6 +309,231 @@ func TestInvalidTransactions(t *testing.T) {
 	}
 }
 
+// testAuthChecker is a sender authentication lookup backed by a static set.
+type testAuthChecker map[common.Address]bool
+
+func (c testAuthChecker) IsAuthenticated(addr common.Address, number uint64) (bool, error) {
+	return c[addr], nil
+}
+
+func (c testAuthChecker) CanDeploy(addr common.Address, number uint64) (bool, error) {
+	return c[addr], nil
+}
+
+// lockingAuthChecker is a sender authentication lookup acquiring the lock of the
+// pool, to catch lookups made while holding it.
+type lockingAuthChecker struct {
+	testAuthChecker
+	pool *TxPool
+}
+
+func (c lockingAuthChecker) IsAuthenticated(addr common.Address, number uint64) (bool, error) {
+	c.pool.mu.Lock()
+	defer c.pool.mu.Unlock()
+	return c.testAuthChecker.IsAuthenticated(addr, number)
+}
+
+// Tests that senders not authenticated in the auth contract are rejected when
+// the pool enforces sender authentication.
+func TestSenderAuthentication(t *testing.T) {
+	t.Parallel()
+
+	authed, _ := crypto.GenerateKey()
+	unauthed, _ := crypto.GenerateKey()
+	allowed, _ := crypto.GenerateKey()
+
+	poolConfig := testTxPoolConfig
+	poolConfig.AuthSenders = true
+	poolConfig.AuthAllowlist = []common.Address{crypto.PubkeyToAddress(allowed.PublicKey)}
+
+	newPool := func(authBlock int64) *TxPool {
+		config := *params.TestChainConfig
+		config.AuthBlock = big.NewInt(authBlock)
+
+		statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+		blockchain := &testBlockChain{10000000, statedb, new(event.Feed)}
+
+		pool := NewTxPool(poolConfig, &config, blockchain)
+		for _, key := range []*ecdsa.PrivateKey{authed, unauthed, allowed} {
+			testAddBalance(pool, crypto.PubkeyToAddress(key.PublicKey), big.NewInt(params.Ether))
+		}
+		// The senders are looked up without holding the pool lock
+		pool.SetAuthChecker(lockingAuthChecker{testAuthChecker{crypto.PubkeyToAddress(authed.PublicKey): true}, pool})
+		return pool
+	}
+	pool := newPool(0)
+	defer pool.Stop()
+
+	// Without a checker the pool accepts anything
+	pool.SetAuthChecker(nil)
+	if err := pool.AddRemote(transaction(0, 100000, unauthed)); err != nil {
+		t.Fatalf("failed to add transaction without checker: %v", err)
+	}
+	pool.SetAuthChecker(lockingAuthChecker{testAuthChecker{crypto.PubkeyToAddress(authed.PublicKey): true}, pool})
+
+	if err := pool.AddRemote(transaction(0, 100000, authed)); err != nil {
+		t.Errorf("failed to add authenticated transaction: %v", err)
+	}
+	if err := pool.AddRemote(transaction(1, 100000, unauthed)); !errors.Is(err, ErrSenderNotAuthenticated) {
+		t.Errorf("unauthenticated transaction error mismatch: have %v, want %v", err, ErrSenderNotAuthenticated)
+	}
+	if err := pool.AddRemote(transaction(0, 100000, allowed)); err != nil {
+		t.Errorf("failed to add allowlisted transaction: %v", err)
+	}
+	// Before the auth fork the check is not enforced (but the minimum cost is)
+	prefork := newPool(1)
+	defer prefork.Stop()
+
+	if err := prefork.AddRemote(pricedTransaction(0, 100000, big.NewInt(2*params.GWei*1000), unauthed)); err != nil {
+		t.Errorf("failed to add transaction before auth fork: %v", err)
+	}
+}
+
+// Tests that the content of the pool is bucketed by the authentication status
+// of the senders.
+func TestTxPoolAuthSummary(t *testing.T) {
+	t.Parallel()
+
+	config := *params.TestChainConfig
+	config.AuthBlock = big.NewInt(0)
+
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	blockchain := &testBlockChain{10000000, statedb, new(event.Feed)}
+
+	authed, _ := crypto.GenerateKey()
+	unauthed, _ := crypto.GenerateKey()
+	allowed, _ := crypto.GenerateKey()
+
+	poolConfig := testTxPoolConfig
+	poolConfig.AuthAllowlist = []common.Address{crypto.PubkeyToAddress(allowed.PublicKey)}
+
+	pool := NewTxPool(poolConfig, &config, blockchain)
+	defer pool.Stop()
+
+	if _, err := pool.AuthSummary(); !errors.Is(err, ErrAuthCheckerUnavailable) {
+		t.Fatalf("summary without checker error mismatch: have %v, want %v", err, ErrAuthCheckerUnavailable)
+	}
+	for _, key := range []*ecdsa.PrivateKey{authed, unauthed, allowed} {
+		testAddBalance(pool, crypto.PubkeyToAddress(key.PublicKey), big.NewInt(params.Ether))
+	}
+	// Authentication is not enforced, so the unauthenticated sender gets in
+	pool.AddRemotesSync([]*types.Transaction{
+		transaction(0, 100000, authed),
+		transaction(1, 100000, authed),
+		transaction(0, 100000, unauthed),
+		transaction(2, 100000, unauthed),
+		transaction(0, 100000, allowed),
+	})
+	// The senders are looked up without holding the pool lock
+	pool.SetAuthChecker(lockingAuthChecker{testAuthChecker{crypto.PubkeyToAddress(authed.PublicKey): true}, pool})
+
+	summary, err := pool.AuthSummary()
+	if err != nil {
+		t.Fatalf("failed to summarize pool: %v", err)
+	}
+	if summary.Enforced {
+		t.Errorf("authentication reported as enforced")
+	}
+	if want := (TxPoolAuthBucket{Senders: 1, Pending: 2}); summary.Authenticated != want {
+		t.Errorf("authenticated bucket mismatch: have %+v, want %+v", summary.Authenticated, want)
+	}
+	if want := (TxPoolAuthBucket{Senders: 1, Pending: 1, Queued: 1}); summary.Unauthenticated != want {
+		t.Errorf("unauthenticated bucket mismatch: have %+v, want %+v", summary.Unauthenticated, want)
+	}
+	if want := (TxPoolAuthBucket{Senders: 1, Pending: 1}); summary.Exempt != want {
+		t.Errorf("exempt bucket mismatch: have %+v, want %+v", summary.Exempt, want)
+	}
+}
+
+// Tests that contract creations from senders not allowed to deploy are rejected
+// once the deployment restriction is active, while calls are unaffected.
+func TestDeployAuthorization(t *testing.T) {
+	t.Parallel()
+
+	allowed, _ := crypto.GenerateKey()
+	config := *params.TestChainConfig
+	config.AuthBlock = big.NewInt(0)
+	config.DeployAuth = &params.DeployAuthConfig{
+		Block:     big.NewInt(0),
+		Allowlist: []common.Address{crypto.PubkeyToAddress(allowed.PublicKey)},
+	}
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	blockchain := &testBlockChain{10000000, statedb, new(event.Feed)}
+
+	pool := NewTxPool(testTxPoolConfig, &config, blockchain)
+	defer pool.Stop()
+
+	authed, _ := crypto.GenerateKey()
+	unauthed, _ := crypto.GenerateKey()
+	for _, key := range []*ecdsa.PrivateKey{authed, unauthed, allowed} {
+		testAddBalance(pool, crypto.PubkeyToAddress(key.PublicKey), big.NewInt(params.Ether))
+	}
+	pool.SetAuthChecker(testAuthChecker{crypto.PubkeyToAddress(authed.PublicKey): true})
+
+	create := func(nonce uint64, key *ecdsa.PrivateKey) *types.Transaction {
+		tx, _ := types.SignTx(types.NewContractCreation(nonce, new(big.Int), 100000, big.NewInt(1), nil), types.HomesteadSigner{}, key)
+		return tx
+	}
+	if err := pool.AddRemote(create(0, authed)); err != nil {
+		t.Errorf("failed to add authorized deployment: %v", err)
+	}
+	if err := pool.AddRemote(create(0, allowed)); err != nil {
+		t.Errorf("failed to add allowlisted deployment: %v", err)
+	}
+	if err := pool.AddRemote(create(0, unauthed)); !errors.Is(err, ErrDeployNotAuthorized) {
+		t.Errorf("unauthorized deployment error mismatch: have %v, want %v", err, ErrDeployNotAuthorized)
+	}
+	if err := pool.AddRemote(transaction(0, 100000, unauthed)); err != nil {
+		t.Errorf("failed to add call from unauthorized sender: %v", err)
+	}
+	// Before the restriction is active anyone may deploy
+	config.DeployAuth.Block = big.NewInt(100)
+	if err := pool.AddRemote(create(1, unauthed)); err != nil {
+		t.Errorf("failed to add deployment before restriction: %v", err)
+	}
+}
+
+// Tests that authenticated senders may submit transactions below the minimum
+// gas price of the pool once the gas subsidy is active.
+func TestSubsidySponsoredTransactions(t *testing.T) {
+	t.Parallel()
+
+	config := *params.TestChainConfig
+	config.AuthBlock = big.NewInt(0)
+	config.Subsidy = &params.SubsidyConfig{
+		Block:         big.NewInt(0),
+		GasPrice:      big.NewInt(10),
+		BlockGasLimit: 1000000,
+	}
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	blockchain := &testBlockChain{10000000, statedb, new(event.Feed)}
+
+	pool := NewTxPool(testTxPoolConfig, &config, blockchain)
+	defer pool.Stop()
+	pool.SetGasPrice(big.NewInt(100))
+
+	authed, _ := crypto.GenerateKey()
+	unauthed, _ := crypto.GenerateKey()
+	for _, key := range []*ecdsa.PrivateKey{authed, unauthed} {
+		testAddBalance(pool, crypto.PubkeyToAddress(key.PublicKey), big.NewInt(params.Ether))
+	}
+	pool.SetAuthChecker(lockingAuthChecker{testAuthChecker{crypto.PubkeyToAddress(authed.PublicKey): true}, pool})
+
+	if err := pool.addRemoteSync(pricedTransaction(0, 100000, big.NewInt(1), authed)); err != nil {
+		t.Errorf("failed to add sponsored transaction: %v", err)
+	}
+	if err := pool.AddRemote(pricedTransaction(0, 100000, big.NewInt(1), unauthed)); !errors.Is(err, ErrUnderpriced) {
+		t.Errorf("unauthenticated transaction error mismatch: have %v, want %v", err, ErrUnderpriced)
+	}
+	// Priced at or above the threshold the pool minimum applies again
+	if err := pool.AddRemote(pricedTransaction(1, 100000, big.NewInt(10), authed)); !errors.Is(err, ErrUnderpriced) {
+		t.Errorf("unsponsored transaction error mismatch: have %v, want %v", err, ErrUnderpriced)
+	}
+	if pending := pool.Pending(true); len(pending[crypto.PubkeyToAddress(authed.PublicKey)]) != 1 {
+		t.Errorf("sponsored transaction not pending with tip enforcement")
+	}
+}
+
 func TestTransactionQueue(t *testing.T) {
 	t.Parallel()
 
@@ -430,14 +655,14 @@ func TestTransactionChainFork(t *testing.T) {
 	resetState()
 
 	tx := transaction(0, 100000, key)
-	if _, err := pool.add(tx, false); err != nil {
+	if _, err := pool.add(tx, false, nil); err != nil {
 		t.Error("didn't expect error", err)
 	}
 	pool.removeTx(tx.Hash(), true)
 
 	// reset the pool's internal state
 	resetState()
-	if _, err := pool.add(tx, false); err != nil {
+	if _, err := pool.add(tx, false, nil); err != nil {
 		t.Error("didn't expect error", err)
 	}
 }
@@ -464,10 +689,10 @@ func TestTransactionDoubleNonce(t *testing.T) {
 	tx3, _ := types.SignTx(types.NewTransaction(0, common.Address{}, big.NewInt(100), 1000000, big.NewInt(1), nil), signer, key)
 
 	// Add the first two transaction, ensure higher priced stays only
-	if replace, err := pool.add(tx1, false); err != nil || replace {
+	if replace, err := pool.add(tx1, false, nil); err != nil || replace {
 		t.Errorf("first transaction insert failed (%v) or reported replacement (%v)", err, replace)
 	}
-	if replace, err := pool.add(tx2, false); err != nil || !replace {
+	if replace, err := pool.add(tx2, false, nil); err != nil || !replace {
 		t.Errorf("second transaction insert failed (%v) or not reported replacement (%v)", err, replace)
 	}
 	<-pool.requestPromoteExecutables(newAccountSet(signer, addr))
@@ -479,7 +704,7 @@ func TestTransactionDoubleNonce(t *testing.T) {
 	}
 
 	// Add the third transaction and ensure it's not saved (smaller price)
-	pool.add(tx3, false)
+	pool.add(tx3, false, nil)
 	<-pool.requestPromoteExecutables(newAccountSet(signer, addr))
 	if pool.pending[addr].Len() != 1 {
 		t.Error("expected 1 pending transactions, got", pool.pending[addr].Len())
@@ -502,7 +727,7 @@ func TestTransactionMissingNonce(t *testing.T) {
 	addr := crypto.PubkeyToAddress(key.PublicKey)
 	testAddBalance(pool, addr, big.NewInt(100000000000000))
 	tx := transaction(1, 100000, key)
-	if _, err := pool.add(tx, false); err != nil {
+	if _, err := pool.add(tx, false, nil); err != nil {
 		t.Error("didn't expect error", err)
 	}
 	if len(pool.pending) != 0 {
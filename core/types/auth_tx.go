@@ -0,0 +1,207 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/qydata/go-ctereum/common"
+	"github.com/qydata/go-ctereum/common/math"
+	"github.com/qydata/go-ctereum/crypto"
+	"github.com/qydata/go-ctereum/rlp"
+)
+
+// AuthTxType is the type of authenticated transactions. It is picked from the
+// top of the type range to stay clear of future Ethereum transaction types.
+const AuthTxType = 0x7e
+
+// ErrInvalidAuthProof is returned if the auth proof of an authenticated
+// transaction is malformed.
+var ErrInvalidAuthProof = errors.New("invalid auth proof")
+
+// AuthTx is a dynamic fee transaction carrying a proof that its sender was
+// authenticated by the owner of the AuthController. The proof is checked against
+// the AuthController live at the block including the transaction.
+type AuthTx struct {
+	ChainID    *big.Int
+	Nonce      uint64
+	GasTipCap  *big.Int // a.k.a. maxPriorityFeePerGas
+	GasFeeCap  *big.Int // a.k.a. maxFeePerGas
+	Gas        uint64
+	To         *common.Address `rlp:"nil"` // nil means contract creation
+	Value      *big.Int
+	Data       []byte
+	AccessList AccessList
+	AuthProof  []byte // RLP encoded AuthProof
+
+	// Signature values
+	V *big.Int `json:"v" gencodec:"required"`
+	R *big.Int `json:"r" gencodec:"required"`
+	S *big.Int `json:"s" gencodec:"required"`
+}
+
+// copy creates a deep copy of the transaction data and initializes all fields.
+func (tx *AuthTx) copy() TxData {
+	cpy := &AuthTx{
+		Nonce:     tx.Nonce,
+		To:        copyAddressPtr(tx.To),
+		Data:      common.CopyBytes(tx.Data),
+		Gas:       tx.Gas,
+		AuthProof: common.CopyBytes(tx.AuthProof),
+		// These are copied below.
+		AccessList: make(AccessList, len(tx.AccessList)),
+		Value:      new(big.Int),
+		ChainID:    new(big.Int),
+		GasTipCap:  new(big.Int),
+		GasFeeCap:  new(big.Int),
+		V:          new(big.Int),
+		R:          new(big.Int),
+		S:          new(big.Int),
+	}
+	copy(cpy.AccessList, tx.AccessList)
+	if tx.Value != nil {
+		cpy.Value.Set(tx.Value)
+	}
+	if tx.ChainID != nil {
+		cpy.ChainID.Set(tx.ChainID)
+	}
+	if tx.GasTipCap != nil {
+		cpy.GasTipCap.Set(tx.GasTipCap)
+	}
+	if tx.GasFeeCap != nil {
+		cpy.GasFeeCap.Set(tx.GasFeeCap)
+	}
+	if tx.V != nil {
+		cpy.V.Set(tx.V)
+	}
+	if tx.R != nil {
+		cpy.R.Set(tx.R)
+	}
+	if tx.S != nil {
+		cpy.S.Set(tx.S)
+	}
+	return cpy
+}
+
+// accessors for innerTx.
+func (tx *AuthTx) txType() byte           { return AuthTxType }
+func (tx *AuthTx) chainID() *big.Int      { return tx.ChainID }
+func (tx *AuthTx) accessList() AccessList { return tx.AccessList }
+func (tx *AuthTx) data() []byte           { return tx.Data }
+func (tx *AuthTx) gas() uint64            { return tx.Gas }
+func (tx *AuthTx) gasFeeCap() *big.Int    { return tx.GasFeeCap }
+func (tx *AuthTx) gasTipCap() *big.Int    { return tx.GasTipCap }
+func (tx *AuthTx) gasPrice() *big.Int     { return tx.GasFeeCap }
+func (tx *AuthTx) value() *big.Int        { return tx.Value }
+func (tx *AuthTx) nonce() uint64          { return tx.Nonce }
+func (tx *AuthTx) to() *common.Address    { return tx.To }
+
+func (tx *AuthTx) rawSignatureValues() (v, r, s *big.Int) {
+	return tx.V, tx.R, tx.S
+}
+
+func (tx *AuthTx) setSignatureValues(chainID, v, r, s *big.Int) {
+	tx.ChainID, tx.V, tx.R, tx.S = chainID, v, r, s
+}
+
+// AuthProof is an AuthController authentication record of the sender of an
+// authenticated transaction, signed by the owner of the AuthController as per
+// EIP-712. The controller and the sender are implied by the transaction.
+type AuthProof struct {
+	AuthTime   *big.Int
+	AuthExpiry *big.Int // Unix time after which the proof is void, mandatory
+	AuthLevel  *big.Int
+	ExpandData string
+	Signature  []byte // [R || S || V] with V being 27 or 28
+}
+
+// DecodeAuthProof decodes the auth proof carried by an authenticated
+// transaction.
+func DecodeAuthProof(blob []byte) (*AuthProof, error) {
+	proof := new(AuthProof)
+	if err := rlp.DecodeBytes(blob, proof); err != nil {
+		return nil, ErrInvalidAuthProof
+	}
+	if len(proof.Signature) != crypto.SignatureLength {
+		return nil, ErrInvalidAuthProof
+	}
+	return proof, nil
+}
+
+// Expired reports whether the proof has expired at the given unix time.
+func (p *AuthProof) Expired(time uint64) bool {
+	return p.AuthExpiry != nil && p.AuthExpiry.Sign() > 0 && p.AuthExpiry.Cmp(new(big.Int).SetUint64(time)) < 0
+}
+
+var (
+	authDomainTypeHash = crypto.Keccak256Hash([]byte("EIP712Domain(string name,string version,uint256 chainId,address verifyingContract)"))
+	authDataTypeHash   = crypto.Keccak256Hash([]byte("AuthData(address caddress,address sender,uint256 authTime,uint256 authExpiry,bool isAuth,uint256 authLevel,string expandData)"))
+	authDomainName     = crypto.Keccak256Hash([]byte("AuthController"))
+	authDomainVersion  = crypto.Keccak256Hash([]byte("1"))
+)
+
+// Hash returns the EIP-712 digest the owner of the AuthController signs to
+// authenticate the sender, matching the AuthData records of the contract.
+func (p *AuthProof) Hash(chainID *big.Int, controller common.Address, sender common.Address) common.Hash {
+	domain := crypto.Keccak256(
+		authDomainTypeHash[:],
+		authDomainName[:],
+		authDomainVersion[:],
+		authWord(chainID),
+		common.LeftPadBytes(controller[:], 32),
+	)
+	data := crypto.Keccak256(
+		authDataTypeHash[:],
+		common.LeftPadBytes(controller[:], 32),
+		common.LeftPadBytes(sender[:], 32),
+		authWord(p.AuthTime),
+		authWord(p.AuthExpiry),
+		common.LeftPadBytes([]byte{1}, 32), // isAuth
+		authWord(p.AuthLevel),
+		crypto.Keccak256([]byte(p.ExpandData)),
+	)
+	return crypto.Keccak256Hash([]byte{0x19, 0x01}, domain, data)
+}
+
+// Signer returns the address that signed the proof for the given sender.
+func (p *AuthProof) Signer(chainID *big.Int, controller common.Address, sender common.Address) (common.Address, error) {
+	if len(p.Signature) != crypto.SignatureLength {
+		return common.Address{}, ErrInvalidAuthProof
+	}
+	sig := common.CopyBytes(p.Signature)
+	if sig[crypto.RecoveryIDOffset] >= 27 {
+		sig[crypto.RecoveryIDOffset] -= 27
+	}
+	if sig[crypto.RecoveryIDOffset] > 1 {
+		return common.Address{}, ErrInvalidAuthProof
+	}
+	hash := p.Hash(chainID, controller, sender)
+	pubkey, err := crypto.SigToPub(hash[:], sig)
+	if err != nil {
+		return common.Address{}, ErrInvalidAuthProof
+	}
+	return crypto.PubkeyToAddress(*pubkey), nil
+}
+
+// authWord encodes an integer as an EIP-712 uint256 word, treating nil as zero.
+func authWord(n *big.Int) []byte {
+	if n == nil {
+		return make([]byte, 32)
+	}
+	return math.U256Bytes(new(big.Int).Set(n))
+}
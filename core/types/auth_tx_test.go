@@ -0,0 +1,105 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/qydata/go-ctereum/common"
+	"github.com/qydata/go-ctereum/crypto"
+	"github.com/qydata/go-ctereum/rlp"
+)
+
+// Tests that authenticated transactions are signed, encoded and decoded like
+// the other typed transactions, and only accepted by the auth signer.
+func TestAuthTxCoding(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	owner, _ := crypto.GenerateKey()
+
+	var (
+		chainID    = big.NewInt(1)
+		signer     = NewAuthSigner(chainID)
+		sender     = crypto.PubkeyToAddress(key.PublicKey)
+		controller = common.HexToAddress("0xa0")
+		recipient  = common.HexToAddress("0x095e7baea6a6c7c4c2dfeb977efac326af552d87")
+		proof      = &AuthProof{AuthTime: big.NewInt(1000), AuthExpiry: big.NewInt(2000), AuthLevel: big.NewInt(1), ExpandData: "kyc"}
+	)
+	hash := proof.Hash(chainID, controller, sender)
+	sig, err := crypto.Sign(hash[:], owner)
+	if err != nil {
+		t.Fatalf("failed to sign proof: %v", err)
+	}
+	sig[crypto.RecoveryIDOffset] += 27
+	proof.Signature = sig
+
+	blob, err := rlp.EncodeToBytes(proof)
+	if err != nil {
+		t.Fatalf("failed to encode proof: %v", err)
+	}
+	tx, err := SignNewTx(key, signer, &AuthTx{
+		ChainID:   chainID,
+		Nonce:     1,
+		GasTipCap: big.NewInt(1),
+		GasFeeCap: big.NewInt(10),
+		Gas:       21000,
+		To:        &recipient,
+		Value:     big.NewInt(5),
+		AuthProof: blob,
+	})
+	if err != nil {
+		t.Fatalf("failed to sign transaction: %v", err)
+	}
+	if tx.Type() != AuthTxType {
+		t.Fatalf("type mismatch: have %d, want %d", tx.Type(), AuthTxType)
+	}
+	for name, coding := range map[string]func(*Transaction) (*Transaction, error){"rlp": encodeDecodeBinary, "json": encodeDecodeJSON} {
+		parsed, err := coding(tx)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if err := assertEqual(parsed, tx); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if from, err := Sender(signer, parsed); err != nil || from != sender {
+			t.Errorf("%s: sender mismatch: have %x (%v), want %x", name, from, err, sender)
+		}
+	}
+	// The proof is part of the signed payload
+	if _, err := Sender(NewLondonSigner(chainID), tx); !errors.Is(err, ErrTxTypeNotSupported) {
+		t.Errorf("london signer error mismatch: have %v, want %v", err, ErrTxTypeNotSupported)
+	}
+	msg, err := tx.AsMessage(signer, nil)
+	if err != nil {
+		t.Fatalf("failed to convert to message: %v", err)
+	}
+	if have, err := msg.AuthProof().Signer(chainID, controller, sender); err != nil || have != crypto.PubkeyToAddress(owner.PublicKey) {
+		t.Errorf("proof signer mismatch: have %x (%v), want %x", have, err, crypto.PubkeyToAddress(owner.PublicKey))
+	}
+	if have, _ := msg.AuthProof().Signer(chainID, controller, recipient); have == crypto.PubkeyToAddress(owner.PublicKey) {
+		t.Errorf("proof not bound to the sender")
+	}
+	if !msg.AuthProof().Expired(2001) || msg.AuthProof().Expired(2000) {
+		t.Errorf("proof expiry mismatch")
+	}
+	// Malformed proofs are rejected on conversion
+	bad, _ := SignNewTx(key, signer, &AuthTx{ChainID: chainID, GasTipCap: new(big.Int), GasFeeCap: new(big.Int), Value: new(big.Int), AuthProof: []byte{0x01}})
+	if _, err := bad.AsMessage(signer, nil); !errors.Is(err, ErrInvalidAuthProof) {
+		t.Errorf("malformed proof error mismatch: have %v, want %v", err, ErrInvalidAuthProof)
+	}
+}
@@ -184,6 +184,10 @@ func (tx *Transaction) decodeTyped(b []byte) (TxData, error) {
 		var inner DynamicFeeTx
 		err := rlp.DecodeBytes(b[1:], &inner)
 		return &inner, err
+	case AuthTxType:
+		var inner AuthTx
+		err := rlp.DecodeBytes(b[1:], &inner)
+		return &inner, err
 	default:
 		return nil, ErrTxTypeNotSupported
 	}
@@ -261,6 +265,15 @@ func (tx *Transaction) Data() []byte { return tx.inner.data() }
 // AccessList returns the access list of the transaction.
 func (tx *Transaction) AccessList() AccessList { return tx.inner.accessList() }
 
+// AuthProof returns the RLP encoded auth proof of an authenticated transaction,
+// or nil for other transaction types.
+func (tx *Transaction) AuthProof() []byte {
+	if inner, ok := tx.inner.(*AuthTx); ok {
+		return inner.AuthProof
+	}
+	return nil
+}
+
 // Gas returns the gas limit of the transaction.
 func (tx *Transaction) Gas() uint64 { return tx.inner.gas() }
 
@@ -589,6 +602,7 @@ type Message struct {
 	gasTipCap  *big.Int
 	data       []byte
 	accessList AccessList
+	authProof  *AuthProof
 	isFake     bool
 }
 
@@ -626,6 +640,13 @@ func (tx *Transaction) AsMessage(s Signer, baseFee *big.Int) (Message, error) {
 	if baseFee != nil {
 		msg.gasPrice = math.BigMin(msg.gasPrice.Add(msg.gasTipCap, baseFee), msg.gasFeeCap)
 	}
+	if tx.Type() == AuthTxType {
+		proof, err := DecodeAuthProof(tx.AuthProof())
+		if err != nil {
+			return msg, err
+		}
+		msg.authProof = proof
+	}
 	var err error
 	msg.from, err = Sender(s, tx)
 	return msg, err
@@ -643,6 +664,10 @@ func (m Message) Data() []byte           { return m.data }
 func (m Message) AccessList() AccessList { return m.accessList }
 func (m Message) IsFake() bool           { return m.isFake }
 
+// AuthProof returns the decoded auth proof of an authenticated transaction, or
+// nil for other transaction types.
+func (m Message) AuthProof() *AuthProof { return m.authProof }
+
 // copyAddressPtr copies an address.
 func copyAddressPtr(a *common.Address) *common.Address {
 	if a == nil {
@@ -181,7 +181,7 @@ type AuthControllerAuthData struct {
 func (evm *EVM) IsAuth(addr common.Address) ([]byte, bool) {
 	methodId := "authsSingle"
 
-	contractAuthAddr := evm.chainConfig.AuthContract
+	contractAuthAddr := evm.chainConfig.AuthContractAt(evm.Context.BlockNumber)
 	authControllerABI := evm.chainConfig.AuthContractABI()
 	parsed, _ := abi.JSON(strings.NewReader(authControllerABI))
 	data, _ := parsed.Pack(methodId, addr)
@@ -209,7 +209,7 @@ func (evm *EVM) IsAuth(addr common.Address) ([]byte, bool) {
 func (evm *EVM) IsAuthNew(addr common.Address) ([]byte, bool) {
 	methodId := "authsSingle"
 
-	contractAuthAddr := common.HexToAddress("0x709bBc0aD7581D02244E00C356d0EFcbC79AE9f3")
+	contractAuthAddr := evm.chainConfig.AuthContractAt(evm.Context.BlockNumber)
 	authControllerABI := evm.chainConfig.AuthContractABI()
 	parsed, _ := abi.JSON(strings.NewReader(authControllerABI))
 	data, _ := parsed.Pack(methodId, addr)
@@ -234,6 +234,44 @@ func (evm *EVM) IsAuthNew(addr common.Address) ([]byte, bool) {
 	return nil, isAuth
 }
 
+// IsWhitelisted reports whether the address is whitelisted in the auth contract
+// live at the current block.
+func (evm *EVM) IsWhitelisted(addr common.Address) bool {
+	contractAuthAddr := evm.chainConfig.AuthContractAt(evm.Context.BlockNumber)
+	parsed, err := abi.JSON(strings.NewReader(evm.chainConfig.AuthContractABI()))
+	if err != nil {
+		return false
+	}
+	data, err := parsed.Pack("whitelisted", addr)
+	if err != nil {
+		return false
+	}
+	contract := NewContract(AccountRef(contractAuthAddr), AccountRef(contractAuthAddr), new(big.Int), math.MaxUint64/2)
+	contract.SetCallCode(&contractAuthAddr, evm.StateDB.GetCodeHash(contractAuthAddr), evm.StateDB.GetCode(contractAuthAddr))
+	result, err := evm.interpreter.Run(contract, data, true)
+	if err != nil {
+		return false
+	}
+	ret, err := parsed.Unpack("whitelisted", result)
+	if err != nil {
+		return false
+	}
+	return *abi.ConvertType(ret[0], new(bool)).(*bool)
+}
+
+// CanDeploy reports whether the address may deploy contracts while the
+// deployment restriction is active: it must be on the configured allowlist,
+// authenticated or whitelisted in the auth contract.
+func (evm *EVM) CanDeploy(addr common.Address) bool {
+	if evm.chainConfig.DeployAuth.Allowed(addr) {
+		return true
+	}
+	if _, isAuth := evm.IsAuth(addr); isAuth {
+		return true
+	}
+	return evm.IsWhitelisted(addr)
+}
+
 // Call executes the contract associated with the addr with the given input as
 // parameters. It also handles any necessary value transfer required and takes
 // the necessary steps to create accounts and reverses the state in case of an
@@ -529,6 +567,11 @@ func (evm *EVM) create(caller ContractRef, codeAndHash *codeAndHash, gas uint64,
 	if evm.depth > int(params.CallCreateDepth) {
 		return nil, common.Address{}, gas, ErrDepth
 	}
+	// Contracts may only be deployed on behalf of senders allowed to, be it
+	// directly or through a factory.
+	if evm.chainConfig.IsDeployAuth(evm.Context.BlockNumber) && !evm.CanDeploy(evm.Origin) {
+		return nil, common.Address{}, gas, ErrDeployNotAuth
+	}
 	if !evm.Context.CanTransfer(evm.StateDB, caller.Address(), value) {
 		return nil, common.Address{}, gas, ErrInsufficientBalance
 	}
@@ -43,6 +43,12 @@ type StateDB interface {
 	SubRefund(uint64)
 	GetRefund() uint64
 
+	AddSubsidy(common.Address, uint64, *big.Int)
+	Subsidy() (common.Address, uint64, *big.Int)
+
+	AddTips(common.Address, *big.Int)
+	Tips() (common.Address, *big.Int)
+
 	GetCommittedState(common.Address, common.Hash) common.Hash
 	GetState(common.Address, common.Hash) common.Hash
 	SetState(common.Address, common.Hash, common.Hash)
@@ -128,6 +128,52 @@ func TestCall(t *testing.T) {
 	}
 }
 
+// Tests that contracts can only be deployed on behalf of allowed senders once
+// the deployment restriction is active, be it directly or through a factory.
+func TestDeployAuthorization(t *testing.T) {
+	var (
+		authContract = common.HexToAddress("0xa0")
+		factory      = common.HexToAddress("0xa1")
+		authed       = common.HexToAddress("0x01")
+		unauthed     = common.HexToAddress("0x02")
+		allowed      = common.HexToAddress("0x03")
+	)
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+
+	// The auth contract returns the storage slot keyed by the address argument,
+	// the factory creates an empty contract and returns its address.
+	statedb.SetCode(authContract, common.FromHex("600435"+"54"+"600052"+"60206000f3"))
+	statedb.SetState(authContract, common.BytesToHash(authed.Bytes()), common.BigToHash(common.Big1))
+	statedb.SetCode(factory, common.FromHex("600060006000f0"+"600052"+"60206000f3"))
+
+	config := *params.TestChainConfig
+	config.AuthBlock = big.NewInt(0)
+	config.AuthContract = authContract
+	config.DeployAuth = &params.DeployAuthConfig{Block: big.NewInt(0), Allowlist: []common.Address{allowed}}
+
+	for _, test := range []struct {
+		origin common.Address
+		err    error
+	}{
+		{authed, nil},
+		{allowed, nil},
+		{params.SystemAddress, nil},
+		{unauthed, vm.ErrDeployNotAuth},
+	} {
+		cfg := &Config{ChainConfig: &config, State: statedb, Origin: test.origin}
+		if _, _, _, err := Create(nil, cfg); err != test.err {
+			t.Errorf("origin %x: create error mismatch: have %v, want %v", test.origin, err, test.err)
+		}
+		ret, _, err := Call(factory, nil, cfg)
+		if err != nil {
+			t.Fatalf("origin %x: factory call failed: %v", test.origin, err)
+		}
+		if created := common.BytesToAddress(ret) != (common.Address{}); created != (test.err == nil) {
+			t.Errorf("origin %x: factory creation mismatch: have %v, want %v", test.origin, created, test.err == nil)
+		}
+	}
+}
+
 func BenchmarkCall(b *testing.B) {
 	var definition = `[{"constant":true,"inputs":[],"name":"seller","outputs":[{"name":"","type":"address"}],"type":"function"},{"constant":false,"inputs":[],"name":"abort","outputs":[],"type":"function"},{"constant":true,"inputs":[],"name":"value","outputs":[{"name":"","type":"uint256"}],"type":"function"},{"constant":false,"inputs":[],"name":"refund","outputs":[],"type":"function"},{"constant":true,"inputs":[],"name":"buyer","outputs":[{"name":"","type":"address"}],"type":"function"},{"constant":false,"inputs":[],"name":"confirmReceived","outputs":[],"type":"function"},{"constant":true,"inputs":[],"name":"state","outputs":[{"name":"","type":"uint8"}],"type":"function"},{"constant":false,"inputs":[],"name":"confirmPurchase","outputs":[],"type":"function"},{"inputs":[],"type":"constructor"},{"anonymous":false,"inputs":[],"name":"Aborted","type":"event"},{"anonymous":false,"inputs":[],"name":"PurchaseConfirmed","type":"event"},{"anonymous":false,"inputs":[],"name":"ItemReceived","type":"event"},{"anonymous":false,"inputs":[],"name":"Refunded","type":"event"}]`
 
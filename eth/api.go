@@ -30,9 +30,11 @@ import (
 
 	"github.com/qydata/go-ctereum/common"
 	"github.com/qydata/go-ctereum/common/hexutil"
+	"github.com/qydata/go-ctereum/consensus"
 	"github.com/qydata/go-ctereum/core"
 	"github.com/qydata/go-ctereum/core/rawdb"
 	"github.com/qydata/go-ctereum/core/state"
+	"github.com/qydata/go-ctereum/core/state/snapshot"
 	"github.com/qydata/go-ctereum/core/types"
 	"github.com/qydata/go-ctereum/internal/ethapi"
 	"github.com/qydata/go-ctereum/log"
@@ -236,13 +238,75 @@ func (api *AdminAPI) ImportChain(file string) (bool, error) {
 		}
 		// Import the batch and reset the buffer
 		if _, err := api.eth.BlockChain().InsertChain(blocks); err != nil {
-			return false, fmt.Errorf("batch %d: failed to insert: %v", batch, err)
+			return false, withConsensusCode(fmt.Errorf("batch %d: failed to insert: %w", batch, err))
 		}
 		blocks = blocks[:0]
 	}
 	return true, nil
 }
 
+// codedImportError is a block import error surfacing the code of the consensus
+// failure behind it over RPC.
+type codedImportError struct {
+	err   error
+	coded *consensus.CodedError
+}
+
+func (e *codedImportError) Error() string          { return e.err.Error() }
+func (e *codedImportError) Unwrap() error          { return e.err }
+func (e *codedImportError) ErrorCode() int         { return e.coded.ErrorCode() }
+func (e *codedImportError) ErrorData() interface{} { return e.coded.ErrorData() }
+
+// withConsensusCode attaches the code of the consensus failure wrapped by err,
+// if any, to the error returned over RPC.
+func withConsensusCode(err error) error {
+	if coded, ok := consensus.CodeOf(err); ok {
+		return &codedImportError{err: err, coded: coded}
+	}
+	return err
+}
+
+// ImportFailure is the RPC representation of a block rejected during import.
+type ImportFailure struct {
+	Number hexutil.Uint64 `json:"number"`
+	Hash   common.Hash    `json:"hash"`
+	Time   uint64         `json:"time"`
+	Error  string         `json:"error"`
+	Code   int            `json:"code,omitempty"`
+	Name   string         `json:"name,omitempty"`
+}
+
+// ImportStatus is the block import status of the node.
+type ImportStatus struct {
+	Head        hexutil.Uint64 `json:"head"`
+	HeadHash    common.Hash    `json:"headHash"`
+	LastFailure *ImportFailure `json:"lastFailure"`
+}
+
+// ImportStatus returns the current head of the chain along with the last block
+// rejected during import, carrying the code and name of the consensus failure
+// for errors raised by the consensus engine.
+func (api *AdminAPI) ImportStatus() *ImportStatus {
+	head := api.eth.BlockChain().CurrentBlock()
+	status := &ImportStatus{
+		Head:     hexutil.Uint64(head.NumberU64()),
+		HeadHash: head.Hash(),
+	}
+	if failure := api.eth.BlockChain().LastImportFailure(); failure != nil {
+		status.LastFailure = &ImportFailure{
+			Number: hexutil.Uint64(failure.Number),
+			Hash:   failure.Hash,
+			Time:   uint64(failure.Time.Unix()),
+			Error:  failure.Err.Error(),
+		}
+		if coded, ok := consensus.CodeOf(failure.Err); ok {
+			status.LastFailure.Code = coded.ErrorCode()
+			status.LastFailure.Name = coded.Name()
+		}
+	}
+	return status
+}
+
 // DebugAPI is the collection of Ethereum full node APIs for debugging the
 // protocol.
 type DebugAPI struct {
@@ -295,6 +359,19 @@ func (api *DebugAPI) Preimage(ctx context.Context, hash common.Hash) (hexutil.By
 	return nil, errors.New("unknown preimage")
 }
 
+// SetPreimageRecording enables or disables recording the preimages of the trie
+// keys and of the keccak256 hashes computed by the EVM while importing blocks.
+// The setting is not persisted across restarts.
+func (api *DebugAPI) SetPreimageRecording(enabled bool) {
+	api.eth.BlockChain().SetPreimageRecording(enabled)
+}
+
+// PreimageRecording reports whether preimages are recorded while importing
+// blocks.
+func (api *DebugAPI) PreimageRecording() bool {
+	return api.eth.BlockChain().PreimageRecording()
+}
+
 // BadBlockArgs represents the entries in the list returned when bad blocks are queried.
 type BadBlockArgs struct {
 	Hash  common.Hash            `json:"hash"`
@@ -445,6 +522,306 @@ func storageRangeAt(st state.Trie, start []byte, maxResult int) (StorageRangeRes
 	return result, nil
 }
 
+// StateDiffResult is the result of a debug_getStateDiff API call.
+type StateDiffResult struct {
+	Accounts []*accountDiffResult `json:"accounts"`
+}
+
+type accountDiffResult struct {
+	Address    common.Address       `json:"address"`
+	Prev       *accountStateResult  `json:"prev"` // nil if the account was created by the block
+	Post       *accountStateResult  `json:"post"` // nil if the account was deleted by the block
+	Destructed bool                 `json:"destructed,omitempty"`
+	Storage    []stateDiffSlotEntry `json:"storage,omitempty"`
+}
+
+type accountStateResult struct {
+	Nonce    hexutil.Uint64 `json:"nonce"`
+	Balance  *hexutil.Big   `json:"balance"`
+	CodeHash common.Hash    `json:"codeHash"`
+}
+
+type stateDiffSlotEntry struct {
+	Key  common.Hash `json:"key"`
+	Prev common.Hash `json:"prev"`
+	Post common.Hash `json:"post"`
+}
+
+// GetStateDiff returns the accounts and storage slots changed by the given
+// block, along with their values before and after it. The changes are only
+// available for blocks imported with state diff recording enabled.
+func (api *DebugAPI) GetStateDiff(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (*StateDiffResult, error) {
+	header, err := api.eth.APIBackend.HeaderByNumberOrHash(ctx, blockNrOrHash)
+	if err != nil {
+		return nil, err
+	}
+	if header == nil {
+		return nil, errors.New("block not found")
+	}
+	diff, err := api.eth.blockchain.GetStateDiff(header.Hash())
+	if err != nil {
+		return nil, err
+	}
+	if diff == nil {
+		return nil, fmt.Errorf("state diff of block %#x not recorded", header.Hash())
+	}
+	result := &StateDiffResult{Accounts: make([]*accountDiffResult, 0, len(diff.Accounts))}
+	for _, account := range diff.Accounts {
+		entry := &accountDiffResult{
+			Address:    account.Address,
+			Prev:       newAccountStateResult(account.Prev),
+			Post:       newAccountStateResult(account.Post),
+			Destructed: account.Destructed,
+		}
+		for _, slot := range account.Storage {
+			entry.Storage = append(entry.Storage, stateDiffSlotEntry{Key: slot.Key, Prev: slot.Prev, Post: slot.Post})
+		}
+		result.Accounts = append(result.Accounts, entry)
+	}
+	return result, nil
+}
+
+func newAccountStateResult(account *state.AccountState) *accountStateResult {
+	if account == nil {
+		return nil
+	}
+	return &accountStateResult{
+		Nonce:    hexutil.Uint64(account.Nonce),
+		Balance:  (*hexutil.Big)(account.Balance),
+		CodeHash: account.CodeHash,
+	}
+}
+
+// TxIndexStatus is the status of the transaction indexer, as returned by
+// debug_txIndexStatus.
+type TxIndexStatus struct {
+	Limit     hexutil.Uint64  `json:"limit"` // 0 if all transactions are indexed
+	Tail      *hexutil.Uint64 `json:"tail"`  // nil if the indexing has not started yet
+	Head      hexutil.Uint64  `json:"head"`
+	Remaining hexutil.Uint64  `json:"remaining"`
+	Paused    bool            `json:"paused"`
+	Done      bool            `json:"done"`
+}
+
+// TxIndexStatus returns the progress of the transaction indexer in reaching the
+// configured lookup limit.
+func (api *DebugAPI) TxIndexStatus() (*TxIndexStatus, error) {
+	prog, err := api.eth.blockchain.TxIndexProgress()
+	if err != nil {
+		return nil, err
+	}
+	status := &TxIndexStatus{
+		Limit:     hexutil.Uint64(prog.Limit),
+		Head:      hexutil.Uint64(prog.Head),
+		Remaining: hexutil.Uint64(prog.Remaining),
+		Paused:    prog.Paused,
+		Done:      prog.Done(),
+	}
+	if prog.Tail != nil {
+		status.Tail = (*hexutil.Uint64)(prog.Tail)
+	}
+	return status, nil
+}
+
+// SetTxIndexTail moves the oldest block with indexed transactions, indexing or
+// unindexing blocks in the background. The lookup limit is adjusted to keep the
+// tail at the same distance from the chain head. The change is not persisted.
+func (api *DebugAPI) SetTxIndexTail(block hexutil.Uint64) error {
+	return api.eth.blockchain.SetTxIndexTail(uint64(block))
+}
+
+// SetTxLookupLimit sets the number of recent blocks whose transactions are
+// indexed, 0 meaning all of them. The change is not persisted.
+func (api *DebugAPI) SetTxLookupLimit(limit hexutil.Uint64) error {
+	if _, err := api.eth.blockchain.TxIndexProgress(); err != nil {
+		return err
+	}
+	api.eth.blockchain.SetTxLookupLimit(uint64(limit))
+	return nil
+}
+
+// PauseTxIndexing suspends the transaction indexer, keeping its progress.
+func (api *DebugAPI) PauseTxIndexing() error {
+	return api.eth.blockchain.PauseTxIndexing()
+}
+
+// ResumeTxIndexing continues a suspended transaction indexer.
+func (api *DebugAPI) ResumeTxIndexing() error {
+	return api.eth.blockchain.ResumeTxIndexing()
+}
+
+// ExecutionWitness is the execution witness of a block, as returned by
+// debug_executionWitness.
+type ExecutionWitness struct {
+	Root  common.Hash     `json:"root"`
+	Nodes []hexutil.Bytes `json:"nodes"`
+	Codes []hexutil.Bytes `json:"codes"`
+}
+
+// ExecutionWitness returns the trie nodes and contract codes of the parent state
+// accessed while executing the given block. Witnesses not stored on import are
+// generated by executing the block again, which requires the parent state.
+func (api *DebugAPI) ExecutionWitness(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (*ExecutionWitness, error) {
+	block, err := api.eth.APIBackend.BlockByNumberOrHash(ctx, blockNrOrHash)
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, errors.New("block not found")
+	}
+	if block.NumberU64() == 0 {
+		return nil, errors.New("genesis is not executed")
+	}
+	witness, err := api.eth.blockchain.GetWitness(block)
+	if err != nil {
+		return nil, err
+	}
+	result := &ExecutionWitness{
+		Root:  witness.Root,
+		Nodes: make([]hexutil.Bytes, len(witness.Nodes)),
+		Codes: make([]hexutil.Bytes, len(witness.Codes)),
+	}
+	for i, node := range witness.Nodes {
+		result.Nodes[i] = node
+	}
+	for i, code := range witness.Codes {
+		result.Codes[i] = code
+	}
+	return result, nil
+}
+
+// ReorgHistoryEntry is a chain head event recorded in the reorg journal, as
+// returned by debug_getReorgHistory.
+type ReorgHistoryEntry struct {
+	Seq     hexutil.Uint64      `json:"seq"`
+	Type    string              `json:"type"`
+	Time    hexutil.Uint64      `json:"time"`
+	Dropped []reorgHistoryBlock `json:"dropped"`
+	Added   []reorgHistoryBlock `json:"added"`
+}
+
+type reorgHistoryBlock struct {
+	Number hexutil.Uint64 `json:"number"`
+	Hash   common.Hash    `json:"hash"`
+}
+
+// GetReorgHistory returns up to count of the most recent chain head events
+// (new canonical heads, side forks and reorgs), newest first, along with the
+// blocks each of them dropped from and added to the chain.
+func (api *DebugAPI) GetReorgHistory(count int) ([]*ReorgHistoryEntry, error) {
+	if count <= 0 {
+		return nil, errors.New("count must be positive")
+	}
+	entries := api.eth.blockchain.ReorgHistory(count)
+	result := make([]*ReorgHistoryEntry, 0, len(entries))
+	for _, entry := range entries {
+		result = append(result, &ReorgHistoryEntry{
+			Seq:     hexutil.Uint64(entry.Seq),
+			Type:    entry.Type,
+			Time:    hexutil.Uint64(entry.Time),
+			Dropped: newReorgHistoryBlocks(entry.Dropped),
+			Added:   newReorgHistoryBlocks(entry.Added),
+		})
+	}
+	return result, nil
+}
+
+func newReorgHistoryBlocks(blocks []rawdb.ReorgJournalBlock) []reorgHistoryBlock {
+	result := make([]reorgHistoryBlock, len(blocks))
+	for i, block := range blocks {
+		result[i] = reorgHistoryBlock{Number: hexutil.Uint64(block.Number), Hash: block.Hash}
+	}
+	return result
+}
+
+// SnapshotStatus is the progress of the state snapshot generation, as returned
+// by debug_snapshotStatus.
+type SnapshotStatus struct {
+	Root     common.Hash    `json:"root"`
+	Done     bool           `json:"done"`
+	Marker   hexutil.Bytes  `json:"marker,omitempty"`
+	Accounts hexutil.Uint64 `json:"accounts"`
+	Slots    hexutil.Uint64 `json:"slots"`
+	Dangling hexutil.Uint64 `json:"dangling"`
+	Storage  hexutil.Uint64 `json:"storage"`
+	Elapsed  string         `json:"elapsed"`
+	Progress float64        `json:"progress"`
+	ETA      string         `json:"eta,omitempty"`
+	Paused   bool           `json:"paused"`
+	Rate     hexutil.Uint64 `json:"rate"`
+}
+
+// snapshots returns the state snapshot tree of the chain, or an error if
+// snapshots are disabled.
+func (api *DebugAPI) snapshots() (*snapshot.Tree, error) {
+	snaps := api.eth.blockchain.Snapshots()
+	if snaps == nil {
+		return nil, errors.New("state snapshots disabled")
+	}
+	return snaps, nil
+}
+
+// SnapshotStatus returns the progress of the state snapshot generation.
+func (api *DebugAPI) SnapshotStatus() (*SnapshotStatus, error) {
+	snaps, err := api.snapshots()
+	if err != nil {
+		return nil, err
+	}
+	status, err := snaps.GeneratorStatus()
+	if err != nil {
+		return nil, err
+	}
+	result := &SnapshotStatus{
+		Root:     status.Root,
+		Done:     status.Done,
+		Marker:   status.Marker,
+		Accounts: hexutil.Uint64(status.Accounts),
+		Slots:    hexutil.Uint64(status.Slots),
+		Dangling: hexutil.Uint64(status.Dangling),
+		Storage:  hexutil.Uint64(status.Storage),
+		Elapsed:  common.PrettyDuration(status.Elapsed).String(),
+		Progress: status.Progress,
+		Paused:   status.Paused,
+		Rate:     hexutil.Uint64(status.Rate),
+	}
+	if status.ETA > 0 {
+		result.ETA = common.PrettyDuration(status.ETA).String()
+	}
+	return result, nil
+}
+
+// PauseSnapshotGeneration suspends the state snapshot generation until resumed,
+// freeing up disk IO for block processing and sealing.
+func (api *DebugAPI) PauseSnapshotGeneration() error {
+	snaps, err := api.snapshots()
+	if err != nil {
+		return err
+	}
+	snaps.PauseGeneration()
+	return nil
+}
+
+// ResumeSnapshotGeneration continues a suspended state snapshot generation.
+func (api *DebugAPI) ResumeSnapshotGeneration() error {
+	snaps, err := api.snapshots()
+	if err != nil {
+		return err
+	}
+	snaps.ResumeGeneration()
+	return nil
+}
+
+// SetSnapshotGenerationRate limits the snapshot data written by the generator
+// per second, 0 meaning no limit. The setting is not persisted.
+func (api *DebugAPI) SetSnapshotGenerationRate(rate hexutil.Uint64) error {
+	snaps, err := api.snapshots()
+	if err != nil {
+		return err
+	}
+	snaps.SetGenerationRate(uint64(rate))
+	return nil
+}
+
 // GetModifiedAccountsByNumber returns all accounts that have changed between the
 // two blocks specified. A change is defined as a difference in nonce, balance,
 // code hash, or storage hash.
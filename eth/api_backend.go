@@ -26,12 +26,14 @@ import (
 	"github.com/qydata/go-ctereum/accounts"
 	"github.com/qydata/go-ctereum/common"
 	"github.com/qydata/go-ctereum/consensus"
+	"github.com/qydata/go-ctereum/contracts/authcontroller"
 	"github.com/qydata/go-ctereum/core"
 	"github.com/qydata/go-ctereum/core/bloombits"
 	"github.com/qydata/go-ctereum/core/rawdb"
 	"github.com/qydata/go-ctereum/core/state"
 	"github.com/qydata/go-ctereum/core/types"
 	"github.com/qydata/go-ctereum/core/vm"
+	"github.com/qydata/go-ctereum/eth/authmanager"
 	"github.com/qydata/go-ctereum/eth/gasprice"
 	"github.com/qydata/go-ctereum/ethdb"
 	"github.com/qydata/go-ctereum/event"
@@ -238,6 +240,10 @@ func (b *EthAPIBackend) SubscribeChainHeadEvent(ch chan<- core.ChainHeadEvent) e
 	return b.eth.BlockChain().SubscribeChainHeadEvent(ch)
 }
 
+func (b *EthAPIBackend) SubscribeChain2HeadEvent(ch chan<- core.Chain2HeadEvent) event.Subscription {
+	return b.eth.BlockChain().SubscribeChain2HeadEvent(ch)
+}
+
 func (b *EthAPIBackend) SubscribeChainSideEvent(ch chan<- core.ChainSideEvent) event.Subscription {
 	return b.eth.BlockChain().SubscribeChainSideEvent(ch)
 }
@@ -284,6 +290,10 @@ func (b *EthAPIBackend) TxPoolContentFrom(addr common.Address) (types.Transactio
 	return b.eth.TxPool().ContentFrom(addr)
 }
 
+func (b *EthAPIBackend) TxPoolAuthSummary() (*core.TxPoolAuthSummary, error) {
+	return b.eth.TxPool().AuthSummary()
+}
+
 func (b *EthAPIBackend) TxPool() *core.TxPool {
 	return b.eth.TxPool()
 }
@@ -336,6 +346,14 @@ func (b *EthAPIBackend) RPCTxFeeCap() float64 {
 	return b.eth.config.RPCTxFeeCap
 }
 
+func (b *EthAPIBackend) AuthRegistry() authcontroller.Registry {
+	return b.eth.config.AuthRegistry
+}
+
+func (b *EthAPIBackend) AuthManager() *authmanager.AuthManager {
+	return b.eth.authManager
+}
+
 func (b *EthAPIBackend) BloomStatus() (uint64, uint64) {
 	sections, _, _ := b.eth.bloomIndexer.Sections()
 	return params.BloomBitsBlocks, sections
@@ -0,0 +1,90 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"context"
+	"errors"
+
+	"github.com/qydata/go-ctereum/common"
+	"github.com/qydata/go-ctereum/common/hexutil"
+	"github.com/qydata/go-ctereum/consensus/clique"
+	"github.com/qydata/go-ctereum/core/types"
+	"github.com/qydata/go-ctereum/internal/ethapi"
+	"github.com/qydata/go-ctereum/rpc"
+)
+
+// errPendingNotSealed is returned if the seal of the pending block is requested.
+var errPendingNotSealed = errors.New("pending block is not sealed")
+
+// BlockSealAPI serves the blocks of a clique chain along with the metadata of
+// their seal, sparing clients the recovery of the sealer from the extra-data.
+type BlockSealAPI struct {
+	eth    *Ethereum
+	clique *clique.Clique
+}
+
+// NewBlockSealAPI creates a new block seal API for the given clique engine.
+func NewBlockSealAPI(eth *Ethereum, engine *clique.Clique) *BlockSealAPI {
+	return &BlockSealAPI{eth: eth, clique: engine}
+}
+
+// GetBlockByNumber returns the requested canonical block like
+// eth_getBlockByNumber, with an additional "seal" field holding its sealer,
+// whether it was sealed in-turn, the size of the validator set and the block
+// reward recipient. The seal of the genesis block is null.
+func (api *BlockSealAPI) GetBlockByNumber(ctx context.Context, number rpc.BlockNumber, fullTx bool) (map[string]interface{}, error) {
+	if number == rpc.PendingBlockNumber {
+		return nil, errPendingNotSealed
+	}
+	block, err := api.eth.APIBackend.BlockByNumber(ctx, number)
+	if block == nil || err != nil {
+		return nil, err
+	}
+	return api.marshalBlock(block, fullTx)
+}
+
+// GetBlockByHash returns the requested block like eth_getBlockByHash, with the
+// seal metadata of GetBlockByNumber.
+func (api *BlockSealAPI) GetBlockByHash(ctx context.Context, hash common.Hash, fullTx bool) (map[string]interface{}, error) {
+	block, err := api.eth.APIBackend.BlockByHash(ctx, hash)
+	if block == nil || err != nil {
+		return nil, err
+	}
+	return api.marshalBlock(block, fullTx)
+}
+
+// marshalBlock converts a block into its RPC representation extended with the
+// metadata of its seal.
+func (api *BlockSealAPI) marshalBlock(block *types.Block, fullTx bool) (map[string]interface{}, error) {
+	chain := api.eth.BlockChain()
+
+	fields, err := ethapi.RPCMarshalBlock(block, true, fullTx, chain.Config())
+	if err != nil {
+		return nil, err
+	}
+	fields["totalDifficulty"] = (*hexutil.Big)(chain.GetTd(block.Hash(), block.NumberU64()))
+
+	var seal *clique.SealInfo
+	if block.NumberU64() > 0 {
+		if seal, err = api.clique.SealInfo(chain, block.Header()); err != nil {
+			return nil, err
+		}
+	}
+	fields["seal"] = seal
+	return fields, nil
+}
@@ -0,0 +1,87 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"github.com/qydata/go-ctereum/common"
+	"github.com/qydata/go-ctereum/common/hexutil"
+	"github.com/qydata/go-ctereum/consensus/clique"
+	"github.com/qydata/go-ctereum/params"
+)
+
+// NetworkStatus is a summary of the state of the chain and of the node, meant
+// for monitoring dashboards.
+type NetworkStatus struct {
+	ChainID      *hexutil.Big            `json:"chainId"`
+	Head         hexutil.Uint64          `json:"head"`
+	HeadHash     common.Hash             `json:"headHash"`
+	Forks        map[string]bool         `json:"forks"`      // Activation of the chain specific forks at the head
+	Validators   *clique.ValidatorStatus `json:"validators"` // Validator set at the head, nil if not a clique chain
+	Peers        int                     `json:"peers"`
+	Syncing      bool                    `json:"syncing"`
+	HighestBlock hexutil.Uint64          `json:"highestBlock"`
+}
+
+// NetworkStatusAPI provides a summary of the network in a single call.
+type NetworkStatusAPI struct {
+	eth *Ethereum
+}
+
+// NewNetworkStatusAPI creates a new network status API.
+func NewNetworkStatusAPI(eth *Ethereum) *NetworkStatusAPI {
+	return &NetworkStatusAPI{eth: eth}
+}
+
+// NetworkStatus returns the chain id, the activation of the Poa2Pos and ImplAuth
+// forks at the head, the validator set at the head including its last epoch
+// checkpoint, the number of connected peers and the sync status of the node.
+func (api *NetworkStatusAPI) NetworkStatus() (*NetworkStatus, error) {
+	var (
+		chain    = api.eth.BlockChain()
+		config   = chain.Config()
+		head     = chain.CurrentHeader()
+		progress = api.eth.Downloader().Progress()
+	)
+	status := &NetworkStatus{
+		ChainID:  (*hexutil.Big)(config.ChainID),
+		Head:     hexutil.Uint64(head.Number.Uint64()),
+		HeadHash: head.Hash(),
+		Forks: map[string]bool{
+			"poa2pos":  config.IsPoa2Pos(head.Number),
+			"implAuth": config.IsImplAuth(head.Number),
+		},
+		Peers:        api.eth.handler.peers.len(),
+		Syncing:      progress.CurrentBlock < progress.HighestBlock,
+		HighestBlock: hexutil.Uint64(progress.HighestBlock),
+	}
+	if engine, ok := api.eth.engine.(*clique.Clique); ok {
+		validators, err := engine.ValidatorStatus(chain, head)
+		if err != nil {
+			return nil, err
+		}
+		status.Validators = validators
+	}
+	return status, nil
+}
+
+// ForkReadiness checks the ct fork blocks and contracts of the chain config of
+// the node against the canonical ones of its network, reporting the diverging
+// settings which would split the node off the network.
+func (api *NetworkStatusAPI) ForkReadiness() *params.ForkReadiness {
+	chain := api.eth.BlockChain()
+	return params.CheckForkReadiness(chain.Genesis().Hash(), chain.Config())
+}
@@ -0,0 +1,195 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package authmanager
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/qydata/go-ctereum/common"
+	"github.com/qydata/go-ctereum/common/hexutil"
+	"github.com/qydata/go-ctereum/rpc"
+)
+
+// maxHistoryResults is the maximum number of events returned by a single
+// history query.
+const maxHistoryResults = 10000
+
+// IndexerAPI exposes the indexed auth events.
+type IndexerAPI struct {
+	indexer *Indexer
+}
+
+// NewIndexerAPI creates a new API for the auth event indexer.
+func NewIndexerAPI(indexer *Indexer) *IndexerAPI {
+	return &IndexerAPI{indexer}
+}
+
+// GetAuthHistory returns the Authentication events of the address emitted
+// between the given blocks (inclusive). Only the blocks within the indexed range
+// are covered, see AuthIndexStatus.
+func (api *IndexerAPI) GetAuthHistory(address common.Address, fromBlock rpc.BlockNumber, toBlock rpc.BlockNumber) ([]*AuthEvent, error) {
+	head := api.indexer.chain.CurrentBlock().NumberU64()
+	from, to := resolveBlockNumber(fromBlock, head), resolveBlockNumber(toBlock, head)
+	if from > to {
+		return nil, errors.New("invalid block range")
+	}
+	events, err := api.indexer.History(address, from, to, maxHistoryResults+1)
+	if err != nil {
+		return nil, err
+	}
+	if len(events) > maxHistoryResults {
+		return nil, errors.New("query returned more than 10000 results")
+	}
+	return events, nil
+}
+
+// AuthIndexStatus returns the range of blocks indexed and the backfill state.
+func (api *IndexerAPI) AuthIndexStatus() *BackfillStatus {
+	return api.indexer.Status()
+}
+
+// IndexerAdminAPI controls the backfill of the auth event indexer and exports
+// its snapshots to the local filesystem.
+type IndexerAdminAPI struct {
+	indexer *Indexer
+}
+
+// NewIndexerAdminAPI creates a new admin API for the auth event indexer.
+func NewIndexerAdminAPI(indexer *Indexer) *IndexerAdminAPI {
+	return &IndexerAdminAPI{indexer}
+}
+
+// StartAuthBackfill starts indexing the auth events from the given block up to
+// the first block already indexed.
+func (api *IndexerAdminAPI) StartAuthBackfill(from hexutil.Uint64) error {
+	return api.indexer.StartBackfill(uint64(from))
+}
+
+// StopAuthBackfill interrupts the running backfill, which can be resumed by
+// starting it again.
+func (api *IndexerAdminAPI) StopAuthBackfill() error {
+	return api.indexer.StopBackfill()
+}
+
+// ExportAuthIndex writes a snapshot of all addresses authenticated as of the
+// given block, the latest one if omitted, into the file in the given format,
+// csv or json. The file is compressed if its name ends in .gz. The number of
+// exported addresses is returned.
+func (api *IndexerAdminAPI) ExportAuthIndex(file string, format string, block *rpc.BlockNumber) (int, error) {
+	number := api.indexer.chain.CurrentBlock().NumberU64()
+	if block != nil {
+		number = resolveBlockNumber(*block, number)
+	}
+	if _, err := os.Stat(file); err == nil {
+		// Periodic exports are expected to be written to fresh files, refuse to
+		// truncate an existing one picked by mistake instead of a report.
+		return 0, errors.New("location would overwrite an existing file")
+	}
+	out, err := os.OpenFile(file, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.ModePerm)
+	if err != nil {
+		return 0, err
+	}
+	defer out.Close()
+
+	var writer io.Writer = out
+	if strings.HasSuffix(file, ".gz") {
+		writer = gzip.NewWriter(writer)
+		defer writer.(*gzip.Writer).Close()
+	}
+	return api.indexer.Export(writer, format, number)
+}
+
+// ManagerAPI exposes the authentication statuses tracked by the auth manager.
+type ManagerAPI struct {
+	manager *AuthManager
+}
+
+// NewManagerAPI creates a new API for the auth manager.
+func NewManagerAPI(manager *AuthManager) *ManagerAPI {
+	return &ManagerAPI{manager}
+}
+
+// GetAuthValidity returns whether the address is authenticated as of the head
+// block and, if the authentication expires, the remaining validity in seconds
+// and in estimated blocks. The optional controller selects a registered auth
+// contract by label instead of the one of the chain config.
+func (api *ManagerAPI) GetAuthValidity(address common.Address, controller *string) (*AuthValidity, error) {
+	return api.manager.Validity(address, label(controller))
+}
+
+// ManagerAdminAPI exposes the order ID allocation of the auth manager, which
+// persists its counter and probes the auth contract.
+type ManagerAdminAPI struct {
+	manager *AuthManager
+}
+
+// NewManagerAdminAPI creates a new admin API for the auth manager.
+func NewManagerAdminAPI(manager *AuthManager) *ManagerAdminAPI {
+	return &ManagerAdminAPI{manager}
+}
+
+// NextOrderId allocates an order ID for an authentication submission that is
+// neither used in the auth contract nor handed out before by this node. The
+// optional controller selects a registered auth contract by label.
+func (api *ManagerAdminAPI) NextOrderId(controller *string) (*hexutil.Big, error) {
+	id, err := api.manager.NextOrderID(label(controller))
+	if err != nil {
+		return nil, err
+	}
+	return (*hexutil.Big)(id), nil
+}
+
+// LightManagerAPI exposes the authentication statuses resolved by the light
+// auth manager.
+type LightManagerAPI struct {
+	manager *LightManager
+}
+
+// NewLightManagerAPI creates a new API for the light auth manager.
+func NewLightManagerAPI(manager *LightManager) *LightManagerAPI {
+	return &LightManagerAPI{manager}
+}
+
+// GetAuthValidity returns whether the address is authenticated as of the head
+// block. The remaining validity is not known to light clients. The optional
+// controller selects a registered auth contract by label instead of the one of
+// the chain config.
+func (api *LightManagerAPI) GetAuthValidity(ctx context.Context, address common.Address, controller *string) (*AuthValidity, error) {
+	return api.manager.Validity(ctx, address, label(controller))
+}
+
+// label dereferences an optional auth controller label.
+func label(controller *string) string {
+	if controller == nil {
+		return ""
+	}
+	return *controller
+}
+
+// resolveBlockNumber converts a block number into an absolute one, resolving
+// the special ones against the head.
+func resolveBlockNumber(number rpc.BlockNumber, head uint64) uint64 {
+	if number < 0 {
+		return head
+	}
+	return uint64(number)
+}
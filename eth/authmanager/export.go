@@ -0,0 +1,216 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package authmanager
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/qydata/go-ctereum/common"
+	"github.com/qydata/go-ctereum/common/hexutil"
+	"github.com/qydata/go-ctereum/core/rawdb"
+	"github.com/qydata/go-ctereum/log"
+	"github.com/qydata/go-ctereum/rlp"
+)
+
+// exportReportInterval is the time between two export progress reports.
+const exportReportInterval = 8 * time.Second
+
+// Snapshot formats supported by Export.
+const (
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+)
+
+// AuthSnapshotEntry is an authenticated address in an auth index snapshot.
+type AuthSnapshotEntry struct {
+	Address common.Address `json:"address"`
+	Level   hexutil.Uint64 `json:"level"`
+	Expiry  hexutil.Uint64 `json:"expiry"` // Unix time the authentication expires at, zero if never
+	Block   hexutil.Uint64 `json:"block"`  // Block of the event that authenticated the address
+}
+
+// snapshotWriter streams the entries of a snapshot in one of the formats.
+type snapshotWriter interface {
+	write(entry *AuthSnapshotEntry) error
+	close() error
+}
+
+// csvSnapshotWriter writes a snapshot as CSV with a header row.
+type csvSnapshotWriter struct {
+	w *csv.Writer
+}
+
+func newCSVSnapshotWriter(w io.Writer) (*csvSnapshotWriter, error) {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"address", "level", "expiry", "block"}); err != nil {
+		return nil, err
+	}
+	return &csvSnapshotWriter{cw}, nil
+}
+
+func (w *csvSnapshotWriter) write(entry *AuthSnapshotEntry) error {
+	return w.w.Write([]string{
+		entry.Address.Hex(),
+		strconv.FormatUint(uint64(entry.Level), 10),
+		strconv.FormatUint(uint64(entry.Expiry), 10),
+		strconv.FormatUint(uint64(entry.Block), 10),
+	})
+}
+
+func (w *csvSnapshotWriter) close() error {
+	w.w.Flush()
+	return w.w.Error()
+}
+
+// jsonSnapshotWriter writes a snapshot as a JSON array, one entry per line.
+type jsonSnapshotWriter struct {
+	w     *bufio.Writer
+	count int
+}
+
+func newJSONSnapshotWriter(w io.Writer) (*jsonSnapshotWriter, error) {
+	bw := bufio.NewWriter(w)
+	if _, err := bw.WriteString("["); err != nil {
+		return nil, err
+	}
+	return &jsonSnapshotWriter{w: bw}, nil
+}
+
+func (w *jsonSnapshotWriter) write(entry *AuthSnapshotEntry) error {
+	blob, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	sep := ",\n"
+	if w.count == 0 {
+		sep = "\n"
+	}
+	w.count++
+	if _, err := w.w.WriteString(sep); err != nil {
+		return err
+	}
+	_, err = w.w.Write(blob)
+	return err
+}
+
+func (w *jsonSnapshotWriter) close() error {
+	if _, err := w.w.WriteString("\n]\n"); err != nil {
+		return err
+	}
+	return w.w.Flush()
+}
+
+// Export writes a snapshot of all addresses authenticated as of the given block
+// in the given format, along with their level and expiry, returning the number
+// of addresses exported. Authentications expired by the time of the block are
+// left out. The snapshot is derived from the indexed events, so the indexed
+// range must cover all blocks since the auth fork up to the given one.
+func (idx *Indexer) Export(w io.Writer, format string, number uint64) (int, error) {
+	header := idx.chain.GetHeaderByNumber(number)
+	if header == nil {
+		return 0, fmt.Errorf("%w #%d", errUnknownBlock, number)
+	}
+	// The genesis block carries no events
+	first := uint64(1)
+	if authBlock := idx.chain.Config().AuthBlock; authBlock != nil && authBlock.Uint64() > first {
+		first = authBlock.Uint64()
+	}
+	idx.lock.Lock()
+	tail, head := idx.tail, idx.head
+	idx.lock.Unlock()
+	if tail > first {
+		return 0, fmt.Errorf("auth index incomplete, blocks %d to %d need to be backfilled", first, tail-1)
+	}
+	if number > head {
+		return 0, fmt.Errorf("block #%d not indexed yet, head is #%d", number, head)
+	}
+	var (
+		out snapshotWriter
+		err error
+	)
+	switch format {
+	case FormatCSV:
+		out, err = newCSVSnapshotWriter(w)
+	case FormatJSON:
+		out, err = newJSONSnapshotWriter(w)
+	default:
+		return 0, fmt.Errorf("unknown snapshot format %q", format)
+	}
+	if err != nil {
+		return 0, err
+	}
+	var (
+		start    = time.Now()
+		reported = time.Now()
+		count    int
+
+		last   *AuthEvent // Latest event of the current address up to the block
+		lastTo common.Address
+	)
+	// flush exports the current address if its latest event authenticated it
+	flush := func() error {
+		if last == nil || !last.IsAuth || (last.Expiry != 0 && uint64(last.Expiry) <= header.Time) {
+			return nil
+		}
+		count++
+		return out.write(&AuthSnapshotEntry{
+			Address: lastTo,
+			Level:   last.Level,
+			Expiry:  last.Expiry,
+			Block:   last.Block,
+		})
+	}
+	err = rawdb.IterateAuthEvents(idx.db, func(addr common.Address, block uint64, blob []byte) error {
+		if addr != lastTo {
+			if err := flush(); err != nil {
+				return err
+			}
+			last, lastTo = nil, addr
+		}
+		if block > number {
+			return nil
+		}
+		event := new(AuthEvent)
+		if err := rlp.DecodeBytes(blob, event); err != nil {
+			return err
+		}
+		last = event
+
+		if time.Since(reported) >= exportReportInterval {
+			log.Info("Exporting auth index", "exported", count, "elapsed", common.PrettyDuration(time.Since(start)))
+			reported = time.Now()
+		}
+		return nil
+	})
+	if err == nil {
+		err = flush()
+	}
+	if err != nil {
+		return count, err
+	}
+	if err := out.close(); err != nil {
+		return count, err
+	}
+	log.Info("Exported auth index", "block", number, "addresses", count, "elapsed", common.PrettyDuration(time.Since(start)))
+	return count, nil
+}
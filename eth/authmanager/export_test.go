@@ -0,0 +1,106 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package authmanager
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/qydata/go-ctereum/common"
+	"github.com/qydata/go-ctereum/contracts/authcontroller/contract"
+	"github.com/qydata/go-ctereum/core/rawdb"
+)
+
+func TestExport(t *testing.T) {
+	chain, db, _ := newTestChainWithCode(t, logCode)
+	defer chain.Stop()
+
+	idx, err := NewIndexer(chain, db)
+	if err != nil {
+		t.Fatalf("failed to create indexer: %v", err)
+	}
+	if err := idx.Start(); err != nil {
+		t.Fatalf("failed to start indexer: %v", err)
+	}
+	defer idx.Stop()
+
+	expiredAddr := common.HexToAddress("0xdead")
+	auth := func(addr common.Address, expiry int64, level int64) contract.AuthControllerAuthData {
+		return contract.AuthControllerAuthData{
+			Caddress:   addr,
+			Sender:     testAddr,
+			AuthTime:   new(big.Int),
+			AuthExpiry: big.NewInt(expiry),
+			IsAuth:     true,
+			AuthLevel:  big.NewInt(level),
+		}
+	}
+	// The generated blocks are 10 seconds apart
+	blocks := makeEventBlocks(t, chain, db, chain.Genesis(), 5, 0, map[int][]byte{
+		0: authEventCall(t, contract.AuthControllerMetaData, authedAddr, auth(authedAddr, 200, 3)),
+		1: authEventCall(t, contract.AuthControllerV1MetaData, otherAddr, contract.AuthControllerV1AuthData{Caddress: otherAddr, Sender: testAddr, IsAuth: true}),
+		2: authEventCall(t, contract.AuthControllerMetaData, expiredAddr, auth(expiredAddr, 20, 1)),
+		3: authEventCall(t, contract.AuthControllerV1MetaData, otherAddr, contract.AuthControllerV1AuthData{Caddress: otherAddr, Sender: testAddr}),
+	})
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	waitIndexed(t, idx, 5)
+
+	var buf bytes.Buffer
+	if n, err := idx.Export(&buf, FormatCSV, 3); err != nil || n != 2 {
+		t.Fatalf("failed to export: count %d, err %v", n, err)
+	}
+	want := fmt.Sprintf("address,level,expiry,block\n%s,3,200,1\n%s,0,0,2\n", authedAddr.Hex(), otherAddr.Hex())
+	if buf.String() != want {
+		t.Errorf("csv snapshot mismatch:\nhave %q\nwant %q", buf.String(), want)
+	}
+	// The revocation drops the address from later snapshots
+	buf.Reset()
+	if n, err := idx.Export(&buf, FormatJSON, 5); err != nil || n != 1 {
+		t.Fatalf("failed to export: count %d, err %v", n, err)
+	}
+	var entries []*AuthSnapshotEntry
+	if err := json.Unmarshal(buf.Bytes(), &entries); err != nil {
+		t.Fatalf("failed to parse json snapshot: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Address != authedAddr || entries[0].Level != 3 || entries[0].Expiry != 200 {
+		t.Errorf("json snapshot mismatch: %+v", entries)
+	}
+	if _, err := idx.Export(&buf, "xml", 5); err == nil {
+		t.Errorf("exported in unknown format")
+	}
+	if _, err := idx.Export(&buf, FormatCSV, 6); err == nil {
+		t.Errorf("exported beyond the head")
+	}
+	// Snapshots are refused until the history is backfilled
+	fresh, err := NewIndexer(chain, rawdb.NewMemoryDatabase())
+	if err != nil {
+		t.Fatalf("failed to create indexer: %v", err)
+	}
+	if err := fresh.Start(); err != nil {
+		t.Fatalf("failed to start indexer: %v", err)
+	}
+	defer fresh.Stop()
+
+	if _, err := fresh.Export(&buf, FormatCSV, 5); err == nil {
+		t.Errorf("exported from incomplete index")
+	}
+}
@@ -0,0 +1,425 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package authmanager
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/qydata/go-ctereum/accounts/abi/bind"
+	"github.com/qydata/go-ctereum/common"
+	"github.com/qydata/go-ctereum/common/hexutil"
+	"github.com/qydata/go-ctereum/contracts/authcontroller"
+	"github.com/qydata/go-ctereum/contracts/authcontroller/contract"
+	"github.com/qydata/go-ctereum/core"
+	"github.com/qydata/go-ctereum/core/rawdb"
+	"github.com/qydata/go-ctereum/core/types"
+	"github.com/qydata/go-ctereum/ethdb"
+	"github.com/qydata/go-ctereum/event"
+	"github.com/qydata/go-ctereum/log"
+	"github.com/qydata/go-ctereum/rlp"
+)
+
+// backfillLogInterval is the number of blocks between two backfill progress
+// reports and database checkpoints.
+const backfillLogInterval = 10000
+
+var (
+	errBackfillRunning    = errors.New("backfill already running")
+	errBackfillNotRunning = errors.New("backfill not running")
+	errAlreadyIndexed     = errors.New("blocks already indexed")
+)
+
+// AuthEvent is an Authentication event of the auth contract, as indexed.
+type AuthEvent struct {
+	Caddress common.Address `json:"caddress"`
+	Sender   common.Address `json:"sender"`
+	IsAuth   bool           `json:"isAuth"`
+	Level    hexutil.Uint64 `json:"level"`
+	Expiry   hexutil.Uint64 `json:"expiry"`
+	Block    hexutil.Uint64 `json:"block"`
+	TxHash   common.Hash    `json:"transactionHash"`
+	Index    hexutil.Uint   `json:"logIndex"`
+}
+
+// BackfillStatus is the progress of the auth event indexing.
+type BackfillStatus struct {
+	Running bool            `json:"running"`
+	Target  *hexutil.Uint64 `json:"target"` // First block the backfill indexes, nil if none requested
+	Tail    hexutil.Uint64  `json:"tail"`   // First block indexed
+	Head    hexutil.Uint64  `json:"head"`   // Last block indexed
+}
+
+// Indexer indexes the Authentication events of all auth contract revisions by
+// the authenticated address. New blocks are indexed as they are imported,
+// while older history is indexed on demand by a resumable backfill.
+type Indexer struct {
+	chain   *core.BlockChain
+	db      ethdb.Database
+	parsers map[common.Hash]*authcontroller.AuthController // Authentication event ID -> contract revision parsing it
+
+	lock         sync.Mutex
+	tail, head   uint64        // Range of blocks indexed continuously
+	backfill     chan struct{} // Quit channel of the running backfill, nil if none
+	backfillDone chan struct{} // Closed when the running backfill terminates
+
+	headCh  chan core.Chain2HeadEvent
+	headSub event.Subscription
+	quit    chan struct{}
+	wg      sync.WaitGroup
+}
+
+// NewIndexer creates an auth event indexer for the given chain.
+func NewIndexer(chain *core.BlockChain, db ethdb.Database) (*Indexer, error) {
+	idx := &Indexer{
+		chain:   chain,
+		db:      db,
+		parsers: make(map[common.Hash]*authcontroller.AuthController),
+		headCh:  make(chan core.Chain2HeadEvent, chainHeadChanSize),
+		quit:    make(chan struct{}),
+	}
+	for version, meta := range map[authcontroller.Version]*bind.MetaData{
+		authcontroller.V1: contract.AuthControllerV1MetaData,
+		authcontroller.V2: contract.AuthControllerMetaData,
+	} {
+		parsed, err := meta.GetAbi()
+		if err != nil {
+			return nil, err
+		}
+		// The parsers never interact with the contract, so neither a backend
+		// nor an address is needed.
+		parser, err := authcontroller.NewAuthController(common.Address{}, nil, version)
+		if err != nil {
+			return nil, err
+		}
+		idx.parsers[parsed.Events["Authentication"].ID] = parser
+	}
+	return idx, nil
+}
+
+// Start implements node.Lifecycle, starting the live indexing and any interrupted
+// backfill. The blocks imported while the node was down are indexed in the
+// background, the head events being subscribed to first so that none of the
+// blocks imported meanwhile is missed.
+func (idx *Indexer) Start() error {
+	head := idx.chain.CurrentBlock().NumberU64()
+
+	tail, last, ok := rawdb.ReadAuthIndexProgress(idx.db)
+	if !ok || last > head || head-last > maxCatchUpBlocks {
+		// Start indexing from the head, older blocks can be backfilled
+		tail, last = head+1, head
+	}
+	idx.headSub = idx.chain.SubscribeChain2HeadEvent(idx.headCh)
+
+	idx.tail, idx.head = tail, last
+	rawdb.WriteAuthIndexProgress(idx.db, idx.tail, idx.head)
+
+	idx.wg.Add(1)
+	go idx.loop()
+
+	if target, ok := rawdb.ReadAuthBackfill(idx.db); ok {
+		idx.lock.Lock()
+		if target < idx.tail {
+			idx.startBackfill(target)
+		} else {
+			rawdb.DeleteAuthBackfill(idx.db)
+		}
+		idx.lock.Unlock()
+	}
+	log.Info("Started auth event indexer", "tail", tail, "head", last, "catchup", head-last)
+	return nil
+}
+
+// Stop implements node.Lifecycle, terminating the live indexing and the
+// running backfill, if any.
+func (idx *Indexer) Stop() error {
+	if err := idx.StopBackfill(); err != nil && err != errBackfillNotRunning {
+		return err
+	}
+	close(idx.quit)
+	idx.wg.Wait()
+	return nil
+}
+
+// loop indexes the chain head events until the indexer is stopped. While the
+// indexed head lags behind the chain, e.g. after a restart or a gap in the head
+// events, the missing blocks are indexed one at a time in between the events.
+func (idx *Indexer) loop() {
+	defer idx.wg.Done()
+	defer idx.headSub.Unsubscribe()
+
+	for {
+		// The indexed head is only moved by this goroutine, no need to lock
+		if next := idx.head + 1; next <= idx.chain.CurrentBlock().NumberU64() {
+			select {
+			case ev := <-idx.headCh:
+				idx.handleHead(ev)
+				continue
+			case <-idx.headSub.Err():
+				return
+			case <-idx.quit:
+				return
+			default:
+			}
+			if block := idx.chain.GetBlockByNumber(next); block != nil {
+				batch := idx.db.NewBatch()
+				idx.processBlock(batch, block)
+				idx.commitHead(batch, next)
+				continue
+			}
+			log.Warn("Missing block for auth indexing", "number", next)
+		}
+		select {
+		case ev := <-idx.headCh:
+			idx.handleHead(ev)
+		case <-idx.headSub.Err():
+			return
+		case <-idx.quit:
+			return
+		}
+	}
+}
+
+// handleHead indexes the blocks of a chain head event following the indexed
+// head, after dropping the events of the blocks it removed from the canonical
+// chain. The blocks past a gap are left to be caught up with by number.
+func (idx *Indexer) handleHead(ev core.Chain2HeadEvent) {
+	if ev.Type == core.Chain2HeadForkEvent || len(ev.NewChain) == 0 {
+		return
+	}
+	var (
+		batch = idx.db.NewBatch()
+		head  = idx.head
+	)
+	for _, block := range ev.OldChain {
+		if block.NumberU64() <= head {
+			idx.revertBlock(batch, block)
+		}
+	}
+	// Rewind to the fork point, the new chain segment is ordered tip first
+	if len(ev.OldChain) > 0 {
+		if fork := ev.NewChain[len(ev.NewChain)-1].NumberU64() - 1; fork < head {
+			head = fork
+		}
+	}
+	for i := len(ev.NewChain) - 1; i >= 0; i-- {
+		number := ev.NewChain[i].NumberU64()
+		if number <= head {
+			continue // Already caught up with
+		}
+		if number > head+1 {
+			break
+		}
+		idx.processBlock(batch, ev.NewChain[i])
+		head = number
+	}
+	idx.commitHead(batch, head)
+}
+
+// commitHead writes the batch of indexed events along with the new indexed head.
+func (idx *Indexer) commitHead(batch ethdb.Batch, head uint64) {
+	idx.lock.Lock()
+	defer idx.lock.Unlock()
+
+	idx.head = head
+	rawdb.WriteAuthIndexProgress(batch, idx.tail, idx.head)
+	if err := batch.Write(); err != nil {
+		log.Crit("Failed to write auth events", "err", err)
+	}
+}
+
+// History returns the indexed Authentication events of the address emitted
+// between the given blocks (inclusive). At most limit events are returned if
+// limit is positive.
+func (idx *Indexer) History(addr common.Address, from, to uint64, limit int) ([]*AuthEvent, error) {
+	var events []*AuthEvent
+	for _, blob := range rawdb.ReadAuthEvents(idx.db, addr, from, to, limit) {
+		event := new(AuthEvent)
+		if err := rlp.DecodeBytes(blob, event); err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// Status returns the progress of the indexing.
+func (idx *Indexer) Status() *BackfillStatus {
+	idx.lock.Lock()
+	defer idx.lock.Unlock()
+
+	status := &BackfillStatus{
+		Running: idx.backfill != nil,
+		Tail:    hexutil.Uint64(idx.tail),
+		Head:    hexutil.Uint64(idx.head),
+	}
+	if target, ok := rawdb.ReadAuthBackfill(idx.db); ok {
+		status.Target = (*hexutil.Uint64)(&target)
+	}
+	return status
+}
+
+// StartBackfill starts indexing the blocks from the given one up to the first
+// block already indexed. The blocks are indexed backwards, extending the
+// indexed range as they go, so an interrupted backfill can always be resumed.
+func (idx *Indexer) StartBackfill(from uint64) error {
+	idx.lock.Lock()
+	defer idx.lock.Unlock()
+
+	if idx.backfill != nil {
+		return errBackfillRunning
+	}
+	if from >= idx.tail {
+		return errAlreadyIndexed
+	}
+	rawdb.WriteAuthBackfill(idx.db, from)
+	idx.startBackfill(from)
+	return nil
+}
+
+// StopBackfill interrupts the running backfill, which can be resumed later.
+func (idx *Indexer) StopBackfill() error {
+	idx.lock.Lock()
+	quit, done := idx.backfill, idx.backfillDone
+	idx.lock.Unlock()
+
+	if quit == nil {
+		return errBackfillNotRunning
+	}
+	close(quit)
+	<-done
+	return nil
+}
+
+// startBackfill starts the backfill down to the given block in the background.
+// The caller must hold the lock.
+func (idx *Indexer) startBackfill(target uint64) {
+	quit, done := make(chan struct{}), make(chan struct{})
+	idx.backfill, idx.backfillDone = quit, done
+
+	go func() {
+		defer close(done)
+		idx.runBackfill(target, quit)
+
+		idx.lock.Lock()
+		idx.backfill, idx.backfillDone = nil, nil
+		idx.lock.Unlock()
+	}()
+	log.Info("Started auth event backfill", "tail", idx.tail, "target", target)
+}
+
+// runBackfill indexes the blocks below the indexed range down to the target
+// until done or interrupted, checkpointing the progress into the database.
+func (idx *Indexer) runBackfill(target uint64, quit chan struct{}) {
+	idx.lock.Lock()
+	tail := idx.tail
+	idx.lock.Unlock()
+
+	batch := idx.db.NewBatch()
+	flush := func() {
+		idx.lock.Lock()
+		idx.tail = tail
+		rawdb.WriteAuthIndexProgress(batch, idx.tail, idx.head)
+		if err := batch.Write(); err != nil {
+			log.Crit("Failed to write auth events", "err", err)
+		}
+		idx.lock.Unlock()
+		batch.Reset()
+	}
+	for tail > target {
+		select {
+		case <-quit:
+			flush()
+			log.Info("Interrupted auth event backfill", "tail", tail, "target", target)
+			return
+		default:
+		}
+		block := idx.chain.GetBlockByNumber(tail - 1)
+		if block == nil {
+			flush()
+			log.Error("Missing block for auth event backfill", "number", tail-1)
+			return
+		}
+		idx.processBlock(batch, block)
+		tail--
+
+		if batch.ValueSize() > ethdb.IdealBatchSize || tail%backfillLogInterval == 0 {
+			flush()
+			log.Info("Backfilling auth events", "tail", tail, "target", target)
+		}
+	}
+	rawdb.DeleteAuthBackfill(batch)
+	flush()
+	log.Info("Finished auth event backfill", "tail", tail)
+}
+
+// processBlock indexes the Authentication events of the given block.
+func (idx *Indexer) processBlock(db ethdb.KeyValueWriter, block *types.Block) {
+	idx.scanBlock(block, func(event *AuthEvent, blob []byte) {
+		rawdb.WriteAuthEvent(db, event.Caddress, uint64(event.Block), uint32(event.Index), blob)
+	})
+}
+
+// revertBlock drops the Authentication events of a block removed from the
+// canonical chain.
+func (idx *Indexer) revertBlock(db ethdb.KeyValueWriter, block *types.Block) {
+	idx.scanBlock(block, func(event *AuthEvent, blob []byte) {
+		rawdb.DeleteAuthEvent(db, event.Caddress, uint64(event.Block), uint32(event.Index))
+	})
+}
+
+// scanBlock invokes the callback for every Authentication event of the auth
+// contract in the given block.
+func (idx *Indexer) scanBlock(block *types.Block, fn func(event *AuthEvent, blob []byte)) {
+	contractAddr := idx.chain.Config().AuthContractAt(block.Number())
+	if contractAddr == (common.Address{}) || !types.BloomLookup(block.Bloom(), contractAddr) {
+		return
+	}
+	for _, receipt := range idx.chain.GetReceiptsByHash(block.Hash()) {
+		for _, l := range receipt.Logs {
+			if l.Address != contractAddr || len(l.Topics) == 0 {
+				continue
+			}
+			parser, ok := idx.parsers[l.Topics[0]]
+			if !ok {
+				continue
+			}
+			auth, err := parser.ParseAuthentication(*l)
+			if err != nil {
+				log.Warn("Failed to parse auth event", "number", block.NumberU64(), "index", l.Index, "err", err)
+				continue
+			}
+			event := &AuthEvent{
+				Caddress: auth.Caddress,
+				Sender:   auth.Sender,
+				IsAuth:   auth.IsAuth,
+				Level:    hexutil.Uint64(auth.Level()),
+				Block:    hexutil.Uint64(block.NumberU64()),
+				TxHash:   l.TxHash,
+				Index:    hexutil.Uint(l.Index),
+			}
+			if auth.AuthExpiry != nil && auth.AuthExpiry.IsUint64() {
+				event.Expiry = hexutil.Uint64(auth.AuthExpiry.Uint64())
+			}
+			blob, err := rlp.EncodeToBytes(event)
+			if err != nil {
+				log.Crit("Failed to RLP encode auth event", "err", err)
+			}
+			fn(event, blob)
+		}
+	}
+}
@@ -0,0 +1,323 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package authmanager
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/qydata/go-ctereum/accounts/abi/bind"
+	"github.com/qydata/go-ctereum/common"
+	"github.com/qydata/go-ctereum/consensus/ethash"
+	"github.com/qydata/go-ctereum/contracts/authcontroller/contract"
+	"github.com/qydata/go-ctereum/core"
+	"github.com/qydata/go-ctereum/core/rawdb"
+	"github.com/qydata/go-ctereum/core/types"
+	"github.com/qydata/go-ctereum/ethdb"
+)
+
+// logCode is a stand-in for the AuthController emitting raw events. Calls are
+// treated as an (address, topic, data) triplet, emitting a log with the topic
+// and the address as topics and the rest of the call data as data.
+var logCode = common.FromHex(
+	"604036038060406000" + "37" + // calldatacopy(0, 64, calldatasize - 64)
+		"600035" + "602035" + "82" + "6000" + "a2" + "00", // log2(0, calldatasize - 64, topic, addr)
+)
+
+// authEventCall packs the call data making logCode emit an Authentication
+// event of the contract described by the metadata.
+func authEventCall(t *testing.T, meta *bind.MetaData, caddress common.Address, data interface{}) []byte {
+	parsed, err := meta.GetAbi()
+	if err != nil {
+		t.Fatalf("failed to parse abi: %v", err)
+	}
+	event := parsed.Events["Authentication"]
+	packed, err := event.Inputs.NonIndexed().Pack(data)
+	if err != nil {
+		t.Fatalf("failed to pack event: %v", err)
+	}
+	call := append(common.LeftPadBytes(caddress.Bytes(), 32), event.ID.Bytes()...)
+	return append(call, packed...)
+}
+
+// makeEventBlocks generates n blocks on top of the parent, sending the calls
+// listed for a block to the auth contract.
+func makeEventBlocks(t *testing.T, chain *core.BlockChain, db ethdb.Database, parent *types.Block, n int, nonce uint64, calls map[int][]byte) []*types.Block {
+	blocks, _ := core.GenerateChain(chain.Config(), parent, ethash.NewFaker(), db, n, func(i int, b *core.BlockGen) {
+		b.SetCoinbase(common.Address{0x01})
+		data, ok := calls[i]
+		if !ok {
+			return
+		}
+		tx, err := types.SignTx(types.NewTransaction(nonce, authAddr, new(big.Int), 200000, b.BaseFee(), data), types.LatestSigner(chain.Config()), testKey)
+		if err != nil {
+			t.Fatalf("failed to sign tx: %v", err)
+		}
+		nonce++
+		b.AddTx(tx)
+	})
+	return blocks
+}
+
+// waitIndexed waits until the indexer processed the events up to the number.
+func waitIndexed(t *testing.T, idx *Indexer, number uint64) {
+	for i := 0; i < 100; i++ {
+		if status := idx.Status(); uint64(status.Head) >= number {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("indexer did not reach block %d", number)
+}
+
+// waitBackfilled waits until the running backfill terminated.
+func waitBackfilled(t *testing.T, idx *Indexer) {
+	for i := 0; i < 100; i++ {
+		if !idx.Status().Running {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("backfill did not terminate")
+}
+
+func checkHistory(t *testing.T, idx *Indexer, addr common.Address, want []uint64) []*AuthEvent {
+	t.Helper()
+	events, err := idx.History(addr, 0, 100, 0)
+	if err != nil {
+		t.Fatalf("failed to retrieve history: %v", err)
+	}
+	if len(events) != len(want) {
+		t.Fatalf("history length mismatch: have %d, want %d", len(events), len(want))
+	}
+	for i, event := range events {
+		if uint64(event.Block) != want[i] {
+			t.Errorf("event %d: block mismatch: have %d, want %d", i, event.Block, want[i])
+		}
+		if event.Caddress != addr {
+			t.Errorf("event %d: address mismatch: have %x, want %x", i, event.Caddress, addr)
+		}
+	}
+	return events
+}
+
+func TestIndexer(t *testing.T) {
+	chain, db, _ := newTestChainWithCode(t, logCode)
+	defer chain.Stop()
+
+	idx, err := NewIndexer(chain, db)
+	if err != nil {
+		t.Fatalf("failed to create indexer: %v", err)
+	}
+	if err := idx.Start(); err != nil {
+		t.Fatalf("failed to start indexer: %v", err)
+	}
+	defer idx.Stop()
+
+	v2 := contract.AuthControllerAuthData{
+		Caddress:   authedAddr,
+		Sender:     testAddr,
+		Signature:  []byte{0x01},
+		AuthTime:   big.NewInt(100),
+		AuthExpiry: big.NewInt(200),
+		IsAuth:     true,
+		AuthLevel:  big.NewInt(3),
+	}
+	v1 := contract.AuthControllerV1AuthData{Caddress: otherAddr, Sender: testAddr, IsAuth: true}
+	blocks := makeEventBlocks(t, chain, db, chain.Genesis(), 5, 0, map[int][]byte{
+		1: authEventCall(t, contract.AuthControllerMetaData, authedAddr, v2),
+		2: authEventCall(t, contract.AuthControllerV1MetaData, otherAddr, v1),
+		3: authEventCall(t, contract.AuthControllerMetaData, authedAddr, v2),
+	})
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	waitIndexed(t, idx, 5)
+
+	events := checkHistory(t, idx, authedAddr, []uint64{2, 4})
+	if event := events[0]; !event.IsAuth || event.Level != 3 || event.Expiry != 200 || event.Sender != testAddr {
+		t.Errorf("v2 event mismatch: %+v", event)
+	}
+	if event := events[0]; event.TxHash != blocks[1].Transactions()[0].Hash() {
+		t.Errorf("tx hash mismatch: have %x, want %x", event.TxHash, blocks[1].Transactions()[0].Hash())
+	}
+	events = checkHistory(t, idx, otherAddr, []uint64{3})
+	if event := events[0]; !event.IsAuth || event.Level != 0 || event.Expiry != 0 {
+		t.Errorf("v1 event mismatch: %+v", event)
+	}
+	// Range queries only return the events within the range
+	if events, _ := idx.History(authedAddr, 3, 4, 0); len(events) != 1 || events[0].Block != 4 {
+		t.Errorf("range query mismatch: %+v", events)
+	}
+	if events, _ := idx.History(authedAddr, 0, 100, 1); len(events) != 1 {
+		t.Errorf("limited query length mismatch: have %d, want 1", len(events))
+	}
+	// Replace the last event with a longer fork without events
+	fork := makeEventBlocks(t, chain, db, blocks[2], 4, 2, nil)
+	if _, err := chain.InsertChain(fork); err != nil {
+		t.Fatalf("failed to insert fork: %v", err)
+	}
+	waitIndexed(t, idx, 7)
+	checkHistory(t, idx, authedAddr, []uint64{2})
+	checkHistory(t, idx, otherAddr, []uint64{3})
+}
+
+// Tests that the blocks imported while the node was down are indexed in the
+// background, along with the ones imported meanwhile.
+func TestIndexerCatchUp(t *testing.T) {
+	chain, db, _ := newTestChainWithCode(t, logCode)
+	defer chain.Stop()
+
+	v2 := contract.AuthControllerAuthData{
+		Caddress:   authedAddr,
+		Sender:     testAddr,
+		AuthTime:   new(big.Int),
+		AuthExpiry: new(big.Int),
+		IsAuth:     true,
+		AuthLevel:  new(big.Int),
+	}
+	call := authEventCall(t, contract.AuthControllerMetaData, authedAddr, v2)
+	blocks := makeEventBlocks(t, chain, db, chain.Genesis(), 8, 0, map[int][]byte{1: call, 4: call, 6: call})
+	if _, err := chain.InsertChain(blocks[:6]); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	// Simulate a node stopped after indexing the first blocks
+	indexDb := rawdb.NewMemoryDatabase()
+	rawdb.WriteAuthIndexProgress(indexDb, 0, 2)
+
+	idx, err := NewIndexer(chain, indexDb)
+	if err != nil {
+		t.Fatalf("failed to create indexer: %v", err)
+	}
+	if err := idx.Start(); err != nil {
+		t.Fatalf("failed to start indexer: %v", err)
+	}
+	defer idx.Stop()
+
+	if _, err := chain.InsertChain(blocks[6:]); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	waitIndexed(t, idx, 8)
+
+	// The event of block 2 was indexed before the stop, in another database
+	checkHistory(t, idx, authedAddr, []uint64{5, 7})
+	if tail, head, ok := rawdb.ReadAuthIndexProgress(indexDb); !ok || tail != 0 || head != 8 {
+		t.Errorf("persisted progress mismatch: tail %d, head %d, ok %v", tail, head, ok)
+	}
+}
+
+func TestIndexerBackfill(t *testing.T) {
+	chain, db, _ := newTestChainWithCode(t, logCode)
+	defer chain.Stop()
+
+	v2 := contract.AuthControllerAuthData{
+		Caddress:   authedAddr,
+		Sender:     testAddr,
+		AuthTime:   new(big.Int),
+		AuthExpiry: new(big.Int),
+		IsAuth:     true,
+		AuthLevel:  new(big.Int),
+	}
+	blocks := makeEventBlocks(t, chain, db, chain.Genesis(), 6, 0, map[int][]byte{
+		0: authEventCall(t, contract.AuthControllerMetaData, authedAddr, v2),
+		3: authEventCall(t, contract.AuthControllerMetaData, authedAddr, v2),
+	})
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	// A fresh indexer only covers the blocks after the head
+	indexDb := rawdb.NewMemoryDatabase()
+	idx, err := NewIndexer(chain, indexDb)
+	if err != nil {
+		t.Fatalf("failed to create indexer: %v", err)
+	}
+	if err := idx.Start(); err != nil {
+		t.Fatalf("failed to start indexer: %v", err)
+	}
+	defer idx.Stop()
+
+	checkHistory(t, idx, authedAddr, nil)
+	if status := idx.Status(); status.Tail != 7 || status.Head != 6 || status.Running || status.Target != nil {
+		t.Fatalf("initial status mismatch: %+v", status)
+	}
+	// Backfill part of the history, then the rest
+	if err := idx.StartBackfill(3); err != nil {
+		t.Fatalf("failed to start backfill: %v", err)
+	}
+	waitBackfilled(t, idx)
+	checkHistory(t, idx, authedAddr, []uint64{4})
+
+	if err := idx.StartBackfill(3); err != errAlreadyIndexed {
+		t.Errorf("backfill error mismatch: have %v, want %v", err, errAlreadyIndexed)
+	}
+	if err := idx.StopBackfill(); err != errBackfillNotRunning {
+		t.Errorf("stop error mismatch: have %v, want %v", err, errBackfillNotRunning)
+	}
+	if err := idx.StartBackfill(0); err != nil {
+		t.Fatalf("failed to start backfill: %v", err)
+	}
+	waitBackfilled(t, idx)
+	checkHistory(t, idx, authedAddr, []uint64{1, 4})
+
+	if status := idx.Status(); status.Tail != 0 || status.Target != nil {
+		t.Errorf("final status mismatch: %+v", status)
+	}
+	if tail, head, ok := rawdb.ReadAuthIndexProgress(indexDb); !ok || tail != 0 || head != 6 {
+		t.Errorf("persisted progress mismatch: tail %d, head %d, ok %v", tail, head, ok)
+	}
+}
+
+// Tests that an interrupted backfill is resumed on restart.
+func TestIndexerBackfillResume(t *testing.T) {
+	chain, db, _ := newTestChainWithCode(t, logCode)
+	defer chain.Stop()
+
+	v2 := contract.AuthControllerAuthData{
+		Caddress:   authedAddr,
+		Sender:     testAddr,
+		AuthTime:   new(big.Int),
+		AuthExpiry: new(big.Int),
+		IsAuth:     true,
+		AuthLevel:  new(big.Int),
+	}
+	blocks := makeEventBlocks(t, chain, db, chain.Genesis(), 4, 0, map[int][]byte{
+		0: authEventCall(t, contract.AuthControllerMetaData, authedAddr, v2),
+	})
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	// Simulate a backfill interrupted after indexing the last block
+	indexDb := rawdb.NewMemoryDatabase()
+	rawdb.WriteAuthIndexProgress(indexDb, 4, 4)
+	rawdb.WriteAuthBackfill(indexDb, 0)
+
+	idx, err := NewIndexer(chain, indexDb)
+	if err != nil {
+		t.Fatalf("failed to create indexer: %v", err)
+	}
+	if err := idx.Start(); err != nil {
+		t.Fatalf("failed to start indexer: %v", err)
+	}
+	defer idx.Stop()
+
+	waitBackfilled(t, idx)
+	checkHistory(t, idx, authedAddr, []uint64{1})
+	if _, ok := rawdb.ReadAuthBackfill(indexDb); ok {
+		t.Errorf("backfill target not cleared")
+	}
+}
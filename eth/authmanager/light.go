@@ -0,0 +1,134 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package authmanager
+
+import (
+	"context"
+
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/qydata/go-ctereum/common"
+	"github.com/qydata/go-ctereum/common/hexutil"
+	"github.com/qydata/go-ctereum/contracts/authcontroller"
+	"github.com/qydata/go-ctereum/core"
+	"github.com/qydata/go-ctereum/core/types"
+	"github.com/qydata/go-ctereum/core/vm"
+	"github.com/qydata/go-ctereum/light"
+)
+
+// lightStatusKey identifies a status resolved by the light auth manager, which
+// is immutable for a given block hash.
+type lightStatusKey struct {
+	hash common.Hash
+	key  statusKey
+}
+
+// LightManager is the light client counterpart of the AuthManager. Without the
+// blocks and their events at hand, it resolves the authentication of addresses
+// by calling the auth contract against the state retrieved on demand from the
+// servers, whose Merkle proofs are checked against the state root of the block.
+// The headers of blocks past the local chain are proven by the trusted CHTs.
+//
+// Resolved statuses are cached by block hash. The expiry of authentications is
+// only carried by the events, so it is not reported in light mode.
+type LightManager struct {
+	chain    *light.LightChain
+	registry authcontroller.Registry
+	cache    *lru.Cache // lightStatusKey -> bool
+}
+
+// NewLight creates an auth manager resolving statuses through the on-demand
+// retrieval of the light chain, for the auth contract of the chain config and
+// those of the registry.
+func NewLight(chain *light.LightChain, registry authcontroller.Registry) (*LightManager, error) {
+	if err := registry.Validate(); err != nil {
+		return nil, err
+	}
+	cache, _ := lru.New(statusCacheSize)
+	return &LightManager{chain: chain, registry: registry, cache: cache}, nil
+}
+
+// IsAuthenticated reports whether the address is authenticated in the auth
+// contract as of the state after the given block.
+func (m *LightManager) IsAuthenticated(ctx context.Context, addr common.Address, number uint64) (bool, error) {
+	header, err := light.GetHeaderByNumber(ctx, m.chain.Odr(), number)
+	if err != nil {
+		return false, err
+	}
+	return m.status(ctx, statusKey{addr: addr}, header)
+}
+
+// Validity returns whether the address is authenticated as of the head block,
+// in the registered auth contract with the given label or in the one of the
+// chain config if none is given. The remaining validity is left unknown.
+func (m *LightManager) Validity(ctx context.Context, addr common.Address, label string) (*AuthValidity, error) {
+	key, err := m.key(addr, label)
+	if err != nil {
+		return nil, err
+	}
+	head := m.chain.CurrentHeader()
+	authenticated, err := m.status(ctx, key, head)
+	if err != nil {
+		return nil, err
+	}
+	validity := &AuthValidity{
+		Authenticated: authenticated,
+		Block:         hexutil.Uint64(head.Number.Uint64()),
+	}
+	if !authenticated {
+		validity.RemainingSeconds, validity.RemainingBlocks = new(hexutil.Uint64), new(hexutil.Uint64)
+	}
+	return validity, nil
+}
+
+// key returns the status key of the address in the registered auth contract
+// with the given label, or in the one of the chain config if none is given.
+func (m *LightManager) key(addr common.Address, label string) (statusKey, error) {
+	if label == "" {
+		return statusKey{addr: addr}, nil
+	}
+	contract, err := m.registry.Lookup(label)
+	if err != nil {
+		return statusKey{}, err
+	}
+	return statusKey{contract: contract, addr: addr}, nil
+}
+
+// status resolves the authentication of the address against the state after
+// the given block, retrieving the accessed state from the servers.
+func (m *LightManager) status(ctx context.Context, key statusKey, header *types.Header) (bool, error) {
+	cacheKey := lightStatusKey{hash: header.Hash(), key: key}
+	if cached, ok := m.cache.Get(cacheKey); ok {
+		return cached.(bool), nil
+	}
+	contract := key.contract
+	if contract == (common.Address{}) {
+		contract = m.chain.Config().AuthContractAt(header.Number)
+	}
+	statedb := light.NewState(ctx, header, m.chain.Odr())
+	evm := vm.NewEVM(core.NewEVMBlockContext(header, m.chain, nil), vm.TxContext{}, statedb, m.chain.Config(), vm.Config{})
+
+	auths, err := authcontroller.AuthsMulti(evm, contract, []common.Address{key.addr})
+	// Failed retrievals leave the state empty, surface them instead
+	if statedb.Error() != nil {
+		return false, statedb.Error()
+	}
+	if err != nil {
+		return false, err
+	}
+	m.cache.Add(cacheKey, auths[0])
+	return auths[0], nil
+}
@@ -0,0 +1,131 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package authmanager
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/qydata/go-ctereum/common"
+	"github.com/qydata/go-ctereum/consensus/ethash"
+	"github.com/qydata/go-ctereum/contracts/authcontroller"
+	"github.com/qydata/go-ctereum/core/rawdb"
+	"github.com/qydata/go-ctereum/core/types"
+	"github.com/qydata/go-ctereum/ethdb"
+	"github.com/qydata/go-ctereum/light"
+	"github.com/qydata/go-ctereum/trie"
+)
+
+var errOdrDisabled = errors.New("ODR disabled")
+
+// testOdr serves the state of a full node database to a light chain, proving
+// the trie nodes like a LES server does.
+type testOdr struct {
+	light.OdrBackend
+	sdb, ldb ethdb.Database
+	disable  bool
+}
+
+func (odr *testOdr) Database() ethdb.Database {
+	return odr.ldb
+}
+
+func (odr *testOdr) IndexerConfig() *light.IndexerConfig {
+	return light.TestClientIndexerConfig
+}
+
+func (odr *testOdr) Retrieve(ctx context.Context, req light.OdrRequest) error {
+	if odr.disable {
+		return errOdrDisabled
+	}
+	switch req := req.(type) {
+	case *light.TrieRequest:
+		t, _ := trie.New(common.BytesToHash(req.Id.AccKey), req.Id.Root, trie.NewDatabase(odr.sdb))
+		nodes := light.NewNodeSet()
+		t.Prove(req.Key, 0, nodes)
+		req.Proof = nodes
+	case *light.CodeRequest:
+		req.Data = rawdb.ReadCode(odr.sdb, req.Hash)
+	}
+	req.StoreResult(odr.ldb)
+	return nil
+}
+
+// Tests that the light auth manager resolves the statuses against the state
+// retrieved on demand, caching them by block.
+func TestLightManager(t *testing.T) {
+	chain, db, gspec := newTestChain(t)
+	defer chain.Stop()
+
+	// Authenticate in block 2, revoke in block 4
+	blocks := makeBlocks(t, chain, db, chain.Genesis(), 5, 0, map[int]bool{1: true, 3: false})
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	ldb := rawdb.NewMemoryDatabase()
+	gspec.MustCommit(ldb)
+
+	odr := &testOdr{sdb: db, ldb: ldb}
+	lightchain, err := light.NewLightChain(odr, gspec.Config, ethash.NewFaker(), nil)
+	if err != nil {
+		t.Fatalf("failed to create light chain: %v", err)
+	}
+	headers := make([]*types.Header, len(blocks))
+	for i, block := range blocks {
+		headers[i] = block.Header()
+	}
+	if _, err := lightchain.InsertHeaderChain(headers, 1); err != nil {
+		t.Fatalf("failed to insert headers: %v", err)
+	}
+	m, err := NewLight(lightchain, authcontroller.Registry{"dapp": dappAddr})
+	if err != nil {
+		t.Fatalf("failed to create manager: %v", err)
+	}
+	// Failed retrievals are reported rather than resolved against an empty state
+	ctx := context.Background()
+
+	odr.disable = true
+	if _, err := m.IsAuthenticated(ctx, authedAddr, 2); !errors.Is(err, errOdrDisabled) {
+		t.Errorf("retrieval error mismatch: have %v, want %v", err, errOdrDisabled)
+	}
+	odr.disable = false
+
+	for number, want := range []bool{false, false, true, true, false, false} {
+		have, err := m.IsAuthenticated(ctx, authedAddr, uint64(number))
+		if err != nil {
+			t.Fatalf("block %d: failed to check auth: %v", number, err)
+		}
+		if have != want {
+			t.Errorf("block %d: auth mismatch: have %v, want %v", number, have, want)
+		}
+	}
+	validity, err := m.Validity(ctx, authedAddr, "dapp")
+	if err != nil {
+		t.Fatalf("failed to query validity: %v", err)
+	}
+	if validity.Authenticated || uint64(validity.Block) != 5 {
+		t.Errorf("validity mismatch: have %+v", validity)
+	}
+	if _, err := m.Validity(ctx, authedAddr, "unknown"); err == nil {
+		t.Errorf("unknown controller label accepted")
+	}
+	// Resolved statuses are cached by block
+	if !m.cache.Contains(lightStatusKey{hash: headers[1].Hash(), key: statusKey{addr: authedAddr}}) {
+		t.Errorf("resolved status not cached")
+	}
+}
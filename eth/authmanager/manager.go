@@ -0,0 +1,729 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package authmanager keeps track of the sender authentication recorded in the
+// on-chain AuthController, so that other modules can look it up without
+// executing contract calls.
+package authmanager
+
+import (
+	"errors"
+	"sort"
+	"sync"
+
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/qydata/go-ctereum/accounts/abi"
+	"github.com/qydata/go-ctereum/accounts/abi/bind"
+	"github.com/qydata/go-ctereum/common"
+	"github.com/qydata/go-ctereum/common/hexutil"
+	"github.com/qydata/go-ctereum/contracts/authcontroller"
+	"github.com/qydata/go-ctereum/contracts/authcontroller/contract"
+	"github.com/qydata/go-ctereum/core"
+	"github.com/qydata/go-ctereum/core/rawdb"
+	"github.com/qydata/go-ctereum/core/types"
+	"github.com/qydata/go-ctereum/core/vm"
+	"github.com/qydata/go-ctereum/ethdb"
+	"github.com/qydata/go-ctereum/event"
+	"github.com/qydata/go-ctereum/log"
+	"github.com/qydata/go-ctereum/rlp"
+)
+
+const (
+	// statusCacheSize is the number of address histories kept in memory.
+	statusCacheSize = 4096
+
+	// chainHeadChanSize is the size of channel listening to Chain2HeadEvent.
+	chainHeadChanSize = 10
+
+	// maxCatchUpBlocks is the maximum number of blocks scanned after a restart to
+	// catch up with the events missed while the node was down. Beyond it the
+	// cached statuses are dropped and tracking restarts from the head.
+	maxCatchUpBlocks = 100000
+
+	// blockTimeWindow is the number of recent blocks the block time is averaged
+	// over when estimating the remaining validity in blocks.
+	blockTimeWindow = 64
+)
+
+var errUnknownBlock = errors.New("unknown block")
+
+// statusKey identifies the tracked statuses of an address in an auth contract.
+// The zero contract stands for the AuthController of the chain config, which
+// may change from block to block.
+type statusKey struct {
+	contract common.Address
+	addr     common.Address
+}
+
+// status is the authentication state of an address from block Number onwards,
+// up to the next recorded status.
+type status struct {
+	Number        uint64
+	Authenticated bool
+	Expiry        uint64 `rlp:"optional"` // Unix time the authentication expires at, zero if never or unknown
+}
+
+// valid reports whether the status authenticates the address at the given unix
+// time, taking the expiry into account.
+func (s status) valid(now uint64) bool {
+	return s.Authenticated && (s.Expiry == 0 || s.Expiry > now)
+}
+
+// AuthValidity is the remaining validity of the authentication of an address
+// as of the head block.
+type AuthValidity struct {
+	Authenticated    bool            `json:"authenticated"`
+	Expiry           *hexutil.Uint64 `json:"expiry"`           // Unix time the authentication expires at, nil if never or unknown
+	RemainingSeconds *hexutil.Uint64 `json:"remainingSeconds"` // Nil if the authentication never expires or the expiry is unknown
+	RemainingBlocks  *hexutil.Uint64 `json:"remainingBlocks"`  // Estimated from the block time, nil if unknown
+	Block            hexutil.Uint64  `json:"block"`
+}
+
+// AuthManager tracks the authentication events of the AuthController and
+// caches the resulting status of every touched address. The cache is kept in
+// memory and persisted into the database, and lookups for addresses without a
+// cached status fall back to a contract call against the local state.
+//
+// The expiry carried by the v2 Authentication events is tracked along with the
+// status and dropped with the blocks that recorded it on reorgs. It is reported
+// by the validity of the authentication only, as consensus does not enforce it.
+// Statuses resolved by a contract call carry no expiry, which is not exposed by
+// the contract.
+//
+// Besides the AuthController of the chain config, the per-dApp controllers of
+// the registry are tracked the same way from the block they were first seen in
+// the registry onwards.
+type AuthManager struct {
+	chain    *core.BlockChain
+	db       ethdb.Database
+	registry authcontroller.Registry
+
+	authParsers     map[common.Hash]*authcontroller.AuthController // Authentication event ID -> contract revision parsing it
+	whitelistEvents map[common.Hash]abi.Event                      // AddedToWhiteList and RemovedFromWhiteList events
+
+	lock   sync.Mutex
+	from   uint64                    // First block whose events have been tracked
+	to     uint64                    // Last block whose events have been tracked
+	starts map[common.Address]uint64 // Registered contract -> first block whose events have been tracked
+	cache  *lru.Cache                // statusKey -> []status, in block order
+
+	orderLock sync.Mutex // Serializes the order ID allocations
+
+	headCh  chan core.Chain2HeadEvent
+	headSub event.Subscription
+	quit    chan struct{}
+	wg      sync.WaitGroup
+}
+
+// New creates an auth manager tracking the given chain, along with the auth
+// contracts of the registry.
+func New(chain *core.BlockChain, db ethdb.Database, registry authcontroller.Registry) (*AuthManager, error) {
+	if err := registry.Validate(); err != nil {
+		return nil, err
+	}
+	cache, _ := lru.New(statusCacheSize)
+	m := &AuthManager{
+		chain:           chain,
+		db:              db,
+		registry:        registry,
+		starts:          make(map[common.Address]uint64),
+		authParsers:     make(map[common.Hash]*authcontroller.AuthController),
+		whitelistEvents: make(map[common.Hash]abi.Event),
+		cache:           cache,
+		headCh:          make(chan core.Chain2HeadEvent, chainHeadChanSize),
+		quit:            make(chan struct{}),
+	}
+	for version, meta := range map[authcontroller.Version]*bind.MetaData{
+		authcontroller.V1: contract.AuthControllerV1MetaData,
+		authcontroller.V2: contract.AuthControllerMetaData,
+	} {
+		parsed, err := meta.GetAbi()
+		if err != nil {
+			return nil, err
+		}
+		parser, err := authcontroller.NewAuthController(common.Address{}, nil, version)
+		if err != nil {
+			return nil, err
+		}
+		m.authParsers[parsed.Events["Authentication"].ID] = parser
+		for _, name := range []string{"AddedToWhiteList", "RemovedFromWhiteList"} {
+			ev := parsed.Events[name]
+			m.whitelistEvents[ev.ID] = ev
+		}
+	}
+	return m, nil
+}
+
+// Start implements node.Lifecycle, starting the event tracking. The blocks
+// imported while the node was down are caught up with in the background, the
+// head events being subscribed to first so that none of the blocks imported
+// meanwhile is missed.
+func (m *AuthManager) Start() error {
+	m.headSub = m.chain.SubscribeChain2HeadEvent(m.headCh)
+	head := m.chain.CurrentBlock().NumberU64()
+
+	from, to, ok := rawdb.ReadAuthProgress(m.db)
+	switch {
+	case !ok || to > head || head-to > maxCatchUpBlocks:
+		from, to = head, head
+		m.reset(head)
+		m.startRegistry(head)
+	default:
+		m.lock.Lock()
+		m.from, m.to = from, to
+		m.lock.Unlock()
+		m.startRegistry(to)
+	}
+	m.wg.Add(1)
+	go m.loop()
+
+	log.Info("Started auth manager", "from", from, "to", to, "catchup", head-to)
+	return nil
+}
+
+// registryProgress is the first block scanned for the events of a registered
+// auth contract.
+type registryProgress struct {
+	Contract common.Address
+	Start    uint64
+}
+
+// startRegistry resumes tracking the registered auth contracts, starting from
+// the given block for the ones not tracked before. Contracts removed from the
+// registry have their statuses dropped, as their tracking is interrupted.
+func (m *AuthManager) startRegistry(head uint64) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	var progress []registryProgress
+	if blob := rawdb.ReadAuthRegistryProgress(m.db); len(blob) > 0 {
+		if err := rlp.DecodeBytes(blob, &progress); err != nil {
+			log.Error("Invalid auth registry progress RLP", "err", err)
+			progress = nil
+		}
+	}
+	starts := make(map[common.Address]uint64)
+	for _, p := range progress {
+		starts[p.Contract] = p.Start
+	}
+	registered := make(map[common.Address]bool)
+	for _, contract := range m.registry.Addresses() {
+		registered[contract] = true
+		start, ok := starts[contract]
+		if !ok || start < m.from || start > head {
+			rawdb.DeleteAuthControllerStatuses(m.db, contract)
+			start = head
+		}
+		m.starts[contract] = start
+	}
+	for contract := range starts {
+		if !registered[contract] {
+			rawdb.DeleteAuthControllerStatuses(m.db, contract)
+		}
+	}
+	m.writeRegistryProgress()
+}
+
+// writeRegistryProgress persists the first blocks tracked for the registered
+// auth contracts. The caller must hold the lock.
+func (m *AuthManager) writeRegistryProgress() {
+	var progress []registryProgress
+	for _, contract := range m.registry.Addresses() {
+		progress = append(progress, registryProgress{Contract: contract, Start: m.starts[contract]})
+	}
+	blob, err := rlp.EncodeToBytes(progress)
+	if err != nil {
+		log.Crit("Failed to RLP encode auth registry progress", "err", err)
+	}
+	rawdb.WriteAuthRegistryProgress(m.db, blob)
+}
+
+// Stop implements node.Lifecycle, terminating the event tracking.
+func (m *AuthManager) Stop() error {
+	close(m.quit)
+	m.wg.Wait()
+	return nil
+}
+
+// loop processes the chain head events until the manager is stopped. While the
+// tracked head lags behind the chain, e.g. after a restart or a gap in the head
+// events, the missing blocks are processed one at a time in between the events.
+func (m *AuthManager) loop() {
+	defer m.wg.Done()
+	defer m.headSub.Unsubscribe()
+
+	for {
+		// The tracked head is only moved by this goroutine, no need to lock
+		if next := m.to + 1; next <= m.chain.CurrentBlock().NumberU64() {
+			select {
+			case ev := <-m.headCh:
+				m.handleHead(ev)
+				continue
+			case <-m.headSub.Err():
+				return
+			case <-m.quit:
+				return
+			default:
+			}
+			if block := m.chain.GetBlockByNumber(next); block != nil {
+				m.processBlock(block)
+				m.setProgress(next)
+				continue
+			}
+			log.Warn("Missing block for auth tracking", "number", next)
+		}
+		select {
+		case ev := <-m.headCh:
+			m.handleHead(ev)
+		case <-m.headSub.Err():
+			return
+		case <-m.quit:
+			return
+		}
+	}
+}
+
+// handleHead processes the blocks of a chain head event following the tracked
+// head, after reverting the blocks it removed from the canonical chain. The
+// blocks past a gap are left to be caught up with by number.
+func (m *AuthManager) handleHead(ev core.Chain2HeadEvent) {
+	if ev.Type == core.Chain2HeadForkEvent || len(ev.NewChain) == 0 {
+		return
+	}
+	head := m.to
+	for _, block := range ev.OldChain {
+		if block.NumberU64() <= head {
+			m.revertBlock(block)
+		}
+	}
+	// Rewind to the fork point, the new chain segment is ordered tip first
+	if len(ev.OldChain) > 0 {
+		if fork := ev.NewChain[len(ev.NewChain)-1].NumberU64() - 1; fork < head {
+			head = fork
+		}
+	}
+	for i := len(ev.NewChain) - 1; i >= 0; i-- {
+		number := ev.NewChain[i].NumberU64()
+		if number <= head {
+			continue // Already caught up with
+		}
+		if number > head+1 {
+			break
+		}
+		m.processBlock(ev.NewChain[i])
+		head = number
+	}
+	m.setProgress(head)
+}
+
+// IsAuthenticated reports whether the address is authenticated in the auth
+// contract as of the state after the given block. The expiry of the records is
+// not taken into account, matching the authentication enforced by consensus;
+// it is only reported by Validity.
+func (m *AuthManager) IsAuthenticated(addr common.Address, number uint64) (bool, error) {
+	header := m.chain.GetHeaderByNumber(number)
+	if header == nil {
+		return false, errUnknownBlock
+	}
+	st, err := m.status(statusKey{addr: addr}, header)
+	if err != nil {
+		return false, err
+	}
+	return st.Authenticated, nil
+}
+
+// Validity returns the remaining validity of the authentication of the address
+// as of the head block, in the registered auth contract with the given label
+// or in the one of the chain config if none is given.
+func (m *AuthManager) Validity(addr common.Address, label string) (*AuthValidity, error) {
+	key, err := m.key(addr, label)
+	if err != nil {
+		return nil, err
+	}
+	head := m.chain.CurrentHeader()
+	st, err := m.status(key, head)
+	if err != nil {
+		return nil, err
+	}
+	validity := &AuthValidity{
+		Authenticated: st.valid(head.Time),
+		Block:         hexutil.Uint64(head.Number.Uint64()),
+	}
+	if st.Expiry != 0 {
+		expiry := hexutil.Uint64(st.Expiry)
+		validity.Expiry = &expiry
+	}
+	switch {
+	case !validity.Authenticated:
+		validity.RemainingSeconds, validity.RemainingBlocks = new(hexutil.Uint64), new(hexutil.Uint64)
+
+	case st.Expiry != 0:
+		seconds := st.Expiry - head.Time
+		validity.RemainingSeconds = (*hexutil.Uint64)(&seconds)
+		if period := m.blockTime(head); period > 0 {
+			blocks := (seconds + period - 1) / period
+			validity.RemainingBlocks = (*hexutil.Uint64)(&blocks)
+		}
+	}
+	return validity, nil
+}
+
+// key returns the status key of the address in the registered auth contract
+// with the given label, or in the one of the chain config if none is given.
+func (m *AuthManager) key(addr common.Address, label string) (statusKey, error) {
+	if label == "" {
+		return statusKey{addr: addr}, nil
+	}
+	contract, err := m.registry.Lookup(label)
+	if err != nil {
+		return statusKey{}, err
+	}
+	return statusKey{contract: contract, addr: addr}, nil
+}
+
+// status returns the recorded authentication status of the address as of the
+// state after the given block, resolving it against the state if needed.
+func (m *AuthManager) status(key statusKey, header *types.Header) (status, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	// Statuses are only complete within the tracked range, anything older or
+	// not caught up with yet needs to be resolved against the state directly.
+	from := m.from
+	if start, ok := m.starts[key.contract]; ok && start > from {
+		from = start
+	}
+	number := header.Number.Uint64()
+	if number < from || number > m.to {
+		authenticated, err := m.query(key, header)
+		return status{Number: number, Authenticated: authenticated}, err
+	}
+	history := m.history(key)
+	idx := sort.Search(len(history), func(i int) bool { return history[i].Number > number })
+	if idx > 0 {
+		return history[idx-1], nil
+	}
+	// No change has been recorded up to the requested block, so the status
+	// holds from the first tracked block until the first recorded change (or
+	// the last tracked block). Resolve it at the most recent of those states,
+	// which is the most likely one not to be pruned yet, and cache it.
+	at := m.to
+	if len(history) > 0 {
+		at = history[0].Number - 1
+	}
+	if at != number {
+		if header = m.chain.GetHeaderByNumber(at); header == nil {
+			return status{}, errUnknownBlock
+		}
+	}
+	authenticated, err := m.query(key, header)
+	if err != nil {
+		return status{}, err
+	}
+	st := status{Number: from, Authenticated: authenticated}
+	m.store(key, append([]status{st}, history...))
+	return st, nil
+}
+
+// blockTime returns the expected number of seconds between blocks, taken from
+// the clique period or averaged over the recent blocks otherwise. Zero is
+// returned if it cannot be determined.
+func (m *AuthManager) blockTime(head *types.Header) uint64 {
+	if config := m.chain.Config().Clique; config != nil && config.Period > 0 {
+		return config.Period
+	}
+	number := head.Number.Uint64()
+	if number == 0 {
+		return 0
+	}
+	window := uint64(blockTimeWindow)
+	if number < window {
+		window = number
+	}
+	old := m.chain.GetHeaderByNumber(number - window)
+	if old == nil || old.Time >= head.Time {
+		return 0
+	}
+	return (head.Time - old.Time) / window
+}
+
+// CanDeploy reports whether the address may deploy contracts as of the state
+// after the given block, being authenticated or whitelisted in the auth
+// contract. The configured deployment allowlist is not consulted.
+func (m *AuthManager) CanDeploy(addr common.Address, number uint64) (bool, error) {
+	if authenticated, err := m.IsAuthenticated(addr, number); err != nil || authenticated {
+		return authenticated, err
+	}
+	header := m.chain.GetHeaderByNumber(number)
+	if header == nil {
+		return false, errUnknownBlock
+	}
+	evm, err := m.evmAt(header)
+	if err != nil {
+		return false, err
+	}
+	return evm.IsWhitelisted(addr), nil
+}
+
+// processBlock records the status of every address touched by the auth
+// contract events of the given canonical block.
+func (m *AuthManager) processBlock(block *types.Block) {
+	keys, expiries := m.touched(block)
+	if len(keys) == 0 {
+		return
+	}
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	number := block.NumberU64()
+	for _, key := range keys {
+		authenticated, err := m.query(key, block.Header())
+		if err != nil {
+			log.Warn("Failed to query auth status", "contract", key.contract, "addr", key.addr, "number", number, "err", err)
+			continue
+		}
+		history := truncate(m.history(key), number)
+
+		// Whitelist changes keep the expiry of the last authentication
+		expiry, ok := expiries[key]
+		if !ok && len(history) > 0 {
+			expiry = history[len(history)-1].Expiry
+		}
+		history = append(history, status{Number: number, Authenticated: authenticated, Expiry: expiry})
+		m.store(key, history)
+	}
+	log.Debug("Updated auth statuses", "number", number, "addrs", len(keys))
+}
+
+// revertBlock drops the statuses recorded by a block removed from the
+// canonical chain.
+func (m *AuthManager) revertBlock(block *types.Block) {
+	keys, _ := m.touched(block)
+	if len(keys) == 0 {
+		return
+	}
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	for _, key := range keys {
+		m.store(key, truncate(m.history(key), block.NumberU64()))
+	}
+}
+
+// touched returns the addresses whose authentication was changed by the auth
+// contract events of the given block, along with the expiry set by the last
+// Authentication event of each authenticated address.
+func (m *AuthManager) touched(block *types.Block) ([]statusKey, map[statusKey]uint64) {
+	var (
+		chainContract = m.chain.Config().AuthContractAt(block.Number())
+		contracts     = make(map[common.Address][]common.Address) // Log address -> status key contracts
+	)
+	if chainContract != (common.Address{}) && types.BloomLookup(block.Bloom(), chainContract) {
+		contracts[chainContract] = append(contracts[chainContract], common.Address{})
+	}
+	for _, contract := range m.registry.Addresses() {
+		if types.BloomLookup(block.Bloom(), contract) {
+			contracts[contract] = append(contracts[contract], contract)
+		}
+	}
+	if len(contracts) == 0 {
+		return nil, nil
+	}
+	var (
+		keys     []statusKey
+		seen     = make(map[statusKey]struct{})
+		expiries = make(map[statusKey]uint64)
+	)
+	for _, receipt := range m.chain.GetReceiptsByHash(block.Hash()) {
+		for _, l := range receipt.Logs {
+			owners, ok := contracts[l.Address]
+			if !ok || len(l.Topics) == 0 {
+				continue
+			}
+			addr, ok := m.affected(l)
+			if !ok {
+				continue
+			}
+			expiry, isAuth := m.expiry(l)
+			for _, contract := range owners {
+				key := statusKey{contract: contract, addr: addr}
+				if _, ok := seen[key]; !ok {
+					seen[key] = struct{}{}
+					keys = append(keys, key)
+				}
+				if isAuth {
+					expiries[key] = expiry
+				}
+			}
+		}
+	}
+	return keys, expiries
+}
+
+// expiry returns the expiry set by an Authentication event log, zero if none
+// is set, and whether the log is an Authentication event at all.
+func (m *AuthManager) expiry(l *types.Log) (uint64, bool) {
+	parser, ok := m.authParsers[l.Topics[0]]
+	if !ok {
+		return 0, false
+	}
+	auth, err := parser.ParseAuthentication(*l)
+	if err != nil {
+		log.Debug("Failed to parse auth event", "number", l.BlockNumber, "index", l.Index, "err", err)
+		return 0, true
+	}
+	if auth.AuthExpiry == nil || !auth.AuthExpiry.IsUint64() {
+		return 0, true
+	}
+	return auth.AuthExpiry.Uint64(), true
+}
+
+// affected returns the address whose authentication is changed by the log.
+func (m *AuthManager) affected(l *types.Log) (common.Address, bool) {
+	if _, ok := m.authParsers[l.Topics[0]]; ok {
+		if len(l.Topics) < 2 {
+			return common.Address{}, false
+		}
+		return common.BytesToAddress(l.Topics[1].Bytes()), true
+	}
+	if ev, ok := m.whitelistEvents[l.Topics[0]]; ok {
+		values, err := ev.Inputs.Unpack(l.Data)
+		if err != nil || len(values) == 0 {
+			return common.Address{}, false
+		}
+		addr, ok := values[0].(common.Address)
+		return addr, ok
+	}
+	return common.Address{}, false
+}
+
+// query resolves the authentication of the address against the state after
+// the given block.
+func (m *AuthManager) query(key statusKey, header *types.Header) (bool, error) {
+	evm, err := m.evmAt(header)
+	if err != nil {
+		return false, err
+	}
+	if key.contract == (common.Address{}) {
+		_, authenticated := evm.IsAuth(key.addr)
+		return authenticated, nil
+	}
+	auths, err := authcontroller.AuthsMulti(evm, key.contract, []common.Address{key.addr})
+	if err != nil {
+		return false, err
+	}
+	return auths[0], nil
+}
+
+// evmAt creates an EVM operating on the state after the given block.
+func (m *AuthManager) evmAt(header *types.Header) (*vm.EVM, error) {
+	statedb, err := m.chain.StateAt(header.Root)
+	if err != nil {
+		return nil, err
+	}
+	return vm.NewEVM(core.NewEVMBlockContext(header, m.chain, nil), vm.TxContext{}, statedb, m.chain.Config(), vm.Config{}), nil
+}
+
+// history returns the recorded statuses of the address, loading them from the
+// database if they are not cached. The caller must hold the lock.
+func (m *AuthManager) history(key statusKey) []status {
+	if cached, ok := m.cache.Get(key); ok {
+		return cached.([]status)
+	}
+	var (
+		history []status
+		blob    []byte
+	)
+	if key.contract == (common.Address{}) {
+		blob = rawdb.ReadAuthStatus(m.db, key.addr)
+	} else {
+		blob = rawdb.ReadAuthControllerStatus(m.db, key.contract, key.addr)
+	}
+	if len(blob) > 0 {
+		if err := rlp.DecodeBytes(blob, &history); err != nil {
+			log.Error("Invalid auth status RLP", "contract", key.contract, "addr", key.addr, "err", err)
+			history = nil
+		}
+	}
+	m.cache.Add(key, history)
+	return history
+}
+
+// store updates the recorded statuses of the address both in memory and in the
+// database. The caller must hold the lock.
+func (m *AuthManager) store(key statusKey, history []status) {
+	m.cache.Add(key, history)
+	if len(history) == 0 {
+		if key.contract == (common.Address{}) {
+			rawdb.DeleteAuthStatus(m.db, key.addr)
+		} else {
+			rawdb.DeleteAuthControllerStatus(m.db, key.contract, key.addr)
+		}
+		return
+	}
+	blob, err := rlp.EncodeToBytes(history)
+	if err != nil {
+		log.Crit("Failed to RLP encode auth status", "err", err)
+	}
+	if key.contract == (common.Address{}) {
+		rawdb.WriteAuthStatus(m.db, key.addr, blob)
+	} else {
+		rawdb.WriteAuthControllerStatus(m.db, key.contract, key.addr, blob)
+	}
+}
+
+// reset drops all recorded statuses and restarts the tracking at the given
+// block.
+func (m *AuthManager) reset(head uint64) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	rawdb.DeleteAuthStatuses(m.db)
+	rawdb.WriteAuthRegistryProgress(m.db, nil)
+	m.cache.Purge()
+	m.from, m.to = head, head
+	rawdb.WriteAuthProgress(m.db, m.from, m.to)
+}
+
+// setProgress records the last block whose events have been tracked.
+func (m *AuthManager) setProgress(head uint64) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if head < m.from {
+		m.from = head
+	}
+	m.to = head
+	rawdb.WriteAuthProgress(m.db, m.from, m.to)
+
+	// Registered contracts whose tracking started past a rewound head resume
+	// from it as well
+	var rewound bool
+	for contract, start := range m.starts {
+		if head < start {
+			m.starts[contract], rewound = head, true
+		}
+	}
+	if rewound {
+		m.writeRegistryProgress()
+	}
+}
+
+// truncate drops the statuses recorded at or after the given block.
+func truncate(history []status, number uint64) []status {
+	idx := sort.Search(len(history), func(i int) bool { return history[i].Number >= number })
+	return append([]status(nil), history[:idx]...)
+}
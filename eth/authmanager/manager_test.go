@@ -0,0 +1,424 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package authmanager
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/qydata/go-ctereum/common"
+	"github.com/qydata/go-ctereum/consensus/ethash"
+	"github.com/qydata/go-ctereum/contracts/authcontroller"
+	"github.com/qydata/go-ctereum/contracts/authcontroller/contract"
+	"github.com/qydata/go-ctereum/core"
+	"github.com/qydata/go-ctereum/core/rawdb"
+	"github.com/qydata/go-ctereum/core/types"
+	"github.com/qydata/go-ctereum/core/vm"
+	"github.com/qydata/go-ctereum/crypto"
+	"github.com/qydata/go-ctereum/ethdb"
+	"github.com/qydata/go-ctereum/params"
+)
+
+var (
+	testKey, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	testAddr    = crypto.PubkeyToAddress(testKey.PublicKey)
+	authAddr    = common.HexToAddress("0xa0")
+	dappAddr    = common.HexToAddress("0xa1")
+	authedAddr  = common.HexToAddress("0xbeef")
+	otherAddr   = common.HexToAddress("0xcafe")
+	authEventID = func() common.Hash {
+		parsed, _ := contract.AuthControllerMetaData.GetAbi()
+		return parsed.Events["Authentication"].ID
+	}()
+)
+
+// authCode is a minimal stand-in for the AuthController. A 36 byte call is
+// treated as authsSingle(address) and returns the flag stored for the address.
+// Any other call is treated as a raw (address, flag, topic) triplet followed by
+// the log data, storing the flag and emitting a log with the topic, the address
+// and the data.
+var authCode = common.FromHex(
+	"6024361460" + "22" + "57" + // if calldatasize == 36 goto read
+		"602035" + "600035" + "55" + // sstore(addr, flag)
+		"600035" + "604035" + "606036" + "03" + // size = calldatasize - 96
+		"80" + "6060" + "6000" + "37" + // calldatacopy(0, 96, size)
+		"6000" + "a2" + "00" + // log2(0, size, topic, addr)
+		"5b" + "600435" + "54" + "600052" + "60206000f3", // read: return sload(addr)
+)
+
+func newTestChain(t *testing.T) (*core.BlockChain, ethdb.Database, *core.Genesis) {
+	return newTestChainWithCode(t, authCode)
+}
+
+// newTestChainWithCode creates a chain with the given code deployed as the auth
+// contract, along with the auth stand-in as a per-dApp contract.
+func newTestChainWithCode(t *testing.T, code []byte) (*core.BlockChain, ethdb.Database, *core.Genesis) {
+	config := *params.AllEthashProtocolChanges
+	config.AuthContract = authAddr
+
+	db := rawdb.NewMemoryDatabase()
+	gspec := &core.Genesis{
+		Config: &config,
+		Alloc: core.GenesisAlloc{
+			testAddr: {Balance: big.NewInt(params.Ether)},
+			authAddr: {Code: code, Balance: new(big.Int)},
+			dappAddr: {Code: authCode, Balance: new(big.Int)},
+		},
+		BaseFee: big.NewInt(params.InitialBaseFee),
+	}
+	gspec.MustCommit(db)
+
+	chain, err := core.NewBlockChain(db, nil, gspec.Config, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	return chain, db, gspec
+}
+
+// makeBlocks generates n blocks on top of the parent, setting the auth flag of
+// the test address in the blocks listed in flags.
+func makeBlocks(t *testing.T, chain *core.BlockChain, db ethdb.Database, parent *types.Block, n int, nonce uint64, flags map[int]bool) []*types.Block {
+	blocks, _ := core.GenerateChain(chain.Config(), parent, ethash.NewFaker(), db, n, func(i int, b *core.BlockGen) {
+		b.SetCoinbase(common.Address{0x01})
+		flag, ok := flags[i]
+		if !ok {
+			return
+		}
+		var data []byte
+		data = append(data, common.LeftPadBytes(authedAddr.Bytes(), 32)...)
+		if flag {
+			data = append(data, common.LeftPadBytes([]byte{1}, 32)...)
+		} else {
+			data = append(data, make([]byte, 32)...)
+		}
+		data = append(data, authEventID.Bytes()...)
+
+		tx, err := types.SignTx(types.NewTransaction(nonce, authAddr, new(big.Int), 100000, b.BaseFee(), data), types.LatestSigner(chain.Config()), testKey)
+		if err != nil {
+			t.Fatalf("failed to sign tx: %v", err)
+		}
+		nonce++
+		b.AddTx(tx)
+	})
+	return blocks
+}
+
+// waitTracked waits until the manager processed the events up to the number.
+func waitTracked(t *testing.T, m *AuthManager, number uint64) {
+	for i := 0; i < 100; i++ {
+		m.lock.Lock()
+		to := m.to
+		m.lock.Unlock()
+		if to >= number {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("manager did not reach block %d", number)
+}
+
+func checkAuthenticated(t *testing.T, m *AuthManager, addr common.Address, number uint64, want bool) {
+	t.Helper()
+	have, err := m.IsAuthenticated(addr, number)
+	if err != nil {
+		t.Fatalf("block %d: failed to check auth: %v", number, err)
+	}
+	if have != want {
+		t.Errorf("block %d: auth mismatch for %x: have %v, want %v", number, addr, have, want)
+	}
+}
+
+func TestAuthManagerTracking(t *testing.T) {
+	chain, db, _ := newTestChain(t)
+	defer chain.Stop()
+
+	m, err := New(chain, db, nil)
+	if err != nil {
+		t.Fatalf("failed to create manager: %v", err)
+	}
+	if err := m.Start(); err != nil {
+		t.Fatalf("failed to start manager: %v", err)
+	}
+	// Authenticate in block 2, revoke in block 4
+	blocks := makeBlocks(t, chain, db, chain.Genesis(), 5, 0, map[int]bool{1: true, 3: false})
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	waitTracked(t, m, 5)
+
+	for number, want := range []bool{false, false, true, true, false, false} {
+		checkAuthenticated(t, m, authedAddr, uint64(number), want)
+	}
+	checkAuthenticated(t, m, otherAddr, 5, false)
+
+	// The two changes plus the lazily resolved initial status
+	if history := m.history(statusKey{addr: authedAddr}); len(history) != 3 {
+		t.Errorf("history length mismatch: have %d, want 3", len(history))
+	}
+	m.Stop()
+
+	// A fresh manager resumes from the persisted statuses
+	m, err = New(chain, db, nil)
+	if err != nil {
+		t.Fatalf("failed to create manager: %v", err)
+	}
+	if err := m.Start(); err != nil {
+		t.Fatalf("failed to restart manager: %v", err)
+	}
+	defer m.Stop()
+
+	if blob := rawdb.ReadAuthStatus(db, authedAddr); len(blob) == 0 {
+		t.Fatalf("missing persisted status")
+	}
+	for number, want := range []bool{false, false, true, true, false, false} {
+		checkAuthenticated(t, m, authedAddr, uint64(number), want)
+	}
+}
+
+// Tests that the events of the blocks imported while the manager was down are
+// caught up with in the background after a restart.
+func TestAuthManagerCatchUp(t *testing.T) {
+	chain, db, _ := newTestChain(t)
+	defer chain.Stop()
+
+	m, err := New(chain, db, nil)
+	if err != nil {
+		t.Fatalf("failed to create manager: %v", err)
+	}
+	if err := m.Start(); err != nil {
+		t.Fatalf("failed to start manager: %v", err)
+	}
+	// Authenticate in block 2 while running, revoke in block 4 while down
+	blocks := makeBlocks(t, chain, db, chain.Genesis(), 5, 0, map[int]bool{1: true, 3: false})
+	if _, err := chain.InsertChain(blocks[:2]); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	waitTracked(t, m, 2)
+	m.Stop()
+
+	if _, err := chain.InsertChain(blocks[2:]); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	m, err = New(chain, db, nil)
+	if err != nil {
+		t.Fatalf("failed to create manager: %v", err)
+	}
+	if err := m.Start(); err != nil {
+		t.Fatalf("failed to restart manager: %v", err)
+	}
+	defer m.Stop()
+
+	waitTracked(t, m, 5)
+	for number, want := range []bool{false, false, true, true, false, false} {
+		checkAuthenticated(t, m, authedAddr, uint64(number), want)
+	}
+	if from, to, ok := rawdb.ReadAuthProgress(db); !ok || from != 0 || to != 5 {
+		t.Errorf("persisted progress mismatch: from %d, to %d, ok %v", from, to, ok)
+	}
+}
+
+func TestAuthManagerReorg(t *testing.T) {
+	chain, db, _ := newTestChain(t)
+	defer chain.Stop()
+
+	m, err := New(chain, db, nil)
+	if err != nil {
+		t.Fatalf("failed to create manager: %v", err)
+	}
+	if err := m.Start(); err != nil {
+		t.Fatalf("failed to start manager: %v", err)
+	}
+	defer m.Stop()
+
+	blocks := makeBlocks(t, chain, db, chain.Genesis(), 4, 0, map[int]bool{1: true, 3: false})
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	waitTracked(t, m, 4)
+	checkAuthenticated(t, m, authedAddr, 4, false)
+
+	// Replace the revocation with a longer fork keeping the address authenticated
+	fork := makeBlocks(t, chain, db, blocks[1], 4, 1, nil)
+	if _, err := chain.InsertChain(fork); err != nil {
+		t.Fatalf("failed to insert fork: %v", err)
+	}
+	waitTracked(t, m, 6)
+
+	for number := uint64(2); number <= 6; number++ {
+		checkAuthenticated(t, m, authedAddr, number, true)
+	}
+	checkAuthenticated(t, m, authedAddr, 1, false)
+}
+
+// authTx creates a transaction making the auth stand-in emit a v2
+// Authentication event of the address with the given expiry.
+func authTx(t *testing.T, config *params.ChainConfig, b *core.BlockGen, nonce uint64, addr common.Address, expiry uint64) *types.Transaction {
+	parsed, _ := contract.AuthControllerMetaData.GetAbi()
+	event, err := parsed.Events["Authentication"].Inputs.NonIndexed().Pack(contract.AuthControllerAuthData{
+		Caddress:   addr,
+		Signature:  []byte{},
+		AuthTime:   new(big.Int),
+		AuthExpiry: new(big.Int).SetUint64(expiry),
+		IsAuth:     true,
+		AuthLevel:  new(big.Int),
+	})
+	if err != nil {
+		t.Fatalf("failed to pack event: %v", err)
+	}
+	var data []byte
+	data = append(data, common.LeftPadBytes(addr.Bytes(), 32)...)
+	data = append(data, common.LeftPadBytes([]byte{1}, 32)...)
+	data = append(data, authEventID.Bytes()...)
+	data = append(data, event...)
+
+	tx, err := types.SignTx(types.NewTransaction(nonce, authAddr, new(big.Int), 200000, b.BaseFee(), data), types.LatestSigner(config), testKey)
+	if err != nil {
+		t.Fatalf("failed to sign tx: %v", err)
+	}
+	return tx
+}
+
+func TestAuthManagerExpiry(t *testing.T) {
+	chain, db, _ := newTestChain(t)
+	defer chain.Stop()
+
+	m, err := New(chain, db, nil)
+	if err != nil {
+		t.Fatalf("failed to create manager: %v", err)
+	}
+	if err := m.Start(); err != nil {
+		t.Fatalf("failed to start manager: %v", err)
+	}
+	defer m.Stop()
+
+	// Blocks are 10 seconds apart, so the auth of the other address lapses
+	// at block 4 while the one of the authenticated address outlives the chain.
+	// The expiry is not enforced by consensus, only reported by the validity.
+	blocks, _ := core.GenerateChain(chain.Config(), chain.Genesis(), ethash.NewFaker(), db, 5, func(i int, b *core.BlockGen) {
+		b.SetCoinbase(common.Address{0x01})
+		if i == 0 {
+			b.AddTx(authTx(t, chain.Config(), b, 0, authedAddr, 1000))
+			b.AddTx(authTx(t, chain.Config(), b, 1, otherAddr, 35))
+		}
+	})
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	waitTracked(t, m, 5)
+
+	for number, want := range []bool{false, true, true, true, true, true} {
+		checkAuthenticated(t, m, otherAddr, uint64(number), want)
+	}
+	checkAuthenticated(t, m, authedAddr, 5, true)
+
+	validity, err := m.Validity(authedAddr, "")
+	if err != nil {
+		t.Fatalf("failed to retrieve validity: %v", err)
+	}
+	if !validity.Authenticated || validity.Expiry == nil || *validity.Expiry != 1000 ||
+		validity.RemainingSeconds == nil || *validity.RemainingSeconds != 950 ||
+		validity.RemainingBlocks == nil || *validity.RemainingBlocks != 95 {
+		t.Errorf("validity mismatch: %+v", validity)
+	}
+	validity, err = m.Validity(otherAddr, "")
+	if err != nil {
+		t.Fatalf("failed to retrieve validity: %v", err)
+	}
+	if validity.Authenticated || validity.RemainingSeconds == nil || *validity.RemainingSeconds != 0 {
+		t.Errorf("expired validity mismatch: %+v", validity)
+	}
+
+	// A fork renewing the auth in block 3 drops the expiring record
+	fork, _ := core.GenerateChain(chain.Config(), blocks[1], ethash.NewFaker(), db, 4, func(i int, b *core.BlockGen) {
+		b.SetCoinbase(common.Address{0x02})
+		if i == 0 {
+			b.AddTx(authTx(t, chain.Config(), b, 2, otherAddr, 1000))
+		}
+	})
+	if _, err := chain.InsertChain(fork); err != nil {
+		t.Fatalf("failed to insert fork: %v", err)
+	}
+	waitTracked(t, m, 6)
+
+	for number, want := range []bool{false, true, true, true, true, true, true} {
+		checkAuthenticated(t, m, otherAddr, uint64(number), want)
+	}
+	validity, err = m.Validity(otherAddr, "")
+	if err != nil {
+		t.Fatalf("failed to retrieve validity: %v", err)
+	}
+	if !validity.Authenticated || validity.Expiry == nil || *validity.Expiry != 1000 {
+		t.Errorf("renewed validity mismatch: %+v", validity)
+	}
+}
+
+func TestAuthManagerRegistry(t *testing.T) {
+	chain, db, _ := newTestChain(t)
+	defer chain.Stop()
+
+	m, err := New(chain, db, authcontroller.Registry{"dapp": dappAddr})
+	if err != nil {
+		t.Fatalf("failed to create manager: %v", err)
+	}
+	if err := m.Start(); err != nil {
+		t.Fatalf("failed to start manager: %v", err)
+	}
+	defer m.Stop()
+
+	// Authenticate in the dApp contract only
+	blocks, _ := core.GenerateChain(chain.Config(), chain.Genesis(), ethash.NewFaker(), db, 3, func(i int, b *core.BlockGen) {
+		b.SetCoinbase(common.Address{0x01})
+		if i != 1 {
+			return
+		}
+		var data []byte
+		data = append(data, common.LeftPadBytes(authedAddr.Bytes(), 32)...)
+		data = append(data, common.LeftPadBytes([]byte{1}, 32)...)
+		data = append(data, authEventID.Bytes()...)
+
+		tx, err := types.SignTx(types.NewTransaction(0, dappAddr, new(big.Int), 100000, b.BaseFee(), data), types.LatestSigner(chain.Config()), testKey)
+		if err != nil {
+			t.Fatalf("failed to sign tx: %v", err)
+		}
+		b.AddTx(tx)
+	})
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	waitTracked(t, m, 3)
+
+	// The event was recorded for the dApp contract alone
+	m.lock.Lock()
+	history := m.history(statusKey{contract: dappAddr, addr: authedAddr})
+	m.lock.Unlock()
+	if len(history) != 1 || history[0].Number != 2 || !history[0].Authenticated {
+		t.Errorf("dApp history mismatch: %+v", history)
+	}
+	if blob := rawdb.ReadAuthStatus(db, authedAddr); len(blob) != 0 {
+		t.Errorf("event recorded for the chain contract")
+	}
+	if validity, err := m.Validity(authedAddr, "dapp"); err != nil || !validity.Authenticated {
+		t.Errorf("dApp validity mismatch: %+v, %v", validity, err)
+	}
+	if validity, err := m.Validity(authedAddr, ""); err != nil || validity.Authenticated {
+		t.Errorf("chain validity mismatch: %+v, %v", validity, err)
+	}
+	if _, err := m.Validity(authedAddr, "unknown"); err == nil {
+		t.Errorf("validity of unknown controller succeeded")
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package authmanager
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/qydata/go-ctereum/accounts/abi/bind"
+	"github.com/qydata/go-ctereum/common"
+	"github.com/qydata/go-ctereum/contracts/authcontroller"
+	"github.com/qydata/go-ctereum/contracts/authcontroller/contract"
+	"github.com/qydata/go-ctereum/core/rawdb"
+)
+
+// maxOrderProbes is the maximum number of order IDs checked against the auth
+// contract by a single allocation.
+const maxOrderProbes = 1024
+
+var (
+	errAuthNotConfigured = errors.New("auth contract not configured")
+	errOrdersExhausted   = errors.New("no unused order ID found")
+)
+
+// NextOrderID allocates an order ID for an authentication submission, skipping
+// the IDs already consumed in the auth contract as of the head block. The IDs
+// handed out are never reissued, as the allocation counter is persisted, but
+// IDs used by transactions not yet mined are only skipped if this node handed
+// them out. The label selects a registered auth contract instead of the one of
+// the chain config, all of them sharing the allocation counter.
+func (m *AuthManager) NextOrderID(label string) (*big.Int, error) {
+	m.orderLock.Lock()
+	defer m.orderLock.Unlock()
+
+	head := m.chain.CurrentHeader()
+	contractAddr := m.chain.Config().AuthContractAt(head.Number)
+	if label != "" {
+		addr, err := m.registry.Lookup(label)
+		if err != nil {
+			return nil, err
+		}
+		contractAddr = addr
+	}
+	if contractAddr == (common.Address{}) {
+		return nil, errAuthNotConfigured
+	}
+	// Both contract revisions share the orders mapping
+	caller, err := contract.NewAuthControllerCaller(contractAddr, authcontroller.NewChainBackend(m.chain))
+	if err != nil {
+		return nil, err
+	}
+	next := rawdb.ReadAuthOrderCounter(m.db)
+	if next == 0 {
+		next = 1 // Leave zero to mean no order
+	}
+	defer func() { rawdb.WriteAuthOrderCounter(m.db, next) }()
+
+	for i := 0; i < maxOrderProbes; i++ {
+		id := new(big.Int).SetUint64(next)
+		used, err := caller.Orders(&bind.CallOpts{BlockNumber: head.Number}, id)
+		if err != nil {
+			return nil, err
+		}
+		next++
+		if !used {
+			return id, nil
+		}
+	}
+	return nil, errOrdersExhausted
+}
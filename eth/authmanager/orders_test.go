@@ -0,0 +1,70 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package authmanager
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/qydata/go-ctereum/common"
+	"github.com/qydata/go-ctereum/consensus/ethash"
+	"github.com/qydata/go-ctereum/core"
+	"github.com/qydata/go-ctereum/core/types"
+)
+
+func TestNextOrderID(t *testing.T) {
+	chain, db, _ := newTestChain(t)
+	defer chain.Stop()
+
+	// The auth stand-in answers orders(id) from the slot of the id, so mark
+	// the first two IDs as used by raw stores.
+	blocks, _ := core.GenerateChain(chain.Config(), chain.Genesis(), ethash.NewFaker(), db, 1, func(i int, b *core.BlockGen) {
+		for nonce, id := range []byte{1, 2} {
+			data := append(common.LeftPadBytes([]byte{id}, 32), common.LeftPadBytes([]byte{1}, 32)...)
+			data = append(data, make([]byte, 32)...)
+			tx, err := types.SignTx(types.NewTransaction(uint64(nonce), authAddr, new(big.Int), 100000, b.BaseFee(), data), types.LatestSigner(chain.Config()), testKey)
+			if err != nil {
+				t.Fatalf("failed to sign tx: %v", err)
+			}
+			b.AddTx(tx)
+		}
+	})
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	m, err := New(chain, db, nil)
+	if err != nil {
+		t.Fatalf("failed to create manager: %v", err)
+	}
+	for _, want := range []int64{3, 4} {
+		id, err := m.NextOrderID("")
+		if err != nil {
+			t.Fatalf("failed to allocate order ID: %v", err)
+		}
+		if id.Cmp(big.NewInt(want)) != 0 {
+			t.Errorf("order ID mismatch: have %v, want %d", id, want)
+		}
+	}
+	// A fresh manager continues after the IDs handed out
+	m, err = New(chain, db, nil)
+	if err != nil {
+		t.Fatalf("failed to create manager: %v", err)
+	}
+	if id, err := m.NextOrderID(""); err != nil || id.Cmp(big.NewInt(5)) != 0 {
+		t.Errorf("order ID mismatch after restart: have %v, %v, want 5", id, err)
+	}
+}
@@ -39,8 +39,10 @@ import (
 	"github.com/qydata/go-ctereum/core/state/pruner"
 	"github.com/qydata/go-ctereum/core/types"
 	"github.com/qydata/go-ctereum/core/vm"
+	"github.com/qydata/go-ctereum/eth/authmanager"
 	"github.com/qydata/go-ctereum/eth/downloader"
 	"github.com/qydata/go-ctereum/eth/ethconfig"
+	"github.com/qydata/go-ctereum/eth/logindex"
 	"github.com/qydata/go-ctereum/eth/protocols/eth"
 	"github.com/qydata/go-ctereum/eth/protocols/snap"
 	"github.com/qydata/go-ctereum/ethdb"
@@ -69,6 +71,9 @@ type Ethereum struct {
 	// Handlers
 	txPool             *core.TxPool
 	blockchain         *core.BlockChain
+	authManager        *authmanager.AuthManager
+	authIndexer        *authmanager.Indexer
+	logIndexer         *logindex.Indexer
 	handler            *handler
 	ethDialCandidates  enode.Iterator
 	snapDialCandidates enode.Iterator
@@ -135,10 +140,16 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 	if err != nil {
 		return nil, err
 	}
-	chainConfig, genesisHash, genesisErr := core.SetupGenesisBlockWithOverride(chainDb, config.Genesis, config.OverrideTerminalTotalDifficulty, config.OverrideTerminalTotalDifficultyPassed)
+	chainConfig, genesisHash, genesisErr := core.SetupGenesisBlockWithOverride(chainDb, config.Genesis, config.OverrideTerminalTotalDifficulty, config.OverrideTerminalTotalDifficultyPassed, config.OverrideAuthContract, config.OverrideValidatorContract)
 	if _, ok := genesisErr.(*params.ConfigCompatError); genesisErr != nil && !ok {
 		return nil, genesisErr
 	}
+	if err := config.Validate(chainConfig); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %v", err)
+	}
+	if err := config.CheckForks(genesisHash, chainConfig); err != nil {
+		return nil, err
+	}
 	log.Info("")
 	log.Info(strings.Repeat("-", 153))
 	for _, line := range strings.Split(chainConfig.String(), "\n") {
@@ -182,6 +193,13 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 	// create eth api and set engine
 	ethAPI := ethapi.NewBlockChainAPI(eth.APIBackend)
 	eth.engine = ethconfig.CreateConsensusEngine(stack, chainConfig, &ethashConfig, config.Miner.Notify, config.Miner.Noverify, chainDb, ethAPI)
+	if c, ok := eth.engine.(*clique.Clique); ok {
+		c.SetActivityWindow(config.CliqueActivityWindow)
+	} else if b, ok := eth.engine.(*beacon.Beacon); ok {
+		if c, ok := b.InnerEngine().(*clique.Clique); ok {
+			c.SetActivityWindow(config.CliqueActivityWindow)
+		}
+	}
 
 	bcVersion := rawdb.ReadDatabaseVersion(chainDb)
 	var dbVer = "<nil>"
@@ -214,6 +232,9 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 			TrieTimeLimit:       config.TrieTimeout,
 			SnapshotLimit:       config.SnapshotCache,
 			Preimages:           config.Preimages,
+			ParallelEVM:         config.ParallelEVM,
+			StateDiffs:          config.StateDiffs,
+			Witnesses:           config.StateWitnesses,
 		}
 	)
 	eth.blockchain, err = core.NewBlockChain(chainDb, cacheConfig, chainConfig, eth.engine, vmConfig, eth.shouldPreserve, &config.TxLookupLimit)
@@ -233,6 +254,22 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 	}
 	eth.txPool = core.NewTxPool(config.TxPool, chainConfig, eth.blockchain)
 
+	if chainConfig.AuthBlock != nil {
+		if eth.authManager, err = authmanager.New(eth.blockchain, chainDb, config.AuthRegistry); err != nil {
+			return nil, err
+		}
+		eth.txPool.SetAuthChecker(eth.authManager)
+
+		if eth.authIndexer, err = authmanager.NewIndexer(eth.blockchain, chainDb); err != nil {
+			return nil, err
+		}
+	}
+	if config.LogIndex {
+		if eth.logIndexer, err = logindex.New(eth.blockchain, chainDb); err != nil {
+			return nil, err
+		}
+	}
+
 	// Permit the downloader to use the trie cache allowance during fast sync
 	cacheLimit := cacheConfig.TrieCleanLimit + cacheConfig.TrieDirtyLimit + cacheConfig.SnapshotLimit
 	checkpoint := config.Checkpoint
@@ -307,6 +344,34 @@ func (s *Ethereum) APIs() []rpc.API {
 	// Append any APIs exposed explicitly by the consensus engine
 	apis = append(apis, s.engine.APIs(s.BlockChain())...)
 
+	if engine, ok := s.engine.(*clique.Clique); ok {
+		apis = append(apis, rpc.API{
+			Namespace: "ct",
+			Service:   NewBlockSealAPI(s, engine),
+		})
+	}
+	apis = append(apis, rpc.API{
+		Namespace: "ct",
+		Service:   NewNetworkStatusAPI(s),
+	})
+	if s.authManager != nil {
+		apis = append(apis, rpc.API{
+			Namespace: "ct",
+			Service:   authmanager.NewManagerAPI(s.authManager),
+		}, rpc.API{
+			Namespace: "admin",
+			Service:   authmanager.NewManagerAdminAPI(s.authManager),
+		})
+	}
+	if s.authIndexer != nil {
+		apis = append(apis, rpc.API{
+			Namespace: "ct",
+			Service:   authmanager.NewIndexerAPI(s.authIndexer),
+		}, rpc.API{
+			Namespace: "admin",
+			Service:   authmanager.NewIndexerAdminAPI(s.authIndexer),
+		})
+	}
 	// Append all the local APIs and return
 	return append(apis, []rpc.API{
 		{
@@ -492,19 +557,20 @@ func (s *Ethereum) StopMining() {
 func (s *Ethereum) IsMining() bool      { return s.miner.Mining() }
 func (s *Ethereum) Miner() *miner.Miner { return s.miner }
 
-func (s *Ethereum) AccountManager() *accounts.Manager  { return s.accountManager }
-func (s *Ethereum) BlockChain() *core.BlockChain       { return s.blockchain }
-func (s *Ethereum) TxPool() *core.TxPool               { return s.txPool }
-func (s *Ethereum) EventMux() *event.TypeMux           { return s.eventMux }
-func (s *Ethereum) Engine() consensus.Engine           { return s.engine }
-func (s *Ethereum) ChainDb() ethdb.Database            { return s.chainDb }
-func (s *Ethereum) IsListening() bool                  { return true } // Always listening
-func (s *Ethereum) Downloader() *downloader.Downloader { return s.handler.downloader }
-func (s *Ethereum) Synced() bool                       { return atomic.LoadUint32(&s.handler.acceptTxs) == 1 }
-func (s *Ethereum) SetSynced()                         { atomic.StoreUint32(&s.handler.acceptTxs, 1) }
-func (s *Ethereum) ArchiveMode() bool                  { return s.config.NoPruning }
-func (s *Ethereum) BloomIndexer() *core.ChainIndexer   { return s.bloomIndexer }
-func (s *Ethereum) Merger() *consensus.Merger          { return s.merger }
+func (s *Ethereum) AccountManager() *accounts.Manager     { return s.accountManager }
+func (s *Ethereum) BlockChain() *core.BlockChain          { return s.blockchain }
+func (s *Ethereum) TxPool() *core.TxPool                  { return s.txPool }
+func (s *Ethereum) AuthManager() *authmanager.AuthManager { return s.authManager }
+func (s *Ethereum) EventMux() *event.TypeMux              { return s.eventMux }
+func (s *Ethereum) Engine() consensus.Engine              { return s.engine }
+func (s *Ethereum) ChainDb() ethdb.Database               { return s.chainDb }
+func (s *Ethereum) IsListening() bool                     { return true } // Always listening
+func (s *Ethereum) Downloader() *downloader.Downloader    { return s.handler.downloader }
+func (s *Ethereum) Synced() bool                          { return atomic.LoadUint32(&s.handler.acceptTxs) == 1 }
+func (s *Ethereum) SetSynced()                            { atomic.StoreUint32(&s.handler.acceptTxs, 1) }
+func (s *Ethereum) ArchiveMode() bool                     { return s.config.NoPruning }
+func (s *Ethereum) BloomIndexer() *core.ChainIndexer      { return s.bloomIndexer }
+func (s *Ethereum) Merger() *consensus.Merger             { return s.merger }
 func (s *Ethereum) SyncMode() downloader.SyncMode {
 	mode, _ := s.handler.chainSync.modeAndLocalHead()
 	return mode
@@ -528,6 +594,18 @@ func (s *Ethereum) Start() error {
 	// Start the bloom bits servicing goroutines
 	s.startBloomHandlers(params.BloomBitsBlocks)
 
+	// Start the chain event trackers, stopped along with the bloom indexer so
+	// that they are done writing before the database is closed
+	trackers := s.chainTrackers()
+	for i, tracker := range trackers {
+		if err := tracker.Start(); err != nil {
+			for _, started := range trackers[:i] {
+				started.Stop()
+			}
+			return err
+		}
+	}
+
 	// Regularly update shutdown marker
 	s.shutdownTracker.Start()
 
@@ -544,27 +622,54 @@ func (s *Ethereum) Start() error {
 	return nil
 }
 
+// chainTrackers returns the enabled services tracking the chain events into the
+// database: the auth manager and the auth and log indexers.
+func (s *Ethereum) chainTrackers() []node.Lifecycle {
+	var trackers []node.Lifecycle
+	if s.authManager != nil {
+		trackers = append(trackers, s.authManager)
+	}
+	if s.authIndexer != nil {
+		trackers = append(trackers, s.authIndexer)
+	}
+	if s.logIndexer != nil {
+		trackers = append(trackers, s.logIndexer)
+	}
+	return trackers
+}
+
 // Stop implements node.Lifecycle, terminating all internal goroutines used by the
 // Ethereum protocol.
 func (s *Ethereum) Stop() error {
-	// Stop all the peer-related stuff first.
-	s.ethDialCandidates.Close()
-	s.snapDialCandidates.Close()
-	s.handler.Stop()
-
-	// Then stop everything else.
-	s.bloomIndexer.Close()
-	close(s.closeBloomHandler)
-	s.txPool.Stop()
-	s.miner.Close()
-	s.blockchain.Stop()
-	s.engine.Close()
-
-	// Clean shutdown marker as the last thing before closing db
-	s.shutdownTracker.Stop()
-
-	s.chainDb.Close()
-	s.eventMux.Stop()
-
+	runShutdown([]shutdownPhase{
+		// Stop all the peer-related stuff first.
+		{"network", func() {
+			s.ethDialCandidates.Close()
+			s.snapDialCandidates.Close()
+			s.handler.Stop()
+		}},
+		{"indexers", func() {
+			s.bloomIndexer.Close()
+			close(s.closeBloomHandler)
+			for _, tracker := range s.chainTrackers() {
+				tracker.Stop()
+			}
+		}},
+		// Stop producing blocks before the chain they are written to
+		{"sealing", func() {
+			s.miner.Close()
+			drainSealing(s.engine)
+		}},
+		{"txpool", s.txPool.Stop},
+		{"chain", s.blockchain.Stop},
+		{"consensus", func() { s.engine.Close() }},
+		{"database", func() {
+			// Clean shutdown marker as the last thing before closing db
+			s.shutdownTracker.Stop()
+
+			s.chainDb.Close()
+			s.eventMux.Stop()
+		}},
+	})
 	return nil
 }
@@ -18,6 +18,8 @@
 package ethconfig
 
 import (
+	"errors"
+	"fmt"
 	"github.com/qydata/go-ctereum/consensus/clique/contract"
 	"github.com/qydata/go-ctereum/consensus/clique/span"
 	"github.com/qydata/go-ctereum/internal/ethapi"
@@ -33,6 +35,7 @@ import (
 	"github.com/qydata/go-ctereum/consensus/beacon"
 	"github.com/qydata/go-ctereum/consensus/clique"
 	"github.com/qydata/go-ctereum/consensus/ethash"
+	"github.com/qydata/go-ctereum/contracts/authcontroller"
 	"github.com/qydata/go-ctereum/core"
 	"github.com/qydata/go-ctereum/eth/downloader"
 	"github.com/qydata/go-ctereum/eth/gasprice"
@@ -51,6 +54,7 @@ var FullNodeGPO = gasprice.Config{
 	MaxBlockHistory:  1024,
 	MaxPrice:         gasprice.DefaultMaxPrice,
 	IgnorePrice:      gasprice.DefaultIgnorePrice,
+	MaxEmptyBlocks:   200,
 }
 
 // LightClientGPO contains default gasprice oracle settings for light client.
@@ -61,6 +65,7 @@ var LightClientGPO = gasprice.Config{
 	MaxBlockHistory:  5,
 	MaxPrice:         gasprice.DefaultMaxPrice,
 	IgnorePrice:      gasprice.DefaultIgnorePrice,
+	MaxEmptyBlocks:   20,
 }
 
 // Defaults contains default settings for use on the Ethereum main net.
@@ -97,7 +102,8 @@ var Defaults = Config{
 	RPCEVMTimeout: 5 * time.Second,
 	GPO:           FullNodeGPO,
 	//RPCTxFeeCap:   1, // 1 ether
-	RPCTxFeeCap: 0, // unlimit
+	RPCTxFeeCap:          0, // unlimit
+	CliqueActivityWindow: 64,
 }
 
 func init() {
@@ -141,8 +147,14 @@ type Config struct {
 	NoPruning  bool // Whether to disable pruning and flush everything to disk
 	NoPrefetch bool // Whether to disable prefetching and only load state on demand
 
+	ParallelEVM    bool // Whether to execute block transactions speculatively in parallel
+	StateDiffs     bool // Whether to record the state changes made by every block
+	StateWitnesses bool // Whether to record the execution witness of every block
+
 	TxLookupLimit uint64 `toml:",omitempty"` // The maximum number of blocks from head whose tx indices are reserved.
 
+	LogIndex bool // Whether to index the blocks containing Authentication and Staked events
+
 	// RequiredBlocks is a set of block number -> hash mappings which must be in the
 	// canonical chain of all remote peers. Setting the option makes geth verify the
 	// presence of these blocks for every new peer connection.
@@ -157,9 +169,14 @@ type Config struct {
 	LightNoSyncServe   bool `toml:",omitempty"` // Whether to serve light clients before syncing
 	SyncFromCheckpoint bool `toml:",omitempty"` // Whether to sync the header chain from the configured checkpoint
 
+	// LightValidatorBalance is the positive balance granted by light servers to
+	// the clients proving control of a staked validator, zero to disable.
+	LightValidatorBalance uint64 `toml:",omitempty"`
+
 	// Ultra Light client options
 	UltraLightServers      []string `toml:",omitempty"` // List of trusted ultra light servers
 	UltraLightFraction     int      `toml:",omitempty"` // Percentage of trusted servers to accept an announcement
+	UltraLightQuorum       int      `toml:",omitempty"` // Number of trusted servers to accept an announcement, overriding the fraction
 	UltraLightOnlyAnnounce bool     `toml:",omitempty"` // Whether to only announce headers, or also serve them
 
 	// Database options
@@ -179,6 +196,10 @@ type Config struct {
 	// This is the number of blocks for which logs will be cached in the filter system.
 	FilterLogCacheSize int
 
+	// Limits of the log queries served over RPC, 0 meaning unlimited.
+	RPCLogsRangeLimit  uint64
+	RPCLogsResultLimit int
+
 	// Mining options
 	Miner miner.Config
 
@@ -213,11 +234,90 @@ type Config struct {
 	// CheckpointOracle is the configuration for checkpoint oracle.
 	CheckpointOracle *params.CheckpointOracleConfig `toml:",omitempty"`
 
+	// CliqueCheckpoint is the clique epoch block light clients start syncing
+	// from, which can be nil.
+	CliqueCheckpoint *params.CliqueCheckpoint `toml:",omitempty"`
+
 	// OverrideTerminalTotalDifficulty (TODO: remove after the fork)
 	OverrideTerminalTotalDifficulty *big.Int `toml:",omitempty"`
 
 	// OverrideTerminalTotalDifficultyPassed (TODO: remove after the fork)
 	OverrideTerminalTotalDifficultyPassed *bool `toml:",omitempty"`
+
+	// OverrideAuthContract replaces the AuthController address of the chain config
+	// queried before the fix fork, the one queried after it is left untouched
+	OverrideAuthContract *common.Address `toml:",omitempty"`
+
+	// AuthRegistry labels the per-dApp AuthController instances tracked next
+	// to the one of the chain config.
+	AuthRegistry authcontroller.Registry `toml:",omitempty"`
+
+	// OverrideValidatorContract replaces the staking contract of the clique config
+	OverrideValidatorContract *common.Address `toml:",omitempty"`
+
+	// CliqueActivityWindow is the number of recent blocks the signer activity
+	// reported by clique_status is computed over.
+	CliqueActivityWindow uint64
+
+	// StrictForks refuses to start if the ct forks of the chain config diverge
+	// from the canonical ones of its network, instead of only warning.
+	StrictForks bool `toml:",omitempty"`
+}
+
+// Validate checks the ct specific settings against the chain configuration they
+// are used with, the overrides being already applied to it.
+func (c *Config) Validate(chainConfig *params.ChainConfig) error {
+	if c.OverrideAuthContract != nil && *c.OverrideAuthContract == (common.Address{}) {
+		return errors.New("auth contract override is the zero address, set a deployed AuthController with --auth.contract")
+	}
+	if chainConfig.AuthBlock != nil && chainConfig.AuthContract == (common.Address{}) {
+		return fmt.Errorf("auth fork enabled at block %v without an auth contract, set authContract in the genesis config or use --auth.contract", chainConfig.AuthBlock)
+	}
+	if len(c.AuthRegistry) > 0 {
+		if chainConfig.AuthBlock == nil {
+			return errors.New("auth registry configured on a chain without the auth fork, remove --auth.registry or set authBlock in the genesis config")
+		}
+		if err := c.AuthRegistry.Validate(); err != nil {
+			return fmt.Errorf("invalid auth registry: %v", err)
+		}
+	}
+	if c.OverrideValidatorContract != nil {
+		if chainConfig.Clique == nil {
+			return errors.New("validator contract override requires a clique chain, remove --validator.contract")
+		}
+		if *c.OverrideValidatorContract == (common.Address{}) {
+			return errors.New("validator contract override is the zero address, set the staking contract with --validator.contract")
+		}
+	}
+	if chainConfig.Clique != nil && chainConfig.Clique.ValidatorContract != "" && !common.IsHexAddress(chainConfig.Clique.ValidatorContract) {
+		return fmt.Errorf("invalid validator contract %q in the clique config, set a hex address in the genesis config or use --validator.contract", chainConfig.Clique.ValidatorContract)
+	}
+	if c.GPO.MinTip != nil && c.GPO.MaxPrice != nil && c.GPO.MinTip.Cmp(c.GPO.MaxPrice) > 0 {
+		return fmt.Errorf("gas price oracle minimum tip %v above the maximum price %v, lower --gpo.mintip or raise --gpo.maxprice", c.GPO.MinTip, c.GPO.MaxPrice)
+	}
+	return nil
+}
+
+// CheckForks checks the ct fork blocks and contracts of the chain config against
+// the canonical ones of the network of the genesis. Diverging settings are logged
+// loudly as they would split the node off the network, and are fatal in strict
+// mode.
+func (c *Config) CheckForks(genesis common.Hash, chainConfig *params.ChainConfig) error {
+	readiness := params.CheckForkReadiness(genesis, chainConfig)
+	if readiness.Ready {
+		return nil
+	}
+	if c.StrictForks {
+		return readiness.Error()
+	}
+	log.Warn("")
+	log.Warn("The chain config diverges from the canonical forks of the network, the node will split off it!", "network", readiness.Network)
+	for _, check := range readiness.Mismatches() {
+		log.Warn("Diverging fork setting", "name", check.Name, "have", check.Have, "want", check.Want)
+	}
+	log.Warn("Update the genesis file or the overrides, or use --strict to refuse starting")
+	log.Warn("")
+	return nil
 }
 
 // CreateConsensusEngine creates a consensus engine for the given chain configuration.
@@ -0,0 +1,123 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethconfig
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/qydata/go-ctereum/common"
+	"github.com/qydata/go-ctereum/contracts/authcontroller"
+	"github.com/qydata/go-ctereum/params"
+)
+
+// Tests that the invalid combinations of ct settings are rejected at startup.
+func TestValidate(t *testing.T) {
+	var (
+		zero     = common.Address{}
+		contract = common.HexToAddress("0xa0")
+	)
+	authChain := func() *params.ChainConfig {
+		return &params.ChainConfig{ChainID: big.NewInt(1), AuthBlock: big.NewInt(10), AuthContract: contract}
+	}
+	cliqueChain := func(validators string) *params.ChainConfig {
+		return &params.ChainConfig{ChainID: big.NewInt(1), Clique: &params.CliqueConfig{Period: 1, Epoch: 30000, ValidatorContract: validators}}
+	}
+	tests := []struct {
+		name   string
+		config func(*Config)
+		chain  *params.ChainConfig
+		err    string
+	}{
+		{name: "defaults", config: func(*Config) {}, chain: params.TestChainConfig},
+		{name: "auth", config: func(*Config) {}, chain: authChain()},
+		{
+			name:   "auth without contract",
+			config: func(*Config) {},
+			chain:  &params.ChainConfig{ChainID: big.NewInt(1), AuthBlock: big.NewInt(10)},
+			err:    "without an auth contract",
+		},
+		{
+			name:   "zero auth override",
+			config: func(c *Config) { c.OverrideAuthContract = &zero },
+			chain:  authChain(),
+			err:    "auth contract override is the zero address",
+		},
+		{
+			name:   "registry",
+			config: func(c *Config) { c.AuthRegistry = authcontroller.Registry{"dapp": common.HexToAddress("0xb0")} },
+			chain:  authChain(),
+		},
+		{
+			name:   "registry without auth fork",
+			config: func(c *Config) { c.AuthRegistry = authcontroller.Registry{"dapp": common.HexToAddress("0xb0")} },
+			chain:  params.TestChainConfig,
+			err:    "without the auth fork",
+		},
+		{
+			name:   "invalid registry",
+			config: func(c *Config) { c.AuthRegistry = authcontroller.Registry{"dapp": zero} },
+			chain:  authChain(),
+			err:    "invalid auth registry",
+		},
+		{
+			name:   "validator override",
+			config: func(c *Config) { c.OverrideValidatorContract = &contract },
+			chain:  cliqueChain(contract.Hex()),
+		},
+		{
+			name:   "validator override without clique",
+			config: func(c *Config) { c.OverrideValidatorContract = &contract },
+			chain:  params.TestChainConfig,
+			err:    "requires a clique chain",
+		},
+		{
+			name:   "zero validator override",
+			config: func(c *Config) { c.OverrideValidatorContract = &zero },
+			chain:  cliqueChain(""),
+			err:    "validator contract override is the zero address",
+		},
+		{
+			name:   "invalid validator contract",
+			config: func(*Config) {},
+			chain:  cliqueChain("0xinvalid"),
+			err:    "invalid validator contract",
+		},
+		{
+			name: "min tip above max price",
+			config: func(c *Config) {
+				c.GPO.MinTip = big.NewInt(2 * params.GWei)
+				c.GPO.MaxPrice = big.NewInt(params.GWei)
+			},
+			chain: params.TestChainConfig,
+			err:   "minimum tip",
+		},
+	}
+	for _, tt := range tests {
+		config := Defaults
+		tt.config(&config)
+
+		err := config.Validate(tt.chain)
+		switch {
+		case tt.err == "" && err != nil:
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+		case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+			t.Errorf("%s: error mismatch: have %v, want %q", tt.name, err, tt.err)
+		}
+	}
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/qydata/go-ctereum/common"
 	"github.com/qydata/go-ctereum/consensus/ethash"
+	"github.com/qydata/go-ctereum/contracts/authcontroller"
 	"github.com/qydata/go-ctereum/core"
 	"github.com/qydata/go-ctereum/eth/downloader"
 	"github.com/qydata/go-ctereum/eth/gasprice"
@@ -25,7 +26,11 @@ func (c Config) MarshalTOML() (interface{}, error) {
 		SnapDiscoveryURLs                     []string
 		NoPruning                             bool
 		NoPrefetch                            bool
-		TxLookupLimit                         uint64                 `toml:",omitempty"`
+		ParallelEVM                           bool
+		StateDiffs                            bool
+		StateWitnesses                        bool
+		TxLookupLimit                         uint64 `toml:",omitempty"`
+		LogIndex                              bool
 		RequiredBlocks                        map[uint64]common.Hash `toml:"-"`
 		LightServ                             int                    `toml:",omitempty"`
 		LightIngress                          int                    `toml:",omitempty"`
@@ -33,9 +38,11 @@ func (c Config) MarshalTOML() (interface{}, error) {
 		LightPeers                            int                    `toml:",omitempty"`
 		LightNoPrune                          bool                   `toml:",omitempty"`
 		LightNoSyncServe                      bool                   `toml:",omitempty"`
+		LightValidatorBalance                 uint64                 `toml:",omitempty"`
 		SyncFromCheckpoint                    bool                   `toml:",omitempty"`
 		UltraLightServers                     []string               `toml:",omitempty"`
 		UltraLightFraction                    int                    `toml:",omitempty"`
+		UltraLightQuorum                      int                    `toml:",omitempty"`
 		UltraLightOnlyAnnounce                bool                   `toml:",omitempty"`
 		SkipBcVersionCheck                    bool                   `toml:"-"`
 		DatabaseHandles                       int                    `toml:"-"`
@@ -49,6 +56,8 @@ func (c Config) MarshalTOML() (interface{}, error) {
 		SnapshotCache                         int
 		Preimages                             bool
 		FilterLogCacheSize                    int
+		RPCLogsRangeLimit                     uint64
+		RPCLogsResultLimit                    int
 		Miner                                 miner.Config
 		Ethash                                ethash.Config
 		TxPool                                core.TxPoolConfig
@@ -60,8 +69,14 @@ func (c Config) MarshalTOML() (interface{}, error) {
 		RPCTxFeeCap                           float64
 		Checkpoint                            *params.TrustedCheckpoint      `toml:",omitempty"`
 		CheckpointOracle                      *params.CheckpointOracleConfig `toml:",omitempty"`
+		CliqueCheckpoint                      *params.CliqueCheckpoint       `toml:",omitempty"`
 		OverrideTerminalTotalDifficulty       *big.Int                       `toml:",omitempty"`
 		OverrideTerminalTotalDifficultyPassed *bool                          `toml:",omitempty"`
+		OverrideAuthContract                  *common.Address                `toml:",omitempty"`
+		AuthRegistry                          authcontroller.Registry        `toml:",omitempty"`
+		OverrideValidatorContract             *common.Address                `toml:",omitempty"`
+		CliqueActivityWindow                  uint64
+		StrictForks                           bool `toml:",omitempty"`
 	}
 	var enc Config
 	enc.Genesis = c.Genesis
@@ -71,7 +86,11 @@ func (c Config) MarshalTOML() (interface{}, error) {
 	enc.SnapDiscoveryURLs = c.SnapDiscoveryURLs
 	enc.NoPruning = c.NoPruning
 	enc.NoPrefetch = c.NoPrefetch
+	enc.ParallelEVM = c.ParallelEVM
+	enc.StateDiffs = c.StateDiffs
+	enc.StateWitnesses = c.StateWitnesses
 	enc.TxLookupLimit = c.TxLookupLimit
+	enc.LogIndex = c.LogIndex
 	enc.RequiredBlocks = c.RequiredBlocks
 	enc.LightServ = c.LightServ
 	enc.LightIngress = c.LightIngress
@@ -79,9 +98,11 @@ func (c Config) MarshalTOML() (interface{}, error) {
 	enc.LightPeers = c.LightPeers
 	enc.LightNoPrune = c.LightNoPrune
 	enc.LightNoSyncServe = c.LightNoSyncServe
+	enc.LightValidatorBalance = c.LightValidatorBalance
 	enc.SyncFromCheckpoint = c.SyncFromCheckpoint
 	enc.UltraLightServers = c.UltraLightServers
 	enc.UltraLightFraction = c.UltraLightFraction
+	enc.UltraLightQuorum = c.UltraLightQuorum
 	enc.UltraLightOnlyAnnounce = c.UltraLightOnlyAnnounce
 	enc.SkipBcVersionCheck = c.SkipBcVersionCheck
 	enc.DatabaseHandles = c.DatabaseHandles
@@ -95,6 +116,8 @@ func (c Config) MarshalTOML() (interface{}, error) {
 	enc.SnapshotCache = c.SnapshotCache
 	enc.Preimages = c.Preimages
 	enc.FilterLogCacheSize = c.FilterLogCacheSize
+	enc.RPCLogsRangeLimit = c.RPCLogsRangeLimit
+	enc.RPCLogsResultLimit = c.RPCLogsResultLimit
 	enc.Miner = c.Miner
 	enc.Ethash = c.Ethash
 	enc.TxPool = c.TxPool
@@ -106,8 +129,14 @@ func (c Config) MarshalTOML() (interface{}, error) {
 	enc.RPCTxFeeCap = c.RPCTxFeeCap
 	enc.Checkpoint = c.Checkpoint
 	enc.CheckpointOracle = c.CheckpointOracle
+	enc.CliqueCheckpoint = c.CliqueCheckpoint
 	enc.OverrideTerminalTotalDifficulty = c.OverrideTerminalTotalDifficulty
 	enc.OverrideTerminalTotalDifficultyPassed = c.OverrideTerminalTotalDifficultyPassed
+	enc.OverrideAuthContract = c.OverrideAuthContract
+	enc.AuthRegistry = c.AuthRegistry
+	enc.OverrideValidatorContract = c.OverrideValidatorContract
+	enc.CliqueActivityWindow = c.CliqueActivityWindow
+	enc.StrictForks = c.StrictForks
 	return &enc, nil
 }
 
@@ -121,7 +150,11 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 		SnapDiscoveryURLs                     []string
 		NoPruning                             *bool
 		NoPrefetch                            *bool
-		TxLookupLimit                         *uint64                `toml:",omitempty"`
+		ParallelEVM                           *bool
+		StateDiffs                            *bool
+		StateWitnesses                        *bool
+		TxLookupLimit                         *uint64 `toml:",omitempty"`
+		LogIndex                              *bool
 		RequiredBlocks                        map[uint64]common.Hash `toml:"-"`
 		LightServ                             *int                   `toml:",omitempty"`
 		LightIngress                          *int                   `toml:",omitempty"`
@@ -129,9 +162,11 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 		LightPeers                            *int                   `toml:",omitempty"`
 		LightNoPrune                          *bool                  `toml:",omitempty"`
 		LightNoSyncServe                      *bool                  `toml:",omitempty"`
+		LightValidatorBalance                 *uint64                `toml:",omitempty"`
 		SyncFromCheckpoint                    *bool                  `toml:",omitempty"`
 		UltraLightServers                     []string               `toml:",omitempty"`
 		UltraLightFraction                    *int                   `toml:",omitempty"`
+		UltraLightQuorum                      *int                   `toml:",omitempty"`
 		UltraLightOnlyAnnounce                *bool                  `toml:",omitempty"`
 		SkipBcVersionCheck                    *bool                  `toml:"-"`
 		DatabaseHandles                       *int                   `toml:"-"`
@@ -145,6 +180,8 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 		SnapshotCache                         *int
 		Preimages                             *bool
 		FilterLogCacheSize                    *int
+		RPCLogsRangeLimit                     *uint64
+		RPCLogsResultLimit                    *int
 		Miner                                 *miner.Config
 		Ethash                                *ethash.Config
 		TxPool                                *core.TxPoolConfig
@@ -156,8 +193,14 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 		RPCTxFeeCap                           *float64
 		Checkpoint                            *params.TrustedCheckpoint      `toml:",omitempty"`
 		CheckpointOracle                      *params.CheckpointOracleConfig `toml:",omitempty"`
+		CliqueCheckpoint                      *params.CliqueCheckpoint       `toml:",omitempty"`
 		OverrideTerminalTotalDifficulty       *big.Int                       `toml:",omitempty"`
 		OverrideTerminalTotalDifficultyPassed *bool                          `toml:",omitempty"`
+		OverrideAuthContract                  *common.Address                `toml:",omitempty"`
+		AuthRegistry                          authcontroller.Registry        `toml:",omitempty"`
+		OverrideValidatorContract             *common.Address                `toml:",omitempty"`
+		CliqueActivityWindow                  *uint64
+		StrictForks                           *bool `toml:",omitempty"`
 	}
 	var dec Config
 	if err := unmarshal(&dec); err != nil {
@@ -184,9 +227,21 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.NoPrefetch != nil {
 		c.NoPrefetch = *dec.NoPrefetch
 	}
+	if dec.ParallelEVM != nil {
+		c.ParallelEVM = *dec.ParallelEVM
+	}
+	if dec.StateDiffs != nil {
+		c.StateDiffs = *dec.StateDiffs
+	}
+	if dec.StateWitnesses != nil {
+		c.StateWitnesses = *dec.StateWitnesses
+	}
 	if dec.TxLookupLimit != nil {
 		c.TxLookupLimit = *dec.TxLookupLimit
 	}
+	if dec.LogIndex != nil {
+		c.LogIndex = *dec.LogIndex
+	}
 	if dec.RequiredBlocks != nil {
 		c.RequiredBlocks = dec.RequiredBlocks
 	}
@@ -208,6 +263,9 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.LightNoSyncServe != nil {
 		c.LightNoSyncServe = *dec.LightNoSyncServe
 	}
+	if dec.LightValidatorBalance != nil {
+		c.LightValidatorBalance = *dec.LightValidatorBalance
+	}
 	if dec.SyncFromCheckpoint != nil {
 		c.SyncFromCheckpoint = *dec.SyncFromCheckpoint
 	}
@@ -217,6 +275,9 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.UltraLightFraction != nil {
 		c.UltraLightFraction = *dec.UltraLightFraction
 	}
+	if dec.UltraLightQuorum != nil {
+		c.UltraLightQuorum = *dec.UltraLightQuorum
+	}
 	if dec.UltraLightOnlyAnnounce != nil {
 		c.UltraLightOnlyAnnounce = *dec.UltraLightOnlyAnnounce
 	}
@@ -256,6 +317,12 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.FilterLogCacheSize != nil {
 		c.FilterLogCacheSize = *dec.FilterLogCacheSize
 	}
+	if dec.RPCLogsRangeLimit != nil {
+		c.RPCLogsRangeLimit = *dec.RPCLogsRangeLimit
+	}
+	if dec.RPCLogsResultLimit != nil {
+		c.RPCLogsResultLimit = *dec.RPCLogsResultLimit
+	}
 	if dec.Miner != nil {
 		c.Miner = *dec.Miner
 	}
@@ -289,11 +356,29 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.CheckpointOracle != nil {
 		c.CheckpointOracle = dec.CheckpointOracle
 	}
+	if dec.CliqueCheckpoint != nil {
+		c.CliqueCheckpoint = dec.CliqueCheckpoint
+	}
 	if dec.OverrideTerminalTotalDifficulty != nil {
 		c.OverrideTerminalTotalDifficulty = dec.OverrideTerminalTotalDifficulty
 	}
 	if dec.OverrideTerminalTotalDifficultyPassed != nil {
 		c.OverrideTerminalTotalDifficultyPassed = dec.OverrideTerminalTotalDifficultyPassed
 	}
+	if dec.OverrideAuthContract != nil {
+		c.OverrideAuthContract = dec.OverrideAuthContract
+	}
+	if dec.AuthRegistry != nil {
+		c.AuthRegistry = dec.AuthRegistry
+	}
+	if dec.OverrideValidatorContract != nil {
+		c.OverrideValidatorContract = dec.OverrideValidatorContract
+	}
+	if dec.CliqueActivityWindow != nil {
+		c.CliqueActivityWindow = *dec.CliqueActivityWindow
+	}
+	if dec.StrictForks != nil {
+		c.StrictForks = *dec.StrictForks
+	}
 	return nil
 }
@@ -28,6 +28,7 @@ import (
 
 	"github.com/qydata/go-ctereum/common"
 	"github.com/qydata/go-ctereum/common/hexutil"
+	"github.com/qydata/go-ctereum/core"
 	"github.com/qydata/go-ctereum/core/types"
 	"github.com/qydata/go-ctereum/rpc"
 )
@@ -230,7 +231,66 @@ func (api *FilterAPI) NewHeads(ctx context.Context) (*rpc.Subscription, error) {
 	return rpcSub, nil
 }
 
+// Chain2HeadResult is the notification payload of a chain2Head subscription.
+// Head and fork events carry only the new block, reorgs additionally carry the
+// dropped canonical segment. Both segments are ordered from newest to oldest.
+type Chain2HeadResult struct {
+	Type     string          `json:"type"`
+	NewChain []*types.Header `json:"newChain"`
+	OldChain []*types.Header `json:"oldChain"`
+}
+
+func newChain2HeadResult(ev core.Chain2HeadEvent) *Chain2HeadResult {
+	res := &Chain2HeadResult{
+		Type:     ev.Type,
+		NewChain: make([]*types.Header, 0, len(ev.NewChain)),
+		OldChain: make([]*types.Header, 0, len(ev.OldChain)),
+	}
+	for _, block := range ev.NewChain {
+		res.NewChain = append(res.NewChain, block.Header())
+	}
+	for _, block := range ev.OldChain {
+		res.OldChain = append(res.OldChain, block.Header())
+	}
+	return res
+}
+
+// Chain2Head sends a notification each time the canonical head changes, typed
+// as "head", "fork" or "reorg" and including the affected chain segments.
+func (api *FilterAPI) Chain2Head(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		chain2Hs := make(chan core.Chain2HeadEvent)
+		chain2HSub := api.events.SubscribeChain2Head(chain2Hs)
+
+		for {
+			select {
+			case ev := <-chain2Hs:
+				notifier.Notify(rpcSub.ID, newChain2HeadResult(ev))
+			case <-rpcSub.Err():
+				chain2HSub.Unsubscribe()
+				return
+			case <-notifier.Closed():
+				chain2HSub.Unsubscribe()
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
+
 // Logs creates a subscription that fires for all new log that match the given filter criteria.
+//
+// If the criteria start at a past block, the matching logs from that block up to
+// the current head are replayed at a bounded pace before the live ones. If the
+// replay fails, its error is notified and the subscription ends.
 func (api *FilterAPI) Logs(ctx context.Context, crit FilterCriteria) (*rpc.Subscription, error) {
 	notifier, supported := rpc.NotifierFromContext(ctx)
 	if !supported {
@@ -248,13 +308,70 @@ func (api *FilterAPI) Logs(ctx context.Context, crit FilterCriteria) (*rpc.Subsc
 	}
 
 	go func() {
+		var (
+			replay   <-chan []*types.Log // Historical logs, nil once replayed
+			errc     <-chan error        // Error of a failed replay
+			head     uint64              // Last block replayed
+			held     []*types.Log        // Live logs held back until the replay is done
+			overflow bool                // Whether live logs were dropped during the replay
+		)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		if crit.FromBlock != nil && crit.FromBlock.Sign() >= 0 {
+			replay, errc, head = api.replayLogs(ctx, crit)
+		}
 		for {
 			select {
 			case logs := <-matchedLogs:
+				if replay != nil {
+					// Hold the live logs back, or drop them all if too many to
+					// replay them from the database once caught up
+					if !overflow && len(held)+len(logs) > maxHeldLogs {
+						held, overflow = nil, true
+					}
+					if !overflow {
+						held = append(held, logs...)
+					}
+					continue
+				}
 				for _, log := range logs {
 					log := log
 					notifier.Notify(rpcSub.ID, &log)
 				}
+			case logs, ok := <-replay:
+				if !ok {
+					select {
+					case err := <-errc:
+						notifier.Notify(rpcSub.ID, &logsReplayError{Error: err.Error()})
+						logsSub.Unsubscribe()
+						return
+					default:
+					}
+					if overflow {
+						// Extend the replay up to the new head, covering the
+						// live logs dropped
+						overflow = false
+
+						next := crit
+						next.FromBlock = new(big.Int).SetUint64(head + 1)
+						if pages, perr, last := api.replayLogs(ctx, next); pages != nil {
+							replay, errc, head = pages, perr, last
+							continue
+						}
+					}
+					// Switch to live mode, skipping the logs already replayed
+					for _, log := range held {
+						if log.Removed || log.BlockNumber > head {
+							notifier.Notify(rpcSub.ID, log)
+						}
+					}
+					replay, held = nil, nil
+					continue
+				}
+				for _, log := range logs {
+					notifier.Notify(rpcSub.ID, log)
+				}
 			case <-rpcSub.Err(): // client send an unsubscribe request
 				logsSub.Unsubscribe()
 				return
@@ -316,6 +433,9 @@ func (api *FilterAPI) NewFilter(crit FilterCriteria) (rpc.ID, error) {
 }
 
 // GetLogs returns logs matching the given argument that are stored within the state.
+//
+// Queries spanning more blocks or matching more logs than the configured limits
+// are rejected, use GetLogsPage to retrieve their logs in several pages.
 func (api *FilterAPI) GetLogs(ctx context.Context, crit FilterCriteria) ([]*types.Log, error) {
 	var filter *Filter
 	if crit.BlockHash != nil {
@@ -331,6 +451,9 @@ func (api *FilterAPI) GetLogs(ctx context.Context, crit FilterCriteria) ([]*type
 		if crit.ToBlock != nil {
 			end = crit.ToBlock.Int64()
 		}
+		if err := api.checkRange(ctx, begin, end); err != nil {
+			return nil, err
+		}
 		// Construct the range filter
 		filter = api.sys.NewRangeFilter(begin, end, crit.Addresses, crit.Topics)
 	}
@@ -339,6 +462,9 @@ func (api *FilterAPI) GetLogs(ctx context.Context, crit FilterCriteria) ([]*type
 	if err != nil {
 		return nil, err
 	}
+	if err := api.checkResults(logs); err != nil {
+		return nil, err
+	}
 	return returnLogs(logs), err
 }
 
@@ -382,6 +508,9 @@ func (api *FilterAPI) GetFilterLogs(ctx context.Context, id rpc.ID) ([]*types.Lo
 		if f.crit.ToBlock != nil {
 			end = f.crit.ToBlock.Int64()
 		}
+		if err := api.checkRange(ctx, begin, end); err != nil {
+			return nil, err
+		}
 		// Construct the range filter
 		filter = api.sys.NewRangeFilter(begin, end, f.crit.Addresses, f.crit.Topics)
 	}
@@ -390,6 +519,9 @@ func (api *FilterAPI) GetFilterLogs(ctx context.Context, id rpc.ID) ([]*types.Lo
 	if err != nil {
 		return nil, err
 	}
+	if err := api.checkResults(logs); err != nil {
+		return nil, err
+	}
 	return returnLogs(logs), nil
 }
 
@@ -0,0 +1,264 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package filters
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/qydata/go-ctereum/common"
+	"github.com/qydata/go-ctereum/common/hexutil"
+	"github.com/qydata/go-ctereum/core/types"
+	"github.com/qydata/go-ctereum/crypto"
+	"github.com/qydata/go-ctereum/log"
+	"github.com/qydata/go-ctereum/rlp"
+	"github.com/qydata/go-ctereum/rpc"
+)
+
+const (
+	// defaultPageRange is the number of blocks scanned for a page of logs if
+	// the range of log queries is unlimited.
+	defaultPageRange = 2048
+
+	// defaultPageResults is the number of logs in a page if the results of log
+	// queries are unlimited.
+	defaultPageResults = 10000
+
+	// logReplayInterval is the pause between the pages of historical logs
+	// replayed to a log subscription.
+	logReplayInterval = 50 * time.Millisecond
+)
+
+// maxHeldLogs is the maximum number of live logs held back by a log subscription
+// during the replay of the historical ones. Past it, the live logs are dropped
+// and the replay extended up to the new head instead.
+var maxHeldLogs = 10000
+
+var (
+	errPagedBlockHash = errors.New("block hash queries cannot be paged")
+	errInvalidCursor  = errors.New("invalid cursor")
+	errInvalidRange   = errors.New("invalid block range")
+)
+
+// limitExceededError is returned if a log query exceeds the configured limits.
+type limitExceededError struct{ message string }
+
+func (e *limitExceededError) Error() string  { return e.message }
+func (e *limitExceededError) ErrorCode() int { return -32005 }
+
+// LogsPage is a page of the logs matching a query, along with the cursor to
+// resume the query from. The cursor is null on the last page.
+type LogsPage struct {
+	Logs   []*types.Log   `json:"logs"`
+	Cursor *hexutil.Bytes `json:"cursor"`
+}
+
+// logsCursor is the position of a paged log query, handed out to clients as an
+// opaque RLP blob.
+type logsCursor struct {
+	Query common.Hash // Digest of the addresses and topics of the query
+	Next  uint64      // Block to resume the query from
+	Index uint64      // Index of the first log to return in the resumed block
+	End   uint64      // Last block of the query
+}
+
+// checkRange rejects range queries spanning more blocks than configured.
+func (api *FilterAPI) checkRange(ctx context.Context, begin, end int64) error {
+	limit := api.sys.cfg.RangeLimit
+	if limit == 0 {
+		return nil
+	}
+	if begin < 0 || end < 0 {
+		header, _ := api.sys.backend.HeaderByNumber(ctx, rpc.LatestBlockNumber)
+		if header == nil {
+			return nil
+		}
+		if begin < 0 {
+			begin = header.Number.Int64()
+		}
+		if end < 0 {
+			end = header.Number.Int64()
+		}
+	}
+	if end >= begin && uint64(end-begin) >= limit {
+		return &limitExceededError{fmt.Sprintf("block range exceeds limit of %d blocks", limit)}
+	}
+	return nil
+}
+
+// checkResults rejects queries matching more logs than configured.
+func (api *FilterAPI) checkResults(logs []*types.Log) error {
+	if limit := api.sys.cfg.ResultLimit; limit > 0 && len(logs) > limit {
+		return &limitExceededError{fmt.Sprintf("query returned more than %d results", limit)}
+	}
+	return nil
+}
+
+// GetLogsPage returns the logs matching the given range query page by page, so
+// that large historical queries return partial results instead of exceeding
+// the limits of GetLogs or timing out. The first page is requested without a
+// cursor, the following ones with the cursor of the previous page along with
+// the same addresses and topics. The block range is fixed by the first page,
+// pending logs are never returned.
+//
+// A page scans at most the configured block range limit and holds at most the
+// configured result limit, so it may be empty while the cursor is set.
+func (api *FilterAPI) GetLogsPage(ctx context.Context, crit FilterCriteria, cursor *hexutil.Bytes) (*LogsPage, error) {
+	if crit.BlockHash != nil {
+		return nil, errPagedBlockHash
+	}
+	query, err := queryDigest(crit)
+	if err != nil {
+		return nil, err
+	}
+	var from, skip, end uint64
+	if cursor != nil {
+		var pos logsCursor
+		if err := rlp.DecodeBytes(*cursor, &pos); err != nil || pos.Query != query || pos.Next > pos.End {
+			return nil, errInvalidCursor
+		}
+		from, skip, end = pos.Next, pos.Index, pos.End
+	} else {
+		header, _ := api.sys.backend.HeaderByNumber(ctx, rpc.LatestBlockNumber)
+		if header == nil {
+			return &LogsPage{Logs: []*types.Log{}}, nil
+		}
+		from, end = header.Number.Uint64(), header.Number.Uint64()
+		if crit.FromBlock != nil && crit.FromBlock.Sign() >= 0 {
+			from = crit.FromBlock.Uint64()
+		}
+		if crit.ToBlock != nil && crit.ToBlock.Sign() >= 0 {
+			end = crit.ToBlock.Uint64()
+		}
+		if from > end {
+			return nil, errInvalidRange
+		}
+	}
+	blocks, results := api.pageLimits()
+
+	last := end
+	if last-from >= blocks {
+		last = from + blocks - 1
+	}
+	logs, err := api.sys.NewRangeFilter(int64(from), int64(last), crit.Addresses, crit.Topics).Logs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// Drop the logs of the resumed block already returned by the previous page
+	for len(logs) > 0 && logs[0].BlockNumber == from && uint64(logs[0].Index) < skip {
+		logs = logs[1:]
+	}
+	next := logsCursor{Query: query, End: end}
+	switch {
+	case len(logs) > results:
+		next.Next, next.Index = logs[results].BlockNumber, uint64(logs[results].Index)
+		logs = logs[:results]
+	case last < end:
+		next.Next = last + 1
+	default:
+		return &LogsPage{Logs: returnLogs(logs)}, nil
+	}
+	enc, err := rlp.EncodeToBytes(&next)
+	if err != nil {
+		return nil, err
+	}
+	return &LogsPage{Logs: returnLogs(logs), Cursor: (*hexutil.Bytes)(&enc)}, nil
+}
+
+// pageLimits returns the maximum number of blocks scanned and logs returned
+// for a page of logs.
+func (api *FilterAPI) pageLimits() (uint64, int) {
+	blocks, results := api.sys.cfg.RangeLimit, api.sys.cfg.ResultLimit
+	if blocks == 0 {
+		blocks = defaultPageRange
+	}
+	if results <= 0 {
+		results = defaultPageResults
+	}
+	return blocks, results
+}
+
+// queryDigest returns the digest of the addresses and topics of a query, tying
+// the cursors handed out to the query they belong to.
+func queryDigest(crit FilterCriteria) (common.Hash, error) {
+	enc, err := rlp.EncodeToBytes([]interface{}{crit.Addresses, crit.Topics})
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return crypto.Keccak256Hash(enc), nil
+}
+
+// logsReplayError is the notification ending a log subscription whose replay of
+// the historical logs failed.
+type logsReplayError struct {
+	Error string `json:"error"`
+}
+
+// replayLogs streams the historical logs matching the criteria, from their start
+// block up to the current head, one page at a time with a pause in between. It
+// returns the channel of the pages, closed once done, the channel the error of
+// a failed replay is delivered on before that, and the last block to be
+// replayed. No logs are replayed if the criteria start past the head.
+func (api *FilterAPI) replayLogs(ctx context.Context, crit FilterCriteria) (<-chan []*types.Log, <-chan error, uint64) {
+	header, _ := api.sys.backend.HeaderByNumber(ctx, rpc.LatestBlockNumber)
+	if header == nil || crit.FromBlock.Cmp(header.Number) > 0 {
+		return nil, nil, 0
+	}
+	head := header.Number.Uint64()
+	if crit.ToBlock != nil && crit.ToBlock.Sign() >= 0 && crit.ToBlock.Uint64() < head {
+		head = crit.ToBlock.Uint64()
+	}
+	crit.BlockHash, crit.ToBlock = nil, new(big.Int).SetUint64(head)
+
+	var (
+		pages = make(chan []*types.Log)
+		errc  = make(chan error, 1)
+	)
+	go func() {
+		defer close(pages)
+
+		var cursor *hexutil.Bytes
+		for {
+			page, err := api.GetLogsPage(ctx, crit, cursor)
+			if err != nil {
+				log.Debug("Failed to replay historical logs", "from", crit.FromBlock, "to", head, "err", err)
+				errc <- err
+				return
+			}
+			if len(page.Logs) > 0 {
+				select {
+				case pages <- page.Logs:
+				case <-ctx.Done():
+					return
+				}
+			}
+			if page.Cursor == nil {
+				return
+			}
+			cursor = page.Cursor
+			select {
+			case <-time.After(logReplayInterval):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return pages, errc, head
+}
@@ -0,0 +1,265 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package filters
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/qydata/go-ctereum/common"
+	"github.com/qydata/go-ctereum/consensus/ethash"
+	"github.com/qydata/go-ctereum/core"
+	"github.com/qydata/go-ctereum/core/rawdb"
+	"github.com/qydata/go-ctereum/core/types"
+	"github.com/qydata/go-ctereum/ethdb"
+	"github.com/qydata/go-ctereum/params"
+	"github.com/qydata/go-ctereum/rpc"
+)
+
+// writeLogChain generates a chain of the given length and writes it to the
+// database, the blocks in counts holding the given number of logs of the address
+// and topic.
+func writeLogChain(db ethdb.Database, gspec *core.Genesis, length int, addr common.Address, topic common.Hash, counts map[int]int) {
+	genesis := gspec.MustCommit(db)
+	chain, receipts := core.GenerateChain(params.TestChainConfig, genesis, ethash.NewFaker(), db, length, func(i int, gen *core.BlockGen) {
+		n := counts[i+1]
+		if n == 0 {
+			return
+		}
+		receipt := types.NewReceipt(nil, false, 0)
+		for j := 0; j < n; j++ {
+			receipt.Logs = append(receipt.Logs, &types.Log{Address: addr, Topics: []common.Hash{topic}})
+		}
+		gen.AddUncheckedReceipt(receipt)
+		gen.AddUncheckedTx(types.NewTransaction(uint64(i), common.HexToAddress("0x1"), big.NewInt(1), 1, gen.BaseFee(), nil))
+	})
+	for i, block := range chain {
+		rawdb.WriteBlock(db, block)
+		rawdb.WriteCanonicalHash(db, block.Hash(), block.NumberU64())
+		rawdb.WriteHeadBlockHash(db, block.Hash())
+		rawdb.WriteReceipts(db, block.Hash(), block.NumberU64(), receipts[i])
+	}
+}
+
+// Tests that log queries exceeding the limits are rejected by eth_getLogs and
+// served page by page by eth_getLogsPage.
+func TestGetLogsPage(t *testing.T) {
+	var (
+		db     = rawdb.NewMemoryDatabase()
+		_, sys = newTestFilterSystem(t, db, Config{RangeLimit: 10, ResultLimit: 3})
+		api    = NewFilterAPI(sys, false)
+		addr   = common.HexToAddress("0x1000")
+		topic  = common.BytesToHash([]byte("topic"))
+		gspec  = &core.Genesis{BaseFee: big.NewInt(params.InitialBaseFee)}
+	)
+	// Blocks 5, 6 and 25 hold two matching logs each, block 26 a single one
+	writeLogChain(db, gspec, 30, addr, topic, map[int]int{5: 2, 6: 2, 25: 2, 26: 1})
+
+	crit := FilterCriteria{
+		FromBlock: big.NewInt(1),
+		ToBlock:   big.NewInt(30),
+		Addresses: []common.Address{addr},
+		Topics:    [][]common.Hash{{topic}},
+	}
+	var limitErr *limitExceededError
+	if _, err := api.GetLogs(context.Background(), crit); !errors.As(err, &limitErr) {
+		t.Fatalf("range limit not enforced: %v", err)
+	}
+	narrow := crit
+	narrow.FromBlock, narrow.ToBlock = big.NewInt(1), big.NewInt(10)
+	if _, err := api.GetLogs(context.Background(), narrow); !errors.As(err, &limitErr) {
+		t.Fatalf("result limit not enforced: %v", err)
+	}
+	narrow.FromBlock, narrow.ToBlock = big.NewInt(6), big.NewInt(15)
+	if logs, err := api.GetLogs(context.Background(), narrow); err != nil || len(logs) != 2 {
+		t.Fatalf("query within limits failed: %d logs, %v", len(logs), err)
+	}
+	// Page through the whole range, expecting every log exactly once
+	type position struct {
+		block uint64
+		index uint
+	}
+	var (
+		seen  []position
+		pages []int
+	)
+	page, err := api.GetLogsPage(context.Background(), crit, nil)
+	for {
+		if err != nil {
+			t.Fatalf("page %d failed: %v", len(pages), err)
+		}
+		for _, log := range page.Logs {
+			seen = append(seen, position{log.BlockNumber, log.Index})
+		}
+		pages = append(pages, len(page.Logs))
+		if page.Cursor == nil {
+			break
+		}
+		page, err = api.GetLogsPage(context.Background(), crit, page.Cursor)
+	}
+	want := []position{{5, 0}, {5, 1}, {6, 0}, {6, 1}, {25, 0}, {25, 1}, {26, 0}}
+	if len(seen) != len(want) {
+		t.Fatalf("paged logs mismatch: have %v, want %v", seen, want)
+	}
+	for i := range want {
+		if seen[i] != want[i] {
+			t.Fatalf("paged log %d mismatch: have %v, want %v", i, seen[i], want[i])
+		}
+	}
+	// Blocks 1-10 overflow the result limit in block 6, the next pages resume
+	// there and scan 6-15, 16-25 and 26-30
+	wantPages := []int{3, 1, 2, 1}
+	if len(pages) != len(wantPages) {
+		t.Fatalf("page sizes mismatch: have %v, want %v", pages, wantPages)
+	}
+	for i := range wantPages {
+		if pages[i] != wantPages[i] {
+			t.Fatalf("page sizes mismatch: have %v, want %v", pages, wantPages)
+		}
+	}
+	// Cursors must not be reusable with other queries
+	page, _ = api.GetLogsPage(context.Background(), crit, nil)
+	other := crit
+	other.Topics = [][]common.Hash{{common.BytesToHash([]byte("other"))}}
+	if _, err := api.GetLogsPage(context.Background(), other, page.Cursor); err != errInvalidCursor {
+		t.Fatalf("cursor of another query accepted: %v", err)
+	}
+}
+
+// Tests that log subscriptions starting at a past block replay the historical
+// logs in order before switching to the live ones, without duplicates.
+func TestLogsReplay(t *testing.T) {
+	var (
+		db           = rawdb.NewMemoryDatabase()
+		backend, sys = newTestFilterSystem(t, db, Config{RangeLimit: 10, ResultLimit: 3})
+		addr         = common.HexToAddress("0x1000")
+		topic        = common.BytesToHash([]byte("topic"))
+	)
+	writeLogChain(db, &core.Genesis{BaseFee: big.NewInt(params.InitialBaseFee)}, 30, addr, topic, map[int]int{5: 2, 6: 2, 25: 2, 26: 1})
+
+	server := rpc.NewServer()
+	defer server.Stop()
+	if err := server.RegisterName("eth", NewFilterAPI(sys, false)); err != nil {
+		t.Fatalf("failed to register filter API: %v", err)
+	}
+	client := rpc.DialInProc(server)
+	defer client.Close()
+
+	logs := make(chan types.Log)
+	sub, err := client.EthSubscribe(context.Background(), logs, "logs", map[string]interface{}{
+		"fromBlock": "0x6",
+		"address":   addr,
+		"topics":    [][]common.Hash{{topic}},
+	})
+	if err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+	defer sub.Unsubscribe()
+
+	// Logs arriving during the replay are held back, those of the replayed
+	// blocks dropped as duplicates
+	backend.logsFeed.Send([]*types.Log{
+		{Address: addr, Topics: []common.Hash{topic}, BlockNumber: 26},
+		{Address: addr, Topics: []common.Hash{topic}, BlockNumber: 31},
+	})
+	want := []uint64{6, 6, 25, 25, 26, 31}
+	for i, number := range want {
+		select {
+		case log := <-logs:
+			if log.BlockNumber != number {
+				t.Fatalf("log %d block mismatch: have %d, want %d", i, log.BlockNumber, number)
+			}
+		case err := <-sub.Err():
+			t.Fatalf("subscription failed: %v", err)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("log %d not received", i)
+		}
+	}
+	select {
+	case log := <-logs:
+		t.Fatalf("unexpected log: %+v", log)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+// Tests that log subscriptions holding back too many live logs during a replay
+// drop them and extend the replay up to the new head instead.
+func TestLogsReplayOverflow(t *testing.T) {
+	defer func(limit int) { maxHeldLogs = limit }(maxHeldLogs)
+	maxHeldLogs = 1
+
+	var (
+		db           = rawdb.NewMemoryDatabase()
+		backend, sys = newTestFilterSystem(t, db, Config{RangeLimit: 10, ResultLimit: 1})
+		addr         = common.HexToAddress("0x1000")
+		topic        = common.BytesToHash([]byte("topic"))
+	)
+	writeLogChain(db, &core.Genesis{BaseFee: big.NewInt(params.InitialBaseFee)}, 35, addr, topic, map[int]int{5: 2, 6: 2, 25: 2, 26: 1, 33: 1})
+
+	// Start the replay at block 30, the logs of the following blocks arriving
+	// live during the replay
+	head := rawdb.ReadHeadBlockHash(db)
+	rawdb.WriteHeadBlockHash(db, rawdb.ReadCanonicalHash(db, 30))
+
+	server := rpc.NewServer()
+	defer server.Stop()
+	if err := server.RegisterName("eth", NewFilterAPI(sys, false)); err != nil {
+		t.Fatalf("failed to register filter API: %v", err)
+	}
+	client := rpc.DialInProc(server)
+	defer client.Close()
+
+	logs := make(chan types.Log)
+	sub, err := client.EthSubscribe(context.Background(), logs, "logs", map[string]interface{}{
+		"fromBlock": "0x6",
+		"address":   addr,
+		"topics":    [][]common.Hash{{topic}},
+	})
+	if err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+	defer sub.Unsubscribe()
+
+	// Overflow the held logs, dropping the one of block 33 to be replayed from
+	// the database and the one of block 34, unknown to the database, for good
+	rawdb.WriteHeadBlockHash(db, head)
+	backend.logsFeed.Send([]*types.Log{
+		{Address: addr, Topics: []common.Hash{topic}, BlockNumber: 33},
+		{Address: addr, Topics: []common.Hash{topic}, BlockNumber: 34},
+	})
+	want := []uint64{6, 6, 25, 25, 26, 33}
+	for i, number := range want {
+		select {
+		case log := <-logs:
+			if log.BlockNumber != number {
+				t.Fatalf("log %d block mismatch: have %d, want %d", i, log.BlockNumber, number)
+			}
+		case err := <-sub.Err():
+			t.Fatalf("subscription failed: %v", err)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("log %d not received", i)
+		}
+	}
+	select {
+	case log := <-logs:
+		t.Fatalf("unexpected log: %+v", log)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
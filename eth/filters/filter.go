@@ -20,9 +20,11 @@ import (
 	"context"
 	"errors"
 	"math/big"
+	"sort"
 
 	"github.com/qydata/go-ctereum/common"
 	"github.com/qydata/go-ctereum/core/bloombits"
+	"github.com/qydata/go-ctereum/core/rawdb"
 	"github.com/qydata/go-ctereum/core/types"
 	"github.com/qydata/go-ctereum/rpc"
 )
@@ -130,17 +132,19 @@ func (f *Filter) Logs(ctx context.Context) ([]*types.Log, error) {
 		end = head
 	}
 	// Gather all indexed logs, and finish with non indexed ones
-	var (
-		logs           []*types.Log
-		err            error
-		size, sections = f.sys.backend.BloomStatus()
-	)
+	logs, err := f.logIndexedLogs(ctx, end)
+	if err != nil {
+		return logs, err
+	}
+	size, sections := f.sys.backend.BloomStatus()
 	if indexed := sections * size; indexed > uint64(f.begin) {
+		var found []*types.Log
 		if indexed > end {
-			logs, err = f.indexedLogs(ctx, end)
+			found, err = f.indexedLogs(ctx, end)
 		} else {
-			logs, err = f.indexedLogs(ctx, indexed-1)
+			found, err = f.indexedLogs(ctx, indexed-1)
 		}
+		logs = append(logs, found...)
 		if err != nil {
 			return logs, err
 		}
@@ -157,6 +161,58 @@ func (f *Filter) Logs(ctx context.Context) ([]*types.Log, error) {
 	return logs, err
 }
 
+// logIndexedLogs returns the logs matching the filter criteria from the blocks
+// the log index lists for the filtered event signatures, up to the given block
+// or the last one indexed. Nothing is returned if the index doesn't cover the
+// event signatures or the start of the range.
+func (f *Filter) logIndexedLogs(ctx context.Context, end uint64) ([]*types.Log, error) {
+	if len(f.topics) == 0 || len(f.topics[0]) == 0 || f.begin < 0 || uint64(f.begin) > end {
+		return nil, nil
+	}
+	db := f.sys.backend.ChainDb()
+	progress := rawdb.ReadLogIndexProgress(db)
+	if progress == nil || uint64(f.begin) < progress.Tail || uint64(f.begin) > progress.Head {
+		return nil, nil
+	}
+	for _, topic := range f.topics[0] {
+		if !progress.Covers(topic) {
+			return nil, nil
+		}
+	}
+	if end > progress.Head {
+		end = progress.Head
+	}
+	// Merge the blocks listed for the alternative event signatures
+	var numbers []uint64
+	for _, topic := range f.topics[0] {
+		numbers = append(numbers, rawdb.ReadLogIndexBlocks(db, topic, uint64(f.begin), end)...)
+	}
+	sort.Slice(numbers, func(i, j int) bool { return numbers[i] < numbers[j] })
+
+	var logs []*types.Log
+	for i, number := range numbers {
+		if i > 0 && number == numbers[i-1] {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return logs, err
+		}
+		f.begin = int64(number) + 1
+
+		header, err := f.sys.backend.HeaderByNumber(ctx, rpc.BlockNumber(number))
+		if header == nil || err != nil {
+			return logs, err
+		}
+		found, err := f.checkMatches(ctx, header)
+		if err != nil {
+			return logs, err
+		}
+		logs = append(logs, found...)
+	}
+	f.begin = int64(end) + 1
+	return logs, nil
+}
+
 // indexedLogs returns the logs matching the filter criteria based on the bloom
 // bits indexed available locally or via the network.
 func (f *Filter) indexedLogs(ctx context.Context, end uint64) ([]*types.Log, error) {
@@ -41,6 +41,8 @@ import (
 type Config struct {
 	LogCacheSize int           // maximum number of cached blocks (default: 32)
 	Timeout      time.Duration // how long filters stay active (default: 5min)
+	RangeLimit   uint64        // maximum number of blocks spanned by a log query (0 = unlimited)
+	ResultLimit  int           // maximum number of logs returned by a log query (0 = unlimited)
 }
 
 func (cfg Config) withDefaults() Config {
@@ -63,6 +65,7 @@ type Backend interface {
 
 	SubscribeNewTxsEvent(chan<- core.NewTxsEvent) event.Subscription
 	SubscribeChainEvent(ch chan<- core.ChainEvent) event.Subscription
+	SubscribeChain2HeadEvent(ch chan<- core.Chain2HeadEvent) event.Subscription
 	SubscribeRemovedLogsEvent(ch chan<- core.RemovedLogsEvent) event.Subscription
 	SubscribeLogsEvent(ch chan<- []*types.Log) event.Subscription
 	SubscribePendingLogsEvent(ch chan<- []*types.Log) event.Subscription
@@ -129,6 +132,9 @@ const (
 	PendingTransactionsSubscription
 	// BlocksSubscription queries hashes for blocks that are imported
 	BlocksSubscription
+	// Chain2HeadSubscription queries head, fork and reorg notifications
+	// carrying the old and new chain segments
+	Chain2HeadSubscription
 	// LastSubscription keeps track of the last index
 	LastIndexSubscription
 )
@@ -143,6 +149,8 @@ const (
 	logsChanSize = 10
 	// chainEvChanSize is the size of channel listening to ChainEvent.
 	chainEvChanSize = 10
+	// chain2HeadChanSize is the size of channel listening to Chain2HeadEvent.
+	chain2HeadChanSize = 10
 )
 
 type subscription struct {
@@ -153,6 +161,7 @@ type subscription struct {
 	logs      chan []*types.Log
 	hashes    chan []common.Hash
 	headers   chan *types.Header
+	chain2Hs  chan core.Chain2HeadEvent
 	installed chan struct{} // closed when the filter is installed
 	err       chan error    // closed when the filter is uninstalled
 }
@@ -171,6 +180,7 @@ type EventSystem struct {
 	rmLogsSub      event.Subscription // Subscription for removed log event
 	pendingLogsSub event.Subscription // Subscription for pending log event
 	chainSub       event.Subscription // Subscription for new chain event
+	chain2HeadSub  event.Subscription // Subscription for new head, fork and reorg event
 
 	// Channels
 	install       chan *subscription         // install filter for event notification
@@ -180,6 +190,7 @@ type EventSystem struct {
 	pendingLogsCh chan []*types.Log          // Channel to receive new log event
 	rmLogsCh      chan core.RemovedLogsEvent // Channel to receive removed log event
 	chainCh       chan core.ChainEvent       // Channel to receive new chain event
+	chain2HeadCh  chan core.Chain2HeadEvent  // Channel to receive new head, fork and reorg event
 }
 
 // NewEventSystem creates a new manager that listens for event on the given mux,
@@ -200,6 +211,7 @@ func NewEventSystem(sys *FilterSystem, lightMode bool) *EventSystem {
 		rmLogsCh:      make(chan core.RemovedLogsEvent, rmLogsChanSize),
 		pendingLogsCh: make(chan []*types.Log, logsChanSize),
 		chainCh:       make(chan core.ChainEvent, chainEvChanSize),
+		chain2HeadCh:  make(chan core.Chain2HeadEvent, chain2HeadChanSize),
 	}
 
 	// Subscribe events
@@ -208,9 +220,10 @@ func NewEventSystem(sys *FilterSystem, lightMode bool) *EventSystem {
 	m.rmLogsSub = m.backend.SubscribeRemovedLogsEvent(m.rmLogsCh)
 	m.chainSub = m.backend.SubscribeChainEvent(m.chainCh)
 	m.pendingLogsSub = m.backend.SubscribePendingLogsEvent(m.pendingLogsCh)
+	m.chain2HeadSub = m.backend.SubscribeChain2HeadEvent(m.chain2HeadCh)
 
 	// Make sure none of the subscriptions are empty
-	if m.txsSub == nil || m.logsSub == nil || m.rmLogsSub == nil || m.chainSub == nil || m.pendingLogsSub == nil {
+	if m.txsSub == nil || m.logsSub == nil || m.rmLogsSub == nil || m.chainSub == nil || m.pendingLogsSub == nil || m.chain2HeadSub == nil {
 		log.Crit("Subscribe for event system failed")
 	}
 
@@ -246,6 +259,7 @@ func (sub *Subscription) Unsubscribe() {
 			case <-sub.f.logs:
 			case <-sub.f.hashes:
 			case <-sub.f.headers:
+			case <-sub.f.chain2Hs:
 			}
 		}
 
@@ -313,6 +327,7 @@ func (es *EventSystem) subscribeMinedPendingLogs(crit ethereum.FilterQuery, logs
 		logs:      logs,
 		hashes:    make(chan []common.Hash),
 		headers:   make(chan *types.Header),
+		chain2Hs:  make(chan core.Chain2HeadEvent),
 		installed: make(chan struct{}),
 		err:       make(chan error),
 	}
@@ -330,6 +345,7 @@ func (es *EventSystem) subscribeLogs(crit ethereum.FilterQuery, logs chan []*typ
 		logs:      logs,
 		hashes:    make(chan []common.Hash),
 		headers:   make(chan *types.Header),
+		chain2Hs:  make(chan core.Chain2HeadEvent),
 		installed: make(chan struct{}),
 		err:       make(chan error),
 	}
@@ -347,6 +363,7 @@ func (es *EventSystem) subscribePendingLogs(crit ethereum.FilterQuery, logs chan
 		logs:      logs,
 		hashes:    make(chan []common.Hash),
 		headers:   make(chan *types.Header),
+		chain2Hs:  make(chan core.Chain2HeadEvent),
 		installed: make(chan struct{}),
 		err:       make(chan error),
 	}
@@ -363,6 +380,7 @@ func (es *EventSystem) SubscribeNewHeads(headers chan *types.Header) *Subscripti
 		logs:      make(chan []*types.Log),
 		hashes:    make(chan []common.Hash),
 		headers:   headers,
+		chain2Hs:  make(chan core.Chain2HeadEvent),
 		installed: make(chan struct{}),
 		err:       make(chan error),
 	}
@@ -379,6 +397,24 @@ func (es *EventSystem) SubscribePendingTxs(hashes chan []common.Hash) *Subscript
 		logs:      make(chan []*types.Log),
 		hashes:    hashes,
 		headers:   make(chan *types.Header),
+		chain2Hs:  make(chan core.Chain2HeadEvent),
+		installed: make(chan struct{}),
+		err:       make(chan error),
+	}
+	return es.subscribe(sub)
+}
+
+// SubscribeChain2Head creates a subscription that writes head, fork and reorg
+// events, including the affected chain segments, as they happen.
+func (es *EventSystem) SubscribeChain2Head(chain2Hs chan core.Chain2HeadEvent) *Subscription {
+	sub := &subscription{
+		id:        rpc.NewID(),
+		typ:       Chain2HeadSubscription,
+		created:   time.Now(),
+		logs:      make(chan []*types.Log),
+		hashes:    make(chan []common.Hash),
+		headers:   make(chan *types.Header),
+		chain2Hs:  chain2Hs,
 		installed: make(chan struct{}),
 		err:       make(chan error),
 	}
@@ -445,6 +481,12 @@ func (es *EventSystem) handleChainEvent(filters filterIndex, ev core.ChainEvent)
 	}
 }
 
+func (es *EventSystem) handleChain2HeadEvent(filters filterIndex, ev core.Chain2HeadEvent) {
+	for _, f := range filters[Chain2HeadSubscription] {
+		f.chain2Hs <- ev
+	}
+}
+
 func (es *EventSystem) lightFilterNewHead(newHeader *types.Header, callBack func(*types.Header, bool)) {
 	oldh := es.lastHead
 	es.lastHead = newHeader
@@ -527,6 +569,7 @@ func (es *EventSystem) eventLoop() {
 		es.rmLogsSub.Unsubscribe()
 		es.pendingLogsSub.Unsubscribe()
 		es.chainSub.Unsubscribe()
+		es.chain2HeadSub.Unsubscribe()
 	}()
 
 	index := make(filterIndex)
@@ -546,6 +589,8 @@ func (es *EventSystem) eventLoop() {
 			es.handlePendingLogs(index, ev)
 		case ev := <-es.chainCh:
 			es.handleChainEvent(index, ev)
+		case ev := <-es.chain2HeadCh:
+			es.handleChain2HeadEvent(index, ev)
 
 		case f := <-es.install:
 			if f.typ == MinedAndPendingLogsSubscription {
@@ -576,6 +621,8 @@ func (es *EventSystem) eventLoop() {
 			return
 		case <-es.chainSub.Err():
 			return
+		case <-es.chain2HeadSub.Err():
+			return
 		}
 	}
 }
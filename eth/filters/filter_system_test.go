@@ -47,6 +47,7 @@ type testBackend struct {
 	rmLogsFeed      event.Feed
 	pendingLogsFeed event.Feed
 	chainFeed       event.Feed
+	chain2HeadFeed  event.Feed
 }
 
 func (b *testBackend) ChainDb() ethdb.Database {
@@ -116,6 +117,10 @@ func (b *testBackend) SubscribeChainEvent(ch chan<- core.ChainEvent) event.Subsc
 	return b.chainFeed.Subscribe(ch)
 }
 
+func (b *testBackend) SubscribeChain2HeadEvent(ch chan<- core.Chain2HeadEvent) event.Subscription {
+	return b.chain2HeadFeed.Subscribe(ch)
+}
+
 func (b *testBackend) BloomStatus() (uint64, uint64) {
 	return params.BloomBitsBlocks, b.sections
 }
@@ -209,6 +214,68 @@ func TestBlockSubscription(t *testing.T) {
 	<-sub1.Err()
 }
 
+// TestChain2HeadSubscription tests that head, fork and reorg events are relayed
+// to chain2Head subscribers with both chain segments intact.
+func TestChain2HeadSubscription(t *testing.T) {
+	t.Parallel()
+
+	var (
+		db           = rawdb.NewMemoryDatabase()
+		backend, sys = newTestFilterSystem(t, db, Config{})
+		api          = NewFilterAPI(sys, false)
+		genesis      = (&core.Genesis{BaseFee: big.NewInt(params.InitialBaseFee)}).MustCommit(db)
+		oldChain, _  = core.GenerateChain(params.TestChainConfig, genesis, ethash.NewFaker(), db, 3, func(i int, gen *core.BlockGen) {})
+		newChain, _  = core.GenerateChain(params.TestChainConfig, genesis, ethash.NewFaker(), db, 4, func(i int, gen *core.BlockGen) {
+			gen.SetExtra([]byte("fork"))
+		})
+		events = []core.Chain2HeadEvent{
+			{Type: core.Chain2HeadCanonicalEvent, NewChain: []*types.Block{oldChain[2]}},
+			{Type: core.Chain2HeadForkEvent, NewChain: []*types.Block{newChain[1]}},
+			{
+				Type:     core.Chain2HeadReorgEvent,
+				NewChain: []*types.Block{newChain[3], newChain[2], newChain[1], newChain[0]},
+				OldChain: []*types.Block{oldChain[2], oldChain[1], oldChain[0]},
+			},
+		}
+	)
+
+	ch := make(chan core.Chain2HeadEvent)
+	sub := api.events.SubscribeChain2Head(ch)
+	defer sub.Unsubscribe()
+
+	go func() {
+		for _, ev := range events {
+			backend.chain2HeadFeed.Send(ev)
+		}
+	}()
+
+	for i, want := range events {
+		select {
+		case have := <-ch:
+			res := newChain2HeadResult(have)
+			if res.Type != want.Type {
+				t.Fatalf("event %d: type mismatch: have %s, want %s", i, res.Type, want.Type)
+			}
+			if len(res.NewChain) != len(want.NewChain) || len(res.OldChain) != len(want.OldChain) {
+				t.Fatalf("event %d: segment length mismatch: have %d/%d, want %d/%d", i,
+					len(res.NewChain), len(res.OldChain), len(want.NewChain), len(want.OldChain))
+			}
+			for j, header := range res.NewChain {
+				if header.Hash() != want.NewChain[j].Hash() {
+					t.Errorf("event %d: new chain %d mismatch: have %x, want %x", i, j, header.Hash(), want.NewChain[j].Hash())
+				}
+			}
+			for j, header := range res.OldChain {
+				if header.Hash() != want.OldChain[j].Hash() {
+					t.Errorf("event %d: old chain %d mismatch: have %x, want %x", i, j, header.Hash(), want.OldChain[j].Hash())
+				}
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("event %d: timeout waiting for %s event", i, want.Type)
+		}
+	}
+}
+
 // TestPendingTxFilter tests whether pending tx filters retrieve all pending transactions that are posted to the event mux.
 func TestPendingTxFilter(t *testing.T) {
 	t.Parallel()
@@ -19,6 +19,7 @@ package filters
 import (
 	"context"
 	"math/big"
+	"reflect"
 	"testing"
 
 	"github.com/qydata/go-ctereum/common"
@@ -230,3 +231,73 @@ func TestFilters(t *testing.T) {
 		t.Error("expected 0 log, got", len(logs))
 	}
 }
+
+// Tests that range filters on indexed event signatures take the blocks to look
+// at from the log index, falling back to the blooms past its head.
+func TestLogIndexFilters(t *testing.T) {
+	var (
+		db      = rawdb.NewMemoryDatabase()
+		_, sys  = newTestFilterSystem(t, db, Config{})
+		key1, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr    = crypto.PubkeyToAddress(key1.PublicKey)
+
+		indexed   = common.BytesToHash([]byte("indexed"))
+		unindexed = common.BytesToHash([]byte("unindexed"))
+
+		gspec = core.Genesis{
+			Alloc:   core.GenesisAlloc{addr: {Balance: big.NewInt(1000000)}},
+			BaseFee: big.NewInt(params.InitialBaseFee),
+		}
+		genesis = gspec.ToBlock()
+	)
+	gspec.MustCommit(db)
+
+	chain, receipts := core.GenerateChain(params.TestChainConfig, genesis, ethash.NewFaker(), db, 20, func(i int, gen *core.BlockGen) {
+		switch i {
+		case 2, 6, 11, 17:
+			receipt := types.NewReceipt(nil, false, 0)
+			receipt.Logs = []*types.Log{
+				{Address: addr, Topics: []common.Hash{indexed}},
+				{Address: addr, Topics: []common.Hash{unindexed}},
+			}
+			gen.AddUncheckedReceipt(receipt)
+			gen.AddUncheckedTx(types.NewTransaction(uint64(i), common.HexToAddress("0x1"), big.NewInt(1), 1, gen.BaseFee(), nil))
+		}
+	})
+	for i, block := range chain {
+		rawdb.WriteBlock(db, block)
+		rawdb.WriteCanonicalHash(db, block.Hash(), block.NumberU64())
+		rawdb.WriteHeadBlockHash(db, block.Hash())
+		rawdb.WriteReceipts(db, block.Hash(), block.NumberU64(), receipts[i])
+	}
+	// Index blocks 1-15, leaving out block 7 to detect the use of the index
+	rawdb.WriteLogIndexProgress(db, &rawdb.LogIndexProgress{Tail: 1, Head: 15, Topics: []common.Hash{indexed}})
+	rawdb.WriteLogIndexEntry(db, indexed, 3)
+	rawdb.WriteLogIndexEntry(db, indexed, 12)
+
+	tests := []struct {
+		begin, end int64
+		topic      common.Hash
+		want       []uint64
+	}{
+		{1, -1, indexed, []uint64{3, 12, 18}},
+		{4, 15, indexed, []uint64{12}},
+		{0, -1, indexed, []uint64{3, 7, 12, 18}},   // range starts below the index
+		{16, -1, indexed, []uint64{18}},            // range starts above the index
+		{1, -1, unindexed, []uint64{3, 7, 12, 18}}, // signature not covered
+	}
+	for i, tt := range tests {
+		filter := sys.NewRangeFilter(tt.begin, tt.end, []common.Address{addr}, [][]common.Hash{{tt.topic}})
+		logs, err := filter.Logs(context.Background())
+		if err != nil {
+			t.Fatalf("test %d: failed to filter logs: %v", i, err)
+		}
+		var have []uint64
+		for _, log := range logs {
+			have = append(have, log.BlockNumber)
+		}
+		if !reflect.DeepEqual(have, tt.want) {
+			t.Errorf("test %d: block mismatch: have %v, want %v", i, have, tt.want)
+		}
+	}
+}
@@ -61,6 +61,7 @@ type processedFees struct {
 	reward               []*big.Int
 	baseFee, nextBaseFee *big.Int
 	gasUsedRatio         float64
+	empty                bool // Whether the block holds no transactions
 }
 
 // txGasAndReward is sorted in ascending order based on reward
@@ -94,6 +95,7 @@ func (oracle *Oracle) processBlock(bf *blockFees, percentiles []float64) {
 		bf.results.nextBaseFee = new(big.Int)
 	}
 	bf.results.gasUsedRatio = float64(bf.header.GasUsed) / float64(bf.header.GasLimit)
+	bf.results.empty = bf.header.TxHash == types.EmptyRootHash
 	if len(percentiles) == 0 {
 		// rewards were not requested, return null
 		return
@@ -213,6 +215,11 @@ func (oracle *Oracle) resolveBlockRange(ctx context.Context, reqEnd rpc.BlockNum
 //   - baseFee: base fee per gas in the given block
 //   - gasUsedRatio: gasUsed/gasLimit in the given block
 //
+// Clique seals blocks at a fixed period whether there are transactions to include
+// or not, so empty blocks are common on PoA chains. The rewards of an empty block
+// of a clique chain are the ones of the last non-empty block of the range preceding
+// it instead of zero, so that idle periods do not drag the percentiles down.
+//
 // Note: baseFee includes the next block after the newest of the returned range, because this
 // value can be derived from the newest block.
 func (oracle *Oracle) FeeHistory(ctx context.Context, blocks int, unresolvedLastBlock rpc.BlockNumber, rewardPercentiles []float64) (*big.Int, [][]*big.Int, []*big.Int, []float64, error) {
@@ -305,6 +312,7 @@ func (oracle *Oracle) FeeHistory(ctx context.Context, blocks int, unresolvedLast
 		reward       = make([][]*big.Int, blocks)
 		baseFee      = make([]*big.Int, blocks+1)
 		gasUsedRatio = make([]float64, blocks)
+		empty        = make([]bool, blocks)
 		firstMissing = blocks
 	)
 	for ; blocks > 0; blocks-- {
@@ -315,6 +323,7 @@ func (oracle *Oracle) FeeHistory(ctx context.Context, blocks int, unresolvedLast
 		i := int(fees.blockNumber - oldestBlock)
 		if fees.results.baseFee != nil {
 			reward[i], baseFee[i], baseFee[i+1], gasUsedRatio[i] = fees.results.reward, fees.results.baseFee, fees.results.nextBaseFee, fees.results.gasUsedRatio
+			empty[i] = fees.results.empty
 		} else {
 			// getting no block and no error means we are requesting into the future (might happen because of a reorg)
 			if i < firstMissing {
@@ -327,9 +336,28 @@ func (oracle *Oracle) FeeHistory(ctx context.Context, blocks int, unresolvedLast
 	}
 	if len(rewardPercentiles) != 0 {
 		reward = reward[:firstMissing]
+		if oracle.backend.ChainConfig().Clique != nil {
+			fillEmptyRewards(reward, empty)
+		}
 	} else {
 		reward = nil
 	}
 	baseFee, gasUsedRatio = baseFee[:firstMissing+1], gasUsedRatio[:firstMissing]
 	return new(big.Int).SetUint64(oldestBlock), reward, baseFee, gasUsedRatio, nil
 }
+
+// fillEmptyRewards replaces the rewards of the empty blocks with the ones of the
+// last non-empty block preceding them. Empty blocks at the start of the range
+// keep their all zero rewards.
+func fillEmptyRewards(reward [][]*big.Int, empty []bool) {
+	var last []*big.Int
+	for i := range reward {
+		if !empty[i] {
+			last = reward[i]
+			continue
+		}
+		if last != nil {
+			reward[i] = last
+		}
+	}
+}
@@ -89,3 +89,22 @@ func TestFeeHistory(t *testing.T) {
 		}
 	}
 }
+
+func TestFillEmptyRewards(t *testing.T) {
+	var (
+		zero   = []*big.Int{new(big.Int), new(big.Int)}
+		first  = []*big.Int{big.NewInt(1), big.NewInt(2)}
+		second = []*big.Int{big.NewInt(3), big.NewInt(4)}
+	)
+	reward := [][]*big.Int{zero, first, zero, zero, second, zero}
+	fillEmptyRewards(reward, []bool{true, false, true, true, false, true})
+
+	want := [][]*big.Int{zero, first, first, first, second, second}
+	for i := range want {
+		for j := range want[i] {
+			if reward[i][j].Cmp(want[i][j]) != 0 {
+				t.Errorf("block %d percentile %d: reward mismatch, want %v, got %v", i, j, want[i][j], reward[i][j])
+			}
+		}
+	}
+}
@@ -47,6 +47,14 @@ type Config struct {
 	Default          *big.Int `toml:",omitempty"`
 	MaxPrice         *big.Int `toml:",omitempty"`
 	IgnorePrice      *big.Int `toml:",omitempty"`
+
+	// MaxEmptyBlocks is the number of empty blocks looked past on clique chains
+	// to find the non-empty blocks to sample.
+	MaxEmptyBlocks int
+
+	// MinTip is the lowest tip suggested on clique chains, the default price if
+	// not set.
+	MinTip *big.Int `toml:",omitempty"`
 }
 
 // OracleBackend includes all necessary background APIs for oracle.
@@ -67,11 +75,13 @@ type Oracle struct {
 	lastPrice   *big.Int
 	maxPrice    *big.Int
 	ignorePrice *big.Int
+	minTip      *big.Int
 	cacheLock   sync.RWMutex
 	fetchLock   sync.Mutex
 
 	checkBlocks, percentile           int
 	maxHeaderHistory, maxBlockHistory int
+	maxEmptyBlocks                    int
 	historyCache                      *lru.Cache
 }
 
@@ -113,6 +123,15 @@ func NewOracle(backend OracleBackend, params Config) *Oracle {
 		maxBlockHistory = 1
 		log.Warn("Sanitizing invalid gasprice oracle max block history", "provided", params.MaxBlockHistory, "updated", maxBlockHistory)
 	}
+	maxEmptyBlocks := params.MaxEmptyBlocks
+	if maxEmptyBlocks < 0 {
+		maxEmptyBlocks = 0
+		log.Warn("Sanitizing invalid gasprice oracle max empty blocks", "provided", params.MaxEmptyBlocks, "updated", maxEmptyBlocks)
+	}
+	minTip := params.MinTip
+	if minTip == nil || minTip.Sign() <= 0 {
+		minTip = params.Default
+	}
 
 	cache, _ := lru.New(2048)
 	headEvent := make(chan core.ChainHeadEvent, 1)
@@ -132,10 +151,12 @@ func NewOracle(backend OracleBackend, params Config) *Oracle {
 		lastPrice:        params.Default,
 		maxPrice:         maxPrice,
 		ignorePrice:      ignorePrice,
+		minTip:           minTip,
 		checkBlocks:      blocks,
 		percentile:       percent,
 		maxHeaderHistory: maxHeaderHistory,
 		maxBlockHistory:  maxBlockHistory,
+		maxEmptyBlocks:   maxEmptyBlocks,
 		historyCache:     cache,
 	}
 }
@@ -167,6 +188,18 @@ func (oracle *Oracle) SuggestTipCap(ctx context.Context) (*big.Int, error) {
 	if headHash == lastHead {
 		return new(big.Int).Set(lastPrice), nil
 	}
+	if oracle.backend.ChainConfig().Clique != nil {
+		price, err := oracle.suggestCliqueTipCap(ctx, head, lastPrice)
+		if err != nil {
+			return new(big.Int).Set(lastPrice), err
+		}
+		oracle.cacheLock.Lock()
+		oracle.lastHead = headHash
+		oracle.lastPrice = price
+		oracle.cacheLock.Unlock()
+
+		return new(big.Int).Set(price), nil
+	}
 	var (
 		sent, exp int
 		number    = head.Number.Uint64()
@@ -221,6 +254,78 @@ func (oracle *Oracle) SuggestTipCap(ctx context.Context) (*big.Int, error) {
 	return new(big.Int).Set(price), nil
 }
 
+// weightedTip is a tip sampled from a block, weighted by how full the block is.
+type weightedTip struct {
+	tip    *big.Int
+	weight float64
+}
+
+// suggestCliqueTipCap suggests a tip cap on clique chains, where blocks are
+// sealed at a fixed period whether there are transactions to include or not.
+// Empty blocks are told apart by their header and skipped, looking past up to
+// maxEmptyBlocks of them for the non-empty blocks to sample. The tips sampled
+// from a block weigh in proportion to its gas used ratio, so that the busy
+// blocks drive the suggestion, which is kept between the minimum tip and the
+// maximum price.
+func (oracle *Oracle) suggestCliqueTipCap(ctx context.Context, head *types.Header, lastPrice *big.Int) (*big.Int, error) {
+	var (
+		samples          []weightedTip
+		sampled, skipped int
+		result           = make(chan results, 1)
+	)
+	for header := head; header != nil && header.Number.Sign() > 0 && sampled < oracle.checkBlocks; {
+		number := header.Number.Uint64()
+		if header.TxHash == types.EmptyRootHash {
+			if skipped++; skipped > oracle.maxEmptyBlocks {
+				break
+			}
+		} else {
+			sampled++
+			signer := types.MakeSigner(oracle.backend.ChainConfig(), header.Number)
+			oracle.getBlockValues(ctx, signer, number, sampleNumber, oracle.ignorePrice, result, nil)
+			res := <-result
+			if res.err != nil {
+				return nil, res.err
+			}
+			for _, tip := range res.values {
+				weight := float64(header.GasUsed) / float64(header.GasLimit) / float64(len(res.values))
+				samples = append(samples, weightedTip{tip, weight})
+			}
+		}
+		var err error
+		if header, err = oracle.backend.HeaderByNumber(ctx, rpc.BlockNumber(number-1)); err != nil {
+			return nil, err
+		}
+	}
+	price := lastPrice
+	if len(samples) > 0 {
+		sort.Slice(samples, func(i, j int) bool { return samples[i].tip.Cmp(samples[j].tip) < 0 })
+
+		var total float64
+		for _, sample := range samples {
+			total += sample.weight
+		}
+		var (
+			target     = total * float64(oracle.percentile) / 100
+			cumulative float64
+		)
+		price = samples[len(samples)-1].tip
+		for _, sample := range samples {
+			if cumulative += sample.weight; cumulative >= target {
+				price = sample.tip
+				break
+			}
+		}
+	}
+	if oracle.minTip != nil && price.Cmp(oracle.minTip) < 0 {
+		price = oracle.minTip
+	}
+	if price.Cmp(oracle.maxPrice) > 0 {
+		price = oracle.maxPrice
+	}
+	return new(big.Int).Set(price), nil
+}
+
 type results struct {
 	values []*big.Int
 	err    error
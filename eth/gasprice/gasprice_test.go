@@ -210,3 +210,95 @@ func TestSuggestTipCap(t *testing.T) {
 		}
 	}
 }
+
+// cliqueTestBackend is a test backend reporting a clique chain config.
+type cliqueTestBackend struct {
+	*testBackend
+	config *params.ChainConfig
+}
+
+func (b *cliqueTestBackend) ChainConfig() *params.ChainConfig {
+	return b.config
+}
+
+// newCliqueTestBackend creates a test backend with a mostly empty chain, only
+// the given blocks holding a transaction with the given tip.
+func newCliqueTestBackend(t *testing.T, tips map[int]int64) *cliqueTestBackend {
+	var (
+		key, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr   = crypto.PubkeyToAddress(key.PublicKey)
+		config = *params.TestChainConfig
+		gspec  = &core.Genesis{
+			Config: &config,
+			Alloc:  core.GenesisAlloc{addr: {Balance: big.NewInt(math.MaxInt64)}},
+		}
+		signer = types.LatestSigner(gspec.Config)
+		engine = ethash.NewFaker()
+		db     = rawdb.NewMemoryDatabase()
+	)
+	genesis := gspec.MustCommit(db)
+	blocks, _ := core.GenerateChain(gspec.Config, genesis, engine, db, testHead+1, func(i int, b *core.BlockGen) {
+		tip, ok := tips[i+1]
+		if !ok {
+			return
+		}
+		b.AddTx(types.MustSignNewTx(key, signer, &types.DynamicFeeTx{
+			ChainID:   gspec.Config.ChainID,
+			Nonce:     b.TxNonce(addr),
+			To:        &common.Address{},
+			Gas:       21000,
+			GasFeeCap: big.NewInt(100 * params.GWei),
+			GasTipCap: big.NewInt(tip * params.GWei),
+		}))
+	})
+	diskdb := rawdb.NewMemoryDatabase()
+	gspec.MustCommit(diskdb)
+	chain, err := core.NewBlockChain(diskdb, nil, gspec.Config, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("Failed to create local chain, %v", err)
+	}
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("Failed to insert chain, %v", err)
+	}
+	clique := config
+	clique.Clique = &params.CliqueConfig{Period: 1, Epoch: 30000}
+	return &cliqueTestBackend{testBackend: &testBackend{chain: chain}, config: &clique}
+}
+
+func TestSuggestCliqueTipCap(t *testing.T) {
+	backend := newCliqueTestBackend(t, map[int]int64{5: 5, 10: 10, 31: 31})
+
+	var cases = []struct {
+		maxEmpty int
+		minTip   int64
+		maxPrice int64
+		expect   int64
+	}{
+		{30, 0, 0, 10},  // Percentile of the three non-empty blocks
+		{5, 0, 0, 31},   // Only the last non-empty block within reach
+		{0, 0, 0, 1},    // Empty head, default price
+		{30, 50, 0, 50}, // Raised to the minimum tip
+		{30, 0, 8, 8},   // Capped to the maximum price
+	}
+	for i, c := range cases {
+		config := Config{
+			Blocks:         3,
+			Percentile:     60,
+			Default:        big.NewInt(params.GWei),
+			MaxEmptyBlocks: c.maxEmpty,
+		}
+		if c.minTip > 0 {
+			config.MinTip = big.NewInt(c.minTip * params.GWei)
+		}
+		if c.maxPrice > 0 {
+			config.MaxPrice = big.NewInt(c.maxPrice * params.GWei)
+		}
+		got, err := NewOracle(backend, config).SuggestTipCap(context.Background())
+		if err != nil {
+			t.Fatalf("case %d: failed to retrieve recommended tip: %v", i, err)
+		}
+		if want := big.NewInt(c.expect * params.GWei); got.Cmp(want) != 0 {
+			t.Errorf("case %d: tip mismatch, want %d, got %d", i, want, got)
+		}
+	}
+}
@@ -0,0 +1,334 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package logindex maintains an index of the blocks containing the events most
+// frequently filtered for on ct networks, used by the filter system to answer
+// log queries over wide block ranges without scanning the bloom bits.
+package logindex
+
+import (
+	"bytes"
+	"sort"
+	"sync"
+
+	"github.com/qydata/go-ctereum/accounts/abi/bind"
+	"github.com/qydata/go-ctereum/common"
+	cliquecontract "github.com/qydata/go-ctereum/consensus/clique/contract"
+	"github.com/qydata/go-ctereum/contracts/authcontroller/contract"
+	"github.com/qydata/go-ctereum/core"
+	"github.com/qydata/go-ctereum/core/rawdb"
+	"github.com/qydata/go-ctereum/core/types"
+	"github.com/qydata/go-ctereum/ethdb"
+	"github.com/qydata/go-ctereum/event"
+	"github.com/qydata/go-ctereum/log"
+)
+
+const (
+	// chainHeadChanSize is the size of channel listening to Chain2HeadEvent.
+	chainHeadChanSize = 10
+
+	// backfillLogInterval is the number of blocks between two backfill progress
+	// reports and database checkpoints.
+	backfillLogInterval = 10000
+)
+
+// Topics returns the event signatures covered by the log index: the
+// Authentication events of all auth contract revisions and the Staked event of
+// the validator contract.
+func Topics() ([]common.Hash, error) {
+	var topics []common.Hash
+	for _, meta := range []*bind.MetaData{contract.AuthControllerV1MetaData, contract.AuthControllerMetaData} {
+		parsed, err := meta.GetAbi()
+		if err != nil {
+			return nil, err
+		}
+		topics = append(topics, parsed.Events["Authentication"].ID)
+	}
+	topics = append(topics, cliquecontract.Staking().Events["Staked"].ID)
+
+	sort.Slice(topics, func(i, j int) bool { return bytes.Compare(topics[i][:], topics[j][:]) < 0 })
+	return topics, nil
+}
+
+// Indexer indexes the blocks containing logs with the covered event signatures.
+// New blocks are indexed as they are imported, while the history is indexed
+// backwards down to the genesis in the background.
+type Indexer struct {
+	chain  *core.BlockChain
+	db     ethdb.Database
+	topics []common.Hash
+
+	lock     sync.Mutex
+	progress *rawdb.LogIndexProgress // Range of blocks indexed continuously
+
+	headCh  chan core.Chain2HeadEvent
+	headSub event.Subscription
+	quit    chan struct{}
+	wg      sync.WaitGroup
+}
+
+// New creates a log indexer for the given chain.
+func New(chain *core.BlockChain, db ethdb.Database) (*Indexer, error) {
+	topics, err := Topics()
+	if err != nil {
+		return nil, err
+	}
+	return &Indexer{
+		chain:  chain,
+		db:     db,
+		topics: topics,
+		headCh: make(chan core.Chain2HeadEvent, chainHeadChanSize),
+		quit:   make(chan struct{}),
+	}, nil
+}
+
+// Start implements node.Lifecycle, starting the live indexing and the history
+// backfill. The blocks imported while the node was down are indexed in the
+// background, the head events being subscribed to first so that none of the
+// blocks imported meanwhile is missed.
+func (idx *Indexer) Start() error {
+	idx.headSub = idx.chain.SubscribeChain2HeadEvent(idx.headCh)
+	head := idx.chain.CurrentBlock().NumberU64()
+
+	progress := rawdb.ReadLogIndexProgress(idx.db)
+	if progress != nil && (!idx.covers(progress) || progress.Head > head) {
+		// The index is of a different event set or past the chain, rebuild it
+		log.Info("Dropping stale log index", "tail", progress.Tail, "head", progress.Head)
+		rawdb.DeleteLogIndex(idx.db)
+		progress = nil
+	}
+	if progress == nil {
+		progress = &rawdb.LogIndexProgress{Tail: head + 1, Head: head, Topics: idx.topics}
+	}
+	rawdb.WriteLogIndexProgress(idx.db, progress)
+	idx.progress = progress
+	tail, last := progress.Tail, progress.Head
+
+	idx.wg.Add(2)
+	go idx.loop()
+	go idx.backfill()
+
+	log.Info("Started log indexer", "tail", tail, "head", last, "catchup", head-last)
+	return nil
+}
+
+// Stop implements node.Lifecycle, terminating the live indexing and the
+// backfill.
+func (idx *Indexer) Stop() error {
+	close(idx.quit)
+	idx.wg.Wait()
+	return nil
+}
+
+// covers reports whether the stored index covers exactly the event signatures
+// of the indexer.
+func (idx *Indexer) covers(progress *rawdb.LogIndexProgress) bool {
+	if len(progress.Topics) != len(idx.topics) {
+		return false
+	}
+	for i, topic := range progress.Topics {
+		if topic != idx.topics[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Progress returns the range of blocks indexed.
+func (idx *Indexer) Progress() (uint64, uint64) {
+	idx.lock.Lock()
+	defer idx.lock.Unlock()
+
+	return idx.progress.Tail, idx.progress.Head
+}
+
+// loop indexes the chain head events until the indexer is stopped. While the
+// indexed head lags behind the chain, e.g. after a restart or a gap in the head
+// events, the missing blocks are indexed one at a time in between the events.
+func (idx *Indexer) loop() {
+	defer idx.wg.Done()
+	defer idx.headSub.Unsubscribe()
+
+	for {
+		// The indexed head is only moved by this goroutine, no need to lock
+		if next := idx.progress.Head + 1; next <= idx.chain.CurrentBlock().NumberU64() {
+			select {
+			case ev := <-idx.headCh:
+				idx.handleHead(ev)
+				continue
+			case <-idx.headSub.Err():
+				return
+			case <-idx.quit:
+				return
+			default:
+			}
+			if block := idx.chain.GetBlockByNumber(next); block != nil {
+				batch := idx.db.NewBatch()
+				idx.processBlock(batch, block)
+				idx.commitHead(batch, next)
+				continue
+			}
+			log.Warn("Missing block for log indexing", "number", next)
+		}
+		select {
+		case ev := <-idx.headCh:
+			idx.handleHead(ev)
+		case <-idx.headSub.Err():
+			return
+		case <-idx.quit:
+			return
+		}
+	}
+}
+
+// handleHead indexes the blocks of a chain head event following the indexed
+// head, after dropping the marks of the blocks it removed from the canonical
+// chain. The blocks past a gap are left to be caught up with by number.
+func (idx *Indexer) handleHead(ev core.Chain2HeadEvent) {
+	if ev.Type == core.Chain2HeadForkEvent || len(ev.NewChain) == 0 {
+		return
+	}
+	var (
+		batch = idx.db.NewBatch()
+		head  = idx.progress.Head
+	)
+	for _, block := range ev.OldChain {
+		if block.NumberU64() <= head {
+			idx.revertBlock(batch, block)
+		}
+	}
+	// Rewind to the fork point, the new chain segment is ordered tip first
+	if len(ev.OldChain) > 0 {
+		if fork := ev.NewChain[len(ev.NewChain)-1].NumberU64() - 1; fork < head {
+			head = fork
+		}
+	}
+	for i := len(ev.NewChain) - 1; i >= 0; i-- {
+		number := ev.NewChain[i].NumberU64()
+		if number <= head {
+			continue // Already caught up with
+		}
+		if number > head+1 {
+			break
+		}
+		idx.processBlock(batch, ev.NewChain[i])
+		head = number
+	}
+	idx.commitHead(batch, head)
+}
+
+// commitHead writes the batch of indexed marks along with the new indexed head.
+func (idx *Indexer) commitHead(batch ethdb.Batch, head uint64) {
+	idx.lock.Lock()
+	defer idx.lock.Unlock()
+
+	idx.progress.Head = head
+	rawdb.WriteLogIndexProgress(batch, idx.progress)
+	if err := batch.Write(); err != nil {
+		log.Crit("Failed to write log index", "err", err)
+	}
+}
+
+// backfill indexes the blocks below the indexed range down to the genesis
+// until done or interrupted, checkpointing the progress into the database.
+func (idx *Indexer) backfill() {
+	defer idx.wg.Done()
+
+	idx.lock.Lock()
+	tail := idx.progress.Tail
+	idx.lock.Unlock()
+
+	if tail == 0 {
+		return
+	}
+	batch := idx.db.NewBatch()
+	flush := func() {
+		idx.lock.Lock()
+		idx.progress.Tail = tail
+		rawdb.WriteLogIndexProgress(batch, idx.progress)
+		if err := batch.Write(); err != nil {
+			log.Crit("Failed to write log index", "err", err)
+		}
+		idx.lock.Unlock()
+		batch.Reset()
+	}
+	for tail > 0 {
+		select {
+		case <-idx.quit:
+			flush()
+			log.Info("Interrupted log index backfill", "tail", tail)
+			return
+		default:
+		}
+		block := idx.chain.GetBlockByNumber(tail - 1)
+		if block == nil {
+			flush()
+			log.Error("Missing block for log index backfill", "number", tail-1)
+			return
+		}
+		idx.processBlock(batch, block)
+		tail--
+
+		if batch.ValueSize() > ethdb.IdealBatchSize || tail%backfillLogInterval == 0 {
+			flush()
+			log.Info("Backfilling log index", "tail", tail)
+		}
+	}
+	flush()
+	log.Info("Finished log index backfill")
+}
+
+// processBlock marks the covered events contained in the given block.
+func (idx *Indexer) processBlock(db ethdb.KeyValueWriter, block *types.Block) {
+	for _, topic := range idx.scanBlock(block) {
+		rawdb.WriteLogIndexEntry(db, topic, block.NumberU64())
+	}
+}
+
+// revertBlock drops the marks of a block removed from the canonical chain.
+func (idx *Indexer) revertBlock(db ethdb.KeyValueWriter, block *types.Block) {
+	for _, topic := range idx.scanBlock(block) {
+		rawdb.DeleteLogIndexEntry(db, topic, block.NumberU64())
+	}
+}
+
+// scanBlock returns the covered event signatures contained in the given block.
+func (idx *Indexer) scanBlock(block *types.Block) []common.Hash {
+	var candidates []common.Hash
+	for _, topic := range idx.topics {
+		if types.BloomLookup(block.Bloom(), topic) {
+			candidates = append(candidates, topic)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+	found := make(map[common.Hash]struct{})
+	for _, receipt := range idx.chain.GetReceiptsByHash(block.Hash()) {
+		for _, l := range receipt.Logs {
+			if len(l.Topics) > 0 {
+				found[l.Topics[0]] = struct{}{}
+			}
+		}
+	}
+	var topics []common.Hash
+	for _, topic := range candidates {
+		if _, ok := found[topic]; ok {
+			topics = append(topics, topic)
+		}
+	}
+	return topics
+}
@@ -0,0 +1,203 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package logindex
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/qydata/go-ctereum/common"
+	cliquecontract "github.com/qydata/go-ctereum/consensus/clique/contract"
+	"github.com/qydata/go-ctereum/consensus/ethash"
+	"github.com/qydata/go-ctereum/core"
+	"github.com/qydata/go-ctereum/core/rawdb"
+	"github.com/qydata/go-ctereum/core/types"
+	"github.com/qydata/go-ctereum/core/vm"
+	"github.com/qydata/go-ctereum/crypto"
+	"github.com/qydata/go-ctereum/ethdb"
+	"github.com/qydata/go-ctereum/params"
+)
+
+var (
+	testKey, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	testAddr    = crypto.PubkeyToAddress(testKey.PublicKey)
+	emitterAddr = common.HexToAddress("0xe1")
+
+	stakedID = cliquecontract.Staking().Events["Staked"].ID
+)
+
+// emitterCode emits a log with the call data as its only topic.
+var emitterCode = common.FromHex("600035" + "6000" + "6000" + "a1" + "00")
+
+func newTestChain(t *testing.T) (*core.BlockChain, ethdb.Database) {
+	db := rawdb.NewMemoryDatabase()
+	gspec := &core.Genesis{
+		Config: params.AllEthashProtocolChanges,
+		Alloc: core.GenesisAlloc{
+			testAddr:    {Balance: big.NewInt(params.Ether)},
+			emitterAddr: {Code: emitterCode, Balance: new(big.Int)},
+		},
+		BaseFee: big.NewInt(params.InitialBaseFee),
+	}
+	gspec.MustCommit(db)
+
+	chain, err := core.NewBlockChain(db, nil, gspec.Config, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	return chain, db
+}
+
+// makeBlocks generates n blocks on top of the parent, emitting the topics
+// listed for a block.
+func makeBlocks(t *testing.T, chain *core.BlockChain, db ethdb.Database, parent *types.Block, n int, nonce uint64, topics map[int]common.Hash) []*types.Block {
+	blocks, _ := core.GenerateChain(chain.Config(), parent, ethash.NewFaker(), db, n, func(i int, b *core.BlockGen) {
+		b.SetCoinbase(common.Address{0x01})
+		topic, ok := topics[i]
+		if !ok {
+			return
+		}
+		tx, err := types.SignTx(types.NewTransaction(nonce, emitterAddr, new(big.Int), 100000, b.BaseFee(), topic.Bytes()), types.LatestSigner(chain.Config()), testKey)
+		if err != nil {
+			t.Fatalf("failed to sign tx: %v", err)
+		}
+		nonce++
+		b.AddTx(tx)
+	})
+	return blocks
+}
+
+// waitProgress waits until the indexer covers the given range.
+func waitProgress(t *testing.T, idx *Indexer, tail, head uint64) {
+	for i := 0; i < 100; i++ {
+		if first, last := idx.Progress(); first <= tail && last >= head {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("indexer did not reach range %d-%d", tail, head)
+}
+
+func checkBlocks(t *testing.T, db ethdb.Database, topic common.Hash, want []uint64) {
+	t.Helper()
+	if have := rawdb.ReadLogIndexBlocks(db, topic, 0, 100); !reflect.DeepEqual(have, want) {
+		t.Errorf("indexed blocks mismatch: have %v, want %v", have, want)
+	}
+}
+
+func TestIndexer(t *testing.T) {
+	chain, db := newTestChain(t)
+	defer chain.Stop()
+
+	other := common.HexToHash("0x01")
+	blocks := makeBlocks(t, chain, db, chain.Genesis(), 6, 0, map[int]common.Hash{
+		1: stakedID,
+		2: other,
+		4: stakedID,
+	})
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	// The history is indexed in the background
+	idx, err := New(chain, db)
+	if err != nil {
+		t.Fatalf("failed to create indexer: %v", err)
+	}
+	if err := idx.Start(); err != nil {
+		t.Fatalf("failed to start indexer: %v", err)
+	}
+	waitProgress(t, idx, 0, 6)
+	checkBlocks(t, db, stakedID, []uint64{2, 5})
+	checkBlocks(t, db, other, nil)
+
+	// New blocks are indexed as imported
+	more := makeBlocks(t, chain, db, blocks[5], 2, 3, map[int]common.Hash{1: stakedID})
+	if _, err := chain.InsertChain(more); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	waitProgress(t, idx, 0, 8)
+	checkBlocks(t, db, stakedID, []uint64{2, 5, 8})
+
+	// Replace the last event with a longer fork without events
+	fork := makeBlocks(t, chain, db, blocks[5], 4, 3, nil)
+	if _, err := chain.InsertChain(fork); err != nil {
+		t.Fatalf("failed to insert fork: %v", err)
+	}
+	waitProgress(t, idx, 0, 10)
+	checkBlocks(t, db, stakedID, []uint64{2, 5})
+
+	// A restarted indexer resumes from the stored progress
+	idx.Stop()
+	idx, err = New(chain, db)
+	if err != nil {
+		t.Fatalf("failed to create indexer: %v", err)
+	}
+	if err := idx.Start(); err != nil {
+		t.Fatalf("failed to start indexer: %v", err)
+	}
+	defer idx.Stop()
+
+	if tail, head := idx.Progress(); tail != 0 || head != 10 {
+		t.Errorf("progress mismatch: have %d-%d, want 0-10", tail, head)
+	}
+	progress := rawdb.ReadLogIndexProgress(db)
+	if progress == nil || !progress.Covers(stakedID) || progress.Covers(other) {
+		t.Errorf("indexed topics mismatch: %v", progress)
+	}
+}
+
+// Tests that the blocks imported while the indexer was down are indexed in the
+// background, keeping the indexed history.
+func TestIndexerCatchUp(t *testing.T) {
+	chain, db := newTestChain(t)
+	defer chain.Stop()
+
+	blocks := makeBlocks(t, chain, db, chain.Genesis(), 8, 0, map[int]common.Hash{
+		1: stakedID,
+		4: stakedID,
+		6: stakedID,
+	})
+	if _, err := chain.InsertChain(blocks[:6]); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	// Simulate a node stopped after indexing the first blocks
+	indexDb := rawdb.NewMemoryDatabase()
+	topics, _ := Topics()
+	rawdb.WriteLogIndexEntry(indexDb, stakedID, 2)
+	rawdb.WriteLogIndexProgress(indexDb, &rawdb.LogIndexProgress{Tail: 0, Head: 2, Topics: topics})
+
+	idx, err := New(chain, indexDb)
+	if err != nil {
+		t.Fatalf("failed to create indexer: %v", err)
+	}
+	if err := idx.Start(); err != nil {
+		t.Fatalf("failed to start indexer: %v", err)
+	}
+	defer idx.Stop()
+
+	if _, err := chain.InsertChain(blocks[6:]); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	waitProgress(t, idx, 0, 8)
+	checkBlocks(t, indexDb, stakedID, []uint64{2, 5, 7})
+
+	if progress := rawdb.ReadLogIndexProgress(indexDb); progress == nil || progress.Tail != 0 || progress.Head != 8 {
+		t.Errorf("persisted progress mismatch: have %+v, want 0-8", progress)
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"time"
+
+	"github.com/qydata/go-ctereum/common"
+	"github.com/qydata/go-ctereum/consensus"
+	"github.com/qydata/go-ctereum/consensus/beacon"
+	"github.com/qydata/go-ctereum/log"
+	"github.com/qydata/go-ctereum/metrics"
+)
+
+// sealDrainTimeout is the maximum time the shutdown waits for the in-flight seals
+// of the consensus engine to be delivered or aborted.
+const sealDrainTimeout = 10 * time.Second
+
+// shutdownPhase is a step of the shutdown of the protocol.
+type shutdownPhase struct {
+	name string
+	run  func()
+}
+
+// runShutdown runs the phases of the shutdown in order. The start of each phase
+// is logged and its duration is timed in the eth/shutdown/<phase> metric, so that
+// a shutdown killed by a supervisor can be traced to the phase it was stuck in.
+func runShutdown(phases []shutdownPhase) {
+	start := time.Now()
+	for i, phase := range phases {
+		log.Info("Shutting down", "phase", phase.name, "step", i+1, "of", len(phases))
+
+		begin := time.Now()
+		phase.run()
+		elapsed := time.Since(begin)
+
+		metrics.GetOrRegisterTimer("eth/shutdown/"+phase.name, nil).Update(elapsed)
+		log.Debug("Shutdown phase completed", "phase", phase.name, "elapsed", common.PrettyDuration(elapsed))
+	}
+	log.Info("Protocol shut down", "elapsed", common.PrettyDuration(time.Since(start)))
+}
+
+// drainSealing stops the sealing of new blocks by the consensus engine, if it
+// supports it, and waits for the blocks being sealed to be delivered or aborted.
+func drainSealing(engine consensus.Engine) {
+	if b, ok := engine.(*beacon.Beacon); ok {
+		engine = b.InnerEngine()
+	}
+	type drainer interface {
+		StopSealing(timeout time.Duration) bool
+	}
+	if d, ok := engine.(drainer); ok {
+		d.StopSealing(sealDrainTimeout)
+	}
+}
@@ -0,0 +1,117 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/qydata/go-ctereum/common"
+	"github.com/qydata/go-ctereum/consensus/ethash"
+	"github.com/qydata/go-ctereum/core"
+	"github.com/qydata/go-ctereum/core/rawdb"
+	"github.com/qydata/go-ctereum/core/vm"
+	"github.com/qydata/go-ctereum/eth/downloader"
+	"github.com/qydata/go-ctereum/eth/ethconfig"
+	"github.com/qydata/go-ctereum/node"
+	"github.com/qydata/go-ctereum/p2p"
+	"github.com/qydata/go-ctereum/params"
+)
+
+// Tests that the node can be shut down while the auth and log indexers are
+// backfilling, the backfills checkpointing their progress before the database
+// is closed.
+func TestShutdownDuringBackfill(t *testing.T) {
+	config := *params.AllEthashProtocolChanges
+	config.AuthBlock = big.NewInt(0)
+	config.AuthContract = common.HexToAddress("0xa0")
+
+	var (
+		genesis = &core.Genesis{Config: &config, BaseFee: big.NewInt(params.InitialBaseFee)}
+		nodeCfg = &node.Config{
+			DataDir: t.TempDir(),
+			P2P:     p2p.Config{ListenAddr: "127.0.0.1:0", NoDiscovery: true, MaxPeers: 25},
+		}
+		ethCfg = ethconfig.Defaults
+	)
+	ethCfg.Genesis = genesis
+	ethCfg.Ethash.PowMode = ethash.ModeFake
+	ethCfg.SyncMode = downloader.FullSync
+	ethCfg.LogIndex = true
+
+	openDatabase := func() *node.Node {
+		stack, err := node.New(nodeCfg)
+		if err != nil {
+			t.Fatalf("failed to create node: %v", err)
+		}
+		return stack
+	}
+	// Import a chain without running the indexers, so that the whole history
+	// needs to be backfilled once they start
+	stack := openDatabase()
+	db, err := stack.OpenDatabaseWithFreezer("chaindata", 0, 0, "", "", false)
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	gblock := genesis.MustCommit(db)
+	chain, err := core.NewBlockChain(db, nil, &config, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	blocks, _ := core.GenerateChain(&config, gblock, ethash.NewFaker(), db, 2048, nil)
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	chain.Stop()
+	stack.Close()
+
+	// Start the node, kick the auth event backfill off and shut down at once
+	stack = openDatabase()
+	ethservice, err := New(stack, &ethCfg)
+	if err != nil {
+		t.Fatalf("failed to create eth service: %v", err)
+	}
+	if err := stack.Start(); err != nil {
+		t.Fatalf("failed to start node: %v", err)
+	}
+	if err := ethservice.authIndexer.StartBackfill(0); err != nil {
+		t.Fatalf("failed to start auth event backfill: %v", err)
+	}
+	if err := stack.Close(); err != nil {
+		t.Fatalf("failed to close node: %v", err)
+	}
+	// The interrupted backfills are checkpointed and resumable
+	stack = openDatabase()
+	defer stack.Close()
+
+	db, err = stack.OpenDatabaseWithFreezer("chaindata", 0, 0, "", "", true)
+	if err != nil {
+		t.Fatalf("failed to reopen database: %v", err)
+	}
+	defer db.Close()
+
+	head := uint64(len(blocks))
+	if tail, last, ok := rawdb.ReadAuthIndexProgress(db); !ok || last != head || tail > head+1 {
+		t.Errorf("auth index progress mismatch: have %d-%d (%v), want ?-%d", tail, last, ok, head)
+	}
+	if tail, ok := rawdb.ReadAuthBackfill(db); ok && tail != 0 {
+		t.Errorf("auth backfill target mismatch: have %d, want 0", tail)
+	}
+	if progress := rawdb.ReadLogIndexProgress(db); progress == nil || progress.Head != head || progress.Tail > head+1 {
+		t.Errorf("log index progress mismatch: have %+v, want ?-%d", progress, head)
+	}
+}
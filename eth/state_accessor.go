@@ -175,6 +175,7 @@ func (eth *Ethereum) stateAtTransaction(block *types.Block, txIndex int, reexec
 	if err != nil {
 		return nil, vm.BlockContext{}, nil, err
 	}
+	core.ApplyStateUpgrades(eth.blockchain.Config(), block.Number(), statedb)
 	if txIndex == 0 && len(block.Transactions()) == 0 {
 		return nil, vm.BlockContext{}, statedb, nil
 	}
@@ -23,6 +23,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math/big"
 	"os"
 	"runtime"
 	"sync"
@@ -85,11 +86,17 @@ type Backend interface {
 // API is the collection of tracing APIs exposed over the private debugging endpoint.
 type API struct {
 	backend Backend
+
+	fileJobs     map[rpc.ID]*traceFileJob // Background block traces to file
+	fileJobsLock sync.Mutex
 }
 
 // NewAPI creates a new API definition for the tracing methods of the Ethereum service.
 func NewAPI(backend Backend) *API {
-	return &API{backend: backend}
+	return &API{
+		backend:  backend,
+		fileJobs: make(map[rpc.ID]*traceFileJob),
+	}
 }
 
 type chainContext struct {
@@ -116,6 +123,31 @@ func (context *chainContext) GetHeader(hash common.Hash, number uint64) *types.H
 	return header
 }
 
+func (context *chainContext) Config() *params.ChainConfig {
+	return context.api.backend.ChainConfig()
+}
+
+func (context *chainContext) CurrentHeader() *types.Header {
+	header, _ := context.api.backend.HeaderByNumber(context.ctx, rpc.LatestBlockNumber)
+	return header
+}
+
+func (context *chainContext) GetHeaderByNumber(number uint64) *types.Header {
+	header, _ := context.api.backend.HeaderByNumber(context.ctx, rpc.BlockNumber(number))
+	return header
+}
+
+func (context *chainContext) GetHeaderByHash(hash common.Hash) *types.Header {
+	header, _ := context.api.backend.HeaderByHash(context.ctx, hash)
+	return header
+}
+
+// GetTd is not available to the tracers, none of the engines needs it to
+// finalize a block.
+func (context *chainContext) GetTd(hash common.Hash, number uint64) *big.Int {
+	return nil
+}
+
 // chainContext constructs the context reader which is used by the evm for reading
 // the necessary chain context.
 func (api *API) chainContext(ctx context.Context) core.ChainContext {
@@ -173,6 +205,9 @@ type TraceConfig struct {
 	// Config specific to given tracer. Note struct logger
 	// config are historically embedded in main object.
 	TracerConfig json.RawMessage
+	// SystemCalls appends the traces of the system calls executed by the
+	// consensus engine while finalizing a block to the block traces.
+	SystemCalls bool
 }
 
 // TraceCallConfig is the config for traceCall API. It holds one more
@@ -188,6 +223,7 @@ type StdTraceConfig struct {
 	logger.Config
 	Reexec *uint64
 	TxHash common.Hash
+	Quota  *uint64 // Maximum number of bytes written to the trace files, unlimited if nil
 }
 
 // txTraceResult is the result of a single transaction trace.
@@ -272,6 +308,7 @@ func (api *API) traceChain(ctx context.Context, start, end *types.Block, config
 
 			// Fetch and execute the next block trace tasks
 			for task := range tasks {
+				core.ApplyStateUpgrades(api.backend.ChainConfig(), task.block.Number(), task.statedb)
 				signer := types.MakeSigner(api.backend.ChainConfig(), task.block.Number())
 				blockCtx := core.NewEVMBlockContext(task.block.Header(), api.chainContext(localctx), nil)
 				// Trace all the transactions contained within
@@ -490,7 +527,7 @@ func (api *API) StandardTraceBlockToFile(ctx context.Context, hash common.Hash,
 	if err != nil {
 		return nil, err
 	}
-	return api.standardTraceBlockToFile(ctx, block, config)
+	return api.standardTraceBlockToFile(ctx, block, config, nil)
 }
 
 // IntermediateRoots executes a block (bad- or canon- or side-), and returns a list
@@ -519,6 +556,7 @@ func (api *API) IntermediateRoots(ctx context.Context, hash common.Hash, config
 	if err != nil {
 		return nil, err
 	}
+	core.ApplyStateUpgrades(api.backend.ChainConfig(), block.Number(), statedb)
 	var (
 		roots              []common.Hash
 		signer             = types.MakeSigner(api.backend.ChainConfig(), block.Number())
@@ -558,7 +596,7 @@ func (api *API) StandardTraceBadBlockToFile(ctx context.Context, hash common.Has
 	if block == nil {
 		return nil, fmt.Errorf("bad block %#x not found", hash)
 	}
-	return api.standardTraceBlockToFile(ctx, block, config)
+	return api.standardTraceBlockToFile(ctx, block, config, nil)
 }
 
 // traceBlock configures a new tracer according to the provided configuration, and
@@ -580,6 +618,7 @@ func (api *API) traceBlock(ctx context.Context, block *types.Block, config *Trac
 	if err != nil {
 		return nil, err
 	}
+	core.ApplyStateUpgrades(api.backend.ChainConfig(), block.Number(), statedb)
 	// Execute all the transaction contained within the block concurrently
 	var (
 		signer  = types.MakeSigner(api.backend.ChainConfig(), block.Number())
@@ -642,13 +681,24 @@ func (api *API) traceBlock(ctx context.Context, block *types.Block, config *Trac
 	if failed != nil {
 		return nil, failed
 	}
+	if config != nil && config.SystemCalls {
+		calls, err := api.traceSystemCalls(ctx, block, statedb, config)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, calls...)
+	}
 	return results, nil
 }
 
 // standardTraceBlockToFile configures a new tracer which uses standard JSON output,
 // and traces either a full block or an individual transaction. The return value will
-// be one filename per transaction traced.
-func (api *API) standardTraceBlockToFile(ctx context.Context, block *types.Block, config *StdTraceConfig) ([]string, error) {
+// be one filename per transaction traced. The progress of the trace is reported to
+// the given job, if any.
+//
+// Tracing stops with errTraceQuotaExceeded once the files written reach the quota
+// of the config, the file of the transaction being traced being truncated.
+func (api *API) standardTraceBlockToFile(ctx context.Context, block *types.Block, config *StdTraceConfig, job *traceFileJob) ([]string, error) {
 	// If we're tracing a single transaction, make sure it's present
 	if config != nil && config.TxHash != (common.Hash{}) {
 		if !containsTx(block, config.TxHash) {
@@ -670,14 +720,20 @@ func (api *API) standardTraceBlockToFile(ctx context.Context, block *types.Block
 	if err != nil {
 		return nil, err
 	}
+	core.ApplyStateUpgrades(api.backend.ChainConfig(), block.Number(), statedb)
 	// Retrieve the tracing configurations, or use default values
 	var (
 		logConfig logger.Config
 		txHash    common.Hash
+		quota     uint64
+		written   uint64
 	)
 	if config != nil {
 		logConfig = config.Config
 		txHash = config.TxHash
+		if config.Quota != nil {
+			quota = *config.Quota
+		}
 	}
 	logConfig.Debug = true
 
@@ -707,6 +763,9 @@ func (api *API) standardTraceBlockToFile(ctx context.Context, block *types.Block
 		}
 	}
 	for i, tx := range block.Transactions() {
+		if err := ctx.Err(); err != nil {
+			return dumps, err
+		}
 		// Prepare the transaction for un-traced execution
 		var (
 			msg, _    = tx.AsMessage(signer, block.BaseFee())
@@ -714,6 +773,7 @@ func (api *API) standardTraceBlockToFile(ctx context.Context, block *types.Block
 			vmConf    vm.Config
 			dump      *os.File
 			writer    *bufio.Writer
+			limiter   *quotaWriter
 			err       error
 		)
 		// If the transaction needs tracing, swap out the configs
@@ -731,16 +791,30 @@ func (api *API) standardTraceBlockToFile(ctx context.Context, block *types.Block
 
 			// Swap out the noop logger to the standard tracer
 			writer = bufio.NewWriter(dump)
+			limiter = &quotaWriter{w: writer, written: &written, quota: quota}
 			vmConf = vm.Config{
 				Debug:                   true,
-				Tracer:                  logger.NewJSONLogger(&logConfig, writer),
+				Tracer:                  logger.NewJSONLogger(&logConfig, limiter),
 				EnablePreimageRecording: true,
 			}
 		}
-		// Execute the transaction and flush any traces to disk
+		// Execute the transaction and flush any traces to disk, aborting the
+		// execution if the trace is cancelled or runs out of quota
 		vmenv := vm.NewEVM(vmctx, txContext, statedb, chainConfig, vmConf)
+		if limiter != nil {
+			limiter.onExceed = vmenv.Cancel
+		}
+		done := make(chan struct{})
+		go func() {
+			select {
+			case <-ctx.Done():
+				vmenv.Cancel()
+			case <-done:
+			}
+		}()
 		statedb.Prepare(tx.Hash(), i)
 		_, err = core.ApplyMessage(vmenv, msg, new(core.GasPool).AddGas(msg.Gas()))
+		close(done)
 		if writer != nil {
 			writer.Flush()
 		}
@@ -748,7 +822,15 @@ func (api *API) standardTraceBlockToFile(ctx context.Context, block *types.Block
 			dump.Close()
 			log.Info("Wrote standard trace", "file", dump.Name())
 		}
-		if err != nil {
+		if job != nil {
+			job.update(i+1, dumps, written)
+		}
+		switch {
+		case limiter != nil && limiter.exceeded:
+			return dumps, errTraceQuotaExceeded
+		case ctx.Err() != nil:
+			return dumps, ctx.Err()
+		case err != nil:
 			return dumps, err
 		}
 		// Finalize the state so any modifications are written to the trie
@@ -810,25 +892,7 @@ func (api *API) TraceTransaction(ctx context.Context, hash common.Hash, config *
 // top of the provided block and returns them as a JSON object.
 func (api *API) TraceCall(ctx context.Context, args ethapi.TransactionArgs, blockNrOrHash rpc.BlockNumberOrHash, config *TraceCallConfig) (interface{}, error) {
 	// Try to retrieve the specified block
-	var (
-		err   error
-		block *types.Block
-	)
-	if hash, ok := blockNrOrHash.Hash(); ok {
-		block, err = api.blockByHash(ctx, hash)
-	} else if number, ok := blockNrOrHash.Number(); ok {
-		if number == rpc.PendingBlockNumber {
-			// We don't have access to the miner here. For tracing 'future' transactions,
-			// it can be done with block- and state-overrides instead, which offers
-			// more flexibility and stability than trying to trace on 'pending', since
-			// the contents of 'pending' is unstable and probably not a true representation
-			// of what the next actual block is likely to contain.
-			return nil, errors.New("tracing on top of pending is not supported")
-		}
-		block, err = api.blockByNumber(ctx, number)
-	} else {
-		return nil, errors.New("invalid arguments; neither block nor hash specified")
-	}
+	block, err := api.callBlock(ctx, blockNrOrHash)
 	if err != nil {
 		return nil, err
 	}
@@ -867,31 +931,127 @@ func (api *API) TraceCall(ctx context.Context, args ethapi.TransactionArgs, bloc
 	return api.traceTx(ctx, msg, new(Context), vmctx, statedb, traceConfig)
 }
 
+// callBundleResult is the result of a single call of a traced bundle.
+type callBundleResult struct {
+	Result    interface{}                     `json:"result,omitempty"`    // Trace results produced by the tracer
+	Error     string                          `json:"error,omitempty"`     // Trace failure produced by the tracer
+	StateDiff map[common.Address]*accountDiff `json:"stateDiff,omitempty"` // State modified by the call
+}
+
+// TraceCallMany lets you trace an ordered bundle of eth_calls on top of a given
+// block. The calls are executed one after the other, each one seeing the state
+// modified by the previous ones, as if they were included in a single block.
+// The trace and the state modified by every call are returned. A failing call
+// leaves the state untouched and the following calls are executed anyway.
+func (api *API) TraceCallMany(ctx context.Context, bundle []ethapi.TransactionArgs, blockNrOrHash rpc.BlockNumberOrHash, config *TraceCallConfig) ([]*callBundleResult, error) {
+	block, err := api.callBlock(ctx, blockNrOrHash)
+	if err != nil {
+		return nil, err
+	}
+	reexec := defaultTraceReexec
+	if config != nil && config.Reexec != nil {
+		reexec = *config.Reexec
+	}
+	statedb, err := api.backend.StateAtBlock(ctx, block, reexec, nil, true, false)
+	if err != nil {
+		return nil, err
+	}
+	vmctx := core.NewEVMBlockContext(block.Header(), api.chainContext(ctx), nil)
+	traceConfig := new(TraceConfig)
+	if config != nil {
+		if err := config.StateOverrides.Apply(statedb); err != nil {
+			return nil, err
+		}
+		config.BlockOverrides.Apply(&vmctx)
+		traceConfig = &config.TraceConfig
+	}
+	var (
+		deleteEmpty = api.backend.ChainConfig().IsEIP158(vmctx.BlockNumber)
+		results     = make([]*callBundleResult, len(bundle))
+	)
+	for i, args := range bundle {
+		msg, err := args.ToMessage(api.backend.RPCGasCap(), block.BaseFee())
+		if err != nil {
+			return nil, fmt.Errorf("call %d: %w", i, err)
+		}
+		txctx := &Context{BlockHash: block.Hash(), TxIndex: i}
+		tracer, err := newTracer(txctx, traceConfig)
+		if err != nil {
+			return nil, err
+		}
+		var (
+			pre  = statedb.Copy()
+			diff = newStateDiffTracer(tracer)
+		)
+		if err := api.applyTraced(ctx, msg, txctx, vmctx, statedb, diff, traceConfig.Timeout); err != nil {
+			results[i] = &callBundleResult{Error: err.Error()}
+			continue
+		}
+		statedb.Finalise(deleteEmpty)
+
+		results[i] = &callBundleResult{StateDiff: diff.diff(pre, statedb)}
+		if res, err := tracer.GetResult(); err != nil {
+			results[i].Error = err.Error()
+		} else {
+			results[i].Result = res
+		}
+	}
+	return results, nil
+}
+
+// callBlock retrieves the block a call is to be traced on top of.
+func (api *API) callBlock(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (*types.Block, error) {
+	var (
+		err   error
+		block *types.Block
+	)
+	if hash, ok := blockNrOrHash.Hash(); ok {
+		block, err = api.blockByHash(ctx, hash)
+	} else if number, ok := blockNrOrHash.Number(); ok {
+		if number == rpc.PendingBlockNumber {
+			// We don't have access to the miner here. For tracing 'future' transactions,
+			// it can be done with block- and state-overrides instead, which offers
+			// more flexibility and stability than trying to trace on 'pending', since
+			// the contents of 'pending' is unstable and probably not a true representation
+			// of what the next actual block is likely to contain.
+			return nil, errors.New("tracing on top of pending is not supported")
+		}
+		block, err = api.blockByNumber(ctx, number)
+	} else {
+		return nil, errors.New("invalid arguments; neither block nor hash specified")
+	}
+	return block, err
+}
+
 // traceTx configures a new tracer according to the provided configuration, and
 // executes the given message in the provided environment. The return value will
 // be tracer dependent.
 func (api *API) traceTx(ctx context.Context, message core.Message, txctx *Context, vmctx vm.BlockContext, statedb *state.StateDB, config *TraceConfig) (interface{}, error) {
+	if config == nil {
+		config = &TraceConfig{}
+	}
+	tracer, err := newTracer(txctx, config)
+	if err != nil {
+		return nil, err
+	}
+	if err := api.applyTraced(ctx, message, txctx, vmctx, statedb, tracer, config.Timeout); err != nil {
+		return nil, err
+	}
+	return tracer.GetResult()
+}
+
+// applyTraced executes the given message in the provided environment through
+// the given tracer, aborting it once the timeout elapses.
+func (api *API) applyTraced(ctx context.Context, message core.Message, txctx *Context, vmctx vm.BlockContext, statedb *state.StateDB, tracer Tracer, timeoutStr *string) error {
 	var (
-		tracer    Tracer
 		err       error
 		timeout   = defaultTraceTimeout
 		txContext = core.NewEVMTxContext(message)
 	)
-	if config == nil {
-		config = &TraceConfig{}
-	}
-	// Default tracer is the struct logger
-	tracer = logger.NewStructLogger(config.Config)
-	if config.Tracer != nil {
-		tracer, err = New(*config.Tracer, txctx, config.TracerConfig)
-		if err != nil {
-			return nil, err
-		}
-	}
 	// Define a meaningful timeout of a single transaction trace
-	if config.Timeout != nil {
-		if timeout, err = time.ParseDuration(*config.Timeout); err != nil {
-			return nil, err
+	if timeoutStr != nil {
+		if timeout, err = time.ParseDuration(*timeoutStr); err != nil {
+			return err
 		}
 	}
 	deadlineCtx, cancel := context.WithTimeout(ctx, timeout)
@@ -908,9 +1068,18 @@ func (api *API) traceTx(ctx context.Context, message core.Message, txctx *Contex
 	// Call Prepare to clear out the statedb access list
 	statedb.Prepare(txctx.TxHash, txctx.TxIndex)
 	if _, err = core.ApplyMessage(vmenv, message, new(core.GasPool).AddGas(message.Gas())); err != nil {
-		return nil, fmt.Errorf("tracing failed: %w", err)
+		return fmt.Errorf("tracing failed: %w", err)
 	}
-	return tracer.GetResult()
+	return nil
+}
+
+// newTracer creates the tracer requested by the given configuration, defaulting
+// to the struct logger.
+func newTracer(txctx *Context, config *TraceConfig) (Tracer, error) {
+	if config.Tracer != nil {
+		return New(*config.Tracer, txctx, config.TracerConfig)
+	}
+	return logger.NewStructLogger(config.Config), nil
 }
 
 // APIs return the collection of RPC services the tracer package offers.
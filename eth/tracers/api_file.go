@@ -0,0 +1,204 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/qydata/go-ctereum/common"
+	"github.com/qydata/go-ctereum/common/hexutil"
+	"github.com/qydata/go-ctereum/log"
+	"github.com/qydata/go-ctereum/rpc"
+)
+
+// maxTraceFileJobs is the maximum number of background file traces tracked,
+// finished ones being dropped oldest first to make room for new ones.
+const maxTraceFileJobs = 16
+
+var (
+	errTraceQuotaExceeded = errors.New("trace disk quota exceeded")
+	errTraceJobNotFound   = errors.New("trace job not found")
+	errTooManyTraceJobs   = errors.New("too many running trace jobs")
+)
+
+// quotaWriter forwards writes to a trace file until the bytes written over all
+// the files of a block trace reach the quota, failing all further writes.
+type quotaWriter struct {
+	w        io.Writer
+	written  *uint64 // Bytes written over all the files of the trace
+	quota    uint64  // Maximum number of bytes to write, unlimited if zero
+	exceeded bool
+	onExceed func() // Invoked once when the quota is exceeded
+}
+
+func (q *quotaWriter) Write(p []byte) (int, error) {
+	if q.quota > 0 && *q.written+uint64(len(p)) > q.quota {
+		if !q.exceeded && q.onExceed != nil {
+			q.onExceed()
+		}
+		q.exceeded = true
+		return 0, errTraceQuotaExceeded
+	}
+	n, err := q.w.Write(p)
+	*q.written += uint64(n)
+	return n, err
+}
+
+// StdTraceProgress is the progress of a background block trace to file.
+type StdTraceProgress struct {
+	Block   common.Hash    `json:"block"`
+	Number  hexutil.Uint64 `json:"number"`
+	Txs     int            `json:"txs"`     // Number of transactions in the block
+	Traced  int            `json:"traced"`  // Number of transactions executed so far
+	Files   []string       `json:"files"`   // Trace files written so far
+	Written hexutil.Uint64 `json:"written"` // Bytes written to the trace files
+	Done    bool           `json:"done"`
+	Error   string         `json:"error,omitempty"`
+}
+
+// traceFileJob is a block trace to file running in the background.
+type traceFileJob struct {
+	cancel  context.CancelFunc
+	started time.Time
+
+	lock     sync.Mutex
+	progress StdTraceProgress
+}
+
+// update records the progress of the trace after executing a transaction.
+func (j *traceFileJob) update(traced int, files []string, written uint64) {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	j.progress.Traced = traced
+	j.progress.Files = append([]string{}, files...)
+	j.progress.Written = hexutil.Uint64(written)
+}
+
+// finish marks the trace as done, successfully or not.
+func (j *traceFileJob) finish(files []string, err error) {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	j.progress.Done = true
+	j.progress.Files = append([]string{}, files...)
+	if err != nil {
+		j.progress.Error = err.Error()
+	}
+}
+
+// snapshot returns a copy of the progress of the trace.
+func (j *traceFileJob) snapshot() *StdTraceProgress {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	progress := j.progress
+	progress.Files = append([]string{}, j.progress.Files...)
+	return &progress
+}
+
+// StartStandardTraceBlockToFile starts dumping the structured logs created
+// during the execution of a block to the local file system in the background,
+// one file per transaction, returning the id of the trace. The files written
+// are bounded by the quota of the config, the progress of the trace is reported
+// by StandardTraceProgress and it is aborted by CancelStandardTrace.
+func (api *API) StartStandardTraceBlockToFile(ctx context.Context, hash common.Hash, config *StdTraceConfig) (rpc.ID, error) {
+	block, err := api.blockByHash(ctx, hash)
+	if err != nil {
+		return "", err
+	}
+	api.fileJobsLock.Lock()
+	defer api.fileJobsLock.Unlock()
+
+	if len(api.fileJobs) >= maxTraceFileJobs && !api.dropFinishedJob() {
+		return "", errTooManyTraceJobs
+	}
+	jobCtx, cancel := context.WithCancel(context.Background())
+	job := &traceFileJob{
+		cancel:  cancel,
+		started: time.Now(),
+		progress: StdTraceProgress{
+			Block:  block.Hash(),
+			Number: hexutil.Uint64(block.NumberU64()),
+			Txs:    len(block.Transactions()),
+			Files:  []string{},
+		},
+	}
+	id := rpc.NewID()
+	api.fileJobs[id] = job
+
+	go func() {
+		defer cancel()
+
+		files, err := api.standardTraceBlockToFile(jobCtx, block, config, job)
+		if err != nil {
+			log.Warn("Standard trace to file failed", "id", id, "block", block.Number(), "err", err)
+		}
+		job.finish(files, err)
+	}()
+	return id, nil
+}
+
+// StandardTraceProgress returns the progress of a background block trace.
+func (api *API) StandardTraceProgress(id rpc.ID) (*StdTraceProgress, error) {
+	api.fileJobsLock.Lock()
+	job, ok := api.fileJobs[id]
+	api.fileJobsLock.Unlock()
+
+	if !ok {
+		return nil, errTraceJobNotFound
+	}
+	return job.snapshot(), nil
+}
+
+// CancelStandardTrace aborts a background block trace, keeping the files it
+// has written so far. The trace is forgotten once cancelled.
+func (api *API) CancelStandardTrace(id rpc.ID) (bool, error) {
+	api.fileJobsLock.Lock()
+	job, ok := api.fileJobs[id]
+	delete(api.fileJobs, id)
+	api.fileJobsLock.Unlock()
+
+	if !ok {
+		return false, errTraceJobNotFound
+	}
+	job.cancel()
+	return true, nil
+}
+
+// dropFinishedJob forgets the oldest finished trace, reporting whether there was
+// any. The caller must hold the job lock.
+func (api *API) dropFinishedJob() bool {
+	var (
+		oldest rpc.ID
+		since  time.Time
+	)
+	for id, job := range api.fileJobs {
+		if job.snapshot().Done && (oldest == "" || job.started.Before(since)) {
+			oldest, since = id, job.started
+		}
+	}
+	if oldest == "" {
+		return false
+	}
+	delete(api.fileJobs, oldest)
+	return true
+}
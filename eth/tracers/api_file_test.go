@@ -0,0 +1,122 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"context"
+	"math/big"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/qydata/go-ctereum/common"
+	"github.com/qydata/go-ctereum/core"
+	"github.com/qydata/go-ctereum/core/types"
+	"github.com/qydata/go-ctereum/params"
+)
+
+// filesSize returns the total size of the given files, removing them.
+func filesSize(t *testing.T, files []string) uint64 {
+	var size uint64
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			t.Fatalf("failed to stat trace file: %v", err)
+		}
+		size += uint64(info.Size())
+		os.Remove(file)
+	}
+	return size
+}
+
+// Tests that block traces to file are bounded by their disk quota and that they
+// can run in the background while reporting their progress.
+func TestStandardTraceBlockToFile(t *testing.T) {
+	t.Parallel()
+
+	// A contract counting down from 32 in a loop, to produce lengthy traces
+	var (
+		accounts = newAccounts(1)
+		loop     = common.HexToAddress("0x1000")
+		genesis  = &core.Genesis{Alloc: core.GenesisAlloc{
+			accounts[0].addr: {Balance: big.NewInt(params.Ether)},
+			loop:             {Code: common.FromHex("0x60205b6001900380600257"), Balance: new(big.Int)},
+		}}
+		signer = types.HomesteadSigner{}
+	)
+	backend := newTestBackend(t, 1, genesis, func(i int, b *core.BlockGen) {
+		for nonce := uint64(0); nonce < 3; nonce++ {
+			tx, _ := types.SignTx(types.NewTransaction(nonce, loop, new(big.Int), 100000, b.BaseFee(), nil), signer, accounts[0].key)
+			b.AddTx(tx)
+		}
+	})
+	api := NewAPI(backend)
+	hash := backend.chain.GetBlockByNumber(1).Hash()
+
+	files, err := api.StandardTraceBlockToFile(context.Background(), hash, nil)
+	if err != nil {
+		t.Fatalf("failed to trace block: %v", err)
+	}
+	if len(files) != 3 {
+		t.Fatalf("trace file count mismatch: have %d, want 3", len(files))
+	}
+	total := filesSize(t, files)
+
+	// Tracing with half the space needed must stop early, within the quota
+	quota := total / 2
+	files, err = api.StandardTraceBlockToFile(context.Background(), hash, &StdTraceConfig{Quota: &quota})
+	if err != errTraceQuotaExceeded {
+		t.Fatalf("quota not enforced: %v", err)
+	}
+	if len(files) == 0 || len(files) >= 3 {
+		t.Fatalf("quota trace file count mismatch: have %d, want 1 or 2", len(files))
+	}
+	if size := filesSize(t, files); size > quota {
+		t.Fatalf("quota exceeded: %d > %d", size, quota)
+	}
+	// Trace in the background and wait for completion
+	id, err := api.StartStandardTraceBlockToFile(context.Background(), hash, nil)
+	if err != nil {
+		t.Fatalf("failed to start trace: %v", err)
+	}
+	var progress *StdTraceProgress
+	for start := time.Now(); time.Since(start) < 10*time.Second; time.Sleep(10 * time.Millisecond) {
+		if progress, err = api.StandardTraceProgress(id); err != nil {
+			t.Fatalf("failed to retrieve progress: %v", err)
+		}
+		if progress.Done {
+			break
+		}
+	}
+	if !progress.Done || progress.Error != "" {
+		t.Fatalf("trace did not complete: %+v", progress)
+	}
+	if progress.Block != hash || progress.Txs != 3 || progress.Traced != 3 || len(progress.Files) != 3 {
+		t.Fatalf("progress mismatch: %+v", progress)
+	}
+	// The logs carry a wall clock timestamp, so only compare the bytes written
+	// with the files of the same run
+	if size := filesSize(t, progress.Files); uint64(progress.Written) != size {
+		t.Fatalf("written size mismatch: have %d, files %d", progress.Written, size)
+	}
+	if ok, err := api.CancelStandardTrace(id); !ok || err != nil {
+		t.Fatalf("failed to drop finished trace: %v", err)
+	}
+	if _, err := api.StandardTraceProgress(id); err != errTraceJobNotFound {
+		t.Fatalf("dropped trace still tracked: %v", err)
+	}
+}
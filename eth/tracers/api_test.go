@@ -420,6 +420,63 @@ func TestTraceBlock(t *testing.T) {
 	}
 }
 
+// systemCallEngine is a consensus engine executing a system call to a contract
+// while finalizing the blocks.
+type systemCallEngine struct {
+	consensus.Engine
+	contract common.Address
+}
+
+func (e *systemCallEngine) FinalizeWithTracer(chain consensus.ChainHeaderReader, header *types.Header, state *state.StateDB, txs []*types.Transaction, uncles []*types.Header, tracer vm.EVMLogger) {
+	e.Engine.Finalize(chain, header, state, txs, uncles)
+
+	blockCtx := core.NewEVMBlockContext(header, nil, &header.Coinbase)
+	evm := vm.NewEVM(blockCtx, vm.TxContext{}, state, chain.Config(), vm.Config{Debug: true, Tracer: tracer})
+	state.PrepareAccessList(params.SystemAddress, &e.contract, nil, nil)
+
+	tracer.CaptureTxStart(100000)
+	_, left, _ := evm.Call(vm.AccountRef(params.SystemAddress), e.contract, nil, 100000, new(big.Int))
+	tracer.CaptureTxEnd(left)
+}
+
+// Tests that the system calls executed by the consensus engine are traced on
+// request along with the transactions of the block.
+func TestTraceBlockSystemCalls(t *testing.T) {
+	t.Parallel()
+
+	accounts := newAccounts(2)
+	contract := common.HexToAddress("0xaaaa")
+	genesis := &core.Genesis{Alloc: core.GenesisAlloc{
+		accounts[0].addr: {Balance: big.NewInt(params.Ether)},
+		contract:         {Code: common.FromHex("6001600155"), Balance: common.Big0}, // sstore(1, 1)
+	}}
+	backend := newTestBackend(t, 1, genesis, func(i int, b *core.BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(uint64(i), accounts[1].addr, big.NewInt(1000), params.TxGas, b.BaseFee(), nil), types.HomesteadSigner{}, accounts[0].key)
+		b.AddTx(tx)
+	})
+	backend.engine = &systemCallEngine{Engine: backend.engine, contract: contract}
+	api := NewAPI(backend)
+
+	result, err := api.TraceBlockByNumber(context.Background(), 1, &TraceConfig{})
+	if err != nil {
+		t.Fatalf("failed to trace block: %v", err)
+	}
+	if len(result) != 1 {
+		t.Fatalf("trace count mismatch without system calls: have %d, want 1", len(result))
+	}
+	result, err = api.TraceBlockByNumber(context.Background(), 1, &TraceConfig{SystemCalls: true})
+	if err != nil {
+		t.Fatalf("failed to trace block: %v", err)
+	}
+	if len(result) != 2 {
+		t.Fatalf("trace count mismatch with system calls: have %d, want 2", len(result))
+	}
+	have, _ := json.Marshal(result[1])
+	if want := `{"result":{"gas":22106,"failed":false,"returnValue":"","structLogs":[`; !bytes.HasPrefix(have, []byte(want)) {
+		t.Errorf("system call trace mismatch: have %s, want prefix %s", have, want)
+	}
+}
+
 func TestTracingWithOverrides(t *testing.T) {
 	t.Parallel()
 	// Initialize test accounts
@@ -575,6 +632,73 @@ func TestTracingWithOverrides(t *testing.T) {
 	}
 }
 
+// Tests that the calls of a bundle are traced on top of each other, reporting
+// the state modified by every one of them.
+func TestTraceCallMany(t *testing.T) {
+	t.Parallel()
+
+	accounts := newAccounts(3)
+	genesis := &core.Genesis{Alloc: core.GenesisAlloc{
+		accounts[0].addr: {Balance: big.NewInt(params.Ether)},
+	}}
+	api := NewAPI(newTestBackend(t, 1, genesis, func(i int, b *core.BlockGen) {}))
+
+	var (
+		sender   = accounts[1].addr
+		contract = accounts[2].addr
+		latest   = rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+		config   = &TraceCallConfig{
+			StateOverrides: &ethapi.StateOverride{
+				sender: ethapi.OverrideAccount{Balance: newRPCBalance(big.NewInt(params.Ether))},
+				// PUSH1 0 SLOAD PUSH1 1 ADD PUSH1 0 SSTORE STOP
+				contract: ethapi.OverrideAccount{Code: newRPCBytes(common.Hex2Bytes("60005460010160005500"))},
+			},
+		}
+		recipient = common.Address{0x01}
+	)
+	bundle := []ethapi.TransactionArgs{
+		{From: &sender, To: &contract},
+		{From: &sender, To: &contract},
+		{From: &sender, To: &recipient, Value: (*hexutil.Big)(big.NewInt(2 * params.Ether))},
+		{From: &sender, To: &recipient, Value: (*hexutil.Big)(big.NewInt(1000))},
+	}
+	results, err := api.TraceCallMany(context.Background(), bundle, latest, config)
+	if err != nil {
+		t.Fatalf("failed to trace bundle: %v", err)
+	}
+	if len(results) != len(bundle) {
+		t.Fatalf("result count mismatch: have %d, want %d", len(results), len(bundle))
+	}
+	// Every increment sees the state left by the previous one
+	for i := 0; i < 2; i++ {
+		if results[i].Error != "" {
+			t.Fatalf("call %d failed: %v", i, results[i].Error)
+		}
+		diff := results[i].StateDiff[contract]
+		if diff == nil {
+			t.Fatalf("call %d: contract state not modified", i)
+		}
+		pre, post := diff.Pre.Storage[common.Hash{}], diff.Post.Storage[common.Hash{}]
+		if pre != common.BigToHash(big.NewInt(int64(i))) || post != common.BigToHash(big.NewInt(int64(i+1))) {
+			t.Errorf("call %d: slot mismatch: have %x -> %x", i, pre, post)
+		}
+		if nonce := results[i].StateDiff[sender].Post.Nonce; nonce == nil || uint64(*nonce) != uint64(i+1) {
+			t.Errorf("call %d: sender nonce mismatch: have %v, want %d", i, nonce, i+1)
+		}
+	}
+	// A failing call is reported without modifying the state
+	if results[2].Error == "" || len(results[2].StateDiff) != 0 {
+		t.Errorf("overspending call not rejected: %+v", results[2])
+	}
+	diff := results[3].StateDiff[recipient]
+	if diff == nil || diff.Pre.Balance.ToInt().Sign() != 0 || diff.Post.Balance.ToInt().Cmp(big.NewInt(1000)) != 0 {
+		t.Errorf("transfer not reported: %+v", diff)
+	}
+	if nonce := results[3].StateDiff[sender].Post.Nonce; nonce == nil || uint64(*nonce) != 3 {
+		t.Errorf("sender nonce mismatch after transfer: have %v, want 3", nonce)
+	}
+}
+
 type Account struct {
 	key  *ecdsa.PrivateKey
 	addr common.Address
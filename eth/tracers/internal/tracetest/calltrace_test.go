@@ -389,3 +389,110 @@ func TestZeroValueToNotExitCall(t *testing.T) {
 		t.Error("have != want")
 	}
 }
+
+// TestCallTracerWithAuth tests that the call frames are annotated with the auth
+// status of their callers if requested.
+func TestCallTracerWithAuth(t *testing.T) {
+	var (
+		authAddr = common.HexToAddress("0xa0")
+		to       = common.HexToAddress("0x00000000000000000000000000000000deadbeef")
+	)
+	privkey, err := crypto.HexToECDSA("0000000000000000deadbeef00000000000000000000000000000000deadbeef")
+	if err != nil {
+		t.Fatalf("err %v", err)
+	}
+	signer := types.NewEIP155Signer(big.NewInt(1))
+	tx, err := types.SignNewTx(privkey, signer, &types.LegacyTx{
+		GasPrice: big.NewInt(0),
+		Gas:      50000,
+		To:       &to,
+	})
+	if err != nil {
+		t.Fatalf("err %v", err)
+	}
+	origin, _ := signer.Sender(tx)
+	config := *params.MainnetChainConfig
+	config.AuthBlock = big.NewInt(0)
+	config.AuthContract = authAddr
+	config.FixAuthContract = authAddr
+
+	txContext := vm.TxContext{
+		Origin:   origin,
+		GasPrice: big.NewInt(1),
+	}
+	context := vm.BlockContext{
+		CanTransfer: core.CanTransfer,
+		Transfer:    core.Transfer,
+		Coinbase:    common.Address{},
+		BlockNumber: new(big.Int).SetUint64(8000000),
+		Time:        new(big.Int).SetUint64(5),
+		Difficulty:  big.NewInt(0x30000),
+		GasLimit:    uint64(6000000),
+	}
+	var code = []byte{
+		byte(vm.PUSH1), 0x0, byte(vm.DUP1), byte(vm.DUP1), byte(vm.DUP1), // in and outs zero
+		byte(vm.DUP1), byte(vm.PUSH1), 0xff, byte(vm.GAS), // value=0,address=0xff, gas=GAS
+		byte(vm.CALL),
+	}
+	var alloc = core.GenesisAlloc{
+		to: core.GenesisAccount{
+			Nonce: 1,
+			Code:  code,
+		},
+		origin: core.GenesisAccount{
+			Nonce:   0,
+			Balance: big.NewInt(500000000000000),
+		},
+		// Stand-in for the auth contract, answering any call with the
+		// storage slot of the address in the call data.
+		authAddr: core.GenesisAccount{
+			Code:    common.FromHex("600435" + "54" + "600052" + "60206000f3"),
+			Storage: map[common.Hash]common.Hash{common.BytesToHash(origin.Bytes()): common.BigToHash(common.Big1)},
+		},
+	}
+	for _, withAuth := range []bool{false, true} {
+		_, statedb := tests.MakePreState(rawdb.NewMemoryDatabase(), alloc, false)
+		cfg, _ := json.Marshal(map[string]bool{"withAuth": withAuth})
+		tracer, err := tracers.New("callTracer", nil, cfg)
+		if err != nil {
+			t.Fatalf("failed to create call tracer: %v", err)
+		}
+		evm := vm.NewEVM(context, txContext, statedb, &config, vm.Config{Debug: true, Tracer: tracer})
+		msg, err := tx.AsMessage(signer, nil)
+		if err != nil {
+			t.Fatalf("failed to prepare transaction for tracing: %v", err)
+		}
+		st := core.NewStateTransition(evm, msg, new(core.GasPool).AddGas(tx.Gas()))
+		if _, err = st.TransitionDb(); err != nil {
+			t.Fatalf("failed to execute transaction: %v", err)
+		}
+		res, err := tracer.GetResult()
+		if err != nil {
+			t.Fatalf("failed to retrieve trace result: %v", err)
+		}
+		var have struct {
+			FromAuth *bool `json:"fromAuth"`
+			Calls    []struct {
+				FromAuth *bool `json:"fromAuth"`
+			} `json:"calls"`
+		}
+		if err := json.Unmarshal(res, &have); err != nil {
+			t.Fatalf("failed to unmarshal trace result: %v", err)
+		}
+		if len(have.Calls) != 1 {
+			t.Fatalf("call count mismatch: have %d, want 1", len(have.Calls))
+		}
+		if !withAuth {
+			if have.FromAuth != nil || have.Calls[0].FromAuth != nil {
+				t.Errorf("unrequested auth annotation: %s", res)
+			}
+			continue
+		}
+		if have.FromAuth == nil || !*have.FromAuth {
+			t.Errorf("origin not annotated as authenticated: %s", res)
+		}
+		if have.Calls[0].FromAuth == nil || *have.Calls[0].FromAuth {
+			t.Errorf("contract not annotated as unauthenticated: %s", res)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package native
+
+import (
+	"github.com/qydata/go-ctereum/common"
+	"github.com/qydata/go-ctereum/core/state"
+	"github.com/qydata/go-ctereum/core/vm"
+)
+
+// authLookup resolves the AuthController authentication of addresses against
+// the state the traced transaction started from. The lookups run on a copy of
+// the state in a separate EVM, so they neither observe nor disturb the traced
+// execution (e.g. by warming up storage slots).
+type authLookup struct {
+	evm   *vm.EVM
+	cache map[common.Address]bool
+}
+
+// newAuthLookup creates an auth lookup for the transaction about to run in the
+// given EVM. Nil is returned if auth is not active at the traced block.
+func newAuthLookup(env *vm.EVM) *authLookup {
+	config := env.ChainConfig()
+	if !config.IsImplAuth(env.Context.BlockNumber) {
+		return nil
+	}
+	statedb, ok := env.StateDB.(*state.StateDB)
+	if !ok {
+		return nil
+	}
+	return &authLookup{
+		evm:   vm.NewEVM(env.Context, vm.TxContext{}, statedb.Copy(), config, vm.Config{}),
+		cache: make(map[common.Address]bool),
+	}
+}
+
+// isAuth reports whether the address is authenticated, nil if unknown.
+func (l *authLookup) isAuth(addr common.Address) *bool {
+	if l == nil {
+		return nil
+	}
+	authenticated, ok := l.cache[addr]
+	if !ok {
+		_, authenticated = l.evm.IsAuth(addr)
+		l.cache[addr] = authenticated
+	}
+	return &authenticated
+}
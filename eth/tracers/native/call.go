@@ -35,28 +35,31 @@ func init() {
 }
 
 type callFrame struct {
-	Type    string      `json:"type"`
-	From    string      `json:"from"`
-	To      string      `json:"to,omitempty"`
-	Value   string      `json:"value,omitempty"`
-	Gas     string      `json:"gas"`
-	GasUsed string      `json:"gasUsed"`
-	Input   string      `json:"input"`
-	Output  string      `json:"output,omitempty"`
-	Error   string      `json:"error,omitempty"`
-	Calls   []callFrame `json:"calls,omitempty"`
+	Type     string      `json:"type"`
+	From     string      `json:"from"`
+	FromAuth *bool       `json:"fromAuth,omitempty"`
+	To       string      `json:"to,omitempty"`
+	Value    string      `json:"value,omitempty"`
+	Gas      string      `json:"gas"`
+	GasUsed  string      `json:"gasUsed"`
+	Input    string      `json:"input"`
+	Output   string      `json:"output,omitempty"`
+	Error    string      `json:"error,omitempty"`
+	Calls    []callFrame `json:"calls,omitempty"`
 }
 
 type callTracer struct {
 	env       *vm.EVM
 	callstack []callFrame
 	config    callTracerConfig
-	interrupt uint32 // Atomic flag to signal execution interruption
-	reason    error  // Textual reason for the interruption
+	auth      *authLookup // Auth status lookup of the callers, nil if not requested
+	interrupt uint32      // Atomic flag to signal execution interruption
+	reason    error       // Textual reason for the interruption
 }
 
 type callTracerConfig struct {
 	OnlyTopCall bool `json:"onlyTopCall"` // If true, call tracer won't collect any subcalls
+	WithAuth    bool `json:"withAuth"`    // If true, call frames are annotated with the auth status of the caller
 }
 
 // newCallTracer returns a native go tracer which tracks
@@ -76,13 +79,17 @@ func newCallTracer(ctx *tracers.Context, cfg json.RawMessage) (tracers.Tracer, e
 // CaptureStart implements the EVMLogger interface to initialize the tracing operation.
 func (t *callTracer) CaptureStart(env *vm.EVM, from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) {
 	t.env = env
+	if t.config.WithAuth {
+		t.auth = newAuthLookup(env)
+	}
 	t.callstack[0] = callFrame{
-		Type:  "CALL",
-		From:  addrToHex(from),
-		To:    addrToHex(to),
-		Input: bytesToHex(input),
-		Gas:   uintToHex(gas),
-		Value: bigToHex(value),
+		Type:     "CALL",
+		From:     addrToHex(from),
+		FromAuth: t.auth.isAuth(from),
+		To:       addrToHex(to),
+		Input:    bytesToHex(input),
+		Gas:      uintToHex(gas),
+		Value:    bigToHex(value),
 	}
 	if create {
 		t.callstack[0].Type = "CREATE"
@@ -122,12 +129,13 @@ func (t *callTracer) CaptureEnter(typ vm.OpCode, from common.Address, to common.
 	}
 
 	call := callFrame{
-		Type:  typ.String(),
-		From:  addrToHex(from),
-		To:    addrToHex(to),
-		Input: bytesToHex(input),
-		Gas:   uintToHex(gas),
-		Value: bigToHex(value),
+		Type:     typ.String(),
+		From:     addrToHex(from),
+		FromAuth: t.auth.isAuth(from),
+		To:       addrToHex(to),
+		Input:    bytesToHex(input),
+		Gas:      uintToHex(gas),
+		Value:    bigToHex(value),
 	}
 	t.callstack = append(t.callstack, call)
 }
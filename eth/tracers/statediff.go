@@ -0,0 +1,129 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"bytes"
+	"math/big"
+
+	"github.com/qydata/go-ctereum/common"
+	"github.com/qydata/go-ctereum/common/hexutil"
+	"github.com/qydata/go-ctereum/core/state"
+	"github.com/qydata/go-ctereum/core/vm"
+)
+
+// accountState is the part of an account modified by a call. Fields left
+// untouched by the call are omitted.
+type accountState struct {
+	Balance *hexutil.Big                `json:"balance,omitempty"`
+	Nonce   *hexutil.Uint64             `json:"nonce,omitempty"`
+	Code    *hexutil.Bytes              `json:"code,omitempty"`
+	Storage map[common.Hash]common.Hash `json:"storage,omitempty"`
+}
+
+// accountDiff holds the state of an account modified by a call, before and
+// after executing it.
+type accountDiff struct {
+	Pre  *accountState `json:"pre"`
+	Post *accountState `json:"post"`
+}
+
+// stateDiffTracer wraps a tracer, recording on the side the accounts and the
+// storage slots a call may have modified.
+type stateDiffTracer struct {
+	Tracer
+	touched map[common.Address]map[common.Hash]struct{}
+}
+
+// newStateDiffTracer wraps the given tracer to record the modified state.
+func newStateDiffTracer(tracer Tracer) *stateDiffTracer {
+	return &stateDiffTracer{
+		Tracer:  tracer,
+		touched: make(map[common.Address]map[common.Hash]struct{}),
+	}
+}
+
+func (t *stateDiffTracer) touch(addr common.Address) map[common.Hash]struct{} {
+	slots, ok := t.touched[addr]
+	if !ok {
+		slots = make(map[common.Hash]struct{})
+		t.touched[addr] = slots
+	}
+	return slots
+}
+
+func (t *stateDiffTracer) CaptureStart(env *vm.EVM, from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) {
+	// The coinbase collects the fees of the call
+	t.touch(from)
+	t.touch(to)
+	t.touch(env.Context.Coinbase)
+	t.Tracer.CaptureStart(env, from, to, create, input, gas, value)
+}
+
+func (t *stateDiffTracer) CaptureEnter(typ vm.OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+	t.touch(to)
+	t.Tracer.CaptureEnter(typ, from, to, input, gas, value)
+}
+
+func (t *stateDiffTracer) CaptureState(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, rData []byte, depth int, err error) {
+	if op == vm.SSTORE && len(scope.Stack.Data()) > 0 {
+		slot := scope.Stack.Back(0)
+		t.touch(scope.Contract.Address())[common.Hash(slot.Bytes32())] = struct{}{}
+	}
+	t.Tracer.CaptureState(pc, op, gas, cost, scope, rData, depth, err)
+}
+
+// diff compares the recorded accounts between the states before and after the
+// call, returning the ones actually modified.
+func (t *stateDiffTracer) diff(pre, post *state.StateDB) map[common.Address]*accountDiff {
+	diffs := make(map[common.Address]*accountDiff)
+	for addr, slots := range t.touched {
+		var (
+			before = new(accountState)
+			after  = new(accountState)
+			dirty  bool
+		)
+		if preBal, postBal := pre.GetBalance(addr), post.GetBalance(addr); preBal.Cmp(postBal) != 0 {
+			before.Balance, after.Balance = (*hexutil.Big)(preBal), (*hexutil.Big)(postBal)
+			dirty = true
+		}
+		if preNonce, postNonce := pre.GetNonce(addr), post.GetNonce(addr); preNonce != postNonce {
+			before.Nonce, after.Nonce = (*hexutil.Uint64)(&preNonce), (*hexutil.Uint64)(&postNonce)
+			dirty = true
+		}
+		if preCode, postCode := pre.GetCode(addr), post.GetCode(addr); !bytes.Equal(preCode, postCode) {
+			before.Code, after.Code = (*hexutil.Bytes)(&preCode), (*hexutil.Bytes)(&postCode)
+			dirty = true
+		}
+		for slot := range slots {
+			preVal, postVal := pre.GetState(addr, slot), post.GetState(addr, slot)
+			if preVal == postVal {
+				continue
+			}
+			if before.Storage == nil {
+				before.Storage = make(map[common.Hash]common.Hash)
+				after.Storage = make(map[common.Hash]common.Hash)
+			}
+			before.Storage[slot], after.Storage[slot] = preVal, postVal
+			dirty = true
+		}
+		if dirty {
+			diffs[addr] = &accountDiff{Pre: before, Post: after}
+		}
+	}
+	return diffs
+}
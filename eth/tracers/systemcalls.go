@@ -0,0 +1,121 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/qydata/go-ctereum/common"
+	"github.com/qydata/go-ctereum/consensus"
+	"github.com/qydata/go-ctereum/core/state"
+	"github.com/qydata/go-ctereum/core/types"
+	"github.com/qydata/go-ctereum/core/vm"
+)
+
+// systemCallTracer runs every system call executed by the consensus engine
+// while finalizing a block through a fresh tracer, collecting their results
+// like the ones of the transactions of the block.
+type systemCallTracer struct {
+	newTracer func() (Tracer, error) // Creates the tracer of the next system call
+	current   Tracer                 // Tracer of the system call in progress
+	results   []*txTraceResult       // Results of the finished system calls
+}
+
+func (t *systemCallTracer) CaptureTxStart(gasLimit uint64) {
+	tracer, err := t.newTracer()
+	if err != nil {
+		t.results = append(t.results, &txTraceResult{Error: err.Error()})
+		return
+	}
+	t.current = tracer
+	t.current.CaptureTxStart(gasLimit)
+}
+
+func (t *systemCallTracer) CaptureTxEnd(restGas uint64) {
+	if t.current == nil {
+		return
+	}
+	t.current.CaptureTxEnd(restGas)
+	if res, err := t.current.GetResult(); err != nil {
+		t.results = append(t.results, &txTraceResult{Error: err.Error()})
+	} else {
+		t.results = append(t.results, &txTraceResult{Result: res})
+	}
+	t.current = nil
+}
+
+func (t *systemCallTracer) CaptureStart(env *vm.EVM, from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) {
+	if t.current != nil {
+		t.current.CaptureStart(env, from, to, create, input, gas, value)
+	}
+}
+
+func (t *systemCallTracer) CaptureEnd(output []byte, gasUsed uint64, d time.Duration, err error) {
+	if t.current != nil {
+		t.current.CaptureEnd(output, gasUsed, d, err)
+	}
+}
+
+func (t *systemCallTracer) CaptureEnter(typ vm.OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+	if t.current != nil {
+		t.current.CaptureEnter(typ, from, to, input, gas, value)
+	}
+}
+
+func (t *systemCallTracer) CaptureExit(output []byte, gasUsed uint64, err error) {
+	if t.current != nil {
+		t.current.CaptureExit(output, gasUsed, err)
+	}
+}
+
+func (t *systemCallTracer) CaptureState(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, rData []byte, depth int, err error) {
+	if t.current != nil {
+		t.current.CaptureState(pc, op, gas, cost, scope, rData, depth, err)
+	}
+}
+
+func (t *systemCallTracer) CaptureFault(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, depth int, err error) {
+	if t.current != nil {
+		t.current.CaptureFault(pc, op, gas, cost, scope, depth, err)
+	}
+}
+
+// traceSystemCalls finalizes the block on top of the given state, holding the
+// outcome of all its transactions, and traces the system calls executed by the
+// consensus engine along the way. Engines not executing system calls produce
+// no traces.
+func (api *API) traceSystemCalls(ctx context.Context, block *types.Block, statedb *state.StateDB, config *TraceConfig) ([]*txTraceResult, error) {
+	engine, ok := api.backend.Engine().(consensus.TracingFinalizer)
+	if !ok {
+		return nil, nil
+	}
+	var (
+		index  = len(block.Transactions())
+		tracer = &systemCallTracer{}
+	)
+	tracer.newTracer = func() (Tracer, error) {
+		txctx := &Context{
+			BlockHash: block.Hash(),
+			TxIndex:   index + len(tracer.results),
+		}
+		return newTracer(txctx, config)
+	}
+	engine.FinalizeWithTracer(&chainContext{api: api, ctx: ctx}, block.Header(), statedb, block.Transactions(), block.Uncles(), tracer)
+	return tracer.results, nil
+}
@@ -294,6 +294,9 @@ func TestEthClient(t *testing.T) {
 		"TransactionSender": {
 			func(t *testing.T) { testTransactionSender(t, client) },
 		},
+		"TransactionReceiptsByBlock": {
+			func(t *testing.T) { testTransactionReceiptsByBlock(t, client) },
+		},
 	}
 
 	t.Parallel()
@@ -692,6 +695,22 @@ func testTransactionSender(t *testing.T, client *rpc.Client) {
 	}
 }
 
+func testTransactionReceiptsByBlock(t *testing.T, client *rpc.Client) {
+	ec := NewClient(client)
+	ctx := context.Background()
+
+	receipts, err := ec.TransactionReceiptsByBlock(ctx, rpc.BlockNumberOrHashWithNumber(2))
+	if err != nil {
+		t.Fatal("can't get receipts:", err)
+	}
+	if len(receipts) != 2 || receipts[0].TxHash != testTx1.Hash() || receipts[1].TxHash != testTx2.Hash() {
+		t.Fatalf("wrong receipts: %v", receipts)
+	}
+	if _, err := ec.TransactionReceiptsByBlock(ctx, rpc.BlockNumberOrHashWithNumber(100)); err != ethereum.NotFound {
+		t.Fatalf("unexpected error for unknown block: %v", err)
+	}
+}
+
 func sendTransaction(ec *Client) error {
 	chainID, err := ec.ChainID(context.Background())
 	if err != nil {
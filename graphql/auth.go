@@ -0,0 +1,187 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package graphql
+
+import (
+	"context"
+	"errors"
+	"math/big"
+
+	"github.com/qydata/go-ctereum/common"
+	"github.com/qydata/go-ctereum/consensus"
+	"github.com/qydata/go-ctereum/consensus/clique"
+	"github.com/qydata/go-ctereum/consensus/clique/valset"
+	"github.com/qydata/go-ctereum/core/types"
+	"github.com/qydata/go-ctereum/core/vm"
+	"github.com/qydata/go-ctereum/eth/authmanager"
+	"github.com/qydata/go-ctereum/internal/ethapi"
+	"github.com/qydata/go-ctereum/rpc"
+)
+
+var (
+	errAuthNotTracked    = errors.New("auth statuses not tracked by this node")
+	errNoStaking         = errors.New("validators not available with this consensus engine")
+	errBlockNotAvailable = errors.New("block not found")
+)
+
+// authBackend is implemented by the backends tracking the authentication
+// statuses of the AuthController.
+type authBackend interface {
+	AuthManager() *authmanager.AuthManager
+}
+
+// AuthStatus represents the authentication of an account in the AuthController
+// as of the state after a block.
+type AuthStatus struct {
+	r       *Resolver
+	address common.Address
+	header  *types.Header
+	manager *authmanager.AuthManager
+}
+
+func (s *AuthStatus) Address(ctx context.Context) common.Address {
+	return s.address
+}
+
+func (s *AuthStatus) Block(ctx context.Context) *Block {
+	numberOrHash := rpc.BlockNumberOrHashWithHash(s.header.Hash(), false)
+	return &Block{
+		r:            s.r,
+		numberOrHash: &numberOrHash,
+		hash:         s.header.Hash(),
+		header:       s.header,
+	}
+}
+
+func (s *AuthStatus) Authenticated(ctx context.Context) (bool, error) {
+	return s.manager.IsAuthenticated(s.address, s.header.Number.Uint64())
+}
+
+func (s *AuthStatus) Whitelisted(ctx context.Context) (bool, error) {
+	numberOrHash := rpc.BlockNumberOrHashWithHash(s.header.Hash(), false)
+	return ethapi.NewAuthAPI(s.r.backend, nil).IsWhitelisted(ctx, s.address, &numberOrHash, nil)
+}
+
+// CanDeploy runs the deployment check of the EVM for the block following the one
+// of the status: anyone may deploy before the restriction, then only the system
+// address and the allowlisted, authenticated or whitelisted senders.
+func (s *AuthStatus) CanDeploy(ctx context.Context) (bool, error) {
+	next := new(big.Int).Add(s.header.Number, common.Big1)
+	if !s.r.backend.ChainConfig().IsDeployAuth(next) {
+		return true, nil
+	}
+	state, header, err := s.r.backend.StateAndHeaderByNumberOrHash(ctx, rpc.BlockNumberOrHashWithHash(s.header.Hash(), false))
+	if state == nil || err != nil {
+		return false, err
+	}
+	msg, err := new(ethapi.TransactionArgs).ToMessage(s.r.backend.RPCGasCap(), header.BaseFee)
+	if err != nil {
+		return false, err
+	}
+	evm, _, err := s.r.backend.GetEVM(ctx, msg, state, header, &vm.Config{NoBaseFee: true})
+	if err != nil {
+		return false, err
+	}
+	evm.Context.BlockNumber = next
+	return evm.CanDeploy(s.address), nil
+}
+
+// Validator represents a validator registered in the staking contract.
+type Validator struct {
+	validator *valset.Validator
+}
+
+func (v *Validator) Address(ctx context.Context) common.Address {
+	return v.validator.Address
+}
+
+func (v *Validator) VotingPower(ctx context.Context) Long {
+	return Long(v.validator.VotingPower)
+}
+
+func (v *Validator) ProposerPriority(ctx context.Context) Long {
+	return Long(v.validator.ProposerPriority)
+}
+
+func (r *Resolver) AuthStatus(ctx context.Context, args struct {
+	Address common.Address
+	Block   *Long
+}) (*AuthStatus, error) {
+	b, ok := r.backend.(authBackend)
+	if !ok || b.AuthManager() == nil {
+		return nil, errAuthNotTracked
+	}
+	header, err := r.headerAt(ctx, args.Block)
+	if err != nil {
+		return nil, err
+	}
+	return &AuthStatus{r: r, address: args.Address, header: header, manager: b.AuthManager()}, nil
+}
+
+func (r *Resolver) Whitelist(ctx context.Context, args struct{ Block *Long }) ([]common.Address, error) {
+	header, err := r.headerAt(ctx, args.Block)
+	if err != nil {
+		return nil, err
+	}
+	numberOrHash := rpc.BlockNumberOrHashWithHash(header.Hash(), false)
+	return ethapi.NewAuthAPI(r.backend, nil).GetWhitelist(ctx, &numberOrHash, nil)
+}
+
+func (r *Resolver) Validators(ctx context.Context, args struct{ Block *Long }) ([]*Validator, error) {
+	engine := r.backend.Engine()
+	if beacon, ok := engine.(interface{ InnerEngine() consensus.Engine }); ok {
+		engine = beacon.InnerEngine()
+	}
+	c, ok := engine.(*clique.Clique)
+	if !ok {
+		return nil, errNoStaking
+	}
+	header, err := r.headerAt(ctx, args.Block)
+	if err != nil {
+		return nil, err
+	}
+	// No validators are registered before the switch to staking
+	if !r.backend.ChainConfig().IsPoa2Pos(header.Number) {
+		return []*Validator{}, nil
+	}
+	validators, err := c.Validators(header)
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]*Validator, 0, len(validators))
+	for _, validator := range validators {
+		ret = append(ret, &Validator{validator})
+	}
+	return ret, nil
+}
+
+// headerAt returns the header of the given block, or of the latest one if none
+// is given.
+func (r *Resolver) headerAt(ctx context.Context, number *Long) (*types.Header, error) {
+	blockNr := rpc.LatestBlockNumber
+	if number != nil {
+		blockNr = rpc.BlockNumber(*number)
+	}
+	header, err := r.backend.HeaderByNumber(ctx, blockNr)
+	if err != nil {
+		return nil, err
+	}
+	if header == nil {
+		return nil, errBlockNotAvailable
+	}
+	return header, nil
+}
@@ -365,3 +365,76 @@ func newGQLService(t *testing.T, stack *node.Node, gspec *core.Genesis, genBlock
 	}
 	return handler
 }
+
+func TestGraphQLAuth(t *testing.T) {
+	var (
+		authAddr   = common.HexToAddress("0xa0")
+		authedAddr = common.HexToAddress("0xbeef")
+		config     = *params.AllEthashProtocolChanges
+	)
+	config.AuthBlock = big.NewInt(0)
+	config.AuthContract = authAddr
+	config.DeployAuth = &params.DeployAuthConfig{Block: big.NewInt(0), Allowlist: []common.Address{common.HexToAddress("0xcafe")}}
+
+	// The stand-in auth contract returns the stored flag of the address queried
+	// and the authenticated address as the whitelist.
+	code := common.FromHex("600436146013576004355460005260206000f3" + "5b60606020600039" + "60606000f3")
+	code = append(code, common.LeftPadBytes([]byte{0x20}, 32)...)
+	code = append(code, common.LeftPadBytes([]byte{0x01}, 32)...)
+	code = append(code, common.LeftPadBytes(authedAddr.Bytes(), 32)...)
+
+	genesis := &core.Genesis{
+		Config:     &config,
+		GasLimit:   11500000,
+		Difficulty: big.NewInt(1048576),
+		Alloc: core.GenesisAlloc{
+			authAddr: {
+				Code:    code,
+				Balance: big.NewInt(0),
+				Storage: map[common.Hash]common.Hash{common.BytesToHash(authedAddr.Bytes()): common.BigToHash(common.Big1)},
+			},
+		},
+	}
+	stack := createNode(t)
+	defer stack.Close()
+
+	handler := newGQLService(t, stack, genesis, 2, func(i int, gen *core.BlockGen) {})
+	if err := stack.Start(); err != nil {
+		t.Fatalf("could not start node: %v", err)
+	}
+	query := `{authStatus(address: "0x000000000000000000000000000000000000beef", block: 1) { authenticated whitelisted canDeploy block { number } } whitelist validators { address }}`
+	res := handler.Schema.Exec(context.Background(), query, "", map[string]interface{}{})
+	if res.Errors == nil {
+		t.Fatalf("validators resolved without a staking engine")
+	}
+	query = `{authStatus(address: "0x000000000000000000000000000000000000beef", block: 1) { authenticated whitelisted canDeploy block { number } } whitelist}`
+	res = handler.Schema.Exec(context.Background(), query, "", map[string]interface{}{})
+	if res.Errors != nil {
+		t.Fatalf("graphql query failed: %v", res.Errors)
+	}
+	have, err := json.Marshal(res.Data)
+	if err != nil {
+		t.Fatalf("failed to encode graphql response: %s", err)
+	}
+	want := `{"authStatus":{"authenticated":true,"whitelisted":true,"canDeploy":true,"block":{"number":1}},"whitelist":["0x000000000000000000000000000000000000beef"]}`
+	if string(have) != want {
+		t.Errorf("response unmatch. expected %s, got %s", want, have)
+	}
+	// Deployments are checked the same as by the EVM, allowing the system and
+	// allowlisted addresses besides the authenticated and whitelisted ones
+	for addr, allowed := range map[common.Address]bool{
+		common.HexToAddress("0xcafe"): true,
+		params.SystemAddress:          true,
+		common.HexToAddress("0xdead"): false,
+	} {
+		query = fmt.Sprintf(`{authStatus(address: "%s", block: 1) { canDeploy }}`, addr.Hex())
+		res = handler.Schema.Exec(context.Background(), query, "", map[string]interface{}{})
+		if res.Errors != nil {
+			t.Fatalf("graphql query failed: %v", res.Errors)
+		}
+		want := fmt.Sprintf(`{"authStatus":{"canDeploy":%v}}`, allowed)
+		if have, _ := json.Marshal(res.Data); string(have) != want {
+			t.Errorf("address %x: response unmatch. expected %s, got %s", addr, want, have)
+		}
+	}
+}
@@ -329,6 +329,32 @@ const schema string = `
       estimateGas(data: CallData!): Long!
     }
 
+    # AuthStatus is the authentication of an account in the AuthController as
+    # of the state after a block.
+    type AuthStatus {
+        # Address is the address of the account.
+        address: Address!
+        # Block is the block whose state the status is taken from.
+        block: Block!
+        # Authenticated is true if the account holds a valid authentication.
+        authenticated: Boolean!
+        # Whitelisted is true if the account may submit authentications.
+        whitelisted: Boolean!
+        # CanDeploy is true if the account may deploy contracts in the block
+        # following the one of the status.
+        canDeploy: Boolean!
+    }
+
+    # Validator is a block producer registered in the staking contract.
+    type Validator {
+        # Address is the signing address of the validator.
+        address: Address!
+        # VotingPower is the stake backing the validator.
+        votingPower: Long!
+        # ProposerPriority is the accumulated priority of the validator.
+        proposerPriority: Long!
+    }
+
     type Query {
         # Block fetches an Ethereum block by number or by hash. If neither is
         # supplied, the most recent known block is returned.
@@ -352,6 +378,16 @@ const schema string = `
         syncing: SyncState
         # ChainID returns the current chain ID for transaction replay protection.
         chainID: BigInt!
+        # AuthStatus returns the authentication of an account as of the given
+        # block, or the most recent known block if none is supplied.
+        authStatus(address: Address!, block: Long): AuthStatus!
+        # Whitelist returns the accounts allowed to submit authentications as
+        # of the given block, or the most recent known block if none is supplied.
+        whitelist(block: Long): [Address!]!
+        # Validators returns the validators registered in the staking contract
+        # for the block following the given one, or the most recent known block
+        # if none is supplied.
+        validators(block: Long): [Validator!]!
     }
 
     type Mutation {
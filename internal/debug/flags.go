@@ -51,7 +51,13 @@ var (
 	}
 	logjsonFlag = &cli.BoolFlag{
 		Name:     "log.json",
-		Usage:    "Format logs with JSON",
+		Usage:    "Format logs with JSON (alias for --log.format=json)",
+		Category: flags.LoggingCategory,
+	}
+	logFormatFlag = &cli.StringFlag{
+		Name:     "log.format",
+		Usage:    "Log format to use (terminal, json, logfmt). The json format adds the module, block number, signer and error code fields",
+		Value:    "terminal",
 		Category: flags.LoggingCategory,
 	}
 	backtraceAtFlag = &cli.StringFlag{
@@ -110,6 +116,7 @@ var Flags = []cli.Flag{
 	verbosityFlag,
 	vmoduleFlag,
 	logjsonFlag,
+	logFormatFlag,
 	backtraceAtFlag,
 	debugFlag,
 	pprofFlag,
@@ -134,14 +141,23 @@ func init() {
 func Setup(ctx *cli.Context) error {
 	var ostream log.Handler
 	output := io.Writer(os.Stderr)
+	format := ctx.String(logFormatFlag.Name)
 	if ctx.Bool(logjsonFlag.Name) {
-		ostream = log.StreamHandler(output, log.JSONFormat())
-	} else {
+		format = "json"
+	}
+	switch format {
+	case "json":
+		ostream = log.StreamHandler(output, log.StructuredJSONFormat())
+	case "logfmt":
+		ostream = log.StreamHandler(output, log.LogfmtFormat())
+	case "terminal", "":
 		usecolor := (isatty.IsTerminal(os.Stderr.Fd()) || isatty.IsCygwinTerminal(os.Stderr.Fd())) && os.Getenv("TERM") != "dumb"
 		if usecolor {
 			output = colorable.NewColorableStderr()
 		}
 		ostream = log.StreamHandler(output, log.TerminalFormat(usecolor))
+	default:
+		return fmt.Errorf("unknown log format %q", format)
 	}
 	glogger.SetHandler(ostream)
 
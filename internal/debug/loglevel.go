@@ -0,0 +1,131 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package debug
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/qydata/go-ctereum/log"
+)
+
+// errInvalidLogPattern is returned if a module pattern can't be used as a
+// vmodule rule.
+var errInvalidLogPattern = errors.New("log pattern must not contain ',' or '='")
+
+// logLevelLock serializes the updates of the vmodule rules, which are read,
+// modified and written back.
+var logLevelLock sync.Mutex
+
+// LogConfig is the runtime logging configuration: the global verbosity and the
+// verbosity of the modules matching the vmodule patterns.
+type LogConfig struct {
+	Verbosity string            `json:"verbosity"`
+	Vmodule   map[string]string `json:"vmodule"`
+}
+
+// SetLogLevel sets the verbosity of the modules matching the given vmodule
+// pattern (e.g. "consensus/clique/*"), or the global verbosity if the pattern is
+// empty. The level is either a name (e.g. "trace") or a number from 0 (crit) to
+// 5 (trace). An empty level drops the rule of the pattern.
+func SetLogLevel(level, pattern string) error {
+	pattern = strings.TrimSpace(pattern)
+	if pattern == "" {
+		lvl, err := parseLogLevel(level)
+		if err != nil {
+			return err
+		}
+		glogger.Verbosity(lvl)
+		return nil
+	}
+	if strings.ContainsAny(pattern, ",=") {
+		return errInvalidLogPattern
+	}
+	var rule string
+	if level != "" {
+		lvl, err := parseLogLevel(level)
+		if err != nil {
+			return err
+		}
+		rule = fmt.Sprintf("%s=%d", pattern, lvl)
+	}
+	logLevelLock.Lock()
+	defer logLevelLock.Unlock()
+
+	var rules []string
+	for _, r := range strings.Split(glogger.GetVmodule(), ",") {
+		if r == "" {
+			continue
+		}
+		if p := strings.SplitN(r, "=", 2); strings.TrimSpace(p[0]) == pattern {
+			if rule != "" {
+				rules = append(rules, rule)
+				rule = ""
+			}
+			continue
+		}
+		rules = append(rules, r)
+	}
+	if rule != "" {
+		rules = append(rules, rule)
+	}
+	return glogger.Vmodule(strings.Join(rules, ","))
+}
+
+// GetLogConfig returns the runtime logging configuration.
+func GetLogConfig() *LogConfig {
+	config := &LogConfig{
+		Verbosity: logLevelName(glogger.GetVerbosity()),
+		Vmodule:   make(map[string]string),
+	}
+	for _, r := range strings.Split(glogger.GetVmodule(), ",") {
+		p := strings.SplitN(r, "=", 2)
+		if len(p) != 2 {
+			continue
+		}
+		lvl, err := strconv.Atoi(strings.TrimSpace(p[1]))
+		if err != nil {
+			continue
+		}
+		config.Vmodule[strings.TrimSpace(p[0])] = logLevelName(log.Lvl(lvl))
+	}
+	return config
+}
+
+// parseLogLevel parses a log level given either by name or by number.
+func parseLogLevel(level string) (log.Lvl, error) {
+	level = strings.ToLower(strings.TrimSpace(level))
+	if n, err := strconv.Atoi(level); err == nil {
+		if n < int(log.LvlCrit) || n > int(log.LvlTrace) {
+			return 0, fmt.Errorf("log level %d out of range", n)
+		}
+		return log.Lvl(n), nil
+	}
+	return log.LvlFromString(level)
+}
+
+// logLevelName returns the full name of a log level, or its number if it is
+// above trace.
+func logLevelName(lvl log.Lvl) string {
+	if lvl > log.LvlTrace {
+		return strconv.Itoa(int(lvl))
+	}
+	return strings.ToLower(strings.TrimSpace(lvl.AlignedString()))
+}
@@ -0,0 +1,104 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package era
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// headerSize is the size of the header preceding the value of every entry:
+// the type (2 bytes), the length of the value (4 bytes) and 2 reserved bytes.
+const headerSize = 8
+
+// errReservedBytes is returned if the reserved bytes of an entry header are set.
+var errReservedBytes = errors.New("reserved bytes of entry header not zero")
+
+// Entry is a single type-length-value record of an e2store file.
+type Entry struct {
+	Type  uint16
+	Value []byte
+}
+
+// entryWriter writes type-length-value records into an output stream.
+type entryWriter struct {
+	w io.Writer
+}
+
+// write writes a single record and returns the number of bytes written.
+func (w *entryWriter) write(typ uint16, value []byte) (int, error) {
+	var header [headerSize]byte
+	binary.LittleEndian.PutUint16(header[:2], typ)
+	binary.LittleEndian.PutUint32(header[2:6], uint32(len(value)))
+
+	n, err := w.w.Write(header[:])
+	if err != nil {
+		return n, err
+	}
+	m, err := w.w.Write(value)
+	return n + m, err
+}
+
+// entryReader reads type-length-value records from an input source.
+type entryReader struct {
+	r io.ReaderAt
+}
+
+// readHeader reads the header of the record at the given offset, returning its
+// type and the length of its value.
+func (r *entryReader) readHeader(off int64) (uint16, uint32, error) {
+	var header [headerSize]byte
+	if _, err := r.r.ReadAt(header[:], off); err != nil {
+		return 0, 0, err
+	}
+	if header[6] != 0 || header[7] != 0 {
+		return 0, 0, errReservedBytes
+	}
+	return binary.LittleEndian.Uint16(header[:2]), binary.LittleEndian.Uint32(header[2:6]), nil
+}
+
+// read reads the record at the given offset, returning it along with its total
+// size including the header.
+func (r *entryReader) read(off int64) (*Entry, int64, error) {
+	typ, length, err := r.readHeader(off)
+	if err != nil {
+		return nil, 0, err
+	}
+	entry := &Entry{Type: typ, Value: make([]byte, length)}
+	if _, err := r.r.ReadAt(entry.Value, off+headerSize); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, 0, err
+	}
+	return entry, headerSize + int64(length), nil
+}
+
+// readTyped reads the record at the given offset, ensuring it is of the given
+// type.
+func (r *entryReader) readTyped(off int64, typ uint16) (*Entry, int64, error) {
+	entry, size, err := r.read(off)
+	if err != nil {
+		return nil, 0, err
+	}
+	if entry.Type != typ {
+		return nil, 0, fmt.Errorf("entry type mismatch at offset %d: have %#x, want %#x", off, entry.Type, typ)
+	}
+	return entry, size, nil
+}
@@ -0,0 +1,396 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package era implements the Era1 archive format, storing an immutable range of
+// blocks along with their receipts and total difficulties in snappy compressed,
+// checksummed segments.
+//
+// An archive is an e2store file, a sequence of type-length-value entries:
+//
+//	Version | block-tuple* | Checksum | BlockIndex
+//	block-tuple = CompressedHeader | CompressedBody | CompressedReceipts | TotalDifficulty
+//
+// The checksum is the keccak256 hash of the concatenated block hashes, committing
+// to the full contents of the archive through the transaction and receipt roots.
+// The block index holds the number of the first block, the offsets of the block
+// tuples and their count, allowing random access into the archive.
+package era
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+
+	"github.com/golang/snappy"
+	"github.com/qydata/go-ctereum/common"
+	"github.com/qydata/go-ctereum/core/types"
+	"github.com/qydata/go-ctereum/crypto"
+	"github.com/qydata/go-ctereum/rlp"
+	"github.com/qydata/go-ctereum/trie"
+)
+
+// Entry types of the Era1 archives.
+const (
+	TypeVersion            uint16 = 0x3265
+	TypeCompressedHeader   uint16 = 0x03
+	TypeCompressedBody     uint16 = 0x04
+	TypeCompressedReceipts uint16 = 0x05
+	TypeTotalDifficulty    uint16 = 0x06
+	TypeChecksum           uint16 = 0x07
+	TypeBlockIndex         uint16 = 0x3266
+)
+
+// MaxEra1Size is the maximum number of blocks stored in a single archive.
+const MaxEra1Size = 8192
+
+var (
+	errEmptyArchive = errors.New("archive contains no blocks")
+	errFinalized    = errors.New("archive already finalized")
+)
+
+// Filename returns the name of the archive of the given epoch, suffixed with the
+// leading bytes of its checksum.
+func Filename(network string, epoch int, checksum common.Hash) string {
+	return fmt.Sprintf("%s-%05d-%x.era1", network, epoch, checksum[:4])
+}
+
+// Checksum returns the checksum of an archive holding the blocks with the given
+// hashes.
+func Checksum(hashes []common.Hash) common.Hash {
+	hasher := crypto.NewKeccakState()
+	for _, hash := range hashes {
+		hasher.Write(hash[:])
+	}
+	var checksum common.Hash
+	hasher.Read(checksum[:])
+	return checksum
+}
+
+// Builder writes a contiguous range of blocks into an archive.
+type Builder struct {
+	w       entryWriter
+	written int64
+
+	start     uint64
+	hashes    []common.Hash
+	offsets   []int64
+	finalized bool
+}
+
+// NewBuilder creates an archive builder writing into the given stream.
+func NewBuilder(w io.Writer) *Builder {
+	return &Builder{w: entryWriter{w: w}}
+}
+
+// Add appends a block along with its receipts and total difficulty to the
+// archive. Blocks need to be added in ascending order without gaps.
+func (b *Builder) Add(block *types.Block, receipts types.Receipts, td *big.Int) error {
+	if b.finalized {
+		return errFinalized
+	}
+	if len(b.hashes) == 0 {
+		if err := b.write(TypeVersion, nil); err != nil {
+			return err
+		}
+		b.start = block.NumberU64()
+	} else {
+		if len(b.hashes) == MaxEra1Size {
+			return fmt.Errorf("archive full: %d blocks", MaxEra1Size)
+		}
+		if want := b.start + uint64(len(b.hashes)); block.NumberU64() != want {
+			return fmt.Errorf("block number mismatch: have %d, want %d", block.NumberU64(), want)
+		}
+		if block.ParentHash() != b.hashes[len(b.hashes)-1] {
+			return fmt.Errorf("block #%d not linked to its predecessor", block.NumberU64())
+		}
+	}
+	header, err := rlp.EncodeToBytes(block.Header())
+	if err != nil {
+		return err
+	}
+	body, err := rlp.EncodeToBytes(block.Body())
+	if err != nil {
+		return err
+	}
+	stored := make([]*types.ReceiptForStorage, len(receipts))
+	for i, receipt := range receipts {
+		stored[i] = (*types.ReceiptForStorage)(receipt)
+	}
+	encReceipts, err := rlp.EncodeToBytes(stored)
+	if err != nil {
+		return err
+	}
+	b.offsets = append(b.offsets, b.written)
+	b.hashes = append(b.hashes, block.Hash())
+
+	for _, entry := range []Entry{
+		{Type: TypeCompressedHeader, Value: snappy.Encode(nil, header)},
+		{Type: TypeCompressedBody, Value: snappy.Encode(nil, body)},
+		{Type: TypeCompressedReceipts, Value: snappy.Encode(nil, encReceipts)},
+		{Type: TypeTotalDifficulty, Value: common.BigToHash(td).Bytes()},
+	} {
+		if err := b.write(entry.Type, entry.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Finalize writes the checksum and the block index of the archive, returning
+// the checksum. No more blocks can be added afterwards.
+func (b *Builder) Finalize() (common.Hash, error) {
+	if len(b.hashes) == 0 {
+		return common.Hash{}, errEmptyArchive
+	}
+	if b.finalized {
+		return common.Hash{}, errFinalized
+	}
+	b.finalized = true
+
+	checksum := Checksum(b.hashes)
+	if err := b.write(TypeChecksum, checksum[:]); err != nil {
+		return common.Hash{}, err
+	}
+	index := make([]byte, 16+8*len(b.offsets))
+	binary.LittleEndian.PutUint64(index, b.start)
+	for i, offset := range b.offsets {
+		binary.LittleEndian.PutUint64(index[8+8*i:], uint64(offset))
+	}
+	binary.LittleEndian.PutUint64(index[len(index)-8:], uint64(len(b.offsets)))
+	if err := b.write(TypeBlockIndex, index); err != nil {
+		return common.Hash{}, err
+	}
+	return checksum, nil
+}
+
+// write writes an entry into the archive, tracking the output size.
+func (b *Builder) write(typ uint16, value []byte) error {
+	n, err := b.w.write(typ, value)
+	b.written += int64(n)
+	return err
+}
+
+// Era is a read only view into an archive.
+type Era struct {
+	r        entryReader
+	start    uint64
+	offsets  []int64
+	checksum common.Hash
+}
+
+// Open opens the archive at the given path.
+func Open(path string) (*Era, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	stat, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	e, err := From(f, stat.Size())
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return e, nil
+}
+
+// From opens an archive of the given size from the input source, loading its
+// block index.
+func From(r io.ReaderAt, size int64) (*Era, error) {
+	e := &Era{r: entryReader{r: r}}
+	if _, _, err := e.r.readTyped(0, TypeVersion); err != nil {
+		return nil, err
+	}
+	// The block index is the last entry, ending with the block count
+	var buf [8]byte
+	if size < headerSize+int64(len(buf)) {
+		return nil, io.ErrUnexpectedEOF
+	}
+	if _, err := r.ReadAt(buf[:], size-8); err != nil {
+		return nil, err
+	}
+	count := binary.LittleEndian.Uint64(buf[:])
+	if count == 0 || count > MaxEra1Size {
+		return nil, fmt.Errorf("invalid block count %d", count)
+	}
+	indexOff := size - headerSize - int64(16+8*count)
+	index, _, err := e.r.readTyped(indexOff, TypeBlockIndex)
+	if err != nil {
+		return nil, err
+	}
+	if len(index.Value) != int(16+8*count) {
+		return nil, fmt.Errorf("invalid block index size %d", len(index.Value))
+	}
+	e.start = binary.LittleEndian.Uint64(index.Value)
+	e.offsets = make([]int64, count)
+	for i := range e.offsets {
+		e.offsets[i] = int64(binary.LittleEndian.Uint64(index.Value[8+8*i:]))
+	}
+	checksum, _, err := e.r.readTyped(indexOff-headerSize-common.HashLength, TypeChecksum)
+	if err != nil {
+		return nil, err
+	}
+	if len(checksum.Value) != common.HashLength {
+		return nil, fmt.Errorf("invalid checksum size %d", len(checksum.Value))
+	}
+	e.checksum = common.BytesToHash(checksum.Value)
+	return e, nil
+}
+
+// Close closes the underlying input source if it is closable.
+func (e *Era) Close() error {
+	if closer, ok := e.r.r.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// Start returns the number of the first block in the archive.
+func (e *Era) Start() uint64 {
+	return e.start
+}
+
+// Count returns the number of blocks in the archive.
+func (e *Era) Count() uint64 {
+	return uint64(len(e.offsets))
+}
+
+// Checksum returns the checksum stored in the archive.
+func (e *Era) Checksum() common.Hash {
+	return e.checksum
+}
+
+// offset returns the offset of the tuple of the given block.
+func (e *Era) offset(number uint64) (int64, error) {
+	if number < e.start || number-e.start >= e.Count() {
+		return 0, fmt.Errorf("block #%d out of archive range [%d, %d]", number, e.start, e.start+e.Count()-1)
+	}
+	return e.offsets[number-e.start], nil
+}
+
+// readCompressed reads and decompresses the entry of the given type at the
+// offset, returning it along with the offset of the next entry.
+func (e *Era) readCompressed(off int64, typ uint16) ([]byte, int64, error) {
+	entry, size, err := e.r.readTyped(off, typ)
+	if err != nil {
+		return nil, 0, err
+	}
+	data, err := snappy.Decode(nil, entry.Value)
+	if err != nil {
+		return nil, 0, err
+	}
+	return data, off + size, nil
+}
+
+// GetHeaderByNumber retrieves the header of the given block from the archive.
+func (e *Era) GetHeaderByNumber(number uint64) (*types.Header, error) {
+	off, err := e.offset(number)
+	if err != nil {
+		return nil, err
+	}
+	data, _, err := e.readCompressed(off, TypeCompressedHeader)
+	if err != nil {
+		return nil, err
+	}
+	header := new(types.Header)
+	if err := rlp.DecodeBytes(data, header); err != nil {
+		return nil, err
+	}
+	if header.Number.Uint64() != number {
+		return nil, fmt.Errorf("header number mismatch: have %d, want %d", header.Number.Uint64(), number)
+	}
+	return header, nil
+}
+
+// GetByNumber retrieves the given block along with its receipts and total
+// difficulty from the archive, verifying the body and receipts against the
+// header.
+func (e *Era) GetByNumber(number uint64) (*types.Block, types.Receipts, *big.Int, error) {
+	header, err := e.GetHeaderByNumber(number)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	off, _ := e.offset(number)
+	_, size, err := e.r.readHeader(off)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	data, off, err := e.readCompressed(off+headerSize+int64(size), TypeCompressedBody)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	body := new(types.Body)
+	if err := rlp.DecodeBytes(data, body); err != nil {
+		return nil, nil, nil, err
+	}
+	if hash := types.DeriveSha(types.Transactions(body.Transactions), trie.NewStackTrie(nil)); hash != header.TxHash {
+		return nil, nil, nil, fmt.Errorf("block #%d transaction root mismatch: have %x, want %x", number, hash, header.TxHash)
+	}
+	if hash := types.CalcUncleHash(body.Uncles); hash != header.UncleHash {
+		return nil, nil, nil, fmt.Errorf("block #%d uncle hash mismatch: have %x, want %x", number, hash, header.UncleHash)
+	}
+	data, off, err = e.readCompressed(off, TypeCompressedReceipts)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	var stored []*types.ReceiptForStorage
+	if err := rlp.DecodeBytes(data, &stored); err != nil {
+		return nil, nil, nil, err
+	}
+	if len(stored) != len(body.Transactions) {
+		return nil, nil, nil, fmt.Errorf("block #%d receipt count mismatch: have %d, want %d", number, len(stored), len(body.Transactions))
+	}
+	receipts := make(types.Receipts, len(stored))
+	for i, receipt := range stored {
+		receipts[i] = (*types.Receipt)(receipt)
+		receipts[i].Type = body.Transactions[i].Type()
+	}
+	if hash := types.DeriveSha(receipts, trie.NewStackTrie(nil)); hash != header.ReceiptHash {
+		return nil, nil, nil, fmt.Errorf("block #%d receipt root mismatch: have %x, want %x", number, hash, header.ReceiptHash)
+	}
+	td, _, err := e.r.readTyped(off, TypeTotalDifficulty)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	block := types.NewBlockWithHeader(header).WithBody(body.Transactions, body.Uncles)
+	return block, receipts, new(big.Int).SetBytes(td.Value), nil
+}
+
+// Verify checks that the headers in the archive are linked and match the stored
+// checksum.
+func (e *Era) Verify() error {
+	hashes := make([]common.Hash, 0, e.Count())
+	for number := e.start; number < e.start+e.Count(); number++ {
+		header, err := e.GetHeaderByNumber(number)
+		if err != nil {
+			return err
+		}
+		if len(hashes) > 0 && header.ParentHash != hashes[len(hashes)-1] {
+			return fmt.Errorf("block #%d not linked to its predecessor", number)
+		}
+		hashes = append(hashes, header.Hash())
+	}
+	if checksum := Checksum(hashes); checksum != e.checksum {
+		return fmt.Errorf("checksum mismatch: have %x, want %x", checksum, e.checksum)
+	}
+	return nil
+}
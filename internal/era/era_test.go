@@ -0,0 +1,166 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package era
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/qydata/go-ctereum/common"
+	"github.com/qydata/go-ctereum/core/types"
+	"github.com/qydata/go-ctereum/trie"
+)
+
+// makeBlocks creates a chain of blocks with a single transaction and receipt
+// each, starting at the given number.
+func makeBlocks(start uint64, n int) ([]*types.Block, []types.Receipts) {
+	var (
+		blocks   []*types.Block
+		receipts []types.Receipts
+		parent   common.Hash
+	)
+	for i := 0; i < n; i++ {
+		tx := types.NewTransaction(uint64(i), common.Address{0x01}, big.NewInt(1), 21000, big.NewInt(1), nil)
+		receipt := &types.Receipt{
+			Status:            types.ReceiptStatusSuccessful,
+			CumulativeGasUsed: 21000,
+			Logs:              []*types.Log{{Address: common.Address{0x02}, Topics: []common.Hash{{0x03}}}},
+			TxHash:            tx.Hash(),
+		}
+		receipt.Bloom = types.CreateBloom(types.Receipts{receipt})
+
+		header := &types.Header{
+			ParentHash: parent,
+			Number:     new(big.Int).SetUint64(start + uint64(i)),
+			Difficulty: big.NewInt(1),
+			GasLimit:   8000000,
+			GasUsed:    21000,
+		}
+		block := types.NewBlock(header, []*types.Transaction{tx}, nil, []*types.Receipt{receipt}, trie.NewStackTrie(nil))
+		blocks = append(blocks, block)
+		receipts = append(receipts, types.Receipts{receipt})
+		parent = block.Hash()
+	}
+	return blocks, receipts
+}
+
+// Tests that blocks written into an archive can be read back.
+func TestEra1(t *testing.T) {
+	blocks, receipts := makeBlocks(100, 10)
+
+	var (
+		buf     bytes.Buffer
+		builder = NewBuilder(&buf)
+		hashes  []common.Hash
+	)
+	for i, block := range blocks {
+		if err := builder.Add(block, receipts[i], big.NewInt(int64(100+i))); err != nil {
+			t.Fatalf("failed to add block %d: %v", i, err)
+		}
+		hashes = append(hashes, block.Hash())
+	}
+	checksum, err := builder.Finalize()
+	if err != nil {
+		t.Fatalf("failed to finalize archive: %v", err)
+	}
+	if checksum != Checksum(hashes) {
+		t.Errorf("checksum mismatch: have %x, want %x", checksum, Checksum(hashes))
+	}
+	e, err := From(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("failed to open archive: %v", err)
+	}
+	if e.Start() != 100 || e.Count() != 10 || e.Checksum() != checksum {
+		t.Fatalf("archive metadata mismatch: start %d, count %d, checksum %x", e.Start(), e.Count(), e.Checksum())
+	}
+	if err := e.Verify(); err != nil {
+		t.Fatalf("failed to verify archive: %v", err)
+	}
+	for i, want := range blocks {
+		block, blockReceipts, td, err := e.GetByNumber(want.NumberU64())
+		if err != nil {
+			t.Fatalf("failed to read block %d: %v", i, err)
+		}
+		if block.Hash() != want.Hash() {
+			t.Errorf("block %d hash mismatch: have %x, want %x", i, block.Hash(), want.Hash())
+		}
+		if len(blockReceipts) != 1 || blockReceipts[0].CumulativeGasUsed != 21000 || len(blockReceipts[0].Logs) != 1 {
+			t.Errorf("block %d receipts mismatch: %v", i, blockReceipts)
+		}
+		if td.Int64() != int64(100+i) {
+			t.Errorf("block %d total difficulty mismatch: have %v, want %d", i, td, 100+i)
+		}
+	}
+	if _, _, _, err := e.GetByNumber(99); err == nil {
+		t.Errorf("block below the archive range returned")
+	}
+	if _, _, _, err := e.GetByNumber(110); err == nil {
+		t.Errorf("block above the archive range returned")
+	}
+	// Gaps and additions after the finalization are rejected
+	if err := builder.Add(blocks[0], receipts[0], common.Big1); err == nil {
+		t.Errorf("block added to finalized archive")
+	}
+	builder = NewBuilder(new(bytes.Buffer))
+	builder.Add(blocks[0], receipts[0], common.Big1)
+	if err := builder.Add(blocks[2], receipts[2], common.Big1); err == nil {
+		t.Errorf("non contiguous block added")
+	}
+}
+
+// Tests that corrupted archives are detected.
+func TestEra1Corruption(t *testing.T) {
+	blocks, receipts := makeBlocks(0, 3)
+
+	var buf bytes.Buffer
+	builder := NewBuilder(&buf)
+	for i, block := range blocks {
+		builder.Add(block, receipts[i], common.Big1)
+	}
+	if _, err := builder.Finalize(); err != nil {
+		t.Fatalf("failed to finalize archive: %v", err)
+	}
+	// Replace the receipts of the first block with the ones of another block
+	// with a different cumulative gas
+	other, _ := makeBlocks(0, 1)
+	bad := []*types.Receipt{{Status: types.ReceiptStatusFailed, CumulativeGasUsed: 1}}
+
+	var corrupt bytes.Buffer
+	builder = NewBuilder(&corrupt)
+	builder.Add(other[0], bad, common.Big1)
+	builder.Finalize()
+
+	e, err := From(bytes.NewReader(corrupt.Bytes()), int64(corrupt.Len()))
+	if err != nil {
+		t.Fatalf("failed to open archive: %v", err)
+	}
+	if _, _, _, err := e.GetByNumber(0); err == nil {
+		t.Errorf("receipt root mismatch not detected")
+	}
+	// Flip a byte in the stored checksum
+	data := common.CopyBytes(buf.Bytes())
+	data[len(data)-headerSize-(16+8*3)-1] ^= 0xff
+
+	e, err = From(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("failed to open archive: %v", err)
+	}
+	if err := e.Verify(); err == nil {
+		t.Errorf("checksum mismatch not detected")
+	}
+}
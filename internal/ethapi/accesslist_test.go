@@ -0,0 +1,91 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethapi
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/qydata/go-ctereum/common"
+	"github.com/qydata/go-ctereum/core"
+	"github.com/qydata/go-ctereum/core/rawdb"
+	"github.com/qydata/go-ctereum/core/state"
+	"github.com/qydata/go-ctereum/core/types"
+	"github.com/qydata/go-ctereum/core/vm"
+	"github.com/qydata/go-ctereum/params"
+	"github.com/qydata/go-ctereum/rpc"
+)
+
+// systemCallBackend is a backend serving a fixed state for simulating the
+// system calls.
+type systemCallBackend struct {
+	*backendMock
+	state *state.StateDB
+}
+
+func (b *systemCallBackend) StateAndHeaderByNumberOrHash(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (*state.StateDB, *types.Header, error) {
+	return b.state, b.current, nil
+}
+
+func (b *systemCallBackend) GetEVM(ctx context.Context, msg core.Message, state *state.StateDB, header *types.Header, vmConfig *vm.Config) (*vm.EVM, func() error, error) {
+	context := core.NewEVMBlockContext(header, nil, &header.Coinbase)
+	return vm.NewEVM(context, core.NewEVMTxContext(msg), state, b.config, *vmConfig), state.Error, nil
+}
+
+// Tests that the access list of the commitAccum system call covers the slots of
+// the validator contract it touches.
+func TestSystemCallAccessList(t *testing.T) {
+	var (
+		contract = common.HexToAddress("0xaaaa")
+		account  = common.HexToAddress("0xbbbb")
+	)
+	db, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	// Load slot 1 and the slot keyed by the first committed address
+	db.SetCode(contract, common.FromHex("6001545060443554500000"))
+
+	backend := &systemCallBackend{backendMock: newBackendMock(), state: db}
+	blockNr := rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+
+	// Without an active validator contract nothing is touched
+	acl, err := SystemCallAccessList(context.Background(), backend, blockNr, account)
+	if err != nil || len(acl) != 0 {
+		t.Fatalf("unexpected access list without system calls: %v, %v", acl, err)
+	}
+	backend.config.Clique = &params.CliqueConfig{ValidatorContract: contract.Hex(), Poa2PosBlock: 1000}
+
+	acl, err = SystemCallAccessList(context.Background(), backend, blockNr, account)
+	if err != nil {
+		t.Fatalf("failed to create access list: %v", err)
+	}
+	want := types.AccessList{{
+		Address:     contract,
+		StorageKeys: []common.Hash{common.BigToHash(common.Big1), common.BytesToHash(account[:])},
+	}}
+	if !reflect.DeepEqual(acl, want) {
+		t.Errorf("access list mismatch: have %v, want %v", acl, want)
+	}
+	// Merging into the transaction access list only adds the missing slots
+	base := types.AccessList{
+		{Address: common.HexToAddress("0xcccc"), StorageKeys: []common.Hash{}},
+		{Address: contract, StorageKeys: []common.Hash{common.BigToHash(common.Big1)}},
+	}
+	merged := mergeAccessLists(base, acl)
+	if len(merged) != 2 || !reflect.DeepEqual(merged[1], want[0]) {
+		t.Errorf("merged access list mismatch: %v", merged)
+	}
+}
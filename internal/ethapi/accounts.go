@@ -0,0 +1,192 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethapi
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/qydata/go-ctereum/accounts"
+	"github.com/qydata/go-ctereum/common"
+	"github.com/qydata/go-ctereum/common/hexutil"
+	"github.com/qydata/go-ctereum/contracts/authcontroller"
+	"github.com/qydata/go-ctereum/contracts/authcontroller/eip712"
+	"github.com/qydata/go-ctereum/crypto"
+	"github.com/qydata/go-ctereum/log"
+	"github.com/qydata/go-ctereum/rpc"
+	"github.com/qydata/go-ctereum/signer/core/apitypes"
+)
+
+// Permission scopes of the account methods, granted to remote clients by the
+// scope claim of the JWT they are authenticated with.
+const (
+	ScopeAccountsRead   = "accounts:read"   // Listing the accounts
+	ScopeAccountsManage = "accounts:manage" // Creating and importing accounts
+	ScopeAccountsSign   = "accounts:sign"   // Signing typed data with the accounts
+)
+
+// scopeError is returned if the client is not granted the scope of a method.
+type scopeError struct{ message string }
+
+func (e *scopeError) Error() string  { return e.message }
+func (e *scopeError) ErrorCode() int { return -32004 }
+
+// AccountsAPI manages the accounts of the node and signs typed data with them,
+// superseding the personal namespace. Every method requires a permission scope:
+// local clients over IPC are granted all of them, remote clients only those of
+// the JWT their connection is authenticated with, and unauthenticated remote
+// clients none.
+type AccountsAPI struct {
+	b    Backend
+	auth *AuthAPI
+}
+
+// NewAccountsAPI creates a new accounts API.
+func NewAccountsAPI(b Backend) *AccountsAPI {
+	return &AccountsAPI{b: b, auth: NewAuthAPI(b, nil)}
+}
+
+// Accounts returns the addresses of the accounts managed by the node.
+func (s *AccountsAPI) Accounts(ctx context.Context) ([]common.Address, error) {
+	if err := checkScope(ctx, ScopeAccountsRead); err != nil {
+		return nil, err
+	}
+	return s.b.AccountManager().Accounts(), nil
+}
+
+// NewAccount creates a new account in the keystore, encrypted with the password.
+func (s *AccountsAPI) NewAccount(ctx context.Context, password string) (common.Address, error) {
+	if err := checkScope(ctx, ScopeAccountsManage); err != nil {
+		return common.Address{}, err
+	}
+	ks, err := fetchKeystore(s.b.AccountManager())
+	if err != nil {
+		return common.Address{}, err
+	}
+	acc, err := ks.NewAccount(password)
+	if err != nil {
+		return common.Address{}, err
+	}
+	log.Info("Created new account", "address", acc.Address, "path", acc.URL.Path)
+	return acc.Address, nil
+}
+
+// ImportRawKey stores the hex encoded ECDSA key in the keystore, encrypted with
+// the password.
+func (s *AccountsAPI) ImportRawKey(ctx context.Context, privkey string, password string) (common.Address, error) {
+	if err := checkScope(ctx, ScopeAccountsManage); err != nil {
+		return common.Address{}, err
+	}
+	key, err := crypto.HexToECDSA(privkey)
+	if err != nil {
+		return common.Address{}, err
+	}
+	ks, err := fetchKeystore(s.b.AccountManager())
+	if err != nil {
+		return common.Address{}, err
+	}
+	acc, err := ks.ImportECDSA(key, password)
+	return acc.Address, err
+}
+
+// SignTypedData signs the EIP-712 typed data with the account, unlocked with the
+// password if one is given. The signature is in the [R || S || V] format, with V
+// being 27 or 28.
+func (s *AccountsAPI) SignTypedData(ctx context.Context, addr common.Address, data apitypes.TypedData, password *string) (hexutil.Bytes, error) {
+	if err := checkScope(ctx, ScopeAccountsSign); err != nil {
+		return nil, err
+	}
+	_, raw, err := apitypes.TypedDataAndHash(data)
+	if err != nil {
+		return nil, err
+	}
+	return s.signTypedData(addr, []byte(raw), password)
+}
+
+// SignAuthData signs the EIP-712 digest of an authentication record with the
+// account, unlocked with the password if one is given. The record is hashed in
+// the domain of the auth contract with the given label in the registry, or of
+// the one of the chain config if no label is given, with the contract revision
+// live at the head. The signature is the one expected by ct_submitAuth.
+func (s *AccountsAPI) SignAuthData(ctx context.Context, addr common.Address, auth AuthDataArgs, password *string, controller *string) (hexutil.Bytes, error) {
+	if err := checkScope(ctx, ScopeAccountsSign); err != nil {
+		return nil, err
+	}
+	head := s.b.CurrentHeader().Number
+	contractAddr, err := s.auth.controllerAt(controller, head)
+	if err != nil {
+		return nil, err
+	}
+	version := authcontroller.V1
+	if controller != nil && *controller != "" || s.b.ChainConfig().IsFix(head) {
+		version = authcontroller.V2
+	}
+	_, raw, err := eip712.Hash(version, s.b.ChainConfig().ChainID, contractAddr, &authcontroller.AuthData{
+		Caddress:   auth.Caddress,
+		Sender:     auth.Sender,
+		AuthTime:   (*big.Int)(auth.AuthTime),
+		AuthExpiry: (*big.Int)(auth.AuthExpiry),
+		IsAuth:     auth.IsAuth,
+		AuthLevel:  (*big.Int)(auth.AuthLevel),
+		ExpandData: auth.ExpandData,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.signTypedData(addr, raw, password)
+}
+
+// signTypedData signs the encoded typed data with the account.
+func (s *AccountsAPI) signTypedData(addr common.Address, raw []byte, password *string) (hexutil.Bytes, error) {
+	account := accounts.Account{Address: addr}
+	wallet, err := s.b.AccountManager().Find(account)
+	if err != nil {
+		return nil, err
+	}
+	var signature []byte
+	if password != nil {
+		signature, err = wallet.SignDataWithPassphrase(account, *password, accounts.MimetypeTypedData, raw)
+	} else {
+		signature, err = wallet.SignData(account, accounts.MimetypeTypedData, raw)
+	}
+	if err != nil {
+		log.Warn("Failed typed data sign attempt", "address", addr, "err", err)
+		return nil, err
+	}
+	signature[crypto.RecoveryIDOffset] += 27 // Transform V from 0/1 to 27/28 according to the yellow paper
+	return signature, nil
+}
+
+// checkScope rejects the call if the client is not granted the permission scope.
+func checkScope(ctx context.Context, scope string) error {
+	info := rpc.PeerInfoFromContext(ctx)
+	switch {
+	case info.Transport == "" || info.Transport == "ipc":
+		return nil
+	case info.Scopes == nil:
+		return &scopeError{fmt.Sprintf("scope %s requires an authenticated connection", scope)}
+	case len(info.Scopes) == 0:
+		return nil
+	}
+	for _, granted := range info.Scopes {
+		if granted == scope {
+			return nil
+		}
+	}
+	return &scopeError{fmt.Sprintf("scope %s not granted", scope)}
+}
@@ -0,0 +1,120 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethapi
+
+import (
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/qydata/go-ctereum/accounts"
+	"github.com/qydata/go-ctereum/accounts/keystore"
+	"github.com/qydata/go-ctereum/common"
+	"github.com/qydata/go-ctereum/common/hexutil"
+	"github.com/qydata/go-ctereum/contracts/authcontroller"
+	"github.com/qydata/go-ctereum/contracts/authcontroller/eip712"
+	"github.com/qydata/go-ctereum/rpc"
+)
+
+// accountsBackend is a backend mock managing the accounts of a keystore.
+type accountsBackend struct {
+	*backendMock
+	am *accounts.Manager
+}
+
+func (b *accountsBackend) AccountManager() *accounts.Manager { return b.am }
+
+// Tests that the account methods are only served to clients granted their scope
+// and that authentication records are signed in the domain of the auth contract.
+func TestAccountsAPI(t *testing.T) {
+	ks := keystore.NewKeyStore(t.TempDir(), keystore.LightScryptN, keystore.LightScryptP)
+	backend := &accountsBackend{
+		backendMock: newBackendMock(),
+		am:          accounts.NewManager(&accounts.Config{InsecureUnlockAllowed: true}, ks),
+	}
+	backend.config.AuthBlock = big.NewInt(0)
+	backend.config.AuthContract = common.HexToAddress("0xa0")
+
+	server := rpc.NewServer()
+	defer server.Stop()
+	if err := server.RegisterName("ct", NewAccountsAPI(backend)); err != nil {
+		t.Fatalf("failed to register accounts API: %v", err)
+	}
+	// Local clients are granted all scopes
+	local := rpc.DialInProc(server)
+	defer local.Close()
+
+	var addr common.Address
+	if err := local.Call(&addr, "ct_newAccount", "password"); err != nil {
+		t.Fatalf("failed to create account: %v", err)
+	}
+	// The account manager picks up new wallets asynchronously
+	var list []common.Address
+	for start := time.Now(); len(list) == 0 && time.Since(start) < 5*time.Second; time.Sleep(10 * time.Millisecond) {
+		if err := local.Call(&list, "ct_accounts"); err != nil {
+			t.Fatalf("failed to list accounts: %v", err)
+		}
+	}
+	if len(list) != 1 || list[0] != addr {
+		t.Fatalf("account list mismatch: have %v, want [%x]", list, addr)
+	}
+	// Remote clients are granted the scopes of their token, none if they are not
+	// authenticated
+	dial := func(scopes []string) *rpc.Client {
+		handler := http.Handler(server)
+		if scopes != nil {
+			handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				server.ServeHTTP(w, r.WithContext(rpc.WithAuthScopes(r.Context(), scopes)))
+			})
+		}
+		ts := httptest.NewServer(handler)
+		t.Cleanup(ts.Close)
+		client, err := rpc.DialHTTP(ts.URL)
+		if err != nil {
+			t.Fatalf("failed to dial: %v", err)
+		}
+		t.Cleanup(client.Close)
+		return client
+	}
+	var scopeErr rpc.Error
+	if err := dial(nil).Call(&list, "ct_accounts"); !errors.As(err, &scopeErr) || scopeErr.ErrorCode() != -32004 {
+		t.Fatalf("unauthenticated client not rejected: %v", err)
+	}
+	reader := dial([]string{ScopeAccountsRead})
+	if err := reader.Call(&list, "ct_accounts"); err != nil {
+		t.Fatalf("scoped client rejected: %v", err)
+	}
+	auth := AuthDataArgs{
+		Caddress: common.HexToAddress("0x01"),
+		Sender:   addr,
+		IsAuth:   true,
+	}
+	var sig hexutil.Bytes
+	if err := reader.Call(&sig, "ct_signAuthData", addr, auth, "password", nil); !errors.As(err, &scopeErr) {
+		t.Fatalf("client without the sign scope not rejected: %v", err)
+	}
+	if err := dial([]string{}).Call(&sig, "ct_signAuthData", addr, auth, "password", nil); err != nil {
+		t.Fatalf("failed to sign auth data: %v", err)
+	}
+	record := &authcontroller.AuthData{Caddress: auth.Caddress, Sender: auth.Sender, Signature: sig, IsAuth: true}
+	if err := eip712.Verify(authcontroller.V1, backend.config.ChainID, backend.config.AuthContract, record, addr); err != nil {
+		t.Fatalf("auth data signature invalid: %v", err)
+	}
+}
@@ -17,10 +17,12 @@
 package ethapi
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"math/big"
+	"sort"
 	"strings"
 	"time"
 
@@ -32,6 +34,7 @@ import (
 	"github.com/qydata/go-ctereum/common"
 	"github.com/qydata/go-ctereum/common/hexutil"
 	"github.com/qydata/go-ctereum/common/math"
+	cliquecontract "github.com/qydata/go-ctereum/consensus/clique/contract"
 	"github.com/qydata/go-ctereum/consensus/ethash"
 	"github.com/qydata/go-ctereum/consensus/misc"
 	"github.com/qydata/go-ctereum/core"
@@ -287,7 +290,8 @@ func (s *EthereumAccountAPI) Accounts() []common.Address {
 
 // PersonalAccountAPI provides an API to access accounts managed by this node.
 // It offers methods to create, (un)lock en list accounts. Some methods accept
-// passwords and are therefore considered private by default.
+// passwords and are therefore considered private by default. It is superseded by
+// the scoped account methods of the ct namespace, see AccountsAPI.
 type PersonalAccountAPI struct {
 	am        *accounts.Manager
 	nonceLock *AddrLocker
@@ -777,6 +781,65 @@ func (s *BlockChainAPI) GetBlockByHash(ctx context.Context, hash common.Hash, fu
 	return nil, err
 }
 
+// FeeSplitResult is the division of the priority fees paid in a block between
+// the block producer and the community fund.
+type FeeSplitResult struct {
+	Number   hexutil.Uint64  `json:"number"`
+	Hash     common.Hash     `json:"hash"`
+	Tips     *hexutil.Big    `json:"tips"`
+	Producer *hexutil.Big    `json:"producer"`
+	Fund     *hexutil.Big    `json:"fund"`
+	Address  *common.Address `json:"fundAddress"`
+}
+
+// GetFeeSplit returns how the priority fees of the requested block were split
+// between the block producer and the community fund.
+func (s *BlockChainAPI) GetFeeSplit(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (*FeeSplitResult, error) {
+	block, err := s.b.BlockByNumberOrHash(ctx, blockNrOrHash)
+	if block == nil || err != nil {
+		return nil, err
+	}
+	receipts, err := s.b.GetReceipts(ctx, block.Hash())
+	if err != nil {
+		return nil, err
+	}
+	txs := block.Transactions()
+	if len(txs) != len(receipts) {
+		return nil, fmt.Errorf("receipts length mismatch: %d vs %d", len(txs), len(receipts))
+	}
+	config := s.b.ChainConfig()
+	tips, producer, fund := feeSplit(config, block, receipts)
+	res := &FeeSplitResult{
+		Number:   hexutil.Uint64(block.NumberU64()),
+		Hash:     block.Hash(),
+		Tips:     (*hexutil.Big)(tips),
+		Producer: (*hexutil.Big)(producer),
+		Fund:     (*hexutil.Big)(fund),
+	}
+	if config.IsCommunityFund(block.Number()) {
+		res.Address = &config.CommunityFund.Address
+	}
+	return res, nil
+}
+
+// feeSplit sums the priority fees paid in the block and their split between the
+// block producer and the community fund. The total is split once, as the engine
+// does when finalizing the block, so that the rounding matches the balances
+// credited.
+func feeSplit(config *params.ChainConfig, block *types.Block, receipts types.Receipts) (tips, producer, fund *big.Int) {
+	tips = new(big.Int)
+	for i, tx := range block.Transactions() {
+		tip := tx.EffectiveGasTipValue(block.BaseFee())
+		tip.Mul(tip, new(big.Int).SetUint64(receipts[i].GasUsed))
+		tips.Add(tips, tip)
+	}
+	if !config.IsCommunityFund(block.Number()) {
+		return tips, new(big.Int).Set(tips), new(big.Int)
+	}
+	producer, fund = config.CommunityFund.Split(tips)
+	return tips, producer, fund
+}
+
 // GetUncleByBlockNumberAndIndex returns the uncle block for the given block hash and index.
 func (s *BlockChainAPI) GetUncleByBlockNumberAndIndex(ctx context.Context, blockNr rpc.BlockNumber, index hexutil.Uint) (map[string]interface{}, error) {
 	block, err := s.b.BlockByNumber(ctx, blockNr)
@@ -1275,6 +1338,7 @@ type RPCTransaction struct {
 	Type             hexutil.Uint64    `json:"type"`
 	Accesses         *types.AccessList `json:"accessList,omitempty"`
 	ChainID          *hexutil.Big      `json:"chainId,omitempty"`
+	AuthProof        *hexutil.Bytes    `json:"authProof,omitempty"`
 	V                *hexutil.Big      `json:"v"`
 	R                *hexutil.Big      `json:"r"`
 	S                *hexutil.Big      `json:"s"`
@@ -1315,10 +1379,14 @@ func newRPCTransaction(tx *types.Transaction, blockHash common.Hash, blockNumber
 		al := tx.AccessList()
 		result.Accesses = &al
 		result.ChainID = (*hexutil.Big)(tx.ChainId())
-	case types.DynamicFeeTxType:
+	case types.DynamicFeeTxType, types.AuthTxType:
 		al := tx.AccessList()
 		result.Accesses = &al
 		result.ChainID = (*hexutil.Big)(tx.ChainId())
+		if tx.Type() == types.AuthTxType {
+			proof := hexutil.Bytes(tx.AuthProof())
+			result.AuthProof = &proof
+		}
 		result.GasFeeCap = (*hexutil.Big)(tx.GasFeeCap())
 		result.GasTipCap = (*hexutil.Big)(tx.GasTipCap())
 		// if the transaction has been mined, compute the effective gas price
@@ -1382,9 +1450,16 @@ type accessListResult struct {
 	GasUsed    hexutil.Uint64    `json:"gasUsed"`
 }
 
+// AccessListOptions are the optional settings of the access list creation.
+type AccessListOptions struct {
+	// SystemCalls also includes the storage slots of the validator contract
+	// touched by the clique system calls issued when finalizing blocks.
+	SystemCalls bool `json:"systemCalls"`
+}
+
 // CreateAccessList creates a EIP-2930 type AccessList for the given transaction.
 // Reexec and BlockNrOrHash can be specified to create the accessList on top of a certain state.
-func (s *BlockChainAPI) CreateAccessList(ctx context.Context, args TransactionArgs, blockNrOrHash *rpc.BlockNumberOrHash) (*accessListResult, error) {
+func (s *BlockChainAPI) CreateAccessList(ctx context.Context, args TransactionArgs, blockNrOrHash *rpc.BlockNumberOrHash, options *AccessListOptions) (*accessListResult, error) {
 	bNrOrHash := rpc.BlockNumberOrHashWithNumber(rpc.PendingBlockNumber)
 	if blockNrOrHash != nil {
 		bNrOrHash = *blockNrOrHash
@@ -1393,6 +1468,13 @@ func (s *BlockChainAPI) CreateAccessList(ctx context.Context, args TransactionAr
 	if err != nil {
 		return nil, err
 	}
+	if options != nil && options.SystemCalls {
+		sysAcl, err := SystemCallAccessList(ctx, s.b, bNrOrHash, args.from())
+		if err != nil {
+			return nil, err
+		}
+		acl = mergeAccessLists(acl, sysAcl)
+	}
 	result := &accessListResult{Accesslist: &acl, GasUsed: hexutil.Uint64(gasUsed)}
 	if vmerr != nil {
 		result.Error = vmerr.Error()
@@ -1466,6 +1548,79 @@ func AccessList(ctx context.Context, b Backend, blockNrOrHash rpc.BlockNumberOrH
 	}
 }
 
+// SystemCallAccessList creates an access list of the validator contract slots
+// touched by the clique commitAccum system call. The accounts committed by the
+// call depend on the signing activity of the validators, so the call is
+// simulated for the given account, usually the sender of a transaction
+// interacting with the staking contract. An empty list is returned if the
+// system calls are not active.
+func SystemCallAccessList(ctx context.Context, b Backend, blockNrOrHash rpc.BlockNumberOrHash, account common.Address) (types.AccessList, error) {
+	config := b.ChainConfig()
+	if config.Clique == nil || config.Clique.ValidatorContract == "" {
+		return nil, nil
+	}
+	db, header, err := b.StateAndHeaderByNumberOrHash(ctx, blockNrOrHash)
+	if db == nil || err != nil {
+		return nil, err
+	}
+	if !config.IsPoa2Pos(new(big.Int).Add(header.Number, common.Big1)) {
+		return nil, nil
+	}
+	data, err := cliquecontract.Staking().Pack("commitAccum", []common.Address{account})
+	if err != nil {
+		return nil, err
+	}
+	var (
+		contract    = common.HexToAddress(config.Clique.ValidatorContract)
+		msg         = types.NewMessage(params.SystemAddress, &contract, 0, new(big.Int), math.MaxUint64/2, new(big.Int), new(big.Int), new(big.Int), data, nil, true)
+		precompiles = vm.ActivePrecompiles(config.Rules(header.Number, header.Difficulty.Sign() == 0))
+		tracer      = logger.NewAccessListTracer(nil, msg.From(), contract, precompiles)
+	)
+	vmenv, _, err := b.GetEVM(ctx, msg, db.Copy(), header, &vm.Config{Tracer: tracer, Debug: true, NoBaseFee: true})
+	if err != nil {
+		return nil, err
+	}
+	// A reverting system call leaves the block valid, only the slots touched
+	// until the failure are of interest.
+	vmenv.Call(vm.AccountRef(msg.From()), contract, data, msg.Gas(), msg.Value())
+
+	// The tracer collects the slots into maps, sort them for a stable output
+	acl := tracer.AccessList()
+	sort.Slice(acl, func(i, j int) bool { return bytes.Compare(acl[i].Address[:], acl[j].Address[:]) < 0 })
+	for _, tuple := range acl {
+		keys := tuple.StorageKeys
+		sort.Slice(keys, func(i, j int) bool { return bytes.Compare(keys[i][:], keys[j][:]) < 0 })
+	}
+	return acl, nil
+}
+
+// mergeAccessLists adds the accounts and storage slots of the extra access list
+// missing from the base one.
+func mergeAccessLists(base, extra types.AccessList) types.AccessList {
+	index := make(map[common.Address]int)
+	for i, tuple := range base {
+		index[tuple.Address] = i
+	}
+	for _, tuple := range extra {
+		i, ok := index[tuple.Address]
+		if !ok {
+			index[tuple.Address] = len(base)
+			base = append(base, types.AccessTuple{Address: tuple.Address, StorageKeys: []common.Hash{}})
+			i = len(base) - 1
+		}
+		known := make(map[common.Hash]struct{})
+		for _, slot := range base[i].StorageKeys {
+			known[slot] = struct{}{}
+		}
+		for _, slot := range tuple.StorageKeys {
+			if _, ok := known[slot]; !ok {
+				base[i].StorageKeys = append(base[i].StorageKeys, slot)
+			}
+		}
+	}
+	return base
+}
+
 // TransactionAPI exposes methods for reading and creating transaction data.
 type TransactionAPI struct {
 	b         Backend
@@ -1610,13 +1765,54 @@ func (s *TransactionAPI) GetTransactionReceipt(ctx context.Context, hash common.
 	// Derive the sender.
 	bigblock := new(big.Int).SetUint64(blockNumber)
 	signer := types.MakeSigner(s.b.ChainConfig(), bigblock)
+
+	var baseFee *big.Int
+	if s.b.ChainConfig().IsLondon(bigblock) {
+		header, err := s.b.HeaderByHash(ctx, blockHash)
+		if err != nil {
+			return nil, err
+		}
+		baseFee = header.BaseFee
+	}
+	return marshalReceipt(receipt, blockHash, blockNumber, signer, tx, int(index), baseFee), nil
+}
+
+// GetTransactionReceiptsByBlock returns the receipts of all the transactions of
+// the given block, in the order of the transactions.
+func (s *TransactionAPI) GetTransactionReceiptsByBlock(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) ([]map[string]interface{}, error) {
+	block, err := s.b.BlockByNumberOrHash(ctx, blockNrOrHash)
+	if block == nil || err != nil {
+		// When the block doesn't exist, the RPC method should return JSON null
+		// as per specification.
+		return nil, err
+	}
+	receipts, err := s.b.GetReceipts(ctx, block.Hash())
+	if err != nil {
+		return nil, err
+	}
+	txs := block.Transactions()
+	if len(receipts) != len(txs) {
+		return nil, fmt.Errorf("receipts length mismatch: %d vs %d", len(receipts), len(txs))
+	}
+	signer := types.MakeSigner(s.b.ChainConfig(), block.Number())
+
+	result := make([]map[string]interface{}, len(receipts))
+	for i, receipt := range receipts {
+		result[i] = marshalReceipt(receipt, block.Hash(), block.NumberU64(), signer, txs[i], i, block.BaseFee())
+	}
+	return result, nil
+}
+
+// marshalReceipt converts the receipt of the given transaction into the RPC
+// representation. The base fee is nil for blocks before London.
+func marshalReceipt(receipt *types.Receipt, blockHash common.Hash, blockNumber uint64, signer types.Signer, tx *types.Transaction, txIndex int, baseFee *big.Int) map[string]interface{} {
 	from, _ := types.Sender(signer, tx)
 
 	fields := map[string]interface{}{
 		"blockHash":         blockHash,
 		"blockNumber":       hexutil.Uint64(blockNumber),
-		"transactionHash":   hash,
-		"transactionIndex":  hexutil.Uint64(index),
+		"transactionHash":   tx.Hash(),
+		"transactionIndex":  hexutil.Uint64(txIndex),
 		"from":              from,
 		"to":                tx.To(),
 		"gasUsed":           hexutil.Uint64(receipt.GasUsed),
@@ -1627,14 +1823,10 @@ func (s *TransactionAPI) GetTransactionReceipt(ctx context.Context, hash common.
 		"type":              hexutil.Uint(tx.Type()),
 	}
 	// Assign the effective gas price paid
-	if !s.b.ChainConfig().IsLondon(bigblock) {
+	if baseFee == nil {
 		fields["effectiveGasPrice"] = hexutil.Uint64(tx.GasPrice().Uint64())
 	} else {
-		header, err := s.b.HeaderByHash(ctx, blockHash)
-		if err != nil {
-			return nil, err
-		}
-		gasPrice := new(big.Int).Add(header.BaseFee, tx.EffectiveGasTipValue(header.BaseFee))
+		gasPrice := new(big.Int).Add(baseFee, tx.EffectiveGasTipValue(baseFee))
 		fields["effectiveGasPrice"] = hexutil.Uint64(gasPrice.Uint64())
 	}
 	// Assign receipt status or post state.
@@ -1650,7 +1842,7 @@ func (s *TransactionAPI) GetTransactionReceipt(ctx context.Context, hash common.
 	if receipt.ContractAddress != (common.Address{}) {
 		fields["contractAddress"] = receipt.ContractAddress
 	}
-	return fields, nil
+	return fields
 }
 
 // sign is a helper function that signs a transaction with the private key of the given address.
@@ -1678,6 +1870,9 @@ func SubmitTransaction(ctx context.Context, b Backend, tx *types.Transaction) (c
 		return common.Hash{}, errors.New("only replay-protected (EIP-155) transactions allowed over RPC")
 	}
 	if err := b.SendTx(ctx, tx); err != nil {
+		if errors.Is(err, core.ErrDeployNotAuthorized) {
+			return common.Hash{}, &deployNotAuthorizedError{err}
+		}
 		return common.Hash{}, err
 	}
 	// Print a log with full tx details for manual investigations and interventions
@@ -1789,13 +1984,15 @@ type SignTransactionResult struct {
 // SignTransaction will sign the given transaction with the from account.
 // The node needs to have the private key of the account corresponding with
 // the given from address and it needs to be unlocked.
+//
+// Legacy, access list, dynamic fee and authenticated (carrying an authProof)
+// transactions are supported. The chain id and the missing fee fields are
+// filled from the local chain and its gas price oracle, the transaction is
+// returned both RLP encoded and decoded for offline workflows.
 func (s *TransactionAPI) SignTransaction(ctx context.Context, args TransactionArgs) (*SignTransactionResult, error) {
 	if args.Gas == nil {
 		return nil, fmt.Errorf("gas not specified")
 	}
-	if args.GasPrice == nil && (args.MaxPriorityFeePerGas == nil || args.MaxFeePerGas == nil) {
-		return nil, fmt.Errorf("missing gasPrice or maxFeePerGas/maxPriorityFeePerGas")
-	}
 	if args.Nonce == nil {
 		return nil, fmt.Errorf("nonce not specified")
 	}
@@ -1923,6 +2120,31 @@ func (api *DebugAPI) GetBlockRlp(ctx context.Context, number uint64) (hexutil.By
 	return rlp.EncodeToBytes(block)
 }
 
+// GetRawHeader retrieves the RLP encoding of a single header, as used for the
+// hash of the block.
+func (api *DebugAPI) GetRawHeader(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (hexutil.Bytes, error) {
+	header, err := api.b.HeaderByNumberOrHash(ctx, blockNrOrHash)
+	if err != nil {
+		return nil, err
+	}
+	if header == nil {
+		return nil, fmt.Errorf("header %s not found", blockNrOrHash.String())
+	}
+	return rlp.EncodeToBytes(header)
+}
+
+// GetRawBlock retrieves the RLP encoding of a single block.
+func (api *DebugAPI) GetRawBlock(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (hexutil.Bytes, error) {
+	block, err := api.b.BlockByNumberOrHash(ctx, blockNrOrHash)
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, fmt.Errorf("block %s not found", blockNrOrHash.String())
+	}
+	return rlp.EncodeToBytes(block)
+}
+
 // GetRawReceipts retrieves the binary-encoded raw receipts of a single block.
 func (api *DebugAPI) GetRawReceipts(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) ([]hexutil.Bytes, error) {
 	var hash common.Hash
@@ -1933,6 +2155,9 @@ func (api *DebugAPI) GetRawReceipts(ctx context.Context, blockNrOrHash rpc.Block
 		if err != nil {
 			return nil, err
 		}
+		if block == nil {
+			return nil, fmt.Errorf("block %s not found", blockNrOrHash.String())
+		}
 		hash = block.Hash()
 	}
 	receipts, err := api.b.GetReceipts(ctx, hash)
@@ -0,0 +1,562 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/qydata/go-ctereum/accounts/abi/bind"
+	"github.com/qydata/go-ctereum/common"
+	"github.com/qydata/go-ctereum/common/hexutil"
+	"github.com/qydata/go-ctereum/contracts/authcontroller"
+	"github.com/qydata/go-ctereum/contracts/authcontroller/contract"
+	"github.com/qydata/go-ctereum/core"
+	"github.com/qydata/go-ctereum/core/types"
+	"github.com/qydata/go-ctereum/core/vm"
+	"github.com/qydata/go-ctereum/log"
+	"github.com/qydata/go-ctereum/rpc"
+)
+
+// maxAuthBatchSize is the maximum number of addresses looked up by a single
+// batch query.
+const maxAuthBatchSize = 1000
+
+// errAuthNotConfigured is returned if the chain has no auth contract.
+var errAuthNotConfigured = errors.New("auth contract not configured")
+
+// deployNotAuthorizedError is returned when submitting a contract creation from
+// a sender not allowed to deploy, carrying the EIP-1474 "transaction rejected"
+// error code so that clients can tell it apart from other submission failures.
+type deployNotAuthorizedError struct {
+	error
+}
+
+// ErrorCode returns the JSON error code of a rejected deployment.
+func (e *deployNotAuthorizedError) ErrorCode() int {
+	return -32003
+}
+
+// AuthAPI provides access to the AuthController keeping the real-name
+// authentication of accounts, without the caller having to know its ABI. All
+// methods accept an optional controller label selecting one of the per-dApp
+// auth contracts of the registry instead of the one of the chain config.
+type AuthAPI struct {
+	b         Backend
+	nonceLock *AddrLocker
+}
+
+// NewAuthAPI creates a new auth API.
+func NewAuthAPI(b Backend, nonceLock *AddrLocker) *AuthAPI {
+	return &AuthAPI{b, nonceLock}
+}
+
+// AuthResult is the authentication status of an account.
+type AuthResult struct {
+	Address  common.Address `json:"address"`
+	Contract common.Address `json:"contract"`
+	IsAuth   bool           `json:"isAuth"`
+}
+
+// AuthDataArgs is the authentication record submitted to the AuthController.
+// Fields unknown to the v1 contract are ignored before the contract fix.
+type AuthDataArgs struct {
+	Caddress   common.Address `json:"caddress"`
+	Sender     common.Address `json:"sender"`
+	Signature  hexutil.Bytes  `json:"signature"`
+	AuthTime   *hexutil.Big   `json:"authTime"`
+	AuthExpiry *hexutil.Big   `json:"authExpiry"`
+	IsAuth     bool           `json:"isAuth"`
+	AuthLevel  *hexutil.Big   `json:"authLevel"`
+	ExpandData string         `json:"expandData"`
+}
+
+// GetAuth returns the authentication status of the address at the given block,
+// or the latest block if none is given.
+func (s *AuthAPI) GetAuth(ctx context.Context, address common.Address, blockNrOrHash *rpc.BlockNumberOrHash, controller *string) (*AuthResult, error) {
+	var isAuth bool
+	contractAddr, err := s.call(ctx, blockNrOrHash, controller, &isAuth, "authsSingle", address)
+	if err != nil {
+		return nil, err
+	}
+	return &AuthResult{Address: address, Contract: contractAddr, IsAuth: isAuth}, nil
+}
+
+// GetAuthBatch returns the authentication status of every address at the given
+// block, or the latest block if none is given, looking all of them up in a
+// single pass over the state.
+func (s *AuthAPI) GetAuthBatch(ctx context.Context, addresses []common.Address, blockNrOrHash *rpc.BlockNumberOrHash, controller *string) ([]*AuthResult, error) {
+	if len(addresses) > maxAuthBatchSize {
+		return nil, fmt.Errorf("too many addresses: %d > %d", len(addresses), maxAuthBatchSize)
+	}
+	if blockNrOrHash == nil {
+		latest := rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+		blockNrOrHash = &latest
+	}
+	state, header, err := s.b.StateAndHeaderByNumberOrHash(ctx, *blockNrOrHash)
+	if state == nil || err != nil {
+		return nil, err
+	}
+	contractAddr, err := s.controllerAt(controller, header.Number)
+	if err != nil {
+		return nil, err
+	}
+	msg, err := new(TransactionArgs).ToMessage(s.b.RPCGasCap(), header.BaseFee)
+	if err != nil {
+		return nil, err
+	}
+	evm, _, err := s.b.GetEVM(ctx, msg, state, header, &vm.Config{NoBaseFee: true})
+	if err != nil {
+		return nil, err
+	}
+	auths, err := authcontroller.AuthsMulti(evm, contractAddr, addresses)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]*AuthResult, len(addresses))
+	for i, addr := range addresses {
+		results[i] = &AuthResult{Address: addr, Contract: contractAddr, IsAuth: auths[i]}
+	}
+	return results, nil
+}
+
+// IsWhitelisted returns whether the address is allowed to submit
+// authentications at the given block, or the latest block if none is given.
+func (s *AuthAPI) IsWhitelisted(ctx context.Context, address common.Address, blockNrOrHash *rpc.BlockNumberOrHash, controller *string) (bool, error) {
+	var whitelisted bool
+	_, err := s.call(ctx, blockNrOrHash, controller, &whitelisted, "whitelisted", address)
+	return whitelisted, err
+}
+
+// GetWhitelist returns the addresses allowed to submit authentications at the
+// given block, or the latest block if none is given.
+func (s *AuthAPI) GetWhitelist(ctx context.Context, blockNrOrHash *rpc.BlockNumberOrHash, controller *string) ([]common.Address, error) {
+	var whitelist []common.Address
+	_, err := s.call(ctx, blockNrOrHash, controller, &whitelist, "getWhitelist")
+	return whitelist, err
+}
+
+// AuthProofResult is the Merkle proof of the authentication status and the
+// whitelist flag of an address, as stored in the auth contract at a block.
+type AuthProofResult struct {
+	Address       common.Address `json:"address"`
+	BlockHash     common.Hash    `json:"blockHash"`
+	BlockNumber   hexutil.Uint64 `json:"blockNumber"`
+	StateRoot     common.Hash    `json:"stateRoot"`
+	AuthSlot      common.Hash    `json:"authSlot"`
+	WhitelistSlot common.Hash    `json:"whitelistSlot"`
+	Proof         *AccountResult `json:"proof"`
+}
+
+// AuthProof returns the eth_getProof style proof of the auth contract account
+// and of the storage slots holding the auth status and whitelist flag of the
+// address, at the given block or the latest one if none is given. The proof
+// can be verified against the state root of the block without trusting the
+// node. The slots proven are first checked against the getters of the contract
+// at that block, refusing to prove the slots of a contract laid out otherwise.
+func (s *AuthAPI) AuthProof(ctx context.Context, address common.Address, blockNrOrHash *rpc.BlockNumberOrHash, controller *string) (*AuthProofResult, error) {
+	if blockNrOrHash == nil {
+		latest := rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+		blockNrOrHash = &latest
+	}
+	state, header, err := s.b.StateAndHeaderByNumberOrHash(ctx, *blockNrOrHash)
+	if state == nil || err != nil {
+		return nil, err
+	}
+	contractAddr, err := s.controllerAt(controller, header.Number)
+	if err != nil {
+		return nil, err
+	}
+	msg, err := new(TransactionArgs).ToMessage(s.b.RPCGasCap(), header.BaseFee)
+	if err != nil {
+		return nil, err
+	}
+	evm, _, err := s.b.GetEVM(ctx, msg, state, header, &vm.Config{NoBaseFee: true})
+	if err != nil {
+		return nil, err
+	}
+	if err := authcontroller.CheckLayout(evm, contractAddr, address); err != nil {
+		return nil, err
+	}
+	var (
+		authSlot      = authcontroller.AuthSlot(address)
+		whitelistSlot = authcontroller.WhitelistedSlot(address)
+	)
+	proof, err := NewBlockChainAPI(s.b).GetProof(ctx, contractAddr, []string{authSlot.Hex(), whitelistSlot.Hex()}, rpc.BlockNumberOrHashWithHash(header.Hash(), false))
+	if err != nil {
+		return nil, err
+	}
+	return &AuthProofResult{
+		Address:       address,
+		BlockHash:     header.Hash(),
+		BlockNumber:   hexutil.Uint64(header.Number.Uint64()),
+		StateRoot:     header.Root,
+		AuthSlot:      authSlot,
+		WhitelistSlot: whitelistSlot,
+		Proof:         proof,
+	}, nil
+}
+
+// WhitelistChange is the notification payload of a whitelist subscription,
+// reporting an address being added to or removed from the whitelist. Changes
+// rolled back by a reorg are sent again with the removed flag set.
+type WhitelistChange struct {
+	Address     common.Address `json:"address"`
+	Added       bool           `json:"added"`
+	Contract    common.Address `json:"contract"`
+	BlockNumber hexutil.Uint64 `json:"blockNumber"`
+	BlockHash   common.Hash    `json:"blockHash"`
+	TxHash      common.Hash    `json:"transactionHash"`
+	Removed     bool           `json:"removed"`
+}
+
+// Whitelist sends a notification each time an address is added to or removed
+// from the whitelist. If a starting block is given, the changes made since are
+// replayed before the live ones.
+func (s *AuthAPI) Whitelist(ctx context.Context, fromBlock *rpc.BlockNumber, controller *string) (*rpc.Subscription, error) {
+	// Resolve the contract upfront, so that unknown labels fail the subscription
+	if _, err := s.controllerAt(controller, s.b.CurrentHeader().Number); err != nil {
+		return nil, err
+	}
+	contractAt := func(number *big.Int) common.Address {
+		contractAddr, _ := s.controllerAt(controller, number)
+		return contractAddr
+	}
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	filterer, err := contract.NewAuthControllerFilterer(common.Address{}, nil)
+	if err != nil {
+		return nil, err
+	}
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		notify := func(logs []*types.Log) {
+			for _, log := range logs {
+				if change := whitelistChange(filterer, contractAt, log); change != nil {
+					notifier.Notify(rpcSub.ID, change)
+				}
+			}
+		}
+		// Replay the past changes, then catch up with the blocks imported
+		// while replaying once subscribed to the live ones.
+		var replayed uint64
+		if fromBlock != nil {
+			from := s.b.CurrentHeader().Number.Uint64()
+			if *fromBlock >= 0 && uint64(*fromBlock) < from {
+				from = uint64(*fromBlock)
+			}
+			if !s.replayWhitelist(from, contractAt, notify, rpcSub, notifier) {
+				return
+			}
+			replayed = s.b.CurrentHeader().Number.Uint64()
+		}
+		var (
+			logs       = make(chan []*types.Log)
+			removed    = make(chan core.RemovedLogsEvent)
+			logsSub    = s.b.SubscribeLogsEvent(logs)
+			removedSub = s.b.SubscribeRemovedLogsEvent(removed)
+		)
+		defer logsSub.Unsubscribe()
+		defer removedSub.Unsubscribe()
+
+		if fromBlock != nil {
+			head := s.b.CurrentHeader().Number.Uint64()
+			if head > replayed && !s.replayWhitelist(replayed+1, contractAt, notify, rpcSub, notifier) {
+				return
+			}
+			replayed = head
+		}
+		for {
+			select {
+			case ls := <-logs:
+				if len(ls) > 0 && ls[0].BlockNumber <= replayed {
+					continue
+				}
+				notify(ls)
+			case ev := <-removed:
+				notify(ev.Logs)
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
+
+// replayWhitelist notifies the whitelist changes made from the given block up
+// to the current head. It returns false if the subscription ended meanwhile.
+func (s *AuthAPI) replayWhitelist(from uint64, contractAt func(*big.Int) common.Address, notify func([]*types.Log), rpcSub *rpc.Subscription, notifier *rpc.Notifier) bool {
+	head := s.b.CurrentHeader().Number.Uint64()
+	for number := from; number <= head; number++ {
+		select {
+		case <-rpcSub.Err():
+			return false
+		case <-notifier.Closed():
+			return false
+		default:
+		}
+		header, err := s.b.HeaderByNumber(context.Background(), rpc.BlockNumber(number))
+		if header == nil || err != nil {
+			log.Warn("Failed to replay whitelist changes", "number", number, "err", err)
+			return true
+		}
+		if !types.BloomLookup(header.Bloom, contractAt(header.Number)) {
+			continue
+		}
+		logs, err := s.b.GetLogs(context.Background(), header.Hash(), number)
+		if err != nil {
+			log.Warn("Failed to replay whitelist changes", "number", number, "err", err)
+			return true
+		}
+		for _, txLogs := range logs {
+			notify(txLogs)
+		}
+	}
+	return true
+}
+
+// whitelistChange decodes a log of the auth contract live at the block of the
+// log into a whitelist change, returning nil for any other log.
+func whitelistChange(filterer *contract.AuthControllerFilterer, contractAt func(*big.Int) common.Address, l *types.Log) *WhitelistChange {
+	if len(l.Topics) == 0 || l.Address != contractAt(new(big.Int).SetUint64(l.BlockNumber)) {
+		return nil
+	}
+	change := &WhitelistChange{
+		Contract:    l.Address,
+		BlockNumber: hexutil.Uint64(l.BlockNumber),
+		BlockHash:   l.BlockHash,
+		TxHash:      l.TxHash,
+		Removed:     l.Removed,
+	}
+	// Both contract revisions share the whitelist events
+	parsed, err := contract.AuthControllerMetaData.GetAbi()
+	if err != nil {
+		return nil
+	}
+	switch l.Topics[0] {
+	case parsed.Events["AddedToWhiteList"].ID:
+		event, err := filterer.ParseAddedToWhiteList(*l)
+		if err != nil {
+			return nil
+		}
+		change.Address, change.Added = event.Arg0, true
+	case parsed.Events["RemovedFromWhiteList"].ID:
+		event, err := filterer.ParseRemovedFromWhiteList(*l)
+		if err != nil {
+			return nil
+		}
+		change.Address = event.Arg0
+	default:
+		return nil
+	}
+	return change
+}
+
+// AuthRevision returns the ABI revision of the auth contract live at the latest
+// block, which the authentications submitted to it are packed with.
+func (s *AuthAPI) AuthRevision(controller *string) (authcontroller.Version, error) {
+	head := s.b.CurrentHeader().Number
+	if _, err := s.controllerAt(controller, head); err != nil {
+		return 0, err
+	}
+	return s.revisionAt(controller, head), nil
+}
+
+// SubmitAuth signs an authentication transaction from the record's sender with
+// the node's accounts and submits it to the transaction pool. The per-dApp auth
+// contracts of the registry are always submitted to with the v2 ABI.
+func (s *AuthAPI) SubmitAuth(ctx context.Context, auth AuthDataArgs, orderId hexutil.Big, controller *string) (common.Hash, error) {
+	head := s.b.CurrentHeader().Number
+	contractAddr, err := s.controllerAt(controller, head)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	var data []byte
+	if s.revisionAt(controller, head) == authcontroller.V2 {
+		data, err = packAuthentication(contract.AuthControllerMetaData, contract.AuthControllerAuthData{
+			Caddress:   auth.Caddress,
+			Sender:     auth.Sender,
+			Signature:  auth.Signature,
+			AuthTime:   (*big.Int)(auth.AuthTime),
+			AuthExpiry: (*big.Int)(auth.AuthExpiry),
+			IsAuth:     auth.IsAuth,
+			AuthLevel:  (*big.Int)(auth.AuthLevel),
+			ExpandData: auth.ExpandData,
+		}, (*big.Int)(&orderId))
+	} else {
+		data, err = packAuthentication(contract.AuthControllerV1MetaData, contract.AuthControllerV1AuthData{
+			Caddress:  auth.Caddress,
+			Sender:    auth.Sender,
+			Signature: auth.Signature,
+			IsAuth:    auth.IsAuth,
+		}, (*big.Int)(&orderId))
+	}
+	if err != nil {
+		return common.Hash{}, err
+	}
+	input := hexutil.Bytes(data)
+	args := TransactionArgs{
+		From:  &auth.Sender,
+		To:    &contractAddr,
+		Input: &input,
+	}
+	return NewTransactionAPI(s.b, s.nonceLock).SendTransaction(ctx, args)
+}
+
+// TxPoolAuthBucket counts the senders of the transaction pool sharing an
+// authentication status, along with their transactions.
+type TxPoolAuthBucket struct {
+	Senders hexutil.Uint `json:"senders"`
+	Pending hexutil.Uint `json:"pending"`
+	Queued  hexutil.Uint `json:"queued"`
+}
+
+// TxPoolAuthSummary is the content of the transaction pool bucketed by the
+// authentication status of the senders.
+type TxPoolAuthSummary struct {
+	BlockNumber     hexutil.Uint64    `json:"blockNumber"`
+	Enforced        bool              `json:"enforced"`
+	Exempt          *TxPoolAuthBucket `json:"exempt"`
+	Authenticated   *TxPoolAuthBucket `json:"authenticated"`
+	Unauthenticated *TxPoolAuthBucket `json:"unauthenticated"`
+}
+
+// AuthAdminAPI provides the operators of the node with the authentication
+// status of the content of their transaction pool.
+type AuthAdminAPI struct {
+	b Backend
+}
+
+// NewAuthAdminAPI creates a new auth admin API.
+func NewAuthAdminAPI(b Backend) *AuthAdminAPI {
+	return &AuthAdminAPI{b}
+}
+
+// TxpoolAuthSummary buckets the pending and queued transactions of the pool by
+// whether their sender is authenticated as of the latest block, showing how much
+// unauthenticated traffic would be dropped once authentication is enforced.
+func (s *AuthAdminAPI) TxpoolAuthSummary() (*TxPoolAuthSummary, error) {
+	summary, err := s.b.TxPoolAuthSummary()
+	if err != nil {
+		return nil, err
+	}
+	bucket := func(b core.TxPoolAuthBucket) *TxPoolAuthBucket {
+		return &TxPoolAuthBucket{
+			Senders: hexutil.Uint(b.Senders),
+			Pending: hexutil.Uint(b.Pending),
+			Queued:  hexutil.Uint(b.Queued),
+		}
+	}
+	return &TxPoolAuthSummary{
+		BlockNumber:     hexutil.Uint64(summary.Number),
+		Enforced:        summary.Enforced,
+		Exempt:          bucket(summary.Exempt),
+		Authenticated:   bucket(summary.Authenticated),
+		Unauthenticated: bucket(summary.Unauthenticated),
+	}, nil
+}
+
+// controllerAt returns the auth contract with the given label in the registry,
+// or the one of the chain config live at the given block if no label is given.
+func (s *AuthAPI) controllerAt(controller *string, number *big.Int) (common.Address, error) {
+	if controller != nil && *controller != "" {
+		return s.b.AuthRegistry().Lookup(*controller)
+	}
+	config := s.b.ChainConfig()
+	if config.AuthBlock == nil {
+		return common.Address{}, errAuthNotConfigured
+	}
+	return config.AuthContractAt(number), nil
+}
+
+// revisionAt returns the ABI revision of the auth contract live at the given
+// block. The per-dApp auth contracts of the registry are always v2 contracts.
+func (s *AuthAPI) revisionAt(controller *string, number *big.Int) authcontroller.Version {
+	if controller != nil && *controller != "" || s.b.ChainConfig().IsFix(number) {
+		return authcontroller.V2
+	}
+	return authcontroller.V1
+}
+
+// call executes a read-only method of the auth contract live at the given block
+// and unpacks the single return value into out. It returns the address of the
+// contract queried.
+func (s *AuthAPI) call(ctx context.Context, blockNrOrHash *rpc.BlockNumberOrHash, controller *string, out interface{}, method string, params ...interface{}) (common.Address, error) {
+	if blockNrOrHash == nil {
+		latest := rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+		blockNrOrHash = &latest
+	}
+	header, err := s.b.HeaderByNumberOrHash(ctx, *blockNrOrHash)
+	if err != nil {
+		return common.Address{}, err
+	}
+	if header == nil {
+		return common.Address{}, errors.New("header not found")
+	}
+	contractAddr, err := s.controllerAt(controller, header.Number)
+	if err != nil {
+		return common.Address{}, err
+	}
+	// Both contract revisions share the ABI of the read-only methods
+	parsed, err := contract.AuthControllerMetaData.GetAbi()
+	if err != nil {
+		return common.Address{}, err
+	}
+	data, err := parsed.Pack(method, params...)
+	if err != nil {
+		return common.Address{}, err
+	}
+	input := hexutil.Bytes(data)
+	args := TransactionArgs{To: &contractAddr, Input: &input}
+
+	result, err := DoCall(ctx, s.b, args, rpc.BlockNumberOrHashWithHash(header.Hash(), false), nil, s.b.RPCEVMTimeout(), s.b.RPCGasCap())
+	if err != nil {
+		return common.Address{}, err
+	}
+	if len(result.Revert()) > 0 {
+		return common.Address{}, newRevertError(result)
+	}
+	if result.Err != nil {
+		return common.Address{}, result.Err
+	}
+	res, err := parsed.Unpack(method, result.Return())
+	if err != nil {
+		return common.Address{}, err
+	}
+	if err := parsed.Methods[method].Outputs.Copy(out, res); err != nil {
+		return common.Address{}, err
+	}
+	return contractAddr, nil
+}
+
+// packAuthentication packs an authentication call with the ABI of the given
+// contract revision.
+func packAuthentication(meta *bind.MetaData, auth interface{}, orderId *big.Int) ([]byte, error) {
+	parsed, err := meta.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	return parsed.Pack("authentication", auth, orderId)
+}
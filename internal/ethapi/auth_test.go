@@ -0,0 +1,244 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethapi
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/qydata/go-ctereum/common"
+	"github.com/qydata/go-ctereum/contracts/authcontroller"
+	"github.com/qydata/go-ctereum/contracts/authcontroller/contract"
+	"github.com/qydata/go-ctereum/core"
+	"github.com/qydata/go-ctereum/core/types"
+	"github.com/qydata/go-ctereum/event"
+	"github.com/qydata/go-ctereum/rpc"
+)
+
+var testAuthContract = common.HexToAddress("0xa0")
+
+// whitelistBackend serves a fixed chain of headers and logs to the whitelist
+// subscription.
+type whitelistBackend struct {
+	*backendMock
+	headers     []*types.Header
+	logs        map[uint64][]*types.Log
+	registry    authcontroller.Registry
+	logsFeed    event.Feed
+	removedFeed event.Feed
+}
+
+func newWhitelistBackend(logs map[uint64][]*types.Log, head uint64) *whitelistBackend {
+	b := &whitelistBackend{backendMock: newBackendMock(), logs: logs}
+	config := *b.config
+	config.AuthBlock = big.NewInt(0)
+	config.AuthContract = testAuthContract
+	b.config = &config
+
+	for number := uint64(0); number <= head; number++ {
+		header := &types.Header{Number: new(big.Int).SetUint64(number)}
+		for _, l := range logs[number] {
+			header.Bloom.Add(l.Address.Bytes())
+		}
+		b.headers = append(b.headers, header)
+	}
+	return b
+}
+
+func (b *whitelistBackend) AuthRegistry() authcontroller.Registry {
+	return b.registry
+}
+
+func (b *whitelistBackend) CurrentHeader() *types.Header {
+	return b.headers[len(b.headers)-1]
+}
+
+func (b *whitelistBackend) HeaderByNumber(ctx context.Context, number rpc.BlockNumber) (*types.Header, error) {
+	if int(number) >= len(b.headers) {
+		return nil, nil
+	}
+	return b.headers[number], nil
+}
+
+func (b *whitelistBackend) GetLogs(ctx context.Context, blockHash common.Hash, number uint64) ([][]*types.Log, error) {
+	return [][]*types.Log{b.logs[number]}, nil
+}
+
+func (b *whitelistBackend) SubscribeLogsEvent(ch chan<- []*types.Log) event.Subscription {
+	return b.logsFeed.Subscribe(ch)
+}
+
+func (b *whitelistBackend) SubscribeRemovedLogsEvent(ch chan<- core.RemovedLogsEvent) event.Subscription {
+	return b.removedFeed.Subscribe(ch)
+}
+
+// whitelistLog creates a whitelist event log of the given contract.
+func whitelistLog(t *testing.T, contractAddr common.Address, name string, addr common.Address, number uint64) *types.Log {
+	parsed, err := contract.AuthControllerMetaData.GetAbi()
+	if err != nil {
+		t.Fatalf("failed to parse abi: %v", err)
+	}
+	return &types.Log{
+		Address:     contractAddr,
+		Topics:      []common.Hash{parsed.Events[name].ID},
+		Data:        common.LeftPadBytes(addr.Bytes(), 32),
+		BlockNumber: number,
+	}
+}
+
+func checkWhitelistChange(t *testing.T, ch chan *WhitelistChange, contractAddr, addr common.Address, added, removed bool, number uint64) {
+	t.Helper()
+	select {
+	case change := <-ch:
+		if change.Address != addr || change.Added != added || change.Removed != removed || uint64(change.BlockNumber) != number || change.Contract != contractAddr {
+			t.Errorf("change mismatch: have %+v, want address %x, added %v, removed %v, block %d", change, addr, added, removed, number)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("timed out waiting for change of %x", addr)
+	}
+}
+
+// sendLive delivers the logs once the subscription is listening to the feed.
+func sendLive(t *testing.T, feed *event.Feed, value interface{}) {
+	for i := 0; i < 100; i++ {
+		if feed.Send(value) > 0 {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("no subscriber listening")
+}
+
+func TestWhitelistSubscription(t *testing.T) {
+	var (
+		a, b  = common.HexToAddress("0x01"), common.HexToAddress("0x02")
+		other = common.HexToAddress("0xa1")
+	)
+	backend := newWhitelistBackend(map[uint64][]*types.Log{
+		1: {whitelistLog(t, testAuthContract, "AddedToWhiteList", a, 1)},
+		2: {whitelistLog(t, other, "AddedToWhiteList", b, 2)},
+		3: {whitelistLog(t, testAuthContract, "RemovedFromWhiteList", a, 3)},
+	}, 3)
+
+	server := rpc.NewServer()
+	defer server.Stop()
+	if err := server.RegisterName("ct", NewAuthAPI(backend, nil)); err != nil {
+		t.Fatalf("failed to register api: %v", err)
+	}
+	client := rpc.DialInProc(server)
+	defer client.Close()
+
+	// Replay the changes made since the first block, skipping foreign contracts
+	ch := make(chan *WhitelistChange, 10)
+	sub, err := client.Subscribe(context.Background(), "ct", ch, "whitelist", rpc.BlockNumber(1))
+	if err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+	defer sub.Unsubscribe()
+
+	checkWhitelistChange(t, ch, testAuthContract, a, true, false, 1)
+	checkWhitelistChange(t, ch, testAuthContract, a, false, false, 3)
+
+	// Already replayed blocks are skipped, new and rolled back ones relayed
+	sendLive(t, &backend.logsFeed, []*types.Log{whitelistLog(t, testAuthContract, "RemovedFromWhiteList", a, 3)})
+	sendLive(t, &backend.logsFeed, []*types.Log{whitelistLog(t, testAuthContract, "AddedToWhiteList", b, 4)})
+	checkWhitelistChange(t, ch, testAuthContract, b, true, false, 4)
+
+	removed := whitelistLog(t, testAuthContract, "AddedToWhiteList", b, 4)
+	removed.Removed = true
+	sendLive(t, &backend.removedFeed, core.RemovedLogsEvent{Logs: []*types.Log{removed}})
+	checkWhitelistChange(t, ch, testAuthContract, b, true, true, 4)
+
+	select {
+	case change := <-ch:
+		t.Errorf("unexpected change: %+v", change)
+	default:
+	}
+}
+
+func TestWhitelistSubscriptionController(t *testing.T) {
+	var (
+		a, b = common.HexToAddress("0x01"), common.HexToAddress("0x02")
+		dapp = common.HexToAddress("0xa1")
+	)
+	backend := newWhitelistBackend(map[uint64][]*types.Log{
+		1: {whitelistLog(t, testAuthContract, "AddedToWhiteList", a, 1)},
+		2: {whitelistLog(t, dapp, "AddedToWhiteList", b, 2)},
+	}, 2)
+	backend.registry = authcontroller.Registry{"dapp": dapp}
+
+	server := rpc.NewServer()
+	defer server.Stop()
+	if err := server.RegisterName("ct", NewAuthAPI(backend, nil)); err != nil {
+		t.Fatalf("failed to register api: %v", err)
+	}
+	client := rpc.DialInProc(server)
+	defer client.Close()
+
+	ch := make(chan *WhitelistChange, 10)
+	if _, err := client.Subscribe(context.Background(), "ct", ch, "whitelist", rpc.BlockNumber(1), "unknown"); err == nil {
+		t.Fatalf("subscribed to an unknown controller")
+	}
+	// Only the changes of the labeled contract are relayed
+	sub, err := client.Subscribe(context.Background(), "ct", ch, "whitelist", rpc.BlockNumber(1), "dapp")
+	if err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+	defer sub.Unsubscribe()
+
+	checkWhitelistChange(t, ch, dapp, b, true, false, 2)
+
+	sendLive(t, &backend.logsFeed, []*types.Log{whitelistLog(t, testAuthContract, "RemovedFromWhiteList", a, 3)})
+	sendLive(t, &backend.logsFeed, []*types.Log{whitelistLog(t, dapp, "RemovedFromWhiteList", b, 3)})
+	checkWhitelistChange(t, ch, dapp, b, false, false, 3)
+
+	select {
+	case change := <-ch:
+		t.Errorf("unexpected change: %+v", change)
+	default:
+	}
+}
+
+// Tests that the ABI revision of the auth contract follows the contract fix,
+// the per-dApp contracts of the registry always being v2 contracts.
+func TestAuthRevision(t *testing.T) {
+	backend := newWhitelistBackend(nil, 0)
+	backend.registry = authcontroller.Registry{"dapp": common.HexToAddress("0xa1")}
+	api := NewAuthAPI(backend, nil)
+
+	dapp, unknown := "dapp", "unknown"
+	fix := &types.Header{Number: big.NewInt(5034751)}
+	for i, tt := range []struct {
+		head       *types.Header
+		controller *string
+		want       authcontroller.Version
+	}{
+		{backend.headers[0], nil, authcontroller.V1},
+		{backend.headers[0], &dapp, authcontroller.V2},
+		{fix, nil, authcontroller.V2},
+	} {
+		backend.headers = []*types.Header{tt.head}
+		if have, err := api.AuthRevision(tt.controller); err != nil || have != tt.want {
+			t.Errorf("test %d: revision mismatch: have %d, %v, want %d", i, have, err, tt.want)
+		}
+	}
+	if _, err := api.AuthRevision(&unknown); err == nil {
+		t.Errorf("revision of an unknown controller returned")
+	}
+}
@@ -26,6 +26,7 @@ import (
 	"github.com/qydata/go-ctereum/accounts"
 	"github.com/qydata/go-ctereum/common"
 	"github.com/qydata/go-ctereum/consensus"
+	"github.com/qydata/go-ctereum/contracts/authcontroller"
 	"github.com/qydata/go-ctereum/core"
 	"github.com/qydata/go-ctereum/core/state"
 	"github.com/qydata/go-ctereum/core/types"
@@ -82,10 +83,12 @@ type Backend interface {
 	Stats() (pending int, queued int)
 	TxPoolContent() (map[common.Address]types.Transactions, map[common.Address]types.Transactions)
 	TxPoolContentFrom(addr common.Address) (types.Transactions, types.Transactions)
+	TxPoolAuthSummary() (*core.TxPoolAuthSummary, error)
 	SubscribeNewTxsEvent(chan<- core.NewTxsEvent) event.Subscription
 
 	ChainConfig() *params.ChainConfig
 	Engine() consensus.Engine
+	AuthRegistry() authcontroller.Registry
 
 	// eth/filters needs to be initialized from this backend type, so methods needed by
 	// it must also be included here.
@@ -116,6 +119,18 @@ func GetAPIs(apiBackend Backend) []rpc.API {
 		}, {
 			Namespace: "personal",
 			Service:   NewPersonalAccountAPI(apiBackend, nonceLock),
+		}, {
+			Namespace: "ct",
+			Service:   NewAuthAPI(apiBackend, nonceLock),
+		}, {
+			Namespace: "admin",
+			Service:   NewAuthAdminAPI(apiBackend),
+		}, {
+			Namespace: "ct",
+			Service:   NewGasPriceAPI(apiBackend),
+		}, {
+			Namespace: "ct",
+			Service:   NewAccountsAPI(apiBackend),
 		},
 	}
 }
@@ -0,0 +1,58 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethapi
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/qydata/go-ctereum/common"
+	"github.com/qydata/go-ctereum/core/types"
+	"github.com/qydata/go-ctereum/params"
+	"github.com/qydata/go-ctereum/trie"
+)
+
+// Tests that the fee split is floored over the block total, as the engine
+// credits it, rather than per transaction.
+func TestFeeSplit(t *testing.T) {
+	config := *params.TestChainConfig
+	config.CommunityFund = &params.CommunityFundConfig{
+		Block:      big.NewInt(1),
+		Address:    common.Address{0xfd},
+		Percentage: 50,
+	}
+	var (
+		txs      types.Transactions
+		receipts types.Receipts
+	)
+	for i := 0; i < 2; i++ {
+		txs = append(txs, types.NewTransaction(uint64(i), common.Address{}, new(big.Int), 21001, big.NewInt(1), nil))
+		receipts = append(receipts, &types.Receipt{GasUsed: 21001})
+	}
+	// Each transaction pays an odd tip, but their total splits evenly
+	block := types.NewBlock(&types.Header{Number: big.NewInt(1)}, txs, nil, receipts, trie.NewStackTrie(nil))
+	tips, producer, fund := feeSplit(&config, block, receipts)
+	if tips.Cmp(big.NewInt(42002)) != 0 || producer.Cmp(big.NewInt(21001)) != 0 || fund.Cmp(big.NewInt(21001)) != 0 {
+		t.Errorf("split mismatch: have %v/%v/%v, want 42002/21001/21001", tips, producer, fund)
+	}
+	// Before the fork the producer keeps everything
+	block = types.NewBlock(&types.Header{Number: big.NewInt(0)}, txs, nil, receipts, trie.NewStackTrie(nil))
+	tips, producer, fund = feeSplit(&config, block, receipts)
+	if tips.Cmp(big.NewInt(42002)) != 0 || producer.Cmp(tips) != 0 || fund.Sign() != 0 {
+		t.Errorf("pre-fork split mismatch: have %v/%v/%v, want 42002/42002/0", tips, producer, fund)
+	}
+}
@@ -0,0 +1,172 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/qydata/go-ctereum/common/hexutil"
+	"github.com/qydata/go-ctereum/rpc"
+)
+
+// maxGasPriceStatsEpochs is the maximum number of epochs summarized by a single
+// gas price stats query.
+const maxGasPriceStatsEpochs = 32
+
+// gasPriceStatsPercentiles are the priority fee percentiles reported for every
+// epoch.
+var gasPriceStatsPercentiles = []float64{10, 50, 90}
+
+// errNotClique is returned if the gas price stats are requested on a chain not
+// sealed by clique, which has no epochs.
+var errNotClique = errors.New("gas price stats require a clique chain")
+
+// GasPriceAPI provides a summary of the fees paid on the chain for wallets.
+type GasPriceAPI struct {
+	b Backend
+}
+
+// NewGasPriceAPI creates a new gas price API.
+func NewGasPriceAPI(b Backend) *GasPriceAPI {
+	return &GasPriceAPI{b}
+}
+
+// FeeStats summarizes a fee over a range of blocks.
+type FeeStats struct {
+	Min *hexutil.Big `json:"min"`
+	Avg *hexutil.Big `json:"avg"`
+	Max *hexutil.Big `json:"max"`
+}
+
+// EpochGasStats summarizes the fees paid during a clique epoch. Only the most
+// recent blocks of long epochs are sampled, as bounded by the fee history limits
+// of the node, the sampled range being reported along.
+type EpochGasStats struct {
+	Epoch        hexutil.Uint64 `json:"epoch"`
+	FirstBlock   hexutil.Uint64 `json:"firstBlock"`
+	LastBlock    hexutil.Uint64 `json:"lastBlock"`
+	EmptyBlocks  hexutil.Uint64 `json:"emptyBlocks"`
+	BaseFee      *FeeStats      `json:"baseFeePerGas"`
+	PriorityFee  []*hexutil.Big `json:"priorityFeePerGas"` // 10th, 50th and 90th percentiles
+	GasUsedRatio float64        `json:"gasUsedRatio"`
+}
+
+// GasPriceStats summarizes the base fee and priority fee trends over the given
+// number of clique epochs, ending with the one holding the given block. The
+// epochs are returned from the oldest to the newest. The priority fees are the
+// averages of the per block percentiles over the blocks holding transactions.
+func (s *GasPriceAPI) GasPriceStats(ctx context.Context, epochs rpc.DecimalOrHex, lastBlock rpc.BlockNumber) ([]*EpochGasStats, error) {
+	clique := s.b.ChainConfig().Clique
+	if clique == nil || clique.Epoch == 0 {
+		return nil, errNotClique
+	}
+	if epochs > maxGasPriceStatsEpochs {
+		return nil, fmt.Errorf("too many epochs requested: %d > %d", epochs, maxGasPriceStatsEpochs)
+	}
+	header, err := s.b.HeaderByNumber(ctx, lastBlock)
+	if err != nil {
+		return nil, err
+	}
+	if header == nil {
+		return nil, fmt.Errorf("block %d not found", lastBlock)
+	}
+	var (
+		last  = header.Number.Uint64()
+		epoch = last / clique.Epoch
+		stats []*EpochGasStats
+	)
+	for i := uint64(0); i < uint64(epochs) && i <= last/clique.Epoch; i++ {
+		var (
+			number = epoch - i
+			start  = number * clique.Epoch
+			end    = start + clique.Epoch - 1
+		)
+		if end > last {
+			end = last
+		}
+		oldest, reward, baseFee, gasUsed, err := s.b.FeeHistory(ctx, int(end-start+1), rpc.BlockNumber(end), gasPriceStatsPercentiles)
+		if err != nil {
+			return nil, err
+		}
+		if len(gasUsed) == 0 {
+			break
+		}
+		stats = append(stats, summarizeEpoch(number, oldest.Uint64(), reward, baseFee[:len(gasUsed)], gasUsed))
+	}
+	// Order the epochs chronologically
+	for i, j := 0, len(stats)-1; i < j; i, j = i+1, j-1 {
+		stats[i], stats[j] = stats[j], stats[i]
+	}
+	return stats, nil
+}
+
+// summarizeEpoch aggregates the fee history of the sampled blocks of an epoch.
+// Blocks without gas used hold no transactions.
+func summarizeEpoch(epoch, first uint64, reward [][]*big.Int, baseFee []*big.Int, gasUsed []float64) *EpochGasStats {
+	var (
+		stats = &EpochGasStats{
+			Epoch:      hexutil.Uint64(epoch),
+			FirstBlock: hexutil.Uint64(first),
+			LastBlock:  hexutil.Uint64(first + uint64(len(gasUsed)) - 1),
+		}
+		min, max = new(big.Int).Set(baseFee[0]), new(big.Int).Set(baseFee[0])
+		sum      = new(big.Int)
+		tips     = make([]*big.Int, len(gasPriceStatsPercentiles))
+		ratio    float64
+	)
+	for i := range tips {
+		tips[i] = new(big.Int)
+	}
+	for i, fee := range baseFee {
+		if fee.Cmp(min) < 0 {
+			min.Set(fee)
+		}
+		if fee.Cmp(max) > 0 {
+			max.Set(fee)
+		}
+		sum.Add(sum, fee)
+		ratio += gasUsed[i]
+
+		if gasUsed[i] == 0 {
+			stats.EmptyBlocks++
+			continue
+		}
+		if i < len(reward) {
+			for j, tip := range reward[i] {
+				tips[j].Add(tips[j], tip)
+			}
+		}
+	}
+	blocks := int64(len(baseFee))
+	stats.BaseFee = &FeeStats{
+		Min: (*hexutil.Big)(min),
+		Avg: (*hexutil.Big)(sum.Div(sum, big.NewInt(blocks))),
+		Max: (*hexutil.Big)(max),
+	}
+	stats.PriorityFee = make([]*hexutil.Big, len(tips))
+	for i, tip := range tips {
+		if full := blocks - int64(stats.EmptyBlocks); full > 0 {
+			tip.Div(tip, big.NewInt(full))
+		}
+		stats.PriorityFee[i] = (*hexutil.Big)(tip)
+	}
+	stats.GasUsedRatio = ratio / float64(blocks)
+	return stats
+}
@@ -0,0 +1,111 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethapi
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/qydata/go-ctereum/core/types"
+	"github.com/qydata/go-ctereum/params"
+	"github.com/qydata/go-ctereum/rpc"
+)
+
+// feeHistoryBackend is a backend serving a synthetic fee history, where block
+// n has a base fee of n+1, priority fee percentiles of n, 2n and 3n, and the
+// even blocks are empty.
+type feeHistoryBackend struct {
+	*backendMock
+	head uint64
+}
+
+func (b *feeHistoryBackend) HeaderByNumber(ctx context.Context, number rpc.BlockNumber) (*types.Header, error) {
+	if number == rpc.LatestBlockNumber {
+		number = rpc.BlockNumber(b.head)
+	}
+	return &types.Header{Number: big.NewInt(int64(number))}, nil
+}
+
+func (b *feeHistoryBackend) FeeHistory(ctx context.Context, blockCount int, lastBlock rpc.BlockNumber, rewardPercentiles []float64) (*big.Int, [][]*big.Int, []*big.Int, []float64, error) {
+	var (
+		oldest  = uint64(lastBlock) + 1 - uint64(blockCount)
+		reward  [][]*big.Int
+		baseFee []*big.Int
+		gasUsed []float64
+	)
+	for n := oldest; n <= uint64(lastBlock); n++ {
+		baseFee = append(baseFee, new(big.Int).SetUint64(n+1))
+		if n%2 == 0 {
+			reward = append(reward, []*big.Int{new(big.Int), new(big.Int), new(big.Int)})
+			gasUsed = append(gasUsed, 0)
+			continue
+		}
+		reward = append(reward, []*big.Int{new(big.Int).SetUint64(n), new(big.Int).SetUint64(2 * n), new(big.Int).SetUint64(3 * n)})
+		gasUsed = append(gasUsed, 0.5)
+	}
+	baseFee = append(baseFee, new(big.Int).SetUint64(uint64(lastBlock)+2))
+	return new(big.Int).SetUint64(oldest), reward, baseFee, gasUsed, nil
+}
+
+func TestGasPriceStats(t *testing.T) {
+	backend := &feeHistoryBackend{backendMock: newBackendMock(), head: 9}
+	api := NewGasPriceAPI(backend)
+
+	if _, err := api.GasPriceStats(context.Background(), 2, rpc.LatestBlockNumber); err != errNotClique {
+		t.Fatalf("stats on non clique chain: have %v, want %v", err, errNotClique)
+	}
+	backend.config.Clique = &params.CliqueConfig{Period: 5, Epoch: 4}
+
+	stats, err := api.GasPriceStats(context.Background(), 2, rpc.LatestBlockNumber)
+	if err != nil {
+		t.Fatalf("failed to retrieve stats: %v", err)
+	}
+	if len(stats) != 2 {
+		t.Fatalf("epoch count mismatch: have %d, want 2", len(stats))
+	}
+	// Epoch 1 holds the blocks 4 to 7, epoch 2 the blocks 8 and 9
+	want := []struct {
+		epoch, first, last, empty uint64
+		min, avg, max             int64
+		tips                      []int64
+		ratio                     float64
+	}{
+		{1, 4, 7, 2, 5, 6, 8, []int64{6, 12, 18}, 0.25},
+		{2, 8, 9, 1, 9, 9, 10, []int64{9, 18, 27}, 0.25},
+	}
+	for i, w := range want {
+		have := stats[i]
+		if uint64(have.Epoch) != w.epoch || uint64(have.FirstBlock) != w.first || uint64(have.LastBlock) != w.last || uint64(have.EmptyBlocks) != w.empty {
+			t.Errorf("epoch %d: range mismatch: have %+v", i, have)
+		}
+		if have.BaseFee.Min.ToInt().Int64() != w.min || have.BaseFee.Avg.ToInt().Int64() != w.avg || have.BaseFee.Max.ToInt().Int64() != w.max {
+			t.Errorf("epoch %d: base fee mismatch: have %v/%v/%v, want %d/%d/%d", i, have.BaseFee.Min, have.BaseFee.Avg, have.BaseFee.Max, w.min, w.avg, w.max)
+		}
+		for j, tip := range w.tips {
+			if have.PriorityFee[j].ToInt().Int64() != tip {
+				t.Errorf("epoch %d: priority fee %d mismatch: have %v, want %d", i, j, have.PriorityFee[j], tip)
+			}
+		}
+		if have.GasUsedRatio != w.ratio {
+			t.Errorf("epoch %d: gas used ratio mismatch: have %v, want %v", i, have.GasUsedRatio, w.ratio)
+		}
+	}
+	if _, err := api.GasPriceStats(context.Background(), maxGasPriceStatsEpochs+1, rpc.LatestBlockNumber); err == nil {
+		t.Errorf("oversized request accepted")
+	}
+}
@@ -0,0 +1,209 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethapi
+
+import (
+	"context"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/qydata/go-ctereum/common"
+	"github.com/qydata/go-ctereum/core/types"
+	"github.com/qydata/go-ctereum/crypto"
+	"github.com/qydata/go-ctereum/rlp"
+	"github.com/qydata/go-ctereum/rpc"
+	"github.com/qydata/go-ctereum/trie"
+)
+
+// receiptsBackend is a backend serving a single block with its receipts.
+type receiptsBackend struct {
+	*backendMock
+	block    *types.Block
+	receipts types.Receipts
+}
+
+func (b *receiptsBackend) HeaderByHash(ctx context.Context, hash common.Hash) (*types.Header, error) {
+	return b.block.Header(), nil
+}
+
+func (b *receiptsBackend) HeaderByNumberOrHash(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (*types.Header, error) {
+	if block, _ := b.BlockByNumberOrHash(ctx, blockNrOrHash); block != nil {
+		return block.Header(), nil
+	}
+	return nil, nil
+}
+
+func (b *receiptsBackend) BlockByNumberOrHash(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (*types.Block, error) {
+	if hash, ok := blockNrOrHash.Hash(); ok && hash != b.block.Hash() {
+		return nil, nil
+	}
+	if number, ok := blockNrOrHash.Number(); ok && uint64(number) != b.block.NumberU64() {
+		return nil, nil
+	}
+	return b.block, nil
+}
+
+func (b *receiptsBackend) GetReceipts(ctx context.Context, hash common.Hash) (types.Receipts, error) {
+	return b.receipts, nil
+}
+
+func (b *receiptsBackend) GetTransaction(ctx context.Context, txHash common.Hash) (*types.Transaction, common.Hash, uint64, uint64, error) {
+	for i, tx := range b.block.Transactions() {
+		if tx.Hash() == txHash {
+			return tx, b.block.Hash(), b.block.NumberU64(), uint64(i), nil
+		}
+	}
+	return nil, common.Hash{}, 0, 0, nil
+}
+
+// Tests that the receipts of a block retrieved at once match the ones retrieved
+// transaction by transaction.
+func TestGetTransactionReceiptsByBlock(t *testing.T) {
+	var (
+		key, _  = crypto.GenerateKey()
+		backend = &receiptsBackend{backendMock: newBackendMock()}
+		signer  = types.LatestSigner(backend.config)
+		header  = &types.Header{Number: big.NewInt(1100), BaseFee: big.NewInt(10)}
+		txs     []*types.Transaction
+	)
+	for i := 0; i < 3; i++ {
+		tx, _ := types.SignNewTx(key, signer, &types.DynamicFeeTx{
+			ChainID:   backend.config.ChainID,
+			Nonce:     uint64(i),
+			GasTipCap: big.NewInt(1),
+			GasFeeCap: big.NewInt(20),
+			Gas:       21000,
+			To:        &common.Address{0x01},
+		})
+		txs = append(txs, tx)
+		backend.receipts = append(backend.receipts, &types.Receipt{
+			Status:            types.ReceiptStatusSuccessful,
+			CumulativeGasUsed: uint64(i+1) * 21000,
+			GasUsed:           21000,
+			TxHash:            tx.Hash(),
+		})
+	}
+	backend.block = types.NewBlock(header, txs, nil, backend.receipts, trie.NewStackTrie(nil))
+	api := NewTransactionAPI(backend, nil)
+
+	for _, blockNrOrHash := range []rpc.BlockNumberOrHash{
+		rpc.BlockNumberOrHashWithNumber(1100),
+		rpc.BlockNumberOrHashWithHash(backend.block.Hash(), false),
+	} {
+		receipts, err := api.GetTransactionReceiptsByBlock(context.Background(), blockNrOrHash)
+		if err != nil {
+			t.Fatalf("failed to retrieve block receipts: %v", err)
+		}
+		if len(receipts) != len(txs) {
+			t.Fatalf("receipt count mismatch: have %d, want %d", len(receipts), len(txs))
+		}
+		for i, tx := range txs {
+			want, err := api.GetTransactionReceipt(context.Background(), tx.Hash())
+			if err != nil {
+				t.Fatalf("failed to retrieve receipt %d: %v", i, err)
+			}
+			if !reflect.DeepEqual(receipts[i], want) {
+				t.Errorf("receipt %d mismatch: have %v, want %v", i, receipts[i], want)
+			}
+		}
+	}
+	// Unknown blocks are reported as null
+	receipts, err := api.GetTransactionReceiptsByBlock(context.Background(), rpc.BlockNumberOrHashWithNumber(1))
+	if receipts != nil || err != nil {
+		t.Errorf("unexpected receipts for unknown block: %v, %v", receipts, err)
+	}
+}
+
+// Tests that the raw encodings of a block, its header and its receipts decode
+// back into the stored objects.
+func TestDebugRawEncodings(t *testing.T) {
+	var (
+		key, _  = crypto.GenerateKey()
+		backend = &receiptsBackend{backendMock: newBackendMock()}
+		signer  = types.LatestSigner(backend.config)
+		header  = &types.Header{Number: big.NewInt(1100), BaseFee: big.NewInt(10), Extra: []byte{0x00, 0x80}}
+	)
+	tx, _ := types.SignNewTx(key, signer, &types.DynamicFeeTx{
+		ChainID:   backend.config.ChainID,
+		GasTipCap: big.NewInt(1),
+		GasFeeCap: big.NewInt(20),
+		Gas:       21000,
+		To:        &common.Address{0x01},
+	})
+	backend.receipts = types.Receipts{{
+		Type:              types.DynamicFeeTxType,
+		Status:            types.ReceiptStatusSuccessful,
+		CumulativeGasUsed: 21000,
+		Logs:              []*types.Log{},
+	}}
+	backend.block = types.NewBlock(header, []*types.Transaction{tx}, nil, backend.receipts, trie.NewStackTrie(nil))
+	api := NewDebugAPI(backend)
+
+	for _, blockNrOrHash := range []rpc.BlockNumberOrHash{
+		rpc.BlockNumberOrHashWithNumber(1100),
+		rpc.BlockNumberOrHashWithHash(backend.block.Hash(), false),
+	} {
+		rawHeader, err := api.GetRawHeader(context.Background(), blockNrOrHash)
+		if err != nil {
+			t.Fatalf("failed to retrieve raw header: %v", err)
+		}
+		var decHeader types.Header
+		if err := rlp.DecodeBytes(rawHeader, &decHeader); err != nil {
+			t.Fatalf("failed to decode raw header: %v", err)
+		}
+		if decHeader.Hash() != backend.block.Hash() {
+			t.Errorf("header hash mismatch: have %x, want %x", decHeader.Hash(), backend.block.Hash())
+		}
+		rawBlock, err := api.GetRawBlock(context.Background(), blockNrOrHash)
+		if err != nil {
+			t.Fatalf("failed to retrieve raw block: %v", err)
+		}
+		var decBlock types.Block
+		if err := rlp.DecodeBytes(rawBlock, &decBlock); err != nil {
+			t.Fatalf("failed to decode raw block: %v", err)
+		}
+		if decBlock.Hash() != backend.block.Hash() || len(decBlock.Transactions()) != 1 || decBlock.Transactions()[0].Hash() != tx.Hash() {
+			t.Errorf("block mismatch: have %x with %d txs", decBlock.Hash(), len(decBlock.Transactions()))
+		}
+		rawReceipts, err := api.GetRawReceipts(context.Background(), blockNrOrHash)
+		if err != nil {
+			t.Fatalf("failed to retrieve raw receipts: %v", err)
+		}
+		if len(rawReceipts) != 1 {
+			t.Fatalf("receipt count mismatch: have %d, want 1", len(rawReceipts))
+		}
+		var decReceipt types.Receipt
+		if err := decReceipt.UnmarshalBinary(rawReceipts[0]); err != nil {
+			t.Fatalf("failed to decode raw receipt: %v", err)
+		}
+		if decReceipt.Type != types.DynamicFeeTxType || decReceipt.CumulativeGasUsed != 21000 {
+			t.Errorf("receipt mismatch: have type %d, cumulative gas %d", decReceipt.Type, decReceipt.CumulativeGasUsed)
+		}
+	}
+	// Unknown blocks are reported as errors
+	unknown := rpc.BlockNumberOrHashWithNumber(1)
+	if _, err := api.GetRawHeader(context.Background(), unknown); err == nil {
+		t.Error("expected error for unknown header")
+	}
+	if _, err := api.GetRawBlock(context.Background(), unknown); err == nil {
+		t.Error("expected error for unknown block")
+	}
+	if _, err := api.GetRawReceipts(context.Background(), unknown); err == nil {
+		t.Error("expected error for unknown receipts")
+	}
+}
@@ -0,0 +1,118 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethapi
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+
+	"github.com/qydata/go-ctereum/common"
+	"github.com/qydata/go-ctereum/core/types"
+	"github.com/qydata/go-ctereum/crypto"
+	"github.com/qydata/go-ctereum/rpc"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	// maxRootHashLength is the maximum number of headers a single root hash
+	// may span.
+	maxRootHashLength = 1 << 15
+
+	// rootHashFetchWorkers is the number of concurrent header retrievals used
+	// while collecting the headers of a root hash range. Light clients fetch
+	// missing headers on demand, so the retrievals are worth parallelising.
+	rootHashFetchWorkers = 16
+)
+
+// GetRootHash returns the merkle root of the headers in the inclusive range
+// [start, end]. Each leaf commits to the number, timestamp, transaction root
+// and receipt root of a header. The headers are retrieved through the backend,
+// so on light clients they are fetched (and CHT verified) on demand.
+func (s *BlockChainAPI) GetRootHash(ctx context.Context, start uint64, end uint64) (string, error) {
+	if start > end {
+		return "", fmt.Errorf("invalid root hash range: start %d > end %d", start, end)
+	}
+	if length := end - start + 1; length > maxRootHashLength {
+		return "", fmt.Errorf("root hash range too long: %d > %d", length, maxRootHashLength)
+	}
+	if head := s.b.CurrentHeader().Number.Uint64(); end > head {
+		return "", fmt.Errorf("root hash range end %d beyond current head %d", end, head)
+	}
+	headers := make([]*types.Header, end-start+1)
+
+	g, gctx := errgroup.WithContext(ctx)
+	next := make(chan int)
+	g.Go(func() error {
+		defer close(next)
+		for i := range headers {
+			select {
+			case next <- i:
+			case <-gctx.Done():
+				return gctx.Err()
+			}
+		}
+		return nil
+	})
+	for w := 0; w < rootHashFetchWorkers; w++ {
+		g.Go(func() error {
+			for i := range next {
+				number := start + uint64(i)
+				header, err := s.b.HeaderByNumber(gctx, rpc.BlockNumber(number))
+				if err != nil {
+					return err
+				}
+				if header == nil {
+					return fmt.Errorf("header #%d not found", number)
+				}
+				headers[i] = header
+			}
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(rootHash(headers).Bytes()), nil
+}
+
+// rootHash computes the merkle root of the given headers. The leaves are
+// padded with zero hashes up to the next power of two, and inner nodes are the
+// keccak256 hash of the concatenation of their children.
+func rootHash(headers []*types.Header) common.Hash {
+	length := 1
+	for length < len(headers) {
+		length <<= 1
+	}
+	nodes := make([]common.Hash, length)
+	for i, header := range headers {
+		nodes[i] = crypto.Keccak256Hash(
+			common.LeftPadBytes(header.Number.Bytes(), 32),
+			common.LeftPadBytes(new(big.Int).SetUint64(header.Time).Bytes(), 32),
+			header.TxHash.Bytes(),
+			header.ReceiptHash.Bytes(),
+		)
+	}
+	for len(nodes) > 1 {
+		for i := 0; i < len(nodes)/2; i++ {
+			nodes[i] = crypto.Keccak256Hash(nodes[2*i].Bytes(), nodes[2*i+1].Bytes())
+		}
+		nodes = nodes[:len(nodes)/2]
+	}
+	return nodes[0]
+}
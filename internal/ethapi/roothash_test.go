@@ -0,0 +1,78 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethapi
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/qydata/go-ctereum/common"
+	"github.com/qydata/go-ctereum/core/types"
+	"github.com/qydata/go-ctereum/crypto"
+)
+
+func rootHashLeaf(header *types.Header) common.Hash {
+	return crypto.Keccak256Hash(
+		common.LeftPadBytes(header.Number.Bytes(), 32),
+		common.LeftPadBytes(new(big.Int).SetUint64(header.Time).Bytes(), 32),
+		header.TxHash.Bytes(),
+		header.ReceiptHash.Bytes(),
+	)
+}
+
+func TestRootHash(t *testing.T) {
+	headers := make([]*types.Header, 3)
+	for i := range headers {
+		headers[i] = &types.Header{
+			Number:      big.NewInt(int64(i + 1)),
+			Time:        uint64(100 + i),
+			TxHash:      common.BigToHash(big.NewInt(int64(2 * i))),
+			ReceiptHash: common.BigToHash(big.NewInt(int64(2*i + 1))),
+		}
+	}
+	// A single header is its own root
+	if have, want := rootHash(headers[:1]), rootHashLeaf(headers[0]); have != want {
+		t.Errorf("single header root mismatch: have %x, want %x", have, want)
+	}
+	// Three headers are padded with an empty leaf
+	var (
+		left  = crypto.Keccak256Hash(rootHashLeaf(headers[0]).Bytes(), rootHashLeaf(headers[1]).Bytes())
+		right = crypto.Keccak256Hash(rootHashLeaf(headers[2]).Bytes(), common.Hash{}.Bytes())
+		want  = crypto.Keccak256Hash(left.Bytes(), right.Bytes())
+	)
+	if have := rootHash(headers); have != want {
+		t.Errorf("padded root mismatch: have %x, want %x", have, want)
+	}
+}
+
+func TestGetRootHashInvalidRange(t *testing.T) {
+	api := NewBlockChainAPI(newBackendMock())
+
+	tests := []struct {
+		start, end uint64
+	}{
+		{10, 9},                // start after end
+		{0, maxRootHashLength}, // too long
+		{1000, 1200},           // beyond the current head (1100)
+	}
+	for i, tt := range tests {
+		if _, err := api.GetRootHash(context.Background(), tt.start, tt.end); err == nil {
+			t.Errorf("test %d: expected error for range [%d, %d]", i, tt.start, tt.end)
+		}
+	}
+}
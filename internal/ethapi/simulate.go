@@ -0,0 +1,356 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/qydata/go-ctereum/common"
+	"github.com/qydata/go-ctereum/common/hexutil"
+	"github.com/qydata/go-ctereum/consensus"
+	"github.com/qydata/go-ctereum/consensus/misc"
+	"github.com/qydata/go-ctereum/core"
+	"github.com/qydata/go-ctereum/core/state"
+	"github.com/qydata/go-ctereum/core/types"
+	"github.com/qydata/go-ctereum/core/vm"
+	"github.com/qydata/go-ctereum/params"
+	"github.com/qydata/go-ctereum/rpc"
+	"github.com/qydata/go-ctereum/trie"
+)
+
+const (
+	// maxSimulateBlocks is the maximum number of blocks simulated by a single
+	// request.
+	maxSimulateBlocks = 256
+
+	// defaultSimulatePeriod is the number of seconds between the simulated
+	// blocks of chains without a fixed block period.
+	defaultSimulatePeriod = 12
+
+	// errCodeVMError is the JSON error code of a call failing in the EVM for
+	// another reason than a revert.
+	errCodeVMError = -32015
+)
+
+// errNoValidatorContract is returned if validator overrides are requested on a
+// chain without a validator contract.
+var errNoValidatorContract = errors.New("validator contract not configured")
+
+// SimBlock is a synthetic block to simulate: the overrides are applied on top
+// of the state left by the previous block before executing the calls.
+type SimBlock struct {
+	BlockOverrides     *BlockOverrides   `json:"blockOverrides"`
+	StateOverrides     *StateOverride    `json:"stateOverrides"`
+	ValidatorOverrides *OverrideAccount  `json:"validatorOverrides"` // Overrides of the clique validator contract
+	Calls              []TransactionArgs `json:"calls"`
+}
+
+// SimOpts are the inputs of a simulation.
+type SimOpts struct {
+	BlockStateCalls []SimBlock `json:"blockStateCalls"`
+}
+
+// simCallError is the failure of a simulated call.
+type simCallError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    string `json:"data,omitempty"`
+}
+
+// simCallResult is the outcome of a simulated call.
+type simCallResult struct {
+	ReturnValue hexutil.Bytes  `json:"returnData"`
+	Logs        []*types.Log   `json:"logs"`
+	GasUsed     hexutil.Uint64 `json:"gasUsed"`
+	Status      hexutil.Uint64 `json:"status"`
+	Error       *simCallError  `json:"error,omitempty"`
+}
+
+// SimulateV1 executes a sequence of synthetic blocks on top of the given block,
+// or the latest one if none is given, each block seeing the state left by the
+// previous ones. Every block holds the given calls, executed in order after
+// applying the block, state and validator contract overrides of the block,
+// which makes it possible to rehearse multi-block flows such as staking
+// governance against the real chain state. Nothing is persisted.
+//
+// The numbers and timestamps of the blocks follow the ones of their parent
+// unless overridden, and must be strictly increasing. The hashes of simulated
+// blocks are not available to the BLOCKHASH opcode.
+//
+// The state upgrades of the blocks are applied before their overrides, and the
+// blocks are finalized by the consensus engine after their calls. Clique skips
+// the block reward of the parent signer and the signer activity accounting of
+// the simulated blocks, which need the sealing history of the chain, but runs
+// the finalization upgrades and the subsidy reimbursement.
+func (s *BlockChainAPI) SimulateV1(ctx context.Context, opts SimOpts, blockNrOrHash *rpc.BlockNumberOrHash) ([]map[string]interface{}, error) {
+	if len(opts.BlockStateCalls) == 0 {
+		return nil, errors.New("empty simulation")
+	}
+	if len(opts.BlockStateCalls) > maxSimulateBlocks {
+		return nil, fmt.Errorf("too many blocks: %d > %d", len(opts.BlockStateCalls), maxSimulateBlocks)
+	}
+	if blockNrOrHash == nil {
+		latest := rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+		blockNrOrHash = &latest
+	}
+	statedb, parent, err := s.b.StateAndHeaderByNumberOrHash(ctx, *blockNrOrHash)
+	if statedb == nil || err != nil {
+		return nil, err
+	}
+	// Cancel the simulation as a whole once the call timeout elapses
+	var cancel context.CancelFunc
+	if timeout := s.b.RPCEVMTimeout(); timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+	} else {
+		ctx, cancel = context.WithCancel(ctx)
+	}
+	defer cancel()
+
+	results := make([]map[string]interface{}, 0, len(opts.BlockStateCalls))
+	for i, block := range opts.BlockStateCalls {
+		header, calls, err := s.simulateBlock(ctx, statedb, parent, &block)
+		if err != nil {
+			return nil, fmt.Errorf("block %d: %w", i, err)
+		}
+		fields := RPCMarshalHeader(header)
+		fields["calls"] = calls
+		results = append(results, fields)
+		parent = header
+	}
+	return results, nil
+}
+
+// simulateBlock executes the calls of a synthetic block on top of the given
+// state, returning the header of the block and the outcome of its calls.
+func (s *BlockChainAPI) simulateBlock(ctx context.Context, statedb *state.StateDB, parent *types.Header, block *SimBlock) (*types.Header, []*simCallResult, error) {
+	header, err := s.simulatedHeader(parent, block.BlockOverrides)
+	if err != nil {
+		return nil, nil, err
+	}
+	config := s.b.ChainConfig()
+	statedb.ResetSubsidy()
+	statedb.ResetTips()
+	core.ApplyStateUpgrades(config, header.Number, statedb)
+
+	if err := block.StateOverrides.Apply(statedb); err != nil {
+		return nil, nil, err
+	}
+	if block.ValidatorOverrides != nil {
+		clique := config.Clique
+		if clique == nil || clique.ValidatorContract == "" {
+			return nil, nil, errNoValidatorContract
+		}
+		override := StateOverride{common.HexToAddress(clique.ValidatorContract): *block.ValidatorOverrides}
+		if err := override.Apply(statedb); err != nil {
+			return nil, nil, err
+		}
+	}
+	var (
+		deleteEmpty = config.IsEIP158(header.Number)
+		gp          = new(core.GasPool).AddGas(header.GasLimit)
+		results     = make([]*simCallResult, len(block.Calls))
+		txs         = make([]*types.Transaction, len(block.Calls))
+		receipts    = make([]*types.Receipt, len(block.Calls))
+		logs        []*types.Log
+	)
+	for i, args := range block.Calls {
+		// Calls without a gas limit may use up all the gas left in the block
+		if args.Gas == nil {
+			remaining := hexutil.Uint64(gp.Gas())
+			args.Gas = &remaining
+		}
+		if args.Nonce == nil {
+			nonce := hexutil.Uint64(statedb.GetNonce(args.from()))
+			args.Nonce = &nonce
+		}
+		if args.ChainID == nil {
+			args.ChainID = (*hexutil.Big)(config.ChainID)
+		}
+		msg, err := args.ToMessage(s.b.RPCGasCap(), header.BaseFee)
+		if err != nil {
+			return nil, nil, fmt.Errorf("call %d: %w", i, err)
+		}
+		tx := args.ToTransaction()
+		statedb.Prepare(tx.Hash(), i)
+
+		evm, vmError, err := s.b.GetEVM(ctx, msg, statedb, header, &vm.Config{NoBaseFee: true})
+		if err != nil {
+			return nil, nil, err
+		}
+		go func() {
+			<-ctx.Done()
+			evm.Cancel()
+		}()
+		result, err := core.ApplyMessage(evm, msg, gp)
+		if err := vmError(); err != nil {
+			return nil, nil, err
+		}
+		if evm.Cancelled() {
+			return nil, nil, fmt.Errorf("execution aborted (timeout = %v)", s.b.RPCEVMTimeout())
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("call %d: %w", i, err)
+		}
+		statedb.Finalise(deleteEmpty)
+		header.GasUsed += result.UsedGas
+
+		res := &simCallResult{
+			ReturnValue: result.Return(),
+			Logs:        statedb.GetLogs(tx.Hash(), common.Hash{}),
+			GasUsed:     hexutil.Uint64(result.UsedGas),
+			Status:      hexutil.Uint64(types.ReceiptStatusSuccessful),
+		}
+		if res.Logs == nil {
+			res.Logs = []*types.Log{}
+		}
+		if result.Failed() {
+			res.Status = hexutil.Uint64(types.ReceiptStatusFailed)
+			if errors.Is(result.Err, vm.ErrExecutionReverted) {
+				revert := newRevertError(result)
+				res.Error = &simCallError{Code: revert.ErrorCode(), Message: revert.Error(), Data: revert.reason}
+			} else {
+				res.Error = &simCallError{Code: errCodeVMError, Message: result.Err.Error()}
+			}
+		}
+		receipt := &types.Receipt{
+			Type:              tx.Type(),
+			Status:            uint64(res.Status),
+			CumulativeGasUsed: header.GasUsed,
+			Logs:              res.Logs,
+			TxHash:            tx.Hash(),
+			GasUsed:           result.UsedGas,
+			TransactionIndex:  uint(i),
+		}
+		receipt.Bloom = types.CreateBloom(types.Receipts{receipt})
+
+		results[i], txs[i], receipts[i] = res, tx, receipt
+		logs = append(logs, res.Logs...)
+	}
+	s.finalizeSimulated(header, statedb, txs)
+	header.Root = statedb.IntermediateRoot(deleteEmpty)
+	header.TxHash = types.DeriveSha(types.Transactions(txs), trie.NewStackTrie(nil))
+	header.ReceiptHash = types.DeriveSha(types.Receipts(receipts), trie.NewStackTrie(nil))
+	header.Bloom = types.CreateBloom(receipts)
+
+	// Stamp the logs with their position now that the block is sealed
+	hash := header.Hash()
+	for i, log := range logs {
+		log.BlockNumber = header.Number.Uint64()
+		log.BlockHash = hash
+		log.Index = uint(i)
+	}
+	return header, results, nil
+}
+
+// finalizeSimulated applies the end of block state changes of the consensus
+// engine to a simulated block, skipping the ones needing its sealed ancestors.
+func (s *BlockChainAPI) finalizeSimulated(header *types.Header, statedb *state.StateDB, txs []*types.Transaction) {
+	chain := &simulatedChain{b: s.b}
+	switch engine := s.b.Engine().(type) {
+	case nil:
+	case consensus.DetachedFinalizer:
+		engine.FinalizeDetached(chain, header, statedb)
+	default:
+		engine.Finalize(chain, header, statedb, txs, nil)
+	}
+}
+
+// simulatedChain is a consensus.ChainHeaderReader over the chain of the backend,
+// on top of which the blocks are simulated.
+type simulatedChain struct {
+	b Backend
+}
+
+func (c *simulatedChain) Config() *params.ChainConfig  { return c.b.ChainConfig() }
+func (c *simulatedChain) CurrentHeader() *types.Header { return c.b.CurrentHeader() }
+
+func (c *simulatedChain) GetHeader(hash common.Hash, number uint64) *types.Header {
+	header, _ := c.b.HeaderByHash(context.Background(), hash)
+	if header == nil || header.Number.Uint64() != number {
+		return nil
+	}
+	return header
+}
+
+func (c *simulatedChain) GetHeaderByNumber(number uint64) *types.Header {
+	header, _ := c.b.HeaderByNumber(context.Background(), rpc.BlockNumber(number))
+	return header
+}
+
+func (c *simulatedChain) GetHeaderByHash(hash common.Hash) *types.Header {
+	header, _ := c.b.HeaderByHash(context.Background(), hash)
+	return header
+}
+
+func (c *simulatedChain) GetTd(hash common.Hash, number uint64) *big.Int {
+	return c.b.GetTd(context.Background(), hash)
+}
+
+// simulatedHeader creates the header of a synthetic block on top of the given
+// parent, applying the given overrides.
+func (s *BlockChainAPI) simulatedHeader(parent *types.Header, overrides *BlockOverrides) (*types.Header, error) {
+	config := s.b.ChainConfig()
+	period := uint64(defaultSimulatePeriod)
+	if config.Clique != nil && config.Clique.Period > 0 {
+		period = config.Clique.Period
+	}
+	header := &types.Header{
+		ParentHash: parent.Hash(),
+		UncleHash:  types.EmptyUncleHash,
+		Coinbase:   parent.Coinbase,
+		Difficulty: new(big.Int).Set(parent.Difficulty),
+		Number:     new(big.Int).Add(parent.Number, common.Big1),
+		GasLimit:   parent.GasLimit,
+		Time:       parent.Time + period,
+	}
+	if overrides != nil {
+		if overrides.Number != nil {
+			if overrides.Number.ToInt().Cmp(parent.Number) <= 0 {
+				return nil, fmt.Errorf("block number %v not above parent %v", overrides.Number, parent.Number)
+			}
+			header.Number = new(big.Int).Set(overrides.Number.ToInt())
+		}
+		if overrides.Time != nil {
+			if !overrides.Time.ToInt().IsUint64() || overrides.Time.ToInt().Uint64() <= parent.Time {
+				return nil, fmt.Errorf("block timestamp %v not above parent %d", overrides.Time, parent.Time)
+			}
+			header.Time = overrides.Time.ToInt().Uint64()
+		}
+		if overrides.Difficulty != nil {
+			header.Difficulty = new(big.Int).Set(overrides.Difficulty.ToInt())
+		}
+		if overrides.GasLimit != nil {
+			header.GasLimit = uint64(*overrides.GasLimit)
+		}
+		if overrides.Coinbase != nil {
+			header.Coinbase = *overrides.Coinbase
+		}
+		if overrides.Random != nil {
+			header.MixDigest = *overrides.Random
+		}
+	}
+	if config.IsLondon(header.Number) {
+		header.BaseFee = misc.CalcBaseFee(config, parent)
+	}
+	if overrides != nil && overrides.BaseFee != nil {
+		header.BaseFee = new(big.Int).Set(overrides.BaseFee.ToInt())
+	}
+	return header, nil
+}
@@ -0,0 +1,174 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethapi
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/qydata/go-ctereum/common"
+	"github.com/qydata/go-ctereum/common/hexutil"
+	"github.com/qydata/go-ctereum/consensus"
+	"github.com/qydata/go-ctereum/consensus/clique"
+	"github.com/qydata/go-ctereum/core/rawdb"
+	"github.com/qydata/go-ctereum/core/state"
+	"github.com/qydata/go-ctereum/core/types"
+	"github.com/qydata/go-ctereum/params"
+	"github.com/qydata/go-ctereum/rpc"
+)
+
+// simulateBackend is a backend serving a fresh copy of a fixed state for every
+// simulation.
+type simulateBackend struct {
+	systemCallBackend
+	engine consensus.Engine
+}
+
+func (b *simulateBackend) Engine() consensus.Engine { return b.engine }
+
+func (b *simulateBackend) StateAndHeaderByNumberOrHash(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (*state.StateDB, *types.Header, error) {
+	return b.state.Copy(), b.current, nil
+}
+
+// Tests that simulated blocks are executed on top of each other, with their
+// overrides applied.
+func TestSimulateV1(t *testing.T) {
+	var (
+		counter   = common.HexToAddress("0xaaaa")
+		reverter  = common.HexToAddress("0xbbbb")
+		validator = common.HexToAddress("0xcccc")
+		// Increment slot 0 and log its new value
+		code = common.FromHex("6000546001018060005560005260206000a000")
+	)
+	db, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	db.SetCode(counter, code)
+	db.SetCode(reverter, common.FromHex("60006000fd"))
+
+	backend := &simulateBackend{systemCallBackend: systemCallBackend{backendMock: newBackendMock(), state: db}}
+	api := NewBlockChainAPI(backend)
+
+	time := hexutil.Big(*big.NewInt(10000))
+	validatorCode := hexutil.Bytes(code)
+	validatorState := map[common.Hash]common.Hash{{}: common.BigToHash(big.NewInt(100))}
+	opts := SimOpts{BlockStateCalls: []SimBlock{
+		{Calls: []TransactionArgs{{To: &counter}, {To: &counter}, {To: &reverter}}},
+		{
+			BlockOverrides:     &BlockOverrides{Time: &time},
+			ValidatorOverrides: &OverrideAccount{Code: &validatorCode, State: &validatorState},
+			Calls:              []TransactionArgs{{To: &counter}, {To: &validator}},
+		},
+	}}
+	// Validator overrides need a validator contract
+	if _, err := api.SimulateV1(context.Background(), opts, nil); err == nil {
+		t.Fatalf("validator overrides accepted without validator contract")
+	}
+	backend.config.Clique = &params.CliqueConfig{Period: 5, ValidatorContract: validator.Hex()}
+
+	blocks, err := api.SimulateV1(context.Background(), opts, nil)
+	if err != nil {
+		t.Fatalf("failed to simulate: %v", err)
+	}
+	if len(blocks) != 2 {
+		t.Fatalf("block count mismatch: have %d, want 2", len(blocks))
+	}
+	wantNumbers := []int64{1101, 1102}
+	wantTimes := []hexutil.Uint64{560, 10000}
+	wantLogs := [][]int64{{1, 2, -1}, {3, 101}}
+	for i, block := range blocks {
+		if number := block["number"].(*hexutil.Big).ToInt().Int64(); number != wantNumbers[i] {
+			t.Errorf("block %d: number mismatch: have %d, want %d", i, number, wantNumbers[i])
+		}
+		if time := block["timestamp"].(hexutil.Uint64); time != wantTimes[i] {
+			t.Errorf("block %d: timestamp mismatch: have %d, want %d", i, time, wantTimes[i])
+		}
+		calls := block["calls"].([]*simCallResult)
+		for j, want := range wantLogs[i] {
+			call := calls[j]
+			if want < 0 {
+				if call.Status != hexutil.Uint64(types.ReceiptStatusFailed) || call.Error == nil || call.Error.Code != 3 {
+					t.Errorf("block %d call %d: revert not reported: %+v", i, j, call)
+				}
+				continue
+			}
+			if call.Status != hexutil.Uint64(types.ReceiptStatusSuccessful) || len(call.Logs) != 1 {
+				t.Fatalf("block %d call %d: unexpected outcome: %+v", i, j, call)
+			}
+			log := call.Logs[0]
+			if have := new(big.Int).SetBytes(log.Data).Int64(); have != want {
+				t.Errorf("block %d call %d: counter mismatch: have %d, want %d", i, j, have, want)
+			}
+			if log.BlockHash != block["hash"].(common.Hash) || log.BlockNumber != uint64(wantNumbers[i]) {
+				t.Errorf("block %d call %d: log position mismatch: %+v", i, j, log)
+			}
+		}
+	}
+	// Blocks must move forward
+	past := hexutil.Big(*big.NewInt(100))
+	opts = SimOpts{BlockStateCalls: []SimBlock{{BlockOverrides: &BlockOverrides{Time: &past}}}}
+	if _, err := api.SimulateV1(context.Background(), opts, nil); err == nil {
+		t.Errorf("block with past timestamp accepted")
+	}
+}
+
+// Tests that the state upgrades of the simulated blocks are applied, both at the
+// start of the blocks and by the finalization of the engine.
+func TestSimulateV1StateUpgrades(t *testing.T) {
+	var (
+		contract = common.HexToAddress("0xaaaa")
+		funded   = common.HexToAddress("0xbbbb")
+		// Return slot 0
+		code = common.FromHex("60005460005260206000f3")
+	)
+	db, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+
+	backend := &simulateBackend{systemCallBackend: systemCallBackend{backendMock: newBackendMock(), state: db}}
+	backend.config.Clique = &params.CliqueConfig{Period: 5}
+	backend.config.StateUpgrades = map[uint64][]params.UpgradeAction{
+		1101: {{Address: contract, Code: code, Storage: map[common.Hash]common.Hash{{}: common.BigToHash(big.NewInt(7))}}},
+		1102: {{Address: funded, AddBalance: big.NewInt(1000), Finalize: true}},
+	}
+	backend.engine = clique.New(backend.config.Clique, rawdb.NewMemoryDatabase(), nil)
+	api := NewBlockChainAPI(backend)
+
+	opts := SimOpts{BlockStateCalls: []SimBlock{
+		{Calls: []TransactionArgs{{To: &contract}}},
+		{},
+		{},
+	}}
+	blocks, err := api.SimulateV1(context.Background(), opts, nil)
+	if err != nil {
+		t.Fatalf("failed to simulate: %v", err)
+	}
+	// The upgrade of the first block is visible to its calls
+	ret := blocks[0]["calls"].([]*simCallResult)[0].ReturnValue
+	if have := new(big.Int).SetBytes(ret).Int64(); have != 7 {
+		t.Errorf("upgraded slot mismatch: have %d, want 7", have)
+	}
+	// The finalization upgrade of the second block is included in its state root
+	want, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	want.SetCode(contract, code)
+	want.SetState(contract, common.Hash{}, common.BigToHash(big.NewInt(7)))
+	want.SetNonce(common.Address{}, 1) // Sender of the call
+	want.AddBalance(funded, big.NewInt(1000))
+	root := want.IntermediateRoot(true)
+	for i := 1; i < len(blocks); i++ {
+		if have := blocks[i]["stateRoot"].(common.Hash); have != root {
+			t.Errorf("block %d: state root mismatch: have %x, want %x", i, have, root)
+		}
+	}
+}
@@ -52,6 +52,9 @@ type TransactionArgs struct {
 	// Introduced by AccessListTxType transaction.
 	AccessList *types.AccessList `json:"accessList,omitempty"`
 	ChainID    *hexutil.Big      `json:"chainId,omitempty"`
+
+	// Introduced by AuthTxType transaction.
+	AuthProof *hexutil.Bytes `json:"authProof,omitempty"`
 }
 
 // from retrieves the transaction sender address.
@@ -127,7 +130,21 @@ func (args *TransactionArgs) setDefaults(ctx context.Context, b Backend) error {
 	} else {
 		args.ChainID = (*hexutil.Big)(want)
 	}
-	return nil
+	return args.checkAuthProof(b)
+}
+
+// checkAuthProof ensures authenticated transactions are enabled for the next
+// block and that the auth proof, if any, is well formed.
+func (args *TransactionArgs) checkAuthProof(b Backend) error {
+	if args.AuthProof == nil {
+		return nil
+	}
+	next := new(big.Int).Add(b.CurrentHeader().Number, common.Big1)
+	if !b.ChainConfig().IsAuthTx(next) {
+		return types.ErrTxTypeNotSupported
+	}
+	_, err := types.DecodeAuthProof(*args.AuthProof)
+	return err
 }
 
 // setFeeDefaults fills in default fee values for unspecified tx fields.
@@ -272,6 +289,27 @@ func (args *TransactionArgs) ToMessage(globalGasCap uint64, baseFee *big.Int) (t
 func (args *TransactionArgs) toTransaction() *types.Transaction {
 	var data types.TxData
 	switch {
+	case args.AuthProof != nil:
+		al := types.AccessList{}
+		if args.AccessList != nil {
+			al = *args.AccessList
+		}
+		feeCap, tip := args.MaxFeePerGas, args.MaxPriorityFeePerGas
+		if feeCap == nil {
+			feeCap, tip = args.GasPrice, args.GasPrice
+		}
+		data = &types.AuthTx{
+			To:         args.To,
+			ChainID:    (*big.Int)(args.ChainID),
+			Nonce:      uint64(*args.Nonce),
+			Gas:        uint64(*args.Gas),
+			GasFeeCap:  (*big.Int)(feeCap),
+			GasTipCap:  (*big.Int)(tip),
+			Value:      (*big.Int)(args.Value),
+			Data:       args.data(),
+			AccessList: al,
+			AuthProof:  *args.AuthProof,
+		}
 	case args.MaxFeePerGas != nil:
 		al := types.AccessList{}
 		if args.AccessList != nil {
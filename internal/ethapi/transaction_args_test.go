@@ -29,14 +29,17 @@ import (
 	"github.com/qydata/go-ctereum/common"
 	"github.com/qydata/go-ctereum/common/hexutil"
 	"github.com/qydata/go-ctereum/consensus"
+	"github.com/qydata/go-ctereum/contracts/authcontroller"
 	"github.com/qydata/go-ctereum/core"
 	"github.com/qydata/go-ctereum/core/bloombits"
 	"github.com/qydata/go-ctereum/core/state"
 	"github.com/qydata/go-ctereum/core/types"
 	"github.com/qydata/go-ctereum/core/vm"
+	"github.com/qydata/go-ctereum/crypto"
 	"github.com/qydata/go-ctereum/ethdb"
 	"github.com/qydata/go-ctereum/event"
 	"github.com/qydata/go-ctereum/params"
+	"github.com/qydata/go-ctereum/rlp"
 	"github.com/qydata/go-ctereum/rpc"
 )
 
@@ -207,6 +210,37 @@ func TestSetFeeDefaults(t *testing.T) {
 	}
 }
 
+// TestCheckAuthProof tests that authenticated transaction arguments are only
+// accepted with a well formed proof once the fork is active.
+func TestCheckAuthProof(t *testing.T) {
+	b := newBackendMock()
+	valid, err := rlp.EncodeToBytes(&types.AuthProof{
+		AuthTime:   big.NewInt(1),
+		AuthExpiry: big.NewInt(0),
+		AuthLevel:  big.NewInt(1),
+		Signature:  make([]byte, crypto.SignatureLength),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	proof := (*hexutil.Bytes)(&valid)
+	malformed := &hexutil.Bytes{0x01, 0x02}
+
+	if err := (&TransactionArgs{}).checkAuthProof(b); err != nil {
+		t.Fatalf("plain transaction rejected: %v", err)
+	}
+	if err := (&TransactionArgs{AuthProof: proof}).checkAuthProof(b); err != types.ErrTxTypeNotSupported {
+		t.Fatalf("authenticated transaction before the fork: have %v, want %v", err, types.ErrTxTypeNotSupported)
+	}
+	b.config.AuthTxBlock = big.NewInt(1000)
+	if err := (&TransactionArgs{AuthProof: malformed}).checkAuthProof(b); err != types.ErrInvalidAuthProof {
+		t.Fatalf("malformed auth proof: have %v, want %v", err, types.ErrInvalidAuthProof)
+	}
+	if err := (&TransactionArgs{AuthProof: proof}).checkAuthProof(b); err != nil {
+		t.Fatalf("authenticated transaction rejected: %v", err)
+	}
+}
+
 type backendMock struct {
 	current *types.Header
 	config  *params.ChainConfig
@@ -309,6 +343,9 @@ func (b *backendMock) SubscribeChainEvent(ch chan<- core.ChainEvent) event.Subsc
 func (b *backendMock) SubscribeChainHeadEvent(ch chan<- core.ChainHeadEvent) event.Subscription {
 	return nil
 }
+func (b *backendMock) SubscribeChain2HeadEvent(ch chan<- core.Chain2HeadEvent) event.Subscription {
+	return nil
+}
 func (b *backendMock) SubscribeChainSideEvent(ch chan<- core.ChainSideEvent) event.Subscription {
 	return nil
 }
@@ -328,6 +365,7 @@ func (b *backendMock) TxPoolContent() (map[common.Address]types.Transactions, ma
 func (b *backendMock) TxPoolContentFrom(addr common.Address) (types.Transactions, types.Transactions) {
 	return nil, nil
 }
+func (b *backendMock) TxPoolAuthSummary() (*core.TxPoolAuthSummary, error)                  { return nil, nil }
 func (b *backendMock) SubscribeNewTxsEvent(chan<- core.NewTxsEvent) event.Subscription      { return nil }
 func (b *backendMock) BloomStatus() (uint64, uint64)                                        { return 0, 0 }
 func (b *backendMock) ServiceFilter(ctx context.Context, session *bloombits.MatcherSession) {}
@@ -340,3 +378,5 @@ func (b *backendMock) SubscribeRemovedLogsEvent(ch chan<- core.RemovedLogsEvent)
 }
 
 func (b *backendMock) Engine() consensus.Engine { return nil }
+
+func (b *backendMock) AuthRegistry() authcontroller.Registry { return nil }
@@ -20,6 +20,7 @@ package web3ext
 var Modules = map[string]string{
 	"admin":    AdminJs,
 	"clique":   CliqueJs,
+	"ct":       CtJs,
 	"stake":    StakeJs,
 	"ethash":   EthashJs,
 	"debug":    DebugJs,
@@ -106,6 +107,29 @@ web3._extend({
 			call: 'stake_getSignersAtHash',
 			params: 1
 		}), 
+		new web3._extend.Method({
+			name: 'getProducers',
+			call: 'stake_getProducers',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'getProducersAtHash',
+			call: 'stake_getProducersAtHash',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'getSignerRotation',
+			call: 'stake_getSignerRotation',
+			params: 2,
+			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter, web3._extend.utils.fromDecimal]
+		}),
+		new web3._extend.Method({
+			name: 'reconcileValidators',
+			call: 'stake_reconcileValidators',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter]
+		}),
 		new web3._extend.Method({
 			name: 'status',
 			call: 'stake_status',
@@ -179,6 +203,16 @@ web3._extend({
 			call: 'admin_removeTrustedPeer',
 			params: 1
 		}),
+		new web3._extend.Method({
+			name: 'addBootnode',
+			call: 'admin_addBootnode',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'removeBootnode',
+			call: 'admin_removeBootnode',
+			params: 1
+		}),
 		new web3._extend.Method({
 			name: 'exportChain',
 			call: 'admin_exportChain',
@@ -190,6 +224,10 @@ web3._extend({
 			call: 'admin_importChain',
 			params: 1
 		}),
+		new web3._extend.Method({
+			name: 'importStatus',
+			call: 'admin_importStatus'
+		}),
 		new web3._extend.Method({
 			name: 'sleepBlocks',
 			call: 'admin_sleepBlocks',
@@ -227,6 +265,49 @@ web3._extend({
 			name: 'stopWS',
 			call: 'admin_stopWS'
 		}),
+		new web3._extend.Method({
+			name: 'setLogLevel',
+			call: 'admin_setLogLevel',
+			params: 2,
+			inputFormatter: [null, null]
+		}),
+		new web3._extend.Method({
+			name: 'getLogConfig',
+			call: 'admin_getLogConfig'
+		}),
+		new web3._extend.Method({
+			name: 'closeWSConnection',
+			call: 'admin_closeWSConnection',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'exportAuthIndex',
+			call: 'admin_exportAuthIndex',
+			params: 3,
+			inputFormatter: [null, null, web3._extend.formatters.inputBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'startAuthBackfill',
+			call: 'admin_startAuthBackfill',
+			params: 1,
+			inputFormatter: [web3._extend.utils.fromDecimal]
+		}),
+		new web3._extend.Method({
+			name: 'stopAuthBackfill',
+			call: 'admin_stopAuthBackfill',
+			params: 0
+		}),
+		new web3._extend.Method({
+			name: 'nextOrderId',
+			call: 'admin_nextOrderId',
+			params: 1,
+			inputFormatter: [null]
+		}),
+		new web3._extend.Method({
+			name: 'txpoolAuthSummary',
+			call: 'admin_txpoolAuthSummary',
+			params: 0
+		}),
 	],
 	properties: [
 		new web3._extend.Property({
@@ -237,10 +318,18 @@ web3._extend({
 			name: 'peers',
 			getter: 'admin_peers'
 		}),
+		new web3._extend.Property({
+			name: 'bootnodes',
+			getter: 'admin_listBootnodes'
+		}),
 		new web3._extend.Property({
 			name: 'datadir',
 			getter: 'admin_datadir'
 		}),
+		new web3._extend.Property({
+			name: 'wsConnections',
+			getter: 'admin_wsConnections'
+		}),
 	]
 });
 `
@@ -271,6 +360,16 @@ web3._extend({
 			call: 'debug_getBlockRlp',
 			params: 1
 		}),
+		new web3._extend.Method({
+			name: 'getRawHeader',
+			call: 'debug_getRawHeader',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'getRawBlock',
+			call: 'debug_getRawBlock',
+			params: 1
+		}),
 		new web3._extend.Method({
 			name: 'getRawReceipts',
 			call: 'debug_getRawReceipts',
@@ -445,6 +544,22 @@ web3._extend({
 			params: 2,
 			inputFormatter: [null, null]
 		}),
+		new web3._extend.Method({
+			name: 'startStandardTraceBlockToFile',
+			call: 'debug_startStandardTraceBlockToFile',
+			params: 2,
+			inputFormatter: [null, null]
+		}),
+		new web3._extend.Method({
+			name: 'standardTraceProgress',
+			call: 'debug_standardTraceProgress',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'cancelStandardTrace',
+			call: 'debug_cancelStandardTrace',
+			params: 1
+		}),
 		new web3._extend.Method({
 			name: 'traceBlockByNumber',
 			call: 'debug_traceBlockByNumber',
@@ -469,17 +584,91 @@ web3._extend({
 			params: 3,
 			inputFormatter: [null, null, null]
 		}),
+		new web3._extend.Method({
+			name: 'traceCallMany',
+			call: 'debug_traceCallMany',
+			params: 3,
+			inputFormatter: [null, null, null]
+		}),
 		new web3._extend.Method({
 			name: 'preimage',
 			call: 'debug_preimage',
 			params: 1,
 			inputFormatter: [null]
 		}),
+		new web3._extend.Method({
+			name: 'setPreimageRecording',
+			call: 'debug_setPreimageRecording',
+			params: 1,
+		}),
+		new web3._extend.Method({
+			name: 'preimageRecording',
+			call: 'debug_preimageRecording',
+		}),
 		new web3._extend.Method({
 			name: 'getBadBlocks',
 			call: 'debug_getBadBlocks',
 			params: 0,
 		}),
+		new web3._extend.Method({
+			name: 'getStateDiff',
+			call: 'debug_getStateDiff',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputDefaultBlockNumberFormatter],
+		}),
+		new web3._extend.Method({
+			name: 'txIndexStatus',
+			call: 'debug_txIndexStatus',
+		}),
+		new web3._extend.Method({
+			name: 'setTxIndexTail',
+			call: 'debug_setTxIndexTail',
+			params: 1,
+			inputFormatter: [web3._extend.utils.fromDecimal],
+		}),
+		new web3._extend.Method({
+			name: 'setTxLookupLimit',
+			call: 'debug_setTxLookupLimit',
+			params: 1,
+			inputFormatter: [web3._extend.utils.fromDecimal],
+		}),
+		new web3._extend.Method({
+			name: 'pauseTxIndexing',
+			call: 'debug_pauseTxIndexing',
+		}),
+		new web3._extend.Method({
+			name: 'resumeTxIndexing',
+			call: 'debug_resumeTxIndexing',
+		}),
+		new web3._extend.Method({
+			name: 'executionWitness',
+			call: 'debug_executionWitness',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputDefaultBlockNumberFormatter],
+		}),
+		new web3._extend.Method({
+			name: 'getReorgHistory',
+			call: 'debug_getReorgHistory',
+			params: 1,
+		}),
+		new web3._extend.Method({
+			name: 'snapshotStatus',
+			call: 'debug_snapshotStatus',
+		}),
+		new web3._extend.Method({
+			name: 'pauseSnapshotGeneration',
+			call: 'debug_pauseSnapshotGeneration',
+		}),
+		new web3._extend.Method({
+			name: 'resumeSnapshotGeneration',
+			call: 'debug_resumeSnapshotGeneration',
+		}),
+		new web3._extend.Method({
+			name: 'setSnapshotGenerationRate',
+			call: 'debug_setSnapshotGenerationRate',
+			params: 1,
+			inputFormatter: [web3._extend.utils.fromDecimal],
+		}),
 		new web3._extend.Method({
 			name: 'storageRangeAt',
 			call: 'debug_storageRangeAt',
@@ -523,6 +712,11 @@ web3._extend({
 			call: 'debug_dbAncients',
 			params: 0
 		}),
+		new web3._extend.Method({
+			name: 'systemCallStats',
+			call: 'debug_systemCallStats',
+			params: 0
+		}),
 	],
 	properties: []
 });
@@ -574,6 +768,17 @@ web3._extend({
 			params: 1,
 			inputFormatter: [web3._extend.formatters.inputTransactionFormatter]
 		}),
+		new web3._extend.Method({
+			name: 'getFeeSplit',
+			call: 'eth_getFeeSplit',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'getRootHash',
+			call: 'eth_getRootHash',
+			params: 2
+		}),
 		new web3._extend.Method({
 			name: 'getHeaderByNumber',
 			call: 'eth_getHeaderByNumber',
@@ -610,6 +815,18 @@ web3._extend({
 			params: 2,
 			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter, web3._extend.utils.toHex]
 		}),
+		new web3._extend.Method({
+			name: 'getTransactionReceiptsByBlock',
+			call: 'eth_getTransactionReceiptsByBlock',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'simulateV1',
+			call: 'eth_simulateV1',
+			params: 2,
+			inputFormatter: [null, web3._extend.formatters.inputBlockNumberFormatter]
+		}),
 		new web3._extend.Method({
 			name: 'getProof',
 			call: 'eth_getProof',
@@ -619,8 +836,8 @@ web3._extend({
 		new web3._extend.Method({
 			name: 'createAccessList',
 			call: 'eth_createAccessList',
-			params: 2,
-			inputFormatter: [null, web3._extend.formatters.inputBlockNumberFormatter],
+			params: 3,
+			inputFormatter: [null, web3._extend.formatters.inputBlockNumberFormatter, null],
 		}),
 		new web3._extend.Method({
 			name: 'feeHistory',
@@ -633,6 +850,12 @@ web3._extend({
 			call: 'eth_getLogs',
 			params: 1,
 		}),
+		new web3._extend.Method({
+			name: 'getLogsPage',
+			call: 'eth_getLogsPage',
+			params: 2,
+			inputFormatter: [null, null]
+		}),
 	],
 	properties: [
 		new web3._extend.Property({
@@ -905,3 +1128,123 @@ web3._extend({
 	]
 });
 `
+
+const CtJs = `
+web3._extend({
+	property: 'ct',
+	methods: [
+		new web3._extend.Method({
+			name: 'getAuth',
+			call: 'ct_getAuth',
+			params: 3,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter, web3._extend.formatters.inputBlockNumberFormatter, null]
+		}),
+		new web3._extend.Method({
+			name: 'getAuthBatch',
+			call: 'ct_getAuthBatch',
+			params: 3,
+			inputFormatter: [null, web3._extend.formatters.inputBlockNumberFormatter, null]
+		}),
+		new web3._extend.Method({
+			name: 'isWhitelisted',
+			call: 'ct_isWhitelisted',
+			params: 3,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter, web3._extend.formatters.inputBlockNumberFormatter, null]
+		}),
+		new web3._extend.Method({
+			name: 'getWhitelist',
+			call: 'ct_getWhitelist',
+			params: 2,
+			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter, null]
+		}),
+		new web3._extend.Method({
+			name: 'authProof',
+			call: 'ct_authProof',
+			params: 3,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter, web3._extend.formatters.inputBlockNumberFormatter, null]
+		}),
+		new web3._extend.Method({
+			name: 'authRevision',
+			call: 'ct_authRevision',
+			params: 1,
+			inputFormatter: [null]
+		}),
+		new web3._extend.Method({
+			name: 'submitAuth',
+			call: 'ct_submitAuth',
+			params: 3,
+			inputFormatter: [null, web3._extend.utils.fromDecimal, null]
+		}),
+		new web3._extend.Method({
+			name: 'getAuthValidity',
+			call: 'ct_getAuthValidity',
+			params: 2,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter, null]
+		}),
+		new web3._extend.Method({
+			name: 'getAuthHistory',
+			call: 'ct_getAuthHistory',
+			params: 3,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter, web3._extend.formatters.inputBlockNumberFormatter, web3._extend.formatters.inputBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'gasPriceStats',
+			call: 'ct_gasPriceStats',
+			params: 2,
+			inputFormatter: [null, web3._extend.formatters.inputBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'getBlockByNumber',
+			call: 'ct_getBlockByNumber',
+			params: 2,
+			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter, function (val) { return !!val; }]
+		}),
+		new web3._extend.Method({
+			name: 'getBlockByHash',
+			call: 'ct_getBlockByHash',
+			params: 2,
+			inputFormatter: [null, function (val) { return !!val; }]
+		}),
+		new web3._extend.Method({
+			name: 'newAccount',
+			call: 'ct_newAccount',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'importRawKey',
+			call: 'ct_importRawKey',
+			params: 2
+		}),
+		new web3._extend.Method({
+			name: 'signTypedData',
+			call: 'ct_signTypedData',
+			params: 3,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter, null, null]
+		}),
+		new web3._extend.Method({
+			name: 'signAuthData',
+			call: 'ct_signAuthData',
+			params: 4,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter, null, null, null]
+		}),
+	],
+	properties: [
+		new web3._extend.Property({
+			name: 'authIndexStatus',
+			getter: 'ct_authIndexStatus'
+		}),
+		new web3._extend.Property({
+			name: 'accounts',
+			getter: 'ct_accounts'
+		}),
+		new web3._extend.Property({
+			name: 'networkStatus',
+			getter: 'ct_networkStatus'
+		}),
+		new web3._extend.Property({
+			name: 'forkReadiness',
+			getter: 'ct_forkReadiness'
+		}),
+	]
+});
+`
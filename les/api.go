@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/qydata/go-ctereum/common"
 	"github.com/qydata/go-ctereum/common/hexutil"
 	"github.com/qydata/go-ctereum/common/mclock"
 	vfs "github.com/qydata/go-ctereum/les/vflux/server"
@@ -67,10 +68,23 @@ func (api *LightServerAPI) ServerInfo() map[string]interface{} {
 	res["maximumCapacity"] = api.server.maxCapacity
 	_, res["totalCapacity"] = api.server.clientPool.Limits()
 	_, res["totalConnectedCapacity"] = api.server.clientPool.Active()
-	res["priorityConnectedCapacity"] = 0 //TODO connect when token sale module is added
+	res["priorityConnectedCapacity"] = api.server.priorityCapacity()
+	res["connectedClients"] = len(api.server.peers.ids())
+	if api.server.validators != nil {
+		res["validatorClients"] = len(api.server.validators.backedClients())
+	}
 	return res
 }
 
+// ValidatorClients returns the clients granted a priority by the staked
+// validators backing them.
+func (api *LightServerAPI) ValidatorClients() (map[common.Address]enode.ID, error) {
+	if api.server.validators == nil {
+		return nil, errValidatorPriorityDisabled
+	}
+	return api.server.validators.backedClients(), nil
+}
+
 // ClientInfo returns information about clients listed in the ids list or matching the given tags
 func (api *LightServerAPI) ClientInfo(nodes []string) map[enode.ID]map[string]interface{} {
 	var ids []enode.ID
@@ -134,6 +148,11 @@ func (api *LightServerAPI) clientInfo(peer *clientPeer, balance vfs.ReadOnlyBala
 		info["connectionTime"] = float64(mclock.Now()-peer.connectedAt) / float64(time.Second)
 		info["capacity"] = peer.getCapacity()
 		info["pricing/negBalance"] = nb
+		info["frozen"] = peer.isFrozen()
+		info["servedRequests"], info["servedCost"] = peer.servedStats()
+		if peer.fcClient != nil {
+			info["flowControl/bufferValue"], info["flowControl/bufferLimit"] = peer.fcClient.BufferStatus()
+		}
 	}
 	return info
 }
@@ -26,6 +26,7 @@ import (
 	"github.com/qydata/go-ctereum/accounts"
 	"github.com/qydata/go-ctereum/common"
 	"github.com/qydata/go-ctereum/consensus"
+	"github.com/qydata/go-ctereum/contracts/authcontroller"
 	"github.com/qydata/go-ctereum/core"
 	"github.com/qydata/go-ctereum/core/bloombits"
 	"github.com/qydata/go-ctereum/core/rawdb"
@@ -224,6 +225,10 @@ func (b *LesApiBackend) TxPoolContentFrom(addr common.Address) (types.Transactio
 	return b.eth.txPool.ContentFrom(addr)
 }
 
+func (b *LesApiBackend) TxPoolAuthSummary() (*core.TxPoolAuthSummary, error) {
+	return nil, core.ErrAuthCheckerUnavailable
+}
+
 func (b *LesApiBackend) SubscribeNewTxsEvent(ch chan<- core.NewTxsEvent) event.Subscription {
 	return b.eth.txPool.SubscribeNewTxsEvent(ch)
 }
@@ -236,6 +241,10 @@ func (b *LesApiBackend) SubscribeChainHeadEvent(ch chan<- core.ChainHeadEvent) e
 	return b.eth.blockchain.SubscribeChainHeadEvent(ch)
 }
 
+func (b *LesApiBackend) SubscribeChain2HeadEvent(ch chan<- core.Chain2HeadEvent) event.Subscription {
+	return b.eth.blockchain.SubscribeChain2HeadEvent(ch)
+}
+
 func (b *LesApiBackend) SubscribeChainSideEvent(ch chan<- core.ChainSideEvent) event.Subscription {
 	return b.eth.blockchain.SubscribeChainSideEvent(ch)
 }
@@ -299,6 +308,10 @@ func (b *LesApiBackend) RPCTxFeeCap() float64 {
 	return b.eth.config.RPCTxFeeCap
 }
 
+func (b *LesApiBackend) AuthRegistry() authcontroller.Registry {
+	return b.eth.config.AuthRegistry
+}
+
 func (b *LesApiBackend) BloomStatus() (uint64, uint64) {
 	if b.eth.bloomIndexer == nil {
 		return 0, 0
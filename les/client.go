@@ -31,6 +31,7 @@ import (
 	"github.com/qydata/go-ctereum/core/bloombits"
 	"github.com/qydata/go-ctereum/core/rawdb"
 	"github.com/qydata/go-ctereum/core/types"
+	"github.com/qydata/go-ctereum/eth/authmanager"
 	"github.com/qydata/go-ctereum/eth/ethconfig"
 	"github.com/qydata/go-ctereum/eth/gasprice"
 	"github.com/qydata/go-ctereum/event"
@@ -61,6 +62,7 @@ type LightEthereum struct {
 	handler            *clientHandler
 	txPool             *light.TxPool
 	blockchain         *light.LightChain
+	authManager        *authmanager.LightManager
 	serverPool         *vfc.ServerPool
 	serverPoolIterator enode.Iterator
 	pruner             *pruner
@@ -92,10 +94,16 @@ func New(stack *node.Node, config *ethconfig.Config) (*LightEthereum, error) {
 	if err != nil {
 		return nil, err
 	}
-	chainConfig, genesisHash, genesisErr := core.SetupGenesisBlockWithOverride(chainDb, config.Genesis, config.OverrideTerminalTotalDifficulty, config.OverrideTerminalTotalDifficultyPassed)
+	chainConfig, genesisHash, genesisErr := core.SetupGenesisBlockWithOverride(chainDb, config.Genesis, config.OverrideTerminalTotalDifficulty, config.OverrideTerminalTotalDifficultyPassed, config.OverrideAuthContract, config.OverrideValidatorContract)
 	if _, isCompat := genesisErr.(*params.ConfigCompatError); genesisErr != nil && !isCompat {
 		return nil, genesisErr
 	}
+	if err := config.Validate(chainConfig); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %v", err)
+	}
+	if err := config.CheckForks(genesisHash, chainConfig); err != nil {
+		return nil, err
+	}
 	log.Info("")
 	log.Info(strings.Repeat("-", 153))
 	for _, line := range strings.Split(chainConfig.String(), "\n") {
@@ -156,7 +164,13 @@ func New(stack *node.Node, config *ethconfig.Config) (*LightEthereum, error) {
 	}
 	leth.chainReader = leth.blockchain
 	leth.txPool = light.NewTxPool(leth.chainConfig, leth.blockchain, leth.relay)
+	leth.txPool.SetAuthSenders(config.TxPool.AuthSenders, config.TxPool.AuthAllowlist)
 
+	if leth.chainConfig.AuthBlock != nil {
+		if leth.authManager, err = authmanager.NewLight(leth.blockchain, config.AuthRegistry); err != nil {
+			return nil, err
+		}
+	}
 	// Set up checkpoint oracle.
 	leth.oracle = leth.setupOracle(stack, genesisHash, config)
 
@@ -182,9 +196,9 @@ func New(stack *node.Node, config *ethconfig.Config) (*LightEthereum, error) {
 	}
 	leth.ApiBackend.gpo = gasprice.NewOracle(leth.ApiBackend, gpoParams)
 
-	leth.handler = newClientHandler(config.UltraLightServers, config.UltraLightFraction, checkpoint, leth)
+	leth.handler = newClientHandler(config.UltraLightServers, config.UltraLightFraction, config.UltraLightQuorum, checkpoint, leth)
 	if leth.handler.ulc != nil {
-		log.Warn("Ultra light client is enabled", "trustedNodes", len(leth.handler.ulc.keys), "minTrustedFraction", leth.handler.ulc.fraction)
+		log.Warn("Ultra light client is enabled", "trustedNodes", len(leth.handler.ulc.keys), "minTrustedFraction", leth.handler.ulc.fraction, "quorum", leth.handler.ulc.quorum)
 		leth.blockchain.DisableCheckFreq()
 	}
 
@@ -290,6 +304,12 @@ func (s *LightDummyAPI) Mining() bool {
 func (s *LightEthereum) APIs() []rpc.API {
 	apis := ethapi.GetAPIs(s.ApiBackend)
 	apis = append(apis, s.engine.APIs(s.BlockChain().HeaderChain())...)
+	if s.authManager != nil {
+		apis = append(apis, rpc.API{
+			Namespace: "ct",
+			Service:   authmanager.NewLightManagerAPI(s.authManager),
+		})
+	}
 	return append(apis, []rpc.API{
 		{
 			Namespace: "eth",
@@ -303,6 +323,9 @@ func (s *LightEthereum) APIs() []rpc.API {
 		}, {
 			Namespace: "les",
 			Service:   NewLightAPI(&s.lesCommons),
+		}, {
+			Namespace: "les",
+			Service:   NewValidatorPriorityAPI(s),
 		}, {
 			Namespace: "vflux",
 			Service:   s.serverPool.API(),
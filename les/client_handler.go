@@ -54,7 +54,7 @@ type clientHandler struct {
 	syncEnd   func(header *types.Header) // Hook called when the syncing is done
 }
 
-func newClientHandler(ulcServers []string, ulcFraction int, checkpoint *params.TrustedCheckpoint, backend *LightEthereum) *clientHandler {
+func newClientHandler(ulcServers []string, ulcFraction int, ulcQuorum int, checkpoint *params.TrustedCheckpoint, backend *LightEthereum) *clientHandler {
 	handler := &clientHandler{
 		forkFilter: forkid.NewFilter(backend.blockchain),
 		checkpoint: checkpoint,
@@ -62,9 +62,9 @@ func newClientHandler(ulcServers []string, ulcFraction int, checkpoint *params.T
 		closeCh:    make(chan struct{}),
 	}
 	if ulcServers != nil {
-		ulc, err := newULC(ulcServers, ulcFraction)
+		ulc, err := newULC(ulcServers, ulcFraction, ulcQuorum)
 		if err != nil {
-			log.Error("Failed to initialize ultra light client")
+			log.Error("Failed to initialize ultra light client", "err", err)
 		}
 		handler.ulc = ulc
 		log.Info("Enable ultra light client mode")
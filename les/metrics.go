@@ -72,11 +72,14 @@ var (
 	connectionTimer       = metrics.NewRegisteredTimer("les/connection/duration", nil)
 	serverConnectionGauge = metrics.NewRegisteredGauge("les/connection/server", nil)
 
-	totalCapacityGauge   = metrics.NewRegisteredGauge("les/server/totalCapacity", nil)
-	totalRechargeGauge   = metrics.NewRegisteredGauge("les/server/totalRecharge", nil)
-	blockProcessingTimer = metrics.NewRegisteredTimer("les/server/blockProcessingTime", nil)
+	totalCapacityGauge    = metrics.NewRegisteredGauge("les/server/totalCapacity", nil)
+	totalRechargeGauge    = metrics.NewRegisteredGauge("les/server/totalRecharge", nil)
+	priorityCapacityGauge = metrics.NewRegisteredGauge("les/server/priorityCapacity", nil)
+	validatorClientsGauge = metrics.NewRegisteredGauge("les/server/validatorClients", nil)
+	blockProcessingTimer  = metrics.NewRegisteredTimer("les/server/blockProcessingTime", nil)
 
 	requestServedMeter               = metrics.NewRegisteredMeter("les/server/req/avgServedTime", nil)
+	requestServedCostMeter           = metrics.NewRegisteredMeter("les/server/req/servedCost", nil)
 	requestServedTimer               = metrics.NewRegisteredTimer("les/server/req/servedTime", nil)
 	requestEstimatedMeter            = metrics.NewRegisteredMeter("les/server/req/avgEstimatedTime", nil)
 	requestEstimatedTimer            = metrics.NewRegisteredTimer("les/server/req/estimatedTime", nil)
@@ -780,6 +780,11 @@ type clientPeer struct {
 	server      bool
 	errCh       chan error
 	fcClient    *flowcontrol.ClientNode // Server side mirror token bucket.
+
+	// servedLock is used for protecting the served request accounting.
+	servedLock sync.Mutex
+	served     map[uint64]uint64 // Number of served requests by message code.
+	servedCost uint64            // Total real cost of the served requests.
 }
 
 func newClientPeer(version int, network uint64, p *p2p.Peer, rw p2p.MsgReadWriter) *clientPeer {
@@ -935,6 +940,32 @@ func (p *clientPeer) InactiveAllowance() time.Duration {
 	return 0 // will return more than zero for les/5 clients
 }
 
+// requestServed accounts a request of the given message code served to the peer
+// at the given real cost.
+func (p *clientPeer) requestServed(code uint64, cost uint64) {
+	p.servedLock.Lock()
+	defer p.servedLock.Unlock()
+
+	if p.served == nil {
+		p.served = make(map[uint64]uint64)
+	}
+	p.served[code]++
+	p.servedCost += cost
+}
+
+// servedStats returns the number of requests served to the peer by request type
+// and their total real cost.
+func (p *clientPeer) servedStats() (map[string]uint64, uint64) {
+	p.servedLock.Lock()
+	defer p.servedLock.Unlock()
+
+	served := make(map[string]uint64, len(p.served))
+	for code, count := range p.served {
+		served[Les3[code].Name] = count
+	}
+	return served, p.servedCost
+}
+
 // getCapacity returns the current capacity of the peer
 func (p *clientPeer) getCapacity() uint64 {
 	p.lock.RLock()
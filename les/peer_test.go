@@ -164,3 +164,30 @@ func TestHandshake(t *testing.T) {
 		}
 	}
 }
+
+// Tests that the requests served to a client peer are accounted by type along
+// with their total cost.
+func TestClientPeerServedStats(t *testing.T) {
+	var id enode.ID
+	rand.Read(id[:])
+	peer := newClientPeer(lpv4, NetworkId, p2p.NewPeer(id, "name", nil), nil)
+
+	if served, cost := peer.servedStats(); len(served) != 0 || cost != 0 {
+		t.Fatalf("fresh peer accounted requests: %v, cost %d", served, cost)
+	}
+	peer.requestServed(GetBlockHeadersMsg, 10)
+	peer.requestServed(GetBlockHeadersMsg, 20)
+	peer.requestServed(GetReceiptsMsg, 5)
+
+	served, cost := peer.servedStats()
+	want := map[string]uint64{
+		Les3[GetBlockHeadersMsg].Name: 2,
+		Les3[GetReceiptsMsg].Name:     1,
+	}
+	if !reflect.DeepEqual(served, want) {
+		t.Errorf("served requests mismatch: have %v, want %v", served, want)
+	}
+	if cost != 35 {
+		t.Errorf("served cost mismatch: have %d, want 35", cost)
+	}
+}
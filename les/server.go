@@ -20,8 +20,12 @@ import (
 	"crypto/ecdsa"
 	"time"
 
+	"github.com/qydata/go-ctereum/common"
 	"github.com/qydata/go-ctereum/common/mclock"
+	"github.com/qydata/go-ctereum/consensus"
+	"github.com/qydata/go-ctereum/consensus/clique"
 	"github.com/qydata/go-ctereum/core"
+	"github.com/qydata/go-ctereum/core/types"
 	"github.com/qydata/go-ctereum/eth/ethconfig"
 	"github.com/qydata/go-ctereum/ethdb"
 	"github.com/qydata/go-ctereum/les/flowcontrol"
@@ -41,7 +45,12 @@ var (
 	defaultNegFactors = vfs.PriceFactors{TimeFactor: 0, CapacityFactor: 1, RequestFactor: 1}
 )
 
-const defaultConnectedBias = time.Minute * 3
+const (
+	defaultConnectedBias = time.Minute * 3
+
+	// priorityCapacityRefresh is the interval of the priority capacity metric updates.
+	priorityCapacityRefresh = 10 * time.Second
+)
 
 type ethBackend interface {
 	ArchiveMode() bool
@@ -68,6 +77,7 @@ type LesServer struct {
 	defParams    flowcontrol.ServerParams
 	servingQueue *servingQueue
 	clientPool   *vfs.ClientPool
+	validators   *validatorPriority // Priority of the clients backed by staked validators, nil if disabled
 
 	minCapacity, maxCapacity uint64
 	threadsIdle              int // Request serving threads count when system is idle.
@@ -142,6 +152,22 @@ func NewLesServer(node *node.Node, e ethBackend, config *ethconfig.Config) (*Les
 	srv.clientPool.SetDefaultFactors(defaultPosFactors, defaultNegFactors)
 	srv.vfluxServer.Register(srv.clientPool, "les", "Ethereum light client service")
 
+	if config.LightValidatorBalance > 0 {
+		chain, engine := e.BlockChain(), e.BlockChain().Engine()
+		if beacon, ok := engine.(interface{ InnerEngine() consensus.Engine }); ok {
+			engine = beacon.InnerEngine()
+		}
+		if c, ok := engine.(*clique.Clique); ok {
+			self := enode.PubkeyToIDV4(&node.Server().PrivateKey.PublicKey)
+			srv.validators = newValidatorPriority(chain, srv.clientPool, self, config.LightValidatorBalance, func(header *types.Header, addr common.Address) (bool, error) {
+				return c.IsValidator(chain, header, addr)
+			})
+			srv.vfluxServer.Register(srv.validators, "validator", "Staked validator priority service")
+		} else {
+			log.Warn("Validator priority requires clique consensus, disabling")
+		}
+	}
+
 	checkpoint := srv.latestLocalCheckpoint()
 	if !checkpoint.Empty() {
 		log.Info("Loaded latest checkpoint", "section", checkpoint.SectionIndex, "head", checkpoint.SectionHead,
@@ -231,6 +257,19 @@ func (s *LesServer) Stop() error {
 	return nil
 }
 
+// priorityCapacity returns the total capacity assigned to the connected clients
+// with a positive balance.
+func (s *LesServer) priorityCapacity() (capacity uint64) {
+	for _, id := range s.peers.ids() {
+		if p := s.peers.peer(id); p != nil && p.balance != nil {
+			if pb, _ := p.balance.GetBalance(); pb > 0 {
+				capacity += p.getCapacity()
+			}
+		}
+	}
+	return capacity
+}
+
 // capacityManagement starts an event handler loop that updates the recharge curve of
 // the client manager and adjusts the client pool's size according to the total
 // capacity updates coming from the client manager
@@ -264,8 +303,13 @@ func (s *LesServer) capacityManagement() {
 	}
 	updateRecharge()
 
+	metricsTicker := time.NewTicker(priorityCapacityRefresh)
+	defer metricsTicker.Stop()
+
 	for {
 		select {
+		case <-metricsTicker.C:
+			priorityCapacityGauge.Update(int64(s.priorityCapacity()))
 		case busy = <-processCh:
 			if busy {
 				blockProcess = mclock.Now()
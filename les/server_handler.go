@@ -254,6 +254,8 @@ func (h *serverHandler) afterHandle(p *clientPeer, reqID, responseCount uint64,
 		h.server.costTracker.updateStats(msg.Code, reqCnt, task.servingTime, realCost)
 		// Reduce priority "balance" for the specific peer.
 		p.balance.RequestServed(realCost)
+		p.requestServed(msg.Code, realCost)
+		requestServedCostMeter.Mark(int64(realCost))
 		p.queueSend(func() {
 			if err := reply.send(bv); err != nil {
 				select {
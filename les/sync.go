@@ -87,6 +87,21 @@ func (h *clientHandler) validateCheckpoint(peer *serverPeer) error {
 	return nil
 }
 
+// syncCliqueCheckpoint retrieves the epoch header of the clique checkpoint from
+// the peer and sets it as the head of the local chain once verified against the
+// trusted header hash and signer set.
+func (h *clientHandler) syncCliqueCheckpoint(peer *serverPeer, checkpoint *params.CliqueCheckpoint) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	wrapPeer := &peerConnection{handler: h, peer: peer}
+	header, err := wrapPeer.RetrieveSingleHeaderByNumber(ctx, checkpoint.Number)
+	if err != nil {
+		return err
+	}
+	return h.backend.blockchain.SyncCliqueCheckpoint(header, checkpoint)
+}
+
 // synchronise tries to sync up our local chain with a remote peer.
 func (h *clientHandler) synchronise(peer *serverPeer) {
 	// Short circuit if the peer is nil.
@@ -99,6 +114,16 @@ func (h *clientHandler) synchronise(peer *serverPeer) {
 	if currentTd != nil && peer.Td().Cmp(currentTd) < 0 {
 		return
 	}
+	// Start from the configured clique checkpoint instead of the headers before
+	// it, if the local chain is still below it
+	if cp := h.backend.config.CliqueCheckpoint; cp != nil && latest.Number.Uint64() < cp.Number {
+		if err := h.syncCliqueCheckpoint(peer, cp); err != nil {
+			log.Debug("Failed to sync clique checkpoint", "reason", err)
+			h.removePeer(peer.id)
+			return
+		}
+		latest = h.backend.blockchain.CurrentHeader()
+	}
 	// Recap the checkpoint. The light client may be connected to several different
 	// versions of the server.
 	// (1) Old version server which can not provide stable checkpoint in the
@@ -242,7 +242,7 @@ func newTestClientHandler(backend *backends.SimulatedBackend, odr *LesOdr, index
 		eventMux:   evmux,
 		merger:     consensus.NewMerger(rawdb.NewMemoryDatabase()),
 	}
-	client.handler = newClientHandler(ulcServers, ulcFraction, nil, client)
+	client.handler = newClientHandler(ulcServers, ulcFraction, 0, nil, client)
 
 	if client.oracle != nil {
 		client.oracle.Start(backend)
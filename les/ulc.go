@@ -18,6 +18,7 @@ package les
 
 import (
 	"errors"
+	"fmt"
 
 	"github.com/qydata/go-ctereum/log"
 	"github.com/qydata/go-ctereum/p2p/enode"
@@ -26,10 +27,11 @@ import (
 type ulc struct {
 	keys     map[string]bool
 	fraction int
+	quorum   int // Number of trusted servers to accept an announcement, overriding the fraction if set
 }
 
 // newULC creates and returns an ultra light client instance.
-func newULC(servers []string, fraction int) (*ulc, error) {
+func newULC(servers []string, fraction int, quorum int) (*ulc, error) {
 	keys := make(map[string]bool)
 	for _, id := range servers {
 		node, err := enode.Parse(enode.ValidSchemes, id)
@@ -42,9 +44,13 @@ func newULC(servers []string, fraction int) (*ulc, error) {
 	if len(keys) == 0 {
 		return nil, errors.New("no trusted servers")
 	}
+	if quorum > len(keys) {
+		return nil, fmt.Errorf("quorum of %d exceeds the %d trusted servers", quorum, len(keys))
+	}
 	return &ulc{
 		keys:     keys,
 		fraction: fraction,
+		quorum:   quorum,
 	}, nil
 }
 
@@ -52,3 +58,13 @@ func newULC(servers []string, fraction int) (*ulc, error) {
 func (u *ulc) trusted(p enode.ID) bool {
 	return u.keys[p.String()]
 }
+
+// agreed returns an indicator whether the given number of trusted servers
+// announcing the same head is enough to accept it, reaching the quorum if one
+// is configured or the minimum fraction of the trusted servers otherwise.
+func (u *ulc) agreed(n int) bool {
+	if u.quorum > 0 {
+		return n >= u.quorum
+	}
+	return 100*n/len(u.keys) >= u.fraction
+}
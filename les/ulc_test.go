@@ -100,6 +100,41 @@ func testULCAnnounceThreshold(t *testing.T, protocol int) {
 	}
 }
 
+// Tests that announcements are accepted once agreed by the quorum of trusted
+// servers if configured, or by the minimum fraction of them otherwise.
+func TestULCQuorum(t *testing.T) {
+	var servers []string
+	for i := 0; i < 4; i++ {
+		key, _ := crypto.GenerateKey()
+		servers = append(servers, enode.NewV4(&key.PublicKey, net.ParseIP("127.0.0.1"), 35000+i, 35000+i).String())
+	}
+	var cases = []struct {
+		fraction int
+		quorum   int
+		agreed   int
+		expect   bool
+	}{
+		{75, 0, 2, false},
+		{75, 0, 3, true},
+		{75, 2, 2, true},
+		{75, 2, 1, false},
+		{25, 3, 2, false},
+		{25, 4, 4, true},
+	}
+	for i, c := range cases {
+		u, err := newULC(servers, c.fraction, c.quorum)
+		if err != nil {
+			t.Fatalf("case %d: failed to create ulc: %v", i, err)
+		}
+		if have := u.agreed(c.agreed); have != c.expect {
+			t.Errorf("case %d: agreement mismatch, want %v, got %v", i, c.expect, have)
+		}
+	}
+	if _, err := newULC(servers, 75, len(servers)+1); err == nil {
+		t.Errorf("quorum exceeding the trusted servers accepted")
+	}
+}
+
 func connect(server *serverHandler, serverId enode.ID, client *clientHandler, protocol int, noInitAnnounce bool) (*serverPeer, *clientPeer, error) {
 	// Create a message pipe to communicate through
 	app, net := p2p.MsgPipe()
@@ -0,0 +1,220 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package les
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/qydata/go-ctereum/accounts"
+	"github.com/qydata/go-ctereum/common"
+	"github.com/qydata/go-ctereum/common/hexutil"
+	"github.com/qydata/go-ctereum/core"
+	"github.com/qydata/go-ctereum/core/types"
+	"github.com/qydata/go-ctereum/crypto"
+	"github.com/qydata/go-ctereum/les/vflux"
+	vfs "github.com/qydata/go-ctereum/les/vflux/server"
+	"github.com/qydata/go-ctereum/log"
+	"github.com/qydata/go-ctereum/p2p/enode"
+	"github.com/qydata/go-ctereum/rlp"
+)
+
+const (
+	// validatorChallengeBlocks is the number of recent blocks whose hash is accepted
+	// as the challenge signed by the validators claiming a priority.
+	validatorChallengeBlocks = 128
+
+	// mimetypeValidatorChallenge is the mimetype of the challenges signed by the
+	// validators claiming a priority.
+	mimetypeValidatorChallenge = "application/x-les-validator-challenge"
+)
+
+var (
+	errValidatorPriorityDisabled = errors.New("validator priority is disabled")
+	errUnknownChallenge          = errors.New("unknown challenge block")
+	errStaleChallenge            = errors.New("stale challenge block")
+	errNotValidator              = errors.New("not a staked validator")
+	errValidatorRejected         = errors.New("validator priority rejected")
+)
+
+// validatorChallenge returns the data whose hash is signed by a validator to claim
+// a priority for the client on the server, binding the claim to both nodes and to
+// a recent block of the server so that it can neither be replayed nor precomputed.
+func validatorChallenge(server, client enode.ID, block common.Hash) []byte {
+	return append(append(append([]byte{}, server[:]...), client[:]...), block[:]...)
+}
+
+// validatorPriority is a vflux service granting a positive balance, and thus a
+// priority in the client pool, to the clients proving control of a staked
+// validator. It lets validators monitor the chain through light clients without
+// being crowded out of busy public servers.
+//
+// Each claim tops the balance of the client up to the configured amount, which
+// is spent as the client is served, so validators are expected to renew their
+// claims. A validator backs a single client at a time, claiming for another one
+// withdraws the balance granted to the previous one.
+type validatorPriority struct {
+	chain       *core.BlockChain
+	pool        *vfs.ClientPool
+	self        enode.ID
+	balance     uint64
+	isValidator func(header *types.Header, addr common.Address) (bool, error)
+
+	lock    sync.Mutex
+	clients map[common.Address]enode.ID // Client backed by each validator
+}
+
+// newValidatorPriority creates a validator priority service granting the given
+// balance to the clients backed by the addresses accepted by isValidator.
+func newValidatorPriority(chain *core.BlockChain, pool *vfs.ClientPool, self enode.ID, balance uint64, isValidator func(*types.Header, common.Address) (bool, error)) *validatorPriority {
+	return &validatorPriority{
+		chain:       chain,
+		pool:        pool,
+		self:        self,
+		balance:     balance,
+		isValidator: isValidator,
+		clients:     make(map[common.Address]enode.ID),
+	}
+}
+
+// Handle implements vfs.Service
+func (vp *validatorPriority) Handle(id enode.ID, address string, name string, data []byte) []byte {
+	if name != vflux.ValidatorPriorityName {
+		return nil
+	}
+	var req vflux.ValidatorPriorityReq
+	if rlp.DecodeBytes(data, &req) != nil {
+		return nil
+	}
+	balance, err := vp.claim(id, req.Block, req.Signature)
+	if err != nil {
+		log.Debug("Rejected validator priority claim", "id", id, "err", err)
+	}
+	reply, _ := rlp.EncodeToBytes(vflux.ValidatorPriorityReply(balance))
+	return reply
+}
+
+// claim verifies the validator signature over the challenge of the client and
+// tops its balance up, returning the resulting positive balance.
+func (vp *validatorPriority) claim(id enode.ID, block common.Hash, sig []byte) (uint64, error) {
+	header := vp.chain.GetHeaderByHash(block)
+	if header == nil {
+		return 0, errUnknownChallenge
+	}
+	head := vp.chain.CurrentHeader()
+	number := header.Number.Uint64()
+	if vp.chain.GetCanonicalHash(number) != block || head.Number.Uint64() > number+validatorChallengeBlocks {
+		return 0, errStaleChallenge
+	}
+	pubkey, err := crypto.SigToPub(crypto.Keccak256(validatorChallenge(vp.self, id, block)), sig)
+	if err != nil {
+		return 0, err
+	}
+	addr := crypto.PubkeyToAddress(*pubkey)
+	ok, err := vp.isValidator(head, addr)
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		return 0, errNotValidator
+	}
+	vp.lock.Lock()
+	defer vp.lock.Unlock()
+
+	if prev, ok := vp.clients[addr]; ok && prev != id {
+		vp.pool.BalanceOperation(prev, "", func(balance vfs.AtomicBalanceOperator) {
+			pos, _ := balance.GetBalance()
+			if pos > vp.balance {
+				pos = vp.balance
+			}
+			balance.AddBalance(-int64(pos))
+		})
+	}
+	vp.clients[addr] = id
+	validatorClientsGauge.Update(int64(len(vp.clients)))
+
+	var pos uint64
+	vp.pool.BalanceOperation(id, "", func(balance vfs.AtomicBalanceOperator) {
+		if pos, _ = balance.GetBalance(); pos < vp.balance {
+			_, pos, _ = balance.AddBalance(int64(vp.balance - pos))
+		}
+	})
+	log.Debug("Granted validator priority", "validator", addr, "id", id, "balance", pos)
+	return pos, nil
+}
+
+// backedClients returns the client backed by each validator.
+func (vp *validatorPriority) backedClients() map[common.Address]enode.ID {
+	vp.lock.Lock()
+	defer vp.lock.Unlock()
+
+	clients := make(map[common.Address]enode.ID, len(vp.clients))
+	for addr, id := range vp.clients {
+		clients[addr] = id
+	}
+	return clients
+}
+
+// claimValidatorPriority proves to the given server the control of the validator
+// account, which needs to be unlocked, returning the positive balance granted.
+func (s *LightEthereum) claimValidatorPriority(n *enode.Node, validator common.Address) (uint64, error) {
+	account := accounts.Account{Address: validator}
+	wallet, err := s.accountManager.Find(account)
+	if err != nil {
+		return 0, err
+	}
+	block := s.blockchain.CurrentHeader().Hash()
+	sig, err := wallet.SignData(account, mimetypeValidatorChallenge, validatorChallenge(n.ID(), s.p2pServer.Self().ID(), block))
+	if err != nil {
+		return 0, err
+	}
+	var requests vflux.Requests
+	requests.Add("validator", vflux.ValidatorPriorityName, vflux.ValidatorPriorityReq{
+		Block:     block,
+		Signature: sig,
+	})
+	var reply vflux.ValidatorPriorityReply
+	if err := s.VfluxRequest(n, requests).Get(0, &reply); err != nil {
+		return 0, err
+	}
+	if reply == 0 {
+		return 0, errValidatorRejected
+	}
+	return uint64(reply), nil
+}
+
+// ValidatorPriorityAPI provides an API for the validators to claim a priority
+// on the light servers.
+type ValidatorPriorityAPI struct {
+	les *LightEthereum
+}
+
+// NewValidatorPriorityAPI creates a new validator priority API.
+func NewValidatorPriorityAPI(les *LightEthereum) *ValidatorPriorityAPI {
+	return &ValidatorPriorityAPI{les: les}
+}
+
+// ClaimValidatorPriority signs the challenge of the given server with the unlocked
+// validator account, returning the positive balance granted to the client.
+func (api *ValidatorPriorityAPI) ClaimValidatorPriority(server string, validator common.Address) (hexutil.Uint64, error) {
+	n, err := enode.Parse(enode.ValidSchemes, server)
+	if err != nil {
+		return 0, err
+	}
+	balance, err := api.les.claimValidatorPriority(n, validator)
+	return hexutil.Uint64(balance), err
+}
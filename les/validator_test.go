@@ -0,0 +1,124 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package les
+
+import (
+	"crypto/ecdsa"
+	"testing"
+
+	"github.com/qydata/go-ctereum/common"
+	"github.com/qydata/go-ctereum/common/mclock"
+	"github.com/qydata/go-ctereum/consensus/ethash"
+	"github.com/qydata/go-ctereum/core"
+	"github.com/qydata/go-ctereum/core/rawdb"
+	"github.com/qydata/go-ctereum/core/types"
+	"github.com/qydata/go-ctereum/core/vm"
+	"github.com/qydata/go-ctereum/crypto"
+	"github.com/qydata/go-ctereum/les/vflux"
+	vfs "github.com/qydata/go-ctereum/les/vflux/server"
+	"github.com/qydata/go-ctereum/p2p/enode"
+	"github.com/qydata/go-ctereum/params"
+	"github.com/qydata/go-ctereum/rlp"
+)
+
+// Tests that the clients proving control of a staked validator with a signature
+// over a recent block are granted a balance, moved along with the validator.
+func TestValidatorPriority(t *testing.T) {
+	var (
+		db    = rawdb.NewMemoryDatabase()
+		gspec = &core.Genesis{Config: params.TestChainConfig}
+	)
+	genesis := gspec.MustCommit(db)
+	chain, _ := core.NewBlockChain(db, nil, gspec.Config, ethash.NewFaker(), vm.Config{}, nil, nil)
+	defer chain.Stop()
+
+	blocks, _ := core.GenerateChain(gspec.Config, genesis, ethash.NewFaker(), db, validatorChallengeBlocks+10, nil)
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	var clock mclock.Simulated
+	pool := vfs.NewClientPool(rawdb.NewMemoryDatabase(), 1, defaultConnectedBias, &clock, func() bool { return true })
+	pool.Start()
+	defer pool.Stop()
+
+	validatorKey, _ := crypto.GenerateKey()
+	otherKey, _ := crypto.GenerateKey()
+	validator := crypto.PubkeyToAddress(validatorKey.PublicKey)
+
+	// Balances below the persistence threshold are dropped, grant well above
+	const grant = 1000000000
+
+	var (
+		self  = enode.ID{0x01}
+		peer1 = enode.ID{0x02}
+		peer2 = enode.ID{0x03}
+	)
+	vp := newValidatorPriority(chain, pool, self, grant, func(header *types.Header, addr common.Address) (bool, error) {
+		return addr == validator, nil
+	})
+	sign := func(key *ecdsa.PrivateKey, server, client enode.ID, block common.Hash) []byte {
+		sig, _ := crypto.Sign(crypto.Keccak256(validatorChallenge(server, client, block)), key)
+		return sig
+	}
+	balance := func(id enode.ID) (pos uint64) {
+		pool.BalanceOperation(id, "", func(balance vfs.AtomicBalanceOperator) {
+			pos, _ = balance.GetBalance()
+		})
+		return pos
+	}
+	recent := chain.CurrentHeader().Hash()
+
+	// Stale, unknown and foreign challenges are rejected
+	rejected := []struct {
+		sig   []byte
+		block common.Hash
+		err   error
+	}{
+		{sign(validatorKey, self, peer1, blocks[0].Hash()), blocks[0].Hash(), errStaleChallenge},
+		{sign(validatorKey, self, peer1, common.Hash{0xff}), common.Hash{0xff}, errUnknownChallenge},
+		{sign(otherKey, self, peer1, recent), recent, errNotValidator},
+		{sign(validatorKey, self, peer2, recent), recent, errNotValidator},
+		{sign(validatorKey, enode.ID{0xff}, peer1, recent), recent, errNotValidator},
+	}
+	for i, tt := range rejected {
+		if _, err := vp.claim(peer1, tt.block, tt.sig); err != tt.err {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, tt.err)
+		}
+	}
+	if have := balance(peer1); have != 0 {
+		t.Fatalf("rejected claims granted a balance: %d", have)
+	}
+	// A valid claim tops the balance up through vflux
+	req, _ := rlp.EncodeToBytes(vflux.ValidatorPriorityReq{Block: recent, Signature: sign(validatorKey, self, peer1, recent)})
+	var reply vflux.ValidatorPriorityReply
+	if err := rlp.DecodeBytes(vp.Handle(peer1, "", vflux.ValidatorPriorityName, req), &reply); err != nil {
+		t.Fatalf("failed to decode reply: %v", err)
+	}
+	if reply != grant || balance(peer1) != grant {
+		t.Fatalf("granted balance mismatch: have %d/%d, want %d", reply, balance(peer1), grant)
+	}
+	// Claiming for another client moves the balance over
+	if _, err := vp.claim(peer2, recent, sign(validatorKey, self, peer2, recent)); err != nil {
+		t.Fatalf("failed to claim priority: %v", err)
+	}
+	if balance(peer1) != 0 || balance(peer2) != grant {
+		t.Errorf("balance not moved: have %d/%d, want 0/%d", balance(peer1), balance(peer2), grant)
+	}
+	if clients := vp.backedClients(); len(clients) != 1 || clients[validator] != peer2 {
+		t.Errorf("backed clients mismatch: have %v", clients)
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"math"
 	"math/big"
 
+	"github.com/qydata/go-ctereum/common"
 	"github.com/qydata/go-ctereum/rlp"
 )
 
@@ -30,6 +31,8 @@ const (
 	MaxRequestLength    = 16 // max number of individual requests in a batch
 	CapacityQueryName   = "cq"
 	CapacityQueryMaxLen = 16
+
+	ValidatorPriorityName = "vp"
 )
 
 type (
@@ -52,6 +55,16 @@ type (
 	}
 	// CapacityQueryReq is the encoding format of the response to the capacity query
 	CapacityQueryReply []uint64
+
+	// ValidatorPriorityReq is the encoding format of the validator priority claim,
+	// signed by the validator over a recent block hash of the server
+	ValidatorPriorityReq struct {
+		Block     common.Hash
+		Signature []byte
+	}
+	// ValidatorPriorityReply is the encoding format of the response to the validator
+	// priority claim, the positive balance of the client (zero if rejected)
+	ValidatorPriorityReply uint64
 )
 
 // Add encodes and adds a new request to the batch
@@ -21,6 +21,7 @@ package light
 import (
 	"context"
 	"errors"
+	"fmt"
 	"math/big"
 	"sync"
 	"sync/atomic"
@@ -33,6 +34,7 @@ import (
 	"github.com/qydata/go-ctereum/core/rawdb"
 	"github.com/qydata/go-ctereum/core/state"
 	"github.com/qydata/go-ctereum/core/types"
+	"github.com/qydata/go-ctereum/crypto"
 	"github.com/qydata/go-ctereum/ethdb"
 	"github.com/qydata/go-ctereum/event"
 	"github.com/qydata/go-ctereum/log"
@@ -45,6 +47,9 @@ var (
 	blockCacheLimit = 256
 )
 
+// cliqueExtraVanity is the length of the vanity prefix of the clique extra-data.
+const cliqueExtraVanity = 32
+
 // LightChain represents a canonical chain that by default only handles block
 // headers, downloading block bodies and receipts on demand through an ODR
 // interface. It only does header validation during chain insertion.
@@ -57,6 +62,7 @@ type LightChain struct {
 	chainFeed     event.Feed
 	chainSideFeed event.Feed
 	chainHeadFeed event.Feed
+	chain2Feed    event.Feed
 	scope         event.SubscriptionScope
 	genesisBlock  *types.Block
 	forker        *core.ForkChoice
@@ -387,6 +393,7 @@ func (lc *LightChain) SetCanonical(header *types.Header) error {
 	block := types.NewBlockWithHeader(header)
 	lc.chainFeed.Send(core.ChainEvent{Block: block, Hash: block.Hash()})
 	lc.chainHeadFeed.Send(core.ChainHeadEvent{Block: block})
+	lc.chain2Feed.Send(core.Chain2HeadEvent{Type: core.Chain2HeadCanonicalEvent, NewChain: []*types.Block{block}})
 	log.Info("Set the chain head", "number", block.Number(), "hash", block.Hash())
 	return nil
 }
@@ -435,8 +442,10 @@ func (lc *LightChain) InsertHeaderChain(chain []*types.Header, checkFreq int) (i
 	case core.CanonStatTy:
 		lc.chainFeed.Send(core.ChainEvent{Block: block, Hash: block.Hash()})
 		lc.chainHeadFeed.Send(core.ChainHeadEvent{Block: block})
+		lc.chain2Feed.Send(core.Chain2HeadEvent{Type: core.Chain2HeadCanonicalEvent, NewChain: []*types.Block{block}})
 	case core.SideStatTy:
 		lc.chainSideFeed.Send(core.ChainSideEvent{Block: block})
+		lc.chain2Feed.Send(core.Chain2HeadEvent{Type: core.Chain2HeadForkEvent, NewChain: []*types.Block{block}})
 	}
 	return 0, err
 }
@@ -546,6 +555,77 @@ func (lc *LightChain) SyncCheckpoint(ctx context.Context, checkpoint *params.Tru
 	return false
 }
 
+// SyncCliqueCheckpoint sets the epoch header of the clique checkpoint as the
+// head of the chain if it is ahead of it, once it is verified to be the trusted
+// epoch header, to list the trusted signer set and to be sealed by one of them. Like the headers proven
+// by a CHT, the epoch header has no parent locally, so the clique engine takes
+// its signers as the starting snapshot of the headers synced on top of it.
+func (lc *LightChain) SyncCliqueCheckpoint(header *types.Header, checkpoint *params.CliqueCheckpoint) error {
+	config := lc.hc.Config().Clique
+	if config == nil {
+		return errors.New("clique checkpoint on a non-clique chain")
+	}
+	number := header.Number.Uint64()
+	if number != checkpoint.Number {
+		return fmt.Errorf("checkpoint number mismatch: have %d, want %d", number, checkpoint.Number)
+	}
+	if number == 0 || number%config.Epoch != 0 {
+		return fmt.Errorf("checkpoint #%d is not an epoch block", number)
+	}
+	if hash := header.Hash(); hash != checkpoint.Hash {
+		return fmt.Errorf("checkpoint hash mismatch: have %x, want %x", hash, checkpoint.Hash)
+	}
+	signers := header.Extra
+	if len(signers) < cliqueExtraVanity+crypto.SignatureLength {
+		return errors.New("checkpoint extra-data too short")
+	}
+	signers = signers[cliqueExtraVanity : len(signers)-crypto.SignatureLength]
+	if len(signers) == 0 || len(signers)%common.AddressLength != 0 {
+		return errors.New("invalid checkpoint signer list")
+	}
+	trusted := make([]common.Address, len(signers)/common.AddressLength)
+	for i := range trusted {
+		copy(trusted[i][:], signers[i*common.AddressLength:])
+	}
+	if hash := params.CliqueSignersHash(trusted); hash != checkpoint.SignersHash {
+		return fmt.Errorf("checkpoint signer set mismatch: have %x, want %x", hash, checkpoint.SignersHash)
+	}
+	sealer, err := lc.engine.Author(header)
+	if err != nil {
+		return err
+	}
+	sealed := false
+	for _, signer := range trusted {
+		sealed = sealed || signer == sealer
+	}
+	if !sealed {
+		return fmt.Errorf("checkpoint sealed by unauthorized signer %x", sealer)
+	}
+	lc.chainmu.Lock()
+	defer lc.chainmu.Unlock()
+
+	// Ensure the chain didn't move past the checkpoint while retrieving it
+	if lc.hc.CurrentHeader().Number.Uint64() >= number {
+		return nil
+	}
+	// The total difficulty is unknown without the preceding headers, start from
+	// a lower bound as every clique block carries a difficulty of at least one
+	td := new(big.Int).Add(lc.genesisBlock.Difficulty(), new(big.Int).SetUint64(number))
+
+	batch := lc.chainDb.NewBatch()
+	rawdb.WriteHeader(batch, header)
+	rawdb.WriteTd(batch, header.Hash(), number, td)
+	rawdb.WriteCanonicalHash(batch, header.Hash(), number)
+	rawdb.WriteHeadHeaderHash(batch, header.Hash())
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	lc.hc.SetCurrentHeader(header)
+
+	log.Info("Updated latest header based on clique checkpoint", "number", number, "hash", header.Hash(), "signers", len(trusted), "age", common.PrettyAge(time.Unix(int64(header.Time), 0)))
+	return nil
+}
+
 // LockChain locks the chain mutex for reading so that multiple canonical hashes can be
 // retrieved while it is guaranteed that they belong to the same version of the chain
 func (lc *LightChain) LockChain() {
@@ -567,6 +647,11 @@ func (lc *LightChain) SubscribeChainHeadEvent(ch chan<- core.ChainHeadEvent) eve
 	return lc.scope.Track(lc.chainHeadFeed.Subscribe(ch))
 }
 
+// SubscribeChain2HeadEvent registers a subscription of Chain2HeadEvent.
+func (lc *LightChain) SubscribeChain2HeadEvent(ch chan<- core.Chain2HeadEvent) event.Subscription {
+	return lc.scope.Track(lc.chain2Feed.Subscribe(ch))
+}
+
 // SubscribeChainSideEvent registers a subscription of ChainSideEvent.
 func (lc *LightChain) SubscribeChainSideEvent(ch chan<- core.ChainSideEvent) event.Subscription {
 	return lc.scope.Track(lc.chainSideFeed.Subscribe(ch))
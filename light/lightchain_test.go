@@ -23,10 +23,13 @@ import (
 	"testing"
 
 	"github.com/qydata/go-ctereum/common"
+	cliquepkg "github.com/qydata/go-ctereum/consensus/clique"
 	"github.com/qydata/go-ctereum/consensus/ethash"
+	"github.com/qydata/go-ctereum/consensus/misc"
 	"github.com/qydata/go-ctereum/core"
 	"github.com/qydata/go-ctereum/core/rawdb"
 	"github.com/qydata/go-ctereum/core/types"
+	"github.com/qydata/go-ctereum/crypto"
 	"github.com/qydata/go-ctereum/ethdb"
 	"github.com/qydata/go-ctereum/params"
 )
@@ -355,3 +358,91 @@ func TestReorgBadHeaderHashes(t *testing.T) {
 		t.Errorf("last header hash mismatch: have: %x, want %x", ncm.CurrentHeader().Hash(), headers[2].Hash())
 	}
 }
+
+// Tests that the light chain starts from a clique epoch header listing the
+// trusted signer set, and syncs the headers on top of it without the ones
+// preceding it.
+func TestSyncCliqueCheckpoint(t *testing.T) {
+	var (
+		key, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		signer = crypto.PubkeyToAddress(key.PublicKey)
+		config = *params.AllCliqueProtocolChanges
+		clique = params.CliqueConfig{Period: 1, Epoch: 4}
+	)
+	config.Clique = &clique
+
+	gspec := core.Genesis{Config: &config, ExtraData: make([]byte, 32+common.AddressLength+crypto.SignatureLength)}
+	copy(gspec.ExtraData[32:], signer[:])
+	genesis := gspec.ToBlock()
+
+	// Seal a header chain of two epochs by the single signer
+	headers := make([]*types.Header, 8)
+	parent := genesis.Header()
+	for i := range headers {
+		header := &types.Header{
+			ParentHash:  parent.Hash(),
+			UncleHash:   types.EmptyUncleHash,
+			Root:        parent.Root,
+			TxHash:      types.EmptyRootHash,
+			ReceiptHash: types.EmptyRootHash,
+			Difficulty:  big.NewInt(2),
+			Number:      big.NewInt(int64(i + 1)),
+			GasLimit:    parent.GasLimit,
+			Time:        parent.Time + 1,
+			Extra:       make([]byte, 32+crypto.SignatureLength),
+		}
+		if config.IsLondon(header.Number) {
+			header.BaseFee = misc.CalcBaseFee(&config, parent)
+		}
+		if header.Number.Uint64()%clique.Epoch == 0 {
+			header.Extra = append(header.Extra[:32], append(signer.Bytes(), header.Extra[32:]...)...)
+		}
+		sig, _ := crypto.Sign(cliquepkg.SealHash(header).Bytes(), key)
+		copy(header.Extra[len(header.Extra)-crypto.SignatureLength:], sig)
+
+		headers[i], parent = header, header
+	}
+	db := rawdb.NewMemoryDatabase()
+	gspec.MustCommit(db)
+	lc, err := NewLightChain(&dummyOdr{db: db, indexerConfig: TestClientIndexerConfig}, &config, cliquepkg.New(&clique, db, nil), nil)
+	if err != nil {
+		t.Fatalf("failed to create light chain: %v", err)
+	}
+	trusted := &params.CliqueCheckpoint{Number: 4, Hash: headers[3].Hash(), SignersHash: params.CliqueSignersHash([]common.Address{signer})}
+
+	// A conflicting epoch header sealed by the same signer
+	forged := types.CopyHeader(headers[3])
+	forged.Time++
+	sig, _ := crypto.Sign(cliquepkg.SealHash(forged).Bytes(), key)
+	copy(forged.Extra[len(forged.Extra)-crypto.SignatureLength:], sig)
+
+	// Checkpoints not matching the trusted ones are rejected
+	if err := lc.SyncCliqueCheckpoint(headers[2], &params.CliqueCheckpoint{Number: 3, Hash: headers[2].Hash(), SignersHash: trusted.SignersHash}); err == nil {
+		t.Errorf("non-epoch checkpoint accepted")
+	}
+	if err := lc.SyncCliqueCheckpoint(headers[3], &params.CliqueCheckpoint{Number: 4, Hash: trusted.Hash, SignersHash: common.Hash{0x01}}); err == nil {
+		t.Errorf("checkpoint with untrusted signers accepted")
+	}
+	if err := lc.SyncCliqueCheckpoint(forged, trusted); err == nil {
+		t.Errorf("checkpoint with untrusted hash accepted")
+	}
+	if head := lc.CurrentHeader().Number.Uint64(); head != 0 {
+		t.Fatalf("head moved by a rejected checkpoint: %d", head)
+	}
+	// The trusted checkpoint becomes the head and the chain syncs on top of it
+	if err := lc.SyncCliqueCheckpoint(headers[3], trusted); err != nil {
+		t.Fatalf("failed to sync checkpoint: %v", err)
+	}
+	if head := lc.CurrentHeader(); head.Hash() != headers[3].Hash() {
+		t.Fatalf("head mismatch: have #%d, want #4", head.Number)
+	}
+	if _, err := lc.InsertHeaderChain(headers[4:], 1); err != nil {
+		t.Fatalf("failed to insert headers past the checkpoint: %v", err)
+	}
+	if head := lc.CurrentHeader(); head.Hash() != headers[7].Hash() {
+		t.Errorf("head mismatch: have #%d, want #8", head.Number)
+	}
+	if lc.GetHeaderByNumber(3) != nil {
+		t.Errorf("header preceding the checkpoint synced")
+	}
+}
@@ -24,10 +24,12 @@ import (
 	"time"
 
 	"github.com/qydata/go-ctereum/common"
+	"github.com/qydata/go-ctereum/contracts/authcontroller"
 	"github.com/qydata/go-ctereum/core"
 	"github.com/qydata/go-ctereum/core/rawdb"
 	"github.com/qydata/go-ctereum/core/state"
 	"github.com/qydata/go-ctereum/core/types"
+	"github.com/qydata/go-ctereum/core/vm"
 	"github.com/qydata/go-ctereum/ethdb"
 	"github.com/qydata/go-ctereum/event"
 	"github.com/qydata/go-ctereum/log"
@@ -69,6 +71,9 @@ type TxPool struct {
 
 	istanbul bool // Fork indicator whether we are in the istanbul stage.
 	eip2718  bool // Fork indicator whether we are in the eip2718 stage.
+
+	authSenders   bool                        // Whether to reject transactions from senders not authenticated in the auth contract
+	authAllowlist map[common.Address]struct{} // Senders exempt from the authentication check
 }
 
 // TxRelayBackend provides an interface to the mechanism that forwards transactions
@@ -113,6 +118,20 @@ func NewTxPool(config *params.ChainConfig, chain *LightChain, relay TxRelayBacke
 	return pool
 }
 
+// SetAuthSenders configures the pre-validation of the sender authentication
+// enforced by the servers once ImplAuth is active, mirroring the AuthSenders
+// and AuthAllowlist options of their transaction pools.
+func (pool *TxPool) SetAuthSenders(enabled bool, allowlist []common.Address) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	pool.authSenders = enabled
+	pool.authAllowlist = make(map[common.Address]struct{}, len(allowlist))
+	for _, addr := range allowlist {
+		pool.authAllowlist[addr] = struct{}{}
+	}
+}
+
 // currentState returns the light state of the current head header
 func (pool *TxPool) currentState(ctx context.Context) *state.StateDB {
 	return NewState(ctx, pool.chain.CurrentHeader(), pool.odr)
@@ -360,7 +379,11 @@ func (pool *TxPool) validateTx(ctx context.Context, tx *types.Transaction) error
 	}
 	// Last but not least check for nonce errors
 	currentState := pool.currentState(ctx)
-	if n := currentState.GetNonce(from); n > tx.Nonce() {
+	n := currentState.GetNonce(from)
+	if err := currentState.Error(); err != nil {
+		return err
+	}
+	if n > tx.Nonce() {
 		return core.ErrNonceTooLow
 	}
 
@@ -392,9 +415,50 @@ func (pool *TxPool) validateTx(ctx context.Context, tx *types.Transaction) error
 	if tx.Gas() < gas {
 		return core.ErrIntrinsicGas
 	}
+	// Pre-validate the authentication the servers would enforce against the
+	// retrieved state, rather than relaying transactions they silently drop
+	if err := pool.validateAuth(currentState, header, tx, from); err != nil {
+		return err
+	}
 	return currentState.Error()
 }
 
+// validateAuth checks the auth proof of authenticated transactions, the sender
+// authentication if configured and the deployment restriction as of the given
+// head. Failed state retrievals take precedence over the rejections, which may
+// stem from the missing state.
+func (pool *TxPool) validateAuth(statedb *state.StateDB, header *types.Header, tx *types.Transaction, from common.Address) error {
+	var (
+		next = new(big.Int).Add(header.Number, common.Big1)
+		evm  = vm.NewEVM(core.NewEVMBlockContext(header, pool.chain, nil), vm.TxContext{}, statedb, pool.config, vm.Config{})
+		err  error
+	)
+	if tx.Type() == types.AuthTxType {
+		var proof *types.AuthProof
+		if proof, err = types.DecodeAuthProof(tx.AuthProof()); err == nil {
+			err = core.VerifyAuthProof(pool.config, statedb, next, uint64(time.Now().Unix()), from, proof)
+		}
+		if err == nil {
+			var auths []bool
+			if auths, err = authcontroller.AuthsMulti(evm, pool.config.AuthContractAt(next), []common.Address{from}); err == nil && !auths[0] {
+				err = fmt.Errorf("%w: address %v", core.ErrAuthProofRevoked, from.Hex())
+			}
+		}
+	} else if _, ok := pool.authAllowlist[from]; pool.authSenders && !ok && pool.config.IsImplAuth(header.Number) {
+		var auths []bool
+		if auths, err = authcontroller.AuthsMulti(evm, pool.config.AuthContractAt(header.Number), []common.Address{from}); err == nil && !auths[0] {
+			err = core.ErrSenderNotAuthenticated
+		}
+	}
+	if err == nil && tx.To() == nil && pool.config.IsDeployAuth(next) && !evm.CanDeploy(from) {
+		err = fmt.Errorf("%w: address %v", core.ErrDeployNotAuthorized, from.Hex())
+	}
+	if statedb.Error() != nil {
+		return statedb.Error()
+	}
+	return err
+}
+
 // add validates a new transaction and sets its state pending if processable.
 // It also updates the locally stored nonce if necessary.
 func (pool *TxPool) add(ctx context.Context, tx *types.Transaction) error {
@@ -18,6 +18,8 @@ package light
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"errors"
 	"math"
 	"math/big"
 	"testing"
@@ -144,3 +146,89 @@ func TestTxPool(t *testing.T) {
 		}
 	}
 }
+
+// Tests that the light pool pre-validates the sender authentication and the
+// deployment restriction against the retrieved state, surfacing retrieval
+// failures rather than rejections.
+func TestTxPoolAuth(t *testing.T) {
+	authAddr := common.HexToAddress("0xa0")
+
+	config := *params.TestChainConfig
+	config.AuthBlock = big.NewInt(0)
+	config.AuthContract = authAddr
+	config.DeployAuth = &params.DeployAuthConfig{Block: big.NewInt(0)}
+
+	var (
+		sdb   = rawdb.NewMemoryDatabase()
+		ldb   = rawdb.NewMemoryDatabase()
+		gspec = core.Genesis{
+			Config: &config,
+			Alloc: core.GenesisAlloc{
+				testBankAddress: {Balance: testBankFunds},
+				acc1Addr:        {Balance: testBankFunds},
+				// Returns the flag stored for the address of any 36 byte call
+				authAddr: {
+					Code:    common.FromHex("600435546000526020" + "6000f3"),
+					Balance: new(big.Int),
+					Storage: map[common.Hash]common.Hash{common.BytesToHash(testBankAddress.Bytes()): common.BytesToHash([]byte{1})},
+				},
+			},
+			BaseFee: big.NewInt(params.InitialBaseFee),
+		}
+	)
+	genesis := gspec.MustCommit(sdb)
+	gspec.MustCommit(ldb)
+
+	// Move past the genesis, whose state is known locally
+	blocks, _ := core.GenerateChain(&config, genesis, ethash.NewFaker(), sdb, 1, nil)
+
+	odr := &testOdr{sdb: sdb, ldb: ldb, indexerConfig: TestClientIndexerConfig}
+	relay := &testTxRelay{
+		send:    make(chan int, 10),
+		discard: make(chan int, 10),
+		mined:   make(chan int, 10),
+	}
+	lightchain, _ := NewLightChain(odr, &config, ethash.NewFullFaker(), nil)
+	if _, err := lightchain.InsertHeaderChain([]*types.Header{blocks[0].Header()}, 1); err != nil {
+		t.Fatalf("failed to insert header: %v", err)
+	}
+	pool := NewTxPool(&config, lightchain, relay)
+	defer pool.Stop()
+	pool.SetAuthSenders(true, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	call := func(nonce uint64, key *ecdsa.PrivateKey) *types.Transaction {
+		tx, _ := types.SignTx(types.NewTransaction(nonce, acc2Addr, big.NewInt(1), params.TxGas, big.NewInt(params.InitialBaseFee), nil), types.HomesteadSigner{}, key)
+		return tx
+	}
+	create := func(nonce uint64, key *ecdsa.PrivateKey) *types.Transaction {
+		tx, _ := types.SignTx(types.NewContractCreation(nonce, new(big.Int), 100000, big.NewInt(params.InitialBaseFee), nil), types.HomesteadSigner{}, key)
+		return tx
+	}
+	odr.disable = true
+	if err := pool.Add(ctx, call(0, acc1Key)); !errors.Is(err, ErrOdrDisabled) {
+		t.Errorf("retrieval error mismatch: have %v, want %v", err, ErrOdrDisabled)
+	}
+	odr.disable = false
+
+	if err := pool.Add(ctx, call(0, testBankKey)); err != nil {
+		t.Errorf("failed to add authenticated transaction: %v", err)
+	}
+	if err := pool.Add(ctx, call(0, acc1Key)); !errors.Is(err, core.ErrSenderNotAuthenticated) {
+		t.Errorf("unauthenticated transaction error mismatch: have %v, want %v", err, core.ErrSenderNotAuthenticated)
+	}
+	if err := pool.Add(ctx, create(1, testBankKey)); err != nil {
+		t.Errorf("failed to add authorized deployment: %v", err)
+	}
+	// Without sender authentication only the deployments are restricted
+	pool.SetAuthSenders(false, nil)
+
+	if err := pool.Add(ctx, create(0, acc1Key)); !errors.Is(err, core.ErrDeployNotAuthorized) {
+		t.Errorf("unauthorized deployment error mismatch: have %v, want %v", err, core.ErrDeployNotAuthorized)
+	}
+	if err := pool.Add(ctx, call(0, acc1Key)); err != nil {
+		t.Errorf("failed to add call from unauthenticated sender: %v", err)
+	}
+}
@@ -3,6 +3,7 @@ package log
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math/big"
 	"reflect"
@@ -248,6 +249,31 @@ func JSONFormatOrderedEx(pretty, lineSeparated bool) Format {
 // records will be pretty-printed. If lineSeparated is true, records
 // will be logged with a new line between each record.
 func JSONFormatEx(pretty, lineSeparated bool) Format {
+	return jsonFormat(pretty, lineSeparated, false)
+}
+
+// StructuredJSONFormat formats log records as line separated JSON objects like
+// JSONFormat, adding the module the record was logged from (e.g.
+// "consensus/clique") along with the code and name of the first coded error of
+// the record context, so that log pipelines can filter and alert on them.
+func StructuredJSONFormat() Format {
+	return jsonFormat(false, true, true)
+}
+
+// codedError is an error carrying a machine-readable code, optionally along with
+// a symbolic name as its data.
+type codedError interface {
+	error
+	ErrorCode() int
+}
+
+type dataError interface {
+	ErrorData() interface{}
+}
+
+// jsonFormat formats log records as JSON objects, with the module and error
+// codes of the records if structured is true.
+func jsonFormat(pretty, lineSeparated, structured bool) Format {
 	jsonMarshal := json.Marshal
 	if pretty {
 		jsonMarshal = func(v interface{}) ([]byte, error) {
@@ -268,8 +294,22 @@ func JSONFormatEx(pretty, lineSeparated bool) Format {
 				props[errorKey] = fmt.Sprintf("%+v is not a string key", r.Ctx[i])
 			}
 			props[k] = formatJSONValue(r.Ctx[i+1])
-		}
 
+			if err, ok := r.Ctx[i+1].(error); ok && structured && props[errCodeKey] == nil {
+				var coded codedError
+				if errors.As(err, &coded) {
+					props[errCodeKey] = coded.ErrorCode()
+					if data, ok := coded.(dataError); ok {
+						props[errNameKey] = data.ErrorData()
+					}
+				}
+			}
+		}
+		if structured {
+			if module := recordModule(r); module != "" {
+				props[moduleKey] = module
+			}
+		}
 		b, err := jsonMarshal(props)
 		if err != nil {
 			b, _ = jsonMarshal(map[string]string{
@@ -286,6 +326,25 @@ func JSONFormatEx(pretty, lineSeparated bool) Format {
 	})
 }
 
+// recordModule returns the package the record was logged from, trimmed like the
+// locations of the terminal format.
+func recordModule(r *Record) string {
+	fn := r.Call.Frame().Function
+	if fn == "" {
+		return ""
+	}
+	// Cut the function off the package path, the first dot after the last slash
+	// separating them
+	slash := strings.LastIndexByte(fn, '/') + 1
+	if dot := strings.IndexByte(fn[slash:], '.'); dot >= 0 {
+		fn = fn[:slash+dot]
+	}
+	for _, prefix := range locationTrims {
+		fn = strings.TrimPrefix(fn, prefix)
+	}
+	return fn
+}
+
 func formatShared(value interface{}) (result interface{}) {
 	defer func() {
 		if err := recover(); err != nil {
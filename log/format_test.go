@@ -1,6 +1,9 @@
 package log
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
 	"math"
 	"math/big"
 	"math/rand"
@@ -80,6 +83,39 @@ func TestPrettyBigInt(t *testing.T) {
 
 var sink string
 
+type codedTestError struct{}
+
+func (codedTestError) Error() string          { return "coded" }
+func (codedTestError) ErrorCode() int         { return -32005 }
+func (codedTestError) ErrorData() interface{} { return "limit" }
+
+// Tests that the structured JSON format adds the module of the records and the
+// codes of their errors.
+func TestStructuredJSONFormat(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New()
+	logger.SetHandler(StreamHandler(&buf, StructuredJSONFormat()))
+
+	logger.Warn("Failed", "number", 1, "err", fmt.Errorf("wrapped: %w", codedTestError{}))
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &fields); err != nil {
+		t.Fatalf("failed to decode record %q: %v", buf.String(), err)
+	}
+	want := map[string]interface{}{
+		"module":  "log",
+		"number":  float64(1),
+		"err":     "wrapped: coded",
+		"errcode": float64(-32005),
+		"errname": "limit",
+	}
+	for k, v := range want {
+		if fields[k] != v {
+			t.Errorf("field %s mismatch: have %v, want %v", k, fields[k], v)
+		}
+	}
+}
+
 func BenchmarkPrettyInt64Logfmt(b *testing.B) {
 	b.ReportAllocs()
 	for i := 0; i < b.N; i++ {
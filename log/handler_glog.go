@@ -44,6 +44,7 @@ type GlogHandler struct {
 	backtrace uint32 // Flag whether backtrace location is set
 
 	patterns  []pattern       // Current list of patterns to override with
+	ruleset   string          // Vmodule ruleset the patterns were parsed from
 	siteCache map[uintptr]Lvl // Cache of callsite pattern evaluations
 	location  string          // file:line location where to do a stackdump at
 	lock      sync.RWMutex    // Lock protecting the override pattern list
@@ -75,6 +76,11 @@ func (h *GlogHandler) Verbosity(level Lvl) {
 	atomic.StoreUint32(&h.level, uint32(level))
 }
 
+// GetVerbosity returns the glog verbosity ceiling.
+func (h *GlogHandler) GetVerbosity() Lvl {
+	return Lvl(atomic.LoadUint32(&h.level))
+}
+
 // Vmodule sets the glog verbosity pattern.
 //
 // The syntax of the argument is a comma-separated list of pattern=N, where the
@@ -137,12 +143,21 @@ func (h *GlogHandler) Vmodule(ruleset string) error {
 	defer h.lock.Unlock()
 
 	h.patterns = filter
+	h.ruleset = ruleset
 	h.siteCache = make(map[uintptr]Lvl)
 	atomic.StoreUint32(&h.override, uint32(len(filter)))
 
 	return nil
 }
 
+// GetVmodule returns the glog verbosity pattern last set by Vmodule.
+func (h *GlogHandler) GetVmodule() string {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	return h.ruleset
+}
+
 // BacktraceAt sets the glog backtrace location. When set to a file and line
 // number holding a logging statement, a stack trace will be written to the Info
 // log whenever execution hits that statement.
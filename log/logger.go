@@ -13,6 +13,9 @@ const lvlKey = "lvl"
 const msgKey = "msg"
 const ctxKey = "ctx"
 const errorKey = "LOG15_ERROR"
+const moduleKey = "module"
+const errCodeKey = "errcode"
+const errNameKey = "errname"
 const skipLevel = 2
 
 type Lvl int
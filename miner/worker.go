@@ -760,6 +760,12 @@ func (w *worker) makeEnv(parent *types.Block, header *types.Header, coinbase com
 		return nil, err
 	}
 	state.StartPrefetcher("miner")
+	if w.chain.StateDiffsEnabled() {
+		state.RecordDiffs()
+	}
+	if w.chain.WitnessesEnabled() {
+		state.RecordWitness()
+	}
 
 	// Note the passed coinbase may be different with header.Coinbase.
 	env := &environment{
@@ -888,6 +894,15 @@ func (w *worker) commitTransactions(env *environment, txs *types.TransactionsByP
 			txs.Pop()
 			continue
 		}
+		// Skip the transactions priced for a subsidy once the sponsored gas of
+		// the block runs out, as the producer would not be reimbursed for them
+		if w.chainConfig.IsSubsidy(env.header.Number) && tx.GasPrice().Cmp(w.chainConfig.Subsidy.GasPrice) < 0 {
+			if _, sponsored, _ := env.state.Subsidy(); sponsored+tx.Gas() > w.chainConfig.Subsidy.BlockGasLimit {
+				log.Trace("Sponsored gas limit reached", "sender", from, "hash", tx.Hash())
+				txs.Pop()
+				continue
+			}
+		}
 		// Start executing the transaction
 		env.state.Prepare(tx.Hash(), env.tcount)
 
@@ -987,11 +1002,19 @@ func (w *worker) prepareWork(genParams *generateParams) (*environment, error) {
 		timestamp = parent.Time() + 1
 	}
 	// Construct the sealing block header, set the extra field if it's allowed
-	num := parent.Number()
+	number := new(big.Int).Add(parent.Number(), common.Big1)
+
+	// Aim for the configured gas limit within the network-wide bounds
+	gasCeil := w.config.GasCeil
+	if min, max := w.chainConfig.GasLimitBoundsAt(number); gasCeil < min {
+		gasCeil = min
+	} else if gasCeil > max {
+		gasCeil = max
+	}
 	header := &types.Header{
 		ParentHash: parent.Hash(),
-		Number:     num.Add(num, common.Big1),
-		GasLimit:   core.CalcGasLimit(parent.GasLimit(), w.config.GasCeil),
+		Number:     number,
+		GasLimit:   core.CalcGasLimit(parent.GasLimit(), gasCeil),
 		Time:       timestamp,
 		Coinbase:   genParams.coinbase,
 	}
@@ -1007,7 +1030,7 @@ func (w *worker) prepareWork(genParams *generateParams) (*environment, error) {
 		header.BaseFee = misc.CalcBaseFee(w.chainConfig, parent.Header())
 		if !w.chainConfig.IsLondon(parent.Number()) {
 			parentGasLimit := parent.GasLimit() * params.ElasticityMultiplier
-			header.GasLimit = core.CalcGasLimit(parentGasLimit, w.config.GasCeil)
+			header.GasLimit = core.CalcGasLimit(parentGasLimit, gasCeil)
 		}
 	}
 	// Run the consensus preparation with the default or customized consensus engine.
@@ -1023,6 +1046,8 @@ func (w *worker) prepareWork(genParams *generateParams) (*environment, error) {
 		log.Error("Failed to create sealing context", "err", err)
 		return nil, err
 	}
+	core.ApplyStateUpgrades(w.chainConfig, header.Number, env.state)
+
 	// Accumulate the uncles for the sealing work only if it's allowed.
 	if !genParams.noUncle {
 		commitUncles := func(blocks map[common.Hash]*types.Block) {
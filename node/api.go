@@ -116,6 +116,79 @@ func (api *adminAPI) RemoveTrustedPeer(url string) (bool, error) {
 	return true, nil
 }
 
+// AddBootnode adds a node to the bootstrap nodes of the discovery table, and
+// persists the list within the datadir. Bootnodes are identified by their URL, as
+// the same node may be reachable through several endpoints.
+func (api *adminAPI) AddBootnode(url string) (bool, error) {
+	// Make sure the server is running, fail otherwise
+	server := api.node.Server()
+	if server == nil {
+		return false, ErrNodeStopped
+	}
+	node, err := enode.Parse(enode.ValidSchemes, url)
+	if err != nil {
+		return false, fmt.Errorf("invalid enode: %v", err)
+	}
+	bootnodes := server.Bootnodes()
+	for _, n := range bootnodes {
+		if n.URLv4() == node.URLv4() {
+			return true, nil
+		}
+	}
+	return true, api.setBootnodes(server, append(bootnodes, node))
+}
+
+// RemoveBootnode removes a node from the bootstrap nodes of the discovery table,
+// and persists the list within the datadir. It doesn't disconnect the node.
+func (api *adminAPI) RemoveBootnode(url string) (bool, error) {
+	// Make sure the server is running, fail otherwise
+	server := api.node.Server()
+	if server == nil {
+		return false, ErrNodeStopped
+	}
+	node, err := enode.Parse(enode.ValidSchemes, url)
+	if err != nil {
+		return false, fmt.Errorf("invalid enode: %v", err)
+	}
+	var (
+		bootnodes = []*enode.Node{}
+		removed   bool
+	)
+	for _, n := range server.Bootnodes() {
+		if n.URLv4() == node.URLv4() {
+			removed = true
+			continue
+		}
+		bootnodes = append(bootnodes, n)
+	}
+	if !removed {
+		return false, nil
+	}
+	return true, api.setBootnodes(server, bootnodes)
+}
+
+// ListBootnodes returns the URLs of the bootstrap nodes of the discovery table.
+func (api *adminAPI) ListBootnodes() ([]string, error) {
+	// Make sure the server is running, fail otherwise
+	server := api.node.Server()
+	if server == nil {
+		return nil, ErrNodeStopped
+	}
+	urls := []string{}
+	for _, n := range server.Bootnodes() {
+		urls = append(urls, n.URLv4())
+	}
+	return urls, nil
+}
+
+// setBootnodes replaces the bootstrap nodes of the server and persists them.
+func (api *adminAPI) setBootnodes(server *p2p.Server, nodes []*enode.Node) error {
+	if err := server.SetBootnodes(nodes); err != nil {
+		return err
+	}
+	return api.node.Config().SaveBootnodes(nodes)
+}
+
 // PeerEvents creates an RPC subscription which receives peer events from the
 // node's p2p.Server
 func (api *adminAPI) PeerEvents(ctx context.Context) (*rpc.Subscription, error) {
@@ -287,6 +360,28 @@ func (api *adminAPI) StopWS() (bool, error) {
 	return true, nil
 }
 
+// WSConnections retrieves the WebSocket connections served by the node, along
+// with the number of messages exchanged over them and their subscriptions.
+func (api *adminAPI) WSConnections() []rpc.ConnInfo {
+	conns := []rpc.ConnInfo{}
+	for _, server := range api.node.wsServers() {
+		conns = append(conns, server.Connections()...)
+	}
+	return conns
+}
+
+// CloseWSConnection terminates the WebSocket connection with the given id, along
+// with all its subscriptions.
+func (api *adminAPI) CloseWSConnection(id rpc.ID) (bool, error) {
+	for _, server := range api.node.wsServers() {
+		if server.CloseConnection(id) {
+			api.node.log.Info("Terminated WebSocket connection", "id", id)
+			return true, nil
+		}
+	}
+	return false, errConnectionNotFound
+}
+
 // Peers retrieves all the information we know about each individual peer at the
 // protocol granularity.
 func (api *adminAPI) Peers() ([]*p2p.PeerInfo, error) {
@@ -312,6 +407,23 @@ func (api *adminAPI) Datadir() string {
 	return api.node.DataDir()
 }
 
+// SetLogLevel sets the log verbosity of the modules matching the given vmodule
+// pattern, or the global verbosity if the pattern is empty, without restarting
+// the node. An empty level drops the verbosity rule of the pattern.
+func (api *adminAPI) SetLogLevel(level string, pattern *string) error {
+	var p string
+	if pattern != nil {
+		p = *pattern
+	}
+	return debug.SetLogLevel(level, p)
+}
+
+// GetLogConfig retrieves the global log verbosity and the verbosity of the
+// modules matching the vmodule patterns.
+func (api *adminAPI) GetLogConfig() *debug.LogConfig {
+	return debug.GetLogConfig()
+}
+
 // web3API offers helper utils
 type web3API struct {
 	stack *Node
@@ -25,6 +25,9 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/qydata/go-ctereum/internal/debug"
+	"github.com/qydata/go-ctereum/p2p"
+	"github.com/qydata/go-ctereum/p2p/enode"
 	"github.com/qydata/go-ctereum/rpc"
 	"github.com/stretchr/testify/assert"
 )
@@ -350,3 +353,86 @@ func not(ok bool) string {
 	}
 	return "not "
 }
+
+// Tests that the log verbosity can be changed at runtime through the admin API.
+func TestSetLogLevel(t *testing.T) {
+	api := &adminAPI{}
+
+	config := api.GetLogConfig()
+	defer func() {
+		debug.SetLogLevel(config.Verbosity, "")
+		for pattern := range api.GetLogConfig().Vmodule {
+			debug.SetLogLevel("", pattern)
+		}
+	}()
+	clique, heimdall := "consensus/clique/*", "heimdall"
+	assert.NoError(t, api.SetLogLevel("warn", nil))
+	assert.NoError(t, api.SetLogLevel("trace", &clique))
+	assert.NoError(t, api.SetLogLevel("4", &heimdall))
+	assert.Equal(t, &debug.LogConfig{
+		Verbosity: "warn",
+		Vmodule:   map[string]string{clique: "trace", heimdall: "debug"},
+	}, api.GetLogConfig())
+
+	// Raising an existing rule replaces it, an empty level drops it
+	assert.NoError(t, api.SetLogLevel("info", &clique))
+	assert.NoError(t, api.SetLogLevel("", &heimdall))
+	assert.Equal(t, map[string]string{clique: "info"}, api.GetLogConfig().Vmodule)
+
+	assert.Error(t, api.SetLogLevel("loud", nil))
+	assert.Error(t, api.SetLogLevel("9", nil))
+	bad := "a=b"
+	assert.Error(t, api.SetLogLevel("trace", &bad))
+}
+
+// Tests that the bootnodes managed through the admin API are persisted within
+// the datadir and used instead of the configured ones on the next start.
+func TestBootnodeManagement(t *testing.T) {
+	var (
+		dir       = t.TempDir()
+		bootnodeA = "enode://1dd9d65c4552b5eb43d5ad55a2ee3f56c6cbc1c64a5c8d659f51fcd51bace24351232b8d7821617d2b29b54b81cdefb9b3e9c37d7fd5f63270bcc9e1a6f6a439@127.0.0.1:30303"
+		bootnodeB = "enode://a979fb575495b8d6db44f750317d0f4622bf4c2aa3365d6af7c284339968eef29b69ad0dce72a4d8db5ebb4968de0e3bec910127f134779fbcb0cb6d3331163c@127.0.0.1:30304"
+	)
+	newNode := func(bootnodes ...string) *Node {
+		config := &Config{Name: "test node", DataDir: dir, P2P: p2p.Config{PrivateKey: testNodeKey, ListenAddr: "127.0.0.1:0"}}
+		for _, url := range bootnodes {
+			config.P2P.BootstrapNodes = append(config.P2P.BootstrapNodes, enode.MustParse(url))
+		}
+		stack, err := New(config)
+		if err != nil {
+			t.Fatalf("failed to create node: %v", err)
+		}
+		if err := stack.Start(); err != nil {
+			t.Fatalf("failed to start node: %v", err)
+		}
+		return stack
+	}
+	stack := newNode(bootnodeA)
+	api := &adminAPI{stack}
+
+	if ok, err := api.AddBootnode(bootnodeB); !ok || err != nil {
+		t.Fatalf("failed to add bootnode: %v", err)
+	}
+	if ok, err := api.AddBootnode(bootnodeB); !ok || err != nil {
+		t.Fatalf("failed to re-add bootnode: %v", err)
+	}
+	if ok, err := api.RemoveBootnode(bootnodeA); !ok || err != nil {
+		t.Fatalf("failed to remove bootnode: %v", err)
+	}
+	if ok, err := api.RemoveBootnode(bootnodeA); ok || err != nil {
+		t.Fatalf("removed unknown bootnode: %v, %v", ok, err)
+	}
+	if _, err := api.AddBootnode("enode://invalid"); err == nil {
+		t.Fatalf("added invalid bootnode")
+	}
+	urls, _ := api.ListBootnodes()
+	assert.Equal(t, []string{bootnodeB}, urls)
+	stack.Close()
+
+	// Restart the node with the original bootnodes and check the managed ones are used
+	stack = newNode(bootnodeA)
+	defer stack.Close()
+
+	urls, _ = (&adminAPI{stack}).ListBootnodes()
+	assert.Equal(t, []string{bootnodeB}, urls)
+}
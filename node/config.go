@@ -18,6 +18,7 @@ package node
 
 import (
 	"crypto/ecdsa"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -36,9 +37,11 @@ import (
 const (
 	datadirPrivateKey      = "nodekey"            // Path within the datadir to the node's private key
 	datadirJWTKey          = "jwtsecret"          // Path within the datadir to the node's jwt secret
+	datadirOperatorJWTKey  = "operatorsecret"     // Path within the datadir to the operator endpoint's jwt secret
 	datadirDefaultKeyStore = "keystore"           // Path within the datadir to the keystore
 	datadirStaticNodes     = "static-nodes.json"  // Path within the datadir to the static node list
 	datadirTrustedNodes    = "trusted-nodes.json" // Path within the datadir to the trusted node list
+	datadirBootnodes       = "bootnodes.json"     // Path within the datadir to the bootnode list managed at runtime
 	datadirNodeDatabase    = "nodes"              // Path within the datadir to store the node infos
 )
 
@@ -65,6 +68,11 @@ type Config struct {
 	// in memory.
 	DataDir string
 
+	// DBEngine is the backing key-value store of the databases, either leveldb or
+	// pebble. If empty, the engine of an existing database is used, leveldb for
+	// new ones.
+	DBEngine string `toml:",omitempty"`
+
 	// Configuration of peer-to-peer networking.
 	P2P p2p.Config
 
@@ -148,6 +156,29 @@ type Config struct {
 	// for the authenticated api. This is by default {'localhost'}.
 	AuthVirtualHosts []string `toml:",omitempty"`
 
+	// OperatorHost is the host interface on which to start the operator RPC
+	// endpoint, serving the privileged APIs over HTTP and websocket behind JWT
+	// authentication. If this field is empty, no operator endpoint is started.
+	//
+	// While the operator endpoint is running, the unauthenticated HTTP and
+	// websocket endpoints only expose the read-only public modules.
+	OperatorHost string `toml:",omitempty"`
+
+	// OperatorPort is the TCP port number on which to start the operator endpoint.
+	OperatorPort int `toml:",omitempty"`
+
+	// OperatorVirtualHosts is the list of virtual hostnames which are allowed on
+	// incoming requests to the operator endpoint.
+	OperatorVirtualHosts []string `toml:",omitempty"`
+
+	// OperatorModules is a list of API modules to expose via the operator endpoint.
+	// If the module list is empty, all the RPC API endpoints are exposed.
+	OperatorModules []string `toml:",omitempty"`
+
+	// OperatorJWTSecret is the path to the hex-encoded jwt secret of the operator
+	// endpoint, distinct from the one of the authenticated APIs.
+	OperatorJWTSecret string `toml:",omitempty"`
+
 	// WSHost is the host interface on which to start the websocket RPC server. If
 	// this field is empty, no websocket API endpoint will be started.
 	WSHost string
@@ -177,6 +208,23 @@ type Config struct {
 	// private APIs to untrusted users is a major security risk.
 	WSExposeAll bool `toml:",omitempty"`
 
+	// RPCPolicy is the access and rate limiting policy enforced on the HTTP and
+	// websocket RPC interfaces, the client quotas spanning both of them. It does
+	// not apply to IPC and to the authenticated APIs.
+	RPCPolicy rpc.PolicyConfig `toml:",omitempty"`
+
+	// BatchRequestLimit is the maximum number of requests in a batch served over
+	// HTTP and websocket. Unlimited if zero.
+	BatchRequestLimit int `toml:",omitempty"`
+
+	// BatchResponseMaxSize is the size in bytes of the results of a batch above
+	// which its remaining requests are answered with an error. Unlimited if zero.
+	BatchResponseMaxSize int `toml:",omitempty"`
+
+	// BatchConcurrency is the number of requests of a batch executed concurrently.
+	// The requests are executed in order if it is at most one.
+	BatchConcurrency int `toml:",omitempty"`
+
 	// GraphQLCors is the Cross-Origin Resource Sharing header to send to requesting
 	// clients. Please be aware that CORS is a browser enforced security, it's fully
 	// useless for custom HTTP clients.
@@ -404,6 +452,51 @@ func (c *Config) TrustedNodes() []*enode.Node {
 	return c.parsePersistentNodes(&c.trustedNodesWarning, c.ResolvePath(datadirTrustedNodes))
 }
 
+// Bootnodes returns the bootstrap node list persisted by the bootnode management
+// API, or nil if the list was never changed at runtime.
+func (c *Config) Bootnodes() []*enode.Node {
+	path := c.ResolvePath(datadirBootnodes)
+	if c.DataDir == "" || !common.FileExist(path) {
+		return nil
+	}
+	var urls []string
+	if err := common.LoadJSON(path, &urls); err != nil {
+		log.Error("Can't load bootnode list file", "err", err)
+		return nil
+	}
+	nodes := make([]*enode.Node, 0, len(urls))
+	for _, url := range urls {
+		node, err := enode.Parse(enode.ValidSchemes, url)
+		if err != nil {
+			log.Error("Invalid bootnode URL", "url", url, "err", err)
+			continue
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes
+}
+
+// SaveBootnodes persists the bootstrap node list within the data directory, to
+// be used instead of the configured one on the next start.
+func (c *Config) SaveBootnodes(nodes []*enode.Node) error {
+	if c.DataDir == "" {
+		return nil
+	}
+	urls := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		urls = append(urls, node.URLv4())
+	}
+	blob, err := json.MarshalIndent(urls, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := c.ResolvePath(datadirBootnodes)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, blob, 0644)
+}
+
 // parsePersistentNodes parses a list of discovery node URLs loaded from a .json
 // file from within the data directory.
 func (c *Config) parsePersistentNodes(w *bool, path string) []*enode.Node {
@@ -38,27 +38,49 @@ const (
 	DefaultAuthPort    = 8551        // Default port for the authenticated apis
 )
 
+const (
+	DefaultOperatorHost = "localhost" // Default host interface for the operator apis
+	DefaultOperatorPort = 8552        // Default port for the operator apis
+)
+
 var (
 	DefaultAuthCors    = []string{"localhost"} // Default cors domain for the authenticated apis
 	DefaultAuthVhosts  = []string{"localhost"} // Default virtual hosts for the authenticated apis
 	DefaultAuthOrigins = []string{"localhost"} // Default origins for the authenticated apis
 	DefaultAuthPrefix  = ""                    // Default prefix for the authenticated apis
 	DefaultAuthModules = []string{"eth", "engine"}
+
+	DefaultOperatorVhosts  = []string{"localhost"}                     // Default virtual hosts for the operator apis
+	DefaultOperatorModules = []string{"admin", "debug", "stake", "ct"} // Default modules of the operator apis
+
+	// PublicModules are the modules the unauthenticated endpoints are limited
+	// to while the operator endpoint is running.
+	PublicModules = []string{"eth", "net", "web3"}
+
+	// OperatorMethods are the methods of the public modules acting with the
+	// node's accounts, only served on the operator endpoint while it is running.
+	OperatorMethods = []string{"eth_sendTransaction", "eth_signTransaction", "eth_sign", "eth_resend"}
 )
 
 // DefaultConfig contains reasonable default settings.
 var DefaultConfig = Config{
-	DataDir:             DefaultDataDir(),
-	HTTPPort:            DefaultHTTPPort,
-	AuthAddr:            DefaultAuthHost,
-	AuthPort:            DefaultAuthPort,
-	AuthVirtualHosts:    DefaultAuthVhosts,
-	HTTPModules:         []string{"net", "web3"},
-	HTTPVirtualHosts:    []string{"localhost"},
-	HTTPTimeouts:        rpc.DefaultHTTPTimeouts,
-	WSPort:              DefaultWSPort,
-	WSModules:           []string{"net", "web3"},
-	GraphQLVirtualHosts: []string{"localhost"},
+	DataDir:              DefaultDataDir(),
+	HTTPPort:             DefaultHTTPPort,
+	AuthAddr:             DefaultAuthHost,
+	AuthPort:             DefaultAuthPort,
+	AuthVirtualHosts:     DefaultAuthVhosts,
+	OperatorPort:         DefaultOperatorPort,
+	OperatorVirtualHosts: DefaultOperatorVhosts,
+	OperatorModules:      DefaultOperatorModules,
+	BatchRequestLimit:    1000,
+	BatchResponseMaxSize: 25 * 1000 * 1000,
+	BatchConcurrency:     8,
+	HTTPModules:          []string{"net", "web3"},
+	HTTPVirtualHosts:     []string{"localhost"},
+	HTTPTimeouts:         rpc.DefaultHTTPTimeouts,
+	WSPort:               DefaultWSPort,
+	WSModules:            []string{"net", "web3"},
+	GraphQLVirtualHosts:  []string{"localhost"},
 	P2P: p2p.Config{
 		ListenAddr: ":30303",
 		MaxPeers:   50,
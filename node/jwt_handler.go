@@ -22,10 +22,19 @@ import (
 	"time"
 
 	"github.com/golang-jwt/jwt/v4"
+	"github.com/qydata/go-ctereum/rpc"
 )
 
 const jwtExpiryTimeout = 60 * time.Second
 
+// jwtClaims are the claims of the tokens accepted by the authenticated
+// endpoints. The optional scope claim is a space separated list of the
+// permission scopes granted to the bearer, all of them if it is empty.
+type jwtClaims struct {
+	jwt.RegisteredClaims
+	Scope string `json:"scope,omitempty"`
+}
+
 type jwtHandler struct {
 	keyFunc func(token *jwt.Token) (interface{}, error)
 	next    http.Handler
@@ -45,7 +54,7 @@ func newJWTHandler(secret []byte, next http.Handler) http.Handler {
 func (handler *jwtHandler) ServeHTTP(out http.ResponseWriter, r *http.Request) {
 	var (
 		strToken string
-		claims   jwt.RegisteredClaims
+		claims   jwtClaims
 	)
 	if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
 		strToken = strings.TrimPrefix(auth, "Bearer ")
@@ -75,6 +84,7 @@ func (handler *jwtHandler) ServeHTTP(out http.ResponseWriter, r *http.Request) {
 	case time.Until(claims.IssuedAt.Time) > jwtExpiryTimeout:
 		http.Error(out, "future token", http.StatusForbidden)
 	default:
-		handler.next.ServeHTTP(out, r)
+		ctx := rpc.WithAuthScopes(r.Context(), strings.Fields(claims.Scope))
+		handler.next.ServeHTTP(out, r.WithContext(ctx))
 	}
 }
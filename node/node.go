@@ -61,6 +61,7 @@ type Node struct {
 	ws            *httpServer //
 	httpAuth      *httpServer //
 	wsAuth        *httpServer //
+	httpOperator  *httpServer // Serves the privileged APIs over both HTTP and websocket
 	ipc           *ipcServer  // Stores information about the ipc http server
 	inprocHandler *rpc.Server // In-process RPC request handler to process the API requests
 
@@ -139,6 +140,10 @@ func New(conf *Config) (*Node, error) {
 	if node.server.Config.TrustedNodes == nil {
 		node.server.Config.TrustedNodes = node.config.TrustedNodes()
 	}
+	if bootnodes := node.config.Bootnodes(); bootnodes != nil {
+		node.log.Info("Using the bootnodes managed at runtime", "count", len(bootnodes))
+		node.server.Config.BootstrapNodes = bootnodes
+	}
 	if node.server.Config.NodeDatabase == "" {
 		node.server.Config.NodeDatabase = node.config.NodeDB()
 	}
@@ -156,6 +161,7 @@ func New(conf *Config) (*Node, error) {
 	node.httpAuth = newHTTPServer(node.log, conf.HTTPTimeouts)
 	node.ws = newHTTPServer(node.log, rpc.DefaultHTTPTimeouts)
 	node.wsAuth = newHTTPServer(node.log, rpc.DefaultHTTPTimeouts)
+	node.httpOperator = newHTTPServer(node.log, conf.HTTPTimeouts)
 	node.ipc = newIPCServer(node.log, conf.IPCEndpoint())
 
 	return node, nil
@@ -292,6 +298,16 @@ func containsLifecycle(lfs []Lifecycle, l Lifecycle) bool {
 	return false
 }
 
+// containsString checks if 'list' contains 's'.
+func containsString(list []string, s string) bool {
+	for _, obj := range list {
+		if obj == s {
+			return true
+		}
+	}
+	return false
+}
+
 // stopServices terminates running services, RPC and p2p networking.
 // It is the inverse of Start.
 func (n *Node) stopServices(running []Lifecycle) error {
@@ -344,13 +360,13 @@ func (n *Node) closeDataDir() {
 }
 
 // obtainJWTSecret loads the jwt-secret, either from the provided config,
-// or from the default location. If neither of those are present, it generates
-// a new secret and stores to the default location.
-func (n *Node) obtainJWTSecret(cliParam string) ([]byte, error) {
+// or from the given default location within the datadir. If neither of those
+// are present, it generates a new secret and stores to the default location.
+func (n *Node) obtainJWTSecret(cliParam string, datadirKey string) ([]byte, error) {
 	fileName := cliParam
 	if len(fileName) == 0 {
 		// no path provided, use default
-		fileName = n.ResolvePath(datadirJWTKey)
+		fileName = n.ResolvePath(datadirKey)
 	}
 	// try reading from file
 	if data, err := os.ReadFile(fileName); err == nil {
@@ -394,7 +410,27 @@ func (n *Node) startRPC() error {
 	var (
 		servers   []*httpServer
 		open, all = n.GetAPIs()
+		policy    *rpc.Policy
+
+		batchLimits = rpc.BatchLimits{
+			Items:        n.config.BatchRequestLimit,
+			ResponseSize: n.config.BatchResponseMaxSize,
+			Workers:      n.config.BatchConcurrency,
+		}
+		httpModules  = n.config.HTTPModules
+		wsModules    = n.config.WSModules
+		policyConfig = n.config.RPCPolicy
 	)
+	// While the operator endpoint is running, limit the unauthenticated ones to
+	// the public modules and keep the methods using the node's accounts off them.
+	if n.config.OperatorHost != "" {
+		httpModules = n.publicModules("HTTP", httpModules)
+		wsModules = n.publicModules("WebSocket", wsModules)
+		policyConfig.Deny = append(append([]string{}, policyConfig.Deny...), OperatorMethods...)
+	}
+	if policyConfig.Enabled() {
+		policy = rpc.NewPolicy(policyConfig)
+	}
 
 	initHttp := func(server *httpServer, apis []rpc.API, port int) error {
 		if err := server.setListenAddr(n.config.HTTPHost, port); err != nil {
@@ -403,8 +439,10 @@ func (n *Node) startRPC() error {
 		if err := server.enableRPC(apis, httpConfig{
 			CorsAllowedOrigins: n.config.HTTPCors,
 			Vhosts:             n.config.HTTPVirtualHosts,
-			Modules:            n.config.HTTPModules,
+			Modules:            httpModules,
 			prefix:             n.config.HTTPPathPrefix,
+			policy:             policy,
+			batchLimits:        batchLimits,
 		}); err != nil {
 			return err
 		}
@@ -418,9 +456,11 @@ func (n *Node) startRPC() error {
 			return err
 		}
 		if err := server.enableWS(n.rpcAPIs, wsConfig{
-			Modules: n.config.WSModules,
-			Origins: n.config.WSOrigins,
-			prefix:  n.config.WSPathPrefix,
+			Modules:     wsModules,
+			Origins:     n.config.WSOrigins,
+			prefix:      n.config.WSPathPrefix,
+			policy:      policy,
+			batchLimits: batchLimits,
 		}); err != nil {
 			return err
 		}
@@ -440,6 +480,7 @@ func (n *Node) startRPC() error {
 			Modules:            DefaultAuthModules,
 			prefix:             DefaultAuthPrefix,
 			jwtSecret:          secret,
+			batchLimits:        batchLimits,
 		}); err != nil {
 			return err
 		}
@@ -450,10 +491,37 @@ func (n *Node) startRPC() error {
 			return err
 		}
 		if err := server.enableWS(apis, wsConfig{
-			Modules:   DefaultAuthModules,
-			Origins:   DefaultAuthOrigins,
-			prefix:    DefaultAuthPrefix,
-			jwtSecret: secret,
+			Modules:     DefaultAuthModules,
+			Origins:     DefaultAuthOrigins,
+			prefix:      DefaultAuthPrefix,
+			jwtSecret:   secret,
+			batchLimits: batchLimits,
+		}); err != nil {
+			return err
+		}
+		servers = append(servers, server)
+		return nil
+	}
+
+	initOperator := func(apis []rpc.API, secret []byte) error {
+		// The operator endpoint serves both HTTP and WS on the same port
+		server := n.httpOperator
+		if err := server.setListenAddr(n.config.OperatorHost, n.config.OperatorPort); err != nil {
+			return err
+		}
+		if err := server.enableRPC(apis, httpConfig{
+			Vhosts:      n.config.OperatorVirtualHosts,
+			Modules:     n.config.OperatorModules,
+			jwtSecret:   secret,
+			batchLimits: batchLimits,
+		}); err != nil {
+			return err
+		}
+		if err := server.enableWS(apis, wsConfig{
+			Modules:     n.config.OperatorModules,
+			Origins:     DefaultAuthOrigins,
+			jwtSecret:   secret,
+			batchLimits: batchLimits,
 		}); err != nil {
 			return err
 		}
@@ -477,7 +545,7 @@ func (n *Node) startRPC() error {
 	}
 	// Configure authenticated API
 	if len(open) != len(all) {
-		jwtSecret, err := n.obtainJWTSecret(n.config.JWTSecret)
+		jwtSecret, err := n.obtainJWTSecret(n.config.JWTSecret, datadirJWTKey)
 		if err != nil {
 			return err
 		}
@@ -485,6 +553,16 @@ func (n *Node) startRPC() error {
 			return err
 		}
 	}
+	// Configure the operator API
+	if n.config.OperatorHost != "" {
+		secret, err := n.obtainJWTSecret(n.config.OperatorJWTSecret, datadirOperatorJWTKey)
+		if err != nil {
+			return err
+		}
+		if err := initOperator(all, secret); err != nil {
+			return err
+		}
+	}
 	// Start the servers
 	for _, server := range servers {
 		if err := server.start(); err != nil {
@@ -494,6 +572,30 @@ func (n *Node) startRPC() error {
 	return nil
 }
 
+// publicModules filters the given modules of an unauthenticated endpoint down
+// to the public ones, all of them if none are given.
+func (n *Node) publicModules(endpoint string, modules []string) []string {
+	if len(modules) == 0 {
+		return PublicModules
+	}
+	var public, dropped []string
+	for _, module := range modules {
+		if containsString(PublicModules, module) {
+			public = append(public, module)
+		} else {
+			dropped = append(dropped, module)
+		}
+	}
+	if len(dropped) > 0 {
+		n.log.Warn("Privileged modules only served on the operator endpoint", "endpoint", endpoint, "modules", dropped)
+	}
+	if len(public) == 0 {
+		// An empty list would expose all the modules, disable the namespaces instead
+		return []string{rpc.MetadataApi}
+	}
+	return public
+}
+
 func (n *Node) wsServerForPort(port int, authenticated bool) *httpServer {
 	httpServer, wsServer := n.http, n.ws
 	if authenticated {
@@ -510,6 +612,7 @@ func (n *Node) stopRPC() {
 	n.ws.stop()
 	n.httpAuth.stop()
 	n.wsAuth.stop()
+	n.httpOperator.stop()
 	n.ipc.stop()
 	n.stopInProc()
 }
@@ -668,6 +771,23 @@ func (n *Node) WSEndpoint() string {
 	return "ws://" + n.ws.listenAddr() + n.ws.wsConfig.prefix
 }
 
+// wsServers returns the RPC servers of the enabled WebSocket endpoints.
+func (n *Node) wsServers() []*rpc.Server {
+	var servers []*rpc.Server
+	for _, h := range []*httpServer{n.http, n.ws, n.httpAuth, n.wsAuth, n.httpOperator} {
+		if server := h.wsServer(); server != nil {
+			servers = append(servers, server)
+		}
+	}
+	return servers
+}
+
+// OperatorEndpoint returns the URL of the operator endpoint, serving both HTTP
+// and WebSocket requests.
+func (n *Node) OperatorEndpoint() string {
+	return "http://" + n.httpOperator.listenAddr()
+}
+
 // EventMux retrieves the event multiplexer used by all the network services in
 // the current protocol stack.
 func (n *Node) EventMux() *event.TypeMux {
@@ -689,7 +809,14 @@ func (n *Node) OpenDatabase(name string, cache, handles int, namespace string, r
 	if n.config.DataDir == "" {
 		db = rawdb.NewMemoryDatabase()
 	} else {
-		db, err = rawdb.NewLevelDBDatabase(n.ResolvePath(name), cache, handles, namespace, readonly)
+		db, err = rawdb.Open(rawdb.OpenOptions{
+			Type:      n.config.DBEngine,
+			Directory: n.ResolvePath(name),
+			Namespace: namespace,
+			Cache:     cache,
+			Handles:   handles,
+			ReadOnly:  readonly,
+		})
 	}
 
 	if err == nil {
@@ -715,7 +842,15 @@ func (n *Node) OpenDatabaseWithFreezer(name string, cache, handles int, ancient
 	if n.config.DataDir == "" {
 		db = rawdb.NewMemoryDatabase()
 	} else {
-		db, err = rawdb.NewLevelDBDatabaseWithFreezer(n.ResolvePath(name), cache, handles, n.ResolveAncient(name, ancient), namespace, readonly)
+		db, err = rawdb.Open(rawdb.OpenOptions{
+			Type:              n.config.DBEngine,
+			Directory:         n.ResolvePath(name),
+			AncientsDirectory: n.ResolveAncient(name, ancient),
+			Namespace:         namespace,
+			Cache:             cache,
+			Handles:           handles,
+			ReadOnly:          readonly,
+		})
 	}
 
 	if err == nil {
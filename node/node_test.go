@@ -17,15 +17,23 @@
 package node
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net"
 	"net/http"
+	"os"
+	"path/filepath"
 	"reflect"
+	"sort"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/qydata/go-ctereum/common/hexutil"
 	"github.com/qydata/go-ctereum/crypto"
 	"github.com/qydata/go-ctereum/ethdb"
 	"github.com/qydata/go-ctereum/p2p"
@@ -479,6 +487,63 @@ func TestWebsocketHTTPOnSeparatePort_WSRequest(t *testing.T) {
 	}
 }
 
+// Tests that the operator endpoint serves the privileged modules behind JWT
+// authentication, while the unauthenticated endpoints are limited to the public
+// ones meanwhile.
+func TestOperatorEndpoint(t *testing.T) {
+	secret := bytes.Repeat([]byte{0x42}, 32)
+	secretFile := filepath.Join(t.TempDir(), "operatorsecret")
+	if err := os.WriteFile(secretFile, []byte(hexutil.Encode(secret)), 0600); err != nil {
+		t.Fatal(err)
+	}
+	node, err := New(&Config{
+		HTTPHost:          "127.0.0.1",
+		HTTPModules:       []string{"web3", "admin"},
+		OperatorHost:      "127.0.0.1",
+		OperatorModules:   []string{"admin", "debug"},
+		OperatorJWTSecret: secretFile,
+	})
+	if err != nil {
+		t.Fatal("can't create node:", err)
+	}
+	defer node.Close()
+	if err := node.Start(); err != nil {
+		t.Fatal("can't start node:", err)
+	}
+	modules := func(url string, headers ...string) (int, []string) {
+		resp := rpcRequest(t, url, headers...)
+		defer resp.Body.Close()
+
+		var res struct {
+			Result map[string]string `json:"result"`
+		}
+		json.NewDecoder(resp.Body).Decode(&res)
+
+		var names []string
+		for name := range res.Result {
+			if name != rpc.MetadataApi {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		return resp.StatusCode, names
+	}
+	if _, names := modules(node.HTTPEndpoint()); !reflect.DeepEqual(names, []string{"web3"}) {
+		t.Errorf("public endpoint modules mismatch: have %v, want [web3]", names)
+	}
+	if status, _ := modules(node.OperatorEndpoint()); status != http.StatusForbidden {
+		t.Errorf("unauthenticated operator request status mismatch: have %d, want %d", status, http.StatusForbidden)
+	}
+	token, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, testClaim{"iat": time.Now().Unix()}).SignedString(secret)
+	status, names := modules(node.OperatorEndpoint(), "Authorization", "Bearer "+token)
+	if status != http.StatusOK {
+		t.Fatalf("authenticated operator request status mismatch: have %d, want %d", status, http.StatusOK)
+	}
+	if !reflect.DeepEqual(names, []string{"admin", "debug"}) {
+		t.Errorf("operator endpoint modules mismatch: have %v, want [admin debug]", names)
+	}
+}
+
 type rpcPrefixTest struct {
 	httpPrefix, wsPrefix string
 	// These lists paths on which JSON-RPC should be served / not served.
@@ -39,16 +39,20 @@ type httpConfig struct {
 	Modules            []string
 	CorsAllowedOrigins []string
 	Vhosts             []string
-	prefix             string // path prefix on which to mount http handler
-	jwtSecret          []byte // optional JWT secret
+	prefix             string          // path prefix on which to mount http handler
+	jwtSecret          []byte          // optional JWT secret
+	policy             *rpc.Policy     // optional access and rate limiting policy
+	batchLimits        rpc.BatchLimits // limits of the batch requests
 }
 
 // wsConfig is the JSON-RPC/Websocket configuration
 type wsConfig struct {
-	Origins   []string
-	Modules   []string
-	prefix    string // path prefix on which to mount ws handler
-	jwtSecret []byte // optional JWT secret
+	Origins     []string
+	Modules     []string
+	prefix      string          // path prefix on which to mount ws handler
+	jwtSecret   []byte          // optional JWT secret
+	policy      *rpc.Policy     // optional access and rate limiting policy
+	batchLimits rpc.BatchLimits // limits of the batch requests
 }
 
 type rpcHandler struct {
@@ -296,6 +300,8 @@ func (h *httpServer) enableRPC(apis []rpc.API, config httpConfig) error {
 	if err := RegisterApis(apis, config.Modules, srv); err != nil {
 		return err
 	}
+	srv.SetPolicy(config.policy)
+	srv.SetBatchLimits(config.batchLimits)
 	h.httpConfig = config
 	h.httpHandler.Store(&rpcHandler{
 		Handler: NewHTTPHandlerStack(srv, config.CorsAllowedOrigins, config.Vhosts, config.jwtSecret),
@@ -327,6 +333,8 @@ func (h *httpServer) enableWS(apis []rpc.API, config wsConfig) error {
 	if err := RegisterApis(apis, config.Modules, srv); err != nil {
 		return err
 	}
+	srv.SetPolicy(config.policy)
+	srv.SetBatchLimits(config.batchLimits)
 	h.wsConfig = config
 	h.wsHandler.Store(&rpcHandler{
 		Handler: NewWSHandlerStack(srv.WebsocketHandler(config.Origins), config.jwtSecret),
@@ -367,6 +375,15 @@ func (h *httpServer) wsAllowed() bool {
 	return h.wsHandler.Load().(*rpcHandler) != nil
 }
 
+// wsServer returns the RPC server handling WebSocket connections, nil if
+// WebSocket is disabled.
+func (h *httpServer) wsServer() *rpc.Server {
+	if ws := h.wsHandler.Load().(*rpcHandler); ws != nil {
+		return ws.server
+	}
+	return nil
+}
+
 // isWebsocket checks the header of an http request for a websocket upgrade request.
 func isWebsocket(r *http.Request) bool {
 	return strings.EqualFold(r.Header.Get("Upgrade"), "websocket") &&
@@ -18,9 +18,12 @@ package node
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
+	"reflect"
 	"strconv"
 	"strings"
 	"testing"
@@ -419,3 +422,47 @@ func TestJWT(t *testing.T) {
 	}
 	srv.stop()
 }
+
+// scopeService reports the permission scopes of its callers.
+type scopeService struct{}
+
+func (scopeService) Scopes(ctx context.Context) []string {
+	return rpc.PeerInfoFromContext(ctx).Scopes
+}
+
+// Tests that the scopes granted by a token are exposed to the method handlers.
+func TestJWTScopes(t *testing.T) {
+	secret := []byte("secret")
+	srv := rpc.NewServer()
+	defer srv.Stop()
+	if err := srv.RegisterName("test", scopeService{}); err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(newJWTHandler(secret, srv))
+	defer ts.Close()
+
+	tests := []struct {
+		claims testClaim
+		want   []string
+	}{
+		{testClaim{"iat": time.Now().Unix()}, []string{}},
+		{testClaim{"iat": time.Now().Unix(), "scope": "accounts:read  accounts:sign"}, []string{"accounts:read", "accounts:sign"}},
+	}
+	for i, tt := range tests {
+		token, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, tt.claims).SignedString(secret)
+		client, err := rpc.DialHTTP(ts.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		client.SetHeader("Authorization", "Bearer "+token)
+
+		var scopes []string
+		if err := client.Call(&scopes, "test_scopes"); err != nil {
+			t.Fatalf("test %d: call failed: %v", i, err)
+		}
+		client.Close()
+		if scopes == nil || !reflect.DeepEqual(scopes, tt.want) {
+			t.Errorf("test %d: scopes mismatch: have %v, want %v", i, scopes, tt.want)
+		}
+	}
+}
@@ -160,6 +160,12 @@ func (t *UDPv4) Self() *enode.Node {
 	return t.localNode.Node()
 }
 
+// SetBootnodes replaces the nodes used to connect to the network if the table
+// is empty and there are no known nodes in the database.
+func (t *UDPv4) SetBootnodes(nodes []*enode.Node) error {
+	return t.tab.setFallbackNodes(nodes)
+}
+
 // Close shuts down the socket and aborts any running queries.
 func (t *UDPv4) Close() {
 	t.closeOnce.Do(func() {
@@ -173,7 +173,7 @@ type Server struct {
 	newPeerHook  func(*Peer)
 	listenFunc   func(network, addr string) (net.Listener, error)
 
-	lock    sync.Mutex // protects running
+	lock    sync.Mutex // protects running and BootstrapNodes
 	running bool
 
 	listener     net.Listener
@@ -320,6 +320,34 @@ func (srv *Server) PeerCount() int {
 	return count
 }
 
+// Bootnodes returns the bootstrap nodes of the discovery table.
+func (srv *Server) Bootnodes() []*enode.Node {
+	srv.lock.Lock()
+	defer srv.lock.Unlock()
+
+	return append([]*enode.Node{}, srv.BootstrapNodes...)
+}
+
+// SetBootnodes replaces the bootstrap nodes of the discovery table. The new nodes
+// are used from the next time the table needs to be seeded.
+func (srv *Server) SetBootnodes(nodes []*enode.Node) error {
+	srv.lock.Lock()
+	defer srv.lock.Unlock()
+
+	for _, n := range nodes {
+		if err := n.ValidateComplete(); err != nil {
+			return fmt.Errorf("bad bootstrap node %q: %v", n, err)
+		}
+	}
+	if srv.ntab != nil {
+		if err := srv.ntab.SetBootnodes(nodes); err != nil {
+			return err
+		}
+	}
+	srv.BootstrapNodes = append([]*enode.Node{}, nodes...)
+	return nil
+}
+
 // AddPeer adds the given node to the static node set. When there is room in the peer set,
 // the server will connect to the node. If the connection fails for any reason, the server
 // will attempt to reconnect the peer.
@@ -17,11 +17,15 @@
 package params
 
 import (
+	"bytes"
 	"encoding/binary"
+	"encoding/json"
 	"fmt"
 	"math/big"
+	"sort"
 
 	"github.com/qydata/go-ctereum/common"
+	"github.com/qydata/go-ctereum/common/hexutil"
 	"golang.org/x/crypto/sha3"
 )
 
@@ -60,6 +64,19 @@ var CheckpointOracles = map[common.Hash]*CheckpointOracleConfig{
 var (
 	MainnetTerminalTotalDifficulty, _ = new(big.Int).SetString("58_750_000_000_000_000_000_000", 0)
 
+	// MainnetAuthContract is the address of the AuthController queried on the
+	// main network from the auth fork onwards.
+	MainnetAuthContract = common.HexToAddress("0x3449c5b666b7f45aF85c99D12e42eD428648a3AD")
+
+	// MainnetFixAuthContract is the address of the AuthController queried on
+	// the main network from the fix fork onwards. Chains that do not configure
+	// their own fix auth contract fall back to it as well.
+	MainnetFixAuthContract = common.HexToAddress("0x709bBc0aD7581D02244E00C356d0EFcbC79AE9f3")
+
+	// SystemAddress is the sender of the system calls issued by the consensus
+	// engine.
+	SystemAddress = common.HexToAddress("0xffffFFFfFFffffffffffffffFfFFFfffFFFfFFfE")
+
 	// MainnetChainConfig is the chain parameters to run a node on the main network.
 	MainnetChainConfig = &ChainConfig{
 		ChainID:             big.NewInt(27),
@@ -73,7 +90,8 @@ var (
 		PetersburgBlock:     big.NewInt(0),
 		IstanbulBlock:       big.NewInt(0),
 		AuthBlock:           big.NewInt(5033582),
-		AuthContract:        common.HexToAddress("0x3449c5b666b7f45aF85c99D12e42eD428648a3AD"),
+		AuthContract:        MainnetAuthContract,
+		FixAuthContract:     MainnetFixAuthContract,
 
 		Clique: &CliqueConfig{
 			Period:            5,
@@ -82,6 +100,7 @@ var (
 			StakeAmount:       2000000,
 			Poa2PosBlock:      5033500,
 		},
+		StateUpgrades: mainnetStateUpgrades,
 		//ChainID:                       big.NewInt(1),
 		//HomesteadBlock:                big.NewInt(1_150_000),
 		//DAOForkBlock:                  big.NewInt(1_920_000),
@@ -316,16 +335,16 @@ var (
 	//
 	// This configuration is intentionally not using keyed fields to force anyone
 	// adding flags to the config to also have to set these fields.
-	AllEthashProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil, nil, nil, common.Address{}, nil, false, new(EthashConfig), nil}
+	AllEthashProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil, nil, nil, common.Address{}, common.Address{}, nil, nil, nil, nil, nil, nil, nil, false, new(EthashConfig), nil}
 
 	// AllCliqueProtocolChanges contains every protocol change (EIPs) introduced
 	// and accepted by the Ethereum core developers into the Clique consensus.
 	//
 	// This configuration is intentionally not using keyed fields to force anyone
 	// adding flags to the config to also have to set these fields.
-	AllCliqueProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil, nil, nil, nil, nil, common.Address{}, nil, false, nil, &CliqueConfig{Period: 0, Epoch: 30000}}
+	AllCliqueProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil, nil, nil, nil, nil, common.Address{}, common.Address{}, nil, nil, nil, nil, nil, nil, nil, false, nil, &CliqueConfig{Period: 0, Epoch: 30000}}
 
-	TestChainConfig = &ChainConfig{big.NewInt(1), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil, nil, nil, common.Address{}, nil, false, new(EthashConfig), nil}
+	TestChainConfig = &ChainConfig{big.NewInt(1), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil, nil, nil, common.Address{}, common.Address{}, nil, nil, nil, nil, nil, nil, nil, false, new(EthashConfig), nil}
 	TestRules       = TestChainConfig.Rules(new(big.Int), false)
 )
 
@@ -378,6 +397,33 @@ func (c *TrustedCheckpoint) Empty() bool {
 	return c.SectionHead == (common.Hash{}) || c.CHTRoot == (common.Hash{}) || c.BloomRoot == (common.Hash{})
 }
 
+// CliqueCheckpoint is a clique epoch block a light client may start syncing
+// from instead of the genesis. The epoch header is retrieved from the servers
+// and trusted if it matches the header hash and the signers listed in it match
+// the signer set hash.
+type CliqueCheckpoint struct {
+	Number      uint64      `json:"number"`
+	Hash        common.Hash `json:"hash"`
+	SignersHash common.Hash `json:"signersHash"`
+}
+
+// CliqueSignersHash returns the hash identifying a clique signer set, the
+// keccak256 of the addresses concatenated in ascending order, the way they are
+// listed in the extra-data of the epoch headers.
+func CliqueSignersHash(signers []common.Address) common.Hash {
+	sorted := make([]common.Address, len(signers))
+	copy(sorted, signers)
+	sort.Slice(sorted, func(i, j int) bool { return bytes.Compare(sorted[i][:], sorted[j][:]) < 0 })
+
+	w := sha3.NewLegacyKeccak256()
+	for _, signer := range sorted {
+		w.Write(signer[:])
+	}
+	var h common.Hash
+	w.Sum(h[:0])
+	return h
+}
+
 // CheckpointOracleConfig represents a set of checkpoint contract(which acts as an oracle)
 // config which used for light client checkpoint syncing.
 type CheckpointOracleConfig struct {
@@ -419,8 +465,21 @@ type ChainConfig struct {
 	ShanghaiBlock       *big.Int `json:"shanghaiBlock,omitempty"`       // Shanghai switch block (nil = no fork, 0 = already on shanghai)
 	CancunBlock         *big.Int `json:"cancunBlock,omitempty"`         // Cancun switch block (nil = no fork, 0 = already on cancun)
 
-	AuthBlock    *big.Int       `json:"authBlock,omitempty"`
-	AuthContract common.Address `json:"authContract,omitempty"`
+	AuthBlock       *big.Int       `json:"authBlock,omitempty"`
+	AuthContract    common.Address `json:"authContract,omitempty"`
+	FixAuthContract common.Address `json:"fixAuthContract,omitempty"` // Auth contract queried after the fix fork (empty = legacy default)
+
+	CommunityFund *CommunityFundConfig `json:"communityFund,omitempty"` // Priority fee split (nil = disabled)
+	DeployAuth    *DeployAuthConfig    `json:"deployAuth,omitempty"`    // Contract deployment restriction (nil = disabled)
+	Subsidy       *SubsidyConfig       `json:"subsidy,omitempty"`       // Sponsored transactions of authenticated senders (nil = disabled)
+	AuthTxBlock   *big.Int             `json:"authTxBlock,omitempty"`   // Authenticated transaction type switch block (nil = no fork)
+
+	GasLimitBounds *GasLimitBoundsConfig `json:"gasLimitBounds,omitempty"` // Network-wide block gas limit range (nil = disabled)
+
+	// StateUpgrades are the state patches applied at the start of the given
+	// blocks, before any transaction, regardless of the consensus engine. The
+	// actions marked finalize are applied at the end of the block instead.
+	StateUpgrades map[uint64][]UpgradeAction `json:"stateUpgrades,omitempty"`
 
 	// TerminalTotalDifficulty is the amount of total difficulty reached by
 	// the network that triggers the consensus upgrade.
@@ -436,6 +495,97 @@ type ChainConfig struct {
 	Clique *CliqueConfig `json:"clique,omitempty"`
 }
 
+// CommunityFundConfig redirects a share of the priority fees paid in every
+// block from the block producer to a community fund address. The fees are
+// withheld during the block and split over its total by the clique engine
+// finalizing it.
+type CommunityFundConfig struct {
+	Block      *big.Int       `json:"block"`      // Activation block (nil = no fork)
+	Address    common.Address `json:"address"`    // Fund receiving the redirected share
+	Percentage uint64         `json:"percentage"` // Share of the priority fees redirected, in percent
+}
+
+// DeployAuthConfig restricts contract deployment to senders authenticated or
+// whitelisted in the auth contract.
+type DeployAuthConfig struct {
+	Block     *big.Int         `json:"block"`     // Activation block (nil = no fork)
+	Allowlist []common.Address `json:"allowlist"` // Senders deploying without authentication
+}
+
+// SubsidyConfig sponsors the transactions of authenticated senders priced below
+// a threshold, the validator contract reimbursing the block producer from its
+// subsidy pool for the difference up to the threshold price.
+//
+// The reimbursement is only paid out once a validator contract with a reimburse
+// method is deployed, the earlier contracts leaving the producers unpaid.
+type SubsidyConfig struct {
+	Block          *big.Int `json:"block"`                    // Activation block (nil = no fork)
+	GasPrice       *big.Int `json:"gasPrice"`                 // Threshold below which transactions are sponsored
+	BlockGasLimit  uint64   `json:"blockGasLimit"`            // Maximum sponsored gas per block
+	ReimburseBlock *big.Int `json:"reimburseBlock,omitempty"` // Reimburse capable validator contract block (nil = producers unpaid)
+}
+
+// Reimbursement returns the amount owed to the block producer for the gas used
+// by a sponsored transaction at the given price.
+func (c *SubsidyConfig) Reimbursement(gasUsed uint64, gasPrice *big.Int) *big.Int {
+	amount := new(big.Int).Sub(c.GasPrice, gasPrice)
+	return amount.Mul(amount, new(big.Int).SetUint64(gasUsed))
+}
+
+// GasLimitBoundsConfig restricts the block gas limit to a network-wide range,
+// preventing a single misconfigured validator from moving it out.
+type GasLimitBoundsConfig struct {
+	Block *big.Int `json:"block"`         // Activation block (nil = no fork)
+	Min   uint64   `json:"min,omitempty"` // Minimum block gas limit (0 = MinGasLimit)
+	Max   uint64   `json:"max,omitempty"` // Maximum block gas limit (0 = MaxGasLimit)
+}
+
+// UpgradeAction patches the state of an account as part of a state upgrade.
+type UpgradeAction struct {
+	Address    common.Address              `json:"address"`
+	Code       hexutil.Bytes               `json:"code,omitempty"`       // Replaces the code if set
+	Storage    map[common.Hash]common.Hash `json:"storage,omitempty"`    // Storage slots overwritten
+	AddBalance *big.Int                    `json:"addBalance,omitempty"` // Amount credited to the account
+	Finalize   bool                        `json:"finalize,omitempty"`   // Applied by the engine finalization, after the block rewards
+}
+
+// Allowed reports whether the sender may deploy contracts without being
+// authenticated. The system address is always allowed.
+func (c *DeployAuthConfig) Allowed(sender common.Address) bool {
+	if sender == SystemAddress {
+		return true
+	}
+	for _, addr := range c.Allowlist {
+		if addr == sender {
+			return true
+		}
+	}
+	return false
+}
+
+// sameAllowlist reports whether both configs allow the same senders, regardless
+// of their order.
+func (c *DeployAuthConfig) sameAllowlist(other *DeployAuthConfig) bool {
+	for _, addr := range c.Allowlist {
+		if !other.Allowed(addr) {
+			return false
+		}
+	}
+	for _, addr := range other.Allowlist {
+		if !c.Allowed(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// Split divides the given priority fee into the producer and the fund shares.
+func (c *CommunityFundConfig) Split(tip *big.Int) (producer *big.Int, fund *big.Int) {
+	fund = new(big.Int).Mul(tip, new(big.Int).SetUint64(c.Percentage))
+	fund.Div(fund, big.NewInt(100))
+	return new(big.Int).Sub(tip, fund), fund
+}
+
 // EthashConfig is the consensus engine configs for proof-of-work based sealing.
 type EthashConfig struct{}
 
@@ -603,6 +753,104 @@ func (c *ChainConfig) IsImplAuth(num *big.Int) bool {
 	return isForked(c.AuthBlock, num)
 }
 
+// AuthContractAt returns the address of the AuthController that is queried for
+// sender authentication at the given block.
+func (c *ChainConfig) AuthContractAt(num *big.Int) common.Address {
+	if c.IsFix(num) {
+		if c.FixAuthContract != (common.Address{}) {
+			return c.FixAuthContract
+		}
+		return MainnetFixAuthContract
+	}
+	return c.AuthContract
+}
+
+// IsCommunityFund returns whether num is either equal to the community fund
+// activation block or greater.
+func (c *ChainConfig) IsCommunityFund(num *big.Int) bool {
+	return c.CommunityFund != nil && isForked(c.CommunityFund.Block, num)
+}
+
+// IsDeployAuth returns whether num is either equal to the deployment
+// restriction activation block or greater.
+func (c *ChainConfig) IsDeployAuth(num *big.Int) bool {
+	return c.DeployAuth != nil && isForked(c.DeployAuth.Block, num)
+}
+
+// StateUpgradesAt returns the state upgrades scheduled at the start of the
+// given block.
+func (c *ChainConfig) StateUpgradesAt(num *big.Int) []UpgradeAction {
+	return c.stateUpgradesAt(num, false)
+}
+
+// FinalizeStateUpgradesAt returns the state upgrades scheduled at the end of
+// the given block.
+func (c *ChainConfig) FinalizeStateUpgradesAt(num *big.Int) []UpgradeAction {
+	return c.stateUpgradesAt(num, true)
+}
+
+func (c *ChainConfig) stateUpgradesAt(num *big.Int, finalize bool) []UpgradeAction {
+	if !num.IsUint64() {
+		return nil
+	}
+	var actions []UpgradeAction
+	for _, action := range c.stateUpgrades()[num.Uint64()] {
+		if action.Finalize == finalize {
+			actions = append(actions, action)
+		}
+	}
+	return actions
+}
+
+// stateUpgrades returns the state upgrade schedule of the chain. Clique chains
+// without an explicit schedule get the validator contract patches the engine
+// used to apply itself, derived from their clique config.
+func (c *ChainConfig) stateUpgrades() map[uint64][]UpgradeAction {
+	if len(c.StateUpgrades) > 0 || c.Clique == nil {
+		return c.StateUpgrades
+	}
+	return legacyStateUpgrades(common.HexToAddress(c.Clique.ValidatorContract), c.Clique.Poa2PosBlock)
+}
+
+// IsSubsidy returns whether num is either equal to the gas subsidy activation
+// block or greater.
+func (c *ChainConfig) IsSubsidy(num *big.Int) bool {
+	return c.Subsidy != nil && isForked(c.Subsidy.Block, num)
+}
+
+// IsReimburse returns whether num is either equal to the block from which the
+// validator contract reimburses the sponsored gas or greater.
+func (c *ChainConfig) IsReimburse(num *big.Int) bool {
+	return c.Subsidy != nil && isForked(c.Subsidy.ReimburseBlock, num)
+}
+
+// IsGasLimitBounds returns whether num is either equal to the gas limit bounds
+// fork block or greater.
+func (c *ChainConfig) IsGasLimitBounds(num *big.Int) bool {
+	return c.GasLimitBounds != nil && isForked(c.GasLimitBounds.Block, num)
+}
+
+// GasLimitBoundsAt returns the range the block gas limit must be in at the
+// given block.
+func (c *ChainConfig) GasLimitBoundsAt(num *big.Int) (uint64, uint64) {
+	min, max := MinGasLimit, MaxGasLimit
+	if c.IsGasLimitBounds(num) {
+		if c.GasLimitBounds.Min != 0 {
+			min = c.GasLimitBounds.Min
+		}
+		if c.GasLimitBounds.Max != 0 {
+			max = c.GasLimitBounds.Max
+		}
+	}
+	return min, max
+}
+
+// IsAuthTx returns whether num is either equal to the authenticated transaction
+// fork block or greater.
+func (c *ChainConfig) IsAuthTx(num *big.Int) bool {
+	return isForked(c.AuthTxBlock, num)
+}
+
 func (c *ChainConfig) IsGasPriceReqired(gasPrice *big.Int) bool {
 	return isForked(big.NewInt(c.ImplGasPrice()), gasPrice)
 }
@@ -711,6 +959,47 @@ func (c *ChainConfig) CheckConfigForkOrder() error {
 			lastFork = cur
 		}
 	}
+	if c.CommunityFund != nil {
+		if c.CommunityFund.Percentage > 100 {
+			return fmt.Errorf("invalid community fund percentage %d, must not exceed 100", c.CommunityFund.Percentage)
+		}
+		if c.Clique == nil {
+			return fmt.Errorf("community fund requires the clique engine")
+		}
+	}
+	if c.Subsidy != nil {
+		if c.Subsidy.GasPrice == nil || c.Subsidy.GasPrice.Sign() <= 0 {
+			return fmt.Errorf("invalid subsidy gas price %v, must be positive", c.Subsidy.GasPrice)
+		}
+		if c.AuthBlock == nil {
+			return fmt.Errorf("subsidy requires the auth contract to be configured")
+		}
+		if c.Subsidy.ReimburseBlock != nil && (c.Subsidy.Block == nil || c.Subsidy.ReimburseBlock.Cmp(c.Subsidy.Block) < 0) {
+			return fmt.Errorf("invalid subsidy reimburse block %v, must not precede the subsidy block %v", c.Subsidy.ReimburseBlock, c.Subsidy.Block)
+		}
+	}
+	if c.AuthTxBlock != nil {
+		if c.LondonBlock == nil || c.AuthTxBlock.Cmp(c.LondonBlock) < 0 {
+			return fmt.Errorf("unsupported fork ordering: authTxBlock %v enabled before londonBlock %v", c.AuthTxBlock, c.LondonBlock)
+		}
+		if c.AuthBlock == nil {
+			return fmt.Errorf("authenticated transactions require the auth contract to be configured")
+		}
+	}
+	if bounds := c.GasLimitBounds; bounds != nil {
+		if bounds.Min != 0 && bounds.Min < MinGasLimit {
+			return fmt.Errorf("invalid gas limit bounds: minimum %d below %d", bounds.Min, MinGasLimit)
+		}
+		if bounds.Max > MaxGasLimit {
+			return fmt.Errorf("invalid gas limit bounds: maximum %d above %d", bounds.Max, MaxGasLimit)
+		}
+		if bounds.Max != 0 && bounds.Min > bounds.Max {
+			return fmt.Errorf("invalid gas limit bounds: minimum %d above maximum %d", bounds.Min, bounds.Max)
+		}
+	}
+	if _, ok := c.StateUpgrades[0]; ok {
+		return fmt.Errorf("invalid state upgrade at genesis, use the genesis alloc instead")
+	}
 	return nil
 }
 
@@ -776,9 +1065,107 @@ func (c *ChainConfig) checkCompatible(newcfg *ChainConfig, head *big.Int) *Confi
 	if isForkIncompatible(c.CancunBlock, newcfg.CancunBlock, head) {
 		return newCompatError("Cancun fork block", c.CancunBlock, newcfg.CancunBlock)
 	}
+	if c.IsCommunityFund(head) || newcfg.IsCommunityFund(head) {
+		var oldBlock, newBlock *big.Int
+		if c.CommunityFund != nil {
+			oldBlock = c.CommunityFund.Block
+		}
+		if newcfg.CommunityFund != nil {
+			newBlock = newcfg.CommunityFund.Block
+		}
+		if isForkIncompatible(oldBlock, newBlock, head) {
+			return newCompatError("Community fund fork block", oldBlock, newBlock)
+		}
+		if c.CommunityFund == nil || newcfg.CommunityFund == nil ||
+			c.CommunityFund.Address != newcfg.CommunityFund.Address || c.CommunityFund.Percentage != newcfg.CommunityFund.Percentage {
+			return newCompatError("Community fund split", oldBlock, newBlock)
+		}
+	}
+	if c.IsDeployAuth(head) || newcfg.IsDeployAuth(head) {
+		var oldBlock, newBlock *big.Int
+		if c.DeployAuth != nil {
+			oldBlock = c.DeployAuth.Block
+		}
+		if newcfg.DeployAuth != nil {
+			newBlock = newcfg.DeployAuth.Block
+		}
+		if isForkIncompatible(oldBlock, newBlock, head) {
+			return newCompatError("Deploy auth fork block", oldBlock, newBlock)
+		}
+		if c.DeployAuth == nil || newcfg.DeployAuth == nil || !c.DeployAuth.sameAllowlist(newcfg.DeployAuth) {
+			return newCompatError("Deploy auth allowlist", oldBlock, newBlock)
+		}
+	}
+	if c.IsSubsidy(head) || newcfg.IsSubsidy(head) {
+		var oldBlock, newBlock *big.Int
+		if c.Subsidy != nil {
+			oldBlock = c.Subsidy.Block
+		}
+		if newcfg.Subsidy != nil {
+			newBlock = newcfg.Subsidy.Block
+		}
+		if isForkIncompatible(oldBlock, newBlock, head) {
+			return newCompatError("Subsidy fork block", oldBlock, newBlock)
+		}
+		if c.Subsidy == nil || newcfg.Subsidy == nil ||
+			!configNumEqual(c.Subsidy.GasPrice, newcfg.Subsidy.GasPrice) || c.Subsidy.BlockGasLimit != newcfg.Subsidy.BlockGasLimit {
+			return newCompatError("Subsidy limits", oldBlock, newBlock)
+		}
+		if isForkIncompatible(c.Subsidy.ReimburseBlock, newcfg.Subsidy.ReimburseBlock, head) {
+			return newCompatError("Subsidy reimburse block", c.Subsidy.ReimburseBlock, newcfg.Subsidy.ReimburseBlock)
+		}
+	}
+	if isForkIncompatible(c.AuthTxBlock, newcfg.AuthTxBlock, head) {
+		return newCompatError("Authenticated transaction fork block", c.AuthTxBlock, newcfg.AuthTxBlock)
+	}
+	if c.IsGasLimitBounds(head) || newcfg.IsGasLimitBounds(head) {
+		var oldBlock, newBlock *big.Int
+		if c.GasLimitBounds != nil {
+			oldBlock = c.GasLimitBounds.Block
+		}
+		if newcfg.GasLimitBounds != nil {
+			newBlock = newcfg.GasLimitBounds.Block
+		}
+		if isForkIncompatible(oldBlock, newBlock, head) {
+			return newCompatError("Gas limit bounds fork block", oldBlock, newBlock)
+		}
+		if c.GasLimitBounds == nil || newcfg.GasLimitBounds == nil ||
+			c.GasLimitBounds.Min != newcfg.GasLimitBounds.Min || c.GasLimitBounds.Max != newcfg.GasLimitBounds.Max {
+			return newCompatError("Gas limit bounds", oldBlock, newBlock)
+		}
+	}
+	if number, ok := stateUpgradesDiffer(c.stateUpgrades(), newcfg.stateUpgrades(), head); ok {
+		return newCompatError("State upgrade", new(big.Int).SetUint64(number), new(big.Int).SetUint64(number))
+	}
 	return nil
 }
 
+// stateUpgradesDiffer returns the first block up to the head whose scheduled
+// state upgrades differ between the two schedules, if any.
+func stateUpgradesDiffer(stored, updated map[uint64][]UpgradeAction, head *big.Int) (uint64, bool) {
+	var numbers []uint64
+	for number := range stored {
+		numbers = append(numbers, number)
+	}
+	for number := range updated {
+		if _, ok := stored[number]; !ok {
+			numbers = append(numbers, number)
+		}
+	}
+	sort.Slice(numbers, func(i, j int) bool { return numbers[i] < numbers[j] })
+	for _, number := range numbers {
+		if !isForked(new(big.Int).SetUint64(number), head) {
+			break
+		}
+		storedBlob, _ := json.Marshal(stored[number])
+		updatedBlob, _ := json.Marshal(updated[number])
+		if !bytes.Equal(storedBlob, updatedBlob) {
+			return number, true
+		}
+	}
+	return 0, false
+}
+
 // isForkIncompatible returns true if a fork scheduled at s1 cannot be rescheduled to
 // block s2 because head is already past the fork.
 func isForkIncompatible(s1, s2, head *big.Int) bool {
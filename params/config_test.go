@@ -17,9 +17,12 @@
 package params
 
 import (
+	"bytes"
 	"math/big"
 	"reflect"
 	"testing"
+
+	"github.com/qydata/go-ctereum/common"
 )
 
 func TestCheckCompatible(t *testing.T) {
@@ -87,6 +90,46 @@ func TestCheckCompatible(t *testing.T) {
 				RewindTo:     30,
 			},
 		},
+		{
+			stored: &ChainConfig{CommunityFund: &CommunityFundConfig{Block: big.NewInt(10), Percentage: 10}},
+			new:    &ChainConfig{CommunityFund: &CommunityFundConfig{Block: big.NewInt(10), Percentage: 20}},
+			head:   20,
+			wantErr: &ConfigCompatError{
+				What:         "Community fund split",
+				StoredConfig: big.NewInt(10),
+				NewConfig:    big.NewInt(10),
+				RewindTo:     9,
+			},
+		},
+		{
+			stored:  &ChainConfig{CommunityFund: &CommunityFundConfig{Block: big.NewInt(10), Percentage: 10}},
+			new:     &ChainConfig{CommunityFund: &CommunityFundConfig{Block: big.NewInt(10), Percentage: 20}},
+			head:    5,
+			wantErr: nil,
+		},
+		{
+			stored: &ChainConfig{DeployAuth: &DeployAuthConfig{Block: big.NewInt(10), Allowlist: []common.Address{{0x01}}}},
+			new:    &ChainConfig{DeployAuth: &DeployAuthConfig{Block: big.NewInt(10), Allowlist: []common.Address{{0x01}, {0x02}}}},
+			head:   20,
+			wantErr: &ConfigCompatError{
+				What:         "Deploy auth allowlist",
+				StoredConfig: big.NewInt(10),
+				NewConfig:    big.NewInt(10),
+				RewindTo:     9,
+			},
+		},
+		{
+			stored:  &ChainConfig{DeployAuth: &DeployAuthConfig{Block: big.NewInt(10), Allowlist: []common.Address{{0x01}, {0x02}}}},
+			new:     &ChainConfig{DeployAuth: &DeployAuthConfig{Block: big.NewInt(10), Allowlist: []common.Address{{0x02}, {0x01}}}},
+			head:    20,
+			wantErr: nil,
+		},
+		{
+			stored:  &ChainConfig{DeployAuth: &DeployAuthConfig{Block: big.NewInt(10), Allowlist: []common.Address{{0x01}}}},
+			new:     &ChainConfig{DeployAuth: &DeployAuthConfig{Block: big.NewInt(10)}},
+			head:    5,
+			wantErr: nil,
+		},
 	}
 
 	for _, test := range tests {
@@ -96,3 +139,165 @@ func TestCheckCompatible(t *testing.T) {
 		}
 	}
 }
+
+func TestCommunityFundSplit(t *testing.T) {
+	config := &CommunityFundConfig{Percentage: 30}
+
+	producer, fund := config.Split(big.NewInt(1001))
+	if producer.Int64() != 701 || fund.Int64() != 300 {
+		t.Errorf("split mismatch: have %v/%v, want 701/300", producer, fund)
+	}
+	invalid := &ChainConfig{Clique: &CliqueConfig{}, CommunityFund: &CommunityFundConfig{Percentage: 101}}
+	if err := invalid.CheckConfigForkOrder(); err == nil {
+		t.Errorf("expected error for percentage above 100")
+	}
+	noClique := &ChainConfig{CommunityFund: &CommunityFundConfig{Percentage: 30}}
+	if err := noClique.CheckConfigForkOrder(); err == nil {
+		t.Errorf("expected error for community fund without clique")
+	}
+}
+
+func TestSubsidyReimbursement(t *testing.T) {
+	config := &SubsidyConfig{GasPrice: big.NewInt(10)}
+	if have := config.Reimbursement(21000, big.NewInt(4)); have.Int64() != 126000 {
+		t.Errorf("reimbursement mismatch: have %v, want 126000", have)
+	}
+	invalid := &ChainConfig{AuthBlock: big.NewInt(0), Subsidy: &SubsidyConfig{GasPrice: big.NewInt(0)}}
+	if err := invalid.CheckConfigForkOrder(); err == nil {
+		t.Errorf("expected error for non-positive subsidy gas price")
+	}
+	noAuth := &ChainConfig{Subsidy: &SubsidyConfig{GasPrice: big.NewInt(10)}}
+	if err := noAuth.CheckConfigForkOrder(); err == nil {
+		t.Errorf("expected error for subsidy without auth contract")
+	}
+}
+
+func TestSubsidyReimburseBlock(t *testing.T) {
+	stored := &ChainConfig{AuthBlock: big.NewInt(0), Subsidy: &SubsidyConfig{Block: big.NewInt(10), GasPrice: big.NewInt(10), ReimburseBlock: big.NewInt(20)}}
+	if err := stored.CheckConfigForkOrder(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if stored.IsReimburse(big.NewInt(19)) || !stored.IsReimburse(big.NewInt(20)) {
+		t.Errorf("reimburse activation mismatch")
+	}
+	early := &ChainConfig{AuthBlock: big.NewInt(0), Subsidy: &SubsidyConfig{Block: big.NewInt(10), GasPrice: big.NewInt(10), ReimburseBlock: big.NewInt(5)}}
+	if err := early.CheckConfigForkOrder(); err == nil {
+		t.Errorf("expected error for reimburse block before the subsidy")
+	}
+	updated := &ChainConfig{AuthBlock: big.NewInt(0), Subsidy: &SubsidyConfig{Block: big.NewInt(10), GasPrice: big.NewInt(10), ReimburseBlock: big.NewInt(30)}}
+	if err := stored.CheckCompatible(updated, 19); err != nil {
+		t.Errorf("unexpected error before the reimburse block: %v", err)
+	}
+	if err := stored.CheckCompatible(updated, 20); err == nil || err.RewindTo != 19 {
+		t.Errorf("moved reimburse block error mismatch: %v", err)
+	}
+}
+
+func TestStateUpgradesCompatible(t *testing.T) {
+	action := UpgradeAction{Address: common.HexToAddress("0x01"), Code: []byte{0x00}}
+	stored := &ChainConfig{StateUpgrades: map[uint64][]UpgradeAction{10: {action}}}
+
+	changed := action
+	changed.Code = []byte{0x01}
+	updated := &ChainConfig{StateUpgrades: map[uint64][]UpgradeAction{10: {changed}}}
+	if err := stored.CheckCompatible(updated, 9); err != nil {
+		t.Errorf("unexpected error before the upgrade: %v", err)
+	}
+	if err := stored.CheckCompatible(updated, 10); err == nil || err.RewindTo != 9 {
+		t.Errorf("changed upgrade error mismatch: %v", err)
+	}
+	if err := stored.CheckCompatible(&ChainConfig{}, 10); err == nil {
+		t.Errorf("expected error for dropped upgrade")
+	}
+	if err := stored.CheckCompatible(&ChainConfig{StateUpgrades: map[uint64][]UpgradeAction{10: {action}, 20: {action}}}, 15); err != nil {
+		t.Errorf("unexpected error for future upgrade: %v", err)
+	}
+	genesis := &ChainConfig{StateUpgrades: map[uint64][]UpgradeAction{0: {action}}}
+	if err := genesis.CheckConfigForkOrder(); err == nil {
+		t.Errorf("expected error for upgrade at genesis")
+	}
+}
+
+// Tests that the main network patches moved from the clique engine into the
+// config are not reported as a change of the configs stored before.
+func TestMainnetStateUpgradesCompatible(t *testing.T) {
+	stored := *MainnetChainConfig
+	stored.StateUpgrades = nil
+	if err := stored.CheckCompatible(MainnetChainConfig, 6000000); err != nil {
+		t.Errorf("unexpected error for the legacy patches: %v", err)
+	}
+	if len(MainnetChainConfig.StateUpgradesAt(big.NewInt(5033499))) != 0 {
+		t.Errorf("legacy patches scheduled at the start of the block")
+	}
+	if len(MainnetChainConfig.FinalizeStateUpgradesAt(big.NewInt(5033499))) != 2 {
+		t.Errorf("legacy patches not scheduled at the end of the block")
+	}
+	changed := *MainnetChainConfig
+	changed.StateUpgrades = map[uint64][]UpgradeAction{
+		5033499: mainnetStateUpgrades[5033499],
+		5185000: {{Address: mainnetValidatorContract, Code: []byte{0x00}, Finalize: true}},
+	}
+	if err := stored.CheckCompatible(&changed, 6000000); err == nil || err.RewindTo != 5184999 {
+		t.Errorf("changed patch error mismatch: %v", err)
+	}
+}
+
+// Tests that clique chains without an explicit schedule keep the validator
+// contract patches the engine applied, derived from their own clique config.
+func TestLegacyStateUpgrades(t *testing.T) {
+	validator := common.HexToAddress("0xbb")
+	config := &ChainConfig{
+		Clique: &CliqueConfig{Period: 1, Epoch: 30000, ValidatorContract: validator.Hex(), Poa2PosBlock: 100},
+	}
+	actions := config.FinalizeStateUpgradesAt(big.NewInt(99))
+	if len(actions) != 2 {
+		t.Fatalf("switch patch count mismatch: have %d, want 2", len(actions))
+	}
+	if actions[0].Address != validator || !bytes.Equal(actions[0].Code, mainnetValidatorCodeV1) {
+		t.Errorf("switch patch mismatch: have %x", actions[0].Address)
+	}
+	if actions[1].AddBalance.Cmp(mainnetIssuance) != 0 {
+		t.Errorf("issuance mismatch: have %v, want %v", actions[1].AddBalance, mainnetIssuance)
+	}
+	if len(config.StateUpgradesAt(big.NewInt(99))) != 0 || len(config.FinalizeStateUpgradesAt(big.NewInt(100))) != 0 {
+		t.Errorf("switch patch scheduled at the wrong time")
+	}
+	actions = config.FinalizeStateUpgradesAt(big.NewInt(5185000))
+	if len(actions) != 1 || actions[0].Address != validator || !bytes.Equal(actions[0].Code, mainnetValidatorCodeV2) {
+		t.Errorf("contract fix mismatch: have %v", actions)
+	}
+	// An explicit schedule replaces the derived one
+	explicit := *config
+	explicit.StateUpgrades = map[uint64][]UpgradeAction{10: {{Address: validator, Code: []byte{0x00}, Finalize: true}}}
+	if len(explicit.FinalizeStateUpgradesAt(big.NewInt(99))) != 0 {
+		t.Errorf("derived patches applied next to an explicit schedule")
+	}
+	if err := config.CheckCompatible(&explicit, 200); err == nil {
+		t.Errorf("expected error for replaced patches")
+	}
+	if err := (&ChainConfig{}).CheckCompatible(&ChainConfig{}, 6000000); err != nil {
+		t.Errorf("unexpected error for non-clique chain: %v", err)
+	}
+	if len((&ChainConfig{}).FinalizeStateUpgradesAt(big.NewInt(5185000))) != 0 {
+		t.Errorf("patches derived for a non-clique chain")
+	}
+}
+
+func TestAuthContractAt(t *testing.T) {
+	var (
+		auth = common.HexToAddress("0x01")
+		fix  = common.HexToAddress("0x02")
+	)
+	config := &ChainConfig{AuthContract: auth}
+	if have := config.AuthContractAt(big.NewInt(100)); have != auth {
+		t.Errorf("pre-fix contract mismatch: have %x, want %x", have, auth)
+	}
+	// Unconfigured chains keep querying the legacy contract after the fix
+	if have := config.AuthContractAt(big.NewInt(5034751)); have != MainnetFixAuthContract {
+		t.Errorf("legacy fix contract mismatch: have %x, want %x", have, MainnetFixAuthContract)
+	}
+	config.FixAuthContract = fix
+	if have := config.AuthContractAt(big.NewInt(5034751)); have != fix {
+		t.Errorf("fix contract mismatch: have %x, want %x", have, fix)
+	}
+}
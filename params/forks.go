@@ -0,0 +1,130 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package params
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/qydata/go-ctereum/common"
+)
+
+// NetworkForks is the canonical configuration of the ct forks of a known network,
+// which the chain config of a node joining it must match.
+type NetworkForks struct {
+	Name              string
+	Poa2PosBlock      int64
+	AuthBlock         *big.Int
+	AuthContract      common.Address
+	FixAuthContract   common.Address
+	ValidatorContract common.Address
+}
+
+// KnownNetworkForks associates the canonical ct forks of the known networks with
+// their genesis hash.
+var KnownNetworkForks = map[common.Hash]*NetworkForks{
+	MainnetGenesisHash: {
+		Name:              "mainnet",
+		Poa2PosBlock:      MainnetChainConfig.Clique.Poa2PosBlock,
+		AuthBlock:         MainnetChainConfig.AuthBlock,
+		AuthContract:      MainnetAuthContract,
+		FixAuthContract:   MainnetFixAuthContract,
+		ValidatorContract: common.HexToAddress(MainnetChainConfig.Clique.ValidatorContract),
+	},
+}
+
+// ForkCheck is the comparison of a ct fork setting of a chain config with the
+// canonical one of its network.
+type ForkCheck struct {
+	Name  string `json:"name"`
+	Have  string `json:"have"`
+	Want  string `json:"want"`
+	Match bool   `json:"match"`
+}
+
+// ForkReadiness is the result of checking the ct fork settings of a chain config
+// against the canonical ones of its network.
+type ForkReadiness struct {
+	Network string      `json:"network"` // Name of the network, empty if unknown
+	Known   bool        `json:"known"`   // Whether the genesis belongs to a known network
+	Ready   bool        `json:"ready"`   // Whether all the settings match, true for unknown networks
+	Checks  []ForkCheck `json:"checks"`
+}
+
+// Mismatches returns the settings diverging from the canonical ones.
+func (r *ForkReadiness) Mismatches() []ForkCheck {
+	var mismatches []ForkCheck
+	for _, check := range r.Checks {
+		if !check.Match {
+			mismatches = append(mismatches, check)
+		}
+	}
+	return mismatches
+}
+
+// Error returns an error describing the diverging settings, or nil if ready.
+func (r *ForkReadiness) Error() error {
+	if r.Ready {
+		return nil
+	}
+	var diffs []string
+	for _, check := range r.Mismatches() {
+		diffs = append(diffs, fmt.Sprintf("%s: have %s, want %s", check.Name, check.Have, check.Want))
+	}
+	return fmt.Errorf("chain config diverges from the canonical %s forks (%s)", r.Network, strings.Join(diffs, ", "))
+}
+
+// CheckForkReadiness compares the ct fork blocks and contracts of the chain config
+// with the canonical ones of the network of the genesis, to detect stale genesis
+// files which would split the node off the network. Chains of unknown networks
+// are always ready.
+func CheckForkReadiness(genesis common.Hash, config *ChainConfig) *ForkReadiness {
+	forks, ok := KnownNetworkForks[genesis]
+	if !ok {
+		return &ForkReadiness{Ready: true, Checks: []ForkCheck{}}
+	}
+	var (
+		poa2pos    = "<nil>"
+		validators = "<nil>"
+	)
+	if config.Clique != nil {
+		poa2pos = fmt.Sprint(config.Clique.Poa2PosBlock)
+		validators = config.Clique.ValidatorContract
+		if common.IsHexAddress(validators) {
+			validators = common.HexToAddress(validators).Hex()
+		}
+	}
+	readiness := &ForkReadiness{
+		Network: forks.Name,
+		Known:   true,
+		Ready:   true,
+		Checks: []ForkCheck{
+			{Name: "poa2posBlock", Have: poa2pos, Want: fmt.Sprint(forks.Poa2PosBlock)},
+			{Name: "authBlock", Have: fmt.Sprint(config.AuthBlock), Want: fmt.Sprint(forks.AuthBlock)},
+			{Name: "authContract", Have: config.AuthContract.Hex(), Want: forks.AuthContract.Hex()},
+			{Name: "fixAuthContract", Have: config.AuthContractAt(new(big.Int).SetUint64(^uint64(0))).Hex(), Want: forks.FixAuthContract.Hex()},
+			{Name: "validatorContract", Have: validators, Want: forks.ValidatorContract.Hex()},
+		},
+	}
+	for i := range readiness.Checks {
+		check := &readiness.Checks[i]
+		check.Match = check.Have == check.Want
+		readiness.Ready = readiness.Ready && check.Match
+	}
+	return readiness
+}
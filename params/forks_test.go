@@ -0,0 +1,93 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package params
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/qydata/go-ctereum/common"
+)
+
+func TestCheckForkReadiness(t *testing.T) {
+	stale := func(modify func(c *ChainConfig)) *ChainConfig {
+		config := *MainnetChainConfig
+		clique := *config.Clique
+		config.Clique = &clique
+		modify(&config)
+		return &config
+	}
+	tests := []struct {
+		name       string
+		genesis    common.Hash
+		config     *ChainConfig
+		mismatches []string
+	}{
+		{name: "mainnet", genesis: MainnetGenesisHash, config: MainnetChainConfig},
+		{name: "unknown network", genesis: common.Hash{0x01}, config: TestChainConfig},
+		{
+			name:       "stale poa2pos block",
+			genesis:    MainnetGenesisHash,
+			config:     stale(func(c *ChainConfig) { c.Clique.Poa2PosBlock = 0 }),
+			mismatches: []string{"poa2posBlock"},
+		},
+		{
+			name:       "missing auth fork",
+			genesis:    MainnetGenesisHash,
+			config:     stale(func(c *ChainConfig) { c.AuthBlock, c.AuthContract = nil, common.Address{} }),
+			mismatches: []string{"authBlock", "authContract"},
+		},
+		{
+			name:    "lowercase validator contract",
+			genesis: MainnetGenesisHash,
+			config: stale(func(c *ChainConfig) {
+				c.Clique.ValidatorContract = "0xaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+			}),
+		},
+		{
+			name:       "wrong validator contract",
+			genesis:    MainnetGenesisHash,
+			config:     stale(func(c *ChainConfig) { c.Clique.ValidatorContract = "0xbb" }),
+			mismatches: []string{"validatorContract"},
+		},
+		{
+			name:    "wrong fix auth contract",
+			genesis: MainnetGenesisHash,
+			config: stale(func(c *ChainConfig) {
+				c.FixAuthContract = common.HexToAddress("0xcc")
+				c.AuthBlock = big.NewInt(5033582)
+			}),
+			mismatches: []string{"fixAuthContract"},
+		},
+	}
+	for _, tt := range tests {
+		readiness := CheckForkReadiness(tt.genesis, tt.config)
+		mismatches := readiness.Mismatches()
+		if len(mismatches) != len(tt.mismatches) {
+			t.Errorf("%s: mismatch count: have %v, want %v", tt.name, mismatches, tt.mismatches)
+			continue
+		}
+		for i, check := range mismatches {
+			if check.Name != tt.mismatches[i] {
+				t.Errorf("%s: mismatch %d: have %s, want %s", tt.name, i, check.Name, tt.mismatches[i])
+			}
+		}
+		if readiness.Ready != (len(tt.mismatches) == 0) || (readiness.Error() == nil) != readiness.Ready {
+			t.Errorf("%s: readiness mismatch: ready %v, error %v", tt.name, readiness.Ready, readiness.Error())
+		}
+	}
+}
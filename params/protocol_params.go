@@ -157,6 +157,10 @@ const (
 	// up to half the consumed gas could be refunded. Redefined as 1/5th in EIP-3529
 	RefundQuotient        uint64 = 2
 	RefundQuotientEIP3529 uint64 = 5
+
+	// MaxAuthProofLifetime is the longest time, in seconds, an auth proof of an
+	// authenticated transaction may remain valid past the block including it.
+	MaxAuthProofLifetime uint64 = 7 * 24 * 3600
 )
 
 // Gas discount table for BLS12-381 G1 and G2 multi exponentiation operations
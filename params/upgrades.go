@@ -0,0 +1,72 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package params
+
+import (
+	"math/big"
+
+	"github.com/qydata/go-ctereum/common"
+)
+
+// mainnetValidatorContract is the validator contract of the main network.
+var mainnetValidatorContract = common.HexToAddress("0xaAaAaAaaAaAaAaaAaAAAAAAAAaaaAaAaAaaAaaAa")
+
+// mainnetStateUpgrades are the validator contract patches of the main network,
+// applied at the end of their blocks as the clique engine originally did.
+var mainnetStateUpgrades = legacyStateUpgrades(mainnetValidatorContract, 5033500)
+
+// legacyStateUpgrades returns the validator contract patches the clique engine
+// applied to every chain before they were moved into the config, derived from
+// the validator contract and the proof-of-stake switch block of the chain.
+func legacyStateUpgrades(validator common.Address, poa2pos int64) map[uint64][]UpgradeAction {
+	upgrades := map[uint64][]UpgradeAction{
+		// Validator contract fix
+		5185000: {
+			{
+				Address:  validator,
+				Code:     mainnetValidatorCodeV2,
+				Finalize: true,
+			},
+		},
+	}
+	// Last block before the proof-of-stake switch, deploying the staking
+	// validator contract and minting the ten billion issuance. The genesis
+	// block is never finalized, so a switch at block one has no patch.
+	if poa2pos > 1 {
+		upgrades[uint64(poa2pos-1)] = append(upgrades[uint64(poa2pos-1)],
+			UpgradeAction{
+				Address:  validator,
+				Code:     mainnetValidatorCodeV1,
+				Finalize: true,
+			},
+			UpgradeAction{
+				Address:    common.HexToAddress("0xEa8943f4c47Ab8602eCCD3ed5087512f75C14E60"),
+				AddBalance: mainnetIssuance,
+				Finalize:   true,
+			},
+		)
+	}
+	return upgrades
+}
+
+// mainnetIssuance is the amount minted at the proof-of-stake switch.
+var mainnetIssuance, _ = new(big.Int).SetString("8974832090000000000000000000", 10)
+
+var (
+	mainnetValidatorCodeV1 = common.FromHex("0x6080604052600436106101145760003560e01c80638563e8c9116100a0578063d1bc0ee711610064578063d1bc0ee714610331578063e804fbf61461035e578063f2888dbb14610373578063f9fc17f514610393578063facd743b146103b357600080fd5b80638563e8c914610275578063b7ab4db5146102ab578063b9f8e7dc146102cf578063c5a222e4146102ef578063ca1e78191461030f57600080fd5b80633434735f116100e75780633434735f146101b7578063373d6132146101ea5780633fd3eb1f146101ff578063714ff425146102295780637a6eea371461023e57600080fd5b806302b75199146101195780630fbf5d92146101595780632367f6b51461016e57806326476204146101a4575b600080fd5b34801561012557600080fd5b506101466101343660046115e3565b60056020526000908152604090205481565b6040519081526020015b60405180910390f35b61016c610167366004611697565b6103ec565b005b34801561017a57600080fd5b506101466101893660046115e3565b6001600160a01b031660009081526002602052604090205490565b61016c6101b23660046115e3565b6104d1565b3480156101c357600080fd5b506101d26002600160a01b0381565b6040516001600160a01b039091168152602001610150565b3480156101f657600080fd5b50600654610146565b34801561020b57600080fd5b506009546102199060ff1681565b6040519015158152602001610150565b34801561023557600080fd5b50600754610146565b34801561024a57600080fd5b5061025d6a01a784379d99db4200000081565b6040516001600160801b039091168152602001610150565b34801561028157600080fd5b506101d26102903660046115e3565b6003602052600090815260409020546001600160a01b031681565b3480156102b757600080fd5b506102c061052c565b6040516101509392919061176e565b3480156102db57600080fd5b5061016c6102ea366004611675565b61086f565b3480156102fb57600080fd5b5061016c61030a366004611605565b6109c4565b34801561031b57600080fd5b50610324610b3d565b604051610150919061175b565b34801561033d57600080fd5b5061014661034c3660046115e3565b60046020526000908152604090205481565b34801561036a57600080fd5b50600854610146565b34801561037f57600080fd5b5061016c61038e3660046115e3565b610b9f565b34801561039f57600080fd5b5061016c6103ae366004611638565b610cce565b3480156103bf57600080fd5b506102196103ce3660046115e3565b6001600160a01b031660009081526001602052604090205460ff1690565b60095460ff161561043b5760405162461bcd60e51b8152602060048201526014602482015273416c726561647920696e697469616c697a65642160601b60448201526064015b60405180910390fd5b6007839055600882905560408051848152602081018490527f8288f503736de9545ced743c85bd6747df04791f503746e7e444d0015b7a7f77910160405180910390a160005b81518110156104be576104ac82828151811061049f5761049f611896565b6020026020010151610f1e565b806104b681611839565b915050610481565b50506009805460ff191660011790555050565b333b156105205760405162461bcd60e51b815260206004820152601b60248201527f4f6e6c7920454f412063616e2063616c6c2066756e6374696f6e2100000000006044820152606401610432565b61052981610f1e565b50565b6009546060908190819060ff1661063e57604080516001808252818301909252600091602080830190803683375050604080516001808252818301909252929350600092915060208083019080368337505060408051600180825281830190925292935060009291506020808301908036833701905050905073cebcbf16494edbad87d7feab0260ade82c571e5d836000815181106105cd576105cd611896565b60200260200101906001600160a01b031690816001600160a01b031681525050621e84808260008151811061060457610604611896565b602002602001018181525050621e84808160008151811061062757610627611896565b602090810291909101015291959094509092509050565b6000805467ffffffffffffffff81111561065a5761065a6118ac565b604051908082528060200260200182016040528015610683578160200160208202803683370190505b50600080549192509067ffffffffffffffff8111156106a4576106a46118ac565b6040519080825280602002602001820160405280156106cd578160200160208202803683370190505b50600080549192509067ffffffffffffffff8111156106ee576106ee6118ac565b604051908082528060200260200182016040528015610717578160200160208202803683370190505b50905060005b600054811015610862576000818154811061073a5761073a611896565b9060005260206000200160009054906101000a90046001600160a01b031684828151811061076a5761076a611896565b60200260200101906001600160a01b031690816001600160a01b031681525050670de0b6b3a7640000600260008084815481106107a9576107a9611896565b60009182526020808320909101546001600160a01b031683528201929092526040019020546107d891906117ef565b8382815181106107ea576107ea611896565b6020026020010181815250506004600080838154811061080c5761080c611896565b60009182526020808320909101546001600160a01b03168352820192909252604001902054825183908390811061084557610845611896565b60209081029190910101528061085a81611839565b91505061071d565b5091959094509092509050565b336002600160a01b03146108ba5760405162461bcd60e51b81526020600482015260126024820152714e6f742053797374656d204164646573732160701b6044820152606401610432565b81806108fc5760405162461bcd60e51b815260206004820152601160248201527076616c2063616e206e6f7420626520302160781b6044820152606401610432565b8183111561097c5760405162461bcd60e51b815260206004820152604160248201527f4d696e2076616c696461746f7273206e756d2063616e206e6f7420626520677260448201527f6561746572207468616e206d6178206e756d206f662076616c696461746f72736064820152602160f81b608482015260a401610432565b6007839055600882905560408051848152602081018490527f8288f503736de9545ced743c85bd6747df04791f503746e7e444d0015b7a7f77910160405180910390a1505050565b6001600160a01b038083166000908152600360205260409020548391163314610a2f5760405162461bcd60e51b815260206004820152601e60248201527f4f6e6c792073656e6465722063616e2063616c6c2066756e6374696f6e2100006044820152606401610432565b826001600160a01b038116610a7f5760405162461bcd60e51b8152602060048201526016602482015275616464722076616c2063616e206e6f7420626520302160501b6044820152606401610432565b826001600160a01b038116610acf5760405162461bcd60e51b8152602060048201526016602482015275616464722076616c2063616e206e6f7420626520302160501b6044820152606401610432565b6001600160a01b0385811660008181526003602090815260409182902080546001600160a01b031916948916948517905581519283528201929092527f831c28b544f77160ca9d466425fadde5c2e38b2370bf8079c4b67861d480536d910160405180910390a15050505050565b60606000805480602002602001604051908101604052809291908181526020018280548015610b9557602002820191906000526020600020905b81546001600160a01b03168152600190910190602001808311610b77575b5050505050905090565b333b15610bee5760405162461bcd60e51b815260206004820152601b60248201527f4f6e6c7920454f412063616e2063616c6c2066756e6374696f6e2100000000006044820152606401610432565b6001600160a01b0381166000908152600260205260409020548190610c555760405162461bcd60e51b815260206004820152601e60248201527f4f6e6c79207374616b65722063616e2063616c6c2066756e6374696f6e2100006044820152606401610432565b6001600160a01b038083166000908152600360205260409020548391163314610cc05760405162461bcd60e51b815260206004820152601e60248201527f4f6e6c792073656e6465722063616e2063616c6c2066756e6374696f6e2100006044820152606401610432565b610cc9836110ed565b505050565b336002600160a01b0314610d195760405162461bcd60e51b81526020600482015260126024820152714e6f742053797374656d204164646573732160701b6044820152606401610432565b60005b8151811015610f1a57670de0b6b3a764000060026000808481548110610d4457610d44611896565b60009182526020808320909101546001600160a01b03168352820192909252604001902054610d7391906117ef565b60046000848481518110610d8957610d89611896565b60200260200101516001600160a01b03166001600160a01b03168152602001908152602001600020541415610f085761271060046000848481518110610dd157610dd1611896565b60200260200101516001600160a01b03166001600160a01b031681526020019081526020016000206000828254610e089190611822565b9250508190555069021e19e0c9bab240000060066000828254610e2b9190611822565b9091555050604051339060009069021e19e0c9bab24000009082818181858883f19350505050158015610e62573d6000803e3d6000fd5b507f5c3feea8eff3540b84cbb449042c19315e2d8db6cce02c68ab8592d8a914ebcb828281518110610e9657610e96611896565b602002602001015160046000858581518110610eb457610eb4611896565b60200260200101516001600160a01b03166001600160a01b0316815260200190815260200160002054604051610eff9291906001600160a01b03929092168252602082015260400190565b60405180910390a15b80610f1281611839565b915050610d1c565b5050565b34610f625760405162461bcd60e51b81526020600482015260146024820152735374616b652076616c7565206973207a65726f2160601b6044820152606401610432565b3460066000828254610f7491906117b1565b90915550506001600160a01b03811660009081526002602052604081208054349290610fa19084906117b1565b90915550610fb99050670de0b6b3a7640000346117ef565b6001600160a01b03821660009081526004602052604081208054909190610fe19084906117b1565b90915550506001600160a01b038116600090815260036020526040902080546001600160a01b0319163317905561102b670de0b6b3a76400006a01a784379d99db420000006117c9565b6001600160a01b0382166000908152600460205260409020546001600160801b0391909116146110905760405162461bcd60e51b815260206004820152601060248201526f20b1b1bab69031b0b6319032b93937b960811b6044820152606401610432565b61109981611209565b156110a7576110a78161125b565b806001600160a01b03167f9e71bc8eea02a63969f509818f2dafb9254532904319f9dbda79b67bd34a5f3d346040516110e291815260200190565b60405180910390a250565b6001600160a01b0381166000908152600260205260408120805490829055600680549192839261111e908490611822565b90915550506001600160a01b03821660009081526001602052604090205460ff161561114d5761114d8261132c565b6001600160a01b03821660009081526004602052604090205461117890670de0b6b3a7640000611803565b6001600160a01b03831660008181526004602052604080822082905551929350909183156108fc0291849190818181858888f193505050501580156111c1573d6000803e3d6000fd5b50816001600160a01b03167f0f5bb82176feb1b5e747e28471aa92156a04d9f3ab9f45f28e2d704232b93f75826040516111fd91815260200190565b60405180910390a25050565b6001600160a01b03811660009081526001602052604081205460ff1615801561125557506001600160a01b0382166000908152600260205260409020546a01a784379d99db4200000011155b92915050565b600854600054106112bf5760405162461bcd60e51b815260206004820152602860248201527f56616c696461746f72207365742068617320726561636865642066756c6c2063604482015267617061636974792160c01b6064820152608401610432565b6001600160a01b03166000818152600160208181526040808420805460ff19168417905583546005909252832081905590810182559080527f290decd9548b62a8d60345a988386fc84ba6bc95484008f6362f93160ef3e5630180546001600160a01b0319169091179055565b600754600054116113af5760405162461bcd60e51b815260206004820152604160248201527f56616c696461746f72732063616e2774206265206c657373207468616e20746860448201527f65206d696e696d756d2072657175697265642076616c696461746f72206e756d6064820152602160f81b608482015260a401610432565b600080546001600160a01b038316825260056020526040909120541061140d5760405162461bcd60e51b8152602060048201526013602482015272696e646578206f7574206f662072616e67652160681b6044820152606401610432565b6001600160a01b038116600090815260056020526040812054815490919061143790600190611822565b90508082146114bc57600080828154811061145457611454611896565b600091825260208220015481546001600160a01b0390911692508291908590811061148157611481611896565b600091825260208083209190910180546001600160a01b0319166001600160a01b039485161790559290911681526005909152604090208290555b6001600160a01b0383166000908152600160209081526040808320805460ff19169055600590915281208190558054806114f8576114f8611880565b600082815260209020810160001990810180546001600160a01b0319169055019055505050565b80356001600160a01b038116811461153657600080fd5b919050565b600082601f83011261154c57600080fd5b8135602067ffffffffffffffff80831115611569576115696118ac565b8260051b604051601f19603f8301168101818110848211171561158e5761158e6118ac565b604052848152838101925086840182880185018910156115ad57600080fd5b600092505b858310156115d7576115c38161151f565b8452928401926001929092019184016115b2565b50979650505050505050565b6000602082840312156115f557600080fd5b6115fe8261151f565b9392505050565b6000806040838503121561161857600080fd5b6116218361151f565b915061162f6020840161151f565b90509250929050565b60006020828403121561164a57600080fd5b813567ffffffffffffffff81111561166157600080fd5b61166d8482850161153b565b949350505050565b6000806040838503121561168857600080fd5b50508035926020909101359150565b6000806000606084860312156116ac57600080fd5b8335925060208401359150604084013567ffffffffffffffff8111156116d157600080fd5b6116dd8682870161153b565b9150509250925092565b600081518084526020808501945080840160005b838110156117205781516001600160a01b0316875295820195908201906001016116fb565b509495945050505050565b600081518084526020808501945080840160005b838110156117205781518752958201959082019060010161173f565b6020815260006115fe60208301846116e7565b60608152600061178160608301866116e7565b8281036020840152611793818661172b565b905082810360408401526117a7818561172b565b9695505050505050565b600082198211156117c4576117c4611854565b500190565b60006001600160801b03808416806117e3576117e361186a565b92169190910492915050565b6000826117fe576117fe61186a565b500490565b600081600019048311821515161561181d5761181d611854565b500290565b60008282101561183457611834611854565b500390565b600060001982141561184d5761184d611854565b5060010190565b634e487b7160e01b600052601160045260246000fd5b634e487b7160e01b600052601260045260246000fd5b634e487b7160e01b600052603160045260246000fd5b634e487b7160e01b600052603260045260246000fd5b634e487b7160e01b600052604160045260246000fdfea264697066735822122038a908c2c4bc79ece6d2485297ba5769f998623c52c2fbb896c50f12d642a04a64736f6c63430008070033")
+	mainnetValidatorCodeV2 = common.FromHex("0x6080604052600436106101145760003560e01c80638563e8c9116100a0578063d1bc0ee711610064578063d1bc0ee714610331578063e804fbf61461035e578063f2888dbb14610373578063f9fc17f514610393578063facd743b146103b357600080fd5b80638563e8c914610275578063b7ab4db5146102ab578063b9f8e7dc146102cf578063c5a222e4146102ef578063ca1e78191461030f57600080fd5b80633434735f116100e75780633434735f146101b7578063373d6132146101ea5780633fd3eb1f146101ff578063714ff425146102295780637a6eea371461023e57600080fd5b806302b75199146101195780630fbf5d92146101595780632367f6b51461016e57806326476204146101a4575b600080fd5b34801561012557600080fd5b50610146610134366004611603565b60056020526000908152604090205481565b6040519081526020015b60405180910390f35b61016c6101673660046116b7565b6103ec565b005b34801561017a57600080fd5b50610146610189366004611603565b6001600160a01b031660009081526002602052604090205490565b61016c6101b2366004611603565b6104d1565b3480156101c357600080fd5b506101d26002600160a01b0381565b6040516001600160a01b039091168152602001610150565b3480156101f657600080fd5b50600654610146565b34801561020b57600080fd5b506009546102199060ff1681565b6040519015158152602001610150565b34801561023557600080fd5b50600754610146565b34801561024a57600080fd5b5061025d6a01a784379d99db4200000081565b6040516001600160801b039091168152602001610150565b34801561028157600080fd5b506101d2610290366004611603565b6003602052600090815260409020546001600160a01b031681565b3480156102b757600080fd5b506102c061052c565b6040516101509392919061178e565b3480156102db57600080fd5b5061016c6102ea366004611695565b61086f565b3480156102fb57600080fd5b5061016c61030a366004611625565b6109c4565b34801561031b57600080fd5b50610324610b3d565b604051610150919061177b565b34801561033d57600080fd5b5061014661034c366004611603565b60046020526000908152604090205481565b34801561036a57600080fd5b50600854610146565b34801561037f57600080fd5b5061016c61038e366004611603565b610b9f565b34801561039f57600080fd5b5061016c6103ae366004611658565b610cce565b3480156103bf57600080fd5b506102196103ce366004611603565b6001600160a01b031660009081526001602052604090205460ff1690565b60095460ff161561043b5760405162461bcd60e51b8152602060048201526014602482015273416c726561647920696e697469616c697a65642160601b60448201526064015b60405180910390fd5b6007839055600882905560408051848152602081018490527f8288f503736de9545ced743c85bd6747df04791f503746e7e444d0015b7a7f77910160405180910390a160005b81518110156104be576104ac82828151811061049f5761049f6118b6565b6020026020010151610f1e565b806104b681611859565b915050610481565b50506009805460ff191660011790555050565b333b156105205760405162461bcd60e51b815260206004820152601b60248201527f4f6e6c7920454f412063616e2063616c6c2066756e6374696f6e2100000000006044820152606401610432565b61052981610f1e565b50565b6009546060908190819060ff1661063e57604080516001808252818301909252600091602080830190803683375050604080516001808252818301909252929350600092915060208083019080368337505060408051600180825281830190925292935060009291506020808301908036833701905050905073cebcbf16494edbad87d7feab0260ade82c571e5d836000815181106105cd576105cd6118b6565b60200260200101906001600160a01b031690816001600160a01b031681525050621e848082600081518110610604576106046118b6565b602002602001018181525050621e848081600081518110610627576106276118b6565b602090810291909101015291959094509092509050565b6000805467ffffffffffffffff81111561065a5761065a6118cc565b604051908082528060200260200182016040528015610683578160200160208202803683370190505b50600080549192509067ffffffffffffffff8111156106a4576106a46118cc565b6040519080825280602002602001820160405280156106cd578160200160208202803683370190505b50600080549192509067ffffffffffffffff8111156106ee576106ee6118cc565b604051908082528060200260200182016040528015610717578160200160208202803683370190505b50905060005b600054811015610862576000818154811061073a5761073a6118b6565b9060005260206000200160009054906101000a90046001600160a01b031684828151811061076a5761076a6118b6565b60200260200101906001600160a01b031690816001600160a01b031681525050670de0b6b3a7640000600260008084815481106107a9576107a96118b6565b60009182526020808320909101546001600160a01b031683528201929092526040019020546107d8919061180f565b8382815181106107ea576107ea6118b6565b6020026020010181815250506004600080838154811061080c5761080c6118b6565b60009182526020808320909101546001600160a01b031683528201929092526040019020548251839083908110610845576108456118b6565b60209081029190910101528061085a81611859565b91505061071d565b5091959094509092509050565b336002600160a01b03146108ba5760405162461bcd60e51b81526020600482015260126024820152714e6f742053797374656d204164646573732160701b6044820152606401610432565b81806108fc5760405162461bcd60e51b815260206004820152601160248201527076616c2063616e206e6f7420626520302160781b6044820152606401610432565b8183111561097c5760405162461bcd60e51b815260206004820152604160248201527f4d696e2076616c696461746f7273206e756d2063616e206e6f7420626520677260448201527f6561746572207468616e206d6178206e756d206f662076616c696461746f72736064820152602160f81b608482015260a401610432565b6007839055600882905560408051848152602081018490527f8288f503736de9545ced743c85bd6747df04791f503746e7e444d0015b7a7f77910160405180910390a1505050565b6001600160a01b038083166000908152600360205260409020548391163314610a2f5760405162461bcd60e51b815260206004820152601e60248201527f4f6e6c792073656e6465722063616e2063616c6c2066756e6374696f6e2100006044820152606401610432565b826001600160a01b038116610a7f5760405162461bcd60e51b8152602060048201526016602482015275616464722076616c2063616e206e6f7420626520302160501b6044820152606401610432565b826001600160a01b038116610acf5760405162461bcd60e51b8152602060048201526016602482015275616464722076616c2063616e206e6f7420626520302160501b6044820152606401610432565b6001600160a01b0385811660008181526003602090815260409182902080546001600160a01b031916948916948517905581519283528201929092527f831c28b544f77160ca9d466425fadde5c2e38b2370bf8079c4b67861d480536d910160405180910390a15050505050565b60606000805480602002602001604051908101604052809291908181526020018280548015610b9557602002820191906000526020600020905b81546001600160a01b03168152600190910190602001808311610b77575b5050505050905090565b333b15610bee5760405162461bcd60e51b815260206004820152601b60248201527f4f6e6c7920454f412063616e2063616c6c2066756e6374696f6e2100000000006044820152606401610432565b6001600160a01b0381166000908152600260205260409020548190610c555760405162461bcd60e51b815260206004820152601e60248201527f4f6e6c79207374616b65722063616e2063616c6c2066756e6374696f6e2100006044820152606401610432565b6001600160a01b038083166000908152600360205260409020548391163314610cc05760405162461bcd60e51b815260206004820152601e60248201527f4f6e6c792073656e6465722063616e2063616c6c2066756e6374696f6e2100006044820152606401610432565b610cc98361110d565b505050565b336002600160a01b0314610d195760405162461bcd60e51b81526020600482015260126024820152714e6f742053797374656d204164646573732160701b6044820152606401610432565b60005b8151811015610f1a57670de0b6b3a764000060026000808481548110610d4457610d446118b6565b60009182526020808320909101546001600160a01b03168352820192909252604001902054610d73919061180f565b60046000848481518110610d8957610d896118b6565b60200260200101516001600160a01b03166001600160a01b03168152602001908152602001600020541415610f085761271060046000848481518110610dd157610dd16118b6565b60200260200101516001600160a01b03166001600160a01b031681526020019081526020016000206000828254610e089190611842565b9250508190555069021e19e0c9bab240000060066000828254610e2b9190611842565b9091555050604051339060009069021e19e0c9bab24000009082818181858883f19350505050158015610e62573d6000803e3d6000fd5b507f5c3feea8eff3540b84cbb449042c19315e2d8db6cce02c68ab8592d8a914ebcb828281518110610e9657610e966118b6565b602002602001015160046000858581518110610eb457610eb46118b6565b60200260200101516001600160a01b03166001600160a01b0316815260200190815260200160002054604051610eff9291906001600160a01b03929092168252602082015260400190565b60405180910390a15b80610f1281611859565b915050610d1c565b5050565b34610f625760405162461bcd60e51b81526020600482015260146024820152735374616b652076616c7565206973207a65726f2160601b6044820152606401610432565b3460066000828254610f7491906117d1565b90915550506001600160a01b03811660009081526002602052604081208054349290610fa19084906117d1565b90915550610fb99050670de0b6b3a76400003461180f565b6001600160a01b03821660009081526004602052604081208054909190610fe19084906117d1565b90915550506001600160a01b038181166000908152600360205260409020541661102e576001600160a01b038116600090815260036020526040902080546001600160a01b031916331790555b61104b670de0b6b3a76400006a01a784379d99db420000006117e9565b6001600160a01b0382166000908152600460205260409020546001600160801b0391909116146110b05760405162461bcd60e51b815260206004820152601060248201526f20b1b1bab69031b0b6319032b93937b960811b6044820152606401610432565b6110b981611229565b156110c7576110c78161127b565b806001600160a01b03167f9e71bc8eea02a63969f509818f2dafb9254532904319f9dbda79b67bd34a5f3d3460405161110291815260200190565b60405180910390a250565b6001600160a01b0381166000908152600260205260408120805490829055600680549192839261113e908490611842565b90915550506001600160a01b03821660009081526001602052604090205460ff161561116d5761116d8261134c565b6001600160a01b03821660009081526004602052604090205461119890670de0b6b3a7640000611823565b6001600160a01b03831660008181526004602052604080822082905551929350909183156108fc0291849190818181858888f193505050501580156111e1573d6000803e3d6000fd5b50816001600160a01b03167f0f5bb82176feb1b5e747e28471aa92156a04d9f3ab9f45f28e2d704232b93f758260405161121d91815260200190565b60405180910390a25050565b6001600160a01b03811660009081526001602052604081205460ff1615801561127557506001600160a01b0382166000908152600260205260409020546a01a784379d99db4200000011155b92915050565b600854600054106112df5760405162461bcd60e51b815260206004820152602860248201527f56616c696461746f72207365742068617320726561636865642066756c6c2063604482015267617061636974792160c01b6064820152608401610432565b6001600160a01b03166000818152600160208181526040808420805460ff19168417905583546005909252832081905590810182559080527f290decd9548b62a8d60345a988386fc84ba6bc95484008f6362f93160ef3e5630180546001600160a01b0319169091179055565b600754600054116113cf5760405162461bcd60e51b815260206004820152604160248201527f56616c696461746f72732063616e2774206265206c657373207468616e20746860448201527f65206d696e696d756d2072657175697265642076616c696461746f72206e756d6064820152602160f81b608482015260a401610432565b600080546001600160a01b038316825260056020526040909120541061142d5760405162461bcd60e51b8152602060048201526013602482015272696e646578206f7574206f662072616e67652160681b6044820152606401610432565b6001600160a01b038116600090815260056020526040812054815490919061145790600190611842565b90508082146114dc576000808281548110611474576114746118b6565b600091825260208220015481546001600160a01b039091169250829190859081106114a1576114a16118b6565b600091825260208083209190910180546001600160a01b0319166001600160a01b039485161790559290911681526005909152604090208290555b6001600160a01b0383166000908152600160209081526040808320805460ff1916905560059091528120819055805480611518576115186118a0565b600082815260209020810160001990810180546001600160a01b0319169055019055505050565b80356001600160a01b038116811461155657600080fd5b919050565b600082601f83011261156c57600080fd5b8135602067ffffffffffffffff80831115611589576115896118cc565b8260051b604051601f19603f830116810181811084821117156115ae576115ae6118cc565b604052848152838101925086840182880185018910156115cd57600080fd5b600092505b858310156115f7576115e38161153f565b8452928401926001929092019184016115d2565b50979650505050505050565b60006020828403121561161557600080fd5b61161e8261153f565b9392505050565b6000806040838503121561163857600080fd5b6116418361153f565b915061164f6020840161153f565b90509250929050565b60006020828403121561166a57600080fd5b813567ffffffffffffffff81111561168157600080fd5b61168d8482850161155b565b949350505050565b600080604083850312156116a857600080fd5b50508035926020909101359150565b6000806000606084860312156116cc57600080fd5b8335925060208401359150604084013567ffffffffffffffff8111156116f157600080fd5b6116fd8682870161155b565b9150509250925092565b600081518084526020808501945080840160005b838110156117405781516001600160a01b03168752958201959082019060010161171b565b509495945050505050565b600081518084526020808501945080840160005b838110156117405781518752958201959082019060010161175f565b60208152600061161e6020830184611707565b6060815260006117a16060830186611707565b82810360208401526117b3818661174b565b905082810360408401526117c7818561174b565b9695505050505050565b600082198211156117e4576117e4611874565b500190565b60006001600160801b03808416806118035761180361188a565b92169190910492915050565b60008261181e5761181e61188a565b500490565b600081600019048311821515161561183d5761183d611874565b500290565b60008282101561185457611854611874565b500390565b600060001982141561186d5761186d611874565b5060010190565b634e487b7160e01b600052601160045260246000fd5b634e487b7160e01b600052601260045260246000fd5b634e487b7160e01b600052603160045260246000fd5b634e487b7160e01b600052603260045260246000fd5b634e487b7160e01b600052604160045260246000fdfea2646970667358221220af75210a1c8fcf837815c811fbf682efb6463953082e5de74718034419c9756064736f6c63430008070033")
+)
@@ -0,0 +1,156 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ConnInfo describes a persistent connection served by the server, along with the
+// messages exchanged over it and its active subscriptions.
+type ConnInfo struct {
+	ID            ID                 `json:"id"`
+	Transport     string             `json:"transport"`
+	RemoteAddr    string             `json:"remoteAddress"`
+	Origin        string             `json:"origin,omitempty"`
+	UserAgent     string             `json:"userAgent,omitempty"`
+	Connected     time.Time          `json:"connected"`
+	Received      uint64             `json:"received"` // Messages received, counting batch items
+	Sent          uint64             `json:"sent"`     // Responses and notifications sent
+	Subscriptions []SubscriptionInfo `json:"subscriptions"`
+}
+
+// SubscriptionInfo describes a subscription active on a connection.
+type SubscriptionInfo struct {
+	ID     ID     `json:"id"`
+	Method string `json:"method"` // Namespace and name of the subscription, e.g. eth_logs
+}
+
+// trackedCodec wraps the codec of a persistent connection, counting the messages
+// exchanged and recording the subscriptions created over it.
+type trackedCodec struct {
+	ServerCodec
+	id        ID
+	info      PeerInfo
+	connected time.Time
+	received  uint64 // atomic
+	sent      uint64 // atomic
+
+	lock sync.Mutex
+	subs map[ID]string // Method of the active subscriptions
+}
+
+func newTrackedCodec(codec ServerCodec) *trackedCodec {
+	return &trackedCodec{
+		ServerCodec: codec,
+		id:          NewID(),
+		info:        codec.peerInfo(),
+		connected:   time.Now(),
+		subs:        make(map[ID]string),
+	}
+}
+
+func (c *trackedCodec) readBatch() ([]*jsonrpcMessage, bool, error) {
+	msgs, batch, err := c.ServerCodec.readBatch()
+	atomic.AddUint64(&c.received, uint64(len(msgs)))
+	return msgs, batch, err
+}
+
+func (c *trackedCodec) writeJSON(ctx context.Context, v interface{}) error {
+	err := c.ServerCodec.writeJSON(ctx, v)
+	if err == nil {
+		atomic.AddUint64(&c.sent, 1)
+	}
+	return err
+}
+
+func (c *trackedCodec) addSubscription(id ID, method string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.subs[id] = method
+}
+
+func (c *trackedCodec) removeSubscription(id ID) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	delete(c.subs, id)
+}
+
+// connInfo returns the description of the connection.
+func (c *trackedCodec) connInfo() ConnInfo {
+	info := ConnInfo{
+		ID:            c.id,
+		Transport:     c.info.Transport,
+		RemoteAddr:    c.info.RemoteAddr,
+		Origin:        c.info.HTTP.Origin,
+		UserAgent:     c.info.HTTP.UserAgent,
+		Connected:     c.connected,
+		Received:      atomic.LoadUint64(&c.received),
+		Sent:          atomic.LoadUint64(&c.sent),
+		Subscriptions: []SubscriptionInfo{},
+	}
+	c.lock.Lock()
+	for id, method := range c.subs {
+		info.Subscriptions = append(info.Subscriptions, SubscriptionInfo{ID: id, Method: method})
+	}
+	c.lock.Unlock()
+
+	sort.Slice(info.Subscriptions, func(i, j int) bool {
+		return info.Subscriptions[i].ID < info.Subscriptions[j].ID
+	})
+	return info
+}
+
+// Connections returns the persistent connections served, i.e. the websocket and
+// IPC ones, oldest first.
+func (s *Server) Connections() []ConnInfo {
+	infos := []ConnInfo{}
+	s.codecs.Each(func(c interface{}) bool {
+		if codec, ok := c.(*trackedCodec); ok {
+			infos = append(infos, codec.connInfo())
+		}
+		return false
+	})
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Connected.Before(infos[j].Connected)
+	})
+	return infos
+}
+
+// CloseConnection terminates the persistent connection with the given id, along
+// with its subscriptions. It reports whether the connection was found.
+func (s *Server) CloseConnection(id ID) bool {
+	var found *trackedCodec
+	s.codecs.Each(func(c interface{}) bool {
+		if codec, ok := c.(*trackedCodec); ok && codec.id == id {
+			found = codec
+			return true
+		}
+		return false
+	})
+	if found == nil {
+		return false
+	}
+	found.close()
+	return true
+}
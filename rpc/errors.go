@@ -54,6 +54,9 @@ var (
 	_ Error = new(invalidRequestError)
 	_ Error = new(invalidMessageError)
 	_ Error = new(invalidParamsError)
+	_ Error = new(methodDeniedError)
+	_ Error = new(rateLimitedError)
+	_ Error = new(responseTooLargeError)
 )
 
 const defaultErrorCode = -32000
@@ -74,6 +77,29 @@ func (e *subscriptionNotFoundError) Error() string {
 	return fmt.Sprintf("no %q subscription in %s namespace", e.subscription, e.namespace)
 }
 
+// method rejected by the access policy of the server
+type methodDeniedError struct{ method string }
+
+func (e *methodDeniedError) ErrorCode() int { return -32004 }
+
+func (e *methodDeniedError) Error() string {
+	return fmt.Sprintf("the method %s is not allowed", e.method)
+}
+
+// request rejected by the rate limits of the server
+type rateLimitedError struct{ message string }
+
+func (e *rateLimitedError) ErrorCode() int { return -32005 }
+
+func (e *rateLimitedError) Error() string { return e.message }
+
+// batch response exceeding the size limit of the server
+type responseTooLargeError struct{}
+
+func (e *responseTooLargeError) ErrorCode() int { return -32003 }
+
+func (e *responseTooLargeError) Error() string { return "response too large" }
+
 // Invalid JSON was received by the server.
 type parseError struct{ message string }
 
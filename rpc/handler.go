@@ -19,10 +19,12 @@ package rpc
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"reflect"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/qydata/go-ctereum/log"
@@ -100,6 +102,20 @@ func (h *handler) handleBatch(msgs []*jsonrpcMessage) {
 		})
 		return
 	}
+	// Reject the batches exceeding the item limit as a whole:
+	if limit := h.reg.batchLimits().Items; limit > 0 && len(msgs) > limit {
+		h.startCallProc(func(cp *callProc) {
+			resp := errorMessage(&invalidRequestError{fmt.Sprintf("batch too large: %d items, limit %d", len(msgs), limit)})
+			for _, msg := range msgs {
+				if msg.isCall() {
+					resp.ID = msg.ID
+					break
+				}
+			}
+			h.conn.writeJSON(cp.ctx, []*jsonrpcMessage{resp})
+		})
+		return
+	}
 
 	// Handle non-call messages first:
 	calls := make([]*jsonrpcMessage, 0, len(msgs))
@@ -113,12 +129,7 @@ func (h *handler) handleBatch(msgs []*jsonrpcMessage) {
 	}
 	// Process calls on a goroutine because they may block indefinitely:
 	h.startCallProc(func(cp *callProc) {
-		answers := make([]*jsonrpcMessage, 0, len(msgs))
-		for _, msg := range calls {
-			if answer := h.handleCallMsg(cp, msg); answer != nil {
-				answers = append(answers, answer)
-			}
-		}
+		answers := h.handleCalls(cp, calls)
 		h.addSubscriptions(cp.notifiers)
 		if len(answers) > 0 {
 			h.conn.writeJSON(cp.ctx, answers)
@@ -129,6 +140,69 @@ func (h *handler) handleBatch(msgs []*jsonrpcMessage) {
 	})
 }
 
+// handleCalls executes the calls of a batch on up to the configured number of
+// concurrent workers and returns their answers in order. Once the results exceed
+// the response size limit, the calls not started yet are answered with an error.
+func (h *handler) handleCalls(cp *callProc, calls []*jsonrpcMessage) []*jsonrpcMessage {
+	limits := h.reg.batchLimits()
+
+	workers := limits.Workers
+	if workers > len(calls) {
+		workers = len(calls)
+	}
+	for _, msg := range calls {
+		// Subscriptions register their notifiers on the call proc, keep them in order
+		if msg.isSubscribe() {
+			workers = 1
+			break
+		}
+	}
+	var (
+		results = make([]*jsonrpcMessage, len(calls))
+		next    = int64(-1)
+		size    int64
+	)
+	work := func() {
+		for {
+			i := int(atomic.AddInt64(&next, 1))
+			if i >= len(calls) {
+				return
+			}
+			msg := calls[i]
+			if limits.ResponseSize > 0 && atomic.LoadInt64(&size) > int64(limits.ResponseSize) {
+				if !msg.isNotification() {
+					results[i] = msg.errorResponse(&responseTooLargeError{})
+				}
+				continue
+			}
+			if answer := h.handleCallMsg(cp, msg); answer != nil {
+				atomic.AddInt64(&size, int64(len(answer.Result)))
+				results[i] = answer
+			}
+		}
+	}
+	if workers <= 1 {
+		work()
+	} else {
+		var wg sync.WaitGroup
+		wg.Add(workers)
+		for i := 0; i < workers; i++ {
+			go func() {
+				defer wg.Done()
+				work()
+			}()
+		}
+		wg.Wait()
+	}
+	answers := make([]*jsonrpcMessage, 0, len(results))
+	for _, answer := range results {
+		if answer != nil {
+			answers = append(answers, answer)
+		}
+	}
+	return answers
+}
+
 // handleMsg handles a single message.
 func (h *handler) handleMsg(msg *jsonrpcMessage) {
 	if ok := h.handleImmediate(msg); ok {
@@ -199,6 +273,9 @@ func (h *handler) addSubscriptions(nn []*Notifier) {
 	for _, n := range nn {
 		if sub := n.takeSubscription(); sub != nil {
 			h.serverSubs[sub.ID] = sub
+			if tracked, ok := h.conn.(*trackedCodec); ok {
+				tracked.addSubscription(sub.ID, n.namespace+"_"+n.name)
+			}
 		}
 	}
 }
@@ -316,6 +393,9 @@ func (h *handler) handleCallMsg(ctx *callProc, msg *jsonrpcMessage) *jsonrpcMess
 
 // handleCall processes method calls.
 func (h *handler) handleCall(cp *callProc, msg *jsonrpcMessage) *jsonrpcMessage {
+	if err := h.reg.checkPolicy(msg.Method, PeerInfoFromContext(cp.ctx)); err != nil {
+		return msg.errorResponse(err)
+	}
 	if msg.isSubscribe() {
 		return h.handleSubscribe(cp, msg)
 	}
@@ -376,7 +456,7 @@ func (h *handler) handleSubscribe(cp *callProc, msg *jsonrpcMessage) *jsonrpcMes
 	args = args[1:]
 
 	// Install notifier in context so the subscription handler can find it.
-	n := &Notifier{h: h, namespace: namespace}
+	n := &Notifier{h: h, namespace: namespace, name: name}
 	cp.notifiers = append(cp.notifiers, n)
 	ctx := context.WithValue(cp.ctx, notifierKey{}, n)
 
@@ -403,6 +483,9 @@ func (h *handler) unsubscribe(ctx context.Context, id ID) (bool, error) {
 	}
 	close(s.err)
 	delete(h.serverSubs, id)
+	if tracked, ok := h.conn.(*trackedCodec); ok {
+		tracked.removeSubscription(id)
+	}
 	return true, nil
 }
 
@@ -0,0 +1,200 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"math"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+const (
+	// policyClientExpiry is the time after which the rate limiter of an idle
+	// client is dropped.
+	policyClientExpiry = 5 * time.Minute
+
+	// policySweepInterval is the interval at which idle clients are dropped.
+	policySweepInterval = time.Minute
+)
+
+// PolicyConfig is the access and rate limiting policy of an RPC server. Rules
+// are keyed either by namespace (e.g. "debug") or by method (e.g.
+// "eth_getLogs"), the method rules taking precedence over the namespace ones.
+type PolicyConfig struct {
+	// Allow lists the methods and namespaces that may be called. All of them may
+	// be called if the list is empty.
+	Allow []string `toml:",omitempty"`
+
+	// Deny lists the methods and namespaces that may not be called, overriding
+	// the allow list for the same method or namespace.
+	Deny []string `toml:",omitempty"`
+
+	// MethodRates caps the number of requests per second to a method or to a
+	// namespace, over all the clients.
+	MethodRates map[string]float64 `toml:",omitempty"`
+
+	// IPRate caps the number of requests per second of a single client IP over
+	// all methods. Unlimited if zero.
+	IPRate float64 `toml:",omitempty"`
+
+	// IPBurst is the number of requests a client IP may issue at once above its
+	// rate.
+	IPBurst int `toml:",omitempty"`
+}
+
+// Enabled reports whether the policy restricts any call.
+func (c *PolicyConfig) Enabled() bool {
+	return len(c.Allow) > 0 || len(c.Deny) > 0 || len(c.MethodRates) > 0 || c.IPRate > 0
+}
+
+// policyClient is the rate limiter of a client IP.
+type policyClient struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// Policy enforces a PolicyConfig on the calls served by one or more servers. A
+// single policy may be shared by several servers for the client quotas to span
+// all of them.
+type Policy struct {
+	allow   map[string]struct{}
+	deny    map[string]struct{}
+	methods map[string]*rate.Limiter
+	ipRate  rate.Limit
+	ipBurst int
+
+	lock    sync.Mutex
+	clients map[string]*policyClient
+	swept   time.Time
+}
+
+// NewPolicy creates the enforcer of the given policy.
+func NewPolicy(config PolicyConfig) *Policy {
+	p := &Policy{
+		allow:   make(map[string]struct{}),
+		deny:    make(map[string]struct{}),
+		methods: make(map[string]*rate.Limiter),
+		ipRate:  rate.Limit(config.IPRate),
+		ipBurst: config.IPBurst,
+		clients: make(map[string]*policyClient),
+		swept:   time.Now(),
+	}
+	for _, name := range config.Allow {
+		p.allow[name] = struct{}{}
+	}
+	for _, name := range config.Deny {
+		p.deny[name] = struct{}{}
+	}
+	for name, limit := range config.MethodRates {
+		p.methods[name] = rate.NewLimiter(rate.Limit(limit), int(math.Max(1, math.Ceil(limit))))
+	}
+	if p.ipBurst < 1 {
+		p.ipBurst = int(math.Max(1, math.Ceil(config.IPRate)))
+	}
+	return p
+}
+
+// namespaceOf returns the namespace of a method.
+func namespaceOf(method string) string {
+	return strings.SplitN(method, serviceMethodSeparator, 2)[0]
+}
+
+// allowed reports whether the method may be called, the rules of the method
+// taking precedence over the ones of its namespace.
+func (p *Policy) allowed(method string) bool {
+	if _, ok := p.deny[method]; ok {
+		return false
+	}
+	if _, ok := p.allow[method]; ok {
+		return true
+	}
+	namespace := namespaceOf(method)
+	if _, ok := p.deny[namespace]; ok {
+		return false
+	}
+	if _, ok := p.allow[namespace]; ok {
+		return true
+	}
+	return len(p.allow) == 0
+}
+
+// methodLimiter returns the rate limiter of the method, or of its namespace if
+// the method has none.
+func (p *Policy) methodLimiter(method string) *rate.Limiter {
+	if limiter, ok := p.methods[method]; ok {
+		return limiter
+	}
+	return p.methods[namespaceOf(method)]
+}
+
+// check reports whether the given client may call the method now, consuming
+// its quotas if so.
+func (p *Policy) check(method string, peer PeerInfo) error {
+	if !p.allowed(method) {
+		return &methodDeniedError{method}
+	}
+	if p.ipRate > 0 {
+		if host := peerHost(peer); host != "" && !p.client(host).Allow() {
+			return &rateLimitedError{"request rate of client exceeded"}
+		}
+	}
+	if limiter := p.methodLimiter(method); limiter != nil && !limiter.Allow() {
+		return &rateLimitedError{"request rate of " + method + " exceeded"}
+	}
+	return nil
+}
+
+// client returns the rate limiter of the given client IP, dropping the ones of
+// the clients gone idle along the way.
+func (p *Policy) client(host string) *rate.Limiter {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	now := time.Now()
+	if now.Sub(p.swept) > policySweepInterval {
+		for ip, c := range p.clients {
+			if now.Sub(c.lastSeen) > policyClientExpiry {
+				delete(p.clients, ip)
+			}
+		}
+		p.swept = now
+	}
+	c, ok := p.clients[host]
+	if !ok {
+		c = &policyClient{limiter: rate.NewLimiter(p.ipRate, p.ipBurst)}
+		p.clients[host] = c
+	}
+	c.lastSeen = now
+	return c.limiter
+}
+
+// peerHost returns the IP of a remote client, or an empty string for local
+// clients connected over IPC or in process.
+func peerHost(peer PeerInfo) string {
+	if peer.Transport != "http" && peer.Transport != "ws" {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(peer.RemoteAddr)
+	if err != nil {
+		return peer.RemoteAddr
+	}
+	return host
+}
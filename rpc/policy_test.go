@@ -0,0 +1,116 @@
+// Copyright 2022 The go-ctereum Authors
+// This file is part of the go-ctereum library.
+//
+// The go-ctereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ctereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ctereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+)
+
+// policyErrorCode returns the JSON error code of a failed call.
+func policyErrorCode(err error) int {
+	var rpcErr Error
+	if errors.As(err, &rpcErr) {
+		return rpcErr.ErrorCode()
+	}
+	return 0
+}
+
+func TestPolicyAccess(t *testing.T) {
+	server := newTestServer()
+	defer server.Stop()
+	server.SetPolicy(NewPolicy(PolicyConfig{
+		Allow: []string{"test", "nftest_echo"},
+		Deny:  []string{"test_rets"},
+	}))
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	client, err := DialHTTP(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	tests := []struct {
+		method string
+		denied bool
+	}{
+		{"test_noArgsRets", false}, // allowed namespace
+		{"test_rets", true},        // denied method of allowed namespace
+		{"nftest_echo", false},     // allowed method of another namespace
+		{"nftest_other", true},     // namespace not allowed
+		{"rpc_modules", true},      // namespace not allowed
+	}
+	for _, tt := range tests {
+		err := client.Call(nil, tt.method)
+		if denied := policyErrorCode(err) == -32004; denied != tt.denied {
+			t.Errorf("%s: denied mismatch: have %v (%v), want %v", tt.method, denied, err, tt.denied)
+		}
+	}
+	// Lifting the policy lets everything through
+	server.SetPolicy(nil)
+	if err := client.Call(nil, "test_rets"); err != nil {
+		t.Errorf("call failed without policy: %v", err)
+	}
+}
+
+func TestPolicyRates(t *testing.T) {
+	server := newTestServer()
+	defer server.Stop()
+	server.SetPolicy(NewPolicy(PolicyConfig{
+		MethodRates: map[string]float64{"test": 0.001, "test_rets": 0.001},
+		IPRate:      0.001,
+		IPBurst:     4,
+	}))
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	client, err := DialHTTP(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	// The namespace and the method have their own limits
+	if err := client.Call(nil, "test_noArgsRets"); err != nil {
+		t.Fatalf("first namespace call failed: %v", err)
+	}
+	if err := client.Call(nil, "test_noArgsRets"); policyErrorCode(err) != -32005 {
+		t.Fatalf("second namespace call not limited: %v", err)
+	}
+	if err := client.Call(nil, "test_rets"); err != nil {
+		t.Fatalf("first method call failed: %v", err)
+	}
+	// The client quota is consumed by all calls, limited or not
+	if err := client.Call(nil, "nftest_echo"); err != nil && policyErrorCode(err) == -32005 {
+		t.Fatalf("call limited before exhausting the client quota: %v", err)
+	}
+	if err := client.Call(nil, "nftest_echo"); policyErrorCode(err) != -32005 {
+		t.Fatalf("client quota not enforced: %v", err)
+	}
+	// Local clients are not subject to the client quota
+	inproc := DialInProc(server)
+	defer inproc.Close()
+	if err := inproc.Call(nil, "test_noArgsRets"); policyErrorCode(err) != -32005 {
+		t.Errorf("method limit not enforced on local client: %v", err)
+	}
+	if err := inproc.Call(nil, "nftest_echo"); policyErrorCode(err) == -32005 {
+		t.Errorf("client quota enforced on local client: %v", err)
+	}
+}
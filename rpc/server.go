@@ -67,6 +67,33 @@ func (s *Server) RegisterName(name string, receiver interface{}) error {
 	return s.services.registerName(name, receiver)
 }
 
+// SetPolicy sets the access and rate limiting policy enforced on the calls
+// served. A nil policy lifts all restrictions.
+func (s *Server) SetPolicy(policy *Policy) {
+	s.services.mu.Lock()
+	defer s.services.mu.Unlock()
+
+	s.services.policy = policy
+}
+
+// BatchLimits bounds the processing of batch requests.
+type BatchLimits struct {
+	Items        int // Maximum number of items in a batch, unlimited if zero
+	ResponseSize int // Maximum size in bytes of the results of a batch, unlimited if zero
+	Workers      int // Number of calls of a batch executed concurrently, sequential if at most one
+}
+
+// SetBatchLimits sets the limits of the batch requests served. Batches with more
+// items than allowed are rejected as a whole, while the calls of a batch still
+// pending once its results exceed the response size limit are answered with an
+// error instead of being executed.
+func (s *Server) SetBatchLimits(limits BatchLimits) {
+	s.services.mu.Lock()
+	defer s.services.mu.Unlock()
+
+	s.services.batch = limits
+}
+
 // ServeCodec reads incoming requests from codec, calls the appropriate callback and writes
 // the response back using the given codec. It will block until the codec is closed or the
 // server is stopped. In either case the codec is closed.
@@ -80,11 +107,13 @@ func (s *Server) ServeCodec(codec ServerCodec, options CodecOption) {
 		return
 	}
 
-	// Add the codec to the set so it can be closed by Stop.
-	s.codecs.Add(codec)
-	defer s.codecs.Remove(codec)
+	// Add the codec to the set so it can be closed by Stop, tracking the messages
+	// and subscriptions of the connection for the connection management API.
+	tracked := newTrackedCodec(codec)
+	s.codecs.Add(tracked)
+	defer s.codecs.Remove(tracked)
 
-	c := initClient(codec, s.idgen, &s.services)
+	c := initClient(tracked, s.idgen, &s.services)
 	<-codec.closed()
 	c.Close()
 }
@@ -169,10 +198,35 @@ type PeerInfo struct {
 		Origin    string
 		Host      string
 	}
+
+	// Permission scopes granted by the token an HTTP or WebSocket client was
+	// authenticated with, nil if it was not authenticated. An empty list
+	// grants all scopes.
+	Scopes []string
 }
 
 type peerInfoContextKey struct{}
 
+type authScopesContextKey struct{}
+
+// WithAuthScopes returns a copy of the context of an authenticated HTTP request
+// carrying the permission scopes granted to the client, all of them if none are
+// given. The scopes are exposed to the method handlers serving the request, or
+// the WebSocket connection it is upgraded to, through PeerInfo.
+func WithAuthScopes(ctx context.Context, scopes []string) context.Context {
+	if scopes == nil {
+		scopes = []string{}
+	}
+	return context.WithValue(ctx, authScopesContextKey{}, scopes)
+}
+
+// authScopes returns the permission scopes carried by the context of a request,
+// nil if it was not authenticated.
+func authScopes(ctx context.Context) []string {
+	scopes, _ := ctx.Value(authScopesContextKey{}).([]string)
+	return scopes
+}
+
 // PeerInfoFromContext returns information about the client's network connection.
 // Use this with the context passed to RPC method handler functions.
 //
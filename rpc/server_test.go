@@ -21,6 +21,8 @@ import (
 	"bytes"
 	"io"
 	"net"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"strings"
@@ -152,3 +154,81 @@ func TestServerShortLivedConn(t *testing.T) {
 		}
 	}
 }
+
+// Tests that batches with more items than allowed are rejected as a whole.
+func TestServerBatchItemLimit(t *testing.T) {
+	server := newTestServer()
+	defer server.Stop()
+	server.SetBatchLimits(BatchLimits{Items: 2})
+
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	body := `[{"jsonrpc":"2.0","id":1,"method":"test_rets"},{"jsonrpc":"2.0","id":2,"method":"test_rets"},{"jsonrpc":"2.0","id":3,"method":"test_rets"}]`
+	resp, err := http.Post(ts.URL, "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	have, _ := io.ReadAll(resp.Body)
+
+	want := `[{"jsonrpc":"2.0","id":1,"error":{"code":-32600,"message":"batch too large: 3 items, limit 2"}}]`
+	if strings.TrimSpace(string(have)) != want {
+		t.Errorf("response mismatch:\nhave %s\nwant %s", have, want)
+	}
+}
+
+// Tests that the calls of a batch still pending once its results exceed the
+// response size limit are answered with an error.
+func TestServerBatchResponseLimit(t *testing.T) {
+	server := newTestServer()
+	defer server.Stop()
+	server.SetBatchLimits(BatchLimits{ResponseSize: 10})
+
+	client := DialInProc(server)
+	defer client.Close()
+
+	batch := make([]BatchElem, 3)
+	for i := range batch {
+		batch[i] = BatchElem{Method: "test_echo", Args: []interface{}{"hello", i, &echoArgs{"world"}}, Result: new(echoResult)}
+	}
+	if err := client.BatchCall(batch); err != nil {
+		t.Fatal(err)
+	}
+	if batch[0].Error != nil {
+		t.Errorf("first call failed: %v", batch[0].Error)
+	}
+	for i, elem := range batch[1:] {
+		if err, ok := elem.Error.(Error); !ok || err.ErrorCode() != -32003 {
+			t.Errorf("call %d: error mismatch: have %v, want response too large", i+1, elem.Error)
+		}
+	}
+}
+
+// Tests that the calls of a batch are executed concurrently by the workers.
+func TestServerBatchConcurrency(t *testing.T) {
+	server := newTestServer()
+	defer server.Stop()
+	server.SetBatchLimits(BatchLimits{Workers: 4})
+
+	client := DialInProc(server)
+	defer client.Close()
+
+	const delay = 300 * time.Millisecond
+	batch := make([]BatchElem, 4)
+	for i := range batch {
+		batch[i] = BatchElem{Method: "test_sleep", Args: []interface{}{delay}, Result: new(interface{})}
+	}
+	start := time.Now()
+	if err := client.BatchCall(batch); err != nil {
+		t.Fatal(err)
+	}
+	for i, elem := range batch {
+		if elem.Error != nil {
+			t.Errorf("call %d failed: %v", i, elem.Error)
+		}
+	}
+	if elapsed := time.Since(start); elapsed >= 2*delay {
+		t.Errorf("batch not executed concurrently: took %v for %d calls of %v", elapsed, len(batch), delay)
+	}
+}
@@ -39,6 +39,8 @@ var (
 type serviceRegistry struct {
 	mu       sync.Mutex
 	services map[string]service
+	policy   *Policy     // Access and rate limiting policy of the calls, nil if unrestricted
+	batch    BatchLimits // Limits of the batch requests
 }
 
 // service represents a registered object.
@@ -103,6 +105,25 @@ func (r *serviceRegistry) callback(method string) *callback {
 	return r.services[elem[0]].callbacks[elem[1]]
 }
 
+// checkPolicy reports whether the given client may call the method now.
+func (r *serviceRegistry) checkPolicy(method string, peer PeerInfo) error {
+	r.mu.Lock()
+	policy := r.policy
+	r.mu.Unlock()
+
+	if policy == nil {
+		return nil
+	}
+	return policy.check(method, peer)
+}
+
+// batchLimits returns the limits of the batch requests.
+func (r *serviceRegistry) batchLimits() BatchLimits {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.batch
+}
+
 // subscription returns a subscription callback in the given service.
 func (r *serviceRegistry) subscription(service, name string) *callback {
 	r.mu.Lock()
@@ -93,6 +93,7 @@ func NotifierFromContext(ctx context.Context) (*Notifier, bool) {
 type Notifier struct {
 	h         *handler
 	namespace string
+	name      string // Name of the subscription, e.g. logs
 
 	mu           sync.Mutex
 	sub          *Subscription
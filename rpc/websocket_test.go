@@ -414,3 +414,71 @@ func (s *severableReadWriteCloser) Write(p []byte) (n int, err error) {
 func (s *severableReadWriteCloser) Close() error {
 	return s.ReadWriteCloser.Close()
 }
+
+// This test checks that websocket connections are listed along with their message
+// counts and subscriptions, and that they can be terminated.
+func TestWebsocketConnections(t *testing.T) {
+	var (
+		s     = newTestServer()
+		ts    = httptest.NewServer(s.WebsocketHandler([]string{"*"}))
+		tsurl = "ws:" + strings.TrimPrefix(ts.URL, "http:")
+	)
+	defer s.Stop()
+	defer ts.Close()
+
+	c, err := DialWebsocket(context.Background(), tsurl, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	notifications := make(chan int, 2)
+	sub, err := c.Subscribe(context.Background(), "nftest", notifications, "someSubscription", 2, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		<-notifications
+	}
+	var echo int
+	if err := c.Call(&echo, "nftest_echo", 1); err != nil {
+		t.Fatal(err)
+	}
+	// The subscription, its two notifications and the call were exchanged
+	var conns []ConnInfo
+	for start := time.Now(); time.Since(start) < 5*time.Second; time.Sleep(10 * time.Millisecond) {
+		if conns = s.Connections(); len(conns) == 1 && conns[0].Sent == 4 {
+			break
+		}
+	}
+	if len(conns) != 1 {
+		t.Fatalf("connection count mismatch: have %d, want 1", len(conns))
+	}
+	conn := conns[0]
+	if conn.Transport != "ws" || conn.Received != 2 || conn.Sent != 4 {
+		t.Fatalf("connection mismatch: %+v", conn)
+	}
+	if len(conn.Subscriptions) != 1 || conn.Subscriptions[0].Method != "nftest_someSubscription" {
+		t.Fatalf("subscriptions mismatch: %+v", conn.Subscriptions)
+	}
+	// Terminate the connection and check that its subscription ends with it
+	if s.CloseConnection(ID("0x00")) {
+		t.Fatal("unknown connection closed")
+	}
+	if !s.CloseConnection(conn.ID) {
+		t.Fatal("connection not found")
+	}
+	select {
+	case err := <-sub.Err():
+		if err == nil {
+			t.Fatal("subscription ended without error")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("subscription not terminated")
+	}
+	for start := time.Now(); len(s.Connections()) > 0; time.Sleep(10 * time.Millisecond) {
+		if time.Since(start) > 5*time.Second {
+			t.Fatal("terminated connection still listed")
+		}
+	}
+}
@@ -289,10 +289,9 @@ func NewDatabaseWithConfig(diskdb ethdb.KeyValueStore, config *Config) *Database
 			cleans = fastcache.LoadFromFileOrNew(config.Journal, config.Cache*1024*1024)
 		}
 	}
-	var preimage *preimageStore
-	if config != nil && config.Preimages {
-		preimage = newPreimageStore(diskdb)
-	}
+	// The preimage store is always set up to allow toggling the recording at
+	// runtime, already recorded preimages are served regardless
+	preimage := newPreimageStore(diskdb, config != nil && config.Preimages)
 	db := &Database{
 		diskdb: diskdb,
 		cleans: cleans,
@@ -874,6 +873,20 @@ func (db *Database) SaveCachePeriodically(dir string, interval time.Duration, st
 	}
 }
 
+// SetPreimageRecording enables or disables recording the preimages of the keys
+// of the secure tries opened on the database.
+func (db *Database) SetPreimageRecording(enabled bool) {
+	if db.preimages != nil {
+		db.preimages.setRecording(enabled)
+	}
+}
+
+// PreimageRecording reports whether the preimages of secure trie keys are
+// recorded.
+func (db *Database) PreimageRecording() bool {
+	return db.preimages != nil && db.preimages.isRecording()
+}
+
 // CommitPreimages flushes the dangling preimages to disk. It is meant to be
 // called when closing the blockchain object, so that preimages are persisted
 // to the database.
@@ -18,6 +18,7 @@ package trie
 
 import (
 	"sync"
+	"sync/atomic"
 
 	"github.com/qydata/go-ctereum/common"
 	"github.com/qydata/go-ctereum/core/rawdb"
@@ -30,20 +31,41 @@ type preimageStore struct {
 	disk          ethdb.KeyValueStore
 	preimages     map[common.Hash][]byte // Preimages of nodes from the secure trie
 	preimagesSize common.StorageSize     // Storage size of the preimages cache
+	recording     uint32                 // Whether new preimages are recorded (atomic)
 }
 
 // newPreimageStore initializes the store for caching preimages.
-func newPreimageStore(disk ethdb.KeyValueStore) *preimageStore {
-	return &preimageStore{
+func newPreimageStore(disk ethdb.KeyValueStore, recording bool) *preimageStore {
+	store := &preimageStore{
 		disk:      disk,
 		preimages: make(map[common.Hash][]byte),
 	}
+	store.setRecording(recording)
+	return store
+}
+
+// setRecording enables or disables recording new preimages. Known preimages
+// remain retrievable either way.
+func (store *preimageStore) setRecording(recording bool) {
+	var flag uint32
+	if recording {
+		flag = 1
+	}
+	atomic.StoreUint32(&store.recording, flag)
+}
+
+// isRecording reports whether new preimages are recorded.
+func (store *preimageStore) isRecording() bool {
+	return atomic.LoadUint32(&store.recording) == 1
 }
 
 // insertPreimage writes a new trie node pre-image to the memory database if it's
 // yet unknown. The method will NOT make a copy of the slice, only use if the
 // preimage will NOT be changed later on.
 func (store *preimageStore) insertPreimage(preimages map[common.Hash][]byte) {
+	if !store.isRecording() {
+		return
+	}
 	store.lock.Lock()
 	defer store.lock.Unlock()
 
@@ -74,7 +96,7 @@ func (store *preimageStore) commit(force bool) error {
 	store.lock.Lock()
 	defer store.lock.Unlock()
 
-	if store.preimagesSize <= 4*1024*1024 && !force {
+	if len(store.preimages) == 0 || (store.preimagesSize <= 4*1024*1024 && !force) {
 		return nil
 	}
 	batch := store.disk.NewBatch()
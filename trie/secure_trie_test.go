@@ -148,3 +148,28 @@ func TestStateTrieConcurrency(t *testing.T) {
 	// Wait for all threads to finish
 	pend.Wait()
 }
+
+// Tests that the preimages of the trie keys are only recorded while recording
+// is enabled on the database.
+func TestSecurePreimageRecording(t *testing.T) {
+	triedb := NewDatabase(memorydb.New())
+	if triedb.PreimageRecording() {
+		t.Fatalf("preimage recording enabled by default")
+	}
+	commit := func(key []byte) {
+		trie, _ := NewStateTrie(common.Hash{}, common.Hash{}, triedb)
+		trie.Update(key, []byte("value"))
+		if _, _, err := trie.Commit(false); err != nil {
+			t.Fatalf("failed to commit trie: %v", err)
+		}
+	}
+	commit([]byte("foo"))
+	if preimage := triedb.preimages.preimage(crypto.Keccak256Hash([]byte("foo"))); preimage != nil {
+		t.Errorf("preimage recorded while disabled: %q", preimage)
+	}
+	triedb.SetPreimageRecording(true)
+	commit([]byte("bar"))
+	if preimage := triedb.preimages.preimage(crypto.Keccak256Hash([]byte("bar"))); !bytes.Equal(preimage, []byte("bar")) {
+		t.Errorf("preimage mismatch: have %q, want %q", preimage, "bar")
+	}
+}